@@ -0,0 +1,155 @@
+// Package client is a typed Go client for the LinkinSync API. Requests and
+// responses use the same models as the server.
+//
+//	c := client.New(client.Config{BaseURL: "http://localhost:8080", APIKey: key})
+//	answer, err := c.Chat(ctx, "What is this song about?")
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/server/models"
+)
+
+// Errors matched by APIError, e.g. errors.Is(err, client.ErrNotFound)
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// APIError is returned for non-2xx responses
+type APIError struct {
+	StatusCode int
+	Code       string        // The error envelope's code, e.g. "not_found"; empty if the body had none
+	Message    string        // The envelope's message, or the trimmed body when it isn't one
+	RetryAfter time.Duration // From Retry-After on 429 responses
+}
+
+// Error describes the failed request
+func (e *APIError) Error() string {
+	return fmt.Sprintf("linkinsync API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// Is matches ErrNotFound, ErrConflict and ErrRateLimited by status code
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// Config holds client configuration
+type Config struct {
+	BaseURL string        // e.g. http://localhost:8080
+	APIKey  string        // Sent as X-API-Key; required for writes that change shared state
+	UserID  string        // Sent as X-User-ID, identifying the user for stats, budgets and restricted mode
+	Timeout time.Duration // Per request; defaults to 30s
+}
+
+// Client calls the LinkinSync API. It is safe for concurrent use.
+type Client struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// New creates a new API client
+func New(config Config) *Client {
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	return &Client{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// ForUser returns a copy of the client that acts as userID, sharing the
+// underlying HTTP client
+func (c *Client) ForUser(userID string) *Client {
+	config := c.config
+	config.UserID = userID
+	return &Client{config: config, httpClient: c.httpClient}
+}
+
+// Health checks that the server is up
+func (c *Client) Health(ctx context.Context) error {
+	_, err := c.do(ctx, "GET", "/api/health", nil, nil, nil)
+	return err
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response
+// into out when it is non-nil. Extra headers may be nil.
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, headers map[string]string, out interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.config.BaseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.config.APIKey != "" {
+		req.Header.Set("X-API-Key", c.config.APIKey)
+	}
+	if c.config.UserID != "" {
+		req.Header.Set("X-User-ID", c.config.UserID)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var envelope models.ErrorResponse
+		if json.Unmarshal(data, &envelope) == nil && envelope.Error.Code != "" {
+			apiErr.Code = envelope.Error.Code
+			apiErr.Message = envelope.Error.Message
+		}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return resp, apiErr
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"backend/server/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Messages returns the newest page of the global chat's main stream, newest
+// first; older pages are fetched with MessagesPage and replies with Thread
+func (c *Client) Messages(ctx context.Context) ([]models.Message, error) {
+	var messages []models.Message
+	if _, err := c.do(ctx, "GET", "/api/messages", nil, nil, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// MessagesPage returns a page of up to limit messages of the main stream,
+// newest first, older than cursor, and the cursor of the next page, which is
+// empty on the last page. Pass an empty cursor for the first page.
+func (c *Client) MessagesPage(ctx context.Context, limit int, cursor string) ([]models.Message, string, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	var messages []models.Message
+	resp, err := c.do(ctx, "GET", "/api/messages?"+query.Encode(), nil, nil, &messages)
+	if err != nil {
+		return nil, "", err
+	}
+	return messages, resp.Header.Get("X-Next-Cursor"), nil
+}
+
+// PostMessage posts to the global chat and returns the stored message.
+// Requires an API key.
+func (c *Client) PostMessage(ctx context.Context, userEmail, username, text string) (*models.Message, error) {
+	message := models.Message{UserEmail: userEmail, Username: username, Text: text}
+	var stored models.Message
+	if _, err := c.do(ctx, "POST", "/api/messages", message, nil, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// ShareTrack posts a track card to the global chat, with text as an optional
+// caption, and returns the stored message. Requires an API key.
+func (c *Client) ShareTrack(ctx context.Context, userEmail, username, text string, track models.UnifiedTrack) (*models.Message, error) {
+	payload, err := json.Marshal(track)
+	if err != nil {
+		return nil, err
+	}
+	message := models.Message{
+		UserEmail:   userEmail,
+		Username:    username,
+		Text:        text,
+		MessageType: models.MessageTypeTrack,
+		Payload:     payload,
+	}
+	var stored models.Message
+	if _, err := c.do(ctx, "POST", "/api/messages", message, nil, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// React adds the client user's emoji reaction to a message and returns the
+// message's reactions. Requires an API key.
+func (c *Client) React(ctx context.Context, messageID int64, emoji string) (*models.MessageReactions, error) {
+	var reactions models.MessageReactions
+	path := fmt.Sprintf("/api/messages/%d/reactions", messageID)
+	if _, err := c.do(ctx, "POST", path, models.ReactionRequest{Emoji: emoji}, nil, &reactions); err != nil {
+		return nil, err
+	}
+	return &reactions, nil
+}
+
+// Unreact removes the client user's emoji reaction from a message and returns
+// the message's reactions. Requires an API key.
+func (c *Client) Unreact(ctx context.Context, messageID int64, emoji string) (*models.MessageReactions, error) {
+	var reactions models.MessageReactions
+	path := fmt.Sprintf("/api/messages/%d/reactions?emoji=%s", messageID, url.QueryEscape(emoji))
+	if _, err := c.do(ctx, "DELETE", path, nil, nil, &reactions); err != nil {
+		return nil, err
+	}
+	return &reactions, nil
+}
+
+// Reply posts a reply to a message in the global chat and returns the stored
+// reply. Requires an API key.
+func (c *Client) Reply(ctx context.Context, parentID int64, userEmail, username, text string) (*models.Message, error) {
+	message := models.Message{UserEmail: userEmail, Username: username, Text: text, ParentMessageID: &parentID}
+	var stored models.Message
+	if _, err := c.do(ctx, "POST", "/api/messages", message, nil, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// Thread returns a message and its replies, oldest first
+func (c *Client) Thread(ctx context.Context, messageID int64) (*models.MessageThread, error) {
+	var thread models.MessageThread
+	if _, err := c.do(ctx, "GET", fmt.Sprintf("/api/messages/%d/thread", messageID), nil, nil, &thread); err != nil {
+		return nil, err
+	}
+	return &thread, nil
+}
+
+// Report reports a message to moderators as the client user; reason is one
+// of models.ReportReasons. Requires an API key.
+func (c *Client) Report(ctx context.Context, messageID int64, reason, details string) (*models.MessageReport, error) {
+	var report models.MessageReport
+	path := fmt.Sprintf("/api/messages/%d/report", messageID)
+	if _, err := c.do(ctx, "POST", path, models.ReportRequest{Reason: reason, Details: details}, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Blocks returns the authors the client user blocked or muted, most recent
+// first. Requires an API key.
+func (c *Client) Blocks(ctx context.Context) ([]models.UserBlock, error) {
+	var blocks []models.UserBlock
+	if _, err := c.do(ctx, "GET", "/api/blocks", nil, nil, &blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// Block hides an author's messages from the client user; kind is
+// models.BlockKindBlock or models.BlockKindMute. Requires an API key.
+func (c *Client) Block(ctx context.Context, userEmail, kind string) (*models.UserBlock, error) {
+	var block models.UserBlock
+	if _, err := c.do(ctx, "POST", "/api/blocks", models.BlockRequest{UserEmail: userEmail, Kind: kind}, nil, &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// Unblock unblocks or unmutes an author for the client user. Requires an API key.
+func (c *Client) Unblock(ctx context.Context, userEmail string) error {
+	_, err := c.do(ctx, "DELETE", "/api/blocks/"+url.PathEscape(userEmail), nil, nil, nil)
+	return err
+}
+
+// Rooms lists the chat rooms with the client user's unread count in each.
+// Requires an API key.
+func (c *Client) Rooms(ctx context.Context) ([]models.Room, error) {
+	var rooms []models.Room
+	if _, err := c.do(ctx, "GET", "/api/rooms", nil, nil, &rooms); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// MarkRead sets the last message the client user read in a room, such as
+// models.GlobalRoom. Requires an API key.
+func (c *Client) MarkRead(ctx context.Context, room string, messageID int64) (*models.ReadMarker, error) {
+	var marker models.ReadMarker
+	path := "/api/rooms/" + url.PathEscape(room) + "/read"
+	if _, err := c.do(ctx, "PUT", path, models.ReadMarkerRequest{MessageID: messageID}, nil, &marker); err != nil {
+		return nil, err
+	}
+	return &marker, nil
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"backend/server/models"
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Chat asks the AI assistant a question about the current song, a mood or music in general
+func (c *Client) Chat(ctx context.Context, query string) (*models.ChatResponse, error) {
+	var response models.ChatResponse
+	if _, err := c.do(ctx, "POST", "/api/chat", models.ChatRequest{Query: query}, nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// NowPlaying returns the current song. It returns an error matching
+// ErrNotFound when nothing is playing.
+func (c *Client) NowPlaying(ctx context.Context) (*models.NowPlaying, error) {
+	var nowPlaying models.NowPlaying
+	if _, err := c.do(ctx, "GET", "/api/now-playing", nil, nil, &nowPlaying); err != nil {
+		return nil, err
+	}
+	return &nowPlaying, nil
+}
+
+// UpdateNowPlaying sets the current song, whose Source should name the music
+// service (e.g. "spotify"), and returns its new version. It
+// returns an error matching ErrConflict when another source owns playback.
+// Requires an API key.
+func (c *Client) UpdateNowPlaying(ctx context.Context, track models.UnifiedTrack) (int64, error) {
+	return c.updateNowPlaying(ctx, track, nil)
+}
+
+// UpdateNowPlayingIfVersion sets the current song only if it is still at
+// version, as returned by NowPlaying or a previous update. It returns an error
+// matching ErrConflict when the song changed in the meantime.
+func (c *Client) UpdateNowPlayingIfVersion(ctx context.Context, track models.UnifiedTrack, version int64) (int64, error) {
+	return c.updateNowPlaying(ctx, track, map[string]string{"If-Match": fmt.Sprintf(`"%d"`, version)})
+}
+
+func (c *Client) updateNowPlaying(ctx context.Context, track models.UnifiedTrack, headers map[string]string) (int64, error) {
+	resp, err := c.do(ctx, "POST", "/api/now-playing", track, headers, nil)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.Trim(resp.Header.Get("ETag"), `"`), 10, 64)
+}
+
+// StopNowPlaying marks playback as stopped. Requires an API key.
+func (c *Client) StopNowPlaying(ctx context.Context) error {
+	_, err := c.do(ctx, "DELETE", "/api/now-playing", nil, nil, nil)
+	return err
+}
+
+// SetPlaybackState sets the playback state to models.PlaybackPlaying,
+// PlaybackPaused or PlaybackStopped. Requires an API key.
+func (c *Client) SetPlaybackState(ctx context.Context, state string) error {
+	_, err := c.do(ctx, "POST", "/api/now-playing/state", map[string]string{"state": state}, nil, nil)
+	return err
+}
+
+// Heartbeat reports playback progress of the current song. Requires an API key.
+func (c *Client) Heartbeat(ctx context.Context, trackID string, positionMs, durationMs int64) error {
+	body := map[string]interface{}{
+		"track_id":    trackID,
+		"position_ms": positionMs,
+		"duration_ms": durationMs,
+	}
+	_, err := c.do(ctx, "POST", "/api/now-playing/heartbeat", body, nil, nil)
+	return err
+}
+
+// History returns recently played songs, most recent first
+func (c *Client) History(ctx context.Context) ([]models.PlayHistoryItem, error) {
+	var history []models.PlayHistoryItem
+	if _, err := c.do(ctx, "GET", "/api/history", nil, nil, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// FilterHistory returns up to limit played songs matching filter, most recent
+// first, after skipping offset of them, along with the number of matching songs
+func (c *Client) FilterHistory(ctx context.Context, filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error) {
+	query := historyQuery(filter)
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+
+	var history []models.PlayHistoryItem
+	resp, err := c.do(ctx, "GET", "/api/history?"+query.Encode(), nil, nil, &history)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := strconv.Atoi(resp.Header.Get("X-Total-Count"))
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid X-Total-Count header: %w", err)
+	}
+	return history, total, nil
+}
+
+// HistoryPage returns a page of up to limit played songs matching filter,
+// most recent first, after cursor, and the cursor of the next page, which is
+// empty on the last page. Pass an empty cursor for the first page.
+func (c *Client) HistoryPage(ctx context.Context, filter models.PlayHistoryFilter, limit int, cursor string) ([]models.PlayHistoryItem, string, error) {
+	query := historyQuery(filter)
+	query.Set("limit", strconv.Itoa(limit))
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	var history []models.PlayHistoryItem
+	resp, err := c.do(ctx, "GET", "/api/history?"+query.Encode(), nil, nil, &history)
+	if err != nil {
+		return nil, "", err
+	}
+	return history, resp.Header.Get("X-Next-Cursor"), nil
+}
+
+// historyQuery returns the query parameters of a history filter
+func historyQuery(filter models.PlayHistoryFilter) url.Values {
+	query := url.Values{}
+	if filter.Source != "" {
+		query.Set("source", filter.Source)
+	}
+	if filter.Artist != "" {
+		query.Set("artist", filter.Artist)
+	}
+	if !filter.From.IsZero() {
+		query.Set("from", filter.From.Format(time.RFC3339))
+	}
+	if !filter.To.IsZero() {
+		query.Set("to", filter.To.Format(time.RFC3339))
+	}
+	return query
+}
+
+// TrackMoods looks up mood analyses for up to 50 tracks. With analyze set,
+// tracks missing from the cache are analyzed.
+func (c *Client) TrackMoods(ctx context.Context, tracks []models.TrackReference, analyze bool) (*models.TrackMoodsResponse, error) {
+	var response models.TrackMoodsResponse
+	request := models.TrackMoodsRequest{Tracks: tracks, Analyze: analyze}
+	if _, err := c.do(ctx, "POST", "/api/tracks/moods", request, nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
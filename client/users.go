@@ -0,0 +1,47 @@
+package client
+
+import (
+	"backend/server/models"
+	"context"
+)
+
+// Me returns the client user's profile. Requires an API key.
+func (c *Client) Me(ctx context.Context) (*models.User, error) {
+	var user models.User
+	if _, err := c.do(ctx, "GET", "/api/users/me", nil, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateMe changes the fields of the client user's profile that update sets.
+// Requires an API key.
+func (c *Client) UpdateMe(ctx context.Context, update models.UserUpdate) (*models.User, error) {
+	var user models.User
+	if _, err := c.do(ctx, "PATCH", "/api/users/me", update, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteMyData queues the deletion of the client user's data, returning the
+// job; once it succeeds, its result is a models.DataDeletionReceipt.
+// Requires an API key.
+func (c *Client) DeleteMyData(ctx context.Context) (*models.QueuedJob, error) {
+	var job models.QueuedJob
+	if _, err := c.do(ctx, "DELETE", "/api/users/me/data", nil, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ExportMyData queues an export of the client user's data, returning the job
+// and the download URL of the bundle, which works once the job succeeded.
+// Requires an API key.
+func (c *Client) ExportMyData(ctx context.Context) (*models.DataExportQueued, error) {
+	var queued models.DataExportQueued
+	if _, err := c.do(ctx, "GET", "/api/users/me/export", nil, nil, &queued); err != nil {
+		return nil, err
+	}
+	return &queued, nil
+}
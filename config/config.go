@@ -2,24 +2,111 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// DefaultRateLimits limits chat and AI-backed routes per user or IP and
+// caps overall API use
+const DefaultRateLimits = "POST /api/chat=30/1m, POST /api/chat/stream=30/1m, POST /api/messages=20/1m, POST /api/dj=10/1m, /api/*=600/1m"
+
+// DefaultHelplines lists well-known crisis lines for a few regions and an
+// international directory for the others
+const DefaultHelplines = "US=988 Suicide & Crisis Lifeline|988|https://988lifeline.org; " +
+	"CA=9-8-8 Suicide Crisis Helpline|988|https://988.ca; " +
+	"GB=Samaritans|116 123|https://www.samaritans.org; " +
+	"IE=Samaritans|116 123|https://www.samaritans.org; " +
+	"AU=Lifeline|13 11 14|https://www.lifeline.org.au; " +
+	"*=Find A Helpline||https://findahelpline.com"
+
 // Config holds all application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Spotify  SpotifyConfig
-	Genius   GeniusConfig
-	Ollama   OllamaConfig
-	OpenAI   OpenAIConfig
+	Server     ServerConfig
+	NowPlaying NowPlayingConfig
+	History    HistoryConfig
+	State      StateConfig
+	Database   DatabaseConfig
+	Spotify    SpotifyConfig
+	Genius     GeniusConfig
+	AI         AIConfig
+	Prompts    PromptsConfig
+	Ollama     OllamaConfig
+	OpenAI     OpenAIConfig
+	Azure      AzureOpenAIConfig
+	Anthropic  AnthropicConfig
+	Jobs       JobsConfig
+	Breaker    BreakerConfig
+	Events     EventsConfig
+	Trending   TrendingConfig
+	Retention  RetentionConfig
+	Encryption EncryptionConfig
+	Auth       AuthConfig
+	Restricted RestrictedConfig
+	CleanMode  CleanModeConfig
+	RateLimit  RateLimitConfig
+	WebSocket  WebSocketConfig
+	Presence   PresenceConfig
+	Avatar     AvatarConfig
+	Export     ExportConfig
+	Topics     TopicsConfig
+	Quiz       QuizConfig
+	Trivia     TriviaConfig
+	Webhooks   WebhooksConfig
+	Customize  CustomizationConfig
+	Safety     SafetyConfig
+	Moderation ModerationConfig
+	TLS        TLSConfig
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port string
+	Port              string
+	ReadTimeout       time.Duration // For the whole request, including the body
+	ReadHeaderTimeout time.Duration // For the request headers; cuts off slow-loris clients
+	WriteTimeout      time.Duration // From the end of the request headers until the response is written
+	IdleTimeout       time.Duration // How long keep-alive connections wait for the next request
+	MaxHeaderBytes    int
+	AllowedOrigins    []string // Browser origins allowed by CORS and WebSocket upgrades
+}
+
+// TLSConfig holds native HTTPS settings for deployments without a reverse
+// proxy. HTTPS is served on PORT when a certificate or autocert hosts are set.
+type TLSConfig struct {
+	CertFile         string   // PEM certificate chain
+	KeyFile          string   // PEM private key
+	AutocertHosts    []string // Obtain a certificate for these hosts from an ACME CA instead
+	AutocertEmail    string
+	AutocertCacheDir string
+	AutocertURL      string // ACME directory; Let's Encrypt by default
+	RedirectPort     string // Plain HTTP port redirecting to HTTPS and answering ACME challenges; empty disables
+}
+
+// Enabled reports whether HTTPS is configured
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || len(c.AutocertHosts) > 0
+}
+
+// NowPlayingConfig holds now-playing source conflict and expiry settings
+type NowPlayingConfig struct {
+	MinDwell         time.Duration  // How long a source keeps playback before another may take over
+	SourcePriorities map[string]int // Higher priority sources take over immediately
+	StaleAfter       time.Duration  // Tracks without updates for this long are stopped; 0 disables
+	RestoreWithin    time.Duration  // A saved track updated this recently is restored on startup; 0 disables saving
+}
+
+// HistoryConfig holds when tracks are added to the play history and where it is kept
+type HistoryConfig struct {
+	Backend          string        // "memory" or "postgres", which keeps every play across restarts
+	ScrobbleFraction float64       // Share of a track that must be heard; 0 adds tracks as they start
+	ScrobbleAfter    time.Duration // Listening time that is always enough, for long or unknown-length tracks
+	RepeatWindow     time.Duration // Repeats of the latest track within this are collapsed into it; 0 disables
+	Size             int           // Tracks kept in memory, or returned as the recent history from Postgres
 }
 
 // DatabaseConfig holds database configuration
@@ -30,6 +117,10 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	MaxOpenConns    int           // Connections open at once, in use or idle; 0 for no limit
+	MaxIdleConns    int           // Idle connections kept open for reuse
+	ConnMaxLifetime time.Duration // Connections are closed and replaced once this old; 0 keeps them
 }
 
 // SpotifyConfig holds Spotify API configuration
@@ -40,7 +131,65 @@ type SpotifyConfig struct {
 
 // GeniusConfig holds Genius API configuration
 type GeniusConfig struct {
-	AccessToken string
+	AccessToken    string
+	LyricsCacheTTL time.Duration // How long fetched lyrics are reused
+	LyricsCacheMax int           // Maximum songs with cached lyrics; 0 disables the cache
+
+	// Clean-up of scraped lyrics, before they are cached or sent to the AI
+	StripSectionMarkers bool // Remove [Verse]/[Chorus] markers
+	RemoveNoise         bool // Remove contributor headers, "Embed" trailers and ads
+	CollapseWhitespace  bool // Collapse runs of spaces and blank lines
+
+	FactsCacheTTL time.Duration // How long a song's facts and blurb are reused
+	FactsCacheMax int           // Maximum songs with cached facts; 0 disables the cache
+	AlbumCacheTTL time.Duration // How long an album's analysis is reused
+	AlbumCacheMax int           // Maximum albums with a cached analysis; analyses are only served from the cache
+	StyleCacheTTL time.Duration // How long an artist's style analysis is reused
+	StyleCacheMax int           // Maximum artists with a cached style; 0 disables the cache
+}
+
+// AIConfig holds AI provider selection and response caching
+type AIConfig struct {
+	Provider  string // "openai", "azure", "ollama" or "anthropic"; detected from credentials if unset
+	CacheTTL  time.Duration
+	CacheSize int                   // Maximum cached responses; 0 disables the cache
+	Prices    map[string]ModelPrice // By model name, for canary cost estimates
+
+	// Answers to common lyrics questions, such as "what does this song mean",
+	// are stored per track for AnalysisCacheTTL; 0 disables the store. A new
+	// answer is written once every stored one has AnalysisRejectAfter
+	// unhelpful ratings, and more unhelpful than helpful ones, at most once
+	// per AnalysisReanalyzeInterval for each question.
+	AnalysisCacheTTL          time.Duration
+	AnalysisRejectAfter       int
+	AnalysisReanalyzeInterval time.Duration
+
+	// Lyrics beyond LyricsTokenBudget estimated tokens are condensed by
+	// summarizing sections of LyricsChunkTokens; a budget of 0 sends them in full
+	LyricsTokenBudget int
+	LyricsChunkTokens int
+}
+
+// ModelPrice is a model's price in USD per million tokens
+type ModelPrice struct {
+	Input  float64
+	Output float64
+}
+
+// PromptsConfig holds AI prompt template overrides
+type PromptsConfig struct {
+	Dir            string            // Directory of <name>.tmpl files replacing built-in templates
+	Files          map[string]string // Files replacing single templates, by template name
+	ReloadInterval time.Duration     // How often override files are checked for changes; 0 only reloads on SIGHUP
+
+	// Experiments assign users to variants of a template, by template name
+	Experiments map[string][]PromptVariant
+}
+
+// PromptVariant is one arm of a prompt experiment
+type PromptVariant struct {
+	Name   string // "control" for the template itself, otherwise a "<template>.<name>" template
+	Weight int    // Share of users assigned, relative to the other variants
 }
 
 // OllamaConfig holds Ollama configuration
@@ -60,80 +209,885 @@ type OpenAIConfig struct {
 	Temperature float64
 	MaxTokens   int
 	TopP        float64
+
+	// Retries for rate limits and server errors, also applied to Azure OpenAI
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// Daily token budgets, also applied to Azure OpenAI; 0 disables a budget
+	DailyTokenBudget     int
+	UserDailyTokenBudget int
+	BudgetFallback       string // "ollama" to downgrade once a budget is exceeded, "none" to reject
+}
+
+// AzureOpenAIConfig holds Azure OpenAI configuration
+type AzureOpenAIConfig struct {
+	APIKey     string
+	Endpoint   string // e.g. https://my-resource.openai.azure.com
+	Deployment string
+	APIVersion string
+}
+
+// AnthropicConfig holds Anthropic Claude API configuration
+type AnthropicConfig struct {
+	APIKey      string
+	Model       string
+	BaseURL     string
+	Temperature float64
+	MaxTokens   int
+	TopP        float64 // 0 leaves it unset
+}
+
+// BreakerConfig holds circuit breaker settings for external services
+type BreakerConfig struct {
+	FailureThreshold int           // Consecutive failures before a service is bypassed
+	OpenTimeout      time.Duration // How long to bypass it before probing for recovery
+}
+
+// EventsConfig holds optional mirroring of internal events to NATS or Kafka
+type EventsConfig struct {
+	StreamBackend     string // "nats", "kafka", or empty to disable
+	StreamURL         string // nats://host:4222, or the Kafka REST Proxy URL
+	StreamTopicPrefix string
+	MaxAttempts       int // Publish attempts per event, including the first
+	DeliveryLogSize   int // Recent deliveries kept for the admin dashboard
+}
+
+// TrendingConfig holds the sliding window used to rank trending tracks
+type TrendingConfig struct {
+	Window  time.Duration // Plays older than this no longer count
+	Buckets int           // Window granularity; plays expire one bucket at a time
+}
+
+// TopicsConfig holds community topic detection over recent chat
+type TopicsConfig struct {
+	Interval       time.Duration // How often topics are detected
+	Window         time.Duration // How far back messages are clustered
+	Embedder       string        // "openai", "ollama" or "local"; follows the AI provider if unset
+	EmbeddingModel string        // Model of the openai or ollama embedder
+	Similarity     float64       // Minimum cosine similarity within a topic; 0 uses the embedder's default
+	MinClusterSize int           // Messages needed for a topic
+	Digest         bool          // Post a digest of new topics to global chat
+}
+
+// QuizConfig holds lyrics quiz game settings
+type QuizConfig struct {
+	Rounds     int           // Questions per game
+	AnswerTime time.Duration // How long players have to answer each question
+	RevealTime time.Duration // Pause after each answer is revealed
+}
+
+// TriviaConfig holds chat quiz settings
+type TriviaConfig struct {
+	Questions  int           // Questions per quiz
+	SessionTTL time.Duration // A quiz is dropped after this long without an answer
+}
+
+// WebhooksConfig holds delivery settings for registered webhooks
+type WebhooksConfig struct {
+	MaxAttempts     int           // POST attempts per event, including the first
+	Timeout         time.Duration // Time allowed for each POST
+	DeliveryLogSize int           // Recent deliveries kept per webhook
 }
 
-// Load loads configuration from environment variables
+// RestrictedConfig holds restricted (parental/teen) mode settings
+type RestrictedConfig struct {
+	Deployment bool     // Restrict every user
+	Users      []string // Users restricted from startup
+}
+
+// CleanModeConfig holds clean mode settings
+type CleanModeConfig struct {
+	Users []string // Users in clean mode from startup
+	Words []string // Words masked on top of the built-in profanity list
+}
+
+// SafetyConfig holds the support resources offered when a chat message
+// indicates a crisis
+type SafetyConfig struct {
+	Helplines     string // Semicolon-separated "REGION=name|phone|url" entries; "*" applies to every region
+	DefaultRegion string // Two-letter country code used when a request doesn't give its region
+}
+
+// ModerationConfig holds the screening of global chat messages for hate and
+// harassment
+type ModerationConfig struct {
+	Classifier      string   // "openai", "local" or "off"; follows the AI provider if unset
+	Model           string   // Model of the openai classifier
+	Categories      []string // Categories acted on, e.g. "harassment/threatening"
+	FlagThreshold   float64  // Messages scoring at least this are posted and queued for review
+	RejectThreshold float64  // Messages scoring at least this aren't posted
+}
+
+// StateConfig holds where now-playing state and the play history are shared
+// when several instances serve the same deployment
+type StateConfig struct {
+	Backend   string // "memory" for per-instance state, or "redis" to share it across instances
+	RedisURL  string // redis://[:password@]host:6379/db
+	KeyPrefix string // Prefix of the shared state's Redis keys
+}
+
+// RateLimitConfig holds per-route request limits
+type RateLimitConfig struct {
+	Backend   string // "memory" for a per-instance limiter, or "redis" to share limits across instances
+	RedisURL  string // redis://[:password@]host:6379/db
+	KeyPrefix string // Prefix of the limiter's Redis keys
+	Rules     string // Comma-separated "[METHOD] /path=limit/window" rules; the first match applies
+}
+
+// WebSocketConfig holds limits for persistent WebSocket connections
+type WebSocketConfig struct {
+	MaxMessageBytes   int           // Larger client messages close the connection
+	MessagesPerMinute int           // Client messages allowed per connection per minute; 0 disables
+	PingInterval      time.Duration // How often clients are pinged; silent clients are dropped after two intervals
+	TypingTimeout     time.Duration // How long a chat typing indicator lasts unless the client refreshes it
+	ChatFanout        string        // "memory" to push the messages posted to this instance, or "postgres" to push every instance's through LISTEN/NOTIFY
+}
+
+// PresenceConfig holds online presence settings
+type PresenceConfig struct {
+	Timeout time.Duration // How long a heartbeat keeps a user online
+}
+
+// AvatarConfig holds where uploaded avatars are stored
+type AvatarConfig struct {
+	Storage     string // "local" or "s3"
+	Dir         string // Directory of local avatars
+	MaxBytes    int    // Largest image accepted
+	S3Endpoint  string // Defaults to AWS's endpoint for the region; set for S3-compatible services
+	S3Bucket    string
+	S3Region    string
+	S3AccessKey string
+	S3SecretKey string
+}
+
+// ExportConfig holds where personal data exports are kept for download
+type ExportConfig struct {
+	Dir string        // Directory of export bundles
+	TTL time.Duration // How long a bundle can be downloaded before it is deleted
+}
+
+// AuthConfig holds API authentication settings
+type AuthConfig struct {
+	APIKeys []string // Keys accepted for write endpoints, in addition to the api_keys table
+}
+
+// EncryptionConfig holds optional at-rest encryption of per-user mood data
+type EncryptionConfig struct {
+	MasterKey []byte // Decoded from base64; empty disables encryption
+}
+
+// RetentionConfig holds how long raw history is kept before it is archived
+// and deleted. Zero keeps it forever; aggregates are always kept.
+type RetentionConfig struct {
+	MoodHistory  time.Duration
+	Messages     time.Duration
+	Interval     time.Duration // How often the archival job runs
+	ArchiveDir   string        // Where archives are written when ArchiveURL is empty
+	ArchiveURL   string        // Object storage base URL archives are PUT to
+	ArchiveToken string        // Bearer token for ArchiveURL
+}
+
+// JobsConfig holds background job configuration
+type JobsConfig struct {
+	CatalogValidationInterval time.Duration
+	WeeklyReportsInterval     time.Duration // How often to check for users missing last week's report
+	Jitter                    float64       // Share of its interval each run may be delayed by at random
+	Disabled                  []string      // Names of jobs that never run
+
+	// Queue of slow background work, such as bulk mood analyses
+	QueueWorkers     int           // Jobs run at the same time
+	QueueMaxAttempts int           // Attempts per job, including the first
+	QueueRetention   time.Duration // How long finished jobs are kept; 0 keeps them forever
+}
+
+// Load loads configuration from environment variables and the config file
+// named by CONFIG_FILE, if any
 func Load() (*Config, error) {
+	return LoadFrom("")
+}
+
+// LoadFrom loads configuration from environment variables and a JSON or YAML
+// config file. Environment variables override values from the file. If path
+// is empty, CONFIG_FILE is used.
+func LoadFrom(path string) (*Config, error) {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
 		// It's okay if .env doesn't exist in production
 		fmt.Println("Warning: .env file not found, using system environment variables")
 	}
 
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	fileValues = map[string]string{}
+	if path != "" {
+		values, err := loadConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fileValues = values
+	}
+
+	l := &loader{}
 	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnvWithDefault("PORT", "8080"),
+			Port:              l.getEnvWithDefault("PORT", "8080"),
+			ReadTimeout:       l.getEnvDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+			ReadHeaderTimeout: l.getEnvDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+			WriteTimeout:      l.getEnvDuration("SERVER_WRITE_TIMEOUT", 2*time.Minute),
+			IdleTimeout:       l.getEnvDuration("SERVER_IDLE_TIMEOUT", 2*time.Minute),
+			MaxHeaderBytes:    l.getEnvInt("SERVER_MAX_HEADER_BYTES", 1<<20),
+			AllowedOrigins:    l.getEnvListWithDefault("ALLOWED_ORIGINS", []string{"http://localhost:3000", "http://127.0.0.1:3000"}),
+		},
+		NowPlaying: NowPlayingConfig{
+			MinDwell:         l.getEnvDuration("NOW_PLAYING_MIN_DWELL", 15*time.Second),
+			SourcePriorities: l.getEnvPriorities("NOW_PLAYING_SOURCE_PRIORITY"),
+			StaleAfter:       l.getEnvDuration("NOW_PLAYING_STALE_AFTER", 30*time.Minute),
+			RestoreWithin:    l.getEnvDuration("NOW_PLAYING_RESTORE_WITHIN", 10*time.Minute),
+		},
+		History: HistoryConfig{
+			Backend:          l.getEnvWithDefault("HISTORY_BACKEND", "memory"),
+			ScrobbleFraction: l.getEnvFloat("HISTORY_SCROBBLE_FRACTION", 0.5),
+			ScrobbleAfter:    l.getEnvDuration("HISTORY_SCROBBLE_AFTER", 4*time.Minute),
+			RepeatWindow:     l.getEnvDuration("HISTORY_REPEAT_WINDOW", 30*time.Minute),
+			Size:             l.getEnvInt("HISTORY_SIZE", 200),
+		},
+		State: StateConfig{
+			Backend:   l.getEnvWithDefault("STATE_BACKEND", "memory"),
+			RedisURL:  l.getSecretWithDefault("REDIS_URL", ""),
+			KeyPrefix: l.getEnvWithDefault("STATE_KEY_PREFIX", "linkinsync:state:"),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnvWithDefault("DB_HOST", "localhost"),
-			Port:     getEnvWithDefault("DB_PORT", "5432"),
-			User:     getEnvRequired("DB_USER"),
-			Password: getEnvRequired("DB_PASSWORD"),
-			DBName:   getEnvRequired("DB_NAME"),
-			SSLMode:  getEnvWithDefault("DB_SSL_MODE", "disable"),
+			Host:     l.getEnvWithDefault("DB_HOST", "localhost"),
+			Port:     l.getEnvWithDefault("DB_PORT", "5432"),
+			User:     l.getEnvRequired("DB_USER"),
+			Password: l.getSecretRequired("DB_PASSWORD"),
+			DBName:   l.getEnvRequired("DB_NAME"),
+			SSLMode:  l.getEnvWithDefault("DB_SSL_MODE", "disable"),
+
+			MaxOpenConns:    l.getEnvInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    l.getEnvInt("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime: l.getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
 		},
 		Spotify: SpotifyConfig{
-			ClientID:     getEnvRequired("SPOTIFY_CLIENT_ID"),
-			ClientSecret: getEnvRequired("SPOTIFY_CLIENT_SECRET"),
+			ClientID:     l.getSecretRequired("SPOTIFY_CLIENT_ID"),
+			ClientSecret: l.getSecretRequired("SPOTIFY_CLIENT_SECRET"),
 		},
 		Genius: GeniusConfig{
-			AccessToken: getEnvRequired("GENIUS_ACCESS_TOKEN"),
+			AccessToken:    l.getSecretRequired("GENIUS_ACCESS_TOKEN"),
+			LyricsCacheTTL: l.getEnvDuration("LYRICS_CACHE_TTL", 24*time.Hour),
+			LyricsCacheMax: l.getEnvInt("LYRICS_CACHE_SIZE", 500),
+
+			StripSectionMarkers: l.getEnvBool("LYRICS_STRIP_SECTIONS", false),
+			RemoveNoise:         l.getEnvBool("LYRICS_REMOVE_NOISE", true),
+			CollapseWhitespace:  l.getEnvBool("LYRICS_COLLAPSE_WHITESPACE", true),
+
+			FactsCacheTTL: l.getEnvDuration("SONG_FACTS_CACHE_TTL", 7*24*time.Hour),
+			FactsCacheMax: l.getEnvInt("SONG_FACTS_CACHE_SIZE", 500),
+			AlbumCacheTTL: l.getEnvDuration("ALBUM_ANALYSIS_CACHE_TTL", 30*24*time.Hour),
+			AlbumCacheMax: l.getEnvInt("ALBUM_ANALYSIS_CACHE_SIZE", 200),
+			StyleCacheTTL: l.getEnvDuration("ARTIST_STYLE_CACHE_TTL", 7*24*time.Hour),
+			StyleCacheMax: l.getEnvInt("ARTIST_STYLE_CACHE_SIZE", 200),
+		},
+		AI: AIConfig{
+			Provider:  l.getEnvWithDefault("AI_PROVIDER", ""),
+			CacheTTL:  l.getEnvDuration("LLM_CACHE_TTL", time.Hour),
+			CacheSize: l.getEnvInt("LLM_CACHE_SIZE", 500),
+			Prices:    l.getEnvPrices("AI_PRICES"),
+
+			AnalysisCacheTTL:          l.getEnvDuration("LYRICS_ANALYSIS_CACHE_TTL", 30*24*time.Hour),
+			AnalysisRejectAfter:       l.getEnvInt("LYRICS_ANALYSIS_REJECT_AFTER", 3),
+			AnalysisReanalyzeInterval: l.getEnvDuration("LYRICS_ANALYSIS_REANALYZE_INTERVAL", time.Hour),
+
+			LyricsTokenBudget: l.getEnvInt("LYRICS_TOKEN_BUDGET", 1500),
+			LyricsChunkTokens: l.getEnvInt("LYRICS_CHUNK_TOKENS", 1000),
+		},
+		Prompts: PromptsConfig{
+			Dir:            l.getEnvWithDefault("PROMPTS_DIR", ""),
+			Files:          l.getEnvFiles("PROMPTS_FILES"),
+			ReloadInterval: l.getEnvDuration("PROMPTS_RELOAD_INTERVAL", 10*time.Second),
+			Experiments:    l.getEnvExperiments("PROMPT_EXPERIMENTS"),
 		},
 		Ollama: OllamaConfig{
-			BaseURL:     getEnvWithDefault("OLLAMA_BASE_URL", "http://localhost:11434"),
-			Model:       getEnvWithDefault("OLLAMA_MODEL", "llama3.2:3b"),
-			Temperature: 0.7,
-			TopP:        0.9,
-			TopK:        40,
+			BaseURL:     l.getEnvWithDefault("OLLAMA_BASE_URL", "http://localhost:11434"),
+			Model:       l.getEnvWithDefault("OLLAMA_MODEL", "llama3.2:3b"),
+			Temperature: l.getEnvFloat("OLLAMA_TEMPERATURE", 0.7),
+			TopP:        l.getEnvFloat("OLLAMA_TOP_P", 0.9),
+			TopK:        l.getEnvInt("OLLAMA_TOP_K", 40),
 		},
 		OpenAI: OpenAIConfig{
-			APIKey:      getEnvWithDefault("OPENAI_API_KEY", ""),
-			Model:       getEnvWithDefault("OPENAI_MODEL", "gpt-3.5-turbo"),
-			BaseURL:     getEnvWithDefault("OPENAI_BASE_URL", "https://api.openai.com/v1"),
-			Temperature: 0.7,
-			MaxTokens:   500,
-			TopP:        0.9,
+			APIKey:      l.getSecretWithDefault("OPENAI_API_KEY", ""),
+			Model:       l.getEnvWithDefault("OPENAI_MODEL", "gpt-3.5-turbo"),
+			BaseURL:     l.getEnvWithDefault("OPENAI_BASE_URL", "https://api.openai.com/v1"),
+			Temperature: l.getEnvFloat("OPENAI_TEMPERATURE", 0.7),
+			MaxTokens:   l.getEnvInt("OPENAI_MAX_TOKENS", 500),
+			TopP:        l.getEnvFloat("OPENAI_TOP_P", 0.9),
+
+			MaxRetries:     l.getEnvInt("OPENAI_MAX_RETRIES", 3),
+			RetryBaseDelay: l.getEnvDuration("OPENAI_RETRY_BASE_DELAY", 500*time.Millisecond),
+			RetryMaxDelay:  l.getEnvDuration("OPENAI_RETRY_MAX_DELAY", 8*time.Second),
+
+			DailyTokenBudget:     l.getEnvInt("OPENAI_DAILY_TOKEN_BUDGET", 0),
+			UserDailyTokenBudget: l.getEnvInt("OPENAI_USER_DAILY_TOKEN_BUDGET", 0),
+			BudgetFallback:       l.getEnvWithDefault("OPENAI_BUDGET_FALLBACK", "none"),
+		},
+		Azure: AzureOpenAIConfig{
+			APIKey:     l.getSecretWithDefault("AZURE_OPENAI_API_KEY", ""),
+			Endpoint:   l.getEnvWithDefault("AZURE_OPENAI_ENDPOINT", ""),
+			Deployment: l.getEnvWithDefault("AZURE_OPENAI_DEPLOYMENT", ""),
+			APIVersion: l.getEnvWithDefault("AZURE_OPENAI_API_VERSION", "2024-06-01"),
+		},
+		Anthropic: AnthropicConfig{
+			APIKey:      l.getSecretWithDefault("ANTHROPIC_API_KEY", ""),
+			Model:       l.getEnvWithDefault("ANTHROPIC_MODEL", "claude-3-5-haiku-latest"),
+			BaseURL:     l.getEnvWithDefault("ANTHROPIC_BASE_URL", "https://api.anthropic.com/v1"),
+			Temperature: l.getEnvFloat("ANTHROPIC_TEMPERATURE", 0.7),
+			MaxTokens:   l.getEnvInt("ANTHROPIC_MAX_TOKENS", 500),
+			TopP:        l.getEnvFloat("ANTHROPIC_TOP_P", 0),
+		},
+		Jobs: JobsConfig{
+			CatalogValidationInterval: l.getEnvDuration("CATALOG_VALIDATION_INTERVAL", 24*time.Hour),
+			WeeklyReportsInterval:     l.getEnvDuration("WEEKLY_REPORTS_INTERVAL", 6*time.Hour),
+			Jitter:                    l.getEnvFloat("JOBS_JITTER", 0.1),
+			Disabled:                  l.getEnvList("JOBS_DISABLED"),
+			QueueWorkers:              l.getEnvInt("JOB_QUEUE_WORKERS", 2),
+			QueueMaxAttempts:          l.getEnvInt("JOB_QUEUE_MAX_ATTEMPTS", 3),
+			QueueRetention:            l.getEnvDuration("JOB_QUEUE_RETENTION", 7*24*time.Hour),
 		},
+		Breaker: BreakerConfig{
+			FailureThreshold: l.getEnvInt("BREAKER_FAILURE_THRESHOLD", 5),
+			OpenTimeout:      l.getEnvDuration("BREAKER_OPEN_TIMEOUT", 30*time.Second),
+		},
+		Events: EventsConfig{
+			StreamBackend:     l.getEnvWithDefault("EVENT_STREAM_BACKEND", ""),
+			StreamURL:         l.getEnvWithDefault("EVENT_STREAM_URL", ""),
+			StreamTopicPrefix: l.getEnvWithDefault("EVENT_STREAM_TOPIC_PREFIX", "linkinsync."),
+			MaxAttempts:       l.getEnvInt("EVENT_STREAM_MAX_ATTEMPTS", 3),
+			DeliveryLogSize:   l.getEnvInt("EVENT_STREAM_DELIVERY_LOG_SIZE", 200),
+		},
+		Retention: RetentionConfig{
+			MoodHistory:  l.getEnvDuration("RETENTION_MOOD_HISTORY", 2*365*24*time.Hour),
+			Messages:     l.getEnvDuration("RETENTION_MESSAGES", 2*365*24*time.Hour),
+			Interval:     l.getEnvDuration("RETENTION_INTERVAL", 24*time.Hour),
+			ArchiveDir:   l.getEnvWithDefault("ARCHIVE_DIR", "./data/archive"),
+			ArchiveURL:   l.getEnvWithDefault("ARCHIVE_URL", ""),
+			ArchiveToken: l.getSecretWithDefault("ARCHIVE_TOKEN", ""),
+		},
+		Restricted: RestrictedConfig{
+			Deployment: l.getEnvBool("RESTRICTED_MODE", false),
+			Users:      l.getEnvList("RESTRICTED_USERS"),
+		},
+		CleanMode: CleanModeConfig{
+			Users: l.getEnvList("CLEAN_MODE_USERS"),
+			Words: l.getEnvList("PROFANITY_WORDS"),
+		},
+		RateLimit: RateLimitConfig{
+			Backend:   l.getEnvWithDefault("RATE_LIMIT_BACKEND", "memory"),
+			RedisURL:  l.getSecretWithDefault("REDIS_URL", ""),
+			KeyPrefix: l.getEnvWithDefault("RATE_LIMIT_KEY_PREFIX", "linkinsync:ratelimit:"),
+			Rules:     l.getEnvWithDefault("RATE_LIMITS", DefaultRateLimits),
+		},
+		WebSocket: WebSocketConfig{
+			MaxMessageBytes:   l.getEnvInt("WS_MAX_MESSAGE_BYTES", 4096),
+			MessagesPerMinute: l.getEnvInt("WS_MESSAGES_PER_MINUTE", 60),
+			PingInterval:      l.getEnvDuration("WS_PING_INTERVAL", 30*time.Second),
+			TypingTimeout:     l.getEnvDuration("WS_TYPING_TIMEOUT", 5*time.Second),
+			ChatFanout:        l.getEnvWithDefault("WS_CHAT_FANOUT", "memory"),
+		},
+		Presence: PresenceConfig{
+			Timeout: l.getEnvDuration("PRESENCE_TIMEOUT", 60*time.Second),
+		},
+		Avatar: AvatarConfig{
+			Storage:     l.getEnvWithDefault("AVATAR_STORAGE", "local"),
+			Dir:         l.getEnvWithDefault("AVATAR_DIR", "./data/avatars"),
+			MaxBytes:    l.getEnvInt("AVATAR_MAX_BYTES", 2<<20),
+			S3Endpoint:  l.getEnvWithDefault("AVATAR_S3_ENDPOINT", ""),
+			S3Bucket:    l.getEnvWithDefault("AVATAR_S3_BUCKET", ""),
+			S3Region:    l.getEnvWithDefault("AVATAR_S3_REGION", "us-east-1"),
+			S3AccessKey: l.getSecretWithDefault("AVATAR_S3_ACCESS_KEY_ID", ""),
+			S3SecretKey: l.getSecretWithDefault("AVATAR_S3_SECRET_ACCESS_KEY", ""),
+		},
+		Export: ExportConfig{
+			Dir: l.getEnvWithDefault("EXPORT_DIR", "./data/exports"),
+			TTL: l.getEnvDuration("EXPORT_TTL", 24*time.Hour),
+		},
+		Auth: AuthConfig{
+			APIKeys: l.getSecretList("API_KEYS"),
+		},
+		Encryption: EncryptionConfig{
+			MasterKey: l.getSecretBase64("ENCRYPTION_MASTER_KEY"),
+		},
+		TLS: TLSConfig{
+			CertFile:         l.getEnvWithDefault("TLS_CERT_FILE", ""),
+			KeyFile:          l.getEnvWithDefault("TLS_KEY_FILE", ""),
+			AutocertHosts:    l.getEnvList("TLS_AUTOCERT_HOSTS"),
+			AutocertEmail:    l.getEnvWithDefault("TLS_AUTOCERT_EMAIL", ""),
+			AutocertCacheDir: l.getEnvWithDefault("TLS_AUTOCERT_CACHE_DIR", "./data/autocert"),
+			AutocertURL:      l.getEnvWithDefault("TLS_AUTOCERT_URL", "https://acme-v02.api.letsencrypt.org/directory"),
+			RedirectPort:     l.getEnvWithDefault("TLS_REDIRECT_PORT", "80"),
+		},
+		Topics: TopicsConfig{
+			Interval:       l.getEnvDuration("TOPICS_INTERVAL", time.Hour),
+			Window:         l.getEnvDuration("TOPICS_WINDOW", 24*time.Hour),
+			Embedder:       l.getEnvWithDefault("TOPICS_EMBEDDER", ""),
+			EmbeddingModel: l.getEnvWithDefault("TOPICS_EMBEDDING_MODEL", ""),
+			Similarity:     l.getEnvFloat("TOPICS_SIMILARITY", 0),
+			MinClusterSize: l.getEnvInt("TOPICS_MIN_CLUSTER_SIZE", 3),
+			Digest:         l.getEnvBool("TOPICS_DIGEST", false),
+		},
+		Quiz: QuizConfig{
+			Rounds:     l.getEnvInt("QUIZ_ROUNDS", 5),
+			AnswerTime: l.getEnvDuration("QUIZ_ANSWER_TIME", 20*time.Second),
+			RevealTime: l.getEnvDuration("QUIZ_REVEAL_TIME", 5*time.Second),
+		},
+		Trivia: TriviaConfig{
+			Questions:  l.getEnvInt("TRIVIA_QUESTIONS", 5),
+			SessionTTL: l.getEnvDuration("TRIVIA_SESSION_TTL", 30*time.Minute),
+		},
+		Webhooks: WebhooksConfig{
+			MaxAttempts:     l.getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+			Timeout:         l.getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			DeliveryLogSize: l.getEnvInt("WEBHOOK_DELIVERY_LOG_SIZE", 50),
+		},
+		Trending: TrendingConfig{
+			Window:  l.getEnvDuration("TRENDING_WINDOW", time.Hour),
+			Buckets: l.getEnvInt("TRENDING_BUCKETS", 60),
+		},
+		Safety: SafetyConfig{
+			Helplines:     l.getEnvWithDefault("SAFETY_HELPLINES", DefaultHelplines),
+			DefaultRegion: strings.ToUpper(l.getEnvWithDefault("SAFETY_DEFAULT_REGION", "")),
+		},
+		Moderation: ModerationConfig{
+			Classifier:      l.getEnvWithDefault("MODERATION_CLASSIFIER", ""),
+			Model:           l.getEnvWithDefault("MODERATION_MODEL", "omni-moderation-latest"),
+			Categories:      l.getEnvListWithDefault("MODERATION_CATEGORIES", []string{"hate", "hate/threatening", "harassment", "harassment/threatening"}),
+			FlagThreshold:   l.getEnvFloat("MODERATION_FLAG_THRESHOLD", 0.5),
+			RejectThreshold: l.getEnvFloat("MODERATION_REJECT_THRESHOLD", 0.9),
+		},
+		Customize: l.loadCustomization(),
+	}
+
+	if err := cfg.resolveAIProvider(); err != nil {
+		l.problems = append(l.problems, err.Error())
 	}
+	l.problems = append(l.problems, cfg.rangeProblems()...)
 
+	if len(l.problems) > 0 {
+		return nil, &ValidationError{Problems: l.problems}
+	}
 	return cfg, nil
 }
 
+// ValidationError lists every missing or invalid setting found while loading
+type ValidationError struct {
+	Problems []string
+}
+
+// Error describes all problems, one per line
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// loader reads settings and collects problems instead of stopping at the first
+type loader struct {
+	problems []string
+}
+
+// addProblem records a missing or invalid setting
+func (l *loader) addProblem(format string, args ...interface{}) {
+	l.problems = append(l.problems, fmt.Sprintf(format, args...))
+}
+
+// rangeProblems checks numeric settings are within their valid ranges
+func (c *Config) rangeProblems() []string {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	ports := map[string]string{"PORT": c.Server.Port, "DB_PORT": c.Database.Port}
+	if c.TLS.Enabled() && c.TLS.RedirectPort != "" {
+		ports["TLS_REDIRECT_PORT"] = c.TLS.RedirectPort
+	}
+	for key, port := range ports {
+		number, err := strconv.Atoi(port)
+		check(err == nil && number >= 1 && number <= 65535, "%s must be a port between 1 and 65535, got %q", key, port)
+	}
+
+	for key, timeout := range map[string]time.Duration{
+		"SERVER_READ_TIMEOUT":        c.Server.ReadTimeout,
+		"SERVER_READ_HEADER_TIMEOUT": c.Server.ReadHeaderTimeout,
+		"SERVER_WRITE_TIMEOUT":       c.Server.WriteTimeout,
+		"SERVER_IDLE_TIMEOUT":        c.Server.IdleTimeout,
+	} {
+		check(timeout > 0, "%s must be positive", key)
+	}
+	check(c.Database.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative, got %d", c.Database.MaxOpenConns)
+	check(c.Database.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative, got %d", c.Database.MaxIdleConns)
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	check(c.Database.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative")
+	check(c.Server.MaxHeaderBytes >= 4096, "SERVER_MAX_HEADER_BYTES must be at least 4096, got %d", c.Server.MaxHeaderBytes)
+	check(c.Server.ReadHeaderTimeout <= c.Server.ReadTimeout, "SERVER_READ_HEADER_TIMEOUT (%s) must not exceed SERVER_READ_TIMEOUT (%s)", c.Server.ReadHeaderTimeout, c.Server.ReadTimeout)
+	check(c.OpenAI.Temperature <= 2, "OPENAI_TEMPERATURE must be between 0 and 2, got %v", c.OpenAI.Temperature)
+	check(c.Ollama.Temperature <= 2, "OLLAMA_TEMPERATURE must be between 0 and 2, got %v", c.Ollama.Temperature)
+	check(c.Anthropic.Temperature <= 1, "ANTHROPIC_TEMPERATURE must be between 0 and 1, got %v", c.Anthropic.Temperature)
+	check(c.OpenAI.TopP <= 1, "OPENAI_TOP_P must be between 0 and 1, got %v", c.OpenAI.TopP)
+	check(c.Ollama.TopP <= 1, "OLLAMA_TOP_P must be between 0 and 1, got %v", c.Ollama.TopP)
+	check(c.Anthropic.TopP <= 1, "ANTHROPIC_TOP_P must be between 0 and 1, got %v", c.Anthropic.TopP)
+	check(c.Ollama.TopK >= 1, "OLLAMA_TOP_K must be at least 1, got %d", c.Ollama.TopK)
+	check(c.OpenAI.MaxTokens >= 1 && c.OpenAI.MaxTokens <= 128000, "OPENAI_MAX_TOKENS must be between 1 and 128000, got %d", c.OpenAI.MaxTokens)
+	check(c.Anthropic.MaxTokens >= 1 && c.Anthropic.MaxTokens <= 128000, "ANTHROPIC_MAX_TOKENS must be between 1 and 128000, got %d", c.Anthropic.MaxTokens)
+	check(c.OpenAI.MaxRetries <= 10, "OPENAI_MAX_RETRIES must be at most 10, got %d", c.OpenAI.MaxRetries)
+	check(c.OpenAI.RetryBaseDelay <= c.OpenAI.RetryMaxDelay, "OPENAI_RETRY_BASE_DELAY (%s) must not exceed OPENAI_RETRY_MAX_DELAY (%s)", c.OpenAI.RetryBaseDelay, c.OpenAI.RetryMaxDelay)
+	check(c.OpenAI.UserDailyTokenBudget == 0 || c.OpenAI.DailyTokenBudget == 0 || c.OpenAI.UserDailyTokenBudget <= c.OpenAI.DailyTokenBudget,
+		"OPENAI_USER_DAILY_TOKEN_BUDGET (%d) must not exceed OPENAI_DAILY_TOKEN_BUDGET (%d)", c.OpenAI.UserDailyTokenBudget, c.OpenAI.DailyTokenBudget)
+	check(c.OpenAI.BudgetFallback == "none" || c.OpenAI.BudgetFallback == "ollama", "OPENAI_BUDGET_FALLBACK must be ollama or none, got %q", c.OpenAI.BudgetFallback)
+	check(c.AI.LyricsChunkTokens >= 100, "LYRICS_CHUNK_TOKENS must be at least 100, got %d", c.AI.LyricsChunkTokens)
+	check(c.AI.LyricsTokenBudget == 0 || c.AI.LyricsTokenBudget >= 200, "LYRICS_TOKEN_BUDGET must be 0 or at least 200, got %d", c.AI.LyricsTokenBudget)
+	check(c.Breaker.OpenTimeout > 0, "BREAKER_OPEN_TIMEOUT must be positive")
+	check(c.Jobs.CatalogValidationInterval > 0, "CATALOG_VALIDATION_INTERVAL must be positive")
+	check(c.Jobs.WeeklyReportsInterval > 0, "WEEKLY_REPORTS_INTERVAL must be positive")
+	check(c.Jobs.Jitter <= 1, "JOBS_JITTER must be between 0 and 1, got %v", c.Jobs.Jitter)
+	check(c.Jobs.QueueWorkers >= 1 && c.Jobs.QueueWorkers <= 32, "JOB_QUEUE_WORKERS must be between 1 and 32, got %d", c.Jobs.QueueWorkers)
+	check(c.Jobs.QueueMaxAttempts >= 1 && c.Jobs.QueueMaxAttempts <= 10, "JOB_QUEUE_MAX_ATTEMPTS must be between 1 and 10, got %d", c.Jobs.QueueMaxAttempts)
+	check(c.Breaker.FailureThreshold >= 1, "BREAKER_FAILURE_THRESHOLD must be at least 1, got %d", c.Breaker.FailureThreshold)
+	check(c.Genius.LyricsCacheTTL > 0, "LYRICS_CACHE_TTL must be positive")
+	check(c.Genius.FactsCacheTTL > 0, "SONG_FACTS_CACHE_TTL must be positive")
+	check(c.Genius.AlbumCacheTTL > 0, "ALBUM_ANALYSIS_CACHE_TTL must be positive")
+	check(c.Genius.AlbumCacheMax >= 1, "ALBUM_ANALYSIS_CACHE_SIZE must be at least 1, got %d", c.Genius.AlbumCacheMax)
+	check(c.Genius.StyleCacheTTL > 0, "ARTIST_STYLE_CACHE_TTL must be positive")
+	check(c.AI.AnalysisCacheTTL >= 0, "LYRICS_ANALYSIS_CACHE_TTL must not be negative")
+	check(c.AI.AnalysisRejectAfter >= 1, "LYRICS_ANALYSIS_REJECT_AFTER must be at least 1, got %d", c.AI.AnalysisRejectAfter)
+	check(c.AI.AnalysisReanalyzeInterval >= 0, "LYRICS_ANALYSIS_REANALYZE_INTERVAL must not be negative")
+	check(c.History.ScrobbleFraction <= 1, "HISTORY_SCROBBLE_FRACTION must be between 0 and 1, got %v", c.History.ScrobbleFraction)
+	check(c.History.ScrobbleAfter > 0, "HISTORY_SCROBBLE_AFTER must be positive")
+	check(c.History.Backend == "memory" || c.History.Backend == "postgres", "HISTORY_BACKEND must be memory or postgres, got %q", c.History.Backend)
+	check(c.History.RepeatWindow >= 0, "HISTORY_REPEAT_WINDOW must not be negative")
+	check(c.History.Size >= 1 && c.History.Size <= 10000, "HISTORY_SIZE must be between 1 and 10000, got %d", c.History.Size)
+	check(c.State.Backend == "memory" || c.State.Backend == "redis", "STATE_BACKEND must be memory or redis, got %q", c.State.Backend)
+	check(c.State.Backend != "redis" || c.State.RedisURL != "", "REDIS_URL is required when STATE_BACKEND is redis")
+	check(c.Retention.Interval > 0, "RETENTION_INTERVAL must be positive")
+	check(c.Retention.MoodHistory >= 0, "RETENTION_MOOD_HISTORY must not be negative")
+	check(c.Retention.Messages >= 0, "RETENTION_MESSAGES must not be negative")
+	check(len(c.Encryption.MasterKey) == 0 || len(c.Encryption.MasterKey) >= 32, "ENCRYPTION_MASTER_KEY must decode to at least 32 bytes, got %d", len(c.Encryption.MasterKey))
+	check(c.Trending.Window > 0, "TRENDING_WINDOW must be positive")
+	check(c.Trending.Buckets >= 1 && c.Trending.Buckets <= 3600, "TRENDING_BUCKETS must be between 1 and 3600, got %d", c.Trending.Buckets)
+	check(c.RateLimit.Backend == "memory" || c.RateLimit.Backend == "redis", "RATE_LIMIT_BACKEND must be memory or redis, got %q", c.RateLimit.Backend)
+	check(c.RateLimit.Backend != "redis" || c.RateLimit.RedisURL != "", "REDIS_URL is required when RATE_LIMIT_BACKEND is redis")
+	check(c.Events.MaxAttempts >= 1 && c.Events.MaxAttempts <= 10, "EVENT_STREAM_MAX_ATTEMPTS must be between 1 and 10, got %d", c.Events.MaxAttempts)
+	check(c.Events.DeliveryLogSize >= 1, "EVENT_STREAM_DELIVERY_LOG_SIZE must be at least 1, got %d", c.Events.DeliveryLogSize)
+	check(c.WebSocket.MaxMessageBytes >= 128 && c.WebSocket.MaxMessageBytes <= 1<<20, "WS_MAX_MESSAGE_BYTES must be between 128 and 1048576, got %d", c.WebSocket.MaxMessageBytes)
+	check(c.WebSocket.PingInterval >= time.Second, "WS_PING_INTERVAL must be at least 1s, got %s", c.WebSocket.PingInterval)
+	check(c.WebSocket.TypingTimeout >= time.Second, "WS_TYPING_TIMEOUT must be at least 1s, got %s", c.WebSocket.TypingTimeout)
+	check(c.WebSocket.ChatFanout == "memory" || c.WebSocket.ChatFanout == "postgres", "WS_CHAT_FANOUT must be memory or postgres, got %q", c.WebSocket.ChatFanout)
+	check(c.Presence.Timeout >= 5*time.Second, "PRESENCE_TIMEOUT must be at least 5s, got %s", c.Presence.Timeout)
+	check(c.Avatar.Storage == "local" || c.Avatar.Storage == "s3", "AVATAR_STORAGE must be local or s3, got %q", c.Avatar.Storage)
+	check(c.Avatar.Storage != "s3" || (c.Avatar.S3Bucket != "" && c.Avatar.S3AccessKey != "" && c.Avatar.S3SecretKey != ""),
+		"AVATAR_S3_BUCKET, AVATAR_S3_ACCESS_KEY_ID and AVATAR_S3_SECRET_ACCESS_KEY are required when AVATAR_STORAGE is s3")
+	check(c.Avatar.MaxBytes >= 1024 && c.Avatar.MaxBytes <= 20<<20, "AVATAR_MAX_BYTES must be between 1024 and 20971520, got %d", c.Avatar.MaxBytes)
+	check(c.Export.TTL >= time.Minute, "EXPORT_TTL must be at least 1m, got %s", c.Export.TTL)
+	for _, origin := range c.Server.AllowedOrigins {
+		check(strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://"), "ALLOWED_ORIGINS entries must start with http:// or https://, got %q", origin)
+	}
+	check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""), "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	check(c.TLS.CertFile == "" || len(c.TLS.AutocertHosts) == 0, "TLS_CERT_FILE and TLS_AUTOCERT_HOSTS are mutually exclusive")
+	check(len(c.TLS.AutocertHosts) == 0 || c.TLS.RedirectPort != "", "TLS_REDIRECT_PORT is required with TLS_AUTOCERT_HOSTS to answer ACME challenges")
+	if c.TLS.Enabled() && c.TLS.RedirectPort != "" {
+		check(c.TLS.RedirectPort != c.Server.Port, "TLS_REDIRECT_PORT must differ from PORT, both are %s", c.Server.Port)
+	}
+	check(c.Topics.Interval > 0, "TOPICS_INTERVAL must be positive")
+	check(c.Topics.Window > 0, "TOPICS_WINDOW must be positive")
+	check(c.Topics.Embedder == "" || c.Topics.Embedder == "openai" || c.Topics.Embedder == "ollama" || c.Topics.Embedder == "local",
+		"TOPICS_EMBEDDER must be openai, ollama or local, got %q", c.Topics.Embedder)
+	check(c.Topics.Similarity <= 1, "TOPICS_SIMILARITY must be between 0 and 1, got %v", c.Topics.Similarity)
+	check(c.Topics.MinClusterSize >= 2, "TOPICS_MIN_CLUSTER_SIZE must be at least 2, got %d", c.Topics.MinClusterSize)
+	check(c.Quiz.Rounds >= 1 && c.Quiz.Rounds <= 50, "QUIZ_ROUNDS must be between 1 and 50, got %d", c.Quiz.Rounds)
+	check(c.Quiz.AnswerTime >= time.Second, "QUIZ_ANSWER_TIME must be at least 1s, got %s", c.Quiz.AnswerTime)
+	check(c.Quiz.RevealTime >= 0, "QUIZ_REVEAL_TIME must not be negative, got %s", c.Quiz.RevealTime)
+	check(c.Trivia.Questions >= 1 && c.Trivia.Questions <= 10, "TRIVIA_QUESTIONS must be between 1 and 10, got %d", c.Trivia.Questions)
+	check(c.Trivia.SessionTTL > 0, "TRIVIA_SESSION_TTL must be positive, got %s", c.Trivia.SessionTTL)
+	check(c.Webhooks.MaxAttempts >= 1 && c.Webhooks.MaxAttempts <= 10, "WEBHOOK_MAX_ATTEMPTS must be between 1 and 10, got %d", c.Webhooks.MaxAttempts)
+	check(c.Webhooks.Timeout >= time.Second, "WEBHOOK_TIMEOUT must be at least 1s, got %s", c.Webhooks.Timeout)
+	check(c.Webhooks.DeliveryLogSize >= 1, "WEBHOOK_DELIVERY_LOG_SIZE must be at least 1, got %d", c.Webhooks.DeliveryLogSize)
+	check(c.Events.StreamBackend == "" || c.Events.StreamURL != "", "EVENT_STREAM_URL is required when EVENT_STREAM_BACKEND is set")
+	check(c.Safety.DefaultRegion == "" || (len(c.Safety.DefaultRegion) == 2 && strings.Trim(c.Safety.DefaultRegion, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == ""), "SAFETY_DEFAULT_REGION must be a two-letter country code, got %q", c.Safety.DefaultRegion)
+	check(c.Moderation.Classifier == "" || c.Moderation.Classifier == "openai" || c.Moderation.Classifier == "local" || c.Moderation.Classifier == "off",
+		"MODERATION_CLASSIFIER must be openai, local or off, got %q", c.Moderation.Classifier)
+	check(c.Moderation.Classifier != "openai" || c.OpenAI.APIKey != "", "OPENAI_API_KEY is required with MODERATION_CLASSIFIER=openai")
+	check(c.Moderation.FlagThreshold > 0 && c.Moderation.FlagThreshold <= 1, "MODERATION_FLAG_THRESHOLD must be between 0 and 1, got %v", c.Moderation.FlagThreshold)
+	check(c.Moderation.RejectThreshold >= c.Moderation.FlagThreshold, "MODERATION_REJECT_THRESHOLD must be at least MODERATION_FLAG_THRESHOLD, got %v", c.Moderation.RejectThreshold)
+	c.Customize.problems(check)
+
+	sort.Strings(problems)
+	return problems
+}
+
+// hostedAIProviders are the providers that can be detected from their credentials.
+// Ollama needs none, so it is only used when AI_PROVIDER selects it.
+var hostedAIProviders = []string{"openai", "azure", "anthropic"}
+
+// resolveAIProvider checks that the AI provider named by AI_PROVIDER is fully
+// configured. If AI_PROVIDER is unset, exactly one hosted provider must have
+// credentials and it is selected.
+func (c *Config) resolveAIProvider() error {
+	if c.AI.Provider != "" {
+		missing, known := c.missingAISettings(c.AI.Provider)
+		if !known {
+			return fmt.Errorf("unknown AI_PROVIDER %q (expected openai, azure, anthropic or ollama)", c.AI.Provider)
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("AI provider %s is not fully configured: missing %s", c.AI.Provider, strings.Join(missing, ", "))
+		}
+		return nil
+	}
+
+	var configured []string
+	for _, provider := range hostedAIProviders {
+		if missing, _ := c.missingAISettings(provider); len(missing) == 0 {
+			configured = append(configured, provider)
+		}
+	}
+
+	switch len(configured) {
+	case 1:
+		c.AI.Provider = configured[0]
+		return nil
+	case 0:
+		return fmt.Errorf("no AI provider configured: set OPENAI_API_KEY, the AZURE_OPENAI_* variables or ANTHROPIC_API_KEY, or AI_PROVIDER=ollama")
+	default:
+		return fmt.Errorf("multiple AI providers configured (%s): set AI_PROVIDER to choose one", strings.Join(configured, ", "))
+	}
+}
+
+// missingAISettings returns the environment variables a provider still needs,
+// and false if the provider is unknown
+func (c *Config) missingAISettings(provider string) ([]string, bool) {
+	var missing []string
+	require := func(value, key string) {
+		if value == "" {
+			missing = append(missing, key)
+		}
+	}
+
+	switch provider {
+	case "openai":
+		require(c.OpenAI.APIKey, "OPENAI_API_KEY")
+		require(c.OpenAI.Model, "OPENAI_MODEL")
+		require(c.OpenAI.BaseURL, "OPENAI_BASE_URL")
+	case "azure":
+		require(c.Azure.APIKey, "AZURE_OPENAI_API_KEY")
+		require(c.Azure.Endpoint, "AZURE_OPENAI_ENDPOINT")
+		require(c.Azure.Deployment, "AZURE_OPENAI_DEPLOYMENT")
+	case "anthropic":
+		require(c.Anthropic.APIKey, "ANTHROPIC_API_KEY")
+		require(c.Anthropic.Model, "ANTHROPIC_MODEL")
+	case "ollama":
+		require(c.Ollama.BaseURL, "OLLAMA_BASE_URL")
+		require(c.Ollama.Model, "OLLAMA_MODEL")
+	default:
+		return nil, false
+	}
+	return missing, true
+}
+
 // getEnvRequired gets a required environment variable
-func getEnvRequired(key string) string {
-	value := os.Getenv(key)
+func (l *loader) getEnvRequired(key string) string {
+	value := lookupEnv(key)
 	if value == "" {
-		panic(fmt.Sprintf("Required environment variable %s is not set", key))
+		l.addProblem("%s is required but not set", key)
 	}
 	return value
 }
 
 // getEnvWithDefault gets an environment variable with a default value
-func getEnvWithDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+func (l *loader) getEnvWithDefault(key, defaultValue string) string {
+	if value := lookupEnv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
+// getEnvDuration gets a duration environment variable (e.g. "30m", "24h") with a default value
+func (l *loader) getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := lookupEnv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		l.addProblem("%s must be a non-negative duration such as \"30s\" or \"1h\", got %q", key, value)
+		return defaultValue
+	}
+	return duration
+}
+
+// getEnvBool gets a boolean environment variable ("true", "false", "1", "0", ...) with a default value
+func (l *loader) getEnvBool(key string, defaultValue bool) bool {
+	value := lookupEnv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		l.addProblem("%s must be true or false, got %q", key, value)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvList gets a comma-separated list environment variable
+func (l *loader) getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(lookupEnv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvListWithDefault gets a comma-separated list environment variable, using
+// defaultValue when it is unset or empty
+func (l *loader) getEnvListWithDefault(key string, defaultValue []string) []string {
+	if values := l.getEnvList(key); len(values) > 0 {
+		return values
+	}
+	return defaultValue
+}
+
+// getEnvInt gets a non-negative integer environment variable with a default value
+func (l *loader) getEnvInt(key string, defaultValue int) int {
+	value := lookupEnv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		l.addProblem("%s must be a non-negative integer, got %q", key, value)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvFloat gets a non-negative float environment variable with a default value
+func (l *loader) getEnvFloat(key string, defaultValue float64) float64 {
+	value := lookupEnv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 {
+		l.addProblem("%s must be a non-negative number, got %q", key, value)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvPriorities parses a "source=priority,source=priority" environment variable
+func (l *loader) getEnvPriorities(key string) map[string]int {
+	priorities := make(map[string]int)
+	for _, pair := range strings.Split(lookupEnv(key), ",") {
+		source, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			continue
+		}
+		priority, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			l.addProblem("%s has an invalid priority %q for source %s", key, value, source)
+			continue
+		}
+		priorities[strings.TrimSpace(source)] = priority
+	}
+	return priorities
+}
+
+// getEnvFiles parses a "name=path,name=path" environment variable
+func (l *loader) getEnvFiles(key string) map[string]string {
+	files := make(map[string]string)
+	for _, pair := range l.getEnvList(key) {
+		name, path, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(name) == "" || strings.TrimSpace(path) == "" {
+			l.addProblem("%s has an invalid entry %q (expected name=path)", key, pair)
+			continue
+		}
+		files[strings.TrimSpace(name)] = strings.TrimSpace(path)
+	}
+	return files
+}
+
+// getEnvExperiments parses a "template=variant:weight/variant:weight,..."
+// environment variable of prompt experiments; weights default to 1
+func (l *loader) getEnvExperiments(key string) map[string][]PromptVariant {
+	experiments := make(map[string][]PromptVariant)
+	for _, entry := range l.getEnvList(key) {
+		template, value, found := strings.Cut(entry, "=")
+		template = strings.TrimSpace(template)
+		if !found || template == "" {
+			l.addProblem("%s has an invalid experiment %q (expected template=variant:weight/variant:weight)", key, entry)
+			continue
+		}
+		var variants []PromptVariant
+		for _, arm := range strings.Split(value, "/") {
+			name, weight, hasWeight := strings.Cut(strings.TrimSpace(arm), ":")
+			variant := PromptVariant{Name: strings.TrimSpace(name), Weight: 1}
+			if hasWeight {
+				parsed, err := strconv.Atoi(strings.TrimSpace(weight))
+				if err != nil || parsed < 1 {
+					l.addProblem("%s has an invalid weight %q for variant %s of %s", key, weight, variant.Name, template)
+					continue
+				}
+				variant.Weight = parsed
+			}
+			if variant.Name == "" {
+				l.addProblem("%s has an empty variant name in %s", key, template)
+				continue
+			}
+			variants = append(variants, variant)
+		}
+		experiments[template] = variants
+	}
+	return experiments
+}
+
+// getEnvPrices parses a "model=input/output,model=input/output" environment
+// variable of USD prices per million tokens
+func (l *loader) getEnvPrices(key string) map[string]ModelPrice {
+	prices := make(map[string]ModelPrice)
+	for _, pair := range strings.Split(lookupEnv(key), ",") {
+		model, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			continue
+		}
+		input, output, found := strings.Cut(strings.TrimSpace(value), "/")
+		inputPrice, inputErr := strconv.ParseFloat(input, 64)
+		outputPrice, outputErr := strconv.ParseFloat(output, 64)
+		if !found || inputErr != nil || outputErr != nil || inputPrice < 0 || outputPrice < 0 {
+			l.addProblem("%s has an invalid price %q for model %s (expected input/output)", key, value, model)
+			continue
+		}
+		prices[strings.TrimSpace(model)] = ModelPrice{Input: inputPrice, Output: outputPrice}
+	}
+	return prices
+}
+
 // GetDatabaseURL returns the formatted database connection string
 func (c *Config) GetDatabaseURL() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		c.Database.User,
-		c.Database.Password,
-		c.Database.Host,
-		c.Database.Port,
-		c.Database.DBName,
-		c.Database.SSLMode,
-	)
-}
\ No newline at end of file
+	return c.Database.URL()
+}
+
+// URL returns the database's postgres:// connection string
+func (d DatabaseConfig) URL() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     d.Host + ":" + d.Port,
+		Path:     "/" + d.DBName,
+		RawQuery: "sslmode=" + url.QueryEscape(d.SSLMode),
+	}
+	return u.String()
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// CustomizationConfig lets a deployment brand the assistant and replace its
+// canned answers without changing handler code
+type CustomizationConfig struct {
+	AssistantName string            // Shown as the author of system messages and served to frontends
+	Tagline       string            // Optional; served to frontends
+	LogoURL       string            // Optional absolute http(s) URL; served to frontends
+	PrimaryColor  string            // Optional #rrggbb; served to frontends
+	Responses     map[string]string // Replacement answers keyed by CustomResponses entries
+}
+
+// CustomResponses are the answers a deployment may replace, each set by
+// RESPONSES_ and the upper-cased key, e.g. RESPONSES_MUSIC_ONLY. Every answer
+// may use {assistant}; empathetic ones may also use {mood}.
+var CustomResponses = []string{
+	"music_only",          // Refusal of questions that aren't about music
+	"no_song_playing",     // Lyrics questions while nothing is playing
+	"explicit_restricted", // Lyrics questions about explicit songs in restricted mode
+	"empathetic_sad",
+	"empathetic_happy",
+	"empathetic_angry",
+	"empathetic_lonely",
+	"empathetic_anxious",
+	"empathetic_nostalgic",
+	"empathetic_energetic",
+	"empathetic_calm",
+	"empathetic_default", // Introduces recommendations for any other mood
+}
+
+// maxCustomResponseLength caps replacement answers, in bytes
+const maxCustomResponseLength = 1000
+
+var (
+	hexColorPattern    = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+)
+
+// loadCustomization reads branding and the replacement answers that are set
+func (l *loader) loadCustomization() CustomizationConfig {
+	customization := CustomizationConfig{
+		AssistantName: l.getEnvWithDefault("BRANDING_ASSISTANT_NAME", "LinkinSync"),
+		Tagline:       l.getEnvWithDefault("BRANDING_TAGLINE", ""),
+		LogoURL:       l.getEnvWithDefault("BRANDING_LOGO_URL", ""),
+		PrimaryColor:  l.getEnvWithDefault("BRANDING_PRIMARY_COLOR", ""),
+		Responses:     make(map[string]string),
+	}
+	for _, key := range CustomResponses {
+		if response := l.getEnvWithDefault("RESPONSES_"+strings.ToUpper(key), ""); response != "" {
+			customization.Responses[key] = response
+		}
+	}
+	return customization
+}
+
+// problems checks branding values and the placeholders of replacement answers
+func (c *CustomizationConfig) problems(check func(ok bool, format string, args ...interface{})) {
+	check(strings.TrimSpace(c.AssistantName) != "" && len(c.AssistantName) <= 50, "BRANDING_ASSISTANT_NAME must be between 1 and 50 characters, got %q", c.AssistantName)
+	check(c.PrimaryColor == "" || hexColorPattern.MatchString(c.PrimaryColor), "BRANDING_PRIMARY_COLOR must be a #rrggbb color, got %q", c.PrimaryColor)
+	if c.LogoURL != "" {
+		parsed, err := url.Parse(c.LogoURL)
+		check(err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "", "BRANDING_LOGO_URL must be an absolute http or https URL, got %q", c.LogoURL)
+	}
+
+	for key, response := range c.Responses {
+		name := "RESPONSES_" + strings.ToUpper(key)
+		check(len(response) <= maxCustomResponseLength, "%s must be at most %d bytes, got %d", name, maxCustomResponseLength, len(response))
+		for _, placeholder := range placeholderPattern.FindAllString(response, -1) {
+			allowed := placeholder == "{assistant}" || (placeholder == "{mood}" && strings.HasPrefix(key, "empathetic_"))
+			check(allowed, "%s uses unknown placeholder %s", name, placeholder)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileValues holds settings from the config file, keyed by environment
+// variable name. Environment variables take precedence over them.
+var fileValues = map[string]string{}
+
+// lookupEnv returns an environment variable, falling back to the config file
+func lookupEnv(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fileValues[key]
+}
+
+// loadConfigFile reads a JSON or YAML config file. Nested keys are joined with
+// underscores and upper-cased to form the environment variable they set, so
+//
+//	openai:
+//	  model: gpt-4o
+//	  temperature: 0.5
+//
+// is equivalent to OPENAI_MODEL=gpt-4o and OPENAI_TEMPERATURE=0.5.
+func loadConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := make(map[string]string)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var tree map[string]interface{}
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		flattenJSON("", tree, values)
+	case ".yaml", ".yml":
+		if err := parseYAML(string(data), values); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file type %q (expected .json, .yaml or .yml)", filepath.Ext(path))
+	}
+	return values, nil
+}
+
+// flattenJSON flattens nested JSON objects into environment variable names
+func flattenJSON(prefix string, tree map[string]interface{}, values map[string]string) {
+	for key, value := range tree {
+		name := envName(prefix, key)
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenJSON(name, v, values)
+		case nil:
+		default:
+			values[name] = fmt.Sprint(v)
+		}
+	}
+}
+
+// parseYAML parses the subset of YAML used for config files: nested mappings
+// of scalar values, with comments and optional quotes
+func parseYAML(data string, values map[string]string) error {
+	type level struct {
+		indent int
+		name   string
+	}
+	var stack []level
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+
+		key, value, found := strings.Cut(trimmed, ":")
+		if !found {
+			return fmt.Errorf("line %d: expected \"key: value\"", lineNumber)
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		prefix := ""
+		if len(stack) > 0 {
+			prefix = stack[len(stack)-1].name
+		}
+		name := envName(prefix, strings.TrimSpace(key))
+
+		value = strings.TrimSpace(value)
+		if !strings.HasPrefix(value, "\"") && !strings.HasPrefix(value, "'") {
+			if comment := strings.Index(value, " #"); comment >= 0 {
+				value = strings.TrimSpace(value[:comment])
+			}
+		}
+		if value == "" {
+			stack = append(stack, level{indent: indent, name: name})
+			continue
+		}
+		values[name] = strings.Trim(value, "\"'")
+	}
+	return scanner.Err()
+}
+
+// envName joins a config file key onto its parent as an environment variable name
+func envName(prefix, key string) string {
+	name := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Secret references let credentials live outside plain environment variables.
+// Any secret setting may hold one of:
+//
+//	file:///run/secrets/genius_token          contents of a file
+//	docker-secret://genius_token              a Docker secret, from DOCKER_SECRETS_DIR (/run/secrets)
+//	vault://secret/data/linkinsync#genius     a field of a Vault KV secret, using VAULT_ADDR and VAULT_TOKEN
+//	awssm://prod/linkinsync#genius            AWS Secrets Manager, using the AWS_* credentials and AWS_REGION
+//
+// The #field suffix selects a key from a JSON secret; without it an AWS secret
+// is used as-is.
+const (
+	schemeFile         = "file://"
+	schemeDockerSecret = "docker-secret://"
+	schemeVault        = "vault://"
+	schemeAWS          = "awssm://"
+)
+
+// secretTimeout bounds each request to a secret manager
+const secretTimeout = 10 * time.Second
+
+var secretClient = &http.Client{Timeout: secretTimeout}
+
+// getSecretRequired gets a required secret, resolving secret references
+func (l *loader) getSecretRequired(key string) string {
+	value := l.getEnvRequired(key)
+	return l.resolveSecret(key, value)
+}
+
+// getSecretWithDefault gets a secret with a default value, resolving secret references
+func (l *loader) getSecretWithDefault(key, defaultValue string) string {
+	value := l.getEnvWithDefault(key, defaultValue)
+	return l.resolveSecret(key, value)
+}
+
+// getSecretBase64 gets an optional base64-encoded secret, resolving secret references
+func (l *loader) getSecretBase64(key string) []byte {
+	value := l.getSecretWithDefault(key, "")
+	if value == "" {
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		l.addProblem("%s must be base64-encoded: %v", key, err)
+		return nil
+	}
+	return decoded
+}
+
+// getSecretList gets an optional comma-separated list of secrets, resolving
+// a secret reference to the whole list
+func (l *loader) getSecretList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(l.getSecretWithDefault(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// resolveSecret replaces a secret reference with the secret it names.
+// Values that aren't references are returned unchanged.
+func (l *loader) resolveSecret(key, value string) string {
+	var secret string
+	var err error
+	switch {
+	case strings.HasPrefix(value, schemeFile):
+		secret, err = readSecretFile(strings.TrimPrefix(value, schemeFile))
+	case strings.HasPrefix(value, schemeDockerSecret):
+		dir := l.getEnvWithDefault("DOCKER_SECRETS_DIR", "/run/secrets")
+		secret, err = readSecretFile(filepath.Join(dir, strings.TrimPrefix(value, schemeDockerSecret)))
+	case strings.HasPrefix(value, schemeVault):
+		secret, err = l.readVaultSecret(strings.TrimPrefix(value, schemeVault))
+	case strings.HasPrefix(value, schemeAWS):
+		secret, err = l.readAWSSecret(strings.TrimPrefix(value, schemeAWS))
+	default:
+		return value
+	}
+
+	if err != nil {
+		l.addProblem("%s: failed to resolve secret %s: %v", key, value, err)
+		return ""
+	}
+	if secret == "" {
+		l.addProblem("%s: secret %s is empty", key, value)
+	}
+	return secret
+}
+
+// readSecretFile reads a secret from a file, trimming the trailing newline
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// readVaultSecret reads a field of a KV secret from Vault. Both KV version 1
+// (data.<field>) and version 2 (data.data.<field>) responses are understood.
+func (l *loader) readVaultSecret(reference string) (string, error) {
+	path, field, _ := strings.Cut(reference, "#")
+	if field == "" {
+		return "", fmt.Errorf("vault references need a #field")
+	}
+	address := l.getEnvWithDefault("VAULT_ADDR", "")
+	token := l.getEnvWithDefault("VAULT_TOKEN", "")
+	if address == "" || token == "" {
+		return "", fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimRight(address, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	body, err := doSecretRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	data := response.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found", field)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// readAWSSecret reads a secret from AWS Secrets Manager with a SigV4-signed
+// GetSecretValue call
+func (l *loader) readAWSSecret(reference string) (string, error) {
+	secretID, field, _ := strings.Cut(reference, "#")
+	region := l.getEnvWithDefault("AWS_REGION", l.getEnvWithDefault("AWS_DEFAULT_REGION", ""))
+	accessKey := l.getEnvWithDefault("AWS_ACCESS_KEY_ID", "")
+	secretKey := l.getEnvWithDefault("AWS_SECRET_ACCESS_KEY", "")
+	if region == "" || accessKey == "" || secretKey == "" {
+		return "", fmt.Errorf("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	endpoint := l.getEnvWithDefault("AWS_SECRETSMANAGER_ENDPOINT", "https://secretsmanager."+region+".amazonaws.com")
+
+	payload, _ := json.Marshal(map[string]string{"SecretId": secretID})
+	req, err := http.NewRequest("POST", strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if sessionToken := l.getEnvWithDefault("AWS_SESSION_TOKEN", ""); sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+	signAWSRequest(req, payload, region, "secretsmanager", accessKey, secretKey, time.Now().UTC())
+
+	body, err := doSecretRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("invalid secrets manager response: %w", err)
+	}
+	if field == "" {
+		return response.SecretString, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(response.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, can't select %q", field)
+	}
+	value, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found", field)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// doSecretRequest sends a request to a secret manager and returns the body of a 2xx response
+func doSecretRequest(req *http.Request) ([]byte, error) {
+	resp, err := secretClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// signAWSRequest adds AWS Signature Version 4 headers to a request
+func signAWSRequest(req *http.Request, payload []byte, region, service, accessKey, secretKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("Host", req.URL.Host)
+
+	var names []string
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	req.Header.Del("Host") // net/http sends req.Host itself
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(payload),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
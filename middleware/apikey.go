@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"backend/server/apierror"
+	"crypto/sha256"
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// KeyStore checks whether an API key is valid
+type KeyStore interface {
+	ValidAPIKey(key string) (bool, error)
+}
+
+// StaticKeys is a KeyStore of keys from configuration
+type StaticKeys []string
+
+// ValidAPIKey reports whether key is one of the configured keys
+func (s StaticKeys) ValidAPIKey(key string) (bool, error) {
+	// Compare hashes so the comparison takes the same time for every key length
+	sum := sha256.Sum256([]byte(key))
+	valid := false
+	for _, configured := range s {
+		configuredSum := sha256.Sum256([]byte(configured))
+		if subtle.ConstantTimeCompare(sum[:], configuredSum[:]) == 1 {
+			valid = true
+		}
+	}
+	return valid, nil
+}
+
+// APIKey creates a middleware that rejects requests without a valid API key.
+// The key is read from the X-API-Key header or an "Authorization: Bearer" header
+// and accepted if any store knows it. Browsers can't set headers on WebSocket
+// upgrades, so those may pass the key as a "token" query parameter instead.
+func APIKey(stores ...KeyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := apiKeyFromRequest(r)
+			if key == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				apierror.Write(w, http.StatusUnauthorized, apierror.Unauthorized, "API key required")
+				return
+			}
+
+			for _, store := range stores {
+				valid, err := store.ValidAPIKey(key)
+				if err != nil {
+					log.Printf("Error checking API key: %v", err)
+					apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to check API key")
+					return
+				}
+				if valid {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			apierror.Write(w, http.StatusUnauthorized, apierror.Unauthorized, "Invalid API key")
+		})
+	}
+}
+
+// apiKeyFromRequest returns the API key sent with a request, if any
+func apiKeyFromRequest(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+		return key
+	}
+	if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return strings.TrimSpace(r.URL.Query().Get("token"))
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+)
+
+// RedirectHTTPS returns a handler redirecting plain HTTP requests to the same
+// URL over HTTPS on httpsPort
+func RedirectHTTPS(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if hostname, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = hostname
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		// 308 keeps the method and body of API writes
+		status := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			status = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, r, target, status)
+	})
+}
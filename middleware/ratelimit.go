@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"backend/server/apierror"
+	"backend/services/ratelimit"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RateLimit creates a middleware that limits requests per user, or per client
+// IP for anonymous requests, using the first rule matching each request. A
+// request counts as the X-User-ID user's only if it carries an API key one of
+// stores knows, as X-User-ID is otherwise anyone's to pick.
+// Requests matching no rule are not limited. If the limiter fails the request
+// is let through, so an outage of a shared limiter doesn't take the API down.
+func RateLimit(limiter ratelimit.Limiter, rules []ratelimit.Rule, stores ...KeyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rule, ok := matchRule(rules, r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := limiter.Allow(rule.Key()+"|"+clientKey(r, stores), rule.Limit, rule.Window)
+			if err != nil {
+				log.Printf("Error checking rate limit, allowing request: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			if !result.Allowed {
+				retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				apierror.Write(w, http.StatusTooManyRequests, apierror.RateLimited, "Rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// matchRule returns the first rule that applies to a request
+func matchRule(rules []ratelimit.Rule, r *http.Request) (ratelimit.Rule, bool) {
+	for _, rule := range rules {
+		if rule.Matches(r.Method, r.URL.Path) {
+			return rule, true
+		}
+	}
+	return ratelimit.Rule{}, false
+}
+
+// clientKey identifies who is making a request: the user from X-User-ID if
+// the request has a valid API key, or else the client IP
+func clientKey(r *http.Request, stores []KeyStore) string {
+	if userID := strings.TrimSpace(r.Header.Get("X-User-ID")); userID != "" && validAPIKey(r, stores) {
+		return "user:" + userID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// validAPIKey reports whether a request has an API key one of stores knows.
+// Errors count as invalid, so a failing store limits requests by IP.
+func validAPIKey(r *http.Request, stores []KeyStore) bool {
+	key := apiKeyFromRequest(r)
+	if key == "" {
+		return false
+	}
+	for _, store := range stores {
+		if valid, err := store.ValidAPIKey(key); err == nil && valid {
+			return true
+		}
+	}
+	return false
+}
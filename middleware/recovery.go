@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"fmt"
+	"backend/server/apierror"
 	"log"
 	"net/http"
 	"runtime/debug"
@@ -15,11 +15,8 @@ func Recovery(next http.Handler) http.Handler {
 				// Log the error and stack trace
 				log.Printf("Panic recovered: %v\n%s", err, debug.Stack())
 
-				// Return internal server error
-				http.Error(w, 
-					fmt.Sprintf("Internal server error: %v", err), 
-					http.StatusInternalServerError,
-				)
+				// Return internal server error without exposing the panic
+				apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Internal server error")
 			}
 		}()
 
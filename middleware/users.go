@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strings"
+)
+
+// UserRegistrar creates the profile of a user on their first request
+type UserRegistrar interface {
+	Register(userID string) error
+}
+
+// RegisterUsers creates a middleware that registers the user identified by
+// X-User-ID, or on WebSocket upgrades the user_id query parameter, before
+// passing the request on. Requests without a user are passed on as they
+// are, and so are those whose user couldn't be registered; the next request
+// tries again.
+func RegisterUsers(registrar UserRegistrar) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := strings.TrimSpace(r.Header.Get("X-User-ID"))
+			if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+				if queryUserID := strings.TrimSpace(r.URL.Query().Get("user_id")); queryUserID != "" {
+					userID = queryUserID
+				}
+			}
+			if userID != "" {
+				if err := registrar.Register(userID); err != nil {
+					log.Printf("Error registering user %s: %v", userID, err)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
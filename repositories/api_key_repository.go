@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+)
+
+// APIKeyRepository stores API keys in the api_keys table. Only SHA-256
+// hashes of the keys are stored.
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// ValidAPIKey reports whether key exists and has not been revoked
+func (r *APIKeyRepository) ValidAPIKey(key string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+        SELECT EXISTS (
+            SELECT 1 FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL
+        )
+    `, HashAPIKey(key)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	return exists, nil
+}
+
+// HashAPIKey returns the hex SHA-256 hash stored for an API key
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
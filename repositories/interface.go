@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"backend/server/models"
+	"time"
+)
+
+// MusicRepository holds what is playing, what was played and the current
+// song's lyrics. Handlers depend on this interface so they can be tested
+// against mocks; MemoryMusicRepository is the implementation used by the
+// server.
+type MusicRepository interface {
+	// AddTrackListener registers a listener called after every track change
+	AddTrackListener(listener TrackListener)
+
+	// UpdateNowPlaying and UpdateNowPlayingUnified set the current track,
+	// returning false if the source policy rejected the update
+	UpdateNowPlaying(track models.SpotifyTrack) bool
+	UpdateNowPlayingUnified(track models.UnifiedTrack) bool
+	// UpdateNowPlayingIfVersion sets the current track only if it is still at version
+	UpdateNowPlayingIfVersion(track models.UnifiedTrack, version int64) bool
+	GetNowPlaying() models.NowPlaying
+	IsPlaying() bool
+	HasCurrentTrack() bool
+	// SetPlaybackState pauses, resumes or stops the current track,
+	// returning false if no track is loaded
+	SetPlaybackState(state string) bool
+	StopNowPlaying() bool
+	// Heartbeat records playback progress, returning false if trackID is
+	// not the current track
+	Heartbeat(trackID string, positionMs, durationMs int64) bool
+
+	// GetPlayHistory returns the most recent plays, most recent first
+	GetPlayHistory() []models.PlayHistoryItem
+	// QueryPlayHistory returns a page of the plays matching filter and the
+	// number of matching plays
+	QueryPlayHistory(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error)
+
+	GetLyricsForCurrentSong() (string, error)
+	GetCurrentSongInfo() string
+}
+
+// NowPlayingStore keeps a snapshot of the now-playing state, so playback
+// survives a restart
+type NowPlayingStore interface {
+	// Save replaces the snapshot
+	Save(snapshot *models.NowPlaying) error
+	// Load returns the snapshot, or false if none was saved
+	Load() (*models.NowPlaying, bool, error)
+}
+
+// SharedNowPlayingStore is a NowPlayingStore updated by several replicas, see
+// MemoryMusicRepository.ShareNowPlaying. It keeps the pending play with the
+// snapshot, and only replaces state that no other replica saved since it was loaded.
+type SharedNowPlayingStore interface {
+	NowPlayingStore
+	// LoadShared returns the state and its revision, which is empty if none was saved
+	LoadShared() (SharedNowPlaying, string, error)
+	// SaveShared replaces the state if it is still at revision, returning false otherwise
+	SaveShared(state SharedNowPlaying, revision string) (bool, error)
+}
+
+// SharedNowPlaying is the state kept in a SharedNowPlayingStore
+type SharedNowPlaying struct {
+	*models.NowPlaying
+	Pending *PendingPlay `json:"pending,omitempty"`
+}
+
+// PendingPlay is the current track until it passes the scrobble threshold
+type PendingPlay struct {
+	Track     models.UnifiedTrack `json:"track"`
+	StartedAt time.Time           `json:"started_at"`
+}
+
+// HistoryStore keeps the play history. A repeat of the most recent track
+// within the store's repeat window is folded into its item, as described by
+// models.PlayHistoryItem.Repeat.
+type HistoryStore interface {
+	// Add records a track that started playing at playedAt
+	Add(track models.UnifiedTrack, playedAt time.Time, listenedMs int64) error
+	// UpdateListened sets the listening time of the most recent item if it is trackID
+	UpdateListened(trackID string, listenedMs int64) error
+	// Recent returns the most recent items, most recent first
+	Recent() ([]models.PlayHistoryItem, error)
+	// Query returns up to limit items matching filter, most recent first,
+	// after skipping offset of them, and the number of matching items
+	Query(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error)
+}
+
+// MoodSuggestionStore keeps the curated mood catalog
+type MoodSuggestionStore interface {
+	// List returns every suggestion
+	List() ([]models.MoodSuggestion, error)
+	// Add stores a new suggestion, returning it with its ID
+	Add(suggestion models.MoodSuggestion) (models.MoodSuggestion, error)
+	// Update replaces the suggestion with the same ID, returning
+	// ErrMoodSuggestionNotFound if there is none
+	Update(suggestion models.MoodSuggestion) error
+	// Delete removes a suggestion, returning ErrMoodSuggestionNotFound if there is none
+	Delete(id int64) error
+}
+
+// RedisClient sends commands to Redis, e.g. a *redis.Client. Replies are
+// int64, string, []interface{} or nil.
+type RedisClient interface {
+	Do(args ...string) (interface{}, error)
+}
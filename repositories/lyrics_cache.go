@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lyricsEntry is a cached song's lyrics
+type lyricsEntry struct {
+	key       string
+	lyrics    string
+	expiresAt time.Time
+}
+
+// lyricsCache is a thread-safe LRU cache of lyrics with per-entry expiry
+type lyricsCache struct {
+	maxEntries int           // Least recently used entries are evicted beyond this size; 0 disables the cache
+	ttl        time.Duration // How long fetched lyrics are served before being fetched again
+	entries    map[string]*list.Element
+	order      *list.List // Front is most recently used
+	mutex      sync.Mutex
+}
+
+// newLyricsCache creates an empty lyrics cache
+func newLyricsCache(maxEntries int, ttl time.Duration) *lyricsCache {
+	return &lyricsCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// setLimits changes the size and expiry, evicting entries beyond the new size
+func (c *lyricsCache) setLimits(maxEntries int, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.maxEntries = maxEntries
+	c.ttl = ttl
+	c.evict()
+}
+
+// get returns non-expired lyrics and marks them recently used
+func (c *lyricsCache) get(key string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	cached := element.Value.(*lyricsEntry)
+	if time.Now().After(cached.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return "", false
+	}
+
+	c.order.MoveToFront(element)
+	return cached.lyrics, true
+}
+
+// put stores lyrics, evicting the least recently used entries when full
+func (c *lyricsCache) put(key, lyrics string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.maxEntries <= 0 {
+		return
+	}
+
+	expiresAt := time.Now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		cached := element.Value.(*lyricsEntry)
+		cached.lyrics = lyrics
+		cached.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lyricsEntry{key: key, lyrics: lyrics, expiresAt: expiresAt})
+	c.evict()
+}
+
+// evict drops least recently used entries beyond maxEntries; callers must hold the lock
+func (c *lyricsCache) evict() {
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lyricsEntry).key)
+	}
+}
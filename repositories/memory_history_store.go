@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"backend/server/models"
+	"time"
+)
+
+// memoryHistoryStore keeps the play history in a models.PlayHistory
+type memoryHistoryStore struct {
+	history *models.PlayHistory
+}
+
+// NewMemoryHistoryStore creates a HistoryStore keeping the latest size items
+// in memory, collapsing repeats within repeatWindow (0 disables this)
+func NewMemoryHistoryStore(size int, repeatWindow time.Duration) HistoryStore {
+	history := models.NewPlayHistory(size)
+	history.SetRepeatWindow(repeatWindow)
+	return &memoryHistoryStore{history: history}
+}
+
+// Add records a play
+func (m *memoryHistoryStore) Add(track models.UnifiedTrack, playedAt time.Time, listenedMs int64) error {
+	m.history.AddPlayed(track, playedAt, listenedMs)
+	return nil
+}
+
+// UpdateListened sets the listening time of the most recent item
+func (m *memoryHistoryStore) UpdateListened(trackID string, listenedMs int64) error {
+	m.history.UpdateListened(trackID, listenedMs)
+	return nil
+}
+
+// Recent returns every kept item
+func (m *memoryHistoryStore) Recent() ([]models.PlayHistoryItem, error) {
+	return m.history.GetItems(), nil
+}
+
+// Query returns a page of matching items
+func (m *memoryHistoryStore) Query(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error) {
+	items, total := m.history.Query(filter, offset, limit)
+	return items, total, nil
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"backend/server/models"
+	"sync"
+	"time"
+)
+
+// memoryMoodSuggestionStore keeps the mood catalog in memory
+type memoryMoodSuggestionStore struct {
+	suggestions []models.MoodSuggestion
+	nextID      int64
+	mutex       sync.Mutex
+}
+
+// NewMemoryMoodSuggestionStore creates a MoodSuggestionStore that lasts as
+// long as the process
+func NewMemoryMoodSuggestionStore() MoodSuggestionStore {
+	return &memoryMoodSuggestionStore{nextID: 1}
+}
+
+// List returns every suggestion, in the order they were added
+func (m *memoryMoodSuggestionStore) List() ([]models.MoodSuggestion, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	suggestions := make([]models.MoodSuggestion, len(m.suggestions))
+	copy(suggestions, m.suggestions)
+	return suggestions, nil
+}
+
+// Add stores a new suggestion
+func (m *memoryMoodSuggestionStore) Add(suggestion models.MoodSuggestion) (models.MoodSuggestion, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	suggestion.ID = m.nextID
+	suggestion.UpdatedAt = time.Now()
+	m.nextID++
+	m.suggestions = append(m.suggestions, suggestion)
+	return suggestion, nil
+}
+
+// Update replaces the suggestion with the same ID
+func (m *memoryMoodSuggestionStore) Update(suggestion models.MoodSuggestion) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i := range m.suggestions {
+		if m.suggestions[i].ID == suggestion.ID {
+			m.suggestions[i] = suggestion
+			return nil
+		}
+	}
+	return ErrMoodSuggestionNotFound
+}
+
+// Delete removes a suggestion
+func (m *memoryMoodSuggestionStore) Delete(id int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i := range m.suggestions {
+		if m.suggestions[i].ID == id {
+			m.suggestions = append(m.suggestions[:i], m.suggestions[i+1:]...)
+			return nil
+		}
+	}
+	return ErrMoodSuggestionNotFound
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"backend/server/models"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// memoryNowPlayingStore keeps the now-playing snapshot in memory, encoded as
+// in the Postgres store
+type memoryNowPlayingStore struct {
+	snapshot []byte
+	mutex    sync.Mutex
+}
+
+// NewMemoryNowPlayingStore creates a NowPlayingStore that lasts as long as
+// the process, e.g. for tests
+func NewMemoryNowPlayingStore() NowPlayingStore {
+	return &memoryNowPlayingStore{}
+}
+
+// Save replaces the snapshot
+func (m *memoryNowPlayingStore) Save(snapshot *models.NowPlaying) error {
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode now playing: %w", err)
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.snapshot = encoded
+	return nil
+}
+
+// Load returns the snapshot
+func (m *memoryNowPlayingStore) Load() (*models.NowPlaying, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.snapshot == nil {
+		return nil, false, nil
+	}
+
+	var snapshot models.NowPlaying
+	if err := json.Unmarshal(m.snapshot, &snapshot); err != nil {
+		return nil, false, fmt.Errorf("failed to decode now playing: %w", err)
+	}
+	return &snapshot, true, nil
+}
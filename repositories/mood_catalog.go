@@ -0,0 +1,366 @@
+package repositories
+
+import (
+	"backend/server/models"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrMoodSuggestionNotFound is returned for changes to a suggestion that doesn't exist
+var ErrMoodSuggestionNotFound = errors.New("mood suggestion not found")
+
+// MoodCatalog holds the curated mood-based suggestions shown to every user.
+// Reads are served from memory; changes are written through to its store.
+type MoodCatalog struct {
+	store   MoodSuggestionStore
+	entries []models.MoodSuggestion // As listed by the store
+	mutex   sync.RWMutex
+}
+
+// NewMoodCatalog creates a catalog kept in memory, seeded with the given suggestions
+func NewMoodCatalog(suggestions map[string][]models.MoodBasedRecommendation) *MoodCatalog {
+	catalog, _ := NewStoredMoodCatalog(NewMemoryMoodSuggestionStore(), suggestions) // The memory store never fails
+	return catalog
+}
+
+// NewStoredMoodCatalog creates a catalog kept in store, seeding an empty
+// store with the given suggestions
+func NewStoredMoodCatalog(store MoodSuggestionStore, seed map[string][]models.MoodBasedRecommendation) (*MoodCatalog, error) {
+	entries, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		// Seed in a stable order, so IDs don't depend on map iteration
+		moods := make([]string, 0, len(seed))
+		for mood := range seed {
+			moods = append(moods, mood)
+		}
+		sort.Strings(moods)
+		for _, mood := range moods {
+			for _, recommendation := range seed[mood] {
+				added, err := store.Add(models.MoodSuggestion{
+					Mood:        mood,
+					Track:       recommendation.Track,
+					MatchReason: recommendation.MatchReason,
+					MoodScore:   recommendation.MoodScore,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("failed to seed mood catalog: %w", err)
+				}
+				entries = append(entries, added)
+			}
+		}
+	}
+
+	return &MoodCatalog{store: store, entries: entries}, nil
+}
+
+// GetSuggestions returns the suggestions for a mood, best matches first
+func (c *MoodCatalog) GetSuggestions(mood string) ([]models.MoodBasedRecommendation, bool) {
+	matching := c.List(mood)
+	if len(matching) == 0 {
+		return nil, false
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].MoodScore > matching[j].MoodScore
+	})
+	suggestions := make([]models.MoodBasedRecommendation, len(matching))
+	for i, suggestion := range matching {
+		suggestions[i] = suggestion.Recommendation()
+	}
+	return suggestions, true
+}
+
+// List returns a copy of the suggestions for a mood, or of all of them if
+// mood is empty, in the order they were added
+func (c *MoodCatalog) List(mood string) []models.MoodSuggestion {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	suggestions := []models.MoodSuggestion{}
+	for _, suggestion := range c.entries {
+		if mood == "" || suggestion.Mood == mood {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return suggestions
+}
+
+// Add adds a suggestion, returning it with its ID
+func (c *MoodCatalog) Add(suggestion models.MoodSuggestion) (models.MoodSuggestion, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	added, err := c.store.Add(suggestion)
+	if err != nil {
+		return suggestion, err
+	}
+	c.entries = append(c.entries, added)
+	return added, nil
+}
+
+// Update replaces the suggestion with the same ID, returning
+// ErrMoodSuggestionNotFound if there is none
+func (c *MoodCatalog) Update(suggestion models.MoodSuggestion) (models.MoodSuggestion, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	suggestion.UpdatedAt = time.Now()
+	if err := c.store.Update(suggestion); err != nil {
+		return suggestion, err
+	}
+	for i := range c.entries {
+		if c.entries[i].ID == suggestion.ID {
+			c.entries[i] = suggestion
+		}
+	}
+	return suggestion, nil
+}
+
+// Delete removes a suggestion, returning ErrMoodSuggestionNotFound if there is none
+func (c *MoodCatalog) Delete(id int64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.store.Delete(id); err != nil {
+		return err
+	}
+	for i := range c.entries {
+		if c.entries[i].ID == id {
+			c.entries = append(c.entries[:i], c.entries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Tracks returns every distinct track in the catalog
+func (c *MoodCatalog) Tracks() []models.UnifiedTrack {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	var tracks []models.UnifiedTrack
+	for _, suggestion := range c.entries {
+		if !seen[suggestion.Track.ID] {
+			seen[suggestion.Track.ID] = true
+			tracks = append(tracks, suggestion.Track)
+		}
+	}
+	return tracks
+}
+
+// ReplaceTrack swaps every occurrence of a track ID for a corrected track,
+// keeping mood scores and match reasons. It returns the number of entries updated.
+func (c *MoodCatalog) ReplaceTrack(oldID string, track models.UnifiedTrack) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	replaced := 0
+	for i := range c.entries {
+		if c.entries[i].Track.ID != oldID {
+			continue
+		}
+		suggestion := c.entries[i]
+		suggestion.Track = track
+		suggestion.UpdatedAt = time.Now()
+		if err := c.store.Update(suggestion); err != nil {
+			log.Printf("Failed to replace track %s in mood suggestion %d: %v", oldID, suggestion.ID, err)
+			continue
+		}
+		c.entries[i] = suggestion
+		replaced++
+	}
+	return replaced
+}
+
+// DefaultMoodCatalog returns the built-in curated mood-based suggestions
+func DefaultMoodCatalog() map[string][]models.MoodBasedRecommendation {
+	return map[string][]models.MoodBasedRecommendation{
+		"lonely": {
+			{
+				Track: models.UnifiedTrack{
+					ID:     "1mea3bSkSGXuIRvnydlB5b", // Real Spotify ID for "Somewhere I Belong"
+					Name:   "Somewhere I Belong",
+					Artist: "Linkin Park",
+					Album:  "Meteora",
+					Source: "spotify",
+				},
+				MoodScore: 0.95,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "4N3y2ChKKCG3zVCfyNiMQD", // Real Spotify ID for "Mad World"
+					Name:   "Mad World",
+					Artist: "Gary Jules",
+					Album:  "Trading Snakeoil for Wolftickets",
+					Source: "spotify",
+				},
+				MoodScore: 0.90,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "u9HBEOlMgOtK8yXGKKMhRx", // Real Spotify ID for "The Sound of Silence"
+					Name:   "The Sound of Silence",
+					Artist: "Disturbed",
+					Album:  "Immortalized",
+					Source: "spotify",
+				},
+				MoodScore: 0.88,
+			},
+		},
+		"sad": {
+			{
+				Track: models.UnifiedTrack{
+					ID:     "2DjPkzR89MSYPGaWhK8uKQ", // Real Spotify ID for "Hurt" by Johnny Cash
+					Name:   "Hurt",
+					Artist: "Johnny Cash",
+					Album:  "American IV: The Man Comes Around",
+					Source: "spotify",
+				},
+				MoodScore: 0.95,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "0SiQrCn2h2aKOEqz5Zxwow", // Real Spotify ID for "The Night We Met"
+					Name:   "The Night We Met",
+					Artist: "Lord Huron",
+					Album:  "Strange Trails",
+					Source: "spotify",
+				},
+				MoodScore: 0.90,
+			},
+		},
+		"happy": {
+			{
+				Track: models.UnifiedTrack{
+					ID:     "3BxnGCLFNdLKgVgVz6Vn5H", // Real Spotify ID for "Good Life"
+					Name:   "Good Life",
+					Artist: "OneRepublic",
+					Album:  "Waking Up",
+					Source: "spotify",
+				},
+				MoodScore: 0.95,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "05wIrZSwuaVWhcv5FfqeJ0", // Real Spotify ID for "Walking on Sunshine"
+					Name:   "Walking on Sunshine",
+					Artist: "Katrina and the Waves",
+					Album:  "Walking on Sunshine",
+					Source: "spotify",
+				},
+				MoodScore: 0.93,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "60nZcImufyMA1MKQY3dcCH", // Real Spotify ID for "Happy"
+					Name:   "Happy",
+					Artist: "Pharrell Williams",
+					Album:  "G I R L",
+					Source: "spotify",
+				},
+				MoodScore: 0.98,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "0BxE4FqsDD1Ot4YuBXwn8F", // Real Spotify ID for "Can't Stop the Feeling!"
+					Name:   "Can't Stop the Feeling!",
+					Artist: "Justin Timberlake",
+					Album:  "Trolls (Original Motion Picture Soundtrack)",
+					Source: "spotify",
+				},
+				MoodScore: 0.96,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "32OlwWuMpZ6b0aN2RZOeMS", // Real Spotify ID for "Uptown Funk"
+					Name:   "Uptown Funk",
+					Artist: "Mark Ronson ft. Bruno Mars",
+					Album:  "Uptown Special",
+					Source: "spotify",
+				},
+				MoodScore: 0.94,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "1WkMMavIMc4JZ8cfMmxHkI", // Real Spotify ID for "Good as Hell"
+					Name:   "Good as Hell",
+					Artist: "Lizzo",
+					Album:  "Cuz I Love You",
+					Source: "spotify",
+				},
+				MoodScore: 0.92,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "0CFuMybe6s77w6QQrJjW7d", // Real Spotify ID for "I'm Gonna Be (500 Miles)"
+					Name:   "I'm Gonna Be (500 Miles)",
+					Artist: "The Proclaimers",
+					Album:  "Sunshine on Leith",
+					Source: "spotify",
+				},
+				MoodScore: 0.90,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "5T8EDUDqKcs6OSOwEsfqG7", // Real Spotify ID for "Don't Stop Me Now"
+					Name:   "Don't Stop Me Now",
+					Artist: "Queen",
+					Album:  "Jazz",
+					Source: "spotify",
+				},
+				MoodScore: 0.88,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "2RlgNHKcydI9sayD2Df2xp", // Real Spotify ID for "Mr. Blue Sky"
+					Name:   "Mr. Blue Sky",
+					Artist: "Electric Light Orchestra",
+					Album:  "Out of the Blue",
+					Source: "spotify",
+				},
+				MoodScore: 0.86,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "3PPogGhAUjr4FLGzEFGzJI", // Real Spotify ID for "Best Day of My Life"
+					Name:   "Best Day of My Life",
+					Artist: "American Authors",
+					Album:  "Oh, What a Life",
+					Source: "spotify",
+				},
+				MoodScore: 0.84,
+			},
+		},
+		"angry": {
+			{
+				Track: models.UnifiedTrack{
+					ID:     "2OzEKCmOoWhyuB8nHi8xhv", // Real Spotify ID for "Break Stuff"
+					Name:   "Break Stuff",
+					Artist: "Limp Bizkit",
+					Album:  "Significant Other",
+					Source: "spotify",
+				},
+				MoodScore: 0.95,
+			},
+			{
+				Track: models.UnifiedTrack{
+					ID:     "0yp7ORA8XPNO4kvNj5EYdx", // Real Spotify ID for "Bodies"
+					Name:   "Bodies",
+					Artist: "Drowning Pool",
+					Album:  "Sinner",
+					Source: "spotify",
+				},
+				MoodScore: 0.92,
+			},
+		},
+	}
+}
@@ -3,56 +3,537 @@ package repositories
 import (
 	"backend/server/models"
 	"backend/services/genius"
+	"context"
 	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// MusicRepository manages music-related data
-type MusicRepository struct {
+// TrackListener is notified whenever a new track starts playing
+type TrackListener func(track models.UnifiedTrack)
+
+// SourcePolicy resolves competing now-playing updates from different sources,
+// e.g. a Spotify poller and a YouTube client reporting within seconds.
+// The zero value accepts every update (most recent wins).
+type SourcePolicy struct {
+	// MinDwell is how long the current source must have been silent before a
+	// different source may take over
+	MinDwell time.Duration
+	// Priorities lets higher priority sources take over immediately; sources
+	// not listed have priority 0
+	Priorities map[string]int
+}
+
+// ScrobblePolicy decides when a track has been played long enough to enter the
+// play history: after Fraction of its duration or MaxListen of listening,
+// whichever comes first. Tracks of unknown duration need MaxListen. The zero
+// value adds every track as soon as it starts.
+type ScrobblePolicy struct {
+	Fraction  float64       // Share of the duration, e.g. 0.5; 0 disables the policy
+	MaxListen time.Duration // Listening time that is always enough, e.g. 4 minutes; 0 for no cap
+}
+
+// threshold returns the listening time track needs, preferring durationMs
+// from heartbeats over the track's own duration
+func (p ScrobblePolicy) threshold(track models.UnifiedTrack, durationMs int64) time.Duration {
+	if durationMs <= 0 {
+		durationMs = int64(track.Duration) * 1000
+	}
+	if durationMs <= 0 {
+		return p.MaxListen
+	}
+	threshold := time.Duration(p.Fraction * float64(durationMs) * float64(time.Millisecond))
+	if p.MaxListen > 0 && p.MaxListen < threshold {
+		threshold = p.MaxListen
+	}
+	return threshold
+}
+
+// MemoryMusicRepository implements MusicRepository, keeping now-playing state
+// and lyrics in memory and the play history in its HistoryStore
+type MemoryMusicRepository struct {
 	nowPlaying   *models.NowPlaying
-	playHistory  *models.PlayHistory
-	lyricsCache  map[string]string // Simple in-memory cache for lyrics
+	history      HistoryStore
+	lyricsCache  *lyricsCache
 	geniusService genius.Service
+	listeners     []TrackListener
+	listenerMutex sync.RWMutex
+	sourcePolicy   SourcePolicy
+	sourceLastSeen map[string]time.Time // Last accepted update per source
+	scrobblePolicy ScrobblePolicy
+	pending        *PendingPlay // Current track, until it passes the scrobble threshold
+	effects        []func()     // History updates of the update being applied, see update
+	snapshots      NowPlayingStore // Optional; saved after every playback change
+	sharedStore    SharedNowPlayingStore // Set by ShareNowPlaying
+	shared         atomic.Bool     // Snapshots are reloaded before every access, see ShareNowPlaying
+	revision       string          // Of the shared state last reloaded
+	updateMutex    sync.Mutex   // Serializes policy checks with updates
 }
 
+// Lyrics cache limits used until SetLyricsCacheLimits is called
+const (
+	DefaultLyricsCacheSize = 500
+	DefaultLyricsCacheTTL  = 24 * time.Hour
+)
+
+// Ensure MemoryMusicRepository implements MusicRepository
+var _ MusicRepository = (*MemoryMusicRepository)(nil)
+
 // NewMusicRepository creates a new music repository
-func NewMusicRepository(geniusService genius.Service) *MusicRepository {
-	return &MusicRepository{
-		nowPlaying:    models.NewNowPlaying(),
-		playHistory:   models.NewPlayHistory(10), // Keep last 10 tracks
-		lyricsCache:   make(map[string]string),
-		geniusService: geniusService,
+func NewMusicRepository(geniusService genius.Service) *MemoryMusicRepository {
+	return &MemoryMusicRepository{
+		nowPlaying:     models.NewNowPlaying(),
+		history:        NewMemoryHistoryStore(10, 0), // Keep last 10 tracks
+		lyricsCache:    newLyricsCache(DefaultLyricsCacheSize, DefaultLyricsCacheTTL),
+		geniusService:  geniusService,
+		sourceLastSeen: make(map[string]time.Time),
+	}
+}
+
+// SetSourcePolicy sets how conflicting updates from different sources are resolved
+func (r *MemoryMusicRepository) SetSourcePolicy(policy SourcePolicy) {
+	r.updateMutex.Lock()
+	defer r.updateMutex.Unlock()
+	r.sourcePolicy = policy
+}
+
+// SetScrobblePolicy sets how long tracks must play before entering the history
+func (r *MemoryMusicRepository) SetScrobblePolicy(policy ScrobblePolicy) {
+	r.updateMutex.Lock()
+	defer r.updateMutex.Unlock()
+	r.scrobblePolicy = policy
+}
+
+// SetLyricsCacheLimits sets how many songs' lyrics are cached and for how
+// long; a size of 0 disables the cache
+func (r *MemoryMusicRepository) SetLyricsCacheLimits(size int, ttl time.Duration) {
+	r.lyricsCache.setLimits(size, ttl)
+}
+
+// SetHistoryStore sets where the play history is kept, e.g. a
+// NewPostgresHistoryStore to keep it across restarts
+func (r *MemoryMusicRepository) SetHistoryStore(store HistoryStore) {
+	r.updateMutex.Lock()
+	defer r.updateMutex.Unlock()
+	r.history = store
+}
+
+// RestoreNowPlaying saves now-playing snapshots to store from now on, and
+// restores the saved snapshot if it was updated within maxAge. It returns
+// true if a track was restored.
+func (r *MemoryMusicRepository) RestoreNowPlaying(store NowPlayingStore, maxAge time.Duration) (bool, error) {
+	r.updateMutex.Lock()
+	defer r.updateMutex.Unlock()
+
+	r.snapshots = store
+	snapshot, ok, err := store.Load()
+	if err != nil || !ok || snapshot.TrackID == "" || time.Since(snapshot.UpdatedAt) > maxAge {
+		return false, err
+	}
+
+	r.nowPlaying.Restore(snapshot)
+	r.sourceLastSeen[snapshot.Source] = snapshot.UpdatedAt
+	return true, nil
+}
+
+// ShareNowPlaying keeps the now-playing state in store, shared with other
+// replicas: it is reloaded from store before every read and update and saved
+// after every change. An update is applied again to the latest state if
+// another replica saved in between, so none is lost. The pending play is
+// kept in store too, so any replica can add it to the history.
+func (r *MemoryMusicRepository) ShareNowPlaying(store SharedNowPlayingStore) {
+	r.updateMutex.Lock()
+	defer r.updateMutex.Unlock()
+	r.snapshots = store
+	r.sharedStore = store
+	r.shared.Store(true)
+	r.reload()
+}
+
+// reload replaces the local state with the shared state, keeping lyrics
+// already fetched for the same track. Callers must hold updateMutex.
+func (r *MemoryMusicRepository) reload() {
+	if !r.shared.Load() {
+		return
+	}
+	state, revision, err := r.sharedStore.LoadShared()
+	if err != nil {
+		log.Printf("Failed to load shared now playing: %v", err)
+		return
+	}
+	r.revision = revision
+	if state.NowPlaying == nil {
+		return
+	}
+
+	snapshot := state.NowPlaying
+	current := r.nowPlaying.Get()
+	if snapshot.TrackID == current.TrackID && snapshot.Version == current.Version {
+		snapshot.Lyrics = current.Lyrics
+	}
+	r.pending = state.Pending
+	r.nowPlaying.Restore(snapshot)
+	if snapshot.UpdatedAt.After(r.sourceLastSeen[snapshot.Source]) {
+		r.sourceLastSeen[snapshot.Source] = snapshot.UpdatedAt
+	}
+}
+
+// refresh reloads shared state before a read
+func (r *MemoryMusicRepository) refresh() {
+	if !r.shared.Load() {
+		return
+	}
+	r.updateMutex.Lock()
+	defer r.updateMutex.Unlock()
+	r.reload()
+}
+
+// maxSharedAttempts is how often an update is applied while other replicas
+// keep saving the shared state in between
+const maxSharedAttempts = 5
+
+// update applies change to the now-playing state and saves it, then applies
+// the history updates change queued with recordPlay or afterSave. With a
+// shared store, change is applied to the latest shared state, and again if
+// another replica saved in between. change returns false to leave the state
+// as it is; update returns false then, or if the attempts ran out. Callers
+// must hold updateMutex.
+func (r *MemoryMusicRepository) update(change func() bool) bool {
+	defer func() { r.effects = nil }()
+	for attempt := 1; ; attempt++ {
+		r.reload()
+		r.effects = nil
+		if !change() {
+			return false
+		}
+		if r.saveNowPlaying() {
+			break
+		}
+		if attempt == maxSharedAttempts {
+			log.Printf("Failed to save now playing: changed by another replica %d times", attempt)
+			r.reload()
+			return false
+		}
+	}
+	for _, effect := range r.effects {
+		effect()
+	}
+	return true
+}
+
+// afterSave queues a history update until the change being applied is
+// saved; callers must hold updateMutex
+func (r *MemoryMusicRepository) afterSave(effect func()) {
+	r.effects = append(r.effects, effect)
+}
+
+// saveNowPlaying saves a snapshot of the now-playing state, without lyrics,
+// which are fetched again when needed. It returns false if the state is
+// shared and another replica saved it since it was reloaded. Callers must
+// hold updateMutex.
+func (r *MemoryMusicRepository) saveNowPlaying() bool {
+	if r.snapshots == nil {
+		return true
+	}
+	snapshot := r.nowPlaying.Get()
+	snapshot.Lyrics = ""
+	if r.shared.Load() {
+		saved, err := r.sharedStore.SaveShared(SharedNowPlaying{NowPlaying: &snapshot, Pending: r.pending}, r.revision)
+		if err != nil {
+			log.Printf("Failed to save now playing: %v", err)
+			return true
+		}
+		return saved
+	}
+	if err := r.snapshots.Save(&snapshot); err != nil {
+		log.Printf("Failed to save now playing: %v", err)
+	}
+	return true
+}
+
+// recordPlay adds a play to the history once the change being applied is
+// saved, logging failures since playback itself was still updated; callers
+// must hold updateMutex
+func (r *MemoryMusicRepository) recordPlay(track models.UnifiedTrack, playedAt time.Time, listenedMs int64) {
+	r.afterSave(func() {
+		if err := r.history.Add(track, playedAt, listenedMs); err != nil {
+			log.Printf("Failed to record play of %s: %v", track.ID, err)
+		}
+	})
+}
+
+// startPlay adds a new track to the history, or holds it back until it passes
+// the scrobble threshold; callers must hold updateMutex
+func (r *MemoryMusicRepository) startPlay(track models.UnifiedTrack) {
+	if r.scrobblePolicy.Fraction <= 0 {
+		r.recordPlay(track, time.Now(), 0)
+		return
+	}
+	r.pending = &PendingPlay{Track: track, StartedAt: time.Now()}
+}
+
+// finishPlay adds the pending track to the history if it was listened to long
+// enough before it ended. last is the now-playing state from just before it
+// ended. Without heartbeats, the track is assumed to have played from its
+// start until now, up to its duration. Callers must hold updateMutex.
+func (r *MemoryMusicRepository) finishPlay(last *models.NowPlaying) {
+	pending := r.pending
+	r.pending = nil
+	if pending == nil {
+		return
+	}
+
+	threshold := r.scrobblePolicy.threshold(pending.Track, last.DurationMs)
+	listened := time.Duration(last.ListenedMs) * time.Millisecond
+	if last.PositionAt.IsZero() {
+		listened = time.Since(pending.StartedAt)
+		if duration := time.Duration(pending.Track.Duration) * time.Second; duration > 0 && listened > duration {
+			listened = duration
+		}
+	}
+	if listened >= threshold {
+		r.recordPlay(withDuration(pending.Track, last.DurationMs), pending.StartedAt, last.ListenedMs)
+	}
+}
+
+// sameTrack reports whether an update re-syncs the track that was playing,
+// which keeps its pending play and progress and notifies no listeners
+func sameTrack(last *models.NowPlaying, track models.UnifiedTrack) bool {
+	return track.ID != "" && track.ID == last.TrackID
+}
+
+// withDuration fills in a missing track duration from a heartbeat, so
+// repeats of the track can be recognized as loop plays
+func withDuration(track models.UnifiedTrack, durationMs int64) models.UnifiedTrack {
+	if track.Duration == 0 {
+		track.Duration = int(durationMs / 1000)
+	}
+	return track
+}
+
+// acceptsSource checks the source policy for an update; callers must hold updateMutex
+func (r *MemoryMusicRepository) acceptsSource(source string) bool {
+	current := r.nowPlaying.Get()
+	if current.TrackID == "" || current.Source == source {
+		return true
+	}
+
+	if r.sourcePolicy.Priorities[source] > r.sourcePolicy.Priorities[current.Source] {
+		return true
+	}
+
+	return time.Since(r.sourceLastSeen[current.Source]) >= r.sourcePolicy.MinDwell
+}
+
+// AddTrackListener registers a listener called after every track change. A
+// re-sync of the current track is not a change.
+func (r *MemoryMusicRepository) AddTrackListener(listener TrackListener) {
+	r.listenerMutex.Lock()
+	defer r.listenerMutex.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// notifyTrackListeners calls all registered listeners with the new track
+func (r *MemoryMusicRepository) notifyTrackListeners(track models.UnifiedTrack) {
+	r.listenerMutex.RLock()
+	defer r.listenerMutex.RUnlock()
+	for _, listener := range r.listeners {
+		listener(track)
 	}
 }
 
-// UpdateNowPlaying updates the currently playing track from SpotifyTrack
-func (r *MusicRepository) UpdateNowPlaying(track models.SpotifyTrack) {
-	r.nowPlaying.Update(track)
-	r.playHistory.Add(track)
+// UpdateNowPlaying updates the currently playing track from SpotifyTrack.
+// It returns false if the source policy rejected the update.
+func (r *MemoryMusicRepository) UpdateNowPlaying(track models.SpotifyTrack) bool {
+	return r.UpdateNowPlayingUnified(models.FromSpotifyTrack(track))
+}
+
+// UpdateNowPlayingUnified updates the currently playing track from UnifiedTrack.
+// It returns false if the source policy rejected the update.
+func (r *MemoryMusicRepository) UpdateNowPlayingUnified(track models.UnifiedTrack) bool {
+	return r.updateTrack(track, func() bool {
+		r.nowPlaying.UpdateUnified(track)
+		return true
+	})
+}
+
+// UpdateNowPlayingIfVersion updates the currently playing track only if its
+// version still matches and the source policy allows it, returning false otherwise
+func (r *MemoryMusicRepository) UpdateNowPlayingIfVersion(track models.UnifiedTrack, version int64) bool {
+	return r.updateTrack(track, func() bool {
+		return r.nowPlaying.UpdateUnifiedIfVersion(track, version)
+	})
 }
 
-// UpdateNowPlayingUnified updates the currently playing track from UnifiedTrack
-func (r *MusicRepository) UpdateNowPlayingUnified(track models.UnifiedTrack) {
-	r.nowPlaying.UpdateUnified(track)
-	r.playHistory.AddUnified(track)
+// updateTrack makes track current through set, which returns false to
+// reject it, unless the source policy rejects it first. If the track changed,
+// its previous play is finished and listeners are notified.
+func (r *MemoryMusicRepository) updateTrack(track models.UnifiedTrack, set func() bool) bool {
+	r.updateMutex.Lock()
+	var changed bool
+	updated := r.update(func() bool {
+		if !r.acceptsSource(track.Source) {
+			return false
+		}
+		last := r.nowPlaying.Get()
+		if !set() {
+			return false
+		}
+		r.sourceLastSeen[track.Source] = time.Now()
+		changed = !sameTrack(&last, track)
+		if changed {
+			r.finishPlay(&last)
+			r.startPlay(track)
+		}
+		return true
+	})
+	r.updateMutex.Unlock()
+
+	if updated && changed {
+		r.notifyTrackListeners(track)
+	}
+	return updated
 }
 
 // GetNowPlaying returns the currently playing track
-func (r *MusicRepository) GetNowPlaying() models.NowPlaying {
+func (r *MemoryMusicRepository) GetNowPlaying() models.NowPlaying {
+	r.refresh()
 	return r.nowPlaying.Get()
 }
 
-// IsPlaying checks if a track is currently playing
-func (r *MusicRepository) IsPlaying() bool {
+// IsPlaying checks if a track is currently playing (loaded and not paused)
+func (r *MemoryMusicRepository) IsPlaying() bool {
+	r.refresh()
+	return !r.nowPlaying.IsEmpty() && !r.nowPlaying.IsPaused()
+}
+
+// HasCurrentTrack checks if a track is loaded, whether playing or paused
+func (r *MemoryMusicRepository) HasCurrentTrack() bool {
+	r.refresh()
 	return !r.nowPlaying.IsEmpty()
 }
 
+// SetPlaybackState pauses, resumes or stops the current track.
+// It returns false if no track is loaded.
+func (r *MemoryMusicRepository) SetPlaybackState(state string) bool {
+	r.updateMutex.Lock()
+	defer r.updateMutex.Unlock()
+
+	return r.update(func() bool {
+		last := r.nowPlaying.Get()
+		if !r.nowPlaying.SetState(state) {
+			return false
+		}
+		if state == models.PlaybackStopped {
+			r.finishPlay(&last)
+		}
+		return true
+	})
+}
+
+// StopNowPlaying clears the current track. It returns false if nothing was playing.
+func (r *MemoryMusicRepository) StopNowPlaying() bool {
+	return r.SetPlaybackState(models.PlaybackStopped)
+}
+
+// Heartbeat records playback progress for the current track, keeping it from
+// going stale and its source from being displaced. It returns false if trackID
+// is not the current track.
+func (r *MemoryMusicRepository) Heartbeat(trackID string, positionMs, durationMs int64) bool {
+	r.updateMutex.Lock()
+	defer r.updateMutex.Unlock()
+
+	return r.update(func() bool {
+		listenedMs, ok := r.nowPlaying.Heartbeat(trackID, positionMs, durationMs)
+		if !ok {
+			return false
+		}
+
+		current := r.nowPlaying.Get()
+		r.sourceLastSeen[current.Source] = time.Now()
+		if r.pending == nil {
+			r.afterSave(func() {
+				if err := r.history.UpdateListened(current.TrackID, listenedMs); err != nil {
+					log.Printf("Failed to update listening time of %s: %v", current.TrackID, err)
+				}
+			})
+		} else if time.Duration(listenedMs)*time.Millisecond >= r.scrobblePolicy.threshold(r.pending.Track, durationMs) {
+			r.recordPlay(withDuration(r.pending.Track, durationMs), r.pending.StartedAt, listenedMs)
+			r.pending = nil
+		}
+		return true
+	})
+}
+
+// ExpireStale stops the current track if no update arrived within ttl, so
+// clients and the chat assistant stop referring to a song that ended long ago.
+// It returns true if a track was stopped.
+func (r *MemoryMusicRepository) ExpireStale(ttl time.Duration) bool {
+	r.updateMutex.Lock()
+	defer r.updateMutex.Unlock()
+
+	return r.update(func() bool {
+		last := r.nowPlaying.Get()
+		if !r.nowPlaying.StopIfStale(ttl) {
+			return false
+		}
+		r.finishPlay(&last)
+		return true
+	})
+}
+
+// StartStaleExpiry periodically expires stale now-playing entries until ctx is cancelled
+func (r *MemoryMusicRepository) StartStaleExpiry(ctx context.Context, ttl time.Duration) {
+	interval := ttl / 10
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if r.ExpireStale(ttl) {
+					log.Printf("Now playing expired after %s without updates", ttl)
+				}
+			}
+		}
+	}()
+}
+
 // GetPlayHistory returns the play history
-func (r *MusicRepository) GetPlayHistory() []models.PlayHistoryItem {
-	return r.playHistory.GetItems()
+func (r *MemoryMusicRepository) GetPlayHistory() []models.PlayHistoryItem {
+	items, err := r.historyStore().Recent()
+	if err != nil {
+		log.Printf("Failed to load play history: %v", err)
+		return []models.PlayHistoryItem{}
+	}
+	return items
+}
+
+// QueryPlayHistory returns a page of the play history items matching filter
+// and the number of matching items
+func (r *MemoryMusicRepository) QueryPlayHistory(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error) {
+	return r.historyStore().Query(filter, offset, limit)
+}
+
+// historyStore returns the current HistoryStore
+func (r *MemoryMusicRepository) historyStore() HistoryStore {
+	r.updateMutex.Lock()
+	defer r.updateMutex.Unlock()
+	return r.history
 }
 
 // GetLyricsForCurrentSong fetches lyrics for the current song
-func (r *MusicRepository) GetLyricsForCurrentSong() (string, error) {
+func (r *MemoryMusicRepository) GetLyricsForCurrentSong() (string, error) {
+	r.refresh()
 	current := r.nowPlaying.Get()
 	
 	// Check if we have a current song
@@ -67,7 +548,7 @@ func (r *MusicRepository) GetLyricsForCurrentSong() (string, error) {
 
 	// Check memory cache
 	cacheKey := fmt.Sprintf("%s|%s", current.TrackName, current.Artist)
-	if lyrics, ok := r.lyricsCache[cacheKey]; ok {
+	if lyrics, ok := r.lyricsCache.get(cacheKey); ok {
 		r.nowPlaying.UpdateLyrics(lyrics)
 		return lyrics, nil
 	}
@@ -79,13 +560,14 @@ func (r *MusicRepository) GetLyricsForCurrentSong() (string, error) {
 	}
 
 	// Cache the lyrics
-	r.lyricsCache[cacheKey] = lyrics
+	r.lyricsCache.put(cacheKey, lyrics)
 	r.nowPlaying.UpdateLyrics(lyrics)
 
 	return lyrics, nil
 }
 
 // GetCurrentSongInfo returns formatted information about the current song
-func (r *MusicRepository) GetCurrentSongInfo() string {
+func (r *MemoryMusicRepository) GetCurrentSongInfo() string {
+	r.refresh()
 	return r.nowPlaying.GetInfo()
 }
\ No newline at end of file
@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HistorySchema creates the play_history table used by the Postgres history store
+const HistorySchema = `
+        CREATE TABLE IF NOT EXISTS play_history (
+            id BIGSERIAL PRIMARY KEY,
+            track_id TEXT NOT NULL,
+            track_name TEXT NOT NULL,
+            artist TEXT NOT NULL,
+            album TEXT NOT NULL,
+            source TEXT NOT NULL,
+            played_at TIMESTAMP WITH TIME ZONE NOT NULL,
+            last_played_at TIMESTAMP WITH TIME ZONE NOT NULL,
+            play_count INT NOT NULL,
+            listened_ms BIGINT NOT NULL
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_play_history_played_at ON play_history(played_at DESC);
+    `
+
+// postgresHistoryStore keeps the full play history in the play_history table
+type postgresHistoryStore struct {
+	db           *sql.DB
+	recent       int // Items returned by Recent
+	repeatWindow time.Duration
+}
+
+// NewPostgresHistoryStore creates a HistoryStore backed by the table in
+// HistorySchema, collapsing repeats within repeatWindow (0 disables this).
+// Every play is kept; Recent returns the latest recent of them.
+func NewPostgresHistoryStore(db *sql.DB, recent int, repeatWindow time.Duration) HistoryStore {
+	return &postgresHistoryStore{db: db, recent: recent, repeatWindow: repeatWindow}
+}
+
+// historyColumns are selected by scanHistoryItem, in order
+const historyColumns = `id, track_id, track_name, artist, album, source, played_at, last_played_at, play_count, listened_ms`
+
+// Add records a play, folding a repeat into the most recent item
+func (p *postgresHistoryStore) Add(track models.UnifiedTrack, playedAt time.Time, listenedMs int64) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin play history transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, latest, err := scanHistoryItem(tx.QueryRow(`SELECT ` + historyColumns + ` FROM play_history ORDER BY id DESC LIMIT 1 FOR UPDATE`))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("failed to query latest play: %w", err)
+	case latest.Repeat(track, playedAt, listenedMs, p.repeatWindow):
+		_, err := tx.Exec(`
+            UPDATE play_history SET last_played_at = $1, play_count = $2, listened_ms = $3
+            WHERE id = $4
+        `, latest.LastPlayedAt, latest.PlayCount, latest.ListenedMs, id)
+		if err != nil {
+			return fmt.Errorf("failed to update repeated play: %w", err)
+		}
+		return tx.Commit()
+	}
+
+	item := models.NewPlayHistoryItem(track, playedAt, listenedMs)
+	_, err = tx.Exec(`
+        INSERT INTO play_history (track_id, track_name, artist, album, source, played_at, last_played_at, play_count, listened_ms)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `, item.TrackID, item.TrackName, item.Artist, item.Album, item.Source, item.PlayedAt, item.LastPlayedAt, item.PlayCount, item.ListenedMs)
+	if err != nil {
+		return fmt.Errorf("failed to insert play: %w", err)
+	}
+	return tx.Commit()
+}
+
+// UpdateListened sets the listening time of the most recent item
+func (p *postgresHistoryStore) UpdateListened(trackID string, listenedMs int64) error {
+	_, err := p.db.Exec(`
+        UPDATE play_history SET listened_ms = $1
+        WHERE id = (SELECT MAX(id) FROM play_history) AND track_id = $2
+    `, listenedMs, trackID)
+	if err != nil {
+		return fmt.Errorf("failed to update listening time: %w", err)
+	}
+	return nil
+}
+
+// Recent returns the latest items
+func (p *postgresHistoryStore) Recent() ([]models.PlayHistoryItem, error) {
+	items, _, err := p.Query(models.PlayHistoryFilter{}, 0, p.recent)
+	return items, err
+}
+
+// Query returns a page of matching items
+func (p *postgresHistoryStore) Query(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Source != "" {
+		where("source = $%d", filter.Source)
+	}
+	if filter.Artist != "" {
+		where("LOWER(artist) = LOWER($%d)", filter.Artist)
+	}
+	if !filter.From.IsZero() {
+		where("played_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("played_at < $%d", filter.To)
+	}
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := p.db.QueryRow(`SELECT COUNT(*) FROM play_history `+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count plays: %w", err)
+	}
+
+	args = append(args, limit, offset)
+	rows, err := p.db.Query(fmt.Sprintf(`SELECT %s FROM play_history %s ORDER BY id DESC LIMIT $%d OFFSET $%d`,
+		historyColumns, whereClause, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query plays: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.PlayHistoryItem{}
+	for rows.Next() {
+		_, item, err := scanHistoryItem(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan play: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, total, rows.Err()
+}
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanHistoryItem reads a row of historyColumns
+func scanHistoryItem(row rowScanner) (int64, models.PlayHistoryItem, error) {
+	var id int64
+	var item models.PlayHistoryItem
+	err := row.Scan(&id, &item.TrackID, &item.TrackName, &item.Artist, &item.Album, &item.Source,
+		&item.PlayedAt, &item.LastPlayedAt, &item.PlayCount, &item.ListenedMs)
+	return id, item, err
+}
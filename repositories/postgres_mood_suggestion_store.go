@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// MoodSuggestionsSchema creates the mood_suggestions table used by the
+// Postgres mood suggestion store
+const MoodSuggestionsSchema = `
+        CREATE TABLE IF NOT EXISTS mood_suggestions (
+            id BIGSERIAL PRIMARY KEY,
+            mood TEXT NOT NULL,
+            track JSONB NOT NULL,
+            match_reason TEXT NOT NULL DEFAULT '',
+            mood_score DOUBLE PRECISION NOT NULL,
+            updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_mood_suggestions_mood ON mood_suggestions(mood);
+    `
+
+// postgresMoodSuggestionStore keeps the mood catalog in the mood_suggestions table
+type postgresMoodSuggestionStore struct {
+	db *sql.DB
+}
+
+// NewPostgresMoodSuggestionStore creates a MoodSuggestionStore backed by the
+// table in MoodSuggestionsSchema
+func NewPostgresMoodSuggestionStore(db *sql.DB) MoodSuggestionStore {
+	return &postgresMoodSuggestionStore{db: db}
+}
+
+// List returns every suggestion, in the order they were added
+func (p *postgresMoodSuggestionStore) List() ([]models.MoodSuggestion, error) {
+	rows, err := p.db.Query(`
+        SELECT id, mood, track, match_reason, mood_score, updated_at
+        FROM mood_suggestions ORDER BY id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mood suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := []models.MoodSuggestion{}
+	for rows.Next() {
+		var suggestion models.MoodSuggestion
+		var track []byte
+		if err := rows.Scan(&suggestion.ID, &suggestion.Mood, &track, &suggestion.MatchReason, &suggestion.MoodScore, &suggestion.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan mood suggestion: %w", err)
+		}
+		if err := json.Unmarshal(track, &suggestion.Track); err != nil {
+			return nil, fmt.Errorf("failed to decode track of mood suggestion %d: %w", suggestion.ID, err)
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, rows.Err()
+}
+
+// Add stores a new suggestion
+func (p *postgresMoodSuggestionStore) Add(suggestion models.MoodSuggestion) (models.MoodSuggestion, error) {
+	track, err := json.Marshal(suggestion.Track)
+	if err != nil {
+		return suggestion, fmt.Errorf("failed to encode track: %w", err)
+	}
+
+	err = p.db.QueryRow(`
+        INSERT INTO mood_suggestions (mood, track, match_reason, mood_score)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, updated_at
+    `, suggestion.Mood, track, suggestion.MatchReason, suggestion.MoodScore).Scan(&suggestion.ID, &suggestion.UpdatedAt)
+	if err != nil {
+		return suggestion, fmt.Errorf("failed to insert mood suggestion: %w", err)
+	}
+	return suggestion, nil
+}
+
+// Update replaces the suggestion with the same ID
+func (p *postgresMoodSuggestionStore) Update(suggestion models.MoodSuggestion) error {
+	track, err := json.Marshal(suggestion.Track)
+	if err != nil {
+		return fmt.Errorf("failed to encode track: %w", err)
+	}
+
+	result, err := p.db.Exec(`
+        UPDATE mood_suggestions SET mood = $1, track = $2, match_reason = $3, mood_score = $4, updated_at = $5
+        WHERE id = $6
+    `, suggestion.Mood, track, suggestion.MatchReason, suggestion.MoodScore, suggestion.UpdatedAt, suggestion.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update mood suggestion: %w", err)
+	}
+	return requireRow(result)
+}
+
+// Delete removes a suggestion
+func (p *postgresMoodSuggestionStore) Delete(id int64) error {
+	result, err := p.db.Exec(`DELETE FROM mood_suggestions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete mood suggestion: %w", err)
+	}
+	return requireRow(result)
+}
+
+// requireRow returns ErrMoodSuggestionNotFound if result affected no rows
+func requireRow(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrMoodSuggestionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// NowPlayingSchema creates the single-row now_playing table used by the
+// Postgres now-playing store
+const NowPlayingSchema = `
+        CREATE TABLE IF NOT EXISTS now_playing (
+            id SMALLINT PRIMARY KEY CHECK (id = 1),
+            snapshot JSONB NOT NULL,
+            saved_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+        );
+    `
+
+// postgresNowPlayingStore keeps the now-playing snapshot in the now_playing table
+type postgresNowPlayingStore struct {
+	db *sql.DB
+}
+
+// NewPostgresNowPlayingStore creates a NowPlayingStore backed by the table in NowPlayingSchema
+func NewPostgresNowPlayingStore(db *sql.DB) NowPlayingStore {
+	return &postgresNowPlayingStore{db: db}
+}
+
+// Save replaces the snapshot
+func (p *postgresNowPlayingStore) Save(snapshot *models.NowPlaying) error {
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode now playing: %w", err)
+	}
+
+	_, err = p.db.Exec(`
+        INSERT INTO now_playing (id, snapshot, saved_at)
+        VALUES (1, $1, NOW())
+        ON CONFLICT (id) DO UPDATE SET snapshot = EXCLUDED.snapshot, saved_at = EXCLUDED.saved_at
+    `, encoded)
+	if err != nil {
+		return fmt.Errorf("failed to save now playing: %w", err)
+	}
+	return nil
+}
+
+// Load returns the snapshot
+func (p *postgresNowPlayingStore) Load() (*models.NowPlaying, bool, error) {
+	var encoded []byte
+	err := p.db.QueryRow(`SELECT snapshot FROM now_playing WHERE id = 1`).Scan(&encoded)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load now playing: %w", err)
+	}
+
+	var snapshot models.NowPlaying
+	if err := json.Unmarshal(encoded, &snapshot); err != nil {
+		return nil, false, fmt.Errorf("failed to decode now playing: %w", err)
+	}
+	return &snapshot, true, nil
+}
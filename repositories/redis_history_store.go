@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"backend/server/models"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// replaceLatestScript updates the list at KEYS[1] only if its first item is
+// still ARGV[1], an empty ARGV[1] standing for an empty list: ARGV[3] "set"
+// replaces the item with ARGV[2], and "push" adds ARGV[2] before it and
+// keeps the first ARGV[4] items. It returns 1 if the list was updated and 0
+// otherwise.
+const replaceLatestScript = `
+local latest = redis.call('LINDEX', KEYS[1], 0) or ''
+if latest ~= ARGV[1] then
+  return 0
+end
+if ARGV[3] == 'push' then
+  redis.call('LPUSH', KEYS[1], ARGV[2])
+  redis.call('LTRIM', KEYS[1], 0, tonumber(ARGV[4]) - 1)
+else
+  redis.call('LSET', KEYS[1], 0, ARGV[2])
+end
+return 1
+`
+
+// maxHistoryAttempts is how often an update of the latest item is tried
+// while other replicas keep changing it
+const maxHistoryAttempts = 5
+
+// redisHistoryStore keeps the latest plays in a Redis list, most recent first,
+// so every replica sees the same history
+type redisHistoryStore struct {
+	client       RedisClient
+	key          string
+	size         int
+	repeatWindow time.Duration
+}
+
+// NewRedisHistoryStore creates a HistoryStore keeping the latest size items
+// at keyPrefix + "play_history", collapsing repeats within repeatWindow
+// (0 disables this)
+func NewRedisHistoryStore(client RedisClient, keyPrefix string, size int, repeatWindow time.Duration) HistoryStore {
+	return &redisHistoryStore{
+		client:       client,
+		key:          keyPrefix + "play_history",
+		size:         size,
+		repeatWindow: repeatWindow,
+	}
+}
+
+// Add records a play, folding a repeat into the most recent item. The
+// repeat is decided against the latest item as loaded and applied only if no
+// other replica changed it in between, otherwise it is decided again.
+func (r *redisHistoryStore) Add(track models.UnifiedTrack, playedAt time.Time, listenedMs int64) error {
+	return r.updateLatest(func(latest models.PlayHistoryItem, ok bool) (models.PlayHistoryItem, bool) {
+		if ok && latest.Repeat(track, playedAt, listenedMs, r.repeatWindow) {
+			return latest, false
+		}
+		return models.NewPlayHistoryItem(track, playedAt, listenedMs), true
+	})
+}
+
+// UpdateListened sets the listening time of the most recent item
+func (r *redisHistoryStore) UpdateListened(trackID string, listenedMs int64) error {
+	return r.updateLatest(func(latest models.PlayHistoryItem, ok bool) (models.PlayHistoryItem, bool) {
+		if !ok || latest.TrackID != trackID {
+			return latest, false
+		}
+		latest.ListenedMs = listenedMs
+		return latest, false
+	})
+}
+
+// Recent returns every kept item
+func (r *redisHistoryStore) Recent() ([]models.PlayHistoryItem, error) {
+	reply, err := r.client.Do("LRANGE", r.key, "0", "-1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load play history: %w", err)
+	}
+	values, _ := reply.([]interface{})
+
+	items := make([]models.PlayHistoryItem, 0, len(values))
+	for _, value := range values {
+		var item models.PlayHistoryItem
+		encoded, _ := value.(string)
+		if err := json.Unmarshal([]byte(encoded), &item); err != nil {
+			return nil, fmt.Errorf("failed to decode play: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Query returns a page of matching items
+func (r *redisHistoryStore) Query(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error) {
+	items, err := r.Recent()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page, total := filter.Page(items, offset, limit)
+	return page, total, nil
+}
+
+// updateLatest applies change to the most recent item, or false if the
+// history is empty, and replaces the item with the result, or pushes the
+// result as a new item if change returns true. If another replica changed
+// the most recent item in between, it is tried again, up to
+// maxHistoryAttempts times.
+func (r *redisHistoryStore) updateLatest(change func(latest models.PlayHistoryItem, ok bool) (models.PlayHistoryItem, bool)) error {
+	for attempt := 1; ; attempt++ {
+		var latest models.PlayHistoryItem
+		reply, err := r.client.Do("LINDEX", r.key, "0")
+		if err != nil {
+			return fmt.Errorf("failed to load latest play: %w", err)
+		}
+		loaded, ok := reply.(string)
+		if ok {
+			if err := json.Unmarshal([]byte(loaded), &latest); err != nil {
+				return fmt.Errorf("failed to decode play: %w", err)
+			}
+		}
+
+		item, push := change(latest, ok)
+		if !push && !ok {
+			return nil
+		}
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to encode play: %w", err)
+		}
+		if !push && string(encoded) == loaded {
+			return nil
+		}
+		mode := "set"
+		if push {
+			mode = "push"
+		}
+		reply, err = r.client.Do("EVAL", replaceLatestScript, "1", r.key, loaded, string(encoded), mode, strconv.Itoa(r.size))
+		if err != nil {
+			return fmt.Errorf("failed to update play history: %w", err)
+		}
+		if replaced, _ := reply.(int64); replaced == 1 {
+			return nil
+		}
+		if attempt == maxHistoryAttempts {
+			return fmt.Errorf("failed to update play history: changed by another replica %d times", attempt)
+		}
+	}
+}
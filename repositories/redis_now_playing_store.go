@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"backend/server/models"
+	"encoding/json"
+	"fmt"
+)
+
+// replaceIfUnchangedScript sets KEYS[1] to ARGV[2] only if it still holds
+// ARGV[1], an empty ARGV[1] standing for a missing key. It returns 1 if the
+// value was replaced and 0 otherwise.
+const replaceIfUnchangedScript = `
+local current = redis.call('GET', KEYS[1]) or ''
+if current ~= ARGV[1] then
+  return 0
+end
+redis.call('SET', KEYS[1], ARGV[2])
+return 1
+`
+
+// redisNowPlayingStore keeps the now-playing state in a Redis string, so
+// every replica sees the same playback
+type redisNowPlayingStore struct {
+	client RedisClient
+	key    string
+}
+
+// NewRedisNowPlayingStore creates a SharedNowPlayingStore keeping the state
+// at keyPrefix + "now_playing". Its revisions are the encoded state, so a
+// save is compared and applied in one script.
+func NewRedisNowPlayingStore(client RedisClient, keyPrefix string) SharedNowPlayingStore {
+	return &redisNowPlayingStore{client: client, key: keyPrefix + "now_playing"}
+}
+
+// Save replaces the snapshot
+func (r *redisNowPlayingStore) Save(snapshot *models.NowPlaying) error {
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode now playing: %w", err)
+	}
+	if _, err := r.client.Do("SET", r.key, string(encoded)); err != nil {
+		return fmt.Errorf("failed to save now playing: %w", err)
+	}
+	return nil
+}
+
+// Load returns the snapshot
+func (r *redisNowPlayingStore) Load() (*models.NowPlaying, bool, error) {
+	state, revision, err := r.LoadShared()
+	if err != nil || revision == "" || state.NowPlaying == nil {
+		return nil, false, err
+	}
+	return state.NowPlaying, true, nil
+}
+
+// LoadShared returns the state and its revision
+func (r *redisNowPlayingStore) LoadShared() (SharedNowPlaying, string, error) {
+	var state SharedNowPlaying
+	reply, err := r.client.Do("GET", r.key)
+	if err != nil {
+		return state, "", fmt.Errorf("failed to load now playing: %w", err)
+	}
+	encoded, ok := reply.(string)
+	if !ok {
+		return state, "", nil
+	}
+
+	if err := json.Unmarshal([]byte(encoded), &state); err != nil {
+		return state, "", fmt.Errorf("failed to decode now playing: %w", err)
+	}
+	return state, encoded, nil
+}
+
+// SaveShared replaces the state if it is still at revision
+func (r *redisNowPlayingStore) SaveShared(state SharedNowPlaying, revision string) (bool, error) {
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode now playing: %w", err)
+	}
+	reply, err := r.client.Do("EVAL", replaceIfUnchangedScript, "1", r.key, revision, string(encoded))
+	if err != nil {
+		return false, fmt.Errorf("failed to save now playing: %w", err)
+	}
+	replaced, _ := reply.(int64)
+	return replaced == 1, nil
+}
@@ -0,0 +1,76 @@
+// Package apierror writes error responses as a JSON envelope with a typed
+// code, e.g.
+//
+//	{"error": {"code": "not_playing", "message": "No song is currently playing"}}
+package apierror
+
+import (
+	"backend/server/models"
+	"encoding/json"
+	"net/http"
+)
+
+// Error codes
+const (
+	InvalidRequest      = "invalid_request"      // The request is malformed or fails validation
+	Unauthorized        = "unauthorized"         // A valid API key is required
+	Forbidden           = "forbidden"            // The request is not allowed for this caller
+	NotFound            = "not_found"            // The resource or route doesn't exist
+	NotPlaying          = "not_playing"          // No song is currently playing
+	MethodNotAllowed    = "method_not_allowed"   // The route doesn't support the method
+	Conflict            = "conflict"             // The request conflicts with the current state
+	RateLimited         = "rate_limited"         // Too many requests; see Retry-After
+	Internal            = "internal_error"       // Something failed on the server
+	UpstreamUnavailable = "upstream_unavailable" // An AI provider or other upstream service failed
+)
+
+// Write sends an error response with the given status, code and message
+func Write(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.ErrorResponse{
+		Error: models.ErrorDetail{Code: code, Message: message},
+	})
+}
+
+// CodeForStatus returns the generic code for an HTTP status, for errors whose
+// status is decided elsewhere
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusUpgradeRequired:
+		return InvalidRequest
+	case http.StatusUnauthorized:
+		return Unauthorized
+	case http.StatusForbidden:
+		return Forbidden
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusMethodNotAllowed:
+		return MethodNotAllowed
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusTooManyRequests:
+		return RateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return UpstreamUnavailable
+	}
+	if status >= 500 {
+		return Internal
+	}
+	return InvalidRequest
+}
+
+// NotFoundHandler responds to unknown routes
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, http.StatusNotFound, NotFound, "No route for "+r.URL.Path)
+	})
+}
+
+// MethodNotAllowedHandler responds to known routes called with the wrong method
+func MethodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, http.StatusMethodNotAllowed, MethodNotAllowed, r.Method+" is not allowed on "+r.URL.Path)
+	})
+}
@@ -1,28 +1,23 @@
 package database
 
 import (
+	"backend/config"
 	"database/sql"
-	"fmt"
 	_ "github.com/lib/pq"
-	"os"
 )
 
-func InitDB() (*sql.DB, error) {
-	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-	)
-
-	db, err := sql.Open("postgres", connStr)
+// InitDB connects to the database, sizing its connection pool from cfg
+func InitDB(cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.URL())
 	if err != nil {
 		return nil, err
 	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	if err = db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/albums"
+	"backend/services/jobqueue"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// SetAlbums sets the service analyzing albums. Without it the album analysis
+// endpoints answer 404.
+func (h *LyricsHandler) SetAlbums(albums albums.Service) {
+	h.albums = albums
+}
+
+// GetAlbumAnalysis handles GET /api/albums/{id}/analysis, returning the
+// album's cached analysis. It answers 404 until the album has been analyzed
+// with POST. The narrative is masked in restricted and clean mode (?clean=true).
+func (h *LyricsHandler) GetAlbumAnalysis(w http.ResponseWriter, r *http.Request) {
+	if h.albums == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Album analyses are not available")
+		return
+	}
+
+	analysis, ok := h.albums.Cached(mux.Vars(r)["id"])
+	if !ok {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "The album has not been analyzed; POST to this URL to analyze it")
+		return
+	}
+	h.writeAlbumAnalysis(w, r, analysis)
+}
+
+// AnalyzeAlbum handles POST /api/albums/{id}/analysis.
+// A cached analysis is returned at once. Otherwise an album_analysis job is
+// queued and returned with 202 Accepted; its result is the analysis, which
+// GET then returns too.
+func (h *LyricsHandler) AnalyzeAlbum(w http.ResponseWriter, r *http.Request) {
+	if h.albums == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Album analyses are not available")
+		return
+	}
+
+	albumID := mux.Vars(r)["id"]
+	if analysis, ok := h.albums.Cached(albumID); ok {
+		h.writeAlbumAnalysis(w, r, analysis)
+		return
+	}
+	if h.jobQueue == nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Background analysis is not available")
+		return
+	}
+
+	job, err := h.jobQueue.Enqueue(userIDFromRequest(r), models.JobTypeAlbumAnalysis, models.AlbumAnalysisRequest{AlbumID: albumID})
+	if errors.Is(err, jobqueue.ErrQueueFull) {
+		w.Header().Set("Retry-After", "60")
+		apierror.Write(w, http.StatusServiceUnavailable, apierror.RateLimited, "Too many background jobs are waiting; try again later")
+		return
+	}
+	if err != nil {
+		log.Printf("Error queueing analysis of album %s: %v", albumID, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to queue the analysis")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// AlbumAnalysisJob runs an album_analysis job. Tracks analyzed before a failed
+// attempt are cached by the mood service, so a retry picks up where it stopped.
+func (h *LyricsHandler) AlbumAnalysisJob(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var req models.AlbumAnalysisRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, fmt.Errorf("invalid album_analysis payload: %w", err)
+	}
+	if h.albums == nil {
+		return nil, errors.New("album analyses are not available")
+	}
+	return h.albums.Analyze(ctx, req.AlbumID)
+}
+
+// writeAlbumAnalysis writes an album analysis, masking the narrative for
+// restricted and clean users
+func (h *LyricsHandler) writeAlbumAnalysis(w http.ResponseWriter, r *http.Request, analysis *models.AlbumAnalysis) {
+	userID := userIDFromRequest(r)
+	if h.isRestricted(userID) || h.isClean(userID, cleanRequested(r)) {
+		analysis.Narrative = h.cleanMode.Mask(analysis.Narrative)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analysis)
+}
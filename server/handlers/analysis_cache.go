@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/analysiscache"
+	"backend/services/prompts"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// SetAnalysisCache sets the store of answers to common lyrics questions.
+// Without it every lyrics question is sent to the AI, and the stored
+// analysis endpoints answer 404.
+func (h *LyricsHandler) SetAnalysisCache(analyses analysiscache.Service) {
+	h.analyses = analyses
+}
+
+// RateAnalysis handles POST /api/analyses/{id}/rating, recording whether the
+// caller found a stored analysis helpful. Each user has one rating per
+// analysis; rating again replaces it.
+func (h *LyricsHandler) RateAnalysis(w http.ResponseWriter, r *http.Request) {
+	if h.analyses == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Stored analyses are not available")
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid analysis ID")
+		return
+	}
+	var req models.RateAnalysisRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+	if req.Helpful == nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "helpful is required")
+		return
+	}
+
+	analysis, err := h.analyses.Rate(id, userIDFromRequest(r), *req.Helpful)
+	if errors.Is(err, analysiscache.ErrNotFound) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Analysis not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error rating analysis %d: %v", id, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to save the rating")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analysis)
+}
+
+// GetTrackAnalyses handles GET /api/tracks/{id}/analyses, listing the stored
+// answers to common questions about a track, best rated first. Restricted
+// users and clean mode requests (?clean=true) get the answers written for them.
+func (h *LyricsHandler) GetTrackAnalyses(w http.ResponseWriter, r *http.Request) {
+	if h.analyses == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Stored analyses are not available")
+		return
+	}
+
+	trackID := mux.Vars(r)["id"]
+	analyses, err := h.analyses.ForTrack(trackID, h.analysisVariant(userIDFromRequest(r), cleanRequested(r)))
+	if err != nil {
+		log.Printf("Error listing analyses of track %s: %v", trackID, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to list the analyses")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analyses)
+}
+
+// analysisKey returns the key a lyrics question's answer is stored under for
+// the current song, or false if the answer can't be shared: the question is
+// too specific, or the user is in a lyrics_analysis experiment, whose
+// variants must each get their own answers
+func (h *LyricsHandler) analysisKey(query, userID, lang string, clean bool) (analysiscache.Key, bool) {
+	if h.analyses == nil {
+		return analysiscache.Key{}, false
+	}
+	category := h.analyses.Category(query)
+	if category == "" {
+		return analysiscache.Key{}, false
+	}
+	if _, ok := h.assign(prompts.LyricsAnalysis, userID); ok {
+		return analysiscache.Key{}, false
+	}
+
+	track := h.musicRepo.GetNowPlaying()
+	trackID := track.TrackID
+	if trackID == "" {
+		trackID = strings.ToLower(track.TrackName + " - " + track.Artist)
+	}
+	return analysiscache.Key{
+		TrackID:  trackID,
+		Category: category,
+		Language: lang,
+		Variant:  h.analysisVariant(userID, clean),
+	}, true
+}
+
+// analysisVariant returns the variant of stored answers a user gets
+func (h *LyricsHandler) analysisVariant(userID string, clean bool) string {
+	if h.isRestricted(userID) {
+		return "restricted"
+	}
+	if h.isClean(userID, clean) {
+		return "clean"
+	}
+	return ""
+}
+
+// reanalyzeLyrics answers a common question again after users rated the
+// stored answers down, asking for a different answer than those
+func (h *LyricsHandler) reanalyzeLyrics(query, lyrics, songInfo, userID string, clean bool, rejected []string) (string, error) {
+	if h.isRestricted(userID) || h.isClean(userID, clean) {
+		lyrics = h.cleanMode.Mask(lyrics) // The prompt embeds them as they are
+	}
+	if fitter, ok := h.aiService.(lyricsFitter); ok {
+		var err error
+		if lyrics, err = fitter.FitLyrics(lyrics); err != nil {
+			return "", err
+		}
+	}
+	return h.analyses.Reanalyze(h.aiForRequest(userID, clean), query, lyrics, songInfo, rejected)
+}
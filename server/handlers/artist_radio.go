@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"backend/server/models"
+	"backend/services/events"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+const (
+	// radioSeedTracks is how many of the seed artist's top tracks are included
+	radioSeedTracks = 3
+	// radioRelatedArtists is how many similar artists are pulled in
+	radioRelatedArtists = 5
+	// radioTracksPerRelated is how many top tracks are taken from each similar artist
+	radioTracksPerRelated = 2
+)
+
+// artistRadioPatterns introduce the seed artist in a radio request
+var artistRadioPatterns = []string{
+	"something like ", "similar to ", "music like ", "songs like ",
+	"artists like ", "bands like ", "sounds like ", "more like ",
+}
+
+// isArtistRadioQuery checks if a query asks for music similar to an artist
+func (h *LyricsHandler) isArtistRadioQuery(query string) bool {
+	return h.extractRadioArtist(query) != ""
+}
+
+// extractRadioArtist extracts the seed artist from a radio request
+func (h *LyricsHandler) extractRadioArtist(query string) string {
+	lowerQuery := strings.ToLower(query)
+
+	for _, pattern := range artistRadioPatterns {
+		idx := strings.Index(lowerQuery, pattern)
+		if idx == -1 {
+			continue
+		}
+
+		// Keep the original casing of the artist name
+		artist := strings.TrimSpace(query[idx+len(pattern):])
+		artist = strings.TrimRight(artist, "?!.")
+		artist = strings.TrimSuffix(artist, " please")
+		artist = strings.Trim(artist, `"'`)
+		return strings.TrimSpace(artist)
+	}
+
+	return ""
+}
+
+// handleArtistRadioQuery builds a radio list seeded by the artist mentioned in the query
+func (h *LyricsHandler) handleArtistRadioQuery(query, userID, lang string) models.ChatResponse {
+	artistName := h.extractRadioArtist(query)
+
+	seed, err := h.spotifyService.SearchArtist(artistName)
+	if err != nil {
+		log.Printf("Error resolving radio artist %q: %v", artistName, err)
+		return models.ChatResponse{
+			Answer: localize(lang, msgArtistNotFound, artistName),
+		}
+	}
+
+	// Blend in the user's taste: artists they've recently played rank higher
+	recentArtists := make(map[string]bool)
+	for _, item := range h.musicRepo.GetPlayHistory() {
+		recentArtists[strings.ToLower(item.Artist)] = true
+	}
+
+	var radioTracks []models.RadioTrack
+	seen := make(map[string]bool)
+	addTracks := func(tracks []models.UnifiedTrack, limit int, score float64, reason string) {
+		if h.isRestricted(userID) {
+			tracks = h.restrictions.FilterTracks(tracks)
+		}
+		added := 0
+		for _, track := range tracks {
+			if added >= limit {
+				break
+			}
+			if seen[track.ID] {
+				continue
+			}
+			seen[track.ID] = true
+			added++
+
+			trackReason := reason
+			trackScore := score
+			if recentArtists[strings.ToLower(track.Artist)] {
+				trackScore += 0.15
+				trackReason += fmt.Sprintf(" - and you've been listening to %s lately", track.Artist)
+			}
+			if trackScore > 1 {
+				trackScore = 1
+			}
+
+			radioTracks = append(radioTracks, models.RadioTrack{
+				Track:  track,
+				Reason: trackReason,
+				Score:  trackScore,
+			})
+		}
+	}
+
+	// Seed artist's own hits
+	if topTracks, err := h.spotifyService.GetArtistTopTracks(seed.ID); err != nil {
+		log.Printf("Error getting top tracks for %s: %v", seed.Name, err)
+	} else {
+		addTracks(topTracks, radioSeedTracks, 0.9, fmt.Sprintf("One of %s's most popular tracks", seed.Name))
+	}
+
+	// Similar artists' hits
+	related, err := h.spotifyService.GetRelatedArtists(seed.ID)
+	if err != nil {
+		log.Printf("Error getting related artists for %s: %v", seed.Name, err)
+	}
+	for i, artist := range related {
+		if i >= radioRelatedArtists {
+			break
+		}
+
+		topTracks, err := h.spotifyService.GetArtistTopTracks(artist.ID)
+		if err != nil {
+			log.Printf("Error getting top tracks for %s: %v", artist.Name, err)
+			continue
+		}
+
+		// Closer matches are listed first by Spotify, so score them slightly higher
+		score := 0.8 - float64(i)*0.05
+		addTracks(topTracks, radioTracksPerRelated, score, fmt.Sprintf("%s is similar to %s", artist.Name, seed.Name))
+	}
+
+	if len(radioTracks) == 0 {
+		return models.ChatResponse{
+			Answer: localize(lang, msgRadioUnavailable, seed.Name),
+		}
+	}
+
+	sort.SliceStable(radioTracks, func(i, j int) bool {
+		return radioTracks[i].Score > radioTracks[j].Score
+	})
+
+	log.Printf("Artist radio for %s: %d tracks from %d similar artists", seed.Name, len(radioTracks), len(related))
+
+	recommended := make([]models.UnifiedTrack, len(radioTracks))
+	for i, radioTrack := range radioTracks {
+		recommended[i] = radioTrack.Track
+	}
+	h.publish(events.RecommendationServed, events.RecommendationServedPayload{
+		UserID: userID,
+		Kind:   "artist_radio",
+		Tracks: recommended,
+	})
+
+	return models.ChatResponse{
+		Answer: localize(lang, msgRadio, seed.Name),
+		Type:   "artist_radio",
+		Radio: &models.ArtistRadio{
+			SeedArtist: seed.Name,
+			Tracks:     radioTracks,
+		},
+	}
+}
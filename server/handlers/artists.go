@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/breaker"
+	"backend/services/cleanmode"
+	"backend/services/genius"
+	"backend/services/restricted"
+	"backend/services/spotify"
+	"backend/services/style"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// ArtistsHandler serves the artist cards of the chat UI
+type ArtistsHandler struct {
+	genius       genius.Service
+	spotify      spotify.Service
+	restrictions restricted.Service // Optional; leaves explicit top tracks out in restricted mode
+	cleanMode    cleanmode.Service  // Optional; masks styles for users in clean mode
+	style        style.Service      // Optional; analyzes artists' lyrical style
+}
+
+// NewArtistsHandler creates a new artists handler
+func NewArtistsHandler(geniusService genius.Service, spotifyService spotify.Service) *ArtistsHandler {
+	return &ArtistsHandler{
+		genius:  geniusService,
+		spotify: spotifyService,
+	}
+}
+
+// SetRestrictions sets the service deciding which users are in restricted mode
+func (h *ArtistsHandler) SetRestrictions(restrictions restricted.Service) {
+	h.restrictions = restrictions
+}
+
+// SetCleanMode sets the service deciding which users are in clean mode
+func (h *ArtistsHandler) SetCleanMode(cleanMode cleanmode.Service) {
+	h.cleanMode = cleanMode
+}
+
+// SetStyle sets the service analyzing artists' lyrical style. Without it
+// /api/artists/{name}/style answers 404.
+func (h *ArtistsHandler) SetStyle(style style.Service) {
+	h.style = style
+}
+
+// GetArtist handles GET /api/artists/{name}.
+// Genius and Spotify are asked at the same time; the artist is returned with
+// whatever either of them knows, and is only missing if both come up empty.
+func (h *ArtistsHandler) GetArtist(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(mux.Vars(r)["name"])
+	if name == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Artist name is required")
+		return
+	}
+
+	var (
+		geniusArtist          *models.GeniusArtist
+		spotifyArtist         *models.SpotifyArtist
+		topTracks             []models.UnifiedTrack
+		geniusErr, spotifyErr error
+		wg                    sync.WaitGroup
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		geniusArtist, geniusErr = h.genius.GetArtist(name)
+	}()
+	go func() {
+		defer wg.Done()
+		spotifyArtist, spotifyErr = h.spotify.SearchArtist(name)
+		if spotifyErr != nil {
+			return
+		}
+		var err error
+		if topTracks, err = h.spotify.GetArtistTopTracks(spotifyArtist.ID); err != nil {
+			log.Printf("Error getting top tracks for %s: %v", spotifyArtist.Name, err)
+		}
+	}()
+	wg.Wait()
+
+	if geniusArtist == nil && spotifyArtist == nil {
+		if errors.Is(geniusErr, genius.ErrArtistNotFound) {
+			apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Artist not found")
+			return
+		}
+		log.Printf("Error getting artist %q: genius: %v, spotify: %v", name, geniusErr, spotifyErr)
+		apierror.Write(w, http.StatusBadGateway, apierror.UpstreamUnavailable, "Artist details are unavailable right now")
+		return
+	}
+
+	if h.restrictions != nil && h.restrictions.IsRestricted(userIDFromRequest(r)) {
+		topTracks = h.restrictions.FilterTracks(topTracks)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(artistInfo(geniusArtist, spotifyArtist, topTracks))
+}
+
+// GetArtistStyle handles GET /api/artists/{name}/style, summarizing the
+// artist's lyrical themes, how they evolved and their signature phrases from
+// the lyrics of their top songs. The summary is masked in restricted and
+// clean mode (?clean=true).
+func (h *ArtistsHandler) GetArtistStyle(w http.ResponseWriter, r *http.Request) {
+	if h.style == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Artist styles are not available")
+		return
+	}
+	name := strings.TrimSpace(mux.Vars(r)["name"])
+	if name == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Artist name is required")
+		return
+	}
+
+	artistStyle, err := h.style.Get(name)
+	if errors.Is(err, spotify.ErrArtistNotFound) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Artist not found")
+		return
+	}
+	if errors.Is(err, style.ErrNoLyrics) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "No lyrics found for the artist's top songs")
+		return
+	}
+	if errors.Is(err, breaker.ErrOpen) {
+		apierror.Write(w, http.StatusServiceUnavailable, apierror.UpstreamUnavailable, "The service is temporarily unavailable")
+		return
+	}
+	if err != nil {
+		log.Printf("Error analyzing the style of %q: %v", name, err)
+		apierror.Write(w, http.StatusBadGateway, apierror.UpstreamUnavailable, "The artist's style is unavailable right now")
+		return
+	}
+
+	if h.masksFor(r) {
+		artistStyle.Evolution = h.cleanMode.Mask(artistStyle.Evolution)
+		artistStyle.Summary = h.cleanMode.Mask(artistStyle.Summary)
+		phrases := make([]string, len(artistStyle.SignaturePhrases))
+		for i, phrase := range artistStyle.SignaturePhrases {
+			phrases[i] = h.cleanMode.Mask(phrase)
+		}
+		artistStyle.SignaturePhrases = phrases
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(artistStyle)
+}
+
+// masksFor reports whether the request's user gets profanity masked, being
+// in restricted or clean mode or asking for it with ?clean=true
+func (h *ArtistsHandler) masksFor(r *http.Request) bool {
+	if h.cleanMode == nil {
+		return false
+	}
+	userID := userIDFromRequest(r)
+	if h.restrictions != nil && h.restrictions.IsRestricted(userID) {
+		return true
+	}
+	return cleanRequested(r) || h.cleanMode.IsClean(userID)
+}
+
+// artistInfo combines what Genius and Spotify know about an artist; either
+// may be nil. Spotify's spelling of the name wins.
+func artistInfo(geniusArtist *models.GeniusArtist, spotifyArtist *models.SpotifyArtist, topTracks []models.UnifiedTrack) models.ArtistInfo {
+	var info models.ArtistInfo
+	if geniusArtist != nil {
+		info.Name = geniusArtist.Name
+		info.Bio = geniusArtist.Bio
+		info.GeniusURL = geniusArtist.URL
+		if geniusArtist.ImageURL != "" {
+			info.Images = []models.SpotifyImage{{URL: geniusArtist.ImageURL}}
+		}
+	}
+	if spotifyArtist != nil {
+		info.Name = spotifyArtist.Name
+		info.SpotifyID = spotifyArtist.ID
+		info.Genres = spotifyArtist.Genres
+		info.Popularity = spotifyArtist.Popularity
+		if spotifyArtist.Followers != nil {
+			info.Followers = spotifyArtist.Followers.Total
+		}
+		if len(spotifyArtist.Images) > 0 {
+			info.Images = spotifyArtist.Images
+		}
+		info.TopTracks = topTracks
+	}
+	return info
+}
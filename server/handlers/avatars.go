@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/services/avatars"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// avatarPathPrefix is where avatars are served; profiles link to them there
+const avatarPathPrefix = "/api/avatars/"
+
+// multipartOverhead allows for the multipart framing around an uploaded avatar
+const multipartOverhead = 64 << 10
+
+// UploadAvatar handles PUT /api/users/me/avatar, a multipart form with the
+// image as its "avatar" file. The image replaces the caller's avatar, and
+// the profile is returned with its new avatar_url.
+func (h *UsersHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	if h.avatars == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Avatars are not enabled")
+		return
+	}
+
+	maxBytes := h.avatars.MaxBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+multipartOverhead)
+	file, _, err := r.FormFile("avatar")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeAvatarTooLarge(w, maxBytes)
+		return
+	}
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Expected a multipart form with an avatar file")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Failed to read the avatar")
+		return
+	}
+
+	userID := userIDFromRequest(r)
+	previous, err := h.users.Get(userID)
+	if err != nil {
+		log.Printf("Error loading user profile: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to load the profile")
+		return
+	}
+
+	key, err := h.avatars.Save(userID, data)
+	switch {
+	case errors.Is(err, avatars.ErrTooLarge):
+		writeAvatarTooLarge(w, maxBytes)
+		return
+	case errors.Is(err, avatars.ErrUnsupportedType):
+		apierror.Write(w, http.StatusUnsupportedMediaType, apierror.InvalidRequest, err.Error())
+		return
+	case err != nil:
+		log.Printf("Error storing avatar: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to store the avatar")
+		return
+	}
+
+	user, err := h.users.SetAvatar(userID, avatarPathPrefix+key)
+	if err != nil {
+		log.Printf("Error saving avatar on profile: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to update the profile")
+		return
+	}
+	h.deleteAvatar(previous.AvatarURL, user.AvatarURL)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// DeleteAvatar handles DELETE /api/users/me/avatar, removing the caller's
+// avatar
+func (h *UsersHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	if h.avatars == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Avatars are not enabled")
+		return
+	}
+
+	userID := userIDFromRequest(r)
+	previous, err := h.users.Get(userID)
+	if err != nil {
+		log.Printf("Error loading user profile: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to load the profile")
+		return
+	}
+	if previous.AvatarURL == "" {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "No avatar to delete")
+		return
+	}
+	if _, err := h.users.SetAvatar(userID, ""); err != nil {
+		log.Printf("Error clearing avatar on profile: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to update the profile")
+		return
+	}
+	h.deleteAvatar(previous.AvatarURL, "")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAvatar handles GET /api/avatars/{key}. An avatar's key changes with
+// its image, so responses may be cached for good.
+func (h *UsersHandler) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	if h.avatars == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Avatars are not enabled")
+		return
+	}
+
+	key := mux.Vars(r)["key"]
+	etag := `"` + key + `"`
+	if r.Header.Get("If-None-Match") == etag && avatars.ValidKey(key) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, contentType, err := h.avatars.Open(key)
+	if errors.Is(err, avatars.ErrNotFound) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Avatar not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error reading avatar %s: %v", key, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to read the avatar")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write(data)
+}
+
+// deleteAvatar removes a replaced avatar's image, unless it's still current.
+// Failures only leave an orphaned image, so they're logged.
+func (h *UsersHandler) deleteAvatar(avatarURL, current string) {
+	if avatarURL == "" || avatarURL == current {
+		return
+	}
+	if err := h.avatars.Delete(strings.TrimPrefix(avatarURL, avatarPathPrefix)); err != nil {
+		log.Printf("Error deleting replaced avatar: %v", err)
+	}
+}
+
+// writeAvatarTooLarge writes 413 for an avatar over maxBytes
+func writeAvatarTooLarge(w http.ResponseWriter, maxBytes int64) {
+	apierror.Write(w, http.StatusRequestEntityTooLarge, apierror.InvalidRequest, fmt.Sprintf("Avatar exceeds %d bytes", maxBytes))
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// maxBlockedUserLength matches the user_email column of global_messages
+const maxBlockedUserLength = 255
+
+// GetBlocks handles GET /api/blocks, listing the authors the caller blocked
+// or muted, most recent first
+func (h *ChatHandler) GetBlocks(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.query(selectBlocks, userIDFromRequest(r))
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	blocks := []models.UserBlock{}
+	for rows.Next() {
+		var block models.UserBlock
+		if err := rows.Scan(&block.UserEmail, &block.Kind, &block.CreatedAt); err != nil {
+			apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+			return
+		}
+		blocks = append(blocks, block)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blocks)
+}
+
+// Block handles POST /api/blocks, blocking or muting an author for the
+// caller with {"user_email": ..., "kind": "block" | "mute"}. Doing it again
+// replaces the earlier kind.
+func (h *ChatHandler) Block(w http.ResponseWriter, r *http.Request) {
+	var req models.BlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+	switch req.Kind {
+	case "":
+		req.Kind = models.BlockKindBlock
+	case models.BlockKindBlock, models.BlockKindMute:
+	default:
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "kind must be block or mute")
+		return
+	}
+	userID := userIDFromRequest(r)
+	blocked, ok := blockedUser(w, userID, req.UserEmail)
+	if !ok {
+		return
+	}
+
+	var block models.UserBlock
+	err := h.queryRow(upsertBlock, userID, blocked, req.Kind).Scan(&block.UserEmail, &block.Kind, &block.CreatedAt)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(block)
+}
+
+// Unblock handles DELETE /api/blocks/{user}, unblocking or unmuting an author
+// for the caller
+func (h *ChatHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromRequest(r)
+	blocked, ok := blockedUser(w, userID, mux.Vars(r)["user"])
+	if !ok {
+		return
+	}
+
+	result, err := h.exec(deleteBlock, userID, blocked)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "User is not blocked or muted")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// blockedUser normalizes the author a user blocks, writing 400 and returning
+// false if it's empty, too long or the user themselves
+func blockedUser(w http.ResponseWriter, userID, author string) (string, bool) {
+	author = strings.ToLower(strings.TrimSpace(author))
+	if author == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "user_email is required")
+		return "", false
+	}
+	if len(author) > maxBlockedUserLength {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "user_email is too long")
+		return "", false
+	}
+	if author == strings.ToLower(userID) {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "You can't block yourself")
+		return "", false
+	}
+	return author, true
+}
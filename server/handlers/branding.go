@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"backend/server/models"
+	"encoding/json"
+	"net/http"
+)
+
+// BrandingHandler serves the deployment's branding to frontends
+type BrandingHandler struct {
+	branding models.Branding
+}
+
+// NewBrandingHandler creates a new branding handler
+func NewBrandingHandler(branding models.Branding) *BrandingHandler {
+	return &BrandingHandler{branding: branding}
+}
+
+// GetBranding handles GET /api/branding. Branding only changes on restart,
+// so clients may cache it briefly.
+func (h *BrandingHandler) GetBranding(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.branding)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/canary"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// CanaryHandler compares candidate AI configurations with the live one
+type CanaryHandler struct {
+	canaryService canary.Service
+}
+
+// NewCanaryHandler creates a new canary handler
+func NewCanaryHandler(canaryService canary.Service) *CanaryHandler {
+	return &CanaryHandler{canaryService: canaryService}
+}
+
+// RunCanary handles POST /api/admin/canary, answering the query battery with
+// the live configuration and the candidate in the request body
+func (h *CanaryHandler) RunCanary(w http.ResponseWriter, r *http.Request) {
+	var req models.CanaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	report, err := h.canaryService.Run(req.Candidate)
+	if errors.Is(err, canary.ErrInvalidCandidate) {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error running canary: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to run canary")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
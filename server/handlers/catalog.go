@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/services/validation"
+	"encoding/json"
+	"net/http"
+)
+
+// CatalogHandler handles curated catalog maintenance requests
+type CatalogHandler struct {
+	validationService validation.Service
+}
+
+// NewCatalogHandler creates a new catalog handler
+func NewCatalogHandler(validationService validation.Service) *CatalogHandler {
+	return &CatalogHandler{validationService: validationService}
+}
+
+// GetValidationReport handles GET /api/catalog/validation
+func (h *CatalogHandler) GetValidationReport(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.validationService.LastReport()
+	if !ok {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "No validation run has completed yet")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// RunValidation handles POST /api/catalog/validation, running a pass immediately
+func (h *CatalogHandler) RunValidation(w http.ResponseWriter, r *http.Request) {
+	report := h.validationService.Run()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
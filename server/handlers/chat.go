@@ -1,66 +1,351 @@
 package handlers
 
 import (
+	"backend/server/apierror"
 	"backend/server/models"
+	"backend/server/pagination"
+	"backend/services/events"
+	"backend/services/moderation"
+	"backend/services/restricted"
+	"backend/services/users"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
+const (
+	// defaultMessagesLimit is the number of messages on a page when no limit is given
+	defaultMessagesLimit = 50
+	// maxMessagesLimit caps the limit query parameter
+	maxMessagesLimit = 200
+)
+
 type ChatHandler struct {
-	db *sql.DB
+	db           *sql.DB
+	eventBus     events.Bus              // Optional; receives message_posted events
+	restrictions restricted.Service      // Optional; enforces restricted (parental/teen) mode
+	moderation   moderation.Service      // Optional; screens posted messages for hate and harassment
+	users        users.Service           // Optional; authors' profiles name their messages
+	statements   map[chatQuery]*sql.Stmt // Set by Prepare
 }
 
 func NewChatHandler(db *sql.DB) *ChatHandler {
 	return &ChatHandler{db: db}
 }
 
+// SetEventBus sets the bus that posted messages are published to
+func (h *ChatHandler) SetEventBus(eventBus events.Bus) {
+	h.eventBus = eventBus
+}
+
+// SetUsers sets the service of user profiles. Messages are then posted
+// under their author's profile email and name, when set.
+func (h *ChatHandler) SetUsers(users users.Service) {
+	h.users = users
+}
+
+// SetRestrictions sets the service deciding which users are in restricted mode
+func (h *ChatHandler) SetRestrictions(restrictions restricted.Service) {
+	h.restrictions = restrictions
+}
+
+// isRestricted reports whether any of the given user identities is in restricted mode
+func (h *ChatHandler) isRestricted(userIDs ...string) bool {
+	if h.restrictions == nil {
+		return false
+	}
+	for _, userID := range userIDs {
+		if userID != "" && h.restrictions.IsRestricted(userID) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMessages handles GET /api/messages?limit=&cursor=, returning a page of
+// the main stream, newest first: messages that aren't replies, each with its
+// reply count, less those by authors the caller blocked or muted. Pages are
+// keyed on (created_at, id), so they're stable as messages are posted and
+// continue with the cursor in X-Next-Cursor without OFFSET scans.
 func (h *ChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.db.Query(`
-        SELECT id, user_email, username, message_text, created_at 
-        FROM global_messages 
-        ORDER BY created_at ASC
-    `)
+	page, err := pagination.Parse(r.URL.Query(), defaultMessagesLimit, maxMessagesLimit)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, err.Error())
+		return
+	}
+	var beforeCreatedAt time.Time
+	var beforeID int64
+	ok, err := page.Decode(&beforeCreatedAt, &beforeID)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid cursor")
+		return
+	}
+
+	// One more message than the page tells whether there is a next page
+	query, args := selectStream, []interface{}{page.Limit + 1}
+	if ok {
+		query, args = selectStreamBefore, []interface{}{beforeCreatedAt, beforeID, page.Limit + 1}
+	}
+	messages, err := h.queryMessages(userIDFromRequest(r), query, args...)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	if len(messages) > page.Limit {
+		messages = messages[:page.Limit]
+		last := messages[len(messages)-1]
+		pagination.SetNext(w, r, pagination.Encode(last.CreatedAt, last.ID))
+	}
+	if messages == nil {
+		messages = []models.Message{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(messages)
+}
+
+// GetThread handles GET /api/messages/{id}/thread, returning a message and
+// its replies, oldest first. Replies to a reply belong to the same thread,
+// so the thread of a reply is its parent's.
+func (h *ChatHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	id, ok := messageID(w, r)
+	if !ok {
+		return
+	}
+
+	messages, err := h.queryMessages(userIDFromRequest(r), selectThread, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
 		return
 	}
+
+	var thread models.MessageThread
+	thread.Replies = []models.Message{}
+	for _, msg := range messages {
+		if msg.ParentMessageID == nil {
+			thread.Message = msg
+		} else {
+			thread.Replies = append(thread.Replies, msg)
+		}
+	}
+	if thread.Message.ID == 0 {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Message not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(thread)
+}
+
+// queryMessages runs query, one of the messages queries, with args followed
+// by userID and returns the messages with their reactions. Text is masked if
+// userID is restricted.
+func (h *ChatHandler) queryMessages(userID string, query chatQuery, args ...interface{}) ([]models.Message, error) {
+	rows, err := h.query(query, append(args, userID)...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var messages []models.Message
+	var ids []int64
 	for rows.Next() {
 		var msg models.Message
-		err := rows.Scan(&msg.ID, &msg.UserEmail, &msg.Username, &msg.Text, &msg.CreatedAt)
+		var payload []byte
+		var parentID sql.NullInt64
+		err := rows.Scan(&msg.ID, &msg.UserID, &msg.UserEmail, &msg.Username, &msg.Text, &msg.MessageType, &payload, &parentID, &msg.ReplyCount, &msg.CreatedAt)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+			return nil, err
+		}
+		if len(payload) > 0 {
+			msg.Payload = payload
+		}
+		if parentID.Valid {
+			msg.ParentMessageID = &parentID.Int64
 		}
 		messages = append(messages, msg)
+		ids = append(ids, msg.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(messages)
+	reactions, err := h.reactions(ids...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range messages {
+		messages[i].Reactions = reactions[messages[i].ID]
+	}
+
+	if h.isRestricted(userID) {
+		for i := range messages {
+			messages[i].Text = restricted.MaskProfanity(messages[i].Text)
+		}
+	}
+	return messages, nil
+}
+
+// message returns the message with id as a user who blocked no one sees it,
+// or false if it doesn't exist or is hidden
+func (h *ChatHandler) message(id int64) (models.Message, bool, error) {
+	messages, err := h.queryMessages("", selectMessage, id)
+	if err != nil || len(messages) == 0 {
+		return models.Message{}, false, err
+	}
+	return messages[0], true, nil
 }
 
 func (h *ChatHandler) PostMessage(w http.ResponseWriter, r *http.Request) {
 	var msg models.Message
 	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, err.Error())
 		return
 	}
 
-	err := h.db.QueryRow(`
-        INSERT INTO global_messages (user_email, username, message_text, created_at)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id, created_at
-    `, msg.UserEmail, msg.Username, msg.Text, time.Now()).Scan(&msg.ID, &msg.CreatedAt)
-
+	msg, status, err := h.postMessage(msg, userIDFromRequest(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, status, apierror.CodeForStatus(status), err.Error())
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(msg)
 }
+
+// PostSystemMessage posts a message from the server itself, e.g. a digest,
+// under username
+func (h *ChatHandler) PostSystemMessage(username, text string) error {
+	_, _, err := h.postMessage(models.Message{Username: username, Text: text}, "")
+	return err
+}
+
+// postMessage stores and publishes a message sent by userID, which is empty
+// for messages from the server itself. On failure it returns the HTTP status
+// describing the error.
+func (h *ChatHandler) postMessage(msg models.Message, userID string) (models.Message, int, error) {
+	track, err := normalizeMessage(&msg)
+	if err != nil {
+		return msg, http.StatusBadRequest, err
+	}
+
+	// Registered authors post under their profile
+	msg.UserID = userID
+	if h.users != nil && userID != "" {
+		user, err := h.users.Get(userID)
+		if err != nil {
+			return msg, http.StatusInternalServerError, err
+		}
+		if user.Email != "" {
+			msg.UserEmail = user.Email
+		}
+		if name := user.Name(); name != "" {
+			msg.Username = name
+		}
+	}
+
+	// Moderation sees the text as written, before restricted mode masks it
+	verdict := h.moderate(msg, userID)
+	if verdict.Action == models.ModerationReject {
+		return msg, http.StatusForbidden, fmt.Errorf("Message rejected by moderation (%s)", strings.Join(verdict.Categories, ", "))
+	}
+
+	// Restricted users can't reach other users directly, and their messages are kept clean
+	if h.isRestricted(userID, msg.UserEmail) {
+		if restricted.ContainsMention(msg.Text) {
+			return msg, http.StatusForbidden, errors.New("Mentions are disabled in restricted mode")
+		}
+		if track != nil && track.Explicit {
+			return msg, http.StatusForbidden, errors.New("Explicit tracks can't be shared in restricted mode")
+		}
+		msg.Text = restricted.MaskProfanity(msg.Text)
+	}
+
+	// Banned users can't post until their ban expires
+	if userID != "" {
+		until, banned, err := h.bannedUntil(userID, msg.UserEmail)
+		if err != nil {
+			return msg, http.StatusInternalServerError, err
+		}
+		if banned {
+			return msg, http.StatusForbidden, fmt.Errorf("You are banned from chat until %s", until.UTC().Format(time.RFC3339))
+		}
+	}
+
+	// Threads are one level deep: a reply to a reply joins its parent's thread
+	if msg.ParentMessageID != nil {
+		var grandparentID sql.NullInt64
+		err := h.queryRow(selectMessageParent, *msg.ParentMessageID).Scan(&grandparentID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return msg, http.StatusNotFound, errors.New("Parent message not found")
+		}
+		if err != nil {
+			return msg, http.StatusInternalServerError, err
+		}
+		if grandparentID.Valid {
+			msg.ParentMessageID = &grandparentID.Int64
+		}
+	}
+
+	var payload interface{} // NULL for text messages
+	if len(msg.Payload) > 0 {
+		payload = string(msg.Payload)
+	}
+	msg.ReplyCount = 0
+	msg.Reactions = nil
+	err = h.queryRow(insertMessage, msg.UserID, msg.UserEmail, msg.Username, msg.Text, msg.MessageType, payload, msg.ParentMessageID, time.Now()).Scan(&msg.ID, &msg.CreatedAt)
+
+	if err != nil {
+		return msg, http.StatusInternalServerError, err
+	}
+
+	if verdict.Action == models.ModerationFlag {
+		verdict.MessageID = &msg.ID
+		if _, err := h.moderation.Record(verdict); err != nil {
+			log.Printf("Error recording the moderation verdict on message %d: %v", msg.ID, err)
+		}
+	}
+	if h.eventBus != nil {
+		h.eventBus.Publish(events.MessagePosted, msg)
+	}
+	return msg, http.StatusOK, nil
+}
+
+// normalizeMessage checks a message's type and payload. A track card's
+// payload is stored as the track's known fields only, and the track is
+// returned; text messages have no payload.
+func normalizeMessage(msg *models.Message) (*models.UnifiedTrack, error) {
+	switch msg.MessageType {
+	case "", models.MessageTypeText:
+		msg.MessageType = models.MessageTypeText
+		if len(msg.Payload) > 0 && string(msg.Payload) != "null" {
+			return nil, errors.New("Text messages can't have a payload")
+		}
+		msg.Payload = nil
+		return nil, nil
+	case models.MessageTypeTrack:
+		var track models.UnifiedTrack
+		if err := json.Unmarshal(msg.Payload, &track); err != nil {
+			return nil, errors.New("A track message needs the track as its payload")
+		}
+		if track.ID == "" || track.Name == "" || track.Artist == "" {
+			return nil, errors.New("A shared track needs an id, name and artist")
+		}
+		if track.Source != "spotify" && track.Source != "youtube" {
+			return nil, errors.New("A shared track's source must be spotify or youtube")
+		}
+		payload, err := json.Marshal(track)
+		if err != nil {
+			return nil, err
+		}
+		msg.Payload = payload
+		return &track, nil
+	default:
+		return nil, fmt.Errorf("Unknown message type %q", msg.MessageType)
+	}
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// chatQuery is a SQL statement of the chat handler. Every statement is listed
+// in chatQueries, so Prepare checks them all against the schema at startup.
+type chatQuery string
+
+// Messages, newest or oldest first. The caller's user ID follows the other
+// arguments; see queryMessages.
+var (
+	selectStream = messagesQuery(`m.parent_message_id IS NULL`, 2,
+		`ORDER BY m.created_at DESC, m.id DESC LIMIT $1`)
+	selectStreamBefore = messagesQuery(`m.parent_message_id IS NULL AND (m.created_at, m.id) < ($1, $2)`, 4,
+		`ORDER BY m.created_at DESC, m.id DESC LIMIT $3`)
+	selectThread = messagesQuery(`m.id = $1 OR m.parent_message_id = $1
+        OR m.id = (SELECT parent_message_id FROM global_messages WHERE id = $1)
+        OR m.parent_message_id = (SELECT parent_message_id FROM global_messages WHERE id = $1)`, 2,
+		`ORDER BY m.created_at ASC, m.id ASC`)
+	selectMessage = messagesQuery(`m.id = $1`, 2, ``)
+)
+
+// Posting and reacting
+const (
+	selectMessageParent chatQuery = `SELECT parent_message_id FROM global_messages WHERE id = $1`
+	selectMessageExists chatQuery = `SELECT EXISTS (SELECT 1 FROM global_messages WHERE id = $1)`
+	insertMessage       chatQuery = `
+        INSERT INTO global_messages (user_id, user_email, username, message_text, message_type, payload, parent_message_id, created_at)
+        VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at
+    `
+	deleteMessage  chatQuery = `DELETE FROM global_messages WHERE id = $1`
+	insertReaction chatQuery = `
+        INSERT INTO message_reactions (message_id, user_id, emoji, created_at)
+        VALUES ($1, $2, $3, NOW())
+        ON CONFLICT (message_id, user_id, emoji) DO NOTHING
+    `
+	deleteReaction chatQuery = `
+        DELETE FROM message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3
+    `
+	selectReactions chatQuery = `
+        SELECT message_id, emoji, COUNT(*)
+        FROM message_reactions
+        WHERE message_id = ANY($1)
+        GROUP BY message_id, emoji
+        ORDER BY message_id, MIN(created_at)
+    `
+)
+
+// Blocks and read markers
+const (
+	selectBlocks chatQuery = `
+        SELECT blocked_user, kind, created_at
+        FROM user_blocks
+        WHERE user_id = $1
+        ORDER BY created_at DESC
+    `
+	upsertBlock chatQuery = `
+        INSERT INTO user_blocks (user_id, blocked_user, kind, created_at)
+        VALUES ($1, $2, $3, NOW())
+        ON CONFLICT (user_id, blocked_user) DO UPDATE SET
+            kind = EXCLUDED.kind,
+            created_at = EXCLUDED.created_at
+        RETURNING blocked_user, kind, created_at
+    `
+	deleteBlock      chatQuery = `DELETE FROM user_blocks WHERE user_id = $1 AND blocked_user = $2`
+	selectReadMarker chatQuery = `
+        SELECT last_read_message_id, updated_at
+        FROM read_markers
+        WHERE user_id = $1 AND room = $2
+    `
+	upsertReadMarker chatQuery = `
+        INSERT INTO read_markers (user_id, room, last_read_message_id, updated_at)
+        VALUES ($1, $2, $3, NOW())
+        ON CONFLICT (user_id, room) DO UPDATE SET
+            last_read_message_id = EXCLUDED.last_read_message_id,
+            updated_at = EXCLUDED.updated_at
+    `
+)
+
+// selectRoomCounts counts the messages after a read position that the user
+// whose ID is $1 can see, less their own
+var selectRoomCounts = chatQuery(`
+        SELECT
+            COALESCE(MAX(m.id), 0),
+            COUNT(*) FILTER (WHERE m.id > $2 AND LOWER(m.user_email) <> LOWER($1))
+        FROM global_messages m
+        WHERE ` + visibleMessages(1) + `
+    `)
+
+// Reports, bans and the audit log
+const (
+	selectMessageVisible chatQuery = `SELECT hidden_at IS NULL FROM global_messages WHERE id = $1`
+	insertReport         chatQuery = `
+        INSERT INTO message_reports (message_id, reporter_id, reason, details, status, created_at)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+        ON CONFLICT (message_id, reporter_id) DO NOTHING
+        RETURNING id, status, created_at
+    `
+	selectReports chatQuery = `
+        SELECT r.id, r.message_id, r.reporter_id, r.reason, r.details, r.status, r.created_at,
+            m.user_email, m.username, m.message_text, m.message_type, m.created_at
+        FROM message_reports r
+        JOIN global_messages m ON m.id = r.message_id
+        WHERE $1::text = '' OR r.status = $1::text
+        ORDER BY r.created_at ASC, r.id ASC
+        LIMIT $2
+    `
+	updateReportStatus   chatQuery = `UPDATE message_reports SET status = $2 WHERE id = $1 AND status = $3`
+	updateMessageReports chatQuery = `UPDATE message_reports SET status = $2 WHERE message_id = $1 AND status = $3`
+	hideMessage          chatQuery = `UPDATE global_messages SET hidden_at = NOW() WHERE id = $1 AND hidden_at IS NULL`
+	unhideMessage        chatQuery = `UPDATE global_messages SET hidden_at = NULL WHERE id = $1 AND hidden_at IS NOT NULL`
+	selectBans           chatQuery = `
+        SELECT user_id, reason, banned_by, expires_at, created_at
+        FROM user_bans
+        WHERE expires_at > NOW()
+        ORDER BY expires_at ASC
+    `
+	selectBannedUntil chatQuery = `SELECT MAX(expires_at) FROM user_bans WHERE user_id = ANY($1) AND expires_at > NOW()`
+	upsertBan         chatQuery = `
+        INSERT INTO user_bans (user_id, reason, banned_by, expires_at, created_at)
+        VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second', NOW())
+        ON CONFLICT (user_id) DO UPDATE SET
+            reason = EXCLUDED.reason,
+            banned_by = EXCLUDED.banned_by,
+            expires_at = EXCLUDED.expires_at,
+            created_at = EXCLUDED.created_at
+        RETURNING expires_at, created_at
+    `
+	deleteBan   chatQuery = `DELETE FROM user_bans WHERE user_id = $1 AND expires_at > NOW()`
+	selectAudit chatQuery = `
+        SELECT id, actor, action, target_type, target_id, details, created_at
+        FROM moderation_audit
+        ORDER BY created_at DESC, id DESC
+        LIMIT $1
+    `
+	insertAudit chatQuery = `
+        INSERT INTO moderation_audit (actor, action, target_type, target_id, details, created_at)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+    `
+)
+
+// notifyChatEvent announces a chat event to every instance, for
+// WS_CHAT_FANOUT=postgres
+const notifyChatEvent chatQuery = `SELECT pg_notify($1, $2)`
+
+// chatQueries lists every statement Prepare prepares
+var chatQueries = []chatQuery{
+	selectStream, selectStreamBefore, selectThread, selectMessage,
+	selectMessageParent, selectMessageExists, insertMessage, deleteMessage,
+	insertReaction, deleteReaction, selectReactions,
+	selectBlocks, upsertBlock, deleteBlock, selectReadMarker, upsertReadMarker, selectRoomCounts,
+	selectMessageVisible, insertReport, selectReports, updateReportStatus, updateMessageReports,
+	hideMessage, unhideMessage, selectBans, selectBannedUntil, upsertBan, deleteBan,
+	selectAudit, insertAudit, notifyChatEvent,
+}
+
+// messagesQuery builds a query of the messages matching where, a condition on
+// global_messages aliased m, with their reply counts, leaving out hidden
+// messages and those by authors the user whose ID is parameter number
+// userParam blocked or muted. Authors are named by their current profile,
+// where they have one.
+func messagesQuery(where string, userParam int, order string) chatQuery {
+	return chatQuery(`
+        SELECT m.id, COALESCE(m.user_id, ''),
+            COALESCE(NULLIF(u.email, ''), m.user_email),
+            COALESCE(NULLIF(u.display_name, ''), NULLIF(u.username, ''), m.username),
+            m.message_text, m.message_type, m.payload, m.parent_message_id,
+            (SELECT COUNT(*) FROM global_messages reply WHERE reply.parent_message_id = m.id),
+            m.created_at
+        FROM global_messages m
+        LEFT JOIN users u ON u.id = m.user_id
+        WHERE (` + where + `) AND ` + visibleMessages(userParam) + `
+        ` + order)
+}
+
+// visibleMessages returns the condition on global_messages aliased m that
+// leaves out hidden messages and those by authors the user whose ID is
+// parameter number param blocked or muted
+func visibleMessages(param int) string {
+	return `m.hidden_at IS NULL
+            AND NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.user_id = $` + strconv.Itoa(param) + ` AND b.blocked_user = LOWER(m.user_email))`
+}
+
+// Prepare prepares the chat handler's statements, failing if any of them no
+// longer matches the schema. Until it is called, statements are sent as
+// plain queries.
+func (h *ChatHandler) Prepare() error {
+	statements := make(map[chatQuery]*sql.Stmt, len(chatQueries))
+	for _, query := range chatQueries {
+		stmt, err := h.db.Prepare(string(query))
+		if err != nil {
+			for _, prepared := range statements {
+				prepared.Close()
+			}
+			return fmt.Errorf("preparing %q: %w", query.summary(), err)
+		}
+		statements[query] = stmt
+	}
+	h.statements = statements
+	return nil
+}
+
+// exec runs a statement that returns no rows
+func (h *ChatHandler) exec(query chatQuery, args ...interface{}) (sql.Result, error) {
+	if stmt, ok := h.statements[query]; ok {
+		return stmt.Exec(args...)
+	}
+	return h.db.Exec(string(query), args...)
+}
+
+// query runs a statement returning rows
+func (h *ChatHandler) query(query chatQuery, args ...interface{}) (*sql.Rows, error) {
+	if stmt, ok := h.statements[query]; ok {
+		return stmt.Query(args...)
+	}
+	return h.db.Query(string(query), args...)
+}
+
+// queryRow runs a statement returning at most one row
+func (h *ChatHandler) queryRow(query chatQuery, args ...interface{}) *sql.Row {
+	if stmt, ok := h.statements[query]; ok {
+		return stmt.QueryRow(args...)
+	}
+	return h.db.QueryRow(string(query), args...)
+}
+
+// summary shortens a statement to its first words, for errors
+func (q chatQuery) summary() string {
+	words := strings.Fields(string(q))
+	if len(words) > 8 {
+		words = append(words[:8], "...")
+	}
+	return strings.Join(words, " ")
+}
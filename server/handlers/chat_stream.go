@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// StreamingAIService is implemented by AI services that can stream partial responses
+type StreamingAIService interface {
+	GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error
+}
+
+// HandleChatStream handles POST /api/chat/stream.
+// General music questions are streamed as plain text chunks when the active AI
+// service supports streaming; other query types are answered in a single chunk.
+// Generation stops as soon as the client disconnects.
+func (h *LyricsHandler) HandleChatStream(w http.ResponseWriter, r *http.Request) {
+	var chatReq models.ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&chatReq); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	if chatReq.Query == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Query cannot be empty")
+		return
+	}
+	lang, err := resolveLanguage(chatReq.Lang, chatReq.Query)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Language", lang)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	userID := userIDFromRequest(r)
+	streamer, canStream := h.aiForRequest(userID, chatReq.Clean).(StreamingAIService)
+	if !canStream || h.isCrisis(chatReq.Query) || !h.isGeneralMusicQuery(chatReq.Query, lang) {
+		response := h.processChatRequest(chatReq.Query, userID, lang, chatReq.Region, chatReq.Clean)
+		if response.Error != "" {
+			fmt.Fprint(w, response.Error)
+			return
+		}
+		fmt.Fprint(w, response.Answer)
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	started := false
+	err = streamer.GenerateStream(r.Context(), h.generalMusicPrompt(chatReq.Query, lang), func(chunk string) error {
+		started = true
+		if _, err := fmt.Fprint(w, chunk); err != nil {
+			return err
+		}
+		controller.Flush() // Not every writer can flush; the answer then arrives at the end
+		return nil
+	})
+	if err != nil && r.Context().Err() == nil {
+		log.Printf("Error streaming chat response: %v", err)
+		if !started {
+			apierror.Write(w, http.StatusBadGateway, apierror.UpstreamUnavailable, fmt.Sprintf("Error generating response: %v", err))
+			return
+		}
+		// The status is already sent; all that's left is to say so in the text
+		fmt.Fprintf(w, "\n\nError generating response: %v", err)
+	}
+}
+
+// isGeneralMusicQuery checks if a query would be answered by handleGeneralQuery
+func (h *LyricsHandler) isGeneralMusicQuery(query, lang string) bool {
+	return !h.isArtistRadioQuery(query) &&
+		!h.isSongRequestQuery(query) &&
+		!h.containsEmotionalContent(query) &&
+		!h.isLyricsRelatedQuery(query, lang) &&
+		h.isMusicRelatedQuery(query, lang)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/cleanmode"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// SetCleanMode replaces the default clean mode service, e.g. with one that
+// starts with users in clean mode or masks extra words
+func (h *LyricsHandler) SetCleanMode(cleanMode cleanmode.Service) {
+	h.cleanMode = cleanMode
+}
+
+// isClean reports whether a request by userID gets clean mode, either because
+// it asked for it or because the user turned it on
+func (h *LyricsHandler) isClean(userID string, requested bool) bool {
+	return requested || h.cleanMode.IsClean(userID)
+}
+
+// cleanRequested reports whether a GET request asks for clean mode with ?clean=true
+func cleanRequested(r *http.Request) bool {
+	return r.URL.Query().Get("clean") == "true"
+}
+
+// CleanModeHandler manages per-user clean mode
+type CleanModeHandler struct {
+	cleanMode cleanmode.Service
+}
+
+// NewCleanModeHandler creates a new clean mode handler
+func NewCleanModeHandler(cleanMode cleanmode.Service) *CleanModeHandler {
+	return &CleanModeHandler{cleanMode: cleanMode}
+}
+
+// GetCleanMode handles GET /api/users/{userID}/clean-mode
+func (h *CleanModeHandler) GetCleanMode(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.cleanMode.Status(userID))
+}
+
+// SetCleanMode handles PUT /api/users/{userID}/clean-mode. Users can only
+// change their own setting.
+func (h *CleanModeHandler) SetCleanMode(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+	if userIDFromRequest(r) != userID {
+		apierror.Write(w, http.StatusForbidden, apierror.Forbidden, "Clean mode can only be changed by the user")
+		return
+	}
+
+	var req models.CleanModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	h.cleanMode.SetClean(userID, req.Clean)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.cleanMode.Status(userID))
+}
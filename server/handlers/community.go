@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/services/topics"
+	"encoding/json"
+	"net/http"
+)
+
+// CommunityHandler handles community insight requests
+type CommunityHandler struct {
+	topicsService topics.Service
+}
+
+// NewCommunityHandler creates a new community handler
+func NewCommunityHandler(topicsService topics.Service) *CommunityHandler {
+	return &CommunityHandler{topicsService: topicsService}
+}
+
+// GetTopics handles GET /api/community/topics, returning what recent chat is about
+func (h *CommunityHandler) GetTopics(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.topicsService.LastReport()
+	if !ok {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "No topic detection run has completed yet")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// RunTopics handles POST /api/admin/community/topics, clustering recent chat immediately
+func (h *CommunityHandler) RunTopics(w http.ResponseWriter, r *http.Request) {
+	report := h.topicsService.Run()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
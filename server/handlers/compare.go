@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/breaker"
+	"backend/services/comparison"
+	"backend/services/genius"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// SetComparison sets the service comparing songs. Without it
+// /api/analyze/compare answers 404.
+func (h *LyricsHandler) SetComparison(comparison comparison.Service) {
+	h.comparison = comparison
+}
+
+// CompareSongs handles POST /api/analyze/compare.
+// It compares the themes, mood and era of two songs, returned with each
+// song's mood analysis. In restricted and clean mode the lyrics are masked
+// before they reach the AI, and so is its comparison.
+func (h *LyricsHandler) CompareSongs(w http.ResponseWriter, r *http.Request) {
+	if h.comparison == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Song comparisons are not available")
+		return
+	}
+
+	var req models.CompareSongsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+	if req.First.Name == "" || req.First.Artist == "" || req.Second.Name == "" || req.Second.Artist == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Both songs need a name and an artist")
+		return
+	}
+	lang, err := resolveLanguage(req.Lang, "")
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, err.Error())
+		return
+	}
+
+	userID := userIDFromRequest(r)
+	clean := h.isRestricted(userID) || h.isClean(userID, req.Clean)
+	options := comparison.Options{Instruction: languageInstruction(lang)}
+	if clean {
+		options.MaskLyrics = h.cleanMode.Mask
+	}
+
+	result, err := h.comparison.Compare(req.First, req.Second, h.aiForRequest(userID, req.Clean), options)
+	var songErr *comparison.SongError
+	if errors.As(err, &songErr) && errors.Is(err, genius.ErrSongNotFound) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, fmt.Sprintf("No lyrics found for %s by %s", songErr.Track.Name, songErr.Track.Artist))
+		return
+	}
+	if errors.Is(err, breaker.ErrOpen) {
+		apierror.Write(w, http.StatusServiceUnavailable, apierror.UpstreamUnavailable, "The AI service is temporarily unavailable")
+		return
+	}
+	if err != nil {
+		log.Printf("Error comparing %s by %s with %s by %s: %v", req.First.Name, req.First.Artist, req.Second.Name, req.Second.Artist, err)
+		apierror.Write(w, http.StatusBadGateway, apierror.UpstreamUnavailable, "Failed to compare the songs")
+		return
+	}
+
+	if clean {
+		result.Mood = h.cleanMode.Mask(result.Mood)
+		result.Era = h.cleanMode.Mask(result.Era)
+		result.Summary = h.cleanMode.Mask(result.Summary)
+	}
+	result.Language = lang
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"backend/server/models"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// crisisSuggestions is how many calm songs accompany crisis support
+const crisisSuggestions = 5
+
+// isCrisis reports whether a query indicates severe distress or self-harm
+func (h *LyricsHandler) isCrisis(query string) bool {
+	return h.safety != nil && h.safety.Screen(query)
+}
+
+// handleCrisis answers a query indicating severe distress or self-harm with
+// support and the helplines for region, followed by a few calm songs. The
+// mood, detected now unless analysis is given, is only saved to the user's
+// private mood history: it isn't published, logged or returned.
+func (h *LyricsHandler) handleCrisis(query, userID, lang, region string, analysis *models.MoodAnalysis) models.ChatResponse {
+	if analysis == nil {
+		var err error
+		if analysis, err = h.moodService.DetectMood(query); err != nil {
+			analysis = keywordMoodAnalysis(query)
+		}
+	}
+	if err := h.moodService.SaveUserMoodHistory(userID, analysis.PrimaryMood, nil); err != nil {
+		log.Printf("Error saving mood history: %v", err)
+	}
+	log.Printf("Offering crisis support resources for region %q", region)
+
+	resources := h.safety.Resources(region)
+	suggestions := h.getGeneralMoodSuggestions("calm", crisisSuggestions)
+	if h.isRestricted(userID) {
+		suggestions = filterExplicitRecommendations(suggestions)
+	}
+
+	return models.ChatResponse{
+		Answer:    localize(lang, msgCrisisSupport) + formatHelplines(resources),
+		Type:      "crisis_support",
+		Resources: resources,
+		Recommendations: &models.MoodRecommendations{
+			FromLibrary: []models.MoodBasedRecommendation{},
+			Suggested:   suggestions,
+		},
+	}
+}
+
+// formatHelplines lists helplines in the answer text, so clients that only
+// show the answer still show them
+func formatHelplines(helplines []models.Helpline) string {
+	var text strings.Builder
+	for _, helpline := range helplines {
+		contacts := make([]string, 0, 2)
+		for _, contact := range []string{helpline.Phone, helpline.URL} {
+			if contact != "" {
+				contacts = append(contacts, contact)
+			}
+		}
+		fmt.Fprintf(&text, "\n- %s: %s", helpline.Name, strings.Join(contacts, ", "))
+	}
+	if text.Len() > 0 {
+		return "\n" + text.String()
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import "strings"
+
+// Customization brands a deployment's assistant. Responses replace canned
+// answers by key (see config.CustomResponses) in every language, and may use
+// the {assistant} and, for empathetic answers, {mood} placeholders.
+type Customization struct {
+	AssistantName string
+	Responses     map[string]string
+}
+
+// SetCustomization replaces canned answers with the deployment's own
+func (h *LyricsHandler) SetCustomization(customization Customization) {
+	h.customization = customization
+}
+
+// cannedAnswer returns the deployment's answer for key, or the built-in one in lang
+func (h *LyricsHandler) cannedAnswer(lang, key string) string {
+	if response, ok := h.customization.Responses[key]; ok {
+		return h.renderCustom(response, lang, "")
+	}
+	return localize(lang, key)
+}
+
+// customEmpatheticResponse returns the deployment's introduction to
+// recommendations for mood, falling back to its default one if allowed
+func (h *LyricsHandler) customEmpatheticResponse(mood, lang string, fallback bool) (string, bool) {
+	response, ok := h.customization.Responses["empathetic_"+mood]
+	if !ok && fallback {
+		response, ok = h.customization.Responses["empathetic_default"]
+	}
+	if !ok {
+		return "", false
+	}
+	return h.renderCustom(response, lang, mood), true
+}
+
+// renderCustom fills in a custom answer's placeholders
+func (h *LyricsHandler) renderCustom(response, lang, mood string) string {
+	return strings.NewReplacer(
+		"{assistant}", h.customization.AssistantName,
+		"{mood}", localizedMood(lang, mood),
+	).Replace(response)
+}
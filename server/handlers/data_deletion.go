@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/erasure"
+	"backend/services/jobqueue"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// SetDataDeletion enables DELETE /api/users/me/data, erasing users' data
+// with eraser on the job queue
+func (h *UsersHandler) SetDataDeletion(eraser erasure.Service) {
+	h.eraser = eraser
+}
+
+// DeleteMyData handles DELETE /api/users/me/data. A data_deletion job is
+// queued and returned with 202 Accepted; its result, readable at
+// /api/jobs/{id}, is the receipt listing what was deleted. Callers must send
+// X-User-ID.
+func (h *UsersHandler) DeleteMyData(w http.ResponseWriter, r *http.Request) {
+	if h.eraser == nil || h.jobQueue == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Data deletion is not available")
+		return
+	}
+
+	userID, ok := explicitUserID(w, r)
+	if !ok {
+		return
+	}
+	if job, ok := h.enqueue(w, userID, models.JobTypeDataDeletion, models.DataDeletionRequest{UserID: userID}, "data deletion"); ok {
+		writeAccepted(w, job.ID, job)
+	}
+}
+
+// enqueue queues a job for the user, writing the error and returning false
+// if it couldn't be queued
+func (h *UsersHandler) enqueue(w http.ResponseWriter, userID, jobType string, payload interface{}, description string) (models.QueuedJob, bool) {
+	job, err := h.jobQueue.Enqueue(userID, jobType, payload)
+	if errors.Is(err, jobqueue.ErrQueueFull) {
+		w.Header().Set("Retry-After", "60")
+		apierror.Write(w, http.StatusServiceUnavailable, apierror.RateLimited, "Too many background jobs are waiting; try again later")
+		return models.QueuedJob{}, false
+	}
+	if err != nil {
+		log.Printf("Error queueing %s for user %s: %v", description, userID, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to queue the "+description)
+		return models.QueuedJob{}, false
+	}
+	return job, true
+}
+
+// writeAccepted writes body with 202 Accepted, pointing at the queued job
+func writeAccepted(w http.ResponseWriter, jobID int64, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", jobID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(body)
+}
+
+// explicitUserID returns the caller's X-User-ID, writing 400 and returning
+// false if they sent none, so nobody acts on the shared default user's data
+// by mistake
+func explicitUserID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if strings.TrimSpace(r.Header.Get("X-User-ID")) == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "X-User-ID is required")
+		return "", false
+	}
+	return userIDFromRequest(r), true
+}
+
+// DataDeletionJob runs a data_deletion job. Erasure can be repeated, so a
+// failed attempt is simply retried.
+func (h *UsersHandler) DataDeletionJob(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var req models.DataDeletionRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, fmt.Errorf("invalid data_deletion payload: %w", err)
+	}
+	if h.eraser == nil {
+		return nil, errors.New("data deletion is not available")
+	}
+
+	receipt, err := h.eraser.Erase(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Deleted the data of user %s", req.UserID)
+	return receipt, nil
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/export"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// exportPathPrefix is where export bundles are downloaded from
+const exportPathPrefix = "/api/exports/"
+
+// SetDataExport enables GET /api/users/me/export, bundling users' data with
+// exporter on the job queue
+func (h *UsersHandler) SetDataExport(exporter export.Service) {
+	h.exporter = exporter
+}
+
+// ExportMyData handles GET /api/users/me/export. A data_export job is queued
+// and returned with 202 Accepted, along with the download_url its bundle will
+// be served from once it succeeded. The link's token is only in this
+// response: the job gets its hash, so reading the job doesn't reveal it.
+// Callers must send X-User-ID.
+func (h *UsersHandler) ExportMyData(w http.ResponseWriter, r *http.Request) {
+	if h.exporter == nil || h.jobQueue == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Data export is not available")
+		return
+	}
+
+	userID, ok := explicitUserID(w, r)
+	if !ok {
+		return
+	}
+	token, err := export.NewToken()
+	if err != nil {
+		log.Printf("Error queueing data export for user %s: %v", userID, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to queue the data export")
+		return
+	}
+	req := models.DataExportRequest{UserID: userID, BundleID: export.BundleID(token)}
+	if job, ok := h.enqueue(w, userID, models.JobTypeDataExport, req, "data export"); ok {
+		writeAccepted(w, job.ID, models.DataExportQueued{QueuedJob: job, DownloadURL: exportPathPrefix + token})
+	}
+}
+
+// DataExportJob runs a data_export job. A failed attempt leaves no bundle, so
+// it is simply retried.
+func (h *UsersHandler) DataExportJob(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var req models.DataExportRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, fmt.Errorf("invalid data_export payload: %w", err)
+	}
+	if h.exporter == nil {
+		return nil, errors.New("data export is not available")
+	}
+
+	return h.exporter.Export(ctx, req.UserID, req.BundleID)
+}
+
+// DownloadExport handles GET /api/exports/{token}, serving an export bundle
+// as a zip. The unguessable token is the credential, so links work without
+// an API key, e.g. in a browser, until the bundle expires.
+func (h *UsersHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	if h.exporter == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Data export is not available")
+		return
+	}
+
+	file, err := h.exporter.Open(mux.Vars(r)["token"])
+	if errors.Is(err, export.ErrNotFound) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Export not found or expired")
+		return
+	}
+	if err != nil {
+		log.Printf("Error opening export: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to load the export")
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		log.Printf("Error opening export: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to load the export")
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="linkinsync-export.zip"`)
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, "linkinsync-export.zip", info.ModTime(), file)
+}
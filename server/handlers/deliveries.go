@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/streaming"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// defaultDeliveriesLimit is the number of deliveries returned when no limit is given
+	defaultDeliveriesLimit = 50
+	// maxDeliveriesLimit caps the limit query parameter
+	maxDeliveriesLimit = 200
+)
+
+// DeliveriesHandler lets admins inspect and redeliver events published to
+// the external event stream (NATS or Kafka)
+type DeliveriesHandler struct {
+	deliveryLog streaming.DeliveryLog // nil when no event stream is configured
+}
+
+// NewDeliveriesHandler creates a new deliveries handler. deliveryLog may be
+// nil, in which case the endpoints report that no stream is configured.
+func NewDeliveriesHandler(deliveryLog streaming.DeliveryLog) *DeliveriesHandler {
+	return &DeliveriesHandler{deliveryLog: deliveryLog}
+}
+
+// ListDeliveries handles GET /api/admin/deliveries?status=&limit=
+func (h *DeliveriesHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	if !h.configured(w) {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && status != models.DeliveryDelivered && status != models.DeliveryFailed {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid status (expected delivered or failed)")
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid limit")
+			return
+		}
+		if parsed > maxDeliveriesLimit {
+			parsed = maxDeliveriesLimit
+		}
+		limit = parsed
+	}
+
+	response := models.EventDeliveriesResponse{
+		Deliveries: h.deliveryLog.Deliveries(status, limit),
+		Failed:     h.deliveryLog.Failed(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetDelivery handles GET /api/admin/deliveries/{id}
+func (h *DeliveriesHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	if !h.configured(w) {
+		return
+	}
+
+	id, ok := deliveryID(w, r)
+	if !ok {
+		return
+	}
+	delivery, found := h.deliveryLog.Delivery(id)
+	if !found {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Delivery not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// Redeliver handles POST /api/admin/deliveries/{id}/redeliver, publishing the
+// delivery's payload again. The updated delivery is returned either way, with
+// 502 Bad Gateway if the stream rejected it again.
+func (h *DeliveriesHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	if !h.configured(w) {
+		return
+	}
+
+	id, ok := deliveryID(w, r)
+	if !ok {
+		return
+	}
+	delivery, err := h.deliveryLog.Redeliver(id)
+	if errors.Is(err, streaming.ErrDeliveryNotFound) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Delivery not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// configured writes a 404 and returns false when there is no event stream
+func (h *DeliveriesHandler) configured(w http.ResponseWriter) bool {
+	if h.deliveryLog == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "No event stream is configured")
+		return false
+	}
+	return true
+}
+
+// deliveryID parses the {id} route variable, writing a 400 if it is invalid
+func deliveryID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id <= 0 {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid delivery ID")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/breaker"
+	"backend/services/events"
+	"backend/services/spotify"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// djQueueSize is how many tracks a DJ queue holds
+	djQueueSize = 20
+	// djSuggestions is how many tracks are asked of the AI, since some won't be found on Spotify
+	djSuggestions = 30
+	// djTracksPerArtist caps how many top tracks of each suggested artist fill a short queue
+	djTracksPerArtist = 2
+	// maxVibeLength caps the vibe description, in bytes
+	maxVibeLength = 200
+)
+
+// djSuggestion is a track suggested by the AI
+type djSuggestion struct {
+	Name   string `json:"name"`
+	Artist string `json:"artist"`
+}
+
+// DJ handles POST /api/dj.
+// It asks the AI for tracks matching the vibe, in an order that flows, and
+// resolves them on Spotify. With push_to_spotify, the queue is also added to
+// the user's Spotify player using the user's access token from X-Spotify-Token.
+func (h *LyricsHandler) DJ(w http.ResponseWriter, r *http.Request) {
+	var req models.DJRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	vibe := strings.TrimSpace(req.Vibe)
+	if vibe == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Vibe is required")
+		return
+	}
+	if len(vibe) > maxVibeLength {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, fmt.Sprintf("Vibe must be at most %d characters", maxVibeLength))
+		return
+	}
+	userToken := strings.TrimSpace(r.Header.Get("X-Spotify-Token"))
+	if req.PushToSpotify && userToken == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "X-Spotify-Token is required to push to Spotify")
+		return
+	}
+
+	userID := userIDFromRequest(r)
+	tracks, err := h.buildDJQueue(vibe, userID)
+	if errors.Is(err, breaker.ErrOpen) {
+		apierror.Write(w, http.StatusServiceUnavailable, apierror.UpstreamUnavailable, "The AI service is temporarily unavailable")
+		return
+	}
+	if err != nil {
+		log.Printf("Error building DJ queue for %q: %v", vibe, err)
+		apierror.Write(w, http.StatusBadGateway, apierror.UpstreamUnavailable, "Failed to build a queue")
+		return
+	}
+	if len(tracks) == 0 {
+		apierror.Write(w, http.StatusBadGateway, apierror.UpstreamUnavailable, "No tracks were found for this vibe")
+		return
+	}
+
+	h.publish(events.RecommendationServed, events.RecommendationServedPayload{
+		UserID: userID,
+		Kind:   "dj",
+		Tracks: tracks,
+	})
+
+	response := models.DJResponse{Vibe: vibe, Tracks: tracks}
+	if req.PushToSpotify {
+		// In order, one at a time, so the player's queue keeps the flow
+		for _, track := range tracks {
+			if err := h.spotifyService.AddToQueue(userToken, track.ID); err != nil {
+				log.Printf("Error adding %s to the Spotify queue: %v", track.ID, err)
+				response.QueueError = "Failed to add tracks to the Spotify queue"
+				if errors.Is(err, spotify.ErrUserTokenRejected) {
+					response.QueueError = "Spotify rejected the access token"
+				}
+				break
+			}
+			response.Queued++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// buildDJQueue asks the AI for tracks matching vibe and resolves them on
+// Spotify, topping up with the suggested artists' top tracks when too few
+// are found. Explicit tracks are left out for restricted users.
+func (h *LyricsHandler) buildDJQueue(vibe, userID string) ([]models.UnifiedTrack, error) {
+	prompt := fmt.Sprintf(`You are a DJ. Pick %d real, well-known songs for this vibe: %q.
+Order them so the set flows, e.g. easing in, building up and winding down.
+Return a JSON object with:
+- tracks: Array of objects with "name" (the song title) and "artist"
+
+Important: Respond ONLY with valid JSON, no additional text.`, djSuggestions, vibe)
+
+	response, err := h.aiFor(userID).GenerateJSON(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suggestions: %w", err)
+	}
+	var suggestions struct {
+		Tracks []djSuggestion `json:"tracks"`
+	}
+	if err := json.Unmarshal([]byte(response), &suggestions); err != nil {
+		return nil, fmt.Errorf("failed to parse suggestions: %w", err)
+	}
+
+	// Resolve concurrently, keeping the AI's order
+	resolved := make([]*models.UnifiedTrack, len(suggestions.Tracks))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 5) // Search max 5 tracks at a time
+	for i, suggestion := range suggestions.Tracks {
+		if suggestion.Name == "" || suggestion.Artist == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, suggestion djSuggestion) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			track, err := h.spotifyService.SearchTrack(suggestion.Name, suggestion.Artist)
+			if err != nil {
+				log.Printf("DJ suggestion %q by %q not found: %v", suggestion.Name, suggestion.Artist, err)
+				return
+			}
+			resolved[i] = track
+		}(i, suggestion)
+	}
+	wg.Wait()
+
+	var queue []models.UnifiedTrack
+	seen := make(map[string]bool)
+	add := func(tracks []models.UnifiedTrack, limit int) {
+		if h.isRestricted(userID) {
+			tracks = h.restrictions.FilterTracks(tracks)
+		}
+		added := 0
+		for _, track := range tracks {
+			if len(queue) >= djQueueSize || added >= limit {
+				return
+			}
+			if track.ID == "" || seen[track.ID] {
+				continue
+			}
+			seen[track.ID] = true
+			queue = append(queue, track)
+			added++
+		}
+	}
+
+	var artists []string
+	seenArtists := make(map[string]bool)
+	for i, track := range resolved {
+		if track == nil {
+			continue
+		}
+		add([]models.UnifiedTrack{*track}, 1)
+		if artist := suggestions.Tracks[i].Artist; !seenArtists[strings.ToLower(artist)] {
+			seenArtists[strings.ToLower(artist)] = true
+			artists = append(artists, artist)
+		}
+	}
+
+	// Too few suggestions were found, so fill in with the artists' hits
+	for _, name := range artists {
+		if len(queue) >= djQueueSize {
+			break
+		}
+		artist, err := h.spotifyService.SearchArtist(name)
+		if err != nil {
+			log.Printf("Error resolving DJ artist %q: %v", name, err)
+			continue
+		}
+		topTracks, err := h.spotifyService.GetArtistTopTracks(artist.ID)
+		if err != nil {
+			log.Printf("Error getting top tracks for %s: %v", artist.Name, err)
+			continue
+		}
+		add(topTracks, djTracksPerArtist)
+	}
+
+	return queue, nil
+}
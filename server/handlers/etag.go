@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"backend/server/models"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// formatETag renders a resource version as a strong ETag value
+func formatETag(version int64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// nowPlayingETag identifies the representation served by GET /api/now-playing.
+// The version changes with the track and playback state; UpdatedAt also moves
+// with heartbeats and lyrics, which leave the version alone.
+func nowPlayingETag(nowPlaying *models.NowPlaying) string {
+	return fmt.Sprintf(`"%d-%x"`, nowPlaying.Version, nowPlaying.UpdatedAt.UnixNano())
+}
+
+// parseETag extracts the version from an If-Match/If-None-Match header value.
+// Weak validators (W/"...") are accepted since versions are compared exactly,
+// and anything after the version in a now-playing ETag is ignored.
+func parseETag(value string) (int64, error) {
+	tag := strings.TrimSpace(value)
+	tag = strings.TrimPrefix(tag, "W/")
+	tag = strings.Trim(tag, `"`)
+	tag, _, _ = strings.Cut(tag, "-")
+
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ETag %q", value)
+	}
+	return version, nil
+}
+
+// matchesETag reports whether an If-None-Match header value lists etag, using
+// weak comparison as RFC 9110 requires for If-None-Match
+func matchesETag(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/experiments"
+	"backend/services/prompts"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// lyricsFitter is implemented by AI services that condense lyrics too long
+// for the model's context window
+type lyricsFitter interface {
+	FitLyrics(lyrics string) (string, error)
+}
+
+// SetExperiments sets the prompt experiments that lyrics analyses and mood
+// detection take part in, and the templates their variants are rendered from
+func (h *LyricsHandler) SetExperiments(experiments experiments.Service, templates prompts.Service) {
+	h.experiments = experiments
+	h.prompts = templates
+}
+
+// assign returns the user's variant in the experiment on template, if any
+func (h *LyricsHandler) assign(template, userID string) (experiments.Assignment, bool) {
+	if h.experiments == nil {
+		return experiments.Assignment{}, false
+	}
+	return h.experiments.Assign(template, userID)
+}
+
+// record saves an experiment outcome, returning the response ID for feedback
+func (h *LyricsHandler) record(assignment experiments.Assignment, userID string, start time.Time, answer string, err error) string {
+	responseID, recordErr := h.experiments.Record(assignment, userID, time.Since(start).Milliseconds(), answer, err != nil)
+	if recordErr != nil {
+		log.Printf("Failed to record %s experiment outcome: %v", assignment.Experiment, recordErr)
+	}
+	return responseID
+}
+
+// analyzeLyrics answers a question about the current song, in clean mode if
+// asked. Users in a lyrics_analysis experiment get the prompt of their
+// variant, rendered here so every variant takes the same path to the AI service.
+func (h *LyricsHandler) analyzeLyrics(query, lyrics, songInfo, userID string, clean bool) (answer, responseID string, err error) {
+	ai := h.aiForRequest(userID, clean)
+	assignment, ok := h.assign(prompts.LyricsAnalysis, userID)
+	if !ok {
+		answer, err = ai.AnalyzeLyrics(query, lyrics, songInfo)
+		return answer, "", err
+	}
+
+	start := time.Now()
+	if h.isRestricted(userID) || h.isClean(userID, clean) {
+		lyrics = h.cleanMode.Mask(lyrics) // The variant's prompt embeds them as they are
+	}
+	if fitter, ok := h.aiService.(lyricsFitter); ok {
+		if lyrics, err = fitter.FitLyrics(lyrics); err != nil {
+			return "", h.record(assignment, userID, start, "", err), err
+		}
+	}
+	prompt, err := h.prompts.Render(assignment.Template, prompts.LyricsAnalysisData{
+		SongInfo: songInfo,
+		Query:    query,
+		Lyrics:   lyrics,
+	})
+	if err == nil {
+		answer, err = ai.GenerateResponse(prompt)
+	}
+	return answer, h.record(assignment, userID, start, answer, err), err
+}
+
+// detectMood detects the mood of a chat message, with the user's variant of
+// the prompt when a mood_detection experiment is running
+func (h *LyricsHandler) detectMood(query, userID string) (analysis *models.MoodAnalysis, responseID string, err error) {
+	assignment, ok := h.assign(prompts.MoodDetection, userID)
+	if !ok {
+		analysis, err = h.moodService.DetectMood(query)
+		return analysis, "", err
+	}
+
+	start := time.Now()
+	analysis, err = h.moodService.DetectMoodWithPrompt(assignment.Template, query)
+	return analysis, h.record(assignment, userID, start, "", err), err
+}
+
+// HandleChatFeedback handles POST /api/chat/feedback, rating an answer that
+// was part of a prompt experiment
+func (h *LyricsHandler) HandleChatFeedback(w http.ResponseWriter, r *http.Request) {
+	var req models.ChatFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+	if req.ResponseID == "" || req.Helpful == nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "response_id and helpful are required")
+		return
+	}
+	if h.experiments == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Response not found")
+		return
+	}
+
+	err := h.experiments.Feedback(userIDFromRequest(r), req.ResponseID, *req.Helpful)
+	if errors.Is(err, experiments.ErrNotFound) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Response not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error saving chat feedback: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to save feedback")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExperimentsHandler handles prompt experiment requests
+type ExperimentsHandler struct {
+	experiments experiments.Service
+}
+
+// NewExperimentsHandler creates a new experiments handler
+func NewExperimentsHandler(experiments experiments.Service) *ExperimentsHandler {
+	return &ExperimentsHandler{experiments: experiments}
+}
+
+// ListExperiments handles GET /api/admin/experiments, comparing the variants
+// of each running prompt experiment
+func (h *ExperimentsHandler) ListExperiments(w http.ResponseWriter, r *http.Request) {
+	response := models.ExperimentsResponse{Experiments: []models.ExperimentResult{}}
+	if h.experiments != nil {
+		results, err := h.experiments.Results()
+		if err != nil {
+			log.Printf("Error summarizing experiments: %v", err)
+			apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to summarize experiments")
+			return
+		}
+		response.Experiments = results
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
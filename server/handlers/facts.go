@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/services/facts"
+	"backend/services/genius"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// SetFacts sets the service collecting song facts. Without it
+// /api/tracks/current/facts answers 404.
+func (h *LyricsHandler) SetFacts(facts facts.Service) {
+	h.facts = facts
+}
+
+// GetCurrentTrackFacts handles GET /api/tracks/current/facts, returning the
+// current song's Genius metadata and a "did you know" blurb. The blurb is
+// masked in restricted and clean mode (?clean=true).
+func (h *LyricsHandler) GetCurrentTrackFacts(w http.ResponseWriter, r *http.Request) {
+	if h.facts == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Song facts are not available")
+		return
+	}
+	if !h.musicRepo.HasCurrentTrack() {
+		apierror.Write(w, http.StatusNotFound, apierror.NotPlaying, "No song is currently playing")
+		return
+	}
+
+	nowPlaying := h.musicRepo.GetNowPlaying()
+	songFacts, err := h.facts.Get(nowPlaying.TrackName, nowPlaying.Artist)
+	if errors.Is(err, genius.ErrSongNotFound) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "No facts found for this song")
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting facts for %s by %s: %v", nowPlaying.TrackName, nowPlaying.Artist, err)
+		apierror.Write(w, http.StatusBadGateway, apierror.UpstreamUnavailable, "Song facts are unavailable right now")
+		return
+	}
+
+	if userID := userIDFromRequest(r); h.isRestricted(userID) || h.isClean(userID, cleanRequested(r)) {
+		songFacts.DidYouKnow = h.cleanMode.Mask(songFacts.DidYouKnow)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(songFacts)
+}
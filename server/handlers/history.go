@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"backend/server/models"
+	"backend/server/pagination"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultHistoryLimit is the number of history items returned when no limit is given
+	defaultHistoryLimit = 50
+	// maxHistoryLimit caps the limit query parameter
+	maxHistoryLimit = 200
+)
+
+// historyQuery is a parsed GET /api/history request
+type historyQuery struct {
+	filter models.PlayHistoryFilter
+	offset int
+	limit  int
+	after  *historyCursor // The cursor the page continues from, if any
+}
+
+// historyCursor continues the history after the items played at playedAt,
+// truncated to the microseconds that stores keep, skipping the skip items
+// played then that earlier pages returned
+type historyCursor struct {
+	playedAt time.Time
+	skip     int
+}
+
+// parseHistoryQuery reads the filter and page of a GET /api/history request.
+// from and to are RFC 3339 times bounding when tracks were played. A cursor
+// narrows the filter to the items played from its position on.
+func parseHistoryQuery(query url.Values) (historyQuery, error) {
+	q := historyQuery{
+		filter: models.PlayHistoryFilter{
+			Source: query.Get("source"),
+			Artist: query.Get("artist"),
+		},
+	}
+
+	var err error
+	if from := query.Get("from"); from != "" {
+		if q.filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return q, errors.New("Invalid from (expected an RFC 3339 time)")
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		if q.filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return q, errors.New("Invalid to (expected an RFC 3339 time)")
+		}
+	}
+	if !q.filter.From.IsZero() && !q.filter.To.IsZero() && !q.filter.From.Before(q.filter.To) {
+		return q, errors.New("from must be before to")
+	}
+
+	page, err := pagination.Parse(query, defaultHistoryLimit, maxHistoryLimit)
+	if err != nil {
+		return q, err
+	}
+	q.limit = page.Limit
+
+	if offsetParam := query.Get("offset"); offsetParam != "" {
+		parsed, err := strconv.Atoi(offsetParam)
+		if err != nil || parsed < 0 {
+			return q, errors.New("Invalid offset")
+		}
+		q.offset = parsed
+	}
+
+	var cursor historyCursor
+	ok, err := page.Decode(&cursor.playedAt, &cursor.skip)
+	if err != nil || (ok && cursor.skip < 0) {
+		return q, errors.New("Invalid cursor")
+	}
+	if ok {
+		if q.offset != 0 {
+			return q, errors.New("offset can't be combined with cursor")
+		}
+		before := cursor.playedAt.Add(time.Microsecond)
+		if q.filter.To.IsZero() || before.Before(q.filter.To) {
+			q.filter.To = before
+		}
+		q.offset = cursor.skip
+		q.after = &cursor
+	}
+
+	return q, nil
+}
+
+// nextHistoryCursor returns the cursor of the page after items, or an empty
+// one if total says there are no more
+func nextHistoryCursor(q historyQuery, items []models.PlayHistoryItem, total int) string {
+	if len(items) == 0 || q.offset+len(items) >= total {
+		return ""
+	}
+
+	last := items[len(items)-1].PlayedAt.Truncate(time.Microsecond)
+	skip := 0
+	for _, item := range items {
+		if item.PlayedAt.Truncate(time.Microsecond).Equal(last) {
+			skip++
+		}
+	}
+	if q.after != nil && q.after.playedAt.Equal(last) {
+		skip += q.after.skip
+	}
+	return pagination.Encode(last, skip)
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"backend/services/scheduler"
+	"encoding/json"
+	"net/http"
+)
+
+// JobsHandler handles background job requests
+type JobsHandler struct {
+	scheduler scheduler.Service
+}
+
+// NewJobsHandler creates a new jobs handler
+func NewJobsHandler(scheduler scheduler.Service) *JobsHandler {
+	return &JobsHandler{scheduler: scheduler}
+}
+
+// ListJobs handles GET /api/admin/jobs, describing each scheduled job and its latest run
+func (h *JobsHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.scheduler.Jobs())
+}
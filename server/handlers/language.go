@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// defaultLanguage is used when a query's language can't be told
+const defaultLanguage = "en"
+
+// languageNames are the languages answers can be requested in, by ISO 639-1
+// code. The AI is told to answer in the named language.
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"el": "Greek",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"sv": "Swedish",
+	"th": "Thai",
+	"tr": "Turkish",
+	"uk": "Ukrainian",
+	"zh": "Chinese",
+}
+
+// resolveLanguage returns the language to answer in: the requested one, which
+// may be a tag like "pt-BR", or else the one detected from the query
+func resolveLanguage(requested, query string) (string, error) {
+	if requested == "" {
+		return detectLanguage(query), nil
+	}
+	lang := strings.ToLower(strings.TrimSpace(requested))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := languageNames[lang]; !ok {
+		return "", fmt.Errorf("unsupported language %q", requested)
+	}
+	return lang, nil
+}
+
+// languageInstruction tells the AI which language to answer in. English is
+// the prompts' own language, so it needs no instruction.
+func languageInstruction(lang string) string {
+	if lang == defaultLanguage {
+		return ""
+	}
+	return fmt.Sprintf("Answer in %s.", languageNames[lang])
+}
+
+// withLanguageInstruction appends the language instruction to a prompt or query
+func withLanguageInstruction(text, lang string) string {
+	if instruction := languageInstruction(lang); instruction != "" {
+		return text + "\n\n" + instruction
+	}
+	return text
+}
+
+// scriptLanguages maps writing systems to the language detected for them
+var scriptLanguages = []struct {
+	script *unicode.RangeTable
+	lang   string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"}, // After kana, since Japanese also uses Han characters
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+	{unicode.Thai, "th"},
+}
+
+// stopWords are common words of languages written in Latin script. Queries
+// are scored by how many of their words are on each list.
+var stopWords = map[string][]string{
+	"en": {"the", "is", "what", "this", "song", "about", "does", "and", "of", "to", "i", "you", "it", "me", "my", "who", "how", "why", "are", "mean"},
+	"es": {"el", "la", "los", "las", "de", "que", "qué", "es", "esta", "este", "canción", "por", "para", "una", "y", "en", "mi", "cómo", "sobre", "trata", "del"},
+	"fr": {"le", "la", "les", "de", "des", "que", "est", "cette", "ce", "chanson", "une", "et", "je", "mon", "ma", "quoi", "sur", "du", "pourquoi", "comment", "parle"},
+	"de": {"der", "die", "das", "ist", "und", "was", "wie", "ein", "eine", "dieser", "dieses", "lied", "ich", "mein", "mir", "über", "von", "warum", "worum", "geht", "nicht"},
+	"pt": {"o", "os", "as", "de", "que", "é", "esta", "essa", "música", "do", "da", "um", "uma", "e", "em", "meu", "minha", "sobre", "fala", "como", "não", "você"},
+	"it": {"il", "lo", "gli", "di", "che", "è", "questa", "questo", "canzone", "un", "una", "e", "in", "mio", "mia", "cosa", "come", "perché", "parla", "del", "della"},
+	"nl": {"de", "het", "een", "is", "wat", "dit", "dat", "nummer", "lied", "en", "van", "over", "ik", "mijn", "waarom", "hoe", "gaat"},
+}
+
+// letterHints are letters that point to one language among those in stopWords
+var letterHints = map[rune]string{
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ã': "pt", 'õ': "pt",
+	'ß': "de", 'ä': "de", 'ö': "de", 'ü': "de",
+	'è': "fr", 'ê': "fr", 'à': "fr", 'œ': "fr",
+}
+
+// detectionOrder breaks ties between stop word scores, favoring English
+var detectionOrder = []string{"en", "es", "fr", "de", "pt", "it", "nl"}
+
+// detectLanguage guesses a query's language from its script, or for Latin
+// script from its common words. Short or ambiguous queries are English.
+func detectLanguage(query string) string {
+	scriptCounts := make(map[string]int)
+	scores := make(map[string]int)
+	for _, r := range query {
+		for _, entry := range scriptLanguages {
+			if unicode.Is(entry.script, r) {
+				scriptCounts[entry.lang]++
+				break
+			}
+		}
+		if lang, ok := letterHints[unicode.ToLower(r)]; ok {
+			scores[lang] += 2
+		}
+	}
+
+	// Japanese mixes kana with Han characters, so any kana decides it
+	if scriptCounts["ja"] > 0 {
+		return "ja"
+	}
+	best, bestCount := "", 0
+	for _, entry := range scriptLanguages {
+		if scriptCounts[entry.lang] > bestCount {
+			best, bestCount = entry.lang, scriptCounts[entry.lang]
+		}
+	}
+	if best != "" {
+		return best
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for lang, list := range stopWords {
+		for _, word := range words {
+			for _, stopWord := range list {
+				if word == stopWord {
+					scores[lang]++
+				}
+			}
+		}
+	}
+
+	best, bestScore := defaultLanguage, 0
+	for _, lang := range detectionOrder {
+		if scores[lang] > bestScore {
+			best, bestScore = lang, scores[lang]
+		}
+	}
+	return best
+}
+
+// localizedLyricsPatterns are phrases asking about the current song's lyrics,
+// in addition to the English ones in isLyricsRelatedQuery
+var localizedLyricsPatterns = map[string][]string{
+	"es": {"letra", "esta canción", "esta cancion", "qué significa", "que significa", "de qué trata", "de que trata", "significado"},
+	"fr": {"paroles", "cette chanson", "veut dire", "signifie", "parle de", "sens de"},
+	"de": {"songtext", "liedtext", "dieser song", "dieses lied", "bedeutet", "bedeutung", "worum geht"},
+	"pt": {"letra", "esta música", "essa música", "significa", "significado", "fala sobre"},
+}
+
+// localizedMusicKeywords mark general music questions, in addition to the
+// English ones in isMusicRelatedQuery
+var localizedMusicKeywords = map[string][]string{
+	"es": {"música", "musica", "canción", "cancion", "artista", "banda", "álbum", "género", "cantante", "concierto", "guitarra"},
+	"fr": {"musique", "chanson", "artiste", "groupe", "chanteur", "chanteuse", "concert", "guitare", "album"},
+	"de": {"musik", "lied", "song", "künstler", "band", "sänger", "sängerin", "konzert", "gitarre", "album"},
+	"pt": {"música", "musica", "canção", "artista", "banda", "cantor", "cantora", "álbum", "guitarra", "violão"},
+}
+
+// containsAny reports whether text contains one of the phrases
+func containsAny(text string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import "fmt"
+
+// Canned answers given without the AI, looked up with localize
+const (
+	msgNoSongPlaying      = "no_song_playing"
+	msgExplicitRestricted = "explicit_restricted"
+	msgLyricsUnavailable  = "lyrics_unavailable" // Song info, error
+	msgMusicOnly          = "music_only"
+	msgSearchingByArtist  = "searching_by_artist" // Song, artist
+	msgSearching          = "searching"           // Song
+	msgLibraryUnavailable = "library_unavailable" // Mood
+	msgArtistNotFound     = "artist_not_found"    // Artist
+	msgRadioUnavailable   = "radio_unavailable"   // Artist
+	msgRadio              = "radio"               // Artist
+	msgMoodDefault        = "mood_default"        // Mood
+	msgPlaylist           = "playlist"            // Track count, name, URL
+	msgPlaylistName       = "playlist_name"       // Mood
+	msgPlaylistDesc       = "playlist_desc"       // Mood, assistant
+	msgCrisisSupport      = "crisis_support"      // Followed by the helplines
+	msgQuizCorrect        = "quiz_correct"
+	msgQuizWrong          = "quiz_wrong"    // Correct choice
+	msgQuizQuestion       = "quiz_question" // Number, total, question
+	msgQuizFinished       = "quiz_finished" // Score, total
+	msgQuizStopped        = "quiz_stopped"  // Score, answered
+	msgQuizNoTopic        = "quiz_no_topic"
+	msgQuizUnavailable    = "quiz_unavailable"
+)
+
+// cannedMessages holds the canned answers by language. Languages missing here
+// get English ones, while the AI still answers in their language.
+var cannedMessages = map[string]map[string]string{
+	"en": {
+		msgNoSongPlaying:      "No song is currently playing. Please play a song in Spotify first, and I'll be able to help you understand its lyrics and meaning.",
+		msgExplicitRestricted: "This song is marked as explicit, so I can't discuss its lyrics in restricted mode. Try asking about another song!",
+		msgLyricsUnavailable:  "I can see that you're currently playing \"%s\", but I couldn't fetch the lyrics: %v\n\nYou can still ask me general questions about this song or artist!",
+		msgMusicOnly:          "I can only help with questions about music, songs, lyrics, and artists. Please ask me something related to music!",
+		msgSearchingByArtist:  "I'm searching for \"%s\" by %s in your playlists. Let me show you what I found!",
+		msgSearching:          "I'm searching for \"%s\" in your playlists. Let me show you what I found!",
+		msgLibraryUnavailable: "I understand you're feeling %s, but I'm having trouble accessing your music library right now. Please try again later.",
+		msgArtistNotFound:     "I couldn't find an artist called \"%s\". Could you check the spelling?",
+		msgRadioUnavailable:   "I found %s, but couldn't put together a radio for them right now. Please try again later.",
+		msgRadio:              "Here's a radio inspired by %s, mixing their biggest tracks with similar artists:",
+		msgMoodDefault:        "I can sense you're feeling %s. Music has a way of connecting with our emotions. Here are some songs that might resonate with how you're feeling:",
+		msgPlaylist:           "I saved %d songs to a new Spotify playlist, \"%s\": %s",
+		msgPlaylistName:       "Feeling %s",
+		msgPlaylistDesc:       "Songs for when you're feeling %s, picked by %s",
+		msgCrisisSupport:      "I'm really sorry you're going through this. You don't have to face it alone, and talking to someone can help right now. If you're in immediate danger, please call your local emergency number. These services offer free, confidential support:",
+		msgQuizCorrect:        "Correct!",
+		msgQuizWrong:          "Not quite, the answer was %s.",
+		msgQuizQuestion:       "Question %d of %d: %s",
+		msgQuizFinished:       "That's the end of the quiz! You scored %d out of %d.",
+		msgQuizStopped:        "Quiz stopped. You scored %d out of %d answered.",
+		msgQuizNoTopic:        "Play a song or listen to a few first, and I'll quiz you about them!",
+		msgQuizUnavailable:    "I couldn't put a quiz together right now. Please try again later.",
+	},
+	"es": {
+		msgNoSongPlaying:      "No se está reproduciendo ninguna canción. Reproduce primero una canción en Spotify y te ayudaré a entender su letra y su significado.",
+		msgExplicitRestricted: "Esta canción está marcada como explícita, así que no puedo comentar su letra en el modo restringido. ¡Prueba a preguntar por otra canción!",
+		msgLyricsUnavailable:  "Veo que estás escuchando \"%s\", pero no he podido obtener la letra: %v\n\n¡Aun así puedes hacerme preguntas generales sobre esta canción o este artista!",
+		msgMusicOnly:          "Solo puedo ayudarte con preguntas sobre música, canciones, letras y artistas. ¡Pregúntame algo relacionado con la música!",
+		msgSearchingByArtist:  "Estoy buscando \"%s\" de %s en tus listas. ¡Te muestro lo que he encontrado!",
+		msgSearching:          "Estoy buscando \"%s\" en tus listas. ¡Te muestro lo que he encontrado!",
+		msgLibraryUnavailable: "Entiendo que te sientes %s, pero ahora mismo no puedo acceder a tu biblioteca musical. Inténtalo de nuevo más tarde.",
+		msgArtistNotFound:     "No he encontrado ningún artista llamado \"%s\". ¿Puedes comprobar cómo se escribe?",
+		msgRadioUnavailable:   "He encontrado a %s, pero ahora mismo no puedo preparar una radio. Inténtalo de nuevo más tarde.",
+		msgRadio:              "Aquí tienes una radio inspirada en %s, que mezcla sus mayores éxitos con artistas similares:",
+		msgMoodDefault:        "Noto que te sientes %s. La música sabe conectar con nuestras emociones. Aquí tienes algunas canciones que podrían reflejar cómo te sientes:",
+		msgPlaylist:           "He guardado %d canciones en una nueva lista de Spotify, \"%s\": %s",
+		msgPlaylistName:       "Me siento %s",
+		msgPlaylistDesc:       "Canciones para cuando te sientes %s, elegidas por %s",
+		msgCrisisSupport:      "Siento mucho que estés pasando por esto. No tienes que afrontarlo solo, y hablar con alguien puede ayudarte ahora mismo. Si estás en peligro inmediato, llama al número de emergencias de tu zona. Estos servicios ofrecen apoyo gratuito y confidencial:",
+		msgQuizCorrect:        "¡Correcto!",
+		msgQuizWrong:          "No exactamente, la respuesta era %s.",
+		msgQuizQuestion:       "Pregunta %d de %d: %s",
+		msgQuizFinished:       "¡Fin del quiz! Has acertado %d de %d.",
+		msgQuizStopped:        "Quiz detenido. Has acertado %d de %d respondidas.",
+		msgQuizNoTopic:        "Reproduce una canción o escucha algunas primero, ¡y te haré preguntas sobre ellas!",
+		msgQuizUnavailable:    "Ahora mismo no puedo preparar un quiz. Inténtalo de nuevo más tarde.",
+	},
+	"fr": {
+		msgNoSongPlaying:      "Aucune chanson n'est en cours de lecture. Lance d'abord une chanson sur Spotify, et je pourrai t'aider à comprendre ses paroles et leur sens.",
+		msgExplicitRestricted: "Cette chanson est marquée comme explicite, je ne peux donc pas parler de ses paroles en mode restreint. Essaie de me demander une autre chanson !",
+		msgLyricsUnavailable:  "Je vois que tu écoutes \"%s\", mais je n'ai pas pu récupérer les paroles : %v\n\nTu peux quand même me poser des questions générales sur cette chanson ou cet artiste !",
+		msgMusicOnly:          "Je ne peux répondre qu'aux questions sur la musique, les chansons, les paroles et les artistes. Pose-moi une question sur la musique !",
+		msgSearchingByArtist:  "Je cherche \"%s\" de %s dans tes playlists. Voici ce que j'ai trouvé !",
+		msgSearching:          "Je cherche \"%s\" dans tes playlists. Voici ce que j'ai trouvé !",
+		msgLibraryUnavailable: "Je comprends que tu te sens %s, mais je n'arrive pas à accéder à ta bibliothèque musicale pour le moment. Réessaie plus tard.",
+		msgArtistNotFound:     "Je n'ai trouvé aucun artiste nommé \"%s\". Peux-tu vérifier l'orthographe ?",
+		msgRadioUnavailable:   "J'ai trouvé %s, mais je n'arrive pas à préparer une radio pour le moment. Réessaie plus tard.",
+		msgRadio:              "Voici une radio inspirée de %s, qui mélange ses plus grands titres avec des artistes similaires :",
+		msgMoodDefault:        "J'ai l'impression que tu te sens %s. La musique a le don de toucher nos émotions. Voici quelques chansons qui pourraient faire écho à ce que tu ressens :",
+		msgPlaylist:           "J'ai enregistré %d chansons dans une nouvelle playlist Spotify, \"%s\" : %s",
+		msgPlaylistName:       "Humeur : %s",
+		msgPlaylistDesc:       "Des chansons pour quand tu te sens %s, choisies par %s",
+		msgCrisisSupport:      "Je suis vraiment désolé que tu traverses ça. Tu n'as pas à y faire face seul, et parler à quelqu'un peut t'aider dès maintenant. Si tu es en danger immédiat, appelle le numéro d'urgence local. Ces services offrent une écoute gratuite et confidentielle :",
+		msgQuizCorrect:        "Bonne réponse !",
+		msgQuizWrong:          "Pas tout à fait, la réponse était %s.",
+		msgQuizQuestion:       "Question %d sur %d : %s",
+		msgQuizFinished:       "Le quiz est terminé ! Tu as obtenu %d sur %d.",
+		msgQuizStopped:        "Quiz arrêté. Tu as obtenu %d sur %d réponses.",
+		msgQuizNoTopic:        "Lance une chanson ou écoute-en quelques-unes d'abord, et je te poserai des questions dessus !",
+		msgQuizUnavailable:    "Je n'arrive pas à préparer un quiz pour le moment. Réessaie plus tard.",
+	},
+	"de": {
+		msgNoSongPlaying:      "Gerade läuft kein Song. Spiel zuerst einen Song auf Spotify ab, dann helfe ich dir, den Text und seine Bedeutung zu verstehen.",
+		msgExplicitRestricted: "Dieser Song ist als explizit markiert, deshalb kann ich seinen Text im eingeschränkten Modus nicht besprechen. Frag doch nach einem anderen Song!",
+		msgLyricsUnavailable:  "Ich sehe, dass gerade \"%s\" läuft, aber ich konnte den Text nicht abrufen: %v\n\nDu kannst mir trotzdem allgemeine Fragen zu diesem Song oder Künstler stellen!",
+		msgMusicOnly:          "Ich kann nur Fragen zu Musik, Songs, Songtexten und Künstlern beantworten. Frag mich etwas über Musik!",
+		msgSearchingByArtist:  "Ich suche \"%s\" von %s in deinen Playlists. Hier ist, was ich gefunden habe!",
+		msgSearching:          "Ich suche \"%s\" in deinen Playlists. Hier ist, was ich gefunden habe!",
+		msgLibraryUnavailable: "Ich verstehe, dass du dich %s fühlst, aber ich komme gerade nicht an deine Musikbibliothek. Bitte versuch es später noch einmal.",
+		msgArtistNotFound:     "Ich konnte keinen Künstler namens \"%s\" finden. Kannst du die Schreibweise prüfen?",
+		msgRadioUnavailable:   "Ich habe %s gefunden, kann aber gerade kein Radio zusammenstellen. Bitte versuch es später noch einmal.",
+		msgRadio:              "Hier ist ein Radio inspiriert von %s, mit den größten Hits und ähnlichen Künstlern:",
+		msgMoodDefault:        "Ich spüre, dass du dich %s fühlst. Musik kann unsere Gefühle auf besondere Weise berühren. Hier sind ein paar Songs, die zu deiner Stimmung passen könnten:",
+		msgPlaylist:           "Ich habe %d Songs in einer neuen Spotify-Playlist gespeichert, \"%s\": %s",
+		msgPlaylistName:       "Stimmung: %s",
+		msgPlaylistDesc:       "Songs für Momente, in denen du dich %s fühlst, ausgewählt von %s",
+		msgCrisisSupport:      "Es tut mir wirklich leid, dass du das gerade durchmachst. Du musst da nicht allein durch, und mit jemandem zu reden kann jetzt helfen. Wenn du in akuter Gefahr bist, ruf bitte den örtlichen Notruf an. Diese Stellen bieten kostenlose, vertrauliche Unterstützung:",
+		msgQuizCorrect:        "Richtig!",
+		msgQuizWrong:          "Nicht ganz, die Antwort war %s.",
+		msgQuizQuestion:       "Frage %d von %d: %s",
+		msgQuizFinished:       "Das Quiz ist vorbei! Du hast %d von %d Punkten erreicht.",
+		msgQuizStopped:        "Quiz beendet. Du hast %d von %d beantworteten Fragen richtig.",
+		msgQuizNoTopic:        "Spiel zuerst einen Song ab oder hör ein paar, dann stelle ich dir Fragen dazu!",
+		msgQuizUnavailable:    "Ich kann gerade kein Quiz zusammenstellen. Bitte versuch es später noch einmal.",
+	},
+	"pt": {
+		msgNoSongPlaying:      "Nenhuma música está tocando agora. Toque uma música no Spotify primeiro e eu vou te ajudar a entender a letra e o significado dela.",
+		msgExplicitRestricted: "Esta música está marcada como explícita, então não posso comentar a letra no modo restrito. Tente perguntar sobre outra música!",
+		msgLyricsUnavailable:  "Vejo que você está ouvindo \"%s\", mas não consegui obter a letra: %v\n\nVocê ainda pode me fazer perguntas gerais sobre esta música ou artista!",
+		msgMusicOnly:          "Só posso ajudar com perguntas sobre música, canções, letras e artistas. Pergunte algo sobre música!",
+		msgSearchingByArtist:  "Estou procurando \"%s\" de %s nas suas playlists. Veja o que encontrei!",
+		msgSearching:          "Estou procurando \"%s\" nas suas playlists. Veja o que encontrei!",
+		msgLibraryUnavailable: "Entendo que você está se sentindo %s, mas não estou conseguindo acessar sua biblioteca de músicas agora. Tente novamente mais tarde.",
+		msgArtistNotFound:     "Não encontrei nenhum artista chamado \"%s\". Pode conferir a grafia?",
+		msgRadioUnavailable:   "Encontrei %s, mas não consegui montar uma rádio agora. Tente novamente mais tarde.",
+		msgRadio:              "Aqui está uma rádio inspirada em %s, misturando os maiores sucessos com artistas parecidos:",
+		msgMoodDefault:        "Percebo que você está se sentindo %s. A música tem um jeito de se conectar com nossas emoções. Aqui estão algumas músicas que podem combinar com o que você está sentindo:",
+		msgPlaylist:           "Salvei %d músicas em uma nova playlist do Spotify, \"%s\": %s",
+		msgPlaylistName:       "Me sentindo %s",
+		msgPlaylistDesc:       "Músicas para quando você está se sentindo %s, escolhidas por %s",
+		msgCrisisSupport:      "Sinto muito que você esteja passando por isso. Você não precisa enfrentar isso sozinho, e conversar com alguém pode ajudar agora mesmo. Se você estiver em perigo imediato, ligue para o número de emergência local. Estes serviços oferecem apoio gratuito e confidencial:",
+		msgQuizCorrect:        "Correto!",
+		msgQuizWrong:          "Não exatamente, a resposta era %s.",
+		msgQuizQuestion:       "Pergunta %d de %d: %s",
+		msgQuizFinished:       "Fim do quiz! Você acertou %d de %d.",
+		msgQuizStopped:        "Quiz encerrado. Você acertou %d de %d respondidas.",
+		msgQuizNoTopic:        "Toque uma música ou ouça algumas primeiro, e eu faço perguntas sobre elas!",
+		msgQuizUnavailable:    "Não consegui montar um quiz agora. Tente novamente mais tarde.",
+	},
+}
+
+// localize returns a canned answer in the language, or in English if it has
+// no translation, formatted with args
+func localize(lang, key string, args ...interface{}) string {
+	message, ok := cannedMessages[lang][key]
+	if !ok {
+		message = cannedMessages[defaultLanguage][key]
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// moodNames translates detected moods for canned answers; English uses the
+// mood as is
+var moodNames = map[string]map[string]string{
+	"es": {"sad": "triste", "happy": "feliz", "angry": "enfadado", "lonely": "solo", "anxious": "ansioso", "nostalgic": "nostálgico", "energetic": "con energía", "calm": "tranquilo"},
+	"fr": {"sad": "triste", "happy": "heureux", "angry": "en colère", "lonely": "seul", "anxious": "anxieux", "nostalgic": "nostalgique", "energetic": "plein d'énergie", "calm": "calme"},
+	"de": {"sad": "traurig", "happy": "glücklich", "angry": "wütend", "lonely": "einsam", "anxious": "ängstlich", "nostalgic": "nostalgisch", "energetic": "voller Energie", "calm": "ruhig"},
+	"pt": {"sad": "triste", "happy": "feliz", "angry": "com raiva", "lonely": "sozinho", "anxious": "ansioso", "nostalgic": "nostálgico", "energetic": "cheio de energia", "calm": "calmo"},
+}
+
+// localizedMood returns the mood's name in the language, if it has one
+func localizedMood(lang, mood string) string {
+	if name, ok := moodNames[lang][mood]; ok {
+		return name
+	}
+	return mood
+}
+
+// empatheticResponses introduce mood recommendations, by language and mood
+var empatheticResponses = map[string]map[string]string{
+	"en": {
+		"lonely":    "I hear you're feeling disconnected right now. Sometimes music can be a companion when we feel alone. Here are some songs that explore similar feelings and might resonate with you:",
+		"sad":       "I understand you're going through a difficult time. Music has a way of expressing what we can't always put into words. These songs might help you process these feelings:",
+		"happy":     "It's wonderful that you're feeling so positive! Let's keep that energy going with some uplifting tracks that match your mood:",
+		"angry":     "I can sense your frustration. Sometimes we need music that matches our intensity and helps us release these feelings. Here are some powerful tracks for you:",
+		"anxious":   "I understand you're feeling overwhelmed. These songs might help you find some calm or at least know you're not alone in feeling this way:",
+		"nostalgic": "Ah, feeling nostalgic... Music has a unique way of taking us back. Here are some songs that capture that bittersweet feeling of remembering:",
+		"energetic": "You're full of energy! Let's channel that into some high-powered tracks that'll keep you motivated:",
+		"calm":      "Finding your peace... Here are some tranquil songs to help maintain that serene state of mind:",
+	},
+	"es": {
+		"lonely":    "Entiendo que ahora mismo te sientes desconectado. A veces la música puede hacernos compañía cuando nos sentimos solos. Aquí tienes algunas canciones que exploran sentimientos parecidos y que podrían llegarte:",
+		"sad":       "Entiendo que estás pasando por un momento difícil. La música sabe expresar lo que no siempre podemos decir con palabras. Estas canciones podrían ayudarte a procesar lo que sientes:",
+		"happy":     "¡Qué bien que te sientas tan positivo! Mantengamos esa energía con unas canciones alegres que encajan con tu estado de ánimo:",
+		"angry":     "Noto tu frustración. A veces necesitamos música que esté a la altura de nuestra intensidad y nos ayude a soltar lo que sentimos. Aquí tienes unos temas potentes:",
+		"anxious":   "Entiendo que te sientes desbordado. Estas canciones podrían ayudarte a encontrar algo de calma, o al menos a saber que no eres el único que se siente así:",
+		"nostalgic": "Ah, un momento nostálgico... La música tiene una forma única de llevarnos atrás. Aquí tienes algunas canciones que capturan esa sensación agridulce de recordar:",
+		"energetic": "¡Estás lleno de energía! Canalicémosla con unos temas potentes que te mantengan motivado:",
+		"calm":      "Encontrando la paz... Aquí tienes algunas canciones tranquilas para mantener ese estado de serenidad:",
+	},
+	"fr": {
+		"lonely":    "J'entends que tu te sens déconnecté en ce moment. Parfois, la musique peut nous tenir compagnie quand on se sent seul. Voici quelques chansons qui explorent des sentiments proches et qui pourraient te parler :",
+		"sad":       "Je comprends que tu traverses une période difficile. La musique sait exprimer ce qu'on n'arrive pas toujours à dire avec des mots. Ces chansons pourraient t'aider à accueillir ce que tu ressens :",
+		"happy":     "C'est génial que tu te sentes si bien ! Gardons cette énergie avec des morceaux entraînants qui collent à ton humeur :",
+		"angry":     "Je sens ta frustration. Parfois, on a besoin d'une musique à la hauteur de notre intensité pour relâcher tout ça. Voici quelques morceaux puissants pour toi :",
+		"anxious":   "Je comprends que tu te sens dépassé. Ces chansons pourraient t'aider à retrouver un peu de calme, ou au moins à savoir que tu n'es pas seul à ressentir ça :",
+		"nostalgic": "Ah, un moment de nostalgie... La musique a une façon unique de nous ramener en arrière. Voici quelques chansons qui capturent ce sentiment doux-amer du souvenir :",
+		"energetic": "Tu débordes d'énergie ! Canalisons-la avec des morceaux puissants qui te garderont motivé :",
+		"calm":      "En quête de paix... Voici quelques chansons apaisantes pour garder cet état de sérénité :",
+	},
+	"de": {
+		"lonely":    "Ich höre, dass du dich gerade abgeschnitten fühlst. Manchmal kann Musik ein Begleiter sein, wenn wir uns allein fühlen. Hier sind ein paar Songs über ähnliche Gefühle, die dich vielleicht ansprechen:",
+		"sad":       "Ich verstehe, dass du gerade eine schwere Zeit durchmachst. Musik kann ausdrücken, wofür uns oft die Worte fehlen. Diese Songs helfen dir vielleicht, deine Gefühle zu verarbeiten:",
+		"happy":     "Schön, dass du so gut drauf bist! Lass uns die Energie mit ein paar fröhlichen Songs halten, die zu deiner Stimmung passen:",
+		"angry":     "Ich spüre deinen Frust. Manchmal brauchen wir Musik, die unserer Intensität entspricht und uns hilft, die Gefühle rauszulassen. Hier sind ein paar kraftvolle Tracks für dich:",
+		"anxious":   "Ich verstehe, dass dir gerade alles zu viel ist. Diese Songs helfen dir vielleicht, etwas Ruhe zu finden, oder zeigen dir zumindest, dass du mit diesem Gefühl nicht allein bist:",
+		"nostalgic": "Ah, ein nostalgischer Moment... Musik kann uns auf einzigartige Weise zurückversetzen. Hier sind ein paar Songs, die dieses bittersüße Gefühl des Erinnerns einfangen:",
+		"energetic": "Du steckst voller Energie! Lass uns das in ein paar kraftvolle Tracks stecken, die dich motiviert halten:",
+		"calm":      "Zur Ruhe kommen... Hier sind ein paar ruhige Songs, die dir helfen, diesen gelassenen Zustand zu bewahren:",
+	},
+	"pt": {
+		"lonely":    "Entendo que você está se sentindo desconectado agora. Às vezes a música pode ser uma companhia quando nos sentimos sozinhos. Aqui estão algumas músicas que falam de sentimentos parecidos e podem tocar você:",
+		"sad":       "Entendo que você está passando por um momento difícil. A música consegue expressar o que nem sempre conseguimos dizer em palavras. Estas músicas podem ajudar você a lidar com esses sentimentos:",
+		"happy":     "Que ótimo que você está se sentindo tão bem! Vamos manter essa energia com algumas músicas animadas que combinam com seu humor:",
+		"angry":     "Percebo sua frustração. Às vezes precisamos de músicas que acompanhem nossa intensidade e nos ajudem a liberar esses sentimentos. Aqui estão algumas faixas poderosas para você:",
+		"anxious":   "Entendo que você está se sentindo sobrecarregado. Estas músicas podem ajudar você a encontrar um pouco de calma, ou pelo menos mostrar que você não está sozinho nisso:",
+		"nostalgic": "Ah, bateu a nostalgia... A música tem um jeito único de nos levar de volta. Aqui estão algumas músicas que capturam esse sentimento agridoce de lembrar:",
+		"energetic": "Você está cheio de energia! Vamos canalizar isso em algumas faixas potentes que vão manter você motivado:",
+		"calm":      "Encontrando a paz... Aqui estão algumas músicas tranquilas para ajudar a manter esse estado de serenidade:",
+	},
+}
+
+// restrictedEmpatheticResponses replace responses that are too intense for
+// restricted mode, by language and mood
+var restrictedEmpatheticResponses = map[string]map[string]string{
+	"en": {
+		"angry":   "It sounds like something really frustrated you. Music can help you cool down and let it go. Here are some songs that might help:",
+		"sad":     "I'm sorry you're having a hard time. If it keeps feeling heavy, talking to a trusted adult can really help. Here are some gentle songs for now:",
+		"lonely":  "Feeling alone is tough. Reaching out to a friend, family member or another trusted adult can help. Here are some songs to keep you company:",
+		"anxious": "It sounds like a lot is on your mind. Try taking a few slow breaths, and talk to a trusted adult if it doesn't ease up. These calming songs might help:",
+	},
+	"es": {
+		"angry":   "Parece que algo te ha frustrado mucho. La música puede ayudarte a calmarte y a dejarlo ir. Aquí tienes algunas canciones que pueden ayudarte:",
+		"sad":     "Siento que lo estés pasando mal. Si sigue pesando, hablar con un adulto de confianza puede ayudar mucho. Por ahora, aquí tienes algunas canciones suaves:",
+		"lonely":  "Sentirse solo es duro. Hablar con un amigo, un familiar u otro adulto de confianza puede ayudar. Aquí tienes algunas canciones para hacerte compañía:",
+		"anxious": "Parece que tienes muchas cosas en la cabeza. Prueba a respirar despacio unas cuantas veces y habla con un adulto de confianza si no se te pasa. Estas canciones relajantes podrían ayudarte:",
+	},
+	"fr": {
+		"angry":   "On dirait que quelque chose t'a vraiment contrarié. La musique peut t'aider à te calmer et à passer à autre chose. Voici quelques chansons qui pourraient t'aider :",
+		"sad":     "Je suis désolé que ce soit difficile en ce moment. Si ça reste lourd, en parler à un adulte de confiance peut vraiment aider. Voici quelques chansons douces pour l'instant :",
+		"lonely":  "Se sentir seul, c'est dur. Parler à un ami, à quelqu'un de ta famille ou à un autre adulte de confiance peut aider. Voici quelques chansons pour te tenir compagnie :",
+		"anxious": "On dirait que tu as beaucoup de choses en tête. Essaie de respirer lentement quelques fois, et parles-en à un adulte de confiance si ça ne passe pas. Ces chansons apaisantes pourraient t'aider :",
+	},
+	"de": {
+		"angry":   "Es klingt, als hätte dich etwas richtig geärgert. Musik kann dir helfen, runterzukommen und es loszulassen. Hier sind ein paar Songs, die helfen könnten:",
+		"sad":     "Es tut mir leid, dass es dir gerade schwerfällt. Wenn es sich weiter schwer anfühlt, kann es sehr helfen, mit einem Erwachsenen zu reden, dem du vertraust. Hier sind erst einmal ein paar sanfte Songs:",
+		"lonely":  "Sich allein zu fühlen ist hart. Mit einem Freund, jemandem aus deiner Familie oder einem anderen Erwachsenen zu reden, dem du vertraust, kann helfen. Hier sind ein paar Songs, die dir Gesellschaft leisten:",
+		"anxious": "Es klingt, als ginge dir gerade viel durch den Kopf. Atme ein paarmal langsam durch und sprich mit einem Erwachsenen, dem du vertraust, wenn es nicht besser wird. Diese beruhigenden Songs könnten helfen:",
+	},
+	"pt": {
+		"angry":   "Parece que algo te deixou muito frustrado. A música pode ajudar você a se acalmar e deixar isso para trás. Aqui estão algumas músicas que podem ajudar:",
+		"sad":     "Sinto muito que as coisas estejam difíceis. Se continuar pesado, conversar com um adulto de confiança pode ajudar muito. Por enquanto, aqui estão algumas músicas tranquilas:",
+		"lonely":  "Sentir-se sozinho é difícil. Conversar com um amigo, alguém da família ou outro adulto de confiança pode ajudar. Aqui estão algumas músicas para te fazer companhia:",
+		"anxious": "Parece que tem muita coisa na sua cabeça. Tente respirar devagar algumas vezes e converse com um adulto de confiança se não melhorar. Estas músicas calmas podem ajudar:",
+	},
+}
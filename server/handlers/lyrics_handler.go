@@ -2,133 +2,380 @@ package handlers
 
 import (
 	"backend/repositories"
+	"backend/server/apierror"
 	"backend/server/models"
+	"backend/server/pagination"
+	"backend/services/albums"
+	"backend/services/analysiscache"
+	"backend/services/breaker"
+	"backend/services/cleanmode"
+	"backend/services/comparison"
+	"backend/services/events"
+	"backend/services/facts"
+	"backend/services/experiments"
+	"backend/services/jobqueue"
 	"backend/services/mood"
-	// "backend/services/ollama"  // Uncomment when using Ollama
-	"backend/services/openai"
+	"backend/services/prompts"
+	"backend/services/restricted"
+	"backend/services/safety"
 	"backend/services/spotify"
+	"backend/services/trivia"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-// AIService defines a common interface for AI services (both Ollama and OpenAI)
+// AIService defines a common interface for AI services (Ollama, OpenAI and Anthropic)
 type AIService interface {
 	AnalyzeLyrics(query, lyrics, songInfo string) (string, error)
 	GenerateResponse(prompt string) (string, error)
+	GenerateJSON(prompt string) (string, error)
 	IsAvailable() error
 }
 
+// defaultUserID is used when a request does not identify its user
+const defaultUserID = "default_user"
+
 // LyricsHandler handles lyrics-related HTTP requests
 type LyricsHandler struct {
-	musicRepo      *repositories.MusicRepository
-	// AI Services - comment/uncomment to switch between providers
-	aiService      AIService  // Currently active AI service
-	// ollamaService  ollama.Service  // Uncomment to use Ollama
-	openaiService  openai.Service  // Comment to disable OpenAI
+	musicRepo      repositories.MusicRepository
+	moodCatalog    *repositories.MoodCatalog
+	aiService      AIService // Active AI provider, selected via AI_PROVIDER
+	aiForUser      func(userID string) AIService // Optional; scopes AI usage to a user
+	eventBus       events.Bus                    // Optional; receives mood and recommendation events
+	restrictions   restricted.Service            // Optional; enforces restricted (parental/teen) mode
+	cleanMode      cleanmode.Service             // Masks profanity for users and requests in clean mode
+	safety         safety.Service                // Optional; recognizes crises and provides helplines
+	jobQueue       jobqueue.Service              // Optional; runs slow analyses in the background
+	facts          facts.Service                 // Optional; collects song facts
+	trivia         trivia.Service                // Optional; runs quizzes in chat
+	comparison     comparison.Service            // Optional; compares songs
+	albums         albums.Service                // Optional; analyzes albums
+	analyses       analysiscache.Service         // Optional; stores answers to common lyrics questions
+	experiments    experiments.Service           // Optional; tries prompt variants on users
+	prompts        prompts.Service               // Renders experiment variants; set with experiments
 	moodService    mood.Service
 	spotifyService spotify.Service
+	customization  Customization // Optional; the deployment's own canned answers
 }
 
 // NewLyricsHandler creates a new lyrics handler
 func NewLyricsHandler(
-	musicRepo *repositories.MusicRepository,
-	// ollamaService ollama.Service,  // Uncomment to use Ollama
-	openaiService openai.Service,  // Comment to disable OpenAI
+	musicRepo repositories.MusicRepository,
+	aiService AIService,
 	moodService mood.Service,
 	spotifyService spotify.Service,
 ) *LyricsHandler {
-	handler := &LyricsHandler{
+	return &LyricsHandler{
 		musicRepo:      musicRepo,
-		// ollamaService:  ollamaService,  // Uncomment to use Ollama
-		openaiService:  openaiService,  // Comment to disable OpenAI
+		moodCatalog:    repositories.NewMoodCatalog(repositories.DefaultMoodCatalog()),
+		aiService:      aiService,
+		cleanMode:      cleanmode.New(cleanmode.Config{}),
 		moodService:    moodService,
 		spotifyService: spotifyService,
 	}
-	
-	// Set the active AI service - comment/uncomment to switch
-	// handler.aiService = ollamaService  // Use Ollama
-	handler.aiService = openaiService  // Use OpenAI
-	
-	return handler
 }
 
-// UpdateNowPlaying handles POST /api/now-playing
+// SetMoodCatalog replaces the built-in curated mood suggestions, e.g. with a
+// catalog kept in the database
+func (h *LyricsHandler) SetMoodCatalog(catalog *repositories.MoodCatalog) {
+	h.moodCatalog = catalog
+}
+
+// SetUserAIService sets a function returning the AI service to use for a given
+// user, e.g. one that charges the user's token budget. Without it every user
+// shares the default AI service.
+func (h *LyricsHandler) SetUserAIService(aiForUser func(userID string) AIService) {
+	h.aiForUser = aiForUser
+}
+
+// SetEventBus sets the bus that mood and recommendation events are published to
+func (h *LyricsHandler) SetEventBus(eventBus events.Bus) {
+	h.eventBus = eventBus
+}
+
+// SetRestrictions sets the service deciding which users are in restricted mode
+func (h *LyricsHandler) SetRestrictions(restrictions restricted.Service) {
+	h.restrictions = restrictions
+}
+
+// SetSafety sets the service recognizing messages that indicate a crisis.
+// Without it chat answers never offer support resources.
+func (h *LyricsHandler) SetSafety(safety safety.Service) {
+	h.safety = safety
+}
+
+// SetJobQueue sets the queue that bulk mood analyses can be run on. Without
+// it async track mood requests are rejected.
+func (h *LyricsHandler) SetJobQueue(jobQueue jobqueue.Service) {
+	h.jobQueue = jobQueue
+}
+
+// isRestricted reports whether userID is in restricted mode
+func (h *LyricsHandler) isRestricted(userID string) bool {
+	return h.restrictions != nil && h.restrictions.IsRestricted(userID)
+}
+
+// publish sends an event if an event bus is set
+func (h *LyricsHandler) publish(eventType string, payload interface{}) {
+	if h.eventBus != nil {
+		h.eventBus.Publish(eventType, payload)
+	}
+}
+
+// aiFor returns the AI service to use for userID
+func (h *LyricsHandler) aiFor(userID string) AIService {
+	return h.aiForRequest(userID, false)
+}
+
+// aiForRequest returns the AI service to use for a request by userID, kept
+// family-friendly if the request or the user asks for clean mode. Restricted
+// mode already covers clean mode's rules.
+func (h *LyricsHandler) aiForRequest(userID string, clean bool) AIService {
+	ai := h.aiService
+	if h.aiForUser != nil {
+		ai = h.aiForUser(userID)
+	}
+	if h.isRestricted(userID) {
+		return h.restrictions.WrapAI(ai)
+	}
+	if h.isClean(userID, clean) {
+		return h.cleanMode.WrapAI(ai)
+	}
+	return ai
+}
+
+// userIDFromRequest returns the caller's user ID from the X-User-ID header
+func userIDFromRequest(r *http.Request) string {
+	if userID := strings.TrimSpace(r.Header.Get("X-User-ID")); userID != "" {
+		return userID
+	}
+	return defaultUserID
+}
+
+// UpdateNowPlaying handles POST /api/now-playing.
+// Clients may send If-Match with the ETag from GET /api/now-playing; if the
+// track changed in the meantime, or another source currently owns playback,
+// the update is rejected with 409 Conflict and the current state is returned.
 func (h *LyricsHandler) UpdateNowPlaying(w http.ResponseWriter, r *http.Request) {
 	// Parse request body into generic map first
 	var trackData map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&trackData); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
 		return
 	}
 
+	var unifiedTrack models.UnifiedTrack
+
 	// Check if it has a source field (UnifiedTrack) or default to spotify
 	if source, hasSource := trackData["source"]; hasSource && source != nil {
 		// Parse as UnifiedTrack
 		trackBytes, _ := json.Marshal(trackData)
-		var unifiedTrack models.UnifiedTrack
 		if err := json.Unmarshal(trackBytes, &unifiedTrack); err != nil {
-			http.Error(w, "Invalid unified track data", http.StatusBadRequest)
-			return
-		}
-		
-		// Validate required fields
-		if unifiedTrack.ID == "" || unifiedTrack.Name == "" {
-			http.Error(w, "Missing required fields", http.StatusBadRequest)
+			apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid unified track data")
 			return
 		}
-		
-		// Update the currently playing track
-		h.musicRepo.UpdateNowPlayingUnified(unifiedTrack)
-		log.Printf("Now playing updated (%s): %s by %s", unifiedTrack.Source, unifiedTrack.Name, unifiedTrack.Artist)
 	} else {
 		// Parse as SpotifyTrack for backward compatibility
 		trackBytes, _ := json.Marshal(trackData)
 		var track models.SpotifyTrack
 		if err := json.Unmarshal(trackBytes, &track); err != nil {
-			http.Error(w, "Invalid spotify track data", http.StatusBadRequest)
+			apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid spotify track data")
 			return
 		}
+		unifiedTrack = models.FromSpotifyTrack(track)
+	}
 
-		// Validate required fields
-		if track.ID == "" || track.Name == "" {
-			http.Error(w, "Missing required fields", http.StatusBadRequest)
+	// Validate required fields
+	if unifiedTrack.ID == "" || unifiedTrack.Name == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Missing required fields")
+		return
+	}
+
+	// Optional playback progress, as a heartbeat would report it
+	var progress struct {
+		ProgressMs *int64 `json:"progress_ms"`
+		DurationMs *int64 `json:"duration_ms"`
+		IsPaused   bool   `json:"is_paused"`
+	}
+	trackBytes, _ := json.Marshal(trackData)
+	if err := json.Unmarshal(trackBytes, &progress); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid playback progress")
+		return
+	}
+	if progress.DurationMs == nil && unifiedTrack.Duration > 0 {
+		durationMs := int64(unifiedTrack.Duration) * 1000
+		progress.DurationMs = &durationMs
+	}
+	var positionMs, durationMs int64
+	if progress.ProgressMs != nil {
+		positionMs = *progress.ProgressMs
+	}
+	if progress.DurationMs != nil {
+		durationMs = *progress.DurationMs
+	}
+	if positionMs < 0 || durationMs < 0 || (durationMs > 0 && positionMs > durationMs) {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "progress_ms must be between 0 and duration_ms")
+		return
+	}
+
+	// Update the currently playing track, honouring If-Match when present.
+	// Updates are also rejected when another source currently owns playback.
+	var updated bool
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != "*" {
+		version, err := parseETag(ifMatch)
+		if err != nil {
+			apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, err.Error())
 			return
 		}
+		updated = h.musicRepo.UpdateNowPlayingIfVersion(unifiedTrack, version)
+	} else {
+		updated = h.musicRepo.UpdateNowPlayingUnified(unifiedTrack)
+	}
+	if !updated {
+		current := h.musicRepo.GetNowPlaying()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", formatETag(current.Version))
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(&current)
+		return
+	}
+	log.Printf("Now playing updated (%s): %s by %s", unifiedTrack.Source, unifiedTrack.Name, unifiedTrack.Artist)
 
-		// Update the currently playing track
-		h.musicRepo.UpdateNowPlaying(track)
-		log.Printf("Now playing updated (spotify): %s by %s", track.Name, track.Artist)
+	if progress.ProgressMs != nil || progress.DurationMs != nil {
+		h.musicRepo.Heartbeat(unifiedTrack.ID, positionMs, durationMs)
+	}
+	if progress.IsPaused {
+		h.musicRepo.SetPlaybackState(models.PlaybackPaused)
 	}
 
 	// Return success
+	w.Header().Set("ETag", formatETag(h.musicRepo.GetNowPlaying().Version))
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, "Now playing updated")
 }
 
 // GetNowPlaying handles GET /api/now-playing
 func (h *LyricsHandler) GetNowPlaying(w http.ResponseWriter, r *http.Request) {
-	// Check if a song is playing
-	if !h.musicRepo.IsPlaying() {
-		http.Error(w, "No song is currently playing", http.StatusNotFound)
+	// Check if a song is loaded (playing or paused)
+	if !h.musicRepo.HasCurrentTrack() {
+		apierror.Write(w, http.StatusNotFound, apierror.NotPlaying, "No song is currently playing")
 		return
 	}
 
 	// Get the currently playing song
 	nowPlaying := h.musicRepo.GetNowPlaying()
 
+	// Pollers that already have this state get an empty 304. Masked lyrics
+	// are a representation of their own.
+	etag := nowPlayingETag(&nowPlaying)
+	if userID := userIDFromRequest(r); h.isRestricted(userID) || h.isClean(userID, cleanRequested(r)) {
+		nowPlaying.Lyrics = h.cleanMode.Mask(nowPlaying.Lyrics)
+		etag = strings.TrimSuffix(etag, `"`) + `-clean"`
+	}
+	w.Header().Set("ETag", etag)
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && matchesETag(ifNoneMatch, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	// Return the currently playing song
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(nowPlaying)
+	json.NewEncoder(w).Encode(&nowPlaying)
+}
+
+// DeleteNowPlaying handles DELETE /api/now-playing, marking playback as stopped
+func (h *LyricsHandler) DeleteNowPlaying(w http.ResponseWriter, r *http.Request) {
+	if h.musicRepo.StopNowPlaying() {
+		log.Printf("Now playing cleared")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdatePlaybackState handles POST /api/now-playing/state with {"state": "playing" | "paused" | "stopped"}
+func (h *LyricsHandler) UpdatePlaybackState(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		State string `json:"state"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	switch req.State {
+	case models.PlaybackPlaying, models.PlaybackPaused, models.PlaybackStopped:
+	default:
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "State must be one of: playing, paused, stopped")
+		return
+	}
+
+	if !h.musicRepo.SetPlaybackState(req.State) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotPlaying, "No song is currently playing")
+		return
+	}
+	log.Printf("Playback state changed: %s", req.State)
+
+	w.Header().Set("ETag", formatETag(h.musicRepo.GetNowPlaying().Version))
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Playback state updated")
 }
 
-// GetPlayHistory handles GET /api/history
+// Heartbeat handles POST /api/now-playing/heartbeat with the client's playback
+// position, sent periodically while a track is loaded
+func (h *LyricsHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TrackID    string `json:"track_id"`
+		PositionMs int64  `json:"position_ms"`
+		DurationMs int64  `json:"duration_ms"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	if req.PositionMs < 0 || req.DurationMs < 0 || (req.DurationMs > 0 && req.PositionMs > req.DurationMs) {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "position_ms must be between 0 and duration_ms")
+		return
+	}
+
+	if !h.musicRepo.HasCurrentTrack() {
+		apierror.Write(w, http.StatusNotFound, apierror.NotPlaying, "No song is currently playing")
+		return
+	}
+
+	if !h.musicRepo.Heartbeat(req.TrackID, req.PositionMs, req.DurationMs) {
+		apierror.Write(w, http.StatusConflict, apierror.Conflict, "Heartbeat is for a different track than the one playing")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPlayHistory handles GET /api/history?source=&artist=&from=&to=&limit=&cursor=.
+// Pages are continued with the cursor in X-Next-Cursor; offset= still works
+// instead of a cursor. The number of matching items, from the cursor on, is
+// sent in the X-Total-Count header.
 func (h *LyricsHandler) GetPlayHistory(w http.ResponseWriter, r *http.Request) {
-	history := h.musicRepo.GetPlayHistory()
+	q, err := parseHistoryQuery(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, err.Error())
+		return
+	}
+	history, total, err := h.musicRepo.QueryPlayHistory(q.filter, q.offset, q.limit)
+	if err != nil {
+		log.Printf("Error querying play history: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to load play history")
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	pagination.SetNext(w, r, nextHistoryCursor(q, history, total))
 	json.NewEncoder(w).Encode(history)
 }
 
@@ -137,47 +384,76 @@ func (h *LyricsHandler) HandleChat(w http.ResponseWriter, r *http.Request) {
 	// Parse request body
 	var chatReq models.ChatRequest
 	if err := json.NewDecoder(r.Body).Decode(&chatReq); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
 		return
 	}
 
 	// Validate query
 	if chatReq.Query == "" {
-		http.Error(w, "Query cannot be empty", http.StatusBadRequest)
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Query cannot be empty")
+		return
+	}
+	lang, err := resolveLanguage(chatReq.Lang, chatReq.Query)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, err.Error())
 		return
 	}
 
 	// Process the chat request
-	response := h.processChatRequest(chatReq.Query)
+	response := h.processChatRequest(chatReq.Query, userIDFromRequest(r), lang, chatReq.Region, chatReq.Clean)
 
 	// Return the response
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// processChatRequest processes a chat request and returns a response
-func (h *LyricsHandler) processChatRequest(query string) models.ChatResponse {
-	// Check if the query is a song request first
+// processChatRequest processes a chat request and returns a response in lang.
+// region picks the helplines offered if the query indicates a crisis, and
+// clean asks for clean mode whatever the user's setting.
+func (h *LyricsHandler) processChatRequest(query, userID, lang, region string, clean bool) models.ChatResponse {
+	response := h.routeChatRequest(query, userID, lang, region, clean)
+	response.Language = lang
+	return response
+}
+
+// routeChatRequest answers a chat request with the handler for its kind of query
+func (h *LyricsHandler) routeChatRequest(query, userID, lang, region string, clean bool) models.ChatResponse {
+	// Support comes before anything else when the query indicates a crisis
+	if h.isCrisis(query) {
+		return h.handleCrisis(query, userID, lang, region, nil)
+	}
+
+	// Quiz answers and commands come before the other kinds of query
+	if response, ok := h.handleTriviaQuery(query, userID, lang, clean); ok {
+		return response
+	}
+
+	// Check if the query asks for music similar to an artist
+	if h.isArtistRadioQuery(query) {
+		return h.handleArtistRadioQuery(query, userID, lang)
+	}
+	
+	// Check if the query is a song request
 	if h.isSongRequestQuery(query) {
-		return h.handleSongRequest(query)
+		return h.handleSongRequest(query, lang)
 	}
 	
 	// Check if the query contains emotional content that needs mood-based recommendations
 	if h.containsEmotionalContent(query) {
-		return h.handleMoodBasedQuery(query)
+		return h.handleMoodBasedQuery(query, userID, lang, region, clean)
 	}
 	
 	// Check if the query is about lyrics/music
-	if h.isLyricsRelatedQuery(query) {
-		return h.handleLyricsQuery(query)
+	if h.isLyricsRelatedQuery(query, lang) {
+		return h.handleLyricsQuery(query, userID, lang, clean)
 	}
 
 	// Handle general queries
-	return h.handleGeneralQuery(query)
+	return h.handleGeneralQuery(query, userID, lang, clean)
 }
 
 // isLyricsRelatedQuery checks if a query is specifically about current song lyrics
-func (h *LyricsHandler) isLyricsRelatedQuery(query string) bool {
+func (h *LyricsHandler) isLyricsRelatedQuery(query, lang string) bool {
 	lowerQuery := strings.ToLower(query)
 	
 	// Specific patterns for current song/lyrics queries
@@ -199,11 +475,11 @@ func (h *LyricsHandler) isLyricsRelatedQuery(query string) bool {
 		}
 	}
 	
-	return false
+	return containsAny(lowerQuery, localizedLyricsPatterns[lang])
 }
 
 // isMusicRelatedQuery checks if a general query is related to music topics
-func (h *LyricsHandler) isMusicRelatedQuery(query string) bool {
+func (h *LyricsHandler) isMusicRelatedQuery(query, lang string) bool {
 	lowerQuery := strings.ToLower(query)
 	musicKeywords := []string{
 		"music", "song", "artist", "band", "album", "track",
@@ -223,55 +499,86 @@ func (h *LyricsHandler) isMusicRelatedQuery(query string) bool {
 			return true
 		}
 	}
-	return false
+	return containsAny(lowerQuery, localizedMusicKeywords[lang])
 }
 
 // handleLyricsQuery handles queries related to lyrics
-func (h *LyricsHandler) handleLyricsQuery(query string) models.ChatResponse {
-	// Check if we have a current song
-	if !h.musicRepo.IsPlaying() {
+func (h *LyricsHandler) handleLyricsQuery(query, userID, lang string, clean bool) models.ChatResponse {
+	// Check if we have a current song; paused songs can still be discussed
+	if !h.musicRepo.HasCurrentTrack() {
+		return models.ChatResponse{
+			Answer: h.cannedAnswer(lang, msgNoSongPlaying),
+		}
+	}
+
+	if h.isRestricted(userID) && h.musicRepo.GetNowPlaying().Explicit {
 		return models.ChatResponse{
-			Answer: "No song is currently playing. Please play a song in Spotify first, and I'll be able to help you understand its lyrics and meaning.",
+			Answer: h.cannedAnswer(lang, msgExplicitRestricted),
 		}
 	}
 
+	// Common questions are answered from stored analyses when possible,
+	// before the lyrics are even fetched
+	key, cacheable := h.analysisKey(query, userID, lang, clean)
+	var rejected []string
+	if cacheable {
+		analysis, down, ok := h.analyses.Get(key)
+		if ok {
+			return models.ChatResponse{Answer: analysis.Answer, AnalysisID: analysis.ID}
+		}
+		rejected = down
+	}
+
 	// Get song info
 	songInfo := h.musicRepo.GetCurrentSongInfo()
+	if !h.musicRepo.IsPlaying() {
+		songInfo += " (currently paused)"
+	}
 
 	// Try to get lyrics
 	lyrics, err := h.musicRepo.GetLyricsForCurrentSong()
 	if err != nil {
 		// If we can't get lyrics, provide what information we can
 		return models.ChatResponse{
-			Answer: fmt.Sprintf("I can see that you're currently playing \"%s\", but I couldn't fetch the lyrics: %v\n\nYou can still ask me general questions about this song or artist!", songInfo, err),
+			Answer: localize(lang, msgLyricsUnavailable, songInfo, err),
 		}
 	}
 
-	// Ask AI service to analyze the lyrics
-	answer, err := h.aiService.AnalyzeLyrics(query, lyrics, songInfo)
+	// Ask AI service to analyze the lyrics, differently than the stored
+	// answers if users rated them all down
+	var answer, responseID string
+	if len(rejected) > 0 {
+		answer, err = h.reanalyzeLyrics(withLanguageInstruction(query, lang), lyrics, songInfo, userID, clean, rejected)
+	} else {
+		answer, responseID, err = h.analyzeLyrics(withLanguageInstruction(query, lang), lyrics, songInfo, userID, clean)
+	}
 	if err != nil {
 		return models.ChatResponse{
 			Error: fmt.Sprintf("Error analyzing lyrics: %v", err),
 		}
 	}
 
-	return models.ChatResponse{
-		Answer: answer,
+	response := models.ChatResponse{
+		Answer:     answer,
+		ResponseID: responseID,
 	}
+	if cacheable {
+		response.AnalysisID = h.analyses.Put(key, answer).ID
+	}
+	return response
 }
 
 // handleGeneralQuery handles general queries not related to lyrics
-func (h *LyricsHandler) handleGeneralQuery(query string) models.ChatResponse {
+func (h *LyricsHandler) handleGeneralQuery(query, userID, lang string, clean bool) models.ChatResponse {
 	// Check if query is music-related
-	if !h.isMusicRelatedQuery(query) {
+	if !h.isMusicRelatedQuery(query, lang) {
 		return models.ChatResponse{
-			Answer: "I can only help with questions about music, songs, lyrics, and artists. Please ask me something related to music!",
+			Answer: h.cannedAnswer(lang, msgMusicOnly),
 		}
 	}
 
 	// For music-related general queries, provide a concise response
-	musicPrompt := fmt.Sprintf("Answer this music question in EXACTLY 2 short paragraphs. Keep it brief - maximum 4-5 sentences per paragraph: %s", query)
-	answer, err := h.aiService.GenerateResponse(musicPrompt)
+	answer, err := h.aiForRequest(userID, clean).GenerateResponse(h.generalMusicPrompt(query, lang))
 	if err != nil {
 		return models.ChatResponse{
 			Error: fmt.Sprintf("Error generating response: %v", err),
@@ -283,6 +590,12 @@ func (h *LyricsHandler) handleGeneralQuery(query string) models.ChatResponse {
 	}
 }
 
+// generalMusicPrompt builds the prompt for general music questions, answered in lang
+func (h *LyricsHandler) generalMusicPrompt(query, lang string) string {
+	prompt := fmt.Sprintf("Answer this music question in EXACTLY 2 short paragraphs. Keep it brief - maximum 4-5 sentences per paragraph: %s", query)
+	return withLanguageInstruction(prompt, lang)
+}
+
 // isSongRequestQuery checks if a query is a song request
 func (h *LyricsHandler) isSongRequestQuery(query string) bool {
 	lowerQuery := strings.ToLower(query)
@@ -359,7 +672,7 @@ func (h *LyricsHandler) extractSongRequest(query string) (string, string) {
 }
 
 // handleSongRequest handles song request queries
-func (h *LyricsHandler) handleSongRequest(query string) models.ChatResponse {
+func (h *LyricsHandler) handleSongRequest(query, lang string) models.ChatResponse {
 	songName, artist := h.extractSongRequest(query)
 	
 	// Create song query object
@@ -371,9 +684,9 @@ func (h *LyricsHandler) handleSongRequest(query string) models.ChatResponse {
 	// Create response message
 	var responseMsg string
 	if artist != "" {
-		responseMsg = fmt.Sprintf("I'm searching for \"%s\" by %s in your playlists. Let me show you what I found!", songName, artist)
+		responseMsg = localize(lang, msgSearchingByArtist, songName, artist)
 	} else {
-		responseMsg = fmt.Sprintf("I'm searching for \"%s\" in your playlists. Let me show you what I found!", songName)
+		responseMsg = localize(lang, msgSearching, songName)
 	}
 	
 	return models.ChatResponse{
@@ -419,21 +732,30 @@ func (h *LyricsHandler) containsEmotionalContent(query string) bool {
 }
 
 // handleMoodBasedQuery handles queries that contain emotional content
-func (h *LyricsHandler) handleMoodBasedQuery(query string) models.ChatResponse {
+func (h *LyricsHandler) handleMoodBasedQuery(query, userID, lang, region string, clean bool) models.ChatResponse {
 	// Detect mood from the query
-	moodAnalysis, err := h.moodService.DetectMood(query)
+	moodAnalysis, responseID, err := h.detectMood(query, userID)
+	if errors.Is(err, breaker.ErrOpen) {
+		// The AI provider is down; answer with canned suggestions instead of waiting on it
+		return h.degradedMoodResponse(query, userID, lang)
+	}
 	if err != nil {
 		log.Printf("Error detecting mood: %v", err)
-		return h.handleGeneralQuery(query) // Fallback to general query
+		return h.handleGeneralQuery(query, userID, lang, clean) // Fallback to general query
+	}
+	if moodAnalysis.Crisis && h.safety != nil {
+		return h.handleCrisis(query, userID, lang, region, moodAnalysis)
 	}
+	h.publish(events.MoodDetected, events.MoodDetectedPayload{UserID: userID, Analysis: *moodAnalysis})
 	
 	// Get user's playlists and liked songs
 	userTracks, err := h.getUserLibraryTracks()
 	if err != nil {
 		log.Printf("Error getting user tracks: %v", err)
 		return models.ChatResponse{
-			Answer: "I understand you're feeling " + moodAnalysis.PrimaryMood + ", but I'm having trouble accessing your music library right now. Please try again later.",
+			Answer:       localize(lang, msgLibraryUnavailable, localizedMood(lang, moodAnalysis.PrimaryMood)),
 			MoodAnalysis: moodAnalysis,
+			ResponseID:   responseID,
 		}
 	}
 	
@@ -444,26 +766,40 @@ func (h *LyricsHandler) handleMoodBasedQuery(query string) models.ChatResponse {
 	}
 	
 	// Get general song suggestions (10 songs)
-	generalSuggestions := h.getGeneralMoodSuggestions(moodAnalysis.PrimaryMood, 10)
+	generalSuggestions := h.blendMoodSuggestions(moodAnalysis, 10)
+	if h.isRestricted(userID) {
+		libraryMatches = filterExplicitRecommendations(libraryMatches)
+		generalSuggestions = filterExplicitRecommendations(generalSuggestions)
+	}
 	
 	// Create empathetic response
-	response := h.createEmpatheticResponse(moodAnalysis.PrimaryMood, query)
+	response := h.empatheticResponseFor(userID, moodAnalysis.PrimaryMood, lang)
 	
-	// Save mood history (using a dummy user ID for now - should get from auth context)
-	userID := "default_user" // TODO: Get actual user ID from request context
+	// Save mood history
 	var playedSongIDs []string
 	for _, match := range libraryMatches {
 		playedSongIDs = append(playedSongIDs, match.Track.ID)
 	}
 	h.moodService.SaveUserMoodHistory(userID, moodAnalysis.PrimaryMood, playedSongIDs)
 	
-	log.Printf("Mood detected: %s, Library matches: %d, General suggestions: %d", 
-		moodAnalysis.PrimaryMood, len(libraryMatches), len(generalSuggestions))
+	log.Printf("Mood detected, Library matches: %d, General suggestions: %d", 
+		len(libraryMatches), len(generalSuggestions))
+	
+	var recommended []models.UnifiedTrack
+	for _, recommendation := range append(libraryMatches, generalSuggestions...) {
+		recommended = append(recommended, recommendation.Track)
+	}
+	h.publish(events.RecommendationServed, events.RecommendationServedPayload{
+		UserID: userID,
+		Kind:   "mood_recommendation",
+		Tracks: recommended,
+	})
 	
 	return models.ChatResponse{
 		Answer:       response,
 		Type:         "mood_recommendation",
 		MoodAnalysis: moodAnalysis,
+		ResponseID:   responseID,
 		Recommendations: &models.MoodRecommendations{
 			FromLibrary: libraryMatches,
 			Suggested:   generalSuggestions,
@@ -471,6 +807,63 @@ func (h *LyricsHandler) handleMoodBasedQuery(query string) models.ChatResponse {
 	}
 }
 
+// degradedMoodResponse answers an emotional query without the AI provider,
+// guessing the mood from keywords and suggesting curated tracks for it
+func (h *LyricsHandler) degradedMoodResponse(query, userID, lang string) models.ChatResponse {
+	analysis := keywordMoodAnalysis(query)
+	suggestions := h.blendMoodSuggestions(analysis, 10)
+	if h.isRestricted(userID) {
+		suggestions = filterExplicitRecommendations(suggestions)
+	}
+
+	return models.ChatResponse{
+		Answer:       h.empatheticResponseFor(userID, analysis.PrimaryMood, lang),
+		Type:         "mood_recommendation",
+		MoodAnalysis: analysis,
+		Recommendations: &models.MoodRecommendations{
+			FromLibrary: []models.MoodBasedRecommendation{},
+			Suggested:   suggestions,
+		},
+	}
+}
+
+// keywordMoodAnalysis guesses the mood of a query from mood keywords, for
+// when the AI provider can't be asked
+func keywordMoodAnalysis(query string) *models.MoodAnalysis {
+	lowerQuery := strings.ToLower(query)
+	primaryMood, bestMatches, totalMatches := "calm", 0, 0
+	var matched []models.WeightedMood
+	for _, moodName := range []string{"sad", "happy", "angry", "lonely", "anxious", "nostalgic", "energetic", "calm"} {
+		matches := 0
+		for _, keyword := range mood.MoodKeywords[moodName] {
+			if strings.Contains(lowerQuery, keyword) {
+				matches++
+			}
+		}
+		if matches > bestMatches {
+			primaryMood, bestMatches = moodName, matches
+		}
+		if matches > 0 {
+			matched = append(matched, models.WeightedMood{Mood: moodName, Weight: float64(matches)})
+			totalMatches += matches
+		}
+	}
+
+	analysis := &models.MoodAnalysis{
+		PrimaryMood: primaryMood,
+		EmotionTags: []string{},
+	}
+	if len(matched) > 1 {
+		// Keywords from several moods make a mixed mood, weighted by how many matched
+		for i := range matched {
+			matched[i].Weight /= float64(totalMatches)
+		}
+		sort.SliceStable(matched, func(i, j int) bool { return matched[i].Weight > matched[j].Weight })
+		analysis.Moods = matched
+	}
+	return analysis
+}
+
 // getUserLibraryTracks gets tracks from user's playlists and liked songs
 func (h *LyricsHandler) getUserLibraryTracks() ([]models.UnifiedTrack, error) {
 	var allTracks []models.UnifiedTrack
@@ -487,193 +880,11 @@ func (h *LyricsHandler) getUserLibraryTracks() ([]models.UnifiedTrack, error) {
 
 // getGeneralMoodSuggestions returns general song suggestions for a mood
 func (h *LyricsHandler) getGeneralMoodSuggestions(mood string, limit int) []models.MoodBasedRecommendation {
-	// Predefined mood-based suggestions
-	moodSuggestions := map[string][]models.MoodBasedRecommendation{
-		"lonely": {
-			{
-				Track: models.UnifiedTrack{
-					ID:     "1mea3bSkSGXuIRvnydlB5b", // Real Spotify ID for "Somewhere I Belong"
-					Name:   "Somewhere I Belong",
-					Artist: "Linkin Park",
-					Album:  "Meteora",
-					Source: "spotify",
-				},
-				MoodScore:   0.95,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "4N3y2ChKKCG3zVCfyNiMQD", // Real Spotify ID for "Mad World"
-					Name:   "Mad World",
-					Artist: "Gary Jules",
-					Album:  "Trading Snakeoil for Wolftickets",
-					Source: "spotify",
-				},
-				MoodScore:   0.90,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "u9HBEOlMgOtK8yXGKKMhRx", // Real Spotify ID for "The Sound of Silence"
-					Name:   "The Sound of Silence",
-					Artist: "Disturbed",
-					Album:  "Immortalized",
-					Source: "spotify",
-				},
-				MoodScore:   0.88,
-			},
-		},
-		"sad": {
-			{
-				Track: models.UnifiedTrack{
-					ID:     "2DjPkzR89MSYPGaWhK8uKQ", // Real Spotify ID for "Hurt" by Johnny Cash
-					Name:   "Hurt",
-					Artist: "Johnny Cash",
-					Album:  "American IV: The Man Comes Around",
-					Source: "spotify",
-				},
-				MoodScore:   0.95,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "0SiQrCn2h2aKOEqz5Zxwow", // Real Spotify ID for "The Night We Met"
-					Name:   "The Night We Met",
-					Artist: "Lord Huron",
-					Album:  "Strange Trails",
-					Source: "spotify",
-				},
-				MoodScore:   0.90,
-			},
-		},
-		"happy": {
-			{
-				Track: models.UnifiedTrack{
-					ID:     "3BxnGCLFNdLKgVgVz6Vn5H", // Real Spotify ID for "Good Life"
-					Name:   "Good Life",
-					Artist: "OneRepublic",
-					Album:  "Waking Up",
-					Source: "spotify",
-				},
-				MoodScore:   0.95,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "05wIrZSwuaVWhcv5FfqeJ0", // Real Spotify ID for "Walking on Sunshine"
-					Name:   "Walking on Sunshine",
-					Artist: "Katrina and the Waves",
-					Album:  "Walking on Sunshine",
-					Source: "spotify",
-				},
-				MoodScore:   0.93,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "60nZcImufyMA1MKQY3dcCH", // Real Spotify ID for "Happy"
-					Name:   "Happy",
-					Artist: "Pharrell Williams",
-					Album:  "G I R L",
-					Source: "spotify",
-				},
-				MoodScore:   0.98,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "0BxE4FqsDD1Ot4YuBXwn8F", // Real Spotify ID for "Can't Stop the Feeling!"
-					Name:   "Can't Stop the Feeling!",
-					Artist: "Justin Timberlake",
-					Album:  "Trolls (Original Motion Picture Soundtrack)",
-					Source: "spotify",
-				},
-				MoodScore:   0.96,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "32OlwWuMpZ6b0aN2RZOeMS", // Real Spotify ID for "Uptown Funk"
-					Name:   "Uptown Funk",
-					Artist: "Mark Ronson ft. Bruno Mars",
-					Album:  "Uptown Special",
-					Source: "spotify",
-				},
-				MoodScore:   0.94,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "1WkMMavIMc4JZ8cfMmxHkI", // Real Spotify ID for "Good as Hell"
-					Name:   "Good as Hell",
-					Artist: "Lizzo",
-					Album:  "Cuz I Love You",
-					Source: "spotify",
-				},
-				MoodScore:   0.92,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "0CFuMybe6s77w6QQrJjW7d", // Real Spotify ID for "I'm Gonna Be (500 Miles)"
-					Name:   "I'm Gonna Be (500 Miles)",
-					Artist: "The Proclaimers",
-					Album:  "Sunshine on Leith",
-					Source: "spotify",
-				},
-				MoodScore:   0.90,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "5T8EDUDqKcs6OSOwEsfqG7", // Real Spotify ID for "Don't Stop Me Now"
-					Name:   "Don't Stop Me Now",
-					Artist: "Queen",
-					Album:  "Jazz",
-					Source: "spotify",
-				},
-				MoodScore:   0.88,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "2RlgNHKcydI9sayD2Df2xp", // Real Spotify ID for "Mr. Blue Sky"
-					Name:   "Mr. Blue Sky",
-					Artist: "Electric Light Orchestra",
-					Album:  "Out of the Blue",
-					Source: "spotify",
-				},
-				MoodScore:   0.86,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "3PPogGhAUjr4FLGzEFGzJI", // Real Spotify ID for "Best Day of My Life"
-					Name:   "Best Day of My Life",
-					Artist: "American Authors",
-					Album:  "Oh, What a Life",
-					Source: "spotify",
-				},
-				MoodScore:   0.84,
-			},
-		},
-		"angry": {
-			{
-				Track: models.UnifiedTrack{
-					ID:     "2OzEKCmOoWhyuB8nHi8xhv", // Real Spotify ID for "Break Stuff"
-					Name:   "Break Stuff",
-					Artist: "Limp Bizkit",
-					Album:  "Significant Other",
-					Source: "spotify",
-				},
-				MoodScore:   0.95,
-			},
-			{
-				Track: models.UnifiedTrack{
-					ID:     "0yp7ORA8XPNO4kvNj5EYdx", // Real Spotify ID for "Bodies"
-					Name:   "Bodies",
-					Artist: "Drowning Pool",
-					Album:  "Sinner",
-					Source: "spotify",
-				},
-				MoodScore:   0.92,
-			},
-		},
-	}
-	
 	// Get suggestions for the mood
-	suggestions, exists := moodSuggestions[mood]
+	suggestions, exists := h.moodCatalog.GetSuggestions(mood)
 	if !exists {
 		// Default suggestions if mood not found
-		suggestions = moodSuggestions["sad"]
+		suggestions, _ = h.moodCatalog.GetSuggestions("sad")
 	}
 	
 	// Return up to limit suggestions
@@ -684,23 +895,111 @@ func (h *LyricsHandler) getGeneralMoodSuggestions(mood string, limit int) []mode
 	return suggestions
 }
 
-// createEmpatheticResponse creates an empathetic response based on mood
-func (h *LyricsHandler) createEmpatheticResponse(mood, originalQuery string) string {
-	responses := map[string]string{
-		"lonely":    "I hear you're feeling disconnected right now. Sometimes music can be a companion when we feel alone. Here are some songs that explore similar feelings and might resonate with you:",
-		"sad":       "I understand you're going through a difficult time. Music has a way of expressing what we can't always put into words. These songs might help you process these feelings:",
-		"happy":     "It's wonderful that you're feeling so positive! Let's keep that energy going with some uplifting tracks that match your mood:",
-		"angry":     "I can sense your frustration. Sometimes we need music that matches our intensity and helps us release these feelings. Here are some powerful tracks for you:",
-		"anxious":   "I understand you're feeling overwhelmed. These songs might help you find some calm or at least know you're not alone in feeling this way:",
-		"nostalgic": "Ah, feeling nostalgic... Music has a unique way of taking us back. Here are some songs that capture that bittersweet feeling of remembering:",
-		"energetic": "You're full of energy! Let's channel that into some high-powered tracks that'll keep you motivated:",
-		"calm":      "Finding your peace... Here are some tranquil songs to help maintain that serene state of mind:",
+// blendMoodSuggestions returns general song suggestions for every detected mood,
+// giving each mood a share of the limit proportional to its weight and
+// interleaving them so the mix shows from the first suggestions
+func (h *LyricsHandler) blendMoodSuggestions(analysis *models.MoodAnalysis, limit int) []models.MoodBasedRecommendation {
+	moods := analysis.WeightedMoods()
+	if len(moods) == 1 {
+		return h.getGeneralMoodSuggestions(analysis.PrimaryMood, limit)
+	}
+
+	// Split the limit by weight, handing leftover slots to the strongest moods
+	shares := make([]int, len(moods))
+	allocated := 0
+	for i, weighted := range moods {
+		shares[i] = int(weighted.Weight * float64(limit))
+		allocated += shares[i]
+	}
+	for i := 0; allocated < limit; i = (i + 1) % len(moods) {
+		shares[i]++
+		allocated++
+	}
+
+	candidates := make([][]models.MoodBasedRecommendation, len(moods))
+	for i, weighted := range moods {
+		candidates[i], _ = h.moodCatalog.GetSuggestions(weighted.Mood)
+	}
+
+	var blended []models.MoodBasedRecommendation
+	seen := make(map[string]bool)
+	next := make([]int, len(moods))
+	take := func(i int) bool {
+		for next[i] < len(candidates[i]) {
+			suggestion := candidates[i][next[i]]
+			next[i]++
+			if !seen[suggestion.Track.ID] {
+				seen[suggestion.Track.ID] = true
+				blended = append(blended, suggestion)
+				return true
+			}
+		}
+		return false
 	}
-	
-	response, exists := responses[mood]
-	if !exists {
-		response = fmt.Sprintf("I can sense you're feeling %s. Music has a way of connecting with our emotions. Here are some songs that might resonate with how you're feeling:", mood)
+
+	for added := true; added && len(blended) < limit; {
+		added = false
+		for i := range moods {
+			if shares[i] > 0 && len(blended) < limit && take(i) {
+				shares[i]--
+				added = true
+			}
+		}
 	}
-	
-	return response
+	// A mood with too few suggestions leaves its slots to the others
+	for i := range moods {
+		for len(blended) < limit {
+			if !take(i) {
+				break
+			}
+		}
+	}
+
+	if len(blended) == 0 {
+		return h.getGeneralMoodSuggestions(analysis.PrimaryMood, limit)
+	}
+	return blended
+}
+
+// MoodCatalog returns the curated mood suggestion catalog used by the handler
+func (h *LyricsHandler) MoodCatalog() *repositories.MoodCatalog {
+	return h.moodCatalog
+}
+
+// empatheticResponseFor creates the empathetic response for a user, toned down in restricted mode
+func (h *LyricsHandler) empatheticResponseFor(userID, mood, lang string) string {
+	if h.isRestricted(userID) {
+		// Prefer the toned down English response over an untranslated intense one
+		for _, candidate := range []string{lang, defaultLanguage} {
+			if response, ok := restrictedEmpatheticResponses[candidate][mood]; ok {
+				return response
+			}
+		}
+	}
+	return h.createEmpatheticResponse(mood, lang)
+}
+
+// filterExplicitRecommendations removes recommendations of explicit tracks
+func filterExplicitRecommendations(recommendations []models.MoodBasedRecommendation) []models.MoodBasedRecommendation {
+	filtered := make([]models.MoodBasedRecommendation, 0, len(recommendations))
+	for _, recommendation := range recommendations {
+		if !recommendation.Track.Explicit {
+			filtered = append(filtered, recommendation)
+		}
+	}
+	return filtered
+}
+
+// createEmpatheticResponse creates an empathetic response based on mood, in lang
+func (h *LyricsHandler) createEmpatheticResponse(mood, lang string) string {
+	if response, ok := h.customEmpatheticResponse(mood, lang, false); ok {
+		return response
+	}
+	if response, ok := empatheticResponses[lang][mood]; ok {
+		return response
+	}
+	if response, ok := h.customEmpatheticResponse(mood, lang, true); ok {
+		return response
+	}
+	return localize(lang, msgMoodDefault, localizedMood(lang, mood))
 }
\ No newline at end of file
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/events"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+const (
+	maxReportDetailsLength = 1000
+	defaultAdminListLimit  = 50
+	maxAdminListLimit      = 200
+)
+
+// ReportMessage handles POST /api/messages/{id}/report, reporting a message
+// for moderators as the caller with {"reason": ..., "details": ...}. Each
+// user reports a message once.
+func (h *ChatHandler) ReportMessage(w http.ResponseWriter, r *http.Request) {
+	id, ok := messageID(w, r)
+	if !ok {
+		return
+	}
+	var req models.ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+	if !validReportReason(req.Reason) {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "reason must be one of "+strings.Join(models.ReportReasons, ", "))
+		return
+	}
+	req.Details = strings.TrimSpace(req.Details)
+	if len(req.Details) > maxReportDetailsLength {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, fmt.Sprintf("details must be at most %d bytes", maxReportDetailsLength))
+		return
+	}
+
+	var visible bool
+	err := h.queryRow(selectMessageVisible, id).Scan(&visible)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !visible) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Message not found")
+		return
+	}
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	report := models.MessageReport{MessageID: id, ReporterID: userIDFromRequest(r), Reason: req.Reason, Details: req.Details}
+	err = h.queryRow(insertReport, id, report.ReporterID, report.Reason, report.Details, models.ReportOpen).Scan(&report.ID, &report.Status, &report.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		apierror.Write(w, http.StatusConflict, apierror.Conflict, "You already reported this message")
+		return
+	}
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
+
+// ListReports handles GET /api/admin/reports?status=&limit=, listing reports
+// with the reported messages, oldest first. The status defaults to open;
+// "all" lists every report.
+func (h *ChatHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = models.ReportOpen
+	case "all":
+		status = ""
+	case models.ReportOpen, models.ReportResolved, models.ReportDismissed:
+	default:
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid status (expected open, resolved, dismissed or all)")
+		return
+	}
+	limit, ok := adminListLimit(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := h.query(selectReports, status, limit)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	reports := []models.MessageReport{}
+	for rows.Next() {
+		var report models.MessageReport
+		var msg models.Message
+		err := rows.Scan(&report.ID, &report.MessageID, &report.ReporterID, &report.Reason, &report.Details, &report.Status, &report.CreatedAt,
+			&msg.UserEmail, &msg.Username, &msg.Text, &msg.MessageType, &msg.CreatedAt)
+		if err != nil {
+			apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+			return
+		}
+		msg.ID = report.MessageID
+		report.Message = &msg
+		reports = append(reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// DismissReport handles POST /api/admin/reports/{id}/dismiss, closing an open
+// report without acting on the message
+func (h *ChatHandler) DismissReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid report ID")
+		return
+	}
+
+	result, err := h.exec(updateReportStatus, id, models.ReportDismissed, models.ReportOpen)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Open report not found")
+		return
+	}
+	h.audit(r, "dismiss_report", "report", strconv.FormatInt(id, 10), "")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HideMessage handles POST /api/admin/messages/{id}/hide, taking a message
+// out of the chat and resolving its open reports. Chat sockets are told it
+// was removed.
+func (h *ChatHandler) HideMessage(w http.ResponseWriter, r *http.Request) {
+	id, ok := messageID(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.exec(hideMessage, id)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		if h.messageExists(w, id) {
+			apierror.Write(w, http.StatusConflict, apierror.Conflict, "Message is already hidden")
+		}
+		return
+	}
+	if _, err := h.exec(updateMessageReports, id, models.ReportResolved, models.ReportOpen); err != nil {
+		log.Printf("Error resolving the reports of message %d: %v", id, err)
+	}
+
+	h.audit(r, "hide_message", "message", strconv.FormatInt(id, 10), "")
+	if h.eventBus != nil {
+		h.eventBus.Publish(events.MessageRemoved, models.Message{ID: id})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnhideMessage handles POST /api/admin/messages/{id}/unhide, putting a
+// hidden message back. Its reports stay resolved.
+func (h *ChatHandler) UnhideMessage(w http.ResponseWriter, r *http.Request) {
+	id, ok := messageID(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.exec(unhideMessage, id)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		if h.messageExists(w, id) {
+			apierror.Write(w, http.StatusConflict, apierror.Conflict, "Message isn't hidden")
+		}
+		return
+	}
+	h.audit(r, "unhide_message", "message", strconv.FormatInt(id, 10), "")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListBans handles GET /api/admin/bans, listing the bans that haven't
+// expired, soonest to expire first
+func (h *ChatHandler) ListBans(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.query(selectBans)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	bans := []models.UserBan{}
+	for rows.Next() {
+		var ban models.UserBan
+		if err := rows.Scan(&ban.UserID, &ban.Reason, &ban.BannedBy, &ban.ExpiresAt, &ban.CreatedAt); err != nil {
+			apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+			return
+		}
+		bans = append(bans, ban)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bans)
+}
+
+// BanUser handles POST /api/admin/bans, keeping a user from posting to global
+// chat for a duration. Banning a banned user replaces their ban.
+func (h *ChatHandler) BanUser(w http.ResponseWriter, r *http.Request) {
+	var req models.BanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+	userID := strings.ToLower(strings.TrimSpace(req.UserID))
+	if userID == "" || len(userID) > maxBlockedUserLength {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "user_id is required and must be at most 255 bytes")
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "duration must be a positive duration, e.g. 24h")
+		return
+	}
+
+	ban := models.UserBan{UserID: userID, Reason: strings.TrimSpace(req.Reason), BannedBy: userIDFromRequest(r)}
+	err = h.queryRow(upsertBan, ban.UserID, ban.Reason, ban.BannedBy, duration.Seconds()).Scan(&ban.ExpiresAt, &ban.CreatedAt)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	h.audit(r, "ban_user", "user", ban.UserID, strings.TrimSpace(fmt.Sprintf("for %s %s", duration, ban.Reason)))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ban)
+}
+
+// UnbanUser handles DELETE /api/admin/bans/{user}, lifting a ban early
+func (h *ChatHandler) UnbanUser(w http.ResponseWriter, r *http.Request) {
+	userID := strings.ToLower(strings.TrimSpace(mux.Vars(r)["user"]))
+	result, err := h.exec(deleteBan, userID)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "User is not banned")
+		return
+	}
+	h.audit(r, "unban_user", "user", userID, "")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAudit handles GET /api/admin/audit?limit=, listing moderator actions,
+// newest first
+func (h *ChatHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	limit, ok := adminListLimit(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := h.query(selectAudit, limit)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		if err := rows.Scan(&entry.ID, &entry.Actor, &entry.Action, &entry.TargetType, &entry.TargetID, &entry.Details, &entry.CreatedAt); err != nil {
+			apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+			return
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// audit records a moderator action taken by the caller. Failures are logged;
+// the action itself has already been taken.
+func (h *ChatHandler) audit(r *http.Request, action, targetType, targetID, details string) {
+	_, err := h.exec(insertAudit, userIDFromRequest(r), action, targetType, targetID, details)
+	if err != nil {
+		log.Printf("Error recording %s of %s %s in the audit log: %v", action, targetType, targetID, err)
+	}
+}
+
+// bannedUntil returns when the ban on any of the given user identities
+// expires, or false if none of them is banned
+func (h *ChatHandler) bannedUntil(userIDs ...string) (time.Time, bool, error) {
+	var ids []string
+	for _, userID := range userIDs {
+		if userID = strings.ToLower(strings.TrimSpace(userID)); userID != "" {
+			ids = append(ids, userID)
+		}
+	}
+	if len(ids) == 0 {
+		return time.Time{}, false, nil
+	}
+
+	var expiresAt sql.NullTime
+	err := h.queryRow(selectBannedUntil, pq.Array(ids)).Scan(&expiresAt)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return expiresAt.Time, expiresAt.Valid, nil
+}
+
+// validReportReason reports whether reason is one of models.ReportReasons
+func validReportReason(reason string) bool {
+	for _, known := range models.ReportReasons {
+		if reason == known {
+			return true
+		}
+	}
+	return false
+}
+
+// adminListLimit parses the limit query parameter of an admin list, writing
+// 400 and returning false if it's invalid
+func adminListLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam == "" {
+		return defaultAdminListLimit, true
+	}
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil || limit <= 0 {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid limit")
+		return 0, false
+	}
+	if limit > maxAdminListLimit {
+		limit = maxAdminListLimit
+	}
+	return limit, true
+}
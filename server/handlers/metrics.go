@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+)
+
+// MetricsHandler reports the instance's operational metrics
+type MetricsHandler struct {
+	dbStats func() sql.DBStats
+}
+
+// NewMetricsHandler creates a new metrics handler reading the database pool's
+// stats from dbStats, usually the pool's Stats method
+func NewMetricsHandler(dbStats func() sql.DBStats) *MetricsHandler {
+	return &MetricsHandler{dbStats: dbStats}
+}
+
+// GetMetrics handles GET /api/admin/metrics
+func (h *MetricsHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	stats := h.dbStats()
+	metrics := models.Metrics{
+		Database: models.DatabasePoolStats{
+			MaxOpen:           stats.MaxOpenConnections,
+			Open:              stats.OpenConnections,
+			InUse:             stats.InUse,
+			Idle:              stats.Idle,
+			WaitCount:         stats.WaitCount,
+			WaitMs:            stats.WaitDuration.Milliseconds(),
+			MaxIdleClosed:     stats.MaxIdleClosed,
+			MaxLifetimeClosed: stats.MaxLifetimeClosed,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metrics)
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/events"
+	"backend/services/moderation"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// SetModeration sets the service screening posted messages. Without it every
+// message is posted and the moderation endpoints answer 404.
+func (h *ChatHandler) SetModeration(moderation moderation.Service) {
+	h.moderation = moderation
+}
+
+// moderate classifies a message posted by userID. A rejected message's verdict
+// is recorded here; a flagged one's is recorded once the message has an ID.
+// Messages from the server itself aren't moderated.
+func (h *ChatHandler) moderate(msg models.Message, userID string) models.ModerationVerdict {
+	if h.moderation == nil || userID == "" || msg.Text == "" {
+		return models.ModerationVerdict{Action: models.ModerationAllow}
+	}
+
+	verdict := h.moderation.Moderate(msg.Text)
+	verdict.UserEmail = msg.UserEmail
+	verdict.Username = msg.Username
+	if verdict.Action == models.ModerationReject {
+		if _, err := h.moderation.Record(verdict); err != nil {
+			log.Printf("Error recording the moderation verdict on a rejected message: %v", err)
+		}
+	}
+	return verdict
+}
+
+// ModerationQueue handles GET /api/admin/moderation?status=&limit=, listing
+// moderation verdicts oldest first. The status defaults to pending, the
+// flagged messages awaiting review; "all" lists every verdict.
+func (h *ChatHandler) ModerationQueue(w http.ResponseWriter, r *http.Request) {
+	if h.moderation == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Moderation is not enabled")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = models.ModerationPending
+	case "all":
+		status = ""
+	case models.ModerationPending, models.ModerationApproved, models.ModerationRemoved, models.ModerationRejected:
+	default:
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid status (expected pending, approved, removed, rejected or all)")
+		return
+	}
+
+	limit, ok := adminListLimit(w, r)
+	if !ok {
+		return
+	}
+
+	verdicts, err := h.moderation.Queue(status, limit)
+	if err != nil {
+		log.Printf("Error listing moderation verdicts: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to list the moderation queue")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(verdicts)
+}
+
+// ReviewModeration handles POST /api/admin/moderation/{id}/review, approving
+// a flagged message or removing it from the chat. The caller's user ID is
+// recorded as the moderator.
+func (h *ChatHandler) ReviewModeration(w http.ResponseWriter, r *http.Request) {
+	if h.moderation == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Moderation is not enabled")
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid verdict ID")
+		return
+	}
+	var req models.ModerationReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+	if req.Decision != moderation.DecisionApprove && req.Decision != moderation.DecisionRemove {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "decision must be approve or remove")
+		return
+	}
+
+	verdict, err := h.moderation.Get(id)
+	if errors.Is(err, moderation.ErrNotFound) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Verdict not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading moderation verdict %d: %v", id, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to load the verdict")
+		return
+	}
+	if verdict.Status != models.ModerationPending {
+		apierror.Write(w, http.StatusConflict, apierror.Conflict, "Verdict was already reviewed")
+		return
+	}
+
+	if req.Decision == moderation.DecisionRemove && verdict.MessageID != nil {
+		if err := h.removeMessage(*verdict.MessageID); err != nil {
+			log.Printf("Error removing message %d: %v", *verdict.MessageID, err)
+			apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to remove the message")
+			return
+		}
+	}
+
+	verdict, err = h.moderation.Review(id, req.Decision, userIDFromRequest(r))
+	if errors.Is(err, moderation.ErrReviewed) {
+		apierror.Write(w, http.StatusConflict, apierror.Conflict, "Verdict was already reviewed")
+		return
+	}
+	if err != nil {
+		log.Printf("Error reviewing moderation verdict %d: %v", id, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to save the review")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(verdict)
+}
+
+// removeMessage deletes a message and publishes its removal. Its reactions
+// go with it; its replies stay, as top-level messages.
+func (h *ChatHandler) removeMessage(id int64) error {
+	if _, err := h.exec(deleteMessage, id); err != nil {
+		return err
+	}
+	if h.eventBus != nil {
+		h.eventBus.Publish(events.MessageRemoved, models.Message{ID: id})
+	}
+	return nil
+}
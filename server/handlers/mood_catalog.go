@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"backend/repositories"
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/mood"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// maxMatchReasonLength caps curated match reasons, in bytes
+const maxMatchReasonLength = 500
+
+// MoodCatalogHandler lets curators maintain the mood suggestions shown to
+// every user without redeploying
+type MoodCatalogHandler struct {
+	catalog *repositories.MoodCatalog
+}
+
+// NewMoodCatalogHandler creates a new mood catalog handler
+func NewMoodCatalogHandler(catalog *repositories.MoodCatalog) *MoodCatalogHandler {
+	return &MoodCatalogHandler{catalog: catalog}
+}
+
+// ListMoodSuggestions handles GET /api/admin/mood-suggestions?mood=
+func (h *MoodCatalogHandler) ListMoodSuggestions(w http.ResponseWriter, r *http.Request) {
+	moodFilter := strings.ToLower(r.URL.Query().Get("mood"))
+	if _, ok := mood.MoodKeywords[moodFilter]; moodFilter != "" && !ok {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid mood")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.MoodSuggestionsResponse{Suggestions: h.catalog.List(moodFilter)})
+}
+
+// CreateMoodSuggestion handles POST /api/admin/mood-suggestions
+func (h *MoodCatalogHandler) CreateMoodSuggestion(w http.ResponseWriter, r *http.Request) {
+	suggestion, ok := decodeMoodSuggestion(w, r)
+	if !ok {
+		return
+	}
+
+	added, err := h.catalog.Add(suggestion)
+	if err != nil {
+		log.Printf("Error adding mood suggestion: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to add mood suggestion")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(added)
+}
+
+// UpdateMoodSuggestion handles PUT /api/admin/mood-suggestions/{id}
+func (h *MoodCatalogHandler) UpdateMoodSuggestion(w http.ResponseWriter, r *http.Request) {
+	id, ok := moodSuggestionID(w, r)
+	if !ok {
+		return
+	}
+	suggestion, ok := decodeMoodSuggestion(w, r)
+	if !ok {
+		return
+	}
+
+	suggestion.ID = id
+	updated, err := h.catalog.Update(suggestion)
+	if !h.handleError(w, err, "updating mood suggestion") {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// DeleteMoodSuggestion handles DELETE /api/admin/mood-suggestions/{id}
+func (h *MoodCatalogHandler) DeleteMoodSuggestion(w http.ResponseWriter, r *http.Request) {
+	id, ok := moodSuggestionID(w, r)
+	if !ok {
+		return
+	}
+	if !h.handleError(w, h.catalog.Delete(id), "deleting mood suggestion") {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleError writes the response for a catalog error and returns false, or
+// returns true if there was no error
+func (h *MoodCatalogHandler) handleError(w http.ResponseWriter, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, repositories.ErrMoodSuggestionNotFound):
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Mood suggestion not found")
+	default:
+		log.Printf("Error %s: %v", action, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to change mood suggestion")
+	}
+	return false
+}
+
+// moodSuggestionID parses the {id} path variable, writing an error response if it is invalid
+func moodSuggestionID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id <= 0 {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid mood suggestion ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeMoodSuggestion reads and validates a suggestion from the request
+// body, writing an error response if it is invalid. Tracks default to Spotify.
+func decodeMoodSuggestion(w http.ResponseWriter, r *http.Request) (models.MoodSuggestion, bool) {
+	var suggestion models.MoodSuggestion
+	if err := json.NewDecoder(r.Body).Decode(&suggestion); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return suggestion, false
+	}
+
+	suggestion.Mood = strings.ToLower(strings.TrimSpace(suggestion.Mood))
+	if suggestion.Track.Source == "" {
+		suggestion.Track.Source = "spotify"
+	}
+
+	var problem string
+	switch {
+	case suggestion.Mood == "":
+		problem = "Mood is required"
+	case mood.MoodKeywords[suggestion.Mood] == nil:
+		problem = fmt.Sprintf("Unknown mood %q", suggestion.Mood)
+	case suggestion.Track.ID == "" || suggestion.Track.Name == "" || suggestion.Track.Artist == "":
+		problem = "Track id, name and artist are required"
+	case suggestion.MoodScore < 0 || suggestion.MoodScore > 1:
+		problem = "mood_score must be between 0 and 1"
+	case len(suggestion.MatchReason) > maxMatchReasonLength:
+		problem = fmt.Sprintf("match_reason must be at most %d characters", maxMatchReasonLength)
+	}
+	if problem != "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, problem)
+		return suggestion, false
+	}
+	return suggestion, true
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/mood"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxJournalNoteLength caps journal notes, in characters
+const maxJournalNoteLength = 2000
+
+// AddMoodJournalNote handles POST /api/mood/journal.
+// It attaches the caller's note to one of their detected mood entries,
+// replacing any earlier note on it. Callers must send X-User-ID.
+func (h *LyricsHandler) AddMoodJournalNote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := explicitUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.MoodJournalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Note cannot be empty")
+		return
+	}
+	if utf8.RuneCountInString(note) > maxJournalNoteLength {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, fmt.Sprintf("Note must be at most %d characters", maxJournalNoteLength))
+		return
+	}
+
+	entry, err := h.moodService.AddMoodNote(userID, strings.TrimSpace(req.Entry), note)
+	if errors.Is(err, mood.ErrMoodEntryNotFound) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Mood entry not found")
+		return
+	}
+	if errors.Is(err, mood.ErrInvalidUserID) {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid X-User-ID")
+		return
+	}
+	if err != nil {
+		log.Printf("Error saving mood journal note: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to save note")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.MoodJournalResponse{Entry: entry, Note: note})
+}
+
+// GetMoodInsights handles GET /api/mood/insights.
+// It returns the caller's mood history with their journal notes and how
+// often each mood was detected. Callers must send X-User-ID.
+func (h *LyricsHandler) GetMoodInsights(w http.ResponseWriter, r *http.Request) {
+	userID, ok := explicitUserID(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := h.moodService.GetUserMoodHistory(userID)
+	if errors.Is(err, mood.ErrInvalidUserID) {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid X-User-ID")
+		return
+	}
+	if err != nil {
+		log.Printf("Error reading mood history: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to read mood history")
+		return
+	}
+
+	insights := models.MoodInsights{
+		Entries:    make([]models.MoodEntry, 0, len(history)),
+		MoodCounts: make(map[string]int),
+	}
+	for _, entry := range history {
+		insights.Entries = append(insights.Entries, models.MoodEntry{
+			Timestamp:   entry.Timestamp,
+			Mood:        entry.DetectedMood,
+			PlayedSongs: entry.PlayedSongs,
+			Note:        entry.Note,
+		})
+		insights.MoodCounts[entry.DetectedMood]++
+		if insights.MoodCounts[entry.DetectedMood] >= insights.MoodCounts[insights.TopMood] {
+			insights.TopMood = entry.DetectedMood
+		}
+		if entry.Note != "" {
+			insights.Notes++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(insights)
+}
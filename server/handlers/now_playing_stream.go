@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"backend/server/models"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// sseStreams fans out now-playing events to Server-Sent Events clients. A
+// client whose buffer fills up is dropped, like slow WebSocket clients.
+type sseStreams struct {
+	mutex   sync.Mutex
+	clients map[chan sseEvent]struct{}
+}
+
+// sseEvent is one event written to a stream
+type sseEvent struct {
+	name string
+	data []byte
+}
+
+// add registers a client with a buffer of size events
+func (s *sseStreams) add(size int) chan sseEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.clients == nil {
+		s.clients = make(map[chan sseEvent]struct{})
+	}
+	client := make(chan sseEvent, size)
+	s.clients[client] = struct{}{}
+	return client
+}
+
+// remove unregisters a client, closing its channel if it is still open
+func (s *sseStreams) remove(client chan sseEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.clients[client]; ok {
+		delete(s.clients, client)
+		close(client)
+	}
+}
+
+// broadcast queues an event for every client
+func (s *sseStreams) broadcast(event sseEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for client := range s.clients {
+		select {
+		case client <- event:
+		default:
+			delete(s.clients, client)
+			close(client)
+		}
+	}
+}
+
+// NowPlayingStream handles GET /api/now-playing/stream, a Server-Sent Events
+// alternative to the now-playing WebSocket for clients that only listen. The
+// current track is sent on connect, followed by every track change, with a
+// comment at each ping interval to keep proxies from closing the stream.
+func (h *RealtimeHandler) NowPlayingStream(w http.ResponseWriter, r *http.Request) {
+	controller := http.NewResponseController(w)
+	// The stream outlives the server's write timeout, which is meant for ordinary responses
+	controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Keep nginx from buffering events
+	w.WriteHeader(http.StatusOK)
+
+	client := h.streams.add(h.config.SendBuffer)
+	defer h.streams.remove(client)
+
+	if h.musicRepo.HasCurrentTrack() {
+		writeSSE(w, sseEvent{models.RealtimeNowPlaying, realtimeEvent(models.RealtimeNowPlaying, h.musicRepo.GetNowPlaying())})
+	}
+	controller.Flush()
+
+	keepAlive := time.NewTicker(h.config.PingInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, ok := <-client:
+			if !ok {
+				return // Too slow to keep up
+			}
+			if err := writeSSE(w, event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeSSE writes an event in the text/event-stream format. Event data is
+// single-line JSON, so it needs only one data field.
+func writeSSE(w http.ResponseWriter, event sseEvent) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, event.data)
+	return err
+}
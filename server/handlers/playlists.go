@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/spotify"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	// maxPlaylistTracks is how many tracks Spotify accepts when populating a playlist at once
+	maxPlaylistTracks = 100
+	// maxPlaylistNameLength caps custom playlist names, in bytes
+	maxPlaylistNameLength = 100
+	// defaultAssistantName credits playlists when the deployment has no branding
+	defaultAssistantName = "LinkinSync"
+)
+
+// CreatePlaylist handles POST /api/playlists.
+// It saves the Spotify tracks of mood recommendations, library tracks first,
+// as a private playlist on the user's account, using the user's access token
+// from X-Spotify-Token. The answer is a chat response with the playlist URL.
+func (h *LyricsHandler) CreatePlaylist(w http.ResponseWriter, r *http.Request) {
+	var req models.PlaylistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	userToken := strings.TrimSpace(r.Header.Get("X-Spotify-Token"))
+	if userToken == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "X-Spotify-Token is required to create a playlist")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	mood := strings.ToLower(strings.TrimSpace(req.Mood))
+	if name == "" && mood == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "A name or mood is required")
+		return
+	}
+	if len(name) > maxPlaylistNameLength {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, fmt.Sprintf("Name must be at most %d characters", maxPlaylistNameLength))
+		return
+	}
+	lang, err := resolveLanguage(req.Lang, "")
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, err.Error())
+		return
+	}
+
+	userID := userIDFromRequest(r)
+	trackIDs := h.playlistTrackIDs(req.Recommendations, userID)
+	if len(trackIDs) == 0 {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "The recommendations hold no Spotify tracks")
+		return
+	}
+	if len(trackIDs) > maxPlaylistTracks {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, fmt.Sprintf("At most %d tracks can be saved to a playlist", maxPlaylistTracks))
+		return
+	}
+
+	assistant := h.customization.AssistantName
+	if assistant == "" {
+		assistant = defaultAssistantName
+	}
+	description := ""
+	if mood != "" {
+		if name == "" {
+			name = localize(lang, msgPlaylistName, localizedMood(lang, mood))
+		}
+		description = localize(lang, msgPlaylistDesc, localizedMood(lang, mood), assistant)
+	}
+
+	playlist, err := h.spotifyService.CreatePlaylist(userToken, name, description, trackIDs)
+	if errors.Is(err, spotify.ErrUserTokenRejected) {
+		apierror.Write(w, http.StatusForbidden, apierror.Forbidden, "Spotify rejected the access token")
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating Spotify playlist for %s: %v", userID, err)
+		apierror.Write(w, http.StatusBadGateway, apierror.UpstreamUnavailable, "Failed to create the playlist")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.ChatResponse{
+		Answer:   localize(lang, msgPlaylist, playlist.TrackCount, playlist.Name, playlist.URL),
+		Type:     "playlist",
+		Language: lang,
+		Playlist: playlist,
+	})
+}
+
+// playlistTrackIDs returns the IDs of the recommended Spotify tracks without
+// duplicates, leaving out explicit tracks for restricted users
+func (h *LyricsHandler) playlistTrackIDs(recommendations models.MoodRecommendations, userID string) []string {
+	var tracks []models.UnifiedTrack
+	for _, group := range [][]models.MoodBasedRecommendation{recommendations.FromLibrary, recommendations.Suggested} {
+		for _, recommendation := range group {
+			if recommendation.Track.Source == "spotify" && recommendation.Track.ID != "" {
+				tracks = append(tracks, recommendation.Track)
+			}
+		}
+	}
+	if h.isRestricted(userID) {
+		tracks = h.restrictions.FilterTracks(tracks)
+	}
+
+	var trackIDs []string
+	seen := make(map[string]bool)
+	for _, track := range tracks {
+		if !seen[track.ID] {
+			seen[track.ID] = true
+			trackIDs = append(trackIDs, track.ID)
+		}
+	}
+	return trackIDs
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/presence"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// maxPresenceFieldLength bounds usernames and track fields in heartbeats
+const maxPresenceFieldLength = 255
+
+// PresenceHandler reports who is online and what they're playing
+type PresenceHandler struct {
+	presence presence.Service
+}
+
+// NewPresenceHandler creates a new presence handler
+func NewPresenceHandler(presence presence.Service) *PresenceHandler {
+	return &PresenceHandler{presence: presence}
+}
+
+// GetPresence handles GET /api/presence?listening=, listing the users
+// online, most recently seen first. With listening=true only those sharing
+// what they're playing are listed.
+func (h *PresenceHandler) GetPresence(w http.ResponseWriter, r *http.Request) {
+	online := h.presence.Online()
+	switch r.URL.Query().Get("listening") {
+	case "", "false":
+	case "true":
+		listening := []models.Presence{}
+		for _, p := range online {
+			if p.NowPlaying != nil {
+				listening = append(listening, p)
+			}
+		}
+		online = listening
+	default:
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "listening must be true or false")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(online)
+}
+
+// Heartbeat handles POST /api/presence/heartbeat, keeping the caller online
+// and, if they opt in with share_listening, showing what they're playing
+func (h *PresenceHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	var req models.PresenceHeartbeat
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	if len(req.Username) > maxPresenceFieldLength {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "username is too long")
+		return
+	}
+	if track := req.NowPlaying; track != nil {
+		if track.TrackID == "" || track.TrackName == "" {
+			apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "now_playing requires track_id and track_name")
+			return
+		}
+		for _, field := range []string{track.TrackID, track.TrackName, track.Artist, track.Album} {
+			if len(field) > maxPresenceFieldLength {
+				apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "now_playing fields are limited to 255 bytes")
+				return
+			}
+		}
+	}
+
+	h.presence.Heartbeat(userIDFromRequest(r), req)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/jobqueue"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// defaultQueuedJobsLimit is the number of jobs listed when no limit is given
+	defaultQueuedJobsLimit = 20
+	// maxQueuedJobsLimit caps the limit query parameter
+	maxQueuedJobsLimit = 100
+)
+
+// QueueHandler lets users follow the background jobs they queued
+type QueueHandler struct {
+	jobQueue jobqueue.Service
+}
+
+// NewQueueHandler creates a new queue handler
+func NewQueueHandler(jobQueue jobqueue.Service) *QueueHandler {
+	return &QueueHandler{jobQueue: jobQueue}
+}
+
+// ListQueuedJobs handles GET /api/jobs?status=&limit=, listing the caller's
+// jobs, newest first
+func (h *QueueHandler) ListQueuedJobs(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.JobQueued, models.JobRunning, models.JobSucceeded, models.JobFailed:
+	default:
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid status (expected queued, running, succeeded or failed)")
+		return
+	}
+
+	limit := defaultQueuedJobsLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid limit")
+			return
+		}
+		if parsed > maxQueuedJobsLimit {
+			parsed = maxQueuedJobsLimit
+		}
+		limit = parsed
+	}
+
+	jobs, err := h.jobQueue.List(userIDFromRequest(r), status, limit)
+	if err != nil {
+		log.Printf("Error listing jobs: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to list jobs")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.QueuedJobsResponse{Jobs: jobs})
+}
+
+// GetQueuedJob handles GET /api/jobs/{id}, returning one of the caller's jobs
+// with its status and, once it succeeded, its result
+func (h *QueueHandler) GetQueuedJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id <= 0 {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid job ID")
+		return
+	}
+
+	job, err := h.jobQueue.Get(id)
+	if errors.Is(err, jobqueue.ErrNotFound) || (err == nil && job.UserID != userIDFromRequest(r)) {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Job not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting job %d: %v", id, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to get job")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/quiz"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultLeaderboardLimit is the number of players returned when no limit is given
+	defaultLeaderboardLimit = 10
+	// maxLeaderboardLimit caps the limit query parameter
+	maxLeaderboardLimit = 100
+)
+
+// QuizHandler handles lyrics quiz requests. Rounds are played over the quiz
+// WebSocket served by RealtimeHandler.
+type QuizHandler struct {
+	quizService quiz.Service
+}
+
+// NewQuizHandler creates a new quiz handler
+func NewQuizHandler(quizService quiz.Service) *QuizHandler {
+	return &QuizHandler{quizService: quizService}
+}
+
+// StartQuiz handles POST /api/quiz, starting a game in global chat
+func (h *QuizHandler) StartQuiz(w http.ResponseWriter, r *http.Request) {
+	game, err := h.quizService.Start(models.QuizGlobalRoom)
+	if errors.Is(err, quiz.ErrGameRunning) || errors.Is(err, quiz.ErrNotEnoughTracks) {
+		apierror.Write(w, http.StatusConflict, apierror.Conflict, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error starting quiz: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to start quiz")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(game)
+}
+
+// GetQuiz handles GET /api/quiz, returning the running game
+func (h *QuizHandler) GetQuiz(w http.ResponseWriter, r *http.Request) {
+	game, ok := h.quizService.Game(models.QuizGlobalRoom)
+	if !ok {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "No quiz is running")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(game)
+}
+
+// GetLeaderboard handles GET /api/quiz/leaderboard?limit=
+func (h *QuizHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLeaderboardLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid limit")
+			return
+		}
+		if parsed > maxLeaderboardLimit {
+			parsed = maxLeaderboardLimit
+		}
+		limit = parsed
+	}
+
+	scores, err := h.quizService.Leaderboard(models.QuizGlobalRoom, limit)
+	if err != nil {
+		log.Printf("Error loading quiz leaderboard: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to load leaderboard")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.QuizLeaderboardResponse{Room: models.QuizGlobalRoom, Scores: scores})
+}
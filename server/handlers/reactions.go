@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/events"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// maxEmojiBytes bounds a reaction, leaving room for multi-codepoint emoji
+// such as flags and family sequences
+const maxEmojiBytes = 32
+
+// AddReaction handles POST /api/messages/{id}/reactions, reacting to a
+// message as the caller with {"emoji": "🔥"}. Reacting again with the same
+// emoji changes nothing. Returns the message's reaction counts, which are
+// also pushed to chat sockets.
+func (h *ChatHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	id, ok := messageID(w, r)
+	if !ok {
+		return
+	}
+	var req models.ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+	emoji, ok := validEmoji(w, req.Emoji)
+	if !ok {
+		return
+	}
+	if !h.messageExists(w, id) {
+		return
+	}
+
+	_, err := h.exec(insertReaction, id, userIDFromRequest(r), emoji)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	h.writeReactions(w, id)
+}
+
+// RemoveReaction handles DELETE /api/messages/{id}/reactions?emoji=,
+// removing the caller's reaction. Returns the message's reaction counts.
+func (h *ChatHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	id, ok := messageID(w, r)
+	if !ok {
+		return
+	}
+	emoji, ok := validEmoji(w, r.URL.Query().Get("emoji"))
+	if !ok {
+		return
+	}
+	if !h.messageExists(w, id) {
+		return
+	}
+
+	_, err := h.exec(deleteReaction, id, userIDFromRequest(r), emoji)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	h.writeReactions(w, id)
+}
+
+// writeReactions writes a message's reaction counts and publishes them
+func (h *ChatHandler) writeReactions(w http.ResponseWriter, id int64) {
+	reactions, err := h.reactions(id)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+	counts := models.MessageReactions{MessageID: id, Reactions: reactions[id]}
+	if counts.Reactions == nil {
+		counts.Reactions = []models.ReactionCount{}
+	}
+	if h.eventBus != nil {
+		h.eventBus.Publish(events.MessageReacted, counts)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+}
+
+// reactions returns the reaction counts of messages by ID, in the order each
+// emoji was first used on the message
+func (h *ChatHandler) reactions(ids ...int64) (map[int64][]models.ReactionCount, error) {
+	reactions := make(map[int64][]models.ReactionCount)
+	if len(ids) == 0 {
+		return reactions, nil
+	}
+	rows, err := h.query(selectReactions, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID int64
+		var count models.ReactionCount
+		if err := rows.Scan(&messageID, &count.Emoji, &count.Count); err != nil {
+			return nil, err
+		}
+		reactions[messageID] = append(reactions[messageID], count)
+	}
+	return reactions, rows.Err()
+}
+
+// messageExists writes 404 and returns false if the message doesn't exist
+func (h *ChatHandler) messageExists(w http.ResponseWriter, id int64) bool {
+	var exists bool
+	if err := h.queryRow(selectMessageExists, id).Scan(&exists); err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return false
+	}
+	if !exists {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Message not found")
+	}
+	return exists
+}
+
+// messageID parses the {id} route variable, writing 400 if it's invalid
+func messageID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id < 1 {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid message ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// validEmoji trims a reaction and checks that it is a single short run of
+// symbols, writing 400 if it isn't
+func validEmoji(w http.ResponseWriter, emoji string) (string, bool) {
+	emoji = strings.TrimSpace(emoji)
+	valid := emoji != "" && len(emoji) <= maxEmojiBytes && utf8.ValidString(emoji)
+	for _, r := range emoji {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || unicode.IsPunct(r) {
+			valid = false
+		}
+	}
+	if !valid {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "emoji must be a single emoji")
+		return "", false
+	}
+	return emoji, true
+}
@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"backend/repositories"
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/events"
+	"backend/services/pgnotify"
+	"backend/services/presence"
+	"backend/services/quiz"
+	"backend/services/realtime"
+	"backend/services/restricted"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Hub channels WebSocket clients subscribe to
+const (
+	nowPlayingChannel     = "now-playing"
+	chatChannel           = "chat"
+	restrictedChatChannel = "chat:restricted" // Chat with profanity masked
+	quizChannelPrefix     = "quiz:"           // Followed by the room, and ":restricted" for masked snippets
+)
+
+// Postgres channels chat is fanned out on
+const (
+	// messagesNotifyChannel is notified by the global_messages insert trigger
+	// with each new message's ID
+	messagesNotifyChannel = "global_messages"
+	// chatEventsNotifyChannel is notified by instances with the encoded
+	// reactions and removals they publish
+	chatEventsNotifyChannel = "chat_events"
+)
+
+// RealtimeHandler serves WebSocket connections pushing now-playing changes and
+// global chat messages, and a Server-Sent Events stream of now-playing changes.
+// Authentication is left to middleware on the routes; the realtime service
+// checks the Origin and enforces message limits.
+type RealtimeHandler struct {
+	hub         realtime.Hub
+	config      realtime.Config
+	musicRepo   repositories.MusicRepository
+	chatHandler *ChatHandler
+	quizService quiz.Service     // Optional; the quiz socket needs it
+	presence    presence.Service // Optional; chat sockets keep their users online
+	streams     sseStreams       // Now-playing Server-Sent Events clients
+	typing      realtime.TypingTracker
+	listening   bool // Chat is broadcast as Postgres notifies it
+}
+
+// NewRealtimeHandler creates a new realtime handler. Messages posted over
+// the chat socket are stored and published by chatHandler.
+func NewRealtimeHandler(hub realtime.Hub, config realtime.Config, musicRepo repositories.MusicRepository, chatHandler *ChatHandler) *RealtimeHandler {
+	h := &RealtimeHandler{
+		hub:         hub,
+		config:      config,
+		musicRepo:   musicRepo,
+		chatHandler: chatHandler,
+	}
+	h.typing = realtime.NewTypingTracker(config.TypingTimeout, func(room, user string, typing bool) {
+		data := realtimeEvent(models.RealtimeTyping, models.TypingIndicator{Room: room, UserID: user, Typing: typing})
+		h.hub.Broadcast(chatChannel, data)
+		h.hub.Broadcast(restrictedChatChannel, data)
+	})
+	return h
+}
+
+// SetQuizService enables the quiz socket
+func (h *RealtimeHandler) SetQuizService(quizService quiz.Service) {
+	h.quizService = quizService
+}
+
+// SetPresence marks users online while their chat socket is open
+func (h *RealtimeHandler) SetPresence(presence presence.Service) {
+	h.presence = presence
+}
+
+// ListenForMessages broadcasts every message inserted into the database, and
+// every reaction and removal, as Postgres notifies them, rather than those of
+// this instance, so that chat clients of every instance sharing the database
+// see them all. Call it before Subscribe.
+func (h *RealtimeHandler) ListenForMessages(listener *pgnotify.Listener) error {
+	h.listening = true
+	err := listener.Listen(chatEventsNotifyChannel, func(payload string) {
+		h.hub.Broadcast(chatChannel, []byte(payload))
+		h.hub.Broadcast(restrictedChatChannel, []byte(payload))
+	})
+	if err != nil {
+		return err
+	}
+	return listener.Listen(messagesNotifyChannel, func(payload string) {
+		id, err := strconv.ParseInt(payload, 10, 64)
+		if err != nil {
+			log.Printf("Ignoring invalid message notification %q", payload)
+			return
+		}
+		msg, ok, err := h.chatHandler.message(id)
+		if err != nil {
+			log.Printf("Error loading notified message %d: %v", id, err)
+			return
+		}
+		// Messages hidden or removed since they were posted are skipped
+		if ok {
+			h.broadcastMessage(msg)
+		}
+	})
+}
+
+// Subscribe broadcasts track changes, posted and removed messages, reactions
+// and quiz progress from the event bus to connected clients. Posted messages
+// are left to ListenForMessages if it was called, and reactions and removals
+// are sent through Postgres to reach it.
+func (h *RealtimeHandler) Subscribe(eventBus events.Bus) {
+	eventBus.Subscribe(events.TrackChanged, "realtime", func(event events.Event) error {
+		data := realtimeEvent(models.RealtimeTrackChanged, event.Payload)
+		h.hub.Broadcast(nowPlayingChannel, data)
+		h.streams.broadcast(sseEvent{models.RealtimeTrackChanged, data})
+		return nil
+	})
+	if !h.listening {
+		eventBus.Subscribe(events.MessagePosted, "realtime", func(event events.Event) error {
+			h.broadcastMessage(event.Payload.(models.Message))
+			return nil
+		})
+	}
+	eventBus.Subscribe(events.MessageReacted, "realtime", func(event events.Event) error {
+		h.broadcastChatEvent(realtimeEvent(models.RealtimeReactions, event.Payload))
+		return nil
+	})
+	eventBus.Subscribe(events.MessageRemoved, "realtime", func(event events.Event) error {
+		h.broadcastChatEvent(realtimeEvent(models.RealtimeRemoved, event.Payload))
+		return nil
+	})
+	eventBus.Subscribe(events.QuizRoundStarted, "realtime", func(event events.Event) error {
+		round := event.Payload.(models.QuizRound)
+		h.hub.Broadcast(quizChannel(round.Room, false), realtimeEvent(models.RealtimeQuizRound, round))
+		h.hub.Broadcast(quizChannel(round.Room, true), realtimeEvent(models.RealtimeQuizRound, maskQuizRound(round)))
+		return nil
+	})
+	eventBus.Subscribe(events.QuizRoundEnded, "realtime", func(event events.Event) error {
+		result := event.Payload.(models.QuizRoundResult)
+		data := realtimeEvent(models.RealtimeQuizResult, result)
+		h.hub.Broadcast(quizChannel(result.Room, false), data)
+		h.hub.Broadcast(quizChannel(result.Room, true), data)
+		return nil
+	})
+	eventBus.Subscribe(events.QuizEnded, "realtime", func(event events.Event) error {
+		game := event.Payload.(models.QuizGame)
+		data := realtimeEvent(models.RealtimeQuizEnded, game)
+		h.hub.Broadcast(quizChannel(game.Room, false), data)
+		h.hub.Broadcast(quizChannel(game.Room, true), data)
+		return nil
+	})
+}
+
+// broadcastChatEvent pushes a chat event that is the same for restricted
+// clients to all chat clients. When listening, it goes through Postgres so
+// other instances push it too; if the notification fails, only this
+// instance's clients get it.
+func (h *RealtimeHandler) broadcastChatEvent(data []byte) {
+	if h.listening {
+		_, err := h.chatHandler.exec(notifyChatEvent, chatEventsNotifyChannel, string(data))
+		if err == nil {
+			return
+		}
+		log.Printf("Error notifying chat event, pushing it to this instance's clients only: %v", err)
+	}
+	h.hub.Broadcast(chatChannel, data)
+	h.hub.Broadcast(restrictedChatChannel, data)
+}
+
+// broadcastMessage pushes a posted message to chat clients, masked for
+// restricted ones
+func (h *RealtimeHandler) broadcastMessage(msg models.Message) {
+	h.hub.Broadcast(chatChannel, realtimeEvent(models.RealtimeMessage, msg))
+	msg.Text = restricted.MaskProfanity(msg.Text)
+	h.hub.Broadcast(restrictedChatChannel, realtimeEvent(models.RealtimeMessage, msg))
+}
+
+// NowPlaying handles GET /api/ws/now-playing. The current track is sent on
+// connect, followed by every track change.
+func (h *RealtimeHandler) NowPlaying(w http.ResponseWriter, r *http.Request) {
+	conn, err := realtime.Upgrade(w, r, h.config)
+	if err != nil {
+		log.Printf("WebSocket upgrade rejected: %v", err)
+		return
+	}
+	unsubscribe := h.hub.Subscribe(nowPlayingChannel, conn)
+	defer unsubscribe()
+
+	if h.musicRepo.HasCurrentTrack() {
+		conn.Send(realtimeEvent(models.RealtimeNowPlaying, h.musicRepo.GetNowPlaying()))
+	}
+
+	// Clients have nothing to send, but reading handles pings and enforces limits
+	for {
+		if _, err := conn.ReadMessage(); err != nil {
+			logSocketError(err)
+			return
+		}
+	}
+}
+
+// Chat handles GET /api/ws/chat. Posted messages are pushed to every client,
+// and clients may post by sending a message as JSON. Clients sending
+// {"typing": true} are shown as typing to the others until they send
+// {"typing": false}, post, disconnect or stop refreshing it. Browsers pass
+// their user ID as the user_id query parameter, since they can't set
+// X-User-ID.
+func (h *RealtimeHandler) Chat(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromRequest(r)
+	if queryUserID := strings.TrimSpace(r.URL.Query().Get("user_id")); queryUserID != "" {
+		userID = queryUserID
+	}
+
+	conn, err := realtime.Upgrade(w, r, h.config)
+	if err != nil {
+		log.Printf("WebSocket upgrade rejected: %v", err)
+		return
+	}
+
+	channel := chatChannel
+	if h.chatHandler.isRestricted(userID) {
+		channel = restrictedChatChannel
+	}
+	unsubscribe := h.hub.Subscribe(channel, conn)
+	defer unsubscribe()
+	defer h.typing.Stop(models.GlobalRoom, userID)
+	if h.presence != nil {
+		defer h.presence.Connect(userID)()
+	}
+
+	// Clients joining see who is already typing
+	for _, user := range h.typing.Typing(models.GlobalRoom) {
+		if user != userID {
+			conn.Send(realtimeEvent(models.RealtimeTyping, models.TypingIndicator{Room: models.GlobalRoom, UserID: user, Typing: true}))
+		}
+	}
+
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			logSocketError(err)
+			return
+		}
+
+		var typing struct {
+			Typing *bool `json:"typing"`
+		}
+		if json.Unmarshal(data, &typing) == nil && typing.Typing != nil {
+			if *typing.Typing {
+				h.typing.Start(models.GlobalRoom, userID)
+			} else {
+				h.typing.Stop(models.GlobalRoom, userID)
+			}
+			continue
+		}
+
+		var msg models.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			conn.Send(realtimeError("Invalid message"))
+			continue
+		}
+		// The message reaches this client through the hub like everyone else's
+		if _, _, err := h.chatHandler.postMessage(msg, userID); err != nil {
+			conn.Send(realtimeError(err.Error()))
+			continue
+		}
+		h.typing.Stop(models.GlobalRoom, userID)
+	}
+}
+
+// Quiz handles GET /api/ws/quiz. Rounds, results and final standings of
+// global chat's quiz are pushed to every client, and clients answer by
+// sending {"answer": "<choice>"}. Like chat, browsers pass user_id.
+func (h *RealtimeHandler) Quiz(w http.ResponseWriter, r *http.Request) {
+	if h.quizService == nil {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "The quiz is not enabled")
+		return
+	}
+	userID := userIDFromRequest(r)
+	if queryUserID := strings.TrimSpace(r.URL.Query().Get("user_id")); queryUserID != "" {
+		userID = queryUserID
+	}
+
+	conn, err := realtime.Upgrade(w, r, h.config)
+	if err != nil {
+		log.Printf("WebSocket upgrade rejected: %v", err)
+		return
+	}
+
+	masked := h.chatHandler.isRestricted(userID)
+	unsubscribe := h.hub.Subscribe(quizChannel(models.QuizGlobalRoom, masked), conn)
+	defer unsubscribe()
+
+	// Players joining mid-game see the open round
+	if game, ok := h.quizService.Game(models.QuizGlobalRoom); ok {
+		if masked && game.Round != nil {
+			round := maskQuizRound(*game.Round)
+			game.Round = &round
+		}
+		conn.Send(realtimeEvent(models.RealtimeQuiz, game))
+	}
+
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			logSocketError(err)
+			return
+		}
+
+		var req struct {
+			Answer string `json:"answer"`
+		}
+		if err := json.Unmarshal(data, &req); err != nil || strings.TrimSpace(req.Answer) == "" {
+			conn.Send(realtimeError("Invalid answer"))
+			continue
+		}
+		result, err := h.quizService.Answer(models.QuizGlobalRoom, userID, req.Answer)
+		if err != nil {
+			conn.Send(realtimeError(err.Error()))
+			continue
+		}
+		conn.Send(realtimeEvent(models.RealtimeQuizAnswer, result))
+	}
+}
+
+// quizChannel is the hub channel of a room's quiz
+func quizChannel(room string, masked bool) string {
+	if masked {
+		return quizChannelPrefix + room + ":restricted"
+	}
+	return quizChannelPrefix + room
+}
+
+// maskQuizRound masks profanity in a round's snippet for restricted players
+func maskQuizRound(round models.QuizRound) models.QuizRound {
+	round.Snippet = restricted.MaskProfanity(round.Snippet)
+	return round
+}
+
+// realtimeEvent encodes an event for WebSocket clients
+func realtimeEvent(eventType string, data interface{}) []byte {
+	encoded, _ := json.Marshal(models.RealtimeEvent{Type: eventType, Data: data})
+	return encoded
+}
+
+// realtimeError encodes a rejected client message for WebSocket clients
+func realtimeError(message string) []byte {
+	encoded, _ := json.Marshal(models.RealtimeEvent{Type: models.RealtimeError, Error: message})
+	return encoded
+}
+
+// logSocketError logs why a connection ended, unless the client closed it
+func logSocketError(err error) {
+	if !errors.Is(err, io.EOF) {
+		log.Printf("WebSocket closed: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/services/reports"
+	"encoding/json"
+	"net/http"
+)
+
+// ReportsHandler handles weekly report requests
+type ReportsHandler struct {
+	reportsService reports.Service
+}
+
+// NewReportsHandler creates a new reports handler
+func NewReportsHandler(reportsService reports.Service) *ReportsHandler {
+	return &ReportsHandler{reportsService: reportsService}
+}
+
+// GetWeeklyReport handles GET /api/reports/weekly, returning the caller's
+// latest weekly mood and listening report. Callers must send X-User-ID.
+func (h *ReportsHandler) GetWeeklyReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := explicitUserID(w, r)
+	if !ok {
+		return
+	}
+
+	report, ok := h.reportsService.Latest(userID)
+	if !ok {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "No weekly report yet; reports cover weeks with detected moods")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/restricted"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RestrictionsHandler manages per-user restricted (parental/teen) mode
+type RestrictionsHandler struct {
+	restrictions restricted.Service
+}
+
+// NewRestrictionsHandler creates a new restrictions handler
+func NewRestrictionsHandler(restrictions restricted.Service) *RestrictionsHandler {
+	return &RestrictionsHandler{restrictions: restrictions}
+}
+
+// GetRestrictedMode handles GET /api/users/{userID}/restricted-mode
+func (h *RestrictionsHandler) GetRestrictedMode(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.restrictions.Status(userID))
+}
+
+// SetRestrictedMode handles PUT /api/users/{userID}/restricted-mode.
+// A user can't leave restricted mode while the whole deployment is restricted.
+func (h *RestrictionsHandler) SetRestrictedMode(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	var req models.RestrictedModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	status := h.restrictions.Status(userID)
+	if status.Deployment && !req.Restricted {
+		apierror.Write(w, http.StatusConflict, apierror.Conflict, "Restricted mode is enforced for every user by configuration")
+		return
+	}
+
+	h.restrictions.SetRestricted(userID, req.Restricted)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.restrictions.Status(userID))
+}
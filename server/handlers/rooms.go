@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ListRooms handles GET /api/rooms, listing the chat rooms with the caller's
+// read position and unread count in each
+func (h *ChatHandler) ListRooms(w http.ResponseWriter, r *http.Request) {
+	room, _, err := h.roomState(userIDFromRequest(r), models.GlobalRoom)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode([]models.Room{room})
+}
+
+// GetReadMarker handles GET /api/rooms/{room}/read, returning the last
+// message the caller read in the room and how many came after it
+func (h *ChatHandler) GetReadMarker(w http.ResponseWriter, r *http.Request) {
+	roomID, ok := chatRoom(w, r)
+	if !ok {
+		return
+	}
+
+	room, updatedAt, err := h.roomState(userIDFromRequest(r), roomID)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ReadMarker{
+		Room:              room.ID,
+		LastReadMessageID: room.LastReadMessageID,
+		UnreadCount:       room.UnreadCount,
+		UpdatedAt:         updatedAt,
+	})
+}
+
+// SetReadMarker handles PUT /api/rooms/{room}/read with {"message_id": ...},
+// moving the caller's read position in the room to that message. It may move
+// back, to mark messages unread again.
+func (h *ChatHandler) SetReadMarker(w http.ResponseWriter, r *http.Request) {
+	roomID, ok := chatRoom(w, r)
+	if !ok {
+		return
+	}
+	var req models.ReadMarkerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+	if req.MessageID < 1 {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "message_id is required")
+		return
+	}
+	if !h.messageExists(w, req.MessageID) {
+		return
+	}
+
+	userID := userIDFromRequest(r)
+	_, err := h.exec(upsertReadMarker, userID, roomID, req.MessageID)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	room, updatedAt, err := h.roomState(userID, roomID)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ReadMarker{
+		Room:              room.ID,
+		LastReadMessageID: room.LastReadMessageID,
+		UnreadCount:       room.UnreadCount,
+		UpdatedAt:         updatedAt,
+	})
+}
+
+// roomState loads a user's read position in a room, counting the messages
+// and replies after it that they can see, less their own. updatedAt is nil
+// if they never read the room.
+func (h *ChatHandler) roomState(userID, roomID string) (room models.Room, updatedAt *time.Time, err error) {
+	room.ID = roomID
+
+	var readAt time.Time
+	err = h.queryRow(selectReadMarker, userID, roomID).Scan(&room.LastReadMessageID, &readAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return room, nil, err
+	default:
+		updatedAt = &readAt
+	}
+
+	err = h.queryRow(selectRoomCounts, userID, room.LastReadMessageID).Scan(&room.LatestMessageID, &room.UnreadCount)
+	return room, updatedAt, err
+}
+
+// chatRoom reads the {room} route variable, writing 404 if there's no such
+// room
+func chatRoom(w http.ResponseWriter, r *http.Request) (string, bool) {
+	room := mux.Vars(r)["room"]
+	if room != models.GlobalRoom {
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Room not found")
+		return "", false
+	}
+	return room, true
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/services/search"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultSuggestLimit is the number of suggestions returned when no limit is given
+	defaultSuggestLimit = 8
+	// maxSuggestLimit caps the limit query parameter
+	maxSuggestLimit = 20
+)
+
+// SearchHandler handles search-related HTTP requests
+type SearchHandler struct {
+	searchService search.Service
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(searchService search.Service) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// Suggest handles GET /api/search/suggest?q=
+func (h *SearchHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Query parameter q is required")
+		return
+	}
+
+	limit := defaultSuggestLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid limit")
+			return
+		}
+		if parsed > maxSuggestLimit {
+			parsed = maxSuggestLimit
+		}
+		limit = parsed
+	}
+
+	suggestions := h.searchService.Suggest(query, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "private, max-age=30")
+	json.NewEncoder(w).Encode(suggestions)
+}
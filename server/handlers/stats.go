@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/services/projections"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultStatsDays is the window returned when no days parameter is given
+	defaultStatsDays = 7
+	// maxStatsDays caps the days query parameter
+	maxStatsDays = 90
+)
+
+// StatsHandler serves listening and activity stats from read-model projections
+type StatsHandler struct {
+	projections projections.Service
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(projectionService projections.Service) *StatsHandler {
+	return &StatsHandler{projections: projectionService}
+}
+
+// GetStats handles GET /api/stats?days=
+// The stats are the caller's, who must send X-User-ID.
+func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	userID, ok := explicitUserID(w, r)
+	if !ok {
+		return
+	}
+
+	days := defaultStatsDays
+	if daysParam := r.URL.Query().Get("days"); daysParam != "" {
+		parsed, err := strconv.Atoi(daysParam)
+		if err != nil || parsed <= 0 {
+			apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid days")
+			return
+		}
+		if parsed > maxStatsDays {
+			parsed = maxStatsDays
+		}
+		days = parsed
+	}
+
+	stats, err := h.projections.UserStats(userID, days)
+	if err != nil {
+		log.Printf("Error loading stats for %s: %v", userID, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to load stats")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/jobqueue"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+)
+
+const (
+	// maxBulkMoodTracks limits how many tracks a single bulk mood lookup may request
+	maxBulkMoodTracks = 50
+	// maxQueuedMoodTracks limits how many tracks a queued mood analysis may cover
+	maxQueuedMoodTracks = 2000
+)
+
+// GetTrackMoods handles POST /api/tracks/moods.
+// It returns cached mood analyses for up to maxBulkMoodTracks tracks. New AI
+// analyses are only run for cache misses when the request sets "analyze".
+// With "async", up to maxQueuedMoodTracks tracks are analyzed by a queued
+// job instead, and the job is returned with 202 Accepted.
+func (h *LyricsHandler) GetTrackMoods(w http.ResponseWriter, r *http.Request) {
+	var req models.TrackMoodsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	maxTracks := maxBulkMoodTracks
+	if req.Async {
+		maxTracks = maxQueuedMoodTracks
+	}
+	if len(req.Tracks) == 0 {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "At least one track is required")
+		return
+	}
+	if len(req.Tracks) > maxTracks {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, fmt.Sprintf("At most %d tracks can be requested at once", maxTracks))
+		return
+	}
+
+	if req.Async {
+		h.queueTrackMoods(w, r, req)
+		return
+	}
+
+	results := h.analyzeTrackMoods(r.Context(), req.Tracks, req.Analyze)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.TrackMoodsResponse{Results: results})
+}
+
+// queueTrackMoods enqueues a track_moods job analyzing the request's tracks
+func (h *LyricsHandler) queueTrackMoods(w http.ResponseWriter, r *http.Request, req models.TrackMoodsRequest) {
+	if h.jobQueue == nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Background analysis is not available")
+		return
+	}
+
+	req.Analyze, req.Async = true, false
+	job, err := h.jobQueue.Enqueue(userIDFromRequest(r), models.JobTypeTrackMoods, req)
+	if errors.Is(err, jobqueue.ErrQueueFull) {
+		w.Header().Set("Retry-After", "60")
+		apierror.Write(w, http.StatusServiceUnavailable, apierror.RateLimited, "Too many background jobs are waiting; try again later")
+		return
+	}
+	if err != nil {
+		log.Printf("Error queueing track mood analysis: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to queue the analysis")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// TrackMoodsJob runs a track_moods job, analyzing every track missing from
+// the mood cache. The attempt fails, to be retried, if no analysis succeeded;
+// successful analyses are cached, so a retry only repeats the failed ones.
+func (h *LyricsHandler) TrackMoodsJob(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var req models.TrackMoodsRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, fmt.Errorf("invalid track_moods payload: %w", err)
+	}
+
+	results := h.analyzeTrackMoods(ctx, req.Tracks, true)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var analyzed, failed int
+	var lastError string
+	for _, result := range results {
+		switch {
+		case result.MoodAnalysis != nil:
+			analyzed++
+		case result.Error != "":
+			failed++
+			lastError = result.Error
+		}
+	}
+	if analyzed == 0 && failed > 0 {
+		return nil, fmt.Errorf("all %d analyses failed, e.g. %s", failed, lastError)
+	}
+	return models.TrackMoodsResponse{Results: results}, nil
+}
+
+// analyzeTrackMoods looks up the mood of each track, analyzing cache misses
+// five at a time if analyze is set. Once ctx is done no new analyses start.
+func (h *LyricsHandler) analyzeTrackMoods(ctx context.Context, tracks []models.TrackReference, analyze bool) []models.TrackMoodResult {
+	results := make([]models.TrackMoodResult, len(tracks))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 5) // Analyze max 5 tracks at a time
+
+	for i, track := range tracks {
+		results[i].Track = track
+
+		if track.Name == "" || track.Artist == "" {
+			results[i].Error = "name and artist are required"
+			continue
+		}
+
+		if cached, ok := h.moodService.GetCachedLyricsMood(track.Name, track.Artist); ok {
+			results[i].Cached = true
+			results[i].MoodAnalysis = cached.MoodAnalysis
+			results[i].Themes = cached.Themes
+			continue
+		}
+
+		if !analyze {
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *models.TrackMoodResult) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				result.Error = "analysis cancelled"
+				return
+			}
+			defer func() { <-semaphore }()
+
+			analysis, err := h.moodService.GetLyricsWithMood(result.Track.Name, result.Track.Artist)
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			result.MoodAnalysis = analysis.MoodAnalysis
+			result.Themes = analysis.Themes
+		}(&results[i])
+	}
+
+	wg.Wait()
+	return results
+}
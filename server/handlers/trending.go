@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/trending"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultTrendingLimit is the number of tracks returned when no limit is given
+	defaultTrendingLimit = 10
+	// maxTrendingLimit caps the limit query parameter
+	maxTrendingLimit = 50
+)
+
+// TrendingHandler handles trending-related HTTP requests
+type TrendingHandler struct {
+	trendingService trending.Service
+}
+
+// NewTrendingHandler creates a new trending handler
+func NewTrendingHandler(trendingService trending.Service) *TrendingHandler {
+	return &TrendingHandler{trendingService: trendingService}
+}
+
+// GetTrending handles GET /api/trending?limit=
+func (h *TrendingHandler) GetTrending(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTrendingLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid limit")
+			return
+		}
+		if parsed > maxTrendingLimit {
+			parsed = maxTrendingLimit
+		}
+		limit = parsed
+	}
+
+	response := models.TrendingResponse{
+		Window: h.trendingService.Window().String(),
+		Tracks: h.trendingService.Top(limit),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=10")
+	json.NewEncoder(w).Encode(response)
+}
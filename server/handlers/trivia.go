@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"backend/server/models"
+	"backend/services/trivia"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// triviaHistoryTracks is the number of distinct recent songs a quiz about the
+// user's listening history covers
+const triviaHistoryTracks = 10
+
+// triviaTriggers start a chat quiz
+var triviaTriggers = []string{"quiz me", "trivia", "start a quiz", "music quiz"}
+
+// triviaStopPhrases end a chat quiz early
+var triviaStopPhrases = []string{"stop quiz", "stop the quiz", "end quiz", "end the quiz", "quit quiz", "quit the quiz", "stop trivia", "end trivia"}
+
+// triviaHistoryPhrases ask for a quiz about the user's listening history
+// rather than the current artist
+var triviaHistoryPhrases = []string{"history", "recent", "been listening", "listened to"}
+
+// SetTrivia sets the service running chat quizzes. Without it chat has no quiz mode.
+func (h *LyricsHandler) SetTrivia(trivia trivia.Service) {
+	h.trivia = trivia
+}
+
+// handleTriviaQuery answers the query if it starts, answers or stops a chat
+// quiz. It returns false for queries that have nothing to do with a quiz,
+// including messages during a quiz that don't answer the question.
+func (h *LyricsHandler) handleTriviaQuery(query, userID, lang string, clean bool) (models.ChatResponse, bool) {
+	if h.trivia == nil {
+		return models.ChatResponse{}, false
+	}
+	lowerQuery := strings.ToLower(query)
+
+	if h.trivia.Active(userID) {
+		if containsAny(lowerQuery, triviaStopPhrases) {
+			quiz, err := h.trivia.Stop(userID)
+			if err == nil {
+				return triviaResponse(localize(lang, msgQuizStopped, quiz.Score, quiz.Answered), quiz), true
+			}
+		}
+		quiz, err := h.trivia.Answer(userID, query)
+		if err == nil {
+			return triviaResponse(triviaAnswerText(quiz, lang), quiz), true
+		}
+		if !errors.Is(err, trivia.ErrNotAnAnswer) && !errors.Is(err, trivia.ErrNoQuiz) {
+			log.Printf("Error answering quiz for user %s: %v", userID, err)
+		}
+	}
+
+	if !containsAny(lowerQuery, triviaTriggers) {
+		return models.ChatResponse{}, false
+	}
+
+	topic := h.triviaTopic(lowerQuery)
+	if topic == "" {
+		return models.ChatResponse{Type: "quiz", Answer: localize(lang, msgQuizNoTopic)}, true
+	}
+	quiz, err := h.trivia.Start(userID, h.aiForRequest(userID, clean), topic, languageInstruction(lang))
+	if err != nil {
+		log.Printf("Error starting quiz about %s for user %s: %v", topic, userID, err)
+		return models.ChatResponse{Type: "quiz", Answer: localize(lang, msgQuizUnavailable)}, true
+	}
+	return triviaResponse(triviaQuestionText(quiz, lang), quiz), true
+}
+
+// triviaTopic picks what a quiz is about: the current artist, or the user's
+// recent songs if the query asks for them or nothing is playing. It returns ""
+// if there is neither.
+func (h *LyricsHandler) triviaTopic(lowerQuery string) string {
+	if h.musicRepo.HasCurrentTrack() && !containsAny(lowerQuery, triviaHistoryPhrases) {
+		return fmt.Sprintf("the artist %s", h.musicRepo.GetNowPlaying().Artist)
+	}
+
+	seen := make(map[string]bool)
+	var songs []string
+	for _, item := range h.musicRepo.GetPlayHistory() {
+		song := fmt.Sprintf("%q by %s", item.TrackName, item.Artist)
+		if seen[song] {
+			continue
+		}
+		seen[song] = true
+		songs = append(songs, song)
+		if len(songs) == triviaHistoryTracks {
+			break
+		}
+	}
+	if len(songs) == 0 {
+		return ""
+	}
+	return "these songs and their artists: " + strings.Join(songs, ", ")
+}
+
+// triviaResponse is a chat response carrying the quiz state
+func triviaResponse(answer string, quiz models.TriviaQuiz) models.ChatResponse {
+	return models.ChatResponse{
+		Type:   "quiz",
+		Answer: answer,
+		Quiz:   &quiz,
+	}
+}
+
+// triviaAnswerText tells the user whether their answer was right, followed
+// by the next question or the final score
+func triviaAnswerText(quiz models.TriviaQuiz, lang string) string {
+	result := quiz.Result
+	var feedback string
+	if result.Correct {
+		feedback = localize(lang, msgQuizCorrect)
+	} else {
+		feedback = localize(lang, msgQuizWrong, fmt.Sprintf("%c) %s", 'A'+result.Answer, result.Choice))
+	}
+	if result.Explanation != "" {
+		feedback += " " + result.Explanation
+	}
+
+	if quiz.Finished {
+		return feedback + "\n\n" + localize(lang, msgQuizFinished, quiz.Score, quiz.Total)
+	}
+	return feedback + "\n\n" + triviaQuestionText(quiz, lang)
+}
+
+// triviaQuestionText formats the quiz's next question with lettered choices
+func triviaQuestionText(quiz models.TriviaQuiz, lang string) string {
+	question := quiz.Question
+	var text strings.Builder
+	text.WriteString(localize(lang, msgQuizQuestion, question.Number, quiz.Total, question.Question))
+	for i, choice := range question.Choices {
+		fmt.Fprintf(&text, "\n%c) %s", 'A'+i, choice)
+	}
+	return text.String()
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/avatars"
+	"backend/services/erasure"
+	"backend/services/export"
+	"backend/services/jobqueue"
+	"backend/services/users"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Profile limits
+const (
+	maxEmailLength       = 255
+	maxDisplayNameLength = 64
+	maxBioLength         = 500
+	maxFavoriteGenres    = 10
+	maxGenreLength       = 32
+)
+
+// usernamePattern is 3 to 32 letters, digits, dots, dashes and underscores
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{3,32}$`)
+
+// UsersHandler serves user profiles
+type UsersHandler struct {
+	users    users.Service
+	avatars  avatars.Service  // Optional; without it the avatar endpoints answer 404
+	eraser   erasure.Service  // Optional, with jobQueue; without them data deletion answers 404
+	exporter export.Service   // Optional, with jobQueue; without them data export answers 404
+	jobQueue jobqueue.Service // Runs data deletions and exports
+}
+
+// NewUsersHandler creates a new users handler
+func NewUsersHandler(users users.Service) *UsersHandler {
+	return &UsersHandler{users: users}
+}
+
+// SetAvatars enables avatar uploads
+func (h *UsersHandler) SetAvatars(avatars avatars.Service) {
+	h.avatars = avatars
+}
+
+// SetJobQueue sets the queue that data deletions and exports run on
+func (h *UsersHandler) SetJobQueue(jobQueue jobqueue.Service) {
+	h.jobQueue = jobQueue
+}
+
+// GetMe handles GET /api/users/me, returning the caller's profile
+func (h *UsersHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	user, err := h.users.Get(userIDFromRequest(r))
+	if err != nil {
+		log.Printf("Error loading user profile: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to load the profile")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// UpdateMe handles PATCH /api/users/me, changing the fields of the caller's
+// profile that the body sets
+func (h *UsersHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	var update models.UserUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+	if err := normalizeUserUpdate(&update); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, err.Error())
+		return
+	}
+
+	user, err := h.users.Update(userIDFromRequest(r), update)
+	if errors.Is(err, users.ErrUsernameTaken) {
+		apierror.Write(w, http.StatusConflict, apierror.Conflict, "Username already taken")
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating user profile: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to update the profile")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// normalizeUserUpdate trims a profile update and checks its limits
+func normalizeUserUpdate(update *models.UserUpdate) error {
+	if update.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*update.Email))
+		if email != "" && (!strings.Contains(email, "@") || len(email) > maxEmailLength) {
+			return errors.New("email must be an email address of up to 255 bytes")
+		}
+		update.Email = &email
+	}
+	if update.Username != nil {
+		username := strings.TrimSpace(*update.Username)
+		if username != "" && !usernamePattern.MatchString(username) {
+			return errors.New("username must be 3 to 32 letters, digits, dots, dashes or underscores")
+		}
+		update.Username = &username
+	}
+	if update.DisplayName != nil {
+		displayName := strings.TrimSpace(*update.DisplayName)
+		if len(displayName) > maxDisplayNameLength {
+			return errors.New("display_name is limited to 64 bytes")
+		}
+		update.DisplayName = &displayName
+	}
+	if update.Bio != nil {
+		bio := strings.TrimSpace(*update.Bio)
+		if len(bio) > maxBioLength {
+			return errors.New("bio is limited to 500 bytes")
+		}
+		update.Bio = &bio
+	}
+	if update.FavoriteGenres != nil {
+		if len(*update.FavoriteGenres) > maxFavoriteGenres {
+			return errors.New("favorite_genres is limited to 10 genres")
+		}
+		genres := []string{}
+		seen := make(map[string]bool)
+		for _, genre := range *update.FavoriteGenres {
+			genre = strings.ToLower(strings.TrimSpace(genre))
+			if genre == "" || len(genre) > maxGenreLength {
+				return errors.New("favorite_genres must be non-empty and up to 32 bytes each")
+			}
+			if !seen[genre] {
+				seen[genre] = true
+				genres = append(genres, genre)
+			}
+		}
+		update.FavoriteGenres = &genres
+	}
+	return nil
+}
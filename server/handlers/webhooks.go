@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"backend/server/apierror"
+	"backend/server/models"
+	"backend/services/streaming"
+	"backend/services/webhooks"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// WebhooksHandler registers webhooks and lets their owners inspect and
+// redeliver recent deliveries
+type WebhooksHandler struct {
+	webhookService webhooks.Service
+}
+
+// NewWebhooksHandler creates a new webhooks handler
+func NewWebhooksHandler(webhookService webhooks.Service) *WebhooksHandler {
+	return &WebhooksHandler{webhookService: webhookService}
+}
+
+// CreateWebhook handles POST /api/webhooks. The response is the only one
+// that includes the webhook's secret.
+func (h *WebhooksHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var request models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	webhook, err := h.webhookService.Register(request)
+	if errors.Is(err, webhooks.ErrInvalidURL) || errors.Is(err, webhooks.ErrInvalidEvent) {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error registering webhook: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to register webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// ListWebhooks handles GET /api/webhooks
+func (h *WebhooksHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	registered, err := h.webhookService.List()
+	if err != nil {
+		log.Printf("Error listing webhooks: %v", err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed to list webhooks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.WebhooksResponse{Webhooks: registered})
+}
+
+// DeleteWebhook handles DELETE /api/webhooks/{id}
+func (h *WebhooksHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	if !h.handleError(w, h.webhookService.Delete(id), "deleting webhook") {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles GET /api/webhooks/{id}/deliveries?status=&limit=
+func (h *WebhooksHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && status != models.DeliveryDelivered && status != models.DeliveryFailed {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid status (expected delivered or failed)")
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid limit")
+			return
+		}
+		if parsed > maxDeliveriesLimit {
+			parsed = maxDeliveriesLimit
+		}
+		limit = parsed
+	}
+
+	response, err := h.webhookService.Deliveries(id, status, limit)
+	if !h.handleError(w, err, "listing webhook deliveries") {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// RedeliverWebhook handles POST /api/webhooks/{id}/deliveries/{deliveryID}/redeliver.
+// The updated delivery is returned either way, with 502 Bad Gateway if the
+// webhook rejected it again.
+func (h *WebhooksHandler) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseInt(mux.Vars(r)["deliveryID"], 10, 64)
+	if err != nil || deliveryID <= 0 {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid delivery ID")
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(id, deliveryID)
+	if errors.Is(err, webhooks.ErrNotFound) || errors.Is(err, streaming.ErrDeliveryNotFound) {
+		h.handleError(w, err, "redelivering webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// handleError writes the response for a service error and returns false, or
+// returns true if there was none
+func (h *WebhooksHandler) handleError(w http.ResponseWriter, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, webhooks.ErrNotFound):
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Webhook not found")
+	case errors.Is(err, streaming.ErrDeliveryNotFound):
+		apierror.Write(w, http.StatusNotFound, apierror.NotFound, "Delivery not found")
+	default:
+		log.Printf("Error %s: %v", action, err)
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "Failed "+action)
+	}
+	return false
+}
+
+// webhookID parses the {id} route variable, writing a 400 if it is invalid
+func webhookID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id <= 0 {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Invalid webhook ID")
+		return 0, false
+	}
+	return id, true
+}
@@ -4,31 +4,87 @@ import (
 	"backend/config"
 	"backend/middleware"
 	"backend/repositories"
+	"backend/server/apierror"
 	"backend/server/database"
 	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/acme"
+	"backend/services/albums"
+	"backend/services/analysiscache"
+	"backend/services/avatars"
+	"backend/services/anthropic"
+	"backend/services/breaker"
+	"backend/services/budget"
+	"backend/services/canary"
+	"backend/services/chunking"
+	"backend/services/cleanmode"
+	"backend/services/comparison"
+	"backend/services/crypto"
+	"backend/services/erasure"
+	"backend/services/events"
+	"backend/services/experiments"
+	"backend/services/export"
+	"backend/services/facts"
 	"backend/services/genius"
+	"backend/services/jobqueue"
+	"backend/services/llmcache"
+	"backend/services/moderation"
 	"backend/services/mood"
-	// "backend/services/ollama"  // Uncomment when using Ollama
+	"backend/services/ollama"
 	"backend/services/openai"
+	"backend/services/pgnotify"
+	"backend/services/presence"
+	"backend/services/projections"
+	"backend/services/prompts"
+	"backend/services/quiz"
+	"backend/services/ratelimit"
+	"backend/services/redis"
+	"backend/services/realtime"
+	"backend/services/reports"
+	"backend/services/restricted"
+	"backend/services/retention"
+	"backend/services/safety"
+	"backend/services/sanitize"
+	"backend/services/scheduler"
+	"backend/services/search"
 	"backend/services/spotify"
+	"backend/services/streaming"
+	"backend/services/style"
+	"backend/services/topics"
+	"backend/services/trending"
+	"backend/services/trivia"
+	"backend/services/users"
+	"backend/services/validation"
+	"backend/services/webhooks"
+	"context"
+	"crypto/tls"
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 )
 
 func main() {
-	// Load configuration
-	cfg, err := config.Load()
+	configFile := flag.String("config", "", "path to a JSON or YAML config file (defaults to $CONFIG_FILE)")
+	flag.Parse()
+
+	// Load configuration; environment variables override the config file
+	cfg, err := config.LoadFrom(*configFile)
 	if err != nil {
 		log.Fatal("Failed to load configuration:", err)
 	}
 
 	// Initialize database
-	db, err := database.InitDB()
+	db, err := database.InitDB(cfg.Database)
 	if err != nil {
 		log.Fatal("Database connection failed:", err)
 	}
@@ -38,114 +94,1043 @@ func main() {
 	if err := setupDatabase(db); err != nil {
 		log.Fatal("Error setting up database:", err)
 	}
+	if err := setupMessageNotify(db, cfg.WebSocket.ChatFanout == "postgres"); err != nil {
+		log.Fatal("Error setting up message notifications:", err)
+	}
 
-	// Initialize services
-	geniusService := genius.New(genius.Config{
+	// Periodic background jobs, started once every service is set up
+	schedulerConfig := scheduler.DefaultConfig()
+	schedulerConfig.Jitter = cfg.Jobs.Jitter
+	schedulerConfig.Disabled = cfg.Jobs.Disabled
+	jobScheduler := scheduler.New(schedulerConfig)
+
+	// Initialize services; lyrics are cleaned up before anything caches them
+	geniusService := sanitize.NewGenius(breaker.NewGenius(genius.New(genius.Config{
 		AccessToken: cfg.Genius.AccessToken,
-	})
+	}), newBreaker(cfg, "genius", genius.ErrArtistNotFound, genius.ErrSongNotFound)), sanitize.New(sanitize.Config{
+		StripSectionMarkers: cfg.Genius.StripSectionMarkers,
+		RemoveNoise:         cfg.Genius.RemoveNoise,
+		CollapseWhitespace:  cfg.Genius.CollapseWhitespace,
+	}))
 
 	// Initialize Spotify service
-	spotifyService := spotify.New(spotify.Config{
+	spotifyService := breaker.NewSpotify(spotify.New(spotify.Config{
 		ClientID:     cfg.Spotify.ClientID,
 		ClientSecret: cfg.Spotify.ClientSecret,
-	})
+	}), newBreaker(cfg, "spotify", spotify.ErrTrackNotFound, spotify.ErrArtistNotFound, spotify.ErrAlbumNotFound))
 
-	// AI SERVICE CONFIGURATION - Comment/Uncomment to switch between providers
-	
-	// === OLLAMA CONFIGURATION (CURRENTLY COMMENTED - UNCOMMENT TO USE) ===
-	/*
-	ollamaService := ollama.New(ollama.Config{
-		BaseURL:     cfg.Ollama.BaseURL,
-		Model:       cfg.Ollama.Model,
-		Temperature: cfg.Ollama.Temperature,
-		TopP:        cfg.Ollama.TopP,
-		TopK:        cfg.Ollama.TopK,
-	})
+	// Load the AI prompt templates, replacing built-in ones with PROMPTS_DIR and
+	// PROMPTS_FILES; overrides are reloaded on SIGHUP and when they change
+	promptTemplates, err := prompts.New(prompts.Config{Dir: cfg.Prompts.Dir, Files: cfg.Prompts.Files})
+	if err != nil {
+		log.Fatal("Error loading prompt templates:", err)
+	}
+	reloadPromptsOnSignal(promptTemplates)
+	if cfg.Prompts.ReloadInterval > 0 && (cfg.Prompts.Dir != "" || len(cfg.Prompts.Files) > 0) {
+		scheduleJob(jobScheduler, "prompt-reload", cfg.Prompts.ReloadInterval, func() {
+			if reloaded, err := promptTemplates.ReloadIfChanged(); err != nil {
+				log.Printf("Failed to reload prompt templates, keeping the previous ones: %v", err)
+			} else if reloaded {
+				log.Println("Reloaded prompt templates")
+			}
+		})
+	}
 
-	// Check if Ollama is available
-	if err := ollamaService.IsAvailable(); err != nil {
-		log.Fatalf("Error connecting to Ollama: %v - Make sure Ollama is running with 'ollama serve'", err)
+	// Initialize the AI provider selected by AI_PROVIDER
+	aiService, err := newAIService(cfg, promptTemplates)
+	if err != nil {
+		log.Fatal("Error configuring AI service:", err)
+	}
+
+	// Check if the AI provider is available
+	if err := aiService.IsAvailable(); err != nil {
+		log.Fatalf("Error connecting to AI provider %s: %v", cfg.AI.Provider, err)
+	}
+	log.Printf("Successfully connected to AI provider: %s", cfg.AI.Provider)
+
+	// Fail fast while the AI provider is down; exhausted budgets and
+	// disconnected stream clients don't count as provider failures
+	aiBreaker := newBreaker(cfg, "ai", budget.ErrBudgetExceeded, context.Canceled)
+
+	// Charge chat requests to the caller's token budget when budgets are enabled
+	var aiForUser func(userID string) handlers.AIService
+	if tokenBudget, ok := aiService.(budget.Service); ok {
+		aiForUser = func(userID string) handlers.AIService {
+			return breaker.NewAI(tokenBudget.ForUser(userID), aiBreaker)
+		}
+	}
+	aiService = breaker.NewAI(aiService, aiBreaker)
+
+	// Serve repeated prompts from cache
+	if cfg.AI.CacheSize > 0 {
+		cache := llmcache.New(aiService, llmcache.Config{
+			Model:      cfg.AI.Provider + "/" + activeModel(cfg),
+			TTL:        cfg.AI.CacheTTL,
+			MaxEntries: cfg.AI.CacheSize,
+		})
+		aiService = cache
+		if forUser := aiForUser; forUser != nil {
+			aiForUser = func(userID string) handlers.AIService {
+				return cache.Wrap(forUser(userID))
+			}
+		}
 	}
-	log.Println("Successfully connected to Ollama server")
-	*/
-	
-	// === OPENAI CONFIGURATION (CURRENTLY ACTIVE - COMMENT TO DISABLE) ===
-	openaiService := openai.New(openai.Config{
-		APIKey:      cfg.OpenAI.APIKey,
-		Model:       cfg.OpenAI.Model,
-		BaseURL:     cfg.OpenAI.BaseURL,
-		Temperature: cfg.OpenAI.Temperature,
-		MaxTokens:   cfg.OpenAI.MaxTokens,
-		TopP:        cfg.OpenAI.TopP,
-	})
 
-	// Check if OpenAI is available
-	if err := openaiService.IsAvailable(); err != nil {
-		log.Fatalf("Error connecting to OpenAI: %v - Make sure OPENAI_API_KEY is set", err)
+	// Condense lyrics too long for the model's context window
+	if cfg.AI.LyricsTokenBudget > 0 {
+		chunker := chunking.New(aiService, chunking.Config{
+			TokenBudget: cfg.AI.LyricsTokenBudget,
+			ChunkTokens: cfg.AI.LyricsChunkTokens,
+			Parallelism: chunking.DefaultConfig().Parallelism,
+		})
+		aiService = chunker
+		if forUser := aiForUser; forUser != nil {
+			aiForUser = func(userID string) handlers.AIService {
+				return chunker.Wrap(forUser(userID))
+			}
+		}
 	}
-	log.Println("Successfully connected to OpenAI API")
 
 	// Initialize mood service with data directory
 	dataDir := "./data" // You can make this configurable
-	// Choose which AI service to use for mood service - comment/uncomment accordingly
-	// moodService := mood.New(geniusService, ollamaService, dataDir)  // Use Ollama
-	moodService := mood.New(geniusService, openaiService, dataDir)  // Use OpenAI
+	moodService := mood.New(geniusService, aiService, dataDir, promptTemplates)
+	if len(cfg.Encryption.MasterKey) > 0 {
+		// Encrypt mood history with per-user keys so the files alone don't reveal it
+		cryptoService, err := crypto.New(crypto.Config{MasterKey: cfg.Encryption.MasterKey})
+		if err != nil {
+			log.Fatal("Failed to initialize encryption:", err)
+		}
+		moodService = mood.WithEncryption(moodService, cryptoService)
+		log.Println("Mood history encryption enabled")
+	}
 
 	// Initialize repositories
 	musicRepo := repositories.NewMusicRepository(geniusService)
+	musicRepo.SetSourcePolicy(repositories.SourcePolicy{
+		MinDwell:   cfg.NowPlaying.MinDwell,
+		Priorities: cfg.NowPlaying.SourcePriorities,
+	})
+	musicRepo.SetScrobblePolicy(repositories.ScrobblePolicy{
+		Fraction:  cfg.History.ScrobbleFraction,
+		MaxListen: cfg.History.ScrobbleAfter,
+	})
+	var stateClient *redis.Client
+	if cfg.State.Backend == "redis" {
+		if stateClient, err = redis.New(cfg.State.RedisURL); err != nil {
+			log.Fatalf("Failed to set up Redis state store: %v", err)
+		}
+	}
+	switch {
+	case cfg.History.Backend == "postgres":
+		musicRepo.SetHistoryStore(repositories.NewPostgresHistoryStore(db, cfg.History.Size, cfg.History.RepeatWindow))
+	case stateClient != nil:
+		musicRepo.SetHistoryStore(repositories.NewRedisHistoryStore(stateClient, cfg.State.KeyPrefix, cfg.History.Size, cfg.History.RepeatWindow))
+	default:
+		musicRepo.SetHistoryStore(repositories.NewMemoryHistoryStore(cfg.History.Size, cfg.History.RepeatWindow))
+	}
+	musicRepo.SetLyricsCacheLimits(cfg.Genius.LyricsCacheMax, cfg.Genius.LyricsCacheTTL)
+	if stateClient != nil {
+		musicRepo.ShareNowPlaying(repositories.NewRedisNowPlayingStore(stateClient, cfg.State.KeyPrefix))
+		log.Printf("Sharing now playing and play history through Redis")
+	} else if cfg.NowPlaying.RestoreWithin > 0 {
+		restored, err := musicRepo.RestoreNowPlaying(repositories.NewPostgresNowPlayingStore(db), cfg.NowPlaying.RestoreWithin)
+		if err != nil {
+			log.Printf("Warning: failed to restore now playing: %v", err)
+		} else if restored {
+			log.Printf("Restored now playing: %s", musicRepo.GetCurrentSongInfo())
+		}
+	}
+	if cfg.NowPlaying.StaleAfter > 0 {
+		musicRepo.StartStaleExpiry(context.Background(), cfg.NowPlaying.StaleAfter)
+	}
+
+	// Side effects of track changes, moods, messages and recommendations are
+	// handled by event bus subscribers
+	eventBus := events.New(events.DefaultConfig())
+	musicRepo.AddTrackListener(func(track models.UnifiedTrack) {
+		eventBus.Publish(events.TrackChanged, track)
+	})
+	subscribeAnalytics(eventBus)
+
+	// Mirror events to NATS or Kafka for external consumers, retrying failures
+	// and keeping recent deliveries for the admin dashboard
+	var deliveryLog streaming.DeliveryLog
+	if cfg.Events.StreamBackend != "" {
+		publisher, err := streaming.New(streaming.Config{
+			Backend: cfg.Events.StreamBackend,
+			URL:     cfg.Events.StreamURL,
+		})
+		if err != nil {
+			log.Fatal("Error configuring event stream:", err)
+		}
+		deliveryConfig := streaming.DefaultDeliveryLogConfig()
+		deliveryConfig.Backend = cfg.Events.StreamBackend
+		deliveryConfig.Size = cfg.Events.DeliveryLogSize
+		deliveryConfig.MaxAttempts = cfg.Events.MaxAttempts
+		deliveryLog = streaming.NewDeliveryLog(publisher, deliveryConfig)
+		defer deliveryLog.Close()
+		streaming.Mirror(eventBus, deliveryLog, cfg.Events.StreamTopicPrefix)
+		log.Printf("Mirroring events to %s at %s", cfg.Events.StreamBackend, cfg.Events.StreamURL)
+	}
+
+	// Initialize handlers
+	lyricsHandler := handlers.NewLyricsHandler(musicRepo, aiService, moodService, spotifyService)
+	if aiForUser != nil {
+		lyricsHandler.SetUserAIService(aiForUser)
+	}
+	lyricsHandler.SetFacts(facts.New(geniusService, aiService, promptTemplates, facts.Config{
+		CacheTTL:   cfg.Genius.FactsCacheTTL,
+		MaxEntries: cfg.Genius.FactsCacheMax,
+	}))
+	lyricsHandler.SetComparison(comparison.New(moodService, promptTemplates))
+	albumsConfig := albums.DefaultConfig()
+	albumsConfig.CacheTTL = cfg.Genius.AlbumCacheTTL
+	albumsConfig.MaxEntries = cfg.Genius.AlbumCacheMax
+	lyricsHandler.SetAlbums(albums.New(spotifyService, moodService, aiService, promptTemplates, albumsConfig))
+	if cfg.AI.AnalysisCacheTTL > 0 {
+		analyses := analysiscache.New(analysiscache.NewPostgresStore(db), promptTemplates, analysiscache.Config{
+			TTL:               cfg.AI.AnalysisCacheTTL,
+			RejectAfter:       cfg.AI.AnalysisRejectAfter,
+			ReanalyzeInterval: cfg.AI.AnalysisReanalyzeInterval,
+		})
+		lyricsHandler.SetAnalysisCache(analyses)
+		scheduleJob(jobScheduler, "analysis-cache-cleanup", 24*time.Hour, func() {
+			if deleted, err := analyses.Purge(); err != nil {
+				log.Printf("Analysis cache: failed to delete expired answers: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Analysis cache: deleted %d expired answers", deleted)
+			}
+		})
+	}
+	lyricsHandler.SetTrivia(trivia.New(promptTemplates, trivia.Config{
+		Questions:  cfg.Trivia.Questions,
+		SessionTTL: cfg.Trivia.SessionTTL,
+	}))
+	lyricsHandler.SetEventBus(eventBus)
 
-	// Initialize handlers - choose which AI service to use
-	// lyricsHandler := handlers.NewLyricsHandler(musicRepo, ollamaService, moodService, spotifyService)  // Use Ollama
-	lyricsHandler := handlers.NewLyricsHandler(musicRepo, openaiService, moodService, spotifyService)  // Use OpenAI
+	// Curated mood suggestions live in the database, seeded with the built-in ones
+	moodCatalog, err := repositories.NewStoredMoodCatalog(repositories.NewPostgresMoodSuggestionStore(db), repositories.DefaultMoodCatalog())
+	if err != nil {
+		log.Fatalf("Failed to load mood catalog: %v", err)
+	}
+	lyricsHandler.SetMoodCatalog(moodCatalog)
+	moodCatalogHandler := handlers.NewMoodCatalogHandler(moodCatalog)
 	chatHandler := handlers.NewChatHandler(db)
+	chatHandler.SetEventBus(eventBus)
+
+	// User profiles, registered on each user's first request with an API key
+	usersService := users.New(users.NewPostgresStore(db))
+	chatHandler.SetUsers(usersService)
+	// Chat queries no longer matching the schema fail startup, not their first request
+	if err := chatHandler.Prepare(); err != nil {
+		log.Fatalf("Failed to prepare chat queries: %v", err)
+	}
+	usersHandler := handlers.NewUsersHandler(usersService)
+	avatarStorage, err := newAvatarStorage(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up avatar storage: %v", err)
+	}
+	avatarService := avatars.New(avatarStorage, avatars.Config{MaxBytes: int64(cfg.Avatar.MaxBytes)})
+	usersHandler.SetAvatars(avatarService)
+
+	// Restricted (parental/teen) mode, for the whole deployment or per user
+	restrictionsService := restricted.New(restricted.Config{
+		Deployment: cfg.Restricted.Deployment,
+		Users:      cfg.Restricted.Users,
+	})
+	lyricsHandler.SetRestrictions(restrictionsService)
+
+	// Clean mode, per user or per request
+	cleanModeService := cleanmode.New(cleanmode.Config{
+		Users: cfg.CleanMode.Users,
+		Words: cfg.CleanMode.Words,
+	})
+	lyricsHandler.SetCleanMode(cleanModeService)
+	cleanModeHandler := handlers.NewCleanModeHandler(cleanModeService)
+
+	// Deployment branding and replacement canned answers
+	lyricsHandler.SetCustomization(handlers.Customization{
+		AssistantName: cfg.Customize.AssistantName,
+		Responses:     cfg.Customize.Responses,
+	})
+	brandingHandler := handlers.NewBrandingHandler(models.Branding{
+		AssistantName: cfg.Customize.AssistantName,
+		Tagline:       cfg.Customize.Tagline,
+		LogoURL:       cfg.Customize.LogoURL,
+		PrimaryColor:  cfg.Customize.PrimaryColor,
+	})
+	chatHandler.SetRestrictions(restrictionsService)
+
+	// Screen chat messages for hate and harassment, keeping verdicts for review
+	if classifier := newModerationClassifier(cfg); classifier != nil {
+		chatHandler.SetModeration(moderation.New(classifier, moderation.NewPostgresStore(db), moderation.Config{
+			Categories:      cfg.Moderation.Categories,
+			FlagThreshold:   cfg.Moderation.FlagThreshold,
+			RejectThreshold: cfg.Moderation.RejectThreshold,
+		}))
+	}
+
+	// Offer helplines instead of only songs when a message indicates a crisis
+	helplines, err := safety.ParseHelplines(cfg.Safety.Helplines)
+	if err != nil {
+		log.Fatalf("Invalid SAFETY_HELPLINES: %v", err)
+	}
+	lyricsHandler.SetSafety(safety.New(safety.Config{
+		Helplines:     helplines,
+		DefaultRegion: cfg.Safety.DefaultRegion,
+	}))
+	restrictionsHandler := handlers.NewRestrictionsHandler(restrictionsService)
+	artistsHandler := handlers.NewArtistsHandler(geniusService, spotifyService)
+	artistsHandler.SetRestrictions(restrictionsService)
+	artistsHandler.SetCleanMode(cleanModeService)
+	styleConfig := style.DefaultConfig()
+	styleConfig.CacheTTL = cfg.Genius.StyleCacheTTL
+	styleConfig.MaxEntries = cfg.Genius.StyleCacheMax
+	artistsHandler.SetStyle(style.New(spotifyService, geniusService, aiService, promptTemplates, styleConfig))
+
+	// Try prompt variants on users when PROMPT_EXPERIMENTS is set
+	var promptExperiments experiments.Service
+	if len(cfg.Prompts.Experiments) > 0 {
+		variants := make(map[string][]experiments.Variant, len(cfg.Prompts.Experiments))
+		for template, arms := range cfg.Prompts.Experiments {
+			for _, arm := range arms {
+				variants[template] = append(variants[template], experiments.Variant{Name: arm.Name, Weight: arm.Weight})
+			}
+		}
+		promptExperiments, err = experiments.New(experiments.NewPostgresStore(db), variants, promptTemplates)
+		if err != nil {
+			log.Fatalf("Invalid PROMPT_EXPERIMENTS: %v", err)
+		}
+		lyricsHandler.SetExperiments(promptExperiments, promptTemplates)
+	}
+	experimentsHandler := handlers.NewExperimentsHandler(promptExperiments)
+
+	// Index the curated catalog and every played track for autocomplete
+	searchService := search.New()
+	for _, track := range lyricsHandler.MoodCatalog().Tracks() {
+		searchService.Add(track)
+	}
+	eventBus.Subscribe(events.TrackChanged, "search-index", func(event events.Event) error {
+		searchService.Add(event.Payload.(models.UnifiedTrack))
+		return nil
+	})
+	searchHandler := handlers.NewSearchHandler(searchService)
+
+	// Keep daily per-user aggregates up to date so stats never scan history
+	projectionService := projections.New(projections.NewPostgresStore(db), projections.DefaultConfig())
+	projectionService.Register(eventBus)
+	statsHandler := handlers.NewStatsHandler(projectionService)
+
+	// Rank tracks by plays over a sliding window as they change
+	trendingService := trending.New(trending.Config{
+		Window:  cfg.Trending.Window,
+		Buckets: cfg.Trending.Buckets,
+	})
+	eventBus.Subscribe(events.TrackChanged, "trending", func(event events.Event) error {
+		trendingService.Record(event.Payload.(models.UnifiedTrack), event.Time)
+		return nil
+	})
+	trendingHandler := handlers.NewTrendingHandler(trendingService)
+
+	// Periodically check curated Spotify IDs against the live API
+	validationService := validation.New(spotifyService, lyricsHandler.MoodCatalog())
+	scheduleJob(jobScheduler, "catalog-validation", cfg.Jobs.CatalogValidationInterval, func() { validationService.Run() })
+	catalogHandler := handlers.NewCatalogHandler(validationService)
+
+	// Archive and delete raw history past its retention period
+	var archiveSink retention.Sink = retention.NewDirSink(cfg.Retention.ArchiveDir)
+	if cfg.Retention.ArchiveURL != "" {
+		archiveSink = retention.NewHTTPSink(cfg.Retention.ArchiveURL, cfg.Retention.ArchiveToken)
+	}
+	retentionService := retention.New(db, archiveSink, retention.Config{
+		DataDir:              dataDir,
+		MoodHistoryRetention: cfg.Retention.MoodHistory,
+		MessageRetention:     cfg.Retention.Messages,
+	})
+	scheduleJob(jobScheduler, "retention", cfg.Retention.Interval, func() { retentionService.Run() })
+
+	// Cluster recent chat into topics for the community page, optionally
+	// posting a digest to chat
+	topicsConfig := topics.DefaultConfig()
+	topicsConfig.Window = cfg.Topics.Window
+	topicsConfig.MinClusterSize = cfg.Topics.MinClusterSize
+	topicsConfig.DigestUsername = cfg.Customize.AssistantName
+	embedder := newEmbedder(cfg)
+	if embedder.Name() == "local" {
+		topicsConfig.Similarity = topics.LocalSimilarity
+	}
+	if cfg.Topics.Similarity > 0 {
+		topicsConfig.Similarity = cfg.Topics.Similarity
+	}
+	var digestPoster topics.Poster
+	if cfg.Topics.Digest {
+		digestPoster = topics.PosterFunc(func(text string) error {
+			return chatHandler.PostSystemMessage(topicsConfig.DigestUsername, text)
+		})
+	}
+	topicsService := topics.New(topics.NewPostgresSource(db), embedder, aiService, digestPoster, topicsConfig)
+	scheduleJob(jobScheduler, "community-topics", cfg.Topics.Interval, func() { topicsService.Run() })
+	communityHandler := handlers.NewCommunityHandler(topicsService)
+	log.Printf("Detecting community topics with %s embeddings", embedder.Name())
+
+	// Summarize each user's moods and listening once a week
+	reportsService := reports.New(moodService, musicRepo, aiService)
+	scheduleJob(jobScheduler, "weekly-reports", cfg.Jobs.WeeklyReportsInterval, func() { reportsService.Run() })
+	reportsHandler := handlers.NewReportsHandler(reportsService)
+
+	// Run bulk mood analyses on background workers, keeping the jobs in the database
+	queueConfig := jobqueue.DefaultConfig()
+	queueConfig.Workers = cfg.Jobs.QueueWorkers
+	queueConfig.MaxAttempts = cfg.Jobs.QueueMaxAttempts
+	queueConfig.Retention = cfg.Jobs.QueueRetention
+	jobQueue := jobqueue.New(jobqueue.NewPostgresStore(db), queueConfig)
+	jobQueue.Handle(models.JobTypeTrackMoods, lyricsHandler.TrackMoodsJob)
+	jobQueue.Handle(models.JobTypeAlbumAnalysis, lyricsHandler.AlbumAnalysisJob)
+	jobQueue.Handle(models.JobTypeDataDeletion, usersHandler.DataDeletionJob)
+	jobQueue.Handle(models.JobTypeDataExport, usersHandler.DataExportJob)
+	if err := jobQueue.Start(context.Background()); err != nil {
+		log.Fatalf("Failed to start job queue: %v", err)
+	}
+	lyricsHandler.SetJobQueue(jobQueue)
+
+	// Delete and export users' data on request, on the job queue
+	usersHandler.SetJobQueue(jobQueue)
+	usersHandler.SetDataDeletion(erasure.New(db, usersService, avatarService, erasure.Config{DataDir: dataDir}, reportsService))
+	exportConfig := export.DefaultConfig()
+	exportConfig.Dir = cfg.Export.Dir
+	exportConfig.TTL = cfg.Export.TTL
+	exportService := export.New(db, usersService, moodService, musicRepo, exportConfig)
+	usersHandler.SetDataExport(exportService)
+	scheduleJob(jobScheduler, "export-cleanup", time.Hour, func() {
+		if deleted, err := exportService.Purge(); err != nil {
+			log.Printf("Exports: failed to delete expired exports: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Exports: deleted %d expired exports", deleted)
+		}
+	})
+	scheduleJob(jobScheduler, "job-queue-cleanup", 24*time.Hour, func() {
+		if deleted, err := jobQueue.Purge(); err != nil {
+			log.Printf("Job queue: failed to delete finished jobs: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Job queue: deleted %d finished jobs", deleted)
+		}
+	})
+	queueHandler := handlers.NewQueueHandler(jobQueue)
+
+	// Writes that change shared state need an API key from config or the api_keys table
+	if len(cfg.Auth.APIKeys) == 0 {
+		log.Println("Warning: API_KEYS is empty; write endpoints only accept keys from the api_keys table")
+	}
+	keyStores := []middleware.KeyStore{middleware.StaticKeys(cfg.Auth.APIKeys), repositories.NewAPIKeyRepository(db)}
+	apiKey := middleware.APIKey(keyStores...)
+	registerUsers := middleware.RegisterUsers(usersService)
+	requireAPIKey := func(next http.Handler) http.Handler {
+		return apiKey(registerUsers(next))
+	}
+
+	// Limit requests per user with an API key, or else per IP; Redis shares
+	// the limits across instances
+	rateLimits, err := ratelimit.ParseRules(cfg.RateLimit.Rules)
+	if err != nil {
+		log.Fatalf("Invalid RATE_LIMITS: %v", err)
+	}
+	limiter := ratelimit.NewMemory()
+	if cfg.RateLimit.Backend == "redis" {
+		if limiter, err = ratelimit.NewRedis(cfg.RateLimit.RedisURL, cfg.RateLimit.KeyPrefix); err != nil {
+			log.Fatalf("Failed to set up Redis rate limiter: %v", err)
+		}
+	}
+	log.Printf("Rate limiting %d routes with the %s backend", len(rateLimits), cfg.RateLimit.Backend)
+
+	deliveriesHandler := handlers.NewDeliveriesHandler(deliveryLog)
+
+	// Push track changes and chat messages to WebSocket clients
+	realtimeConfig := realtime.DefaultConfig()
+	realtimeConfig.AllowedOrigins = cfg.Server.AllowedOrigins
+	realtimeConfig.MaxMessageBytes = int64(cfg.WebSocket.MaxMessageBytes)
+	realtimeConfig.MessagesPerMinute = cfg.WebSocket.MessagesPerMinute
+	realtimeConfig.PingInterval = cfg.WebSocket.PingInterval
+	realtimeConfig.TypingTimeout = cfg.WebSocket.TypingTimeout
+	realtimeHandler := handlers.NewRealtimeHandler(realtime.NewHub(), realtimeConfig, musicRepo, chatHandler)
+	// Postgres announces every instance's messages, so chat clients see them all
+	if cfg.WebSocket.ChatFanout == "postgres" {
+		listener := pgnotify.New(cfg.GetDatabaseURL())
+		defer listener.Close()
+		if err := realtimeHandler.ListenForMessages(listener); err != nil {
+			log.Fatalf("Failed to listen for chat messages: %v", err)
+		}
+		log.Printf("Fanning out chat messages, reactions and removals through Postgres LISTEN/NOTIFY")
+	}
+	realtimeHandler.Subscribe(eventBus)
+
+	// Who is online, from heartbeats and open chat sockets
+	presenceConfig := presence.DefaultConfig()
+	presenceConfig.Timeout = cfg.Presence.Timeout
+	presenceService := presence.New(presenceConfig)
+	realtimeHandler.SetPresence(presenceService)
+	presenceHandler := handlers.NewPresenceHandler(presenceService)
+
+	// Lyrics quiz games over recently played songs, played over WebSocket
+	quizConfig := quiz.DefaultConfig()
+	quizConfig.Rounds = cfg.Quiz.Rounds
+	quizConfig.AnswerTime = cfg.Quiz.AnswerTime
+	quizConfig.RevealTime = cfg.Quiz.RevealTime
+	quizService := quiz.New(musicRepo, geniusService, quiz.NewPostgresStore(db), eventBus, quizConfig)
+	realtimeHandler.SetQuizService(quizService)
+	quizHandler := handlers.NewQuizHandler(quizService)
+
+	// POST track changes and mood detections to registered webhooks
+	webhookConfig := webhooks.DefaultConfig()
+	webhookConfig.MaxAttempts = cfg.Webhooks.MaxAttempts
+	webhookConfig.Timeout = cfg.Webhooks.Timeout
+	webhookConfig.DeliveryLogSize = cfg.Webhooks.DeliveryLogSize
+	webhookService := webhooks.New(webhooks.NewPostgresStore(db), eventBus, webhookConfig)
+	webhooksHandler := handlers.NewWebhooksHandler(webhookService)
+
+	// Compare candidate AI configurations with the live one before promoting them
+	prices := make(map[string]canary.Price, len(cfg.AI.Prices))
+	for model, price := range cfg.AI.Prices {
+		prices[model] = canary.Price{Input: price.Input, Output: price.Output}
+	}
+	canaryService := canary.New(canaryFactory(cfg, promptTemplates), canary.Config{
+		Live:    liveCanaryConfig(cfg),
+		Prices:  prices,
+		DataDir: dataDir,
+		Prompts: promptTemplates,
+	})
+	canaryHandler := handlers.NewCanaryHandler(canaryService)
+
+	jobScheduler.Start(context.Background())
+	jobsHandler := handlers.NewJobsHandler(jobScheduler)
+	metricsHandler := handlers.NewMetricsHandler(db.Stats)
 
 	// Setup routes
-	router := setupRoutes(lyricsHandler, chatHandler)
+	router := setupRoutes(lyricsHandler, chatHandler, searchHandler, catalogHandler, statsHandler, trendingHandler, restrictionsHandler, cleanModeHandler, artistsHandler, deliveriesHandler, canaryHandler, realtimeHandler, communityHandler, quizHandler, webhooksHandler, brandingHandler, moodCatalogHandler, reportsHandler, jobsHandler, queueHandler, experimentsHandler, presenceHandler, usersHandler, metricsHandler, requireAPIKey)
 
 	// Apply middleware
-	handler := middleware.Recovery(middleware.Logging(router))
+	handler := middleware.Recovery(middleware.Logging(middleware.RateLimit(limiter, rateLimits, keyStores...)(router)))
 
 	// Setup CORS
 	c := cors.New(cors.Options{
-		AllowedOrigins: []string{"http://localhost:3000", "http://127.0.0.1:3000"},
-		AllowedMethods: []string{"GET", "POST", "OPTIONS"},
-		AllowedHeaders: []string{"Content-Type", "Authorization"},
-	})
-
-	// Start server
-	addr := fmt.Sprintf(":%s", cfg.Server.Port)
-	log.Printf("Server starting on %s", addr)
-	
-	// AI Service instructions based on current configuration
-	log.Printf("AI Service: OpenAI API (gpt-3.5-turbo)")
-	log.Printf("Make sure OPENAI_API_KEY environment variable is set")
-	
-	// Uncomment these when using Ollama:
-	// log.Printf("AI Service: Ollama (%s)", cfg.Ollama.Model) 
-	// log.Printf("Make sure Ollama is running: ollama serve")
-	// log.Printf("Make sure you have the model: ollama pull %s", cfg.Ollama.Model)
-	
-	if err := http.ListenAndServe(addr, c.Handler(handler)); err != nil {
+		AllowedOrigins: cfg.Server.AllowedOrigins,
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "If-Match", "X-User-ID", "X-API-Key", "X-Spotify-Token"},
+		ExposedHeaders: []string{"ETag", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-Next-Cursor", "Link"},
+	})
+
+	// Start server; the timeouts keep slow or idle clients from holding connections
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%s", cfg.Server.Port),
+		Handler:           c.Handler(handler),
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+
+	if !cfg.TLS.Enabled() {
+		log.Printf("Server starting on %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil {
+			log.Fatal("Server failed to start:", err)
+		}
+		return
+	}
+
+	// Serve HTTPS directly, redirecting plain HTTP and answering ACME
+	// challenges on the redirect port
+	server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	redirect := middleware.RedirectHTTPS(cfg.Server.Port)
+	if len(cfg.TLS.AutocertHosts) > 0 {
+		acmeConfig := acme.DefaultConfig()
+		acmeConfig.DirectoryURL = cfg.TLS.AutocertURL
+		acmeConfig.Email = cfg.TLS.AutocertEmail
+		acmeConfig.Hosts = cfg.TLS.AutocertHosts
+		acmeConfig.CacheDir = cfg.TLS.AutocertCacheDir
+		certManager, err := acme.New(acmeConfig)
+		if err != nil {
+			log.Fatal("Failed to set up automatic certificates:", err)
+		}
+		server.TLSConfig.GetCertificate = certManager.GetCertificate
+		redirect = certManager.HTTPHandler(redirect)
+		certManager.Start(context.Background())
+		log.Printf("Obtaining certificates for %s from %s", strings.Join(cfg.TLS.AutocertHosts, ", "), cfg.TLS.AutocertURL)
+	}
+	if cfg.TLS.RedirectPort != "" {
+		redirectServer := &http.Server{
+			Addr:              fmt.Sprintf(":%s", cfg.TLS.RedirectPort),
+			Handler:           redirect,
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+			IdleTimeout:       cfg.Server.IdleTimeout,
+		}
+		go func() {
+			log.Printf("Redirecting HTTP on %s to HTTPS", redirectServer.Addr)
+			if err := redirectServer.ListenAndServe(); err != nil {
+				log.Fatal("HTTP redirect server failed:", err)
+			}
+		}()
+	}
+
+	log.Printf("Server starting with TLS on %s", server.Addr)
+	if err := server.ListenAndServeTLS(cfg.TLS.CertFile, cfg.TLS.KeyFile); err != nil {
 		log.Fatal("Server failed to start:", err)
 	}
 }
 
+// newAIService creates the AI provider selected in the configuration
+func newAIService(cfg *config.Config, templates prompts.Service) (handlers.AIService, error) {
+	switch cfg.AI.Provider {
+	case "openai":
+		log.Printf("AI Service: OpenAI API (%s)", cfg.OpenAI.Model)
+		return withTokenBudget(cfg, openai.New(openai.Config{
+			APIKey:      cfg.OpenAI.APIKey,
+			Model:       cfg.OpenAI.Model,
+			BaseURL:     cfg.OpenAI.BaseURL,
+			Temperature: cfg.OpenAI.Temperature,
+			MaxTokens:   cfg.OpenAI.MaxTokens,
+			TopP:        cfg.OpenAI.TopP,
+
+			MaxRetries:     cfg.OpenAI.MaxRetries,
+			RetryBaseDelay: cfg.OpenAI.RetryBaseDelay,
+			RetryMaxDelay:  cfg.OpenAI.RetryMaxDelay,
+
+			Prompts: templates,
+		}), templates)
+	case "azure":
+		log.Printf("AI Service: Azure OpenAI (deployment %s)", cfg.Azure.Deployment)
+		return withTokenBudget(cfg, openai.New(openai.Config{
+			APIKey:      cfg.Azure.APIKey,
+			Model:       cfg.OpenAI.Model,
+			BaseURL:     cfg.Azure.Endpoint,
+			Temperature: cfg.OpenAI.Temperature,
+			MaxTokens:   cfg.OpenAI.MaxTokens,
+			TopP:        cfg.OpenAI.TopP,
+			APIType:     openai.APITypeAzure,
+			Deployment:  cfg.Azure.Deployment,
+			APIVersion:  cfg.Azure.APIVersion,
+
+			MaxRetries:     cfg.OpenAI.MaxRetries,
+			RetryBaseDelay: cfg.OpenAI.RetryBaseDelay,
+			RetryMaxDelay:  cfg.OpenAI.RetryMaxDelay,
+
+			Prompts: templates,
+		}), templates)
+	case "ollama":
+		log.Printf("AI Service: Ollama (%s) - make sure Ollama is running: ollama serve", cfg.Ollama.Model)
+		return newOllamaService(cfg, templates), nil
+	case "anthropic":
+		log.Printf("AI Service: Anthropic Claude (%s)", cfg.Anthropic.Model)
+		return anthropic.New(anthropic.Config{
+			APIKey:      cfg.Anthropic.APIKey,
+			Model:       cfg.Anthropic.Model,
+			BaseURL:     cfg.Anthropic.BaseURL,
+			Temperature: cfg.Anthropic.Temperature,
+			MaxTokens:   cfg.Anthropic.MaxTokens,
+			TopP:        cfg.Anthropic.TopP,
+			Prompts:     templates,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q (expected openai, azure, ollama or anthropic)", cfg.AI.Provider)
+	}
+}
+
+// subscribeAnalytics logs usage events for analytics. Detected moods are
+// left out, so the logs don't reveal mood history that may be encrypted.
+func subscribeAnalytics(eventBus events.Bus) {
+	eventBus.Subscribe(events.MoodDetected, "analytics", func(event events.Event) error {
+		payload := event.Payload.(events.MoodDetectedPayload)
+		log.Printf("Analytics: detected a mood for user %s", payload.UserID)
+		return nil
+	})
+	eventBus.Subscribe(events.RecommendationServed, "analytics", func(event events.Event) error {
+		payload := event.Payload.(events.RecommendationServedPayload)
+		log.Printf("Analytics: served %d %s tracks to user %s", len(payload.Tracks), payload.Kind, payload.UserID)
+		return nil
+	})
+}
+
+// reloadPromptsOnSignal reloads the prompt templates whenever the process
+// receives SIGHUP
+func reloadPromptsOnSignal(templates prompts.Service) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			if err := templates.Reload(); err != nil {
+				log.Printf("Failed to reload prompt templates, keeping the previous ones: %v", err)
+				continue
+			}
+			log.Println("Reloaded prompt templates")
+		}
+	}()
+}
+
+// scheduleJob registers run with the scheduler under name
+func scheduleJob(jobScheduler scheduler.Service, name string, interval time.Duration, run func()) {
+	err := jobScheduler.Register(scheduler.Job{
+		Name:     name,
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			run()
+			return nil
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to schedule %s: %v", name, err)
+	}
+}
+
+// newBreaker creates a circuit breaker for the named service. Errors in ignore
+// are expected outcomes and don't count as failures.
+func newBreaker(cfg *config.Config, name string, ignore ...error) *breaker.Breaker {
+	breakerConfig := breaker.DefaultConfig(name)
+	breakerConfig.FailureThreshold = cfg.Breaker.FailureThreshold
+	breakerConfig.OpenTimeout = cfg.Breaker.OpenTimeout
+	breakerConfig.IgnoreErrors = ignore
+	return breaker.New(breakerConfig)
+}
+
+// newOllamaService creates an Ollama service from the configuration
+func newOllamaService(cfg *config.Config, templates prompts.Service) ollama.Service {
+	return ollama.New(ollama.Config{
+		BaseURL:     cfg.Ollama.BaseURL,
+		Model:       cfg.Ollama.Model,
+		Temperature: cfg.Ollama.Temperature,
+		TopP:        cfg.Ollama.TopP,
+		TopK:        cfg.Ollama.TopK,
+		Prompts:     templates,
+	})
+}
+
+// withTokenBudget wraps an OpenAI service with daily token budgets if any are configured
+func withTokenBudget(cfg *config.Config, service openai.Service, templates prompts.Service) (handlers.AIService, error) {
+	if cfg.OpenAI.DailyTokenBudget <= 0 && cfg.OpenAI.UserDailyTokenBudget <= 0 {
+		return service, nil
+	}
+
+	var fallback budget.AIService
+	switch cfg.OpenAI.BudgetFallback {
+	case "ollama":
+		fallback = newOllamaService(cfg, templates)
+	case "none", "":
+	default:
+		return nil, fmt.Errorf("unknown OPENAI_BUDGET_FALLBACK %q (expected ollama or none)", cfg.OpenAI.BudgetFallback)
+	}
+
+	log.Printf("OpenAI token budgets: %d tokens/day overall, %d tokens/day per user (fallback: %s)",
+		cfg.OpenAI.DailyTokenBudget, cfg.OpenAI.UserDailyTokenBudget, cfg.OpenAI.BudgetFallback)
+	return budget.New(service, fallback, budget.Config{
+		GlobalDailyTokens: cfg.OpenAI.DailyTokenBudget,
+		UserDailyTokens:   cfg.OpenAI.UserDailyTokenBudget,
+	}), nil
+}
+
+// newEmbedder creates the embedder for community topics. Without a choice in
+// TOPICS_EMBEDDER it follows the AI provider, using local embeddings for
+// providers without an embeddings API.
+func newEmbedder(cfg *config.Config) topics.Embedder {
+	kind := cfg.Topics.Embedder
+	if kind == "" {
+		switch cfg.AI.Provider {
+		case "openai", "ollama":
+			kind = cfg.AI.Provider
+		default:
+			kind = "local"
+		}
+	}
+
+	switch kind {
+	case "openai":
+		model := cfg.Topics.EmbeddingModel
+		if model == "" {
+			model = "text-embedding-3-small"
+		}
+		return topics.NewOpenAIEmbedder(cfg.OpenAI.BaseURL, cfg.OpenAI.APIKey, model)
+	case "ollama":
+		model := cfg.Topics.EmbeddingModel
+		if model == "" {
+			model = "nomic-embed-text"
+		}
+		return topics.NewOllamaEmbedder(cfg.Ollama.BaseURL, model)
+	default:
+		return topics.NewHashingEmbedder(512)
+	}
+}
+
+// newModerationClassifier creates the classifier for chat moderation, or nil
+// if it is off. Without a choice in MODERATION_CLASSIFIER it uses the OpenAI
+// moderation API when OpenAI is the AI provider, and the local classifier
+// otherwise.
+func newModerationClassifier(cfg *config.Config) moderation.Classifier {
+	kind := cfg.Moderation.Classifier
+	if kind == "" {
+		kind = "local"
+		if cfg.AI.Provider == "openai" {
+			kind = "openai"
+		}
+	}
+
+	switch kind {
+	case "off":
+		return nil
+	case "openai":
+		return moderation.NewOpenAIClassifier(cfg.OpenAI.BaseURL, cfg.OpenAI.APIKey, cfg.Moderation.Model)
+	default:
+		return moderation.NewLocalClassifier()
+	}
+}
+
+// newAvatarStorage creates the storage of uploaded avatars chosen in
+// AVATAR_STORAGE
+func newAvatarStorage(cfg *config.Config) (avatars.Storage, error) {
+	if cfg.Avatar.Storage == "s3" {
+		return avatars.NewS3Storage(avatars.S3Config{
+			Endpoint:  cfg.Avatar.S3Endpoint,
+			Bucket:    cfg.Avatar.S3Bucket,
+			Region:    cfg.Avatar.S3Region,
+			AccessKey: cfg.Avatar.S3AccessKey,
+			SecretKey: cfg.Avatar.S3SecretKey,
+		}), nil
+	}
+	return avatars.NewLocalStorage(cfg.Avatar.Dir)
+}
+
+// activeModel returns the model name used by the configured AI provider
+func activeModel(cfg *config.Config) string {
+	switch cfg.AI.Provider {
+	case "azure":
+		return cfg.Azure.Deployment
+	case "ollama":
+		return cfg.Ollama.Model
+	case "anthropic":
+		return cfg.Anthropic.Model
+	default:
+		return cfg.OpenAI.Model
+	}
+}
+
+// liveCanaryConfig describes the configured AI provider for canary comparisons
+func liveCanaryConfig(cfg *config.Config) models.CanaryConfig {
+	live := models.CanaryConfig{Provider: cfg.AI.Provider, Model: activeModel(cfg)}
+	switch cfg.AI.Provider {
+	case "ollama":
+		live.Temperature, live.TopP = &cfg.Ollama.Temperature, &cfg.Ollama.TopP
+	case "anthropic":
+		live.Temperature, live.MaxTokens, live.TopP = &cfg.Anthropic.Temperature, &cfg.Anthropic.MaxTokens, &cfg.Anthropic.TopP
+	default:
+		live.Temperature, live.MaxTokens, live.TopP = &cfg.OpenAI.Temperature, &cfg.OpenAI.MaxTokens, &cfg.OpenAI.TopP
+	}
+	return live
+}
+
+// canaryFactory builds AI services for canary configurations from a copy of
+// the configuration, reusing its credentials. Token budgets and the response
+// cache are left out so every canary query reaches the provider.
+func canaryFactory(cfg *config.Config, templates prompts.Service) canary.Factory {
+	return func(candidate models.CanaryConfig) (canary.AIService, error) {
+		candidateCfg := *cfg
+		candidateCfg.AI.Provider = candidate.Provider
+		candidateCfg.OpenAI.DailyTokenBudget = 0
+		candidateCfg.OpenAI.UserDailyTokenBudget = 0
+
+		switch candidate.Provider {
+		case "openai", "azure":
+			if candidate.Provider == "azure" {
+				setIfNotEmpty(&candidateCfg.Azure.Deployment, candidate.Model)
+			} else {
+				setIfNotEmpty(&candidateCfg.OpenAI.Model, candidate.Model)
+			}
+			setIfNotNil(&candidateCfg.OpenAI.Temperature, candidate.Temperature)
+			setIfNotNil(&candidateCfg.OpenAI.MaxTokens, candidate.MaxTokens)
+			setIfNotNil(&candidateCfg.OpenAI.TopP, candidate.TopP)
+		case "anthropic":
+			setIfNotEmpty(&candidateCfg.Anthropic.Model, candidate.Model)
+			setIfNotNil(&candidateCfg.Anthropic.Temperature, candidate.Temperature)
+			setIfNotNil(&candidateCfg.Anthropic.MaxTokens, candidate.MaxTokens)
+			setIfNotNil(&candidateCfg.Anthropic.TopP, candidate.TopP)
+		case "ollama":
+			setIfNotEmpty(&candidateCfg.Ollama.Model, candidate.Model)
+			setIfNotNil(&candidateCfg.Ollama.Temperature, candidate.Temperature)
+			setIfNotNil(&candidateCfg.Ollama.TopP, candidate.TopP)
+		}
+		return newAIService(&candidateCfg, templates)
+	}
+}
+
+func setIfNotEmpty(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
+
+func setIfNotNil[T any](target *T, value *T) {
+	if value != nil {
+		*target = *value
+	}
+}
+
 // setupRoutes configures all HTTP routes
-func setupRoutes(lyricsHandler *handlers.LyricsHandler, chatHandler *handlers.ChatHandler) *mux.Router {
+func setupRoutes(
+	lyricsHandler *handlers.LyricsHandler,
+	chatHandler *handlers.ChatHandler,
+	searchHandler *handlers.SearchHandler,
+	catalogHandler *handlers.CatalogHandler,
+	statsHandler *handlers.StatsHandler,
+	trendingHandler *handlers.TrendingHandler,
+	restrictionsHandler *handlers.RestrictionsHandler,
+	cleanModeHandler *handlers.CleanModeHandler,
+	artistsHandler *handlers.ArtistsHandler,
+	deliveriesHandler *handlers.DeliveriesHandler,
+	canaryHandler *handlers.CanaryHandler,
+	realtimeHandler *handlers.RealtimeHandler,
+	communityHandler *handlers.CommunityHandler,
+	quizHandler *handlers.QuizHandler,
+	webhooksHandler *handlers.WebhooksHandler,
+	brandingHandler *handlers.BrandingHandler,
+	moodCatalogHandler *handlers.MoodCatalogHandler,
+	reportsHandler *handlers.ReportsHandler,
+	jobsHandler *handlers.JobsHandler,
+	queueHandler *handlers.QueueHandler,
+	experimentsHandler *handlers.ExperimentsHandler,
+	presenceHandler *handlers.PresenceHandler,
+	usersHandler *handlers.UsersHandler,
+	metricsHandler *handlers.MetricsHandler,
+	requireAPIKey func(http.Handler) http.Handler,
+) *mux.Router {
 	r := mux.NewRouter()
+	r.NotFoundHandler = apierror.NotFoundHandler()
+	r.MethodNotAllowedHandler = apierror.MethodNotAllowedHandler()
 
 	// API routes
 	api := r.PathPrefix("/api").Subrouter()
 
 	// Global chat routes
 	api.HandleFunc("/messages", chatHandler.GetMessages).Methods("GET")
-	api.HandleFunc("/messages", chatHandler.PostMessage).Methods("POST")
+	api.Handle("/messages", requireAPIKey(http.HandlerFunc(chatHandler.PostMessage))).Methods("POST")
+	api.HandleFunc("/messages/{id}/thread", chatHandler.GetThread).Methods("GET")
+	api.Handle("/messages/{id}/reactions", requireAPIKey(http.HandlerFunc(chatHandler.AddReaction))).Methods("POST")
+	api.Handle("/messages/{id}/reactions", requireAPIKey(http.HandlerFunc(chatHandler.RemoveReaction))).Methods("DELETE")
+	api.Handle("/messages/{id}/report", requireAPIKey(http.HandlerFunc(chatHandler.ReportMessage))).Methods("POST")
+	api.Handle("/blocks", requireAPIKey(http.HandlerFunc(chatHandler.GetBlocks))).Methods("GET")
+	api.Handle("/blocks", requireAPIKey(http.HandlerFunc(chatHandler.Block))).Methods("POST")
+	api.Handle("/blocks/{user}", requireAPIKey(http.HandlerFunc(chatHandler.Unblock))).Methods("DELETE")
+	api.Handle("/rooms", requireAPIKey(http.HandlerFunc(chatHandler.ListRooms))).Methods("GET")
+	api.Handle("/rooms/{room}/read", requireAPIKey(http.HandlerFunc(chatHandler.GetReadMarker))).Methods("GET")
+	api.Handle("/rooms/{room}/read", requireAPIKey(http.HandlerFunc(chatHandler.SetReadMarker))).Methods("PUT")
+
+	// Presence routes; who is online and what they share is user data
+	api.Handle("/presence", requireAPIKey(http.HandlerFunc(presenceHandler.GetPresence))).Methods("GET")
+	api.Handle("/presence/heartbeat", requireAPIKey(http.HandlerFunc(presenceHandler.Heartbeat))).Methods("POST")
 
 	// Music and lyrics routes
-	api.HandleFunc("/now-playing", lyricsHandler.UpdateNowPlaying).Methods("POST")
+	api.Handle("/now-playing", requireAPIKey(http.HandlerFunc(lyricsHandler.UpdateNowPlaying))).Methods("POST")
 	api.HandleFunc("/now-playing", lyricsHandler.GetNowPlaying).Methods("GET")
+	api.Handle("/now-playing", requireAPIKey(http.HandlerFunc(lyricsHandler.DeleteNowPlaying))).Methods("DELETE")
+	api.HandleFunc("/now-playing/stream", realtimeHandler.NowPlayingStream).Methods("GET")
+	api.Handle("/now-playing/state", requireAPIKey(http.HandlerFunc(lyricsHandler.UpdatePlaybackState))).Methods("POST")
+	api.Handle("/now-playing/heartbeat", requireAPIKey(http.HandlerFunc(lyricsHandler.Heartbeat))).Methods("POST")
 	api.HandleFunc("/history", lyricsHandler.GetPlayHistory).Methods("GET")
 	api.HandleFunc("/chat", lyricsHandler.HandleChat).Methods("POST")
+	api.HandleFunc("/chat/stream", lyricsHandler.HandleChatStream).Methods("POST")
+	api.Handle("/chat/feedback", requireAPIKey(http.HandlerFunc(lyricsHandler.HandleChatFeedback))).Methods("POST")
+	api.HandleFunc("/tracks/moods", lyricsHandler.GetTrackMoods).Methods("POST")
+	api.HandleFunc("/tracks/current/facts", lyricsHandler.GetCurrentTrackFacts).Methods("GET")
+	api.HandleFunc("/tracks/{id}/analyses", lyricsHandler.GetTrackAnalyses).Methods("GET")
+	api.Handle("/analyses/{id}/rating", requireAPIKey(http.HandlerFunc(lyricsHandler.RateAnalysis))).Methods("POST")
+	api.HandleFunc("/dj", lyricsHandler.DJ).Methods("POST")
+	api.HandleFunc("/analyze/compare", lyricsHandler.CompareSongs).Methods("POST")
+	api.HandleFunc("/albums/{id}/analysis", lyricsHandler.GetAlbumAnalysis).Methods("GET")
+	api.Handle("/albums/{id}/analysis", requireAPIKey(http.HandlerFunc(lyricsHandler.AnalyzeAlbum))).Methods("POST")
+	api.HandleFunc("/artists/{name}", artistsHandler.GetArtist).Methods("GET")
+	api.HandleFunc("/artists/{name}/style", artistsHandler.GetArtistStyle).Methods("GET")
+	api.HandleFunc("/playlists", lyricsHandler.CreatePlaylist).Methods("POST")
+	api.Handle("/mood/journal", requireAPIKey(http.HandlerFunc(lyricsHandler.AddMoodJournalNote))).Methods("POST")
+	api.Handle("/mood/insights", requireAPIKey(http.HandlerFunc(lyricsHandler.GetMoodInsights))).Methods("GET")
+	api.Handle("/reports/weekly", requireAPIKey(http.HandlerFunc(reportsHandler.GetWeeklyReport))).Methods("GET")
+
+	// Background job routes
+	api.Handle("/jobs", requireAPIKey(http.HandlerFunc(queueHandler.ListQueuedJobs))).Methods("GET")
+	api.Handle("/jobs/{id}", requireAPIKey(http.HandlerFunc(queueHandler.GetQueuedJob))).Methods("GET")
+
+	// Branding for frontends
+	api.HandleFunc("/branding", brandingHandler.GetBranding).Methods("GET")
+
+	// Search routes
+	api.HandleFunc("/search/suggest", searchHandler.Suggest).Methods("GET")
+
+	// Catalog maintenance routes
+	api.HandleFunc("/catalog/validation", catalogHandler.GetValidationReport).Methods("GET")
+	api.Handle("/catalog/validation", requireAPIKey(http.HandlerFunc(catalogHandler.RunValidation))).Methods("POST")
+
+	// Stats routes
+	api.Handle("/stats", requireAPIKey(http.HandlerFunc(statsHandler.GetStats))).Methods("GET")
+	api.HandleFunc("/trending", trendingHandler.GetTrending).Methods("GET")
+
+	// Community routes
+	api.HandleFunc("/community/topics", communityHandler.GetTopics).Methods("GET")
+
+	// Lyrics quiz routes; starting a game interrupts chat, so it needs an API key
+	api.HandleFunc("/quiz", quizHandler.GetQuiz).Methods("GET")
+	api.Handle("/quiz", requireAPIKey(http.HandlerFunc(quizHandler.StartQuiz))).Methods("POST")
+	api.HandleFunc("/quiz/leaderboard", quizHandler.GetLeaderboard).Methods("GET")
+
+	// Profile routes; the caller is identified by X-User-ID
+	api.Handle("/users/me", requireAPIKey(http.HandlerFunc(usersHandler.GetMe))).Methods("GET")
+	api.Handle("/users/me", requireAPIKey(http.HandlerFunc(usersHandler.UpdateMe))).Methods("PATCH")
+	api.Handle("/users/me/avatar", requireAPIKey(http.HandlerFunc(usersHandler.UploadAvatar))).Methods("PUT")
+	api.Handle("/users/me/avatar", requireAPIKey(http.HandlerFunc(usersHandler.DeleteAvatar))).Methods("DELETE")
+	api.Handle("/users/me/data", requireAPIKey(http.HandlerFunc(usersHandler.DeleteMyData))).Methods("DELETE")
+	api.Handle("/users/me/export", requireAPIKey(http.HandlerFunc(usersHandler.ExportMyData))).Methods("GET")
+	api.HandleFunc("/exports/{token}", usersHandler.DownloadExport).Methods("GET")
+	api.HandleFunc("/avatars/{key}", usersHandler.GetAvatar).Methods("GET")
+
+	// Restricted mode routes; changing the setting is reserved for key holders (e.g. a parent app)
+	api.HandleFunc("/users/{userID}/restricted-mode", restrictionsHandler.GetRestrictedMode).Methods("GET")
+	api.Handle("/users/{userID}/restricted-mode", requireAPIKey(http.HandlerFunc(restrictionsHandler.SetRestrictedMode))).Methods("PUT")
+
+	// Clean mode routes; users turn it on or off themselves
+	api.HandleFunc("/users/{userID}/clean-mode", cleanModeHandler.GetCleanMode).Methods("GET")
+	api.Handle("/users/{userID}/clean-mode", requireAPIKey(http.HandlerFunc(cleanModeHandler.SetCleanMode))).Methods("PUT")
+
+	// WebSocket routes; browsers send the API key as a token query parameter
+	api.Handle("/ws/now-playing", requireAPIKey(http.HandlerFunc(realtimeHandler.NowPlaying))).Methods("GET")
+	api.Handle("/ws/chat", requireAPIKey(http.HandlerFunc(realtimeHandler.Chat))).Methods("GET")
+	api.Handle("/ws/quiz", requireAPIKey(http.HandlerFunc(realtimeHandler.Quiz))).Methods("GET")
+
+	// Webhook routes; webhooks receive user data, so all of them need an API key
+	hooks := api.PathPrefix("/webhooks").Subrouter()
+	hooks.Use(mux.MiddlewareFunc(requireAPIKey))
+	hooks.HandleFunc("", webhooksHandler.ListWebhooks).Methods("GET")
+	hooks.HandleFunc("", webhooksHandler.CreateWebhook).Methods("POST")
+	hooks.HandleFunc("/{id}", webhooksHandler.DeleteWebhook).Methods("DELETE")
+	hooks.HandleFunc("/{id}/deliveries", webhooksHandler.ListWebhookDeliveries).Methods("GET")
+	hooks.HandleFunc("/{id}/deliveries/{deliveryID}/redeliver", webhooksHandler.RedeliverWebhook).Methods("POST")
+
+	// Admin routes; payloads may contain user data, so all of them need an API key
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(mux.MiddlewareFunc(requireAPIKey))
+	admin.HandleFunc("/deliveries", deliveriesHandler.ListDeliveries).Methods("GET")
+	admin.HandleFunc("/deliveries/{id}", deliveriesHandler.GetDelivery).Methods("GET")
+	admin.HandleFunc("/deliveries/{id}/redeliver", deliveriesHandler.Redeliver).Methods("POST")
+	admin.HandleFunc("/canary", canaryHandler.RunCanary).Methods("POST")
+	admin.HandleFunc("/jobs", jobsHandler.ListJobs).Methods("GET")
+	admin.HandleFunc("/metrics", metricsHandler.GetMetrics).Methods("GET")
+	admin.HandleFunc("/experiments", experimentsHandler.ListExperiments).Methods("GET")
+	admin.HandleFunc("/community/topics", communityHandler.RunTopics).Methods("POST")
+	admin.HandleFunc("/moderation", chatHandler.ModerationQueue).Methods("GET")
+	admin.HandleFunc("/moderation/{id}/review", chatHandler.ReviewModeration).Methods("POST")
+	admin.HandleFunc("/reports", chatHandler.ListReports).Methods("GET")
+	admin.HandleFunc("/reports/{id}/dismiss", chatHandler.DismissReport).Methods("POST")
+	admin.HandleFunc("/messages/{id}/hide", chatHandler.HideMessage).Methods("POST")
+	admin.HandleFunc("/messages/{id}/unhide", chatHandler.UnhideMessage).Methods("POST")
+	admin.HandleFunc("/bans", chatHandler.ListBans).Methods("GET")
+	admin.HandleFunc("/bans", chatHandler.BanUser).Methods("POST")
+	admin.HandleFunc("/bans/{user}", chatHandler.UnbanUser).Methods("DELETE")
+	admin.HandleFunc("/audit", chatHandler.ListAudit).Methods("GET")
+	admin.HandleFunc("/mood-suggestions", moodCatalogHandler.ListMoodSuggestions).Methods("GET")
+	admin.HandleFunc("/mood-suggestions", moodCatalogHandler.CreateMoodSuggestion).Methods("POST")
+	admin.HandleFunc("/mood-suggestions/{id}", moodCatalogHandler.UpdateMoodSuggestion).Methods("PUT")
+	admin.HandleFunc("/mood-suggestions/{id}", moodCatalogHandler.DeleteMoodSuggestion).Methods("DELETE")
 
 	// Health check
 	api.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -156,6 +1141,28 @@ func setupRoutes(lyricsHandler *handlers.LyricsHandler, chatHandler *handlers.Ch
 	return r
 }
 
+// setupMessageNotify adds the trigger that announces new messages with their
+// ID, for WS_CHAT_FANOUT=postgres, or drops it so that instances fanning out
+// in memory don't pay for notifications nobody listens to
+func setupMessageNotify(db *sql.DB, enabled bool) error {
+	if !enabled {
+		_, err := db.Exec(`DROP TRIGGER IF EXISTS global_messages_notify ON global_messages`)
+		return err
+	}
+	_, err := db.Exec(`
+		CREATE OR REPLACE FUNCTION notify_global_message() RETURNS trigger AS $$
+		BEGIN
+			PERFORM pg_notify('global_messages', NEW.id::text);
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+		DROP TRIGGER IF EXISTS global_messages_notify ON global_messages;
+		CREATE TRIGGER global_messages_notify AFTER INSERT ON global_messages
+			FOR EACH ROW EXECUTE FUNCTION notify_global_message();
+	`)
+	return err
+}
+
 // setupDatabase creates the necessary database tables
 func setupDatabase(db *sql.DB) error {
 	// Create table for global messages if it doesn't exist
@@ -168,16 +1175,146 @@ func setupDatabase(db *sql.DB) error {
             created_at TIMESTAMP WITH TIME ZONE NOT NULL
         );
 
+		-- Structured messages, such as shared tracks, carry their content as JSON
+		ALTER TABLE global_messages ADD COLUMN IF NOT EXISTS message_type VARCHAR(32) NOT NULL DEFAULT 'text';
+		ALTER TABLE global_messages ADD COLUMN IF NOT EXISTS payload JSONB;
+
+		-- Replies point at the message they reply to; they outlive it, e.g. when
+		-- retention archives it, and then join the main stream
+		ALTER TABLE global_messages ADD COLUMN IF NOT EXISTS parent_message_id INTEGER REFERENCES global_messages(id) ON DELETE SET NULL;
+
+		-- Moderators hide reported messages instead of deleting them
+		ALTER TABLE global_messages ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP WITH TIME ZONE;
+
+		-- Messages from registered users name them; their profile names the message
+		ALTER TABLE global_messages ADD COLUMN IF NOT EXISTS user_id VARCHAR(255);
+
 		-- Create indexes for better performance
+		CREATE INDEX IF NOT EXISTS idx_global_messages_user_id ON global_messages(user_id);
 		CREATE INDEX IF NOT EXISTS idx_global_messages_created_at ON global_messages(created_at DESC);
 		CREATE INDEX IF NOT EXISTS idx_global_messages_user_email ON global_messages(user_email);
+		CREATE INDEX IF NOT EXISTS idx_global_messages_parent ON global_messages(parent_message_id);
+		-- Pages of the main stream are read newest first by (created_at, id)
+		CREATE INDEX IF NOT EXISTS idx_global_messages_stream ON global_messages(created_at DESC, id DESC) WHERE parent_message_id IS NULL;
+
+        -- Emoji reactions, one per user and emoji on a message
+        CREATE TABLE IF NOT EXISTS message_reactions (
+            message_id INTEGER NOT NULL REFERENCES global_messages(id) ON DELETE CASCADE,
+            user_id VARCHAR(255) NOT NULL,
+            emoji VARCHAR(64) NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+            PRIMARY KEY (message_id, user_id, emoji)
+        );
+
+        -- Authors each user blocked or muted, by their messages' user_email
+        CREATE TABLE IF NOT EXISTS user_blocks (
+            user_id VARCHAR(255) NOT NULL,
+            blocked_user VARCHAR(255) NOT NULL,
+            kind VARCHAR(16) NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+            PRIMARY KEY (user_id, blocked_user)
+        );
+
+        -- Reports of messages for moderators, one per user and message
+        CREATE TABLE IF NOT EXISTS message_reports (
+            id BIGSERIAL PRIMARY KEY,
+            message_id INTEGER NOT NULL REFERENCES global_messages(id) ON DELETE CASCADE,
+            reporter_id VARCHAR(255) NOT NULL,
+            reason VARCHAR(32) NOT NULL,
+            details TEXT NOT NULL DEFAULT '',
+            status VARCHAR(16) NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+            UNIQUE (message_id, reporter_id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_message_reports_status ON message_reports(status, created_at);
+
+        -- Temporary bans from posting, by X-User-ID or user_email
+        CREATE TABLE IF NOT EXISTS user_bans (
+            user_id VARCHAR(255) PRIMARY KEY,
+            reason TEXT NOT NULL DEFAULT '',
+            banned_by VARCHAR(255) NOT NULL,
+            expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL
+        );
+
+        -- Every action moderators take
+        CREATE TABLE IF NOT EXISTS moderation_audit (
+            id BIGSERIAL PRIMARY KEY,
+            actor VARCHAR(255) NOT NULL,
+            action VARCHAR(32) NOT NULL,
+            target_type VARCHAR(32) NOT NULL,
+            target_id VARCHAR(255) NOT NULL,
+            details TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL
+        );
+
+        -- The last message each user read in each chat room
+        CREATE TABLE IF NOT EXISTS read_markers (
+            user_id VARCHAR(255) NOT NULL,
+            room VARCHAR(64) NOT NULL,
+            last_read_message_id BIGINT NOT NULL,
+            updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+            PRIMARY KEY (user_id, room)
+        );
+
+        -- API keys for write endpoints, stored as SHA-256 hashes
+        CREATE TABLE IF NOT EXISTS api_keys (
+            key_hash CHAR(64) PRIMARY KEY,
+            name VARCHAR(255) NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+            revoked_at TIMESTAMP WITH TIME ZONE
+        );
     `
-	
+
 	_, err := db.Exec(query)
 	if err != nil {
 		return fmt.Errorf("failed to create tables: %w", err)
 	}
 
+	if _, err := db.Exec(projections.Schema); err != nil {
+		return fmt.Errorf("failed to create projection tables: %w", err)
+	}
+
+	if _, err := db.Exec(quiz.Schema); err != nil {
+		return fmt.Errorf("failed to create quiz tables: %w", err)
+	}
+
+	if _, err := db.Exec(webhooks.Schema); err != nil {
+		return fmt.Errorf("failed to create webhook tables: %w", err)
+	}
+
+	if _, err := db.Exec(repositories.NowPlayingSchema); err != nil {
+		return fmt.Errorf("failed to create now playing table: %w", err)
+	}
+
+	if _, err := db.Exec(repositories.HistorySchema); err != nil {
+		return fmt.Errorf("failed to create play history table: %w", err)
+	}
+
+	if _, err := db.Exec(repositories.MoodSuggestionsSchema); err != nil {
+		return fmt.Errorf("failed to create mood suggestions table: %w", err)
+	}
+
+	if _, err := db.Exec(jobqueue.Schema); err != nil {
+		return fmt.Errorf("failed to create job queue table: %w", err)
+	}
+
+	if _, err := db.Exec(experiments.Schema); err != nil {
+		return fmt.Errorf("failed to create prompt experiment outcomes table: %w", err)
+	}
+
+	if _, err := db.Exec(analysiscache.Schema); err != nil {
+		return fmt.Errorf("failed to create lyrics analysis tables: %w", err)
+	}
+
+	if _, err := db.Exec(moderation.Schema); err != nil {
+		return fmt.Errorf("failed to create moderation verdicts table: %w", err)
+	}
+
+	if _, err := db.Exec(users.Schema); err != nil {
+		return fmt.Errorf("failed to create users table: %w", err)
+	}
+
 	log.Println("Database tables set up successfully")
 	return nil
-}
\ No newline at end of file
+}
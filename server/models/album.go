@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// AlbumAnalysisRequest is the payload of an album_analysis job
+type AlbumAnalysisRequest struct {
+	AlbumID string `json:"album_id"` // Spotify album ID
+}
+
+// AlbumAnalysis is the AI's narrative of an album's arc and recurring
+// themes, with the mood of each track
+type AlbumAnalysis struct {
+	AlbumID         string               `json:"album_id"`
+	Name            string               `json:"name"`
+	Artist          string               `json:"artist"`
+	ReleaseDate     string               `json:"release_date,omitempty"`
+	ImageURL        string               `json:"image_url,omitempty"`
+	Narrative       string               `json:"narrative"`
+	RecurringThemes []string             `json:"recurring_themes"`
+	Tracks          []AlbumTrackAnalysis `json:"tracks"` // In album order
+	AnalyzedAt      time.Time            `json:"analyzed_at"`
+}
+
+// AlbumTrackAnalysis is the mood of one track of an analyzed album
+type AlbumTrackAnalysis struct {
+	Number   int           `json:"number"` // 1-based position on the album
+	ID       string        `json:"id"`
+	Name     string        `json:"name"`
+	Explicit bool          `json:"explicit,omitempty"`
+	Mood     *MoodAnalysis `json:"mood_analysis,omitempty"` // Absent for tracks without lyrics
+	Themes   []string      `json:"themes,omitempty"`
+	Error    string        `json:"error,omitempty"` // Why the track has no analysis
+}
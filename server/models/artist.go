@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// GeniusArtist is an artist's page on Genius
+type GeniusArtist struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	ImageURL string `json:"image_url,omitempty"`
+	Bio      string `json:"bio,omitempty"` // Plain text
+}
+
+// ArtistInfo is the response of GET /api/artists/{name}, combining the
+// artist's Genius bio with their Spotify profile and top tracks. Parts a
+// source couldn't provide are left out.
+type ArtistInfo struct {
+	Name       string         `json:"name"`
+	Bio        string         `json:"bio,omitempty"`
+	GeniusURL  string         `json:"genius_url,omitempty"`
+	SpotifyID  string         `json:"spotify_id,omitempty"`
+	Genres     []string       `json:"genres,omitempty"`
+	Followers  int            `json:"followers,omitempty"`
+	Popularity int            `json:"popularity,omitempty"`
+	Images     []SpotifyImage `json:"images,omitempty"` // Largest first; the Genius image if Spotify has none
+	TopTracks  []UnifiedTrack `json:"top_tracks,omitempty"`
+}
+
+// GeniusSong is a song's metadata on Genius
+type GeniusSong struct {
+	ID          int64    `json:"id"`
+	Title       string   `json:"title"`
+	Artist      string   `json:"artist"`
+	URL         string   `json:"url"`
+	Album       string   `json:"album,omitempty"`
+	ReleaseDate string   `json:"release_date,omitempty"` // As Genius displays it, e.g. "March 25, 2003"
+	Producers   []string `json:"producers,omitempty"`
+	Writers     []string `json:"writers,omitempty"`
+	Samples     []string `json:"samples,omitempty"`     // Full titles of the songs it samples
+	Description string   `json:"description,omitempty"` // Plain text
+}
+
+// SongFacts is the response of GET /api/tracks/current/facts: the song's
+// Genius metadata and a short "did you know" blurb written by the AI
+type SongFacts struct {
+	TrackName   string   `json:"track_name"`
+	Artist      string   `json:"artist"`
+	Album       string   `json:"album,omitempty"`
+	ReleaseDate string   `json:"release_date,omitempty"`
+	Producers   []string `json:"producers,omitempty"`
+	Writers     []string `json:"writers,omitempty"`
+	Samples     []string `json:"samples,omitempty"`
+	GeniusURL   string   `json:"genius_url,omitempty"`
+	DidYouKnow  string   `json:"did_you_know,omitempty"` // Missing if the AI couldn't write it
+}
+
+// ArtistStyle is the AI's summary of an artist's lyrical style, drawn from
+// their top songs
+type ArtistStyle struct {
+	Artist           string      `json:"artist"`
+	SpotifyID        string      `json:"spotify_id"`
+	Themes           []string    `json:"themes"`
+	Evolution        string      `json:"evolution"`
+	SignaturePhrases []string    `json:"signature_phrases"`
+	Summary          string      `json:"summary"`
+	Songs            []StyleSong `json:"songs"` // The songs sampled, oldest first
+	AnalyzedAt       time.Time   `json:"analyzed_at"`
+}
+
+// StyleSong is a song whose lyrics were sampled for an artist's style
+type StyleSong struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Album       string `json:"album,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty"`
+}
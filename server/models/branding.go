@@ -0,0 +1,9 @@
+package models
+
+// Branding describes how frontends should present the deployment's assistant
+type Branding struct {
+	AssistantName string `json:"assistant_name"`
+	Tagline       string `json:"tagline,omitempty"`
+	LogoURL       string `json:"logo_url,omitempty"`
+	PrimaryColor  string `json:"primary_color,omitempty"` // #rrggbb
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// CanaryConfig is an AI provider configuration. In a canary request, unset
+// fields keep the live configuration's values.
+type CanaryConfig struct {
+	Provider     string   `json:"provider,omitempty"` // openai, azure, anthropic or ollama
+	Model        string   `json:"model,omitempty"`    // The deployment name for Azure
+	Temperature  *float64 `json:"temperature,omitempty"`
+	MaxTokens    *int     `json:"max_tokens,omitempty"`
+	TopP         *float64 `json:"top_p,omitempty"`
+	Instructions string   `json:"instructions,omitempty"` // Prepended to every prompt
+}
+
+// CanaryRequest is the body of POST /api/admin/canary
+type CanaryRequest struct {
+	Candidate CanaryConfig `json:"candidate"`
+}
+
+// CanaryResult is one configuration's answer to a canary query. Token counts
+// and costs are estimated from the text sent and received.
+type CanaryResult struct {
+	Output           string   `json:"output,omitempty"`
+	Error            string   `json:"error,omitempty"`
+	LatencyMs        int64    `json:"latency_ms"`
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	CostUSD          *float64 `json:"cost_usd,omitempty"` // Omitted when the model has no configured price
+}
+
+// CanaryCase is one query of the battery answered by both configurations
+type CanaryCase struct {
+	Name      string       `json:"name"`
+	Kind      string       `json:"kind"` // lyrics_analysis, mood_detection or song_request
+	Query     string       `json:"query"`
+	Current   CanaryResult `json:"current"`
+	Candidate CanaryResult `json:"candidate"`
+}
+
+// CanarySummary totals one configuration's results across the battery
+type CanarySummary struct {
+	Config           CanaryConfig `json:"config"`
+	Errors           int          `json:"errors"`
+	LatencyMs        int64        `json:"latency_ms"`
+	PromptTokens     int          `json:"prompt_tokens"`
+	CompletionTokens int          `json:"completion_tokens"`
+	CostUSD          *float64     `json:"cost_usd,omitempty"`
+}
+
+// CanaryReport compares a candidate AI configuration with the live one
+type CanaryReport struct {
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Current    CanarySummary `json:"current"`
+	Candidate  CanarySummary `json:"candidate"`
+	Cases      []CanaryCase  `json:"cases"`
+}
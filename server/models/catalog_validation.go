@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// CatalogValidationReport summarizes a validation pass over stored track IDs
+type CatalogValidationReport struct {
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt time.Time               `json:"finished_at"`
+	Checked    int                     `json:"checked"`
+	Valid      int                     `json:"valid"`
+	Replaced   int                     `json:"replaced"`
+	Unresolved int                     `json:"unresolved"`
+	Errors     int                     `json:"errors"` // Tracks that could not be checked, e.g. API failures
+	Issues     []CatalogValidationItem `json:"issues"`
+}
+
+// CatalogValidationItem describes a track whose stored ID could not be resolved
+type CatalogValidationItem struct {
+	TrackID       string `json:"track_id"`
+	Name          string `json:"name"`
+	Artist        string `json:"artist"`
+	Status        string `json:"status"` // "replaced" | "unresolved" | "error"
+	ReplacementID string `json:"replacement_id,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
@@ -2,17 +2,27 @@ package models
 
 // ChatRequest represents a chat request from the user
 type ChatRequest struct {
-	Query string `json:"query"`
+	Query  string `json:"query"`
+	Lang   string `json:"lang,omitempty"`   // ISO 639-1 code of the answer's language, e.g. "es"; detected from the query if unset
+	Region string `json:"region,omitempty"` // ISO 3166-1 alpha-2 code picking the helplines offered in a crisis, e.g. "GB"
+	Clean  bool   `json:"clean,omitempty"`  // Mask profanity and keep the answer family-friendly, whatever the user's clean mode setting
 }
 
 // ChatResponse represents a response to a chat request
 type ChatResponse struct {
 	Answer          string                   `json:"answer"`
 	Error           string                   `json:"error,omitempty"`
-	Type            string                   `json:"type,omitempty"`            // "text" | "song_request" | "mood_recommendation"
+	Type            string                   `json:"type,omitempty"`            // "text" | "song_request" | "mood_recommendation" | "artist_radio" | "playlist" | "crisis_support" | "quiz"
+	Language        string                   `json:"language,omitempty"`        // ISO 639-1 code of the language answered in
 	SongQuery       *SongQuery               `json:"song_query,omitempty"`      // Only present when Type is "song_request"
 	MoodAnalysis    *MoodAnalysis            `json:"mood_analysis,omitempty"`   // Present when mood is detected
 	Recommendations *MoodRecommendations     `json:"recommendations,omitempty"` // Present when Type is "mood_recommendation"
+	Radio           *ArtistRadio             `json:"radio,omitempty"`           // Present when Type is "artist_radio"
+	Playlist        *SpotifyPlaylist         `json:"playlist,omitempty"`        // Present when Type is "playlist"
+	Resources       []Helpline               `json:"resources,omitempty"`       // Present when Type is "crisis_support"
+	ResponseID      string                   `json:"response_id,omitempty"`     // Present when the answer is part of a prompt experiment, for feedback
+	Quiz            *TriviaQuiz              `json:"quiz,omitempty"`            // Present when Type is "quiz"
+	AnalysisID      int64                    `json:"analysis_id,omitempty"`     // Present when the answer is a stored analysis, for ratings
 }
 
 // SongQuery represents a parsed song request
@@ -26,6 +36,22 @@ type MoodAnalysis struct {
 	PrimaryMood  string   `json:"primary_mood"`  // Main emotion detected
 	MoodScore    float64  `json:"mood_score"`    // Confidence score 0-1
 	EmotionTags  []string `json:"emotion_tags"`  // Related emotions/themes
+	Moods        []WeightedMood `json:"moods,omitempty"` // Every mood present in a mixed message, strongest first
+	Crisis       bool     `json:"crisis,omitempty"` // The message suggests severe distress or self-harm
+}
+
+// WeightedMood is one of several moods detected in the same message
+type WeightedMood struct {
+	Mood   string  `json:"mood"`
+	Weight float64 `json:"weight"` // Share of the overall mood, weights sum to 1
+}
+
+// WeightedMoods returns the detected moods, falling back to the primary mood alone
+func (a *MoodAnalysis) WeightedMoods() []WeightedMood {
+	if len(a.Moods) > 0 {
+		return a.Moods
+	}
+	return []WeightedMood{{Mood: a.PrimaryMood, Weight: 1}}
 }
 
 // MoodRecommendations represents mood-based song recommendations
@@ -39,4 +65,17 @@ type MoodBasedRecommendation struct {
 	Track       UnifiedTrack `json:"track"`
 	MatchReason string       `json:"match_reason,omitempty"` // Why this song matches the mood
 	MoodScore   float64      `json:"mood_score"`             // How well it matches (0-1)
+}
+
+// ArtistRadio represents a playable list seeded by an artist
+type ArtistRadio struct {
+	SeedArtist string       `json:"seed_artist"`
+	Tracks     []RadioTrack `json:"tracks"`
+}
+
+// RadioTrack represents a single track in an artist radio list
+type RadioTrack struct {
+	Track  UnifiedTrack `json:"track"`
+	Reason string       `json:"reason"` // Why this track was picked
+	Score  float64      `json:"score"`  // Ranking score (0-1)
 }
\ No newline at end of file
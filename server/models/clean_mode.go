@@ -0,0 +1,12 @@
+package models
+
+// CleanModeStatus describes whether a user is in clean mode
+type CleanModeStatus struct {
+	UserID string `json:"user_id"`
+	Clean  bool   `json:"clean"`
+}
+
+// CleanModeRequest is the body of PUT /api/users/{userID}/clean-mode
+type CleanModeRequest struct {
+	Clean bool `json:"clean"`
+}
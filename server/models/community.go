@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// CommunityTopic is a cluster of recent chat messages about the same thing
+type CommunityTopic struct {
+	Label          string    `json:"label"` // e.g. "People are talking about the new Linkin Park single"
+	Keywords       []string  `json:"keywords"`
+	MessageCount   int       `json:"message_count"`
+	Participants   int       `json:"participants"`    // Distinct users posting in the topic
+	SampleMessages []string  `json:"sample_messages"` // Messages closest to the topic's center
+	FirstAt        time.Time `json:"first_at"`
+	LastAt         time.Time `json:"last_at"`
+}
+
+// CommunityTopicsReport is the result of one clustering pass over recent chat
+type CommunityTopicsReport struct {
+	StartedAt        time.Time        `json:"started_at"`
+	FinishedAt       time.Time        `json:"finished_at"`
+	Since            time.Time        `json:"since"` // Start of the analyzed window
+	Embedder         string           `json:"embedder"`
+	MessagesAnalyzed int              `json:"messages_analyzed"`
+	Topics           []CommunityTopic `json:"topics"`           // Largest first
+	Digest           string           `json:"digest,omitempty"` // Text of the digest posted to chat, if any
+	Error            string           `json:"error,omitempty"`
+}
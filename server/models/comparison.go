@@ -0,0 +1,22 @@
+package models
+
+// CompareSongsRequest represents a request to compare two songs
+type CompareSongsRequest struct {
+	First  TrackReference `json:"first"`
+	Second TrackReference `json:"second"`
+	Lang   string         `json:"lang,omitempty"`  // Answer language; English if empty
+	Clean  bool           `json:"clean,omitempty"` // Keep the comparison family-friendly
+}
+
+// SongComparison is the AI's comparison of two songs with each song's mood analysis
+type SongComparison struct {
+	First        TrackMoodResult `json:"first"`
+	Second       TrackMoodResult `json:"second"`
+	SharedThemes []string        `json:"shared_themes"`
+	FirstThemes  []string        `json:"first_themes"`  // Themes only the first song explores
+	SecondThemes []string        `json:"second_themes"` // Themes only the second song explores
+	Mood         string          `json:"mood"`
+	Era          string          `json:"era"`
+	Summary      string          `json:"summary"`
+	Language     string          `json:"language"`
+}
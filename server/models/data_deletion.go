@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// DataDeletionRequest is the payload of a data_deletion job
+type DataDeletionRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// DataDeletionReceipt is the result of a data_deletion job, confirming what
+// was removed. Counts are keyed by kind of data, e.g. "messages" or
+// "mood_history_files".
+type DataDeletionReceipt struct {
+	UserID      string           `json:"user_id"`
+	Deleted     map[string]int64 `json:"deleted"`
+	Anonymized  map[string]int64 `json:"anonymized"`   // Rows kept for aggregates with the user's ID removed
+	NotPerUser  []string         `json:"not_per_user"` // Data the server keeps for everyone rather than per user, so there was none to delete
+	CompletedAt time.Time        `json:"completed_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// DataExportRequest is the payload of a data_export job. The bundle ID is
+// the hash of the download token, which the job never sees.
+type DataExportRequest struct {
+	UserID   string `json:"user_id"`
+	BundleID string `json:"bundle_id"`
+}
+
+// DataExport is the result of a data_export job: a zip bundle of the user's
+// data, downloadable until it expires
+type DataExport struct {
+	Files     []string  `json:"files"` // The JSON files in the bundle
+	Bytes     int64     `json:"bytes"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DataExportQueued is the response to a data export request: the queued
+// data_export job and the link its bundle can be downloaded from once the job
+// succeeded. The link is only ever returned here.
+type DataExportQueued struct {
+	QueuedJob
+	DownloadURL string `json:"download_url"`
+}
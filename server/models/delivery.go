@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Event delivery statuses
+const (
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// EventDelivery is one event published to the external event stream or a
+// webhook, with the outcome of its latest attempt
+type EventDelivery struct {
+	ID             int64     `json:"id"`
+	Backend        string    `json:"backend"` // "nats", "kafka" or "webhook"
+	Topic          string    `json:"topic"`
+	Status         string    `json:"status"`          // DeliveryDelivered or DeliveryFailed
+	Attempts       int       `json:"attempts"`        // Including retries and manual redeliveries
+	LatencyMs      int64     `json:"latency_ms"`      // Duration of the latest attempt
+	Error          string    `json:"error,omitempty"` // Error of the latest attempt
+	PayloadBytes   int       `json:"payload_bytes"`
+	PayloadPreview string    `json:"payload_preview"` // Start of the payload
+	CreatedAt      time.Time `json:"created_at"`
+	LastAttemptAt  time.Time `json:"last_attempt_at"`
+}
+
+// EventDeliveriesResponse lists recent event deliveries, newest first
+type EventDeliveriesResponse struct {
+	Deliveries []EventDelivery `json:"deliveries"`
+	Failed     int             `json:"failed"` // Failed deliveries among all those remembered
+}
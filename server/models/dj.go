@@ -0,0 +1,15 @@
+package models
+
+// DJRequest asks the AI DJ for a queue of tracks matching a vibe
+type DJRequest struct {
+	Vibe          string `json:"vibe"`                      // e.g. "late night coding" or "road trip"
+	PushToSpotify bool   `json:"push_to_spotify,omitempty"` // Add the queue to the user's Spotify player; needs X-Spotify-Token
+}
+
+// DJResponse represents an ordered queue built for a vibe
+type DJResponse struct {
+	Vibe       string         `json:"vibe"`
+	Tracks     []UnifiedTrack `json:"tracks"`
+	Queued     int            `json:"queued"`                // Tracks added to the user's Spotify queue
+	QueueError string         `json:"queue_error,omitempty"` // Why pushing to Spotify stopped early
+}
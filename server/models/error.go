@@ -0,0 +1,13 @@
+package models
+
+// ErrorResponse is the body of every error response
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes what went wrong. Code is stable and meant for
+// programs; Message is for people and may change.
+type ErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ExperimentOutcome is one request served by a prompt experiment variant
+type ExperimentOutcome struct {
+	ResponseID string    `json:"response_id"` // Sent with the chat response, for feedback
+	Experiment string    `json:"experiment"`  // The template being tested, e.g. "lyrics_analysis"
+	Variant    string    `json:"variant"`
+	UserID     string    `json:"user_id"`
+	LatencyMs  int64     `json:"latency_ms"`
+	Length     int       `json:"length"` // Characters in the AI's answer
+	Failed     bool      `json:"failed"`
+	Helpful    *bool     `json:"helpful,omitempty"` // The user's feedback, if any
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ExperimentVariantResult compares the outcomes of one variant
+type ExperimentVariantResult struct {
+	Variant      string   `json:"variant"`
+	Template     string   `json:"template"` // The prompt template the variant renders
+	Weight       int      `json:"weight"`   // Share of users assigned, relative to the other variants
+	Requests     int      `json:"requests"`
+	Failures     int      `json:"failures"`
+	AvgLatencyMs float64  `json:"avg_latency_ms"`
+	AvgLength    float64  `json:"avg_length"` // Of successful answers
+	Helpful      int      `json:"helpful"`
+	Unhelpful    int      `json:"unhelpful"`
+	HelpfulRate  *float64 `json:"helpful_rate,omitempty"` // Share of feedback that was helpful; absent without feedback
+}
+
+// ExperimentResult compares the variants of a prompt experiment
+type ExperimentResult struct {
+	Experiment string                    `json:"experiment"`
+	Variants   []ExperimentVariantResult `json:"variants"`
+}
+
+// ExperimentsResponse lists the running prompt experiments
+type ExperimentsResponse struct {
+	Experiments []ExperimentResult `json:"experiments"`
+}
+
+// ChatFeedbackRequest rates a chat answer that was part of a prompt experiment
+type ChatFeedbackRequest struct {
+	ResponseID string `json:"response_id"`
+	Helpful    *bool  `json:"helpful"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Statuses of a QueuedJob
+const (
+	JobQueued    = "queued" // Waiting for a worker, or for its next attempt
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed" // Every attempt failed
+)
+
+// JobTypeTrackMoods analyzes the mood of a list of tracks, fetching their
+// lyrics, with a TrackMoodsRequest payload and a TrackMoodsResponse result
+const JobTypeTrackMoods = "track_moods"
+
+// JobTypeAlbumAnalysis analyzes an album's lyrics and writes its narrative,
+// with an AlbumAnalysisRequest payload and an AlbumAnalysis result
+const JobTypeAlbumAnalysis = "album_analysis"
+
+// JobTypeDataDeletion deletes a user's data, with a DataDeletionRequest
+// payload and a DataDeletionReceipt result
+const JobTypeDataDeletion = "data_deletion"
+
+// JobTypeDataExport bundles a user's data for download, with a
+// DataExportRequest payload and a DataExport result
+const JobTypeDataExport = "data_export"
+
+// QueuedJob is slow work run in the background by the job queue
+type QueuedJob struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	UserID      string          `json:"user_id"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	Payload     json.RawMessage `json:"payload"`
+	Result      json.RawMessage `json:"result,omitempty"` // Set once the job succeeded
+	Error       string          `json:"error,omitempty"`  // From the latest failed attempt
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	RunAfter    time.Time       `json:"run_after"` // When a queued job's next attempt may start
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the job succeeded or ran out of attempts
+func (j QueuedJob) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+// QueuedJobsResponse lists queued jobs, newest first
+type QueuedJobsResponse struct {
+	Jobs []QueuedJob `json:"jobs"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// StoredAnalysis is a stored answer to a common question about a track, with
+// its community ratings
+type StoredAnalysis struct {
+	ID        int64     `json:"id"`
+	TrackID   string    `json:"track_id"`
+	Category  string    `json:"category"` // "meaning", "themes", "mood", "story", "inspiration" or "symbolism"
+	Language  string    `json:"language"`
+	Variant   string    `json:"variant,omitempty"` // "clean" or "restricted" for masked answers
+	Answer    string    `json:"answer"`
+	Helpful   int       `json:"helpful"`
+	Unhelpful int       `json:"unhelpful"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RateAnalysisRequest rates a stored analysis
+type RateAnalysisRequest struct {
+	Helpful *bool `json:"helpful"`
+}
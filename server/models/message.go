@@ -1,11 +1,68 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
+
+// Message types
+const (
+	MessageTypeText  = "text"  // A plain text message
+	MessageTypeTrack = "track" // A track card; the payload is a UnifiedTrack and the text an optional caption
+)
 
 type Message struct {
-	ID        int64     `json:"id"`
-	UserEmail string    `json:"user_email"`
-	Username  string    `json:"username"`
-	Text      string    `json:"text"`
+	ID              int64           `json:"id"`
+	UserID          string          `json:"user_id,omitempty"` // The registered author; unset for messages from the server
+	UserEmail       string          `json:"user_email"`        // The author's profile email once they have one
+	Username        string          `json:"username"`          // The author's profile display name or username once they have one
+	Text            string          `json:"text"`
+	MessageType     string          `json:"message_type,omitempty"`      // MessageTypeText when empty
+	Payload         json.RawMessage `json:"payload,omitempty"`           // The structured content of types other than text
+	Reactions       []ReactionCount `json:"reactions,omitempty"`         // In the order they were first used
+	ParentMessageID *int64          `json:"parent_message_id,omitempty"` // Set on replies; threads are one level deep
+	ReplyCount      int             `json:"reply_count,omitempty"`       // Replies to this message
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// MessageThread is a message and its replies, oldest first
+type MessageThread struct {
+	Message Message   `json:"message"`
+	Replies []Message `json:"replies"`
+}
+
+// ReactionCount is how many users reacted to a message with an emoji
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// MessageReactions is a message's reactions after one was added or removed
+type MessageReactions struct {
+	MessageID int64           `json:"message_id"`
+	Reactions []ReactionCount `json:"reactions"`
+}
+
+// ReactionRequest adds a reaction to a message
+type ReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// Kinds of user block
+const (
+	BlockKindBlock = "block" // Hides the author's messages; will also stop their direct messages
+	BlockKindMute  = "mute"  // Hides the author's messages
+)
+
+// UserBlock is an author a user blocked or muted
+type UserBlock struct {
+	UserEmail string    `json:"user_email"` // The author's user_email on their messages
+	Kind      string    `json:"kind"`       // BlockKindBlock or BlockKindMute
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// BlockRequest blocks or mutes an author
+type BlockRequest struct {
+	UserEmail string `json:"user_email"`
+	Kind      string `json:"kind,omitempty"` // BlockKindBlock when empty
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Report statuses
+const (
+	ReportOpen      = "open"
+	ReportResolved  = "resolved"  // The message was hidden
+	ReportDismissed = "dismissed" // A moderator took no action
+)
+
+// ReportReasons are the reasons a message can be reported for
+var ReportReasons = []string{"spam", "harassment", "hate", "explicit", "other"}
+
+// MessageReport is a user's report of a global chat message
+type MessageReport struct {
+	ID         int64     `json:"id"`
+	MessageID  int64     `json:"message_id"`
+	ReporterID string    `json:"reporter_id"`
+	Reason     string    `json:"reason"` // One of ReportReasons
+	Details    string    `json:"details,omitempty"`
+	Status     string    `json:"status"`
+	Message    *Message  `json:"message,omitempty"` // The reported message, in the admin report list
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ReportRequest is the body of POST /api/messages/{id}/report
+type ReportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details,omitempty"`
+}
+
+// UserBan keeps a user from posting to global chat until it expires
+type UserBan struct {
+	UserID    string    `json:"user_id"` // Matches the poster's X-User-ID or user_email
+	Reason    string    `json:"reason,omitempty"`
+	BannedBy  string    `json:"banned_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BanRequest is the body of POST /api/admin/bans
+type BanRequest struct {
+	UserID   string `json:"user_id"`
+	Duration string `json:"duration"` // Go duration, e.g. "24h"
+	Reason   string `json:"reason,omitempty"`
+}
+
+// AuditEntry records an action a moderator took
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	Actor      string    `json:"actor"`       // The moderator's user ID
+	Action     string    `json:"action"`      // e.g. "hide_message", "dismiss_report" or "ban_user"
+	TargetType string    `json:"target_type"` // "message", "report" or "user"
+	TargetID   string    `json:"target_id"`
+	Details    string    `json:"details,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
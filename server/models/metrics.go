@@ -0,0 +1,19 @@
+package models
+
+// Metrics is the body of GET /api/admin/metrics
+type Metrics struct {
+	Database DatabasePoolStats `json:"database"`
+}
+
+// DatabasePoolStats describes the database connection pool of the instance
+// answering
+type DatabasePoolStats struct {
+	MaxOpen           int   `json:"max_open"` // 0 for no limit
+	Open              int   `json:"open"`
+	InUse             int   `json:"in_use"`
+	Idle              int   `json:"idle"`
+	WaitCount         int64 `json:"wait_count"`          // Queries that waited for a free connection
+	WaitMs            int64 `json:"wait_ms"`             // Total time spent waiting
+	MaxIdleClosed     int64 `json:"max_idle_closed"`     // Connections closed as more than DB_MAX_IDLE_CONNS were idle
+	MaxLifetimeClosed int64 `json:"max_lifetime_closed"` // Connections closed after DB_CONN_MAX_LIFETIME
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Moderation actions taken on a posted message
+const (
+	ModerationAllow  = "allow"
+	ModerationFlag   = "flag"   // Posted, and queued for review
+	ModerationReject = "reject" // Not posted
+)
+
+// Moderation verdict statuses
+const (
+	ModerationPending  = "pending"  // Flagged and awaiting review
+	ModerationApproved = "approved" // Reviewed and kept
+	ModerationRemoved  = "removed"  // Reviewed and deleted
+	ModerationRejected = "rejected" // Rejected when posted, so there is nothing to review
+)
+
+// ModerationVerdict records why a chat message was flagged or rejected
+type ModerationVerdict struct {
+	ID         int64              `json:"id"`
+	MessageID  *int64             `json:"message_id,omitempty"` // The posted message; unset for rejected or deleted messages
+	UserEmail  string             `json:"user_email"`
+	Username   string             `json:"username"`
+	Text       string             `json:"text"`
+	Action     string             `json:"action"`           // ModerationFlag or ModerationReject
+	Categories []string           `json:"categories"`       // Categories scored at or above the flag threshold
+	Scores     map[string]float64 `json:"scores,omitempty"` // Score of every moderated category, 0-1
+	Classifier string             `json:"classifier"`       // e.g. "openai/omni-moderation-latest" or "local"
+	Status     string             `json:"status"`
+	ReviewedBy string             `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time         `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// ModerationReviewRequest is the body of POST /api/admin/moderation/{id}/review
+type ModerationReviewRequest struct {
+	Decision string `json:"decision"` // "approve" keeps the message, "remove" deletes it
+}
@@ -0,0 +1,29 @@
+package models
+
+// MoodJournalRequest is the body of POST /api/mood/journal
+type MoodJournalRequest struct {
+	Note  string `json:"note"`
+	Entry string `json:"entry,omitempty"` // Timestamp of the mood entry to annotate; the latest entry if unset
+}
+
+// MoodJournalResponse confirms which mood entry a note was attached to
+type MoodJournalResponse struct {
+	Entry string `json:"entry"`
+	Note  string `json:"note"`
+}
+
+// MoodEntry is a detected mood from the user's mood history
+type MoodEntry struct {
+	Timestamp   string   `json:"timestamp"` // RFC 3339; identifies the entry
+	Mood        string   `json:"mood"`
+	PlayedSongs []string `json:"played_songs"`
+	Note        string   `json:"note,omitempty"` // The user's journal note
+}
+
+// MoodInsights summarizes a user's mood history
+type MoodInsights struct {
+	Entries    []MoodEntry    `json:"entries"`            // Oldest first
+	MoodCounts map[string]int `json:"mood_counts"`        // Entries per mood
+	TopMood    string         `json:"top_mood,omitempty"` // Most frequent mood; the latest one wins ties
+	Notes      int            `json:"notes"`              // Entries with a journal note
+}
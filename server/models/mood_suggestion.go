@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// MoodSuggestion is a curated track suggested to everyone feeling a mood
+type MoodSuggestion struct {
+	ID          int64        `json:"id"`
+	Mood        string       `json:"mood"`
+	Track       UnifiedTrack `json:"track"`
+	MatchReason string       `json:"match_reason,omitempty"`
+	MoodScore   float64      `json:"mood_score"` // How well it matches (0-1); higher scores are suggested first
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Recommendation returns the suggestion as shown in chat answers
+func (s MoodSuggestion) Recommendation() MoodBasedRecommendation {
+	return MoodBasedRecommendation{
+		Track:       s.Track,
+		MatchReason: s.MatchReason,
+		MoodScore:   s.MoodScore,
+	}
+}
+
+// MoodSuggestionsResponse lists curated suggestions
+type MoodSuggestionsResponse struct {
+	Suggestions []MoodSuggestion `json:"suggestions"`
+}
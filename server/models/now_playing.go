@@ -5,6 +5,13 @@ import (
 	"time"
 )
 
+// Playback states
+const (
+	PlaybackPlaying = "playing"
+	PlaybackPaused  = "paused"
+	PlaybackStopped = "stopped"
+)
+
 // NowPlaying represents the currently playing song
 type NowPlaying struct {
 	TrackID   string `json:"track_id"`
@@ -12,9 +19,24 @@ type NowPlaying struct {
 	Artist    string `json:"artist"`
 	Album     string `json:"album"`
 	Source    string `json:"source,omitempty"`
+	Explicit  bool   `json:"explicit,omitempty"`
 	Lyrics    string `json:"lyrics,omitempty"`
+	State     string    `json:"state,omitempty"` // "playing" | "paused" | "stopped"
 	UpdatedAt time.Time `json:"updated_at"`
-	mutex     sync.RWMutex
+	Version   int64     `json:"version"` // Incremented on every change of the track or its state, used as the ETag
+
+	// Playback progress reported by client heartbeats. Clients showing synced
+	// lyrics extrapolate the position from PositionAt while playing.
+	PositionMs int64     `json:"position_ms"`
+	DurationMs int64     `json:"duration_ms,omitempty"`
+	PositionAt time.Time `json:"position_at,omitempty"`
+	ListenedMs int64     `json:"listened_ms"` // Time actually spent listening, excluding seeks
+
+	// Derived when the state is read: the position extrapolated to that
+	// moment while playing, capped at the duration, and whether it is paused
+	ProgressMs int64 `json:"progress_ms"`
+	Paused     bool  `json:"is_paused"`
+	mutex      sync.RWMutex
 }
 
 // NewNowPlaying creates a new NowPlaying instance
@@ -32,13 +54,170 @@ func (np *NowPlaying) UpdateUnified(track UnifiedTrack) {
 	np.mutex.Lock()
 	defer np.mutex.Unlock()
 	
+	np.set(track)
+}
+
+// UpdateUnifiedIfVersion updates the track only if the current version matches
+// the expected one. It returns false without modifying state when another writer
+// has changed the track in the meantime.
+func (np *NowPlaying) UpdateUnifiedIfVersion(track UnifiedTrack, version int64) bool {
+	np.mutex.Lock()
+	defer np.mutex.Unlock()
+	
+	if np.Version != version {
+		return false
+	}
+	np.set(track)
+	return true
+}
+
+// set replaces the current track; an update of the current track only
+// refreshes it. Callers must hold the write lock.
+func (np *NowPlaying) set(track UnifiedTrack) {
+	if track.ID != "" && track.ID == np.TrackID {
+		np.refresh(track)
+		return
+	}
 	np.TrackID = track.ID
 	np.TrackName = track.Name
 	np.Artist = track.Artist
 	np.Album = track.Album
 	np.Source = track.Source
+	np.Explicit = track.Explicit
 	np.Lyrics = "" // Reset lyrics for new track
+	np.State = PlaybackPlaying
+	np.UpdatedAt = time.Now()
+	np.Version++
+	np.resetProgress()
+}
+
+// refresh updates the details of the current track from a client re-syncing
+// it, keeping its lyrics, state and playback progress. The version only
+// changes if the details did, so re-syncs don't invalidate ETags. Callers
+// must hold the write lock.
+func (np *NowPlaying) refresh(track UnifiedTrack) {
+	changed := np.TrackName != track.Name || np.Artist != track.Artist || np.Album != track.Album ||
+		np.Source != track.Source || np.Explicit != track.Explicit
+	np.TrackName = track.Name
+	np.Artist = track.Artist
+	np.Album = track.Album
+	np.Source = track.Source
+	np.Explicit = track.Explicit
+	np.UpdatedAt = time.Now()
+	if changed {
+		np.Version++
+	}
+}
+
+// resetProgress clears heartbeat progress; callers must hold the write lock
+func (np *NowPlaying) resetProgress() {
+	np.PositionMs = 0
+	np.DurationMs = 0
+	np.PositionAt = time.Time{}
+	np.ListenedMs = 0
+}
+
+// Heartbeat records the playback position of the current track and returns
+// the total listening time. Position advances count as listening time only up
+// to the wall-clock time since the previous heartbeat, so seeking forward is
+// not counted. It returns false if trackID is not the current track; an empty
+// trackID matches any current track. Heartbeats keep the track from going
+// stale but don't change its version.
+func (np *NowPlaying) Heartbeat(trackID string, positionMs, durationMs int64) (int64, bool) {
+	np.mutex.Lock()
+	defer np.mutex.Unlock()
+	
+	if np.TrackID == "" || (trackID != "" && trackID != np.TrackID) {
+		return 0, false
+	}
+	
+	now := time.Now()
+	if np.State == PlaybackPlaying && !np.PositionAt.IsZero() {
+		advanced := positionMs - np.PositionMs
+		elapsed := now.Sub(np.PositionAt).Milliseconds()
+		if advanced > elapsed {
+			advanced = elapsed
+		}
+		if advanced > 0 {
+			np.ListenedMs += advanced
+		}
+	}
+	
+	np.PositionMs = positionMs
+	np.DurationMs = durationMs
+	np.PositionAt = now
+	np.UpdatedAt = now
+	return np.ListenedMs, true
+}
+
+// SetState safely changes the playback state of the current track.
+// Stopping clears the track. It returns false if there is no track to change.
+func (np *NowPlaying) SetState(state string) bool {
+	np.mutex.Lock()
+	defer np.mutex.Unlock()
+	
+	if np.TrackID == "" {
+		return false
+	}
+	
+	if state == PlaybackStopped {
+		np.stop()
+		return true
+	}
+	np.State = state
+	np.UpdatedAt = time.Now()
+	np.Version++
+	return true
+}
+
+// StopIfStale stops the current track if it has not been updated within ttl.
+// It returns true if a stale track was stopped.
+func (np *NowPlaying) StopIfStale(ttl time.Duration) bool {
+	np.mutex.Lock()
+	defer np.mutex.Unlock()
+	
+	if np.TrackID == "" || time.Since(np.UpdatedAt) < ttl {
+		return false
+	}
+	np.stop()
+	return true
+}
+
+// stop clears the current track; callers must hold the write lock
+func (np *NowPlaying) stop() {
+	np.TrackID = ""
+	np.TrackName = ""
+	np.Artist = ""
+	np.Album = ""
+	np.Source = ""
+	np.Explicit = false
+	np.Lyrics = ""
+	np.State = PlaybackStopped
 	np.UpdatedAt = time.Now()
+	np.Version++
+	np.resetProgress()
+}
+
+// Restore replaces the state with a snapshot taken by Get, e.g. before a
+// restart. Derived fields are recomputed when read.
+func (np *NowPlaying) Restore(snapshot *NowPlaying) {
+	np.mutex.Lock()
+	defer np.mutex.Unlock()
+	
+	np.TrackID = snapshot.TrackID
+	np.TrackName = snapshot.TrackName
+	np.Artist = snapshot.Artist
+	np.Album = snapshot.Album
+	np.Source = snapshot.Source
+	np.Explicit = snapshot.Explicit
+	np.Lyrics = snapshot.Lyrics
+	np.State = snapshot.State
+	np.UpdatedAt = snapshot.UpdatedAt
+	np.Version = snapshot.Version
+	np.PositionMs = snapshot.PositionMs
+	np.DurationMs = snapshot.DurationMs
+	np.PositionAt = snapshot.PositionAt
+	np.ListenedMs = snapshot.ListenedMs
 }
 
 // UpdateLyrics safely updates the lyrics
@@ -46,6 +225,7 @@ func (np *NowPlaying) UpdateLyrics(lyrics string) {
 	np.mutex.Lock()
 	defer np.mutex.Unlock()
 	np.Lyrics = lyrics
+	np.UpdatedAt = time.Now() // So cached copies without the lyrics go stale
 }
 
 // Get safely returns a copy of the current playing track
@@ -59,9 +239,32 @@ func (np *NowPlaying) Get() NowPlaying {
 		Artist:    np.Artist,
 		Album:     np.Album,
 		Source:    np.Source,
+		Explicit:  np.Explicit,
 		Lyrics:    np.Lyrics,
+		State:     np.State,
 		UpdatedAt: np.UpdatedAt,
+		Version:   np.Version,
+		
+		PositionMs: np.PositionMs,
+		DurationMs: np.DurationMs,
+		PositionAt: np.PositionAt,
+		ListenedMs: np.ListenedMs,
+		
+		ProgressMs: np.progress(time.Now()),
+		Paused:     np.State == PlaybackPaused,
+	}
+}
+
+// progress estimates the playback position at now; callers must hold the lock
+func (np *NowPlaying) progress(now time.Time) int64 {
+	progress := np.PositionMs
+	if np.State == PlaybackPlaying && !np.PositionAt.IsZero() {
+		progress += now.Sub(np.PositionAt).Milliseconds()
 	}
+	if np.DurationMs > 0 && progress > np.DurationMs {
+		progress = np.DurationMs
+	}
+	return progress
 }
 
 // IsEmpty checks if there's no song currently playing
@@ -72,6 +275,14 @@ func (np *NowPlaying) IsEmpty() bool {
 	return np.TrackID == "" || np.TrackName == ""
 }
 
+// IsPaused checks if the current track is paused
+func (np *NowPlaying) IsPaused() bool {
+	np.mutex.RLock()
+	defer np.mutex.RUnlock()
+	
+	return np.State == PlaybackPaused
+}
+
 // GetInfo returns formatted song information
 func (np *NowPlaying) GetInfo() string {
 	np.mutex.RLock()
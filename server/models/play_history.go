@@ -1,18 +1,64 @@
 package models
 
 import (
+	"strings"
 	"sync"
 	"time"
 )
 
 // PlayHistoryItem represents a single song in play history
 type PlayHistoryItem struct {
-	TrackID   string    `json:"track_id"`
-	TrackName string    `json:"track_name"`
-	Artist    string    `json:"artist"`
-	Album     string    `json:"album"`
-	Source    string    `json:"source,omitempty"`
-	PlayedAt  time.Time `json:"played_at"`
+	TrackID    string    `json:"track_id"`
+	TrackName  string    `json:"track_name"`
+	Artist     string    `json:"artist"`
+	Album      string    `json:"album"`
+	Source     string    `json:"source,omitempty"`
+	PlayedAt   time.Time `json:"played_at"`
+	ListenedMs int64     `json:"listened_ms,omitempty"` // Listening time of the latest play, reported by heartbeats
+	
+	// Consecutive plays of the same track within the repeat window are
+	// collapsed into one item
+	PlayCount    int       `json:"play_count"`
+	LastPlayedAt time.Time `json:"last_played_at"`
+}
+
+// PlayHistoryFilter selects history items; zero fields match everything
+type PlayHistoryFilter struct {
+	Source string    // Exact source, e.g. "spotify"
+	Artist string    // Artist, matched case-insensitively
+	From   time.Time // Played at or after
+	To     time.Time // Played before
+}
+
+// Matches reports whether item passes the filter
+func (f PlayHistoryFilter) Matches(item PlayHistoryItem) bool {
+	if f.Source != "" && item.Source != f.Source {
+		return false
+	}
+	if f.Artist != "" && !strings.EqualFold(item.Artist, f.Artist) {
+		return false
+	}
+	if !f.From.IsZero() && item.PlayedAt.Before(f.From) {
+		return false
+	}
+	return f.To.IsZero() || item.PlayedAt.Before(f.To)
+}
+
+// Page returns up to limit of the items matching the filter, after skipping
+// offset of them, along with the number of matching items
+func (f PlayHistoryFilter) Page(items []PlayHistoryItem, offset, limit int) ([]PlayHistoryItem, int) {
+	page := make([]PlayHistoryItem, 0)
+	total := 0
+	for _, item := range items {
+		if !f.Matches(item) {
+			continue
+		}
+		if total >= offset && len(page) < limit {
+			page = append(page, item)
+		}
+		total++
+	}
+	return page, total
 }
 
 // PlayHistory stores recently played tracks
@@ -20,6 +66,7 @@ type PlayHistory struct {
 	items []PlayHistoryItem
 	mutex sync.RWMutex
 	maxItems int
+	repeatWindow time.Duration
 }
 
 // NewPlayHistory creates a new PlayHistory instance
@@ -30,6 +77,14 @@ func NewPlayHistory(maxItems int) *PlayHistory {
 	}
 }
 
+// SetRepeatWindow sets how long after its latest play a repeat of the most
+// recent track is collapsed into it instead of being added; 0 disables this
+func (ph *PlayHistory) SetRepeatWindow(window time.Duration) {
+	ph.mutex.Lock()
+	defer ph.mutex.Unlock()
+	ph.repeatWindow = window
+}
+
 // Add adds a new track from SpotifyTrack to the history
 func (ph *PlayHistory) Add(track SpotifyTrack) {
 	ph.AddUnified(FromSpotifyTrack(track))
@@ -37,18 +92,21 @@ func (ph *PlayHistory) Add(track SpotifyTrack) {
 
 // AddUnified adds a new UnifiedTrack to the history
 func (ph *PlayHistory) AddUnified(track UnifiedTrack) {
+	ph.AddPlayed(track, time.Now(), 0)
+}
+
+// AddPlayed adds a UnifiedTrack that started playing at playedAt and has
+// already been listened to for listenedMs
+func (ph *PlayHistory) AddPlayed(track UnifiedTrack, playedAt time.Time, listenedMs int64) {
 	ph.mutex.Lock()
 	defer ph.mutex.Unlock()
 	
-	item := PlayHistoryItem{
-		TrackID:   track.ID,
-		TrackName: track.Name,
-		Artist:    track.Artist,
-		Album:     track.Album,
-		Source:    track.Source,
-		PlayedAt:  time.Now(),
+	if len(ph.items) > 0 && ph.items[0].Repeat(track, playedAt, listenedMs, ph.repeatWindow) {
+		return
 	}
 	
+	item := NewPlayHistoryItem(track, playedAt, listenedMs)
+	
 	// Add to beginning
 	ph.items = append([]PlayHistoryItem{item}, ph.items...)
 	
@@ -58,6 +116,56 @@ func (ph *PlayHistory) AddUnified(track UnifiedTrack) {
 	}
 }
 
+// NewPlayHistoryItem creates the history item for a track that started
+// playing at playedAt
+func NewPlayHistoryItem(track UnifiedTrack, playedAt time.Time, listenedMs int64) PlayHistoryItem {
+	return PlayHistoryItem{
+		TrackID:      track.ID,
+		TrackName:    track.Name,
+		Artist:       track.Artist,
+		Album:        track.Album,
+		Source:       track.Source,
+		PlayedAt:     playedAt,
+		ListenedMs:   listenedMs,
+		PlayCount:    1,
+		LastPlayedAt: playedAt,
+	}
+}
+
+// Repeat folds a repeat of the item's track within window of its latest
+// play into the item, returning false if track is not such a repeat. A
+// repeat that starts once most of the previous play could have finished is
+// counted as a loop play; earlier ones, such as a client re-syncing, are
+// ignored, as are all repeats of tracks without a known duration.
+func (item *PlayHistoryItem) Repeat(track UnifiedTrack, playedAt time.Time, listenedMs int64, window time.Duration) bool {
+	if window <= 0 || item.TrackID != track.ID {
+		return false
+	}
+	
+	elapsed := playedAt.Sub(item.LastPlayedAt)
+	if elapsed > window {
+		return false
+	}
+	
+	duration := time.Duration(track.Duration) * time.Second
+	if duration > 0 && elapsed >= duration*9/10 {
+		item.PlayCount++
+		item.LastPlayedAt = playedAt
+		item.ListenedMs = listenedMs
+	}
+	return true
+}
+
+// UpdateListened sets the listening time of the most recent entry if it is trackID
+func (ph *PlayHistory) UpdateListened(trackID string, listenedMs int64) {
+	ph.mutex.Lock()
+	defer ph.mutex.Unlock()
+	
+	if len(ph.items) > 0 && ph.items[0].TrackID == trackID {
+		ph.items[0].ListenedMs = listenedMs
+	}
+}
+
 // GetItems returns a copy of all history items
 func (ph *PlayHistory) GetItems() []PlayHistoryItem {
 	ph.mutex.RLock()
@@ -67,4 +175,13 @@ func (ph *PlayHistory) GetItems() []PlayHistoryItem {
 	itemsCopy := make([]PlayHistoryItem, len(ph.items))
 	copy(itemsCopy, ph.items)
 	return itemsCopy
-}
\ No newline at end of file
+}
+
+// Query returns up to limit items matching filter, most recent first, after
+// skipping offset of them, along with the number of matching items
+func (ph *PlayHistory) Query(filter PlayHistoryFilter, offset, limit int) ([]PlayHistoryItem, int) {
+	ph.mutex.RLock()
+	defer ph.mutex.RUnlock()
+	
+	return filter.Page(ph.items, offset, limit)
+}
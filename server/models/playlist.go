@@ -0,0 +1,10 @@
+package models
+
+// PlaylistRequest asks for mood recommendations to be saved as a playlist on
+// the user's Spotify account
+type PlaylistRequest struct {
+	Recommendations MoodRecommendations `json:"recommendations"` // As returned by a mood_recommendation chat response
+	Mood            string              `json:"mood,omitempty"`  // The detected primary mood, used for the default name and description
+	Name            string              `json:"name,omitempty"`  // Overrides the default name
+	Lang            string              `json:"lang,omitempty"`  // ISO 639-1 code of the answer, name and description
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Presence is a user who is online
+type Presence struct {
+	UserID     string         `json:"user_id"`
+	Username   string         `json:"username,omitempty"`
+	NowPlaying *PresenceTrack `json:"now_playing,omitempty"` // Only if the user shares what they're playing
+	LastSeen   time.Time      `json:"last_seen"`
+}
+
+// PresenceTrack is the track an online user is playing
+type PresenceTrack struct {
+	TrackID   string `json:"track_id"`
+	TrackName string `json:"track_name"`
+	Artist    string `json:"artist"`
+	Album     string `json:"album,omitempty"`
+}
+
+// PresenceHeartbeat is the body of POST /api/presence/heartbeat
+type PresenceHeartbeat struct {
+	Username       string         `json:"username,omitempty"`
+	ShareListening bool           `json:"share_listening"` // Opts in to showing NowPlaying to others
+	NowPlaying     *PresenceTrack `json:"now_playing,omitempty"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// QuizGlobalRoom is the room of quizzes played in global chat
+const QuizGlobalRoom = GlobalRoom
+
+// QuizRound is a question: which recently played song is the snippet from?
+type QuizRound struct {
+	Room    string    `json:"room"`
+	Number  int       `json:"number"` // Starting at 1
+	Of      int       `json:"of"`     // Rounds in the game
+	Snippet string    `json:"snippet"`
+	Choices []string  `json:"choices"` // Track names; answers must match one
+	EndsAt  time.Time `json:"ends_at"`
+}
+
+// QuizRoundResult reveals the answer to a round once it has closed
+type QuizRoundResult struct {
+	Room      string         `json:"room"`
+	Number    int            `json:"number"`
+	TrackName string         `json:"track_name"`
+	Artist    string         `json:"artist"`
+	Points    map[string]int `json:"points"` // Points scored this round by user ID
+}
+
+// QuizAnswerResult tells a player how their answer scored
+type QuizAnswerResult struct {
+	Number  int  `json:"number"`
+	Correct bool `json:"correct"`
+	Points  int  `json:"points"`
+}
+
+// QuizScore is a player's score, in a game or on a room's leaderboard
+type QuizScore struct {
+	UserID         string `json:"user_id"`
+	Points         int    `json:"points"`
+	CorrectAnswers int    `json:"correct_answers"`
+	GamesPlayed    int    `json:"games_played"`
+}
+
+// QuizGame is the state of a room's quiz
+type QuizGame struct {
+	Room      string      `json:"room"`
+	Rounds    int         `json:"rounds"`
+	StartedAt time.Time   `json:"started_at"`
+	Round     *QuizRound  `json:"round,omitempty"` // The open round, if any
+	Standings []QuizScore `json:"standings"`       // Highest first
+	Finished  bool        `json:"finished"`
+}
+
+// QuizLeaderboardResponse is the response of GET /api/quiz/leaderboard
+type QuizLeaderboardResponse struct {
+	Room   string      `json:"room"`
+	Scores []QuizScore `json:"scores"`
+}
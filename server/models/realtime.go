@@ -0,0 +1,31 @@
+package models
+
+// Realtime event types sent over WebSocket connections
+const (
+	RealtimeNowPlaying   = "now_playing"       // Data: NowPlaying, sent when a client connects
+	RealtimeTrackChanged = "track_changed"     // Data: UnifiedTrack
+	RealtimeMessage      = "message"           // Data: Message
+	RealtimeReactions    = "message_reactions" // Data: MessageReactions
+	RealtimeRemoved      = "message_removed"   // Data: Message with only its ID, removed by a moderator
+	RealtimeTyping       = "typing"            // Data: TypingIndicator, never stored
+	RealtimeError        = "error"             // A client message was rejected; the connection stays open
+	RealtimeQuiz         = "quiz"              // Data: QuizGame, sent on connect while a game runs
+	RealtimeQuizRound    = "quiz_round"        // Data: QuizRound
+	RealtimeQuizResult   = "quiz_result"       // Data: QuizRoundResult
+	RealtimeQuizEnded    = "quiz_ended"        // Data: QuizGame with the final standings
+	RealtimeQuizAnswer   = "quiz_answer"       // Data: QuizAnswerResult, sent only to the player who answered
+)
+
+// RealtimeEvent is one message sent to WebSocket clients
+type RealtimeEvent struct {
+	Type  string      `json:"type"`
+	Data  interface{} `json:"data,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// TypingIndicator is a user starting or stopping typing in a chat room
+type TypingIndicator struct {
+	Room   string `json:"room"`
+	UserID string `json:"user_id"`
+	Typing bool   `json:"typing"` // False when they stop, post or their typing expires
+}
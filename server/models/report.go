@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// WeeklyReport summarizes a user's moods and listening over one week
+type WeeklyReport struct {
+	UserID        string                 `json:"user_id"`
+	WeekStart     time.Time              `json:"week_start"` // Monday 00:00 UTC
+	WeekEnd       time.Time              `json:"week_end"`   // The following Monday, exclusive
+	MoodEntries   int                    `json:"mood_entries"`
+	MoodCounts    map[string]int         `json:"mood_counts"`
+	DominantMoods []string               `json:"dominant_moods"` // Most frequent first
+	Correlations  []MoodMusicCorrelation `json:"correlations"`   // What was played after each mood, most frequent mood first
+	NotableTracks []ReportTrack          `json:"notable_tracks"` // Most played tracks of the week
+	Summary       string                 `json:"summary"`        // Written by the AI provider, or from a template if it fails
+	GeneratedAt   time.Time              `json:"generated_at"`
+}
+
+// MoodMusicCorrelation is the music played shortly after a mood was detected
+type MoodMusicCorrelation struct {
+	Mood       string   `json:"mood"`
+	Plays      int      `json:"plays"`
+	TopArtists []string `json:"top_artists"` // Most played first
+}
+
+// ReportTrack is a track with how often it was played in the report's week
+type ReportTrack struct {
+	TrackID string `json:"track_id"`
+	Name    string `json:"name"`
+	Artist  string `json:"artist"`
+	Plays   int    `json:"plays"`
+}
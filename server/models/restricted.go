@@ -0,0 +1,13 @@
+package models
+
+// RestrictedModeStatus describes whether a user is in restricted (parental/teen) mode
+type RestrictedModeStatus struct {
+	UserID     string `json:"user_id"`
+	Restricted bool   `json:"restricted"`           // Effective setting
+	Deployment bool   `json:"deployment,omitempty"` // Enforced for every user by configuration
+}
+
+// RestrictedModeRequest is the body of PUT /api/users/{userID}/restricted-mode
+type RestrictedModeRequest struct {
+	Restricted bool `json:"restricted"`
+}
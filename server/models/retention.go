@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RetentionReport summarizes one retention pass
+type RetentionReport struct {
+	StartedAt           time.Time `json:"started_at"`
+	FinishedAt          time.Time `json:"finished_at"`
+	MoodEntriesArchived int       `json:"mood_entries_archived"`
+	MessagesArchived    int       `json:"messages_archived"`
+	Archives            []string  `json:"archives"`         // Names of the archives written
+	Errors              []string  `json:"errors,omitempty"` // Failures; the affected rows were kept
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// GlobalRoom is the global chat, the only chat room until chat has others
+const GlobalRoom = "global"
+
+// Room is a chat room as seen by a user, with their unread count
+type Room struct {
+	ID                string `json:"id"`
+	LastReadMessageID int64  `json:"last_read_message_id"` // 0 if the user never read the room
+	LatestMessageID   int64  `json:"latest_message_id"`    // 0 if the room is empty
+	UnreadCount       int    `json:"unread_count"`         // Messages and replies after the last read one, less the user's own
+}
+
+// ReadMarker is the last message a user read in a room
+type ReadMarker struct {
+	Room              string     `json:"room"`
+	LastReadMessageID int64      `json:"last_read_message_id"`
+	UnreadCount       int        `json:"unread_count"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty"` // Unset if the user never read the room
+}
+
+// ReadMarkerRequest is the body of PUT /api/rooms/{room}/read
+type ReadMarkerRequest struct {
+	MessageID int64 `json:"message_id"`
+}
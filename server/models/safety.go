@@ -0,0 +1,9 @@
+package models
+
+// Helpline is a support resource offered when a message indicates a crisis
+type Helpline struct {
+	Region string `json:"region"` // ISO 3166-1 alpha-2 code, or "*" for international resources
+	Name   string `json:"name"`
+	Phone  string `json:"phone,omitempty"` // Number to call or text
+	URL    string `json:"url,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ScheduledJob describes a periodic background job and its latest run
+type ScheduledJob struct {
+	Name           string     `json:"name"`
+	Interval       string     `json:"interval"` // e.g. "24h0m0s", before jitter
+	Enabled        bool       `json:"enabled"`  // False when listed in JOBS_DISABLED
+	Running        bool       `json:"running"`
+	Runs           int        `json:"runs"`
+	Failures       int        `json:"failures"`
+	Skipped        int        `json:"skipped"` // Runs skipped because the previous one was still going
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"` // From the latest finished run
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+}
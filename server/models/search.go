@@ -0,0 +1,10 @@
+package models
+
+// SearchSuggestion represents a lightweight autocomplete result
+type SearchSuggestion struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Artist   string `json:"artist"`
+	Source   string `json:"source"`
+	ImageURL string `json:"image_url,omitempty"`
+}
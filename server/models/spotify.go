@@ -7,6 +7,7 @@ type SpotifyTrack struct {
 	Artist     string `json:"artist"`
 	Album      string `json:"album"`
 	PreviewURL string `json:"preview_url,omitempty"`
+	Explicit   bool   `json:"explicit,omitempty"`
 }
 
 // SpotifyTokenResponse represents the response from Spotify token API
@@ -14,4 +15,45 @@ type SpotifyTokenResponse struct {
 	AccessToken string `json:"access_token"`
 	TokenType   string `json:"token_type"`
 	ExpiresIn   int    `json:"expires_in"`
-}
\ No newline at end of file
+}
+
+// SpotifyArtist represents an artist from Spotify
+type SpotifyArtist struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Genres     []string          `json:"genres,omitempty"`
+	Images     []SpotifyImage    `json:"images,omitempty"` // Largest first
+	Followers  *SpotifyFollowers `json:"followers,omitempty"`
+	Popularity int               `json:"popularity,omitempty"` // 0-100
+}
+
+// SpotifyAlbum represents an album from Spotify with its tracks
+type SpotifyAlbum struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Artist      string         `json:"artist"`
+	ReleaseDate string         `json:"release_date,omitempty"` // A year, year-month or full date, as Spotify knows it
+	Images      []SpotifyImage `json:"images,omitempty"`       // Largest first
+	URL         string         `json:"url,omitempty"`          // Opens the album in Spotify
+	Tracks      []UnifiedTrack `json:"tracks"`                 // In album order
+}
+
+// SpotifyImage is an image of an artist or album in one size
+type SpotifyImage struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+// SpotifyFollowers counts an artist's followers on Spotify
+type SpotifyFollowers struct {
+	Total int `json:"total"`
+}
+
+// SpotifyPlaylist represents a playlist created on a user's Spotify account
+type SpotifyPlaylist struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	URL        string `json:"url"` // Opens the playlist in Spotify
+	TrackCount int    `json:"track_count"`
+}
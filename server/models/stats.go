@@ -0,0 +1,19 @@
+package models
+
+// DailyUserStats is a per-user, per-day aggregate maintained from events
+type DailyUserStats struct {
+	UserID                string         `json:"user_id"`
+	Day                   string         `json:"day"` // UTC date, e.g. 2024-05-01
+	TracksPlayed          int            `json:"tracks_played"`
+	MessagesPosted        int            `json:"messages_posted"`
+	MoodsDetected         int            `json:"moods_detected"`
+	RecommendationsServed int            `json:"recommendations_served"`
+	Moods                 map[string]int `json:"moods"` // Detections per mood
+}
+
+// UserStats is the response of GET /api/stats
+type UserStats struct {
+	UserID string           `json:"user_id"`
+	Days   []DailyUserStats `json:"days"`  // Oldest first, only days with activity
+	Total  DailyUserStats   `json:"total"` // Sum over Days; Day is empty
+}
@@ -0,0 +1,29 @@
+package models
+
+// TrackReference identifies a track for bulk lookups
+type TrackReference struct {
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name"`
+	Artist string `json:"artist"`
+}
+
+// TrackMoodsRequest represents a bulk mood lookup request
+type TrackMoodsRequest struct {
+	Tracks  []TrackReference `json:"tracks"`
+	Analyze bool             `json:"analyze,omitempty"` // Run AI analysis for tracks missing from the cache
+	Async   bool             `json:"async,omitempty"`   // Queue the analysis as a track_moods job instead of waiting for it
+}
+
+// TrackMoodResult represents the mood lookup result for a single track
+type TrackMoodResult struct {
+	Track        TrackReference `json:"track"`
+	Cached       bool           `json:"cached"`                  // Whether the analysis came from the cache
+	MoodAnalysis *MoodAnalysis  `json:"mood_analysis,omitempty"` // Absent when no analysis is available
+	Themes       []string       `json:"themes,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// TrackMoodsResponse represents a bulk mood lookup response
+type TrackMoodsResponse struct {
+	Results []TrackMoodResult `json:"results"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// TrendingTrack is a track ranked by recent plays
+type TrendingTrack struct {
+	Track UnifiedTrack `json:"track"`
+	Plays int          `json:"plays"` // Plays within the trending window
+}
+
+// TrendingResponse is the response of GET /api/trending
+type TrendingResponse struct {
+	Window string          `json:"window"` // e.g. "1h0m0s"
+	Tracks []TrendingTrack `json:"tracks"`
+}
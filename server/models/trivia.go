@@ -0,0 +1,28 @@
+package models
+
+// TriviaQuestion is a multiple-choice question of a chat quiz. The correct
+// choice stays on the server.
+type TriviaQuestion struct {
+	Number   int      `json:"number"` // 1-based
+	Question string   `json:"question"`
+	Choices  []string `json:"choices"`
+}
+
+// TriviaResult is the outcome of an answer to a chat quiz question
+type TriviaResult struct {
+	Correct     bool   `json:"correct"`
+	Answer      int    `json:"answer"` // Index of the correct choice
+	Choice      string `json:"choice"` // Text of the correct choice
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// TriviaQuiz is the state of a user's chat quiz, returned with every quiz answer
+type TriviaQuiz struct {
+	Topic    string          `json:"topic"`
+	Score    int             `json:"score"`    // Correct answers so far
+	Answered int             `json:"answered"` // Questions answered so far
+	Total    int             `json:"total"`
+	Result   *TriviaResult   `json:"result,omitempty"`   // Present after an answer
+	Question *TriviaQuestion `json:"question,omitempty"` // The question to answer next; missing once finished
+	Finished bool            `json:"finished"`
+}
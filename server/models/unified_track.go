@@ -6,11 +6,13 @@ type UnifiedTrack struct {
 	Name       string `json:"name"`
 	Artist     string `json:"artist"`
 	Album      string `json:"album,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty"` // The album's, as the source knows it, e.g. "2003" or "2003-03-25"
 	Source     string `json:"source"`      // "spotify" or "youtube"
 	PreviewURL string `json:"preview_url,omitempty"`
 	ExternalURL string `json:"external_url,omitempty"`
 	Duration   int    `json:"duration,omitempty"` // duration in seconds
 	ImageURL   string `json:"image_url,omitempty"`
+	Explicit   bool   `json:"explicit,omitempty"` // Flagged explicit by the source
 }
 
 // ToSpotifyTrack converts UnifiedTrack to SpotifyTrack for backward compatibility
@@ -21,6 +23,7 @@ func (t UnifiedTrack) ToSpotifyTrack() SpotifyTrack {
 		Artist:     t.Artist,
 		Album:      t.Album,
 		PreviewURL: t.PreviewURL,
+		Explicit:   t.Explicit,
 	}
 }
 
@@ -33,6 +36,7 @@ func FromSpotifyTrack(track SpotifyTrack) UnifiedTrack {
 		Album:      track.Album,
 		Source:     "spotify",
 		PreviewURL: track.PreviewURL,
+		Explicit:   track.Explicit,
 	}
 }
 
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// User is a user's profile. Users are registered on their first request
+// with an API key and X-User-ID.
+type User struct {
+	ID             string    `json:"id"`                 // The X-User-ID the user sends
+	Email          string    `json:"email,omitempty"`    // Taken from the ID on registration if it is an email address
+	Username       string    `json:"username,omitempty"` // Unique, ignoring case
+	DisplayName    string    `json:"display_name,omitempty"`
+	Bio            string    `json:"bio,omitempty"`
+	FavoriteGenres []string  `json:"favorite_genres"`
+	AvatarURL      string    `json:"avatar_url,omitempty"` // Set by uploading an avatar
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Name is what chat shows for the user: their display name, else their
+// username
+func (u User) Name() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Username
+}
+
+// UserUpdate is the body of PATCH /api/users/me. Fields left out are kept;
+// empty strings clear them.
+type UserUpdate struct {
+	Email          *string   `json:"email,omitempty"`
+	Username       *string   `json:"username,omitempty"`
+	DisplayName    *string   `json:"display_name,omitempty"`
+	Bio            *string   `json:"bio,omitempty"`
+	FavoriteGenres *[]string `json:"favorite_genres,omitempty"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Webhook is a URL that receives signed POSTs of selected events
+type Webhook struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`           // Event types delivered, e.g. "track_changed"
+	Secret    string    `json:"secret,omitempty"` // Signing secret; only returned when the webhook is created
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateWebhookRequest registers a webhook. Events default to all webhook
+// events, and a secret is generated if none is given.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+// WebhooksResponse lists registered webhooks, without their secrets
+type WebhooksResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
@@ -0,0 +1,101 @@
+// Package pagination pages list endpoints with opaque cursors. Clients ask
+// for ?limit= items and pass the X-Next-Cursor header of one page as
+// ?cursor= to get the next; the header is left out on the last page. A
+// cursor is the base64 of the sort keys of its page's last item, e.g.
+//
+//	GET /api/messages?limit=50&cursor=WyIyMDI0LTA1LTAxVDEyOjAwOjAwWiIsNDJd
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// NextCursorHeader carries the cursor of the next page
+const NextCursorHeader = "X-Next-Cursor"
+
+// ErrInvalidCursor is returned for cursors that don't decode to the expected
+// number of sort keys. Cursors aren't tied to an endpoint, so one made for
+// another endpoint with keys of the same types isn't caught.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page is the paging of a list request
+type Page struct {
+	Limit  int
+	Cursor string // Empty for the first page
+}
+
+// Parse reads limit and cursor from query. Without a limit, defaultLimit
+// items are returned; larger limits are capped at maxLimit.
+func Parse(query url.Values, defaultLimit, maxLimit int) (Page, error) {
+	page := Page{Limit: defaultLimit, Cursor: query.Get("cursor")}
+	if limitParam := query.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			return page, errors.New("Invalid limit")
+		}
+		page.Limit = limit
+	}
+	if page.Limit > maxLimit {
+		page.Limit = maxLimit
+	}
+	return page, nil
+}
+
+// Requested reports whether query asks for a page, for endpoints that
+// return everything unless it does
+func Requested(query url.Values) bool {
+	return query.Get("limit") != "" || query.Get("cursor") != ""
+}
+
+// Encode makes the cursor of an item with the given sort keys, which must
+// marshal to JSON
+func Encode(keys ...interface{}) string {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode reads the page's cursor into keys, pointers to the sort keys in the
+// order they were encoded. It returns false for the first page, which has no
+// cursor, and ErrInvalidCursor for a malformed one.
+func (p Page) Decode(keys ...interface{}) (bool, error) {
+	if p.Cursor == "" {
+		return false, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return false, ErrInvalidCursor
+	}
+	var values []json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil || len(values) != len(keys) {
+		return false, ErrInvalidCursor
+	}
+	for i, value := range values {
+		if err := json.Unmarshal(value, keys[i]); err != nil {
+			return false, ErrInvalidCursor
+		}
+	}
+	return true, nil
+}
+
+// SetNext sends the cursor of the page after r's in X-Next-Cursor, and the
+// URL of that page in a Link header. An empty cursor, on the last page,
+// sends neither.
+func SetNext(w http.ResponseWriter, r *http.Request, cursor string) {
+	if cursor == "" {
+		return
+	}
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+	next := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+
+	w.Header().Set(NextCursorHeader, cursor)
+	w.Header().Set("Link", "<"+next.String()+`>; rel="next"`)
+}
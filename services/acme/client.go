@@ -0,0 +1,318 @@
+package acme
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pollAttempts bounds how often a pending authorization or order is checked
+const pollAttempts = 60
+
+// client speaks the ACME protocol (RFC 8555) with an account key
+type client struct {
+	directoryURL string
+	email        string
+	key          *ecdsa.PrivateKey
+	pollInterval time.Duration
+	httpClient   *http.Client
+
+	directory struct {
+		NewNonce   string `json:"newNonce"`
+		NewAccount string `json:"newAccount"`
+		NewOrder   string `json:"newOrder"`
+	}
+	kid   string // Account URL, once registered
+	nonce string // Replay-Nonce from the latest response
+}
+
+// order is an ACME order resource
+type order struct {
+	Status         string   `json:"status"`
+	Authorizations []string `json:"authorizations"`
+	Finalize       string   `json:"finalize"`
+	Certificate    string   `json:"certificate"`
+}
+
+// authorization is an ACME authorization resource
+type authorization struct {
+	Status     string `json:"status"`
+	Identifier struct {
+		Value string `json:"value"`
+	} `json:"identifier"`
+	Challenges []struct {
+		Type   string `json:"type"`
+		URL    string `json:"url"`
+		Token  string `json:"token"`
+		Status string `json:"status"`
+	} `json:"challenges"`
+}
+
+// problem is an ACME error document (RFC 7807)
+type problem struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+}
+
+// obtain orders a certificate for hosts with certKey, calling setToken to
+// publish each http-01 key authorization while its challenge is validated.
+// It returns the DER-encoded chain, leaf first.
+func (c *client) obtain(hosts []string, certKey crypto.Signer, setToken func(token, keyAuth string), clearToken func(token string)) ([][]byte, error) {
+	if err := c.register(); err != nil {
+		return nil, err
+	}
+
+	identifiers := make([]map[string]string, len(hosts))
+	for i, host := range hosts {
+		identifiers[i] = map[string]string{"type": "dns", "value": host}
+	}
+	var o order
+	resp, err := c.post(c.directory.NewOrder, map[string]interface{}{"identifiers": identifiers}, &o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+	orderURL := resp.Header.Get("Location")
+
+	for _, authzURL := range o.Authorizations {
+		if err := c.authorize(authzURL, setToken, clearToken); err != nil {
+			return nil, err
+		}
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: hosts[0]},
+		DNSNames: hosts,
+	}, certKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate request: %w", err)
+	}
+	if _, err := c.post(o.Finalize, map[string]string{"csr": encode(csr)}, &o); err != nil {
+		return nil, fmt.Errorf("failed to finalize order: %w", err)
+	}
+	for attempt := 0; o.Status != "valid"; attempt++ {
+		if o.Status == "invalid" || attempt >= pollAttempts {
+			return nil, fmt.Errorf("order for %s ended as %q", strings.Join(hosts, ", "), o.Status)
+		}
+		time.Sleep(c.pollInterval)
+		if _, err := c.post(orderURL, nil, &o); err != nil {
+			return nil, fmt.Errorf("failed to check order: %w", err)
+		}
+	}
+
+	_, chainPEM, err := c.postRaw(o.Certificate, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download certificate: %w", err)
+	}
+	var chain [][]byte
+	for block, rest := pem.Decode(chainPEM); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type == "CERTIFICATE" {
+			chain = append(chain, block.Bytes)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("certificate response contained no certificates")
+	}
+	return chain, nil
+}
+
+// register fetches the directory and creates or looks up the account
+func (c *client) register() error {
+	if c.kid != "" {
+		return nil
+	}
+
+	resp, err := c.httpClient.Get(c.directoryURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch ACME directory: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&c.directory); err != nil || c.directory.NewOrder == "" {
+		return fmt.Errorf("invalid ACME directory at %s", c.directoryURL)
+	}
+
+	account := map[string]interface{}{"termsOfServiceAgreed": true}
+	if c.email != "" {
+		account["contact"] = []string{"mailto:" + c.email}
+	}
+	resp, err = c.post(c.directory.NewAccount, account, nil)
+	if err != nil {
+		return fmt.Errorf("failed to register ACME account: %w", err)
+	}
+	c.kid = resp.Header.Get("Location")
+	if c.kid == "" {
+		return fmt.Errorf("ACME account response had no Location")
+	}
+	return nil
+}
+
+// authorize completes the http-01 challenge of one authorization
+func (c *client) authorize(authzURL string, setToken func(token, keyAuth string), clearToken func(token string)) error {
+	var authz authorization
+	if _, err := c.post(authzURL, nil, &authz); err != nil {
+		return fmt.Errorf("failed to fetch authorization: %w", err)
+	}
+	if authz.Status == "valid" {
+		return nil
+	}
+
+	for _, challenge := range authz.Challenges {
+		if challenge.Type != "http-01" {
+			continue
+		}
+		setToken(challenge.Token, challenge.Token+"."+c.thumbprint())
+		defer clearToken(challenge.Token)
+
+		if _, err := c.post(challenge.URL, struct{}{}, nil); err != nil {
+			return fmt.Errorf("failed to start challenge for %s: %w", authz.Identifier.Value, err)
+		}
+		for attempt := 0; authz.Status != "valid"; attempt++ {
+			if authz.Status == "invalid" || attempt >= pollAttempts {
+				return fmt.Errorf("authorization for %s ended as %q", authz.Identifier.Value, authz.Status)
+			}
+			time.Sleep(c.pollInterval)
+			if _, err := c.post(authzURL, nil, &authz); err != nil {
+				return fmt.Errorf("failed to check authorization: %w", err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("no http-01 challenge offered for %s", authz.Identifier.Value)
+}
+
+// post sends a JWS-signed request and decodes the JSON response into out.
+// A nil payload sends a POST-as-GET.
+func (c *client) post(url string, payload, out interface{}) (*http.Response, error) {
+	resp, body, err := c.postRaw(url, payload)
+	if err != nil {
+		return nil, err
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return nil, fmt.Errorf("invalid response from %s: %w", url, err)
+		}
+	}
+	return resp, nil
+}
+
+// postRaw sends a JWS-signed request, retrying once on a stale nonce, and
+// returns the response body
+func (c *client) postRaw(url string, payload interface{}) (*http.Response, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		signed, err := c.sign(url, payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp, err := c.httpClient.Post(url, "application/jose+json", bytes.NewReader(signed))
+		if err != nil {
+			return nil, nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		c.nonce = resp.Header.Get("Replay-Nonce")
+
+		if resp.StatusCode < 400 {
+			return resp, body, nil
+		}
+		var p problem
+		json.Unmarshal(body, &p)
+		if p.Type == "urn:ietf:params:acme:error:badNonce" && attempt == 0 {
+			continue
+		}
+		return nil, nil, fmt.Errorf("%s returned status %d: %s %s", url, resp.StatusCode, p.Type, p.Detail)
+	}
+}
+
+// sign wraps payload in a flattened JWS signed with the account key
+func (c *client) sign(url string, payload interface{}) ([]byte, error) {
+	nonce, err := c.takeNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	protected := map[string]interface{}{"alg": "ES256", "nonce": nonce, "url": url}
+	if c.kid != "" {
+		protected["kid"] = c.kid
+	} else {
+		protected["jwk"] = c.jwk()
+	}
+	protectedJSON, _ := json.Marshal(protected)
+
+	payload64 := "" // POST-as-GET
+	if payload != nil {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		payload64 = encode(payloadJSON)
+	}
+
+	signingInput := encode(protectedJSON) + "." + payload64
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, c.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return json.Marshal(map[string]string{
+		"protected": encode(protectedJSON),
+		"payload":   payload64,
+		"signature": encode(signature),
+	})
+}
+
+// takeNonce returns the nonce from the latest response or fetches a new one
+func (c *client) takeNonce() (string, error) {
+	if nonce := c.nonce; nonce != "" {
+		c.nonce = ""
+		return nonce, nil
+	}
+	resp, err := c.httpClient.Head(c.directory.NewNonce)
+	if err != nil {
+		return "", fmt.Errorf("failed to get ACME nonce: %w", err)
+	}
+	resp.Body.Close()
+	nonce := resp.Header.Get("Replay-Nonce")
+	if nonce == "" {
+		return "", fmt.Errorf("ACME server returned no nonce")
+	}
+	return nonce, nil
+}
+
+// jwk returns the account's public key as a JSON Web Key, with its members in
+// the lexicographic order the thumbprint requires
+func (c *client) jwk() json.RawMessage {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	c.key.X.FillBytes(x)
+	c.key.Y.FillBytes(y)
+	return json.RawMessage(fmt.Sprintf(`{"crv":"P-256","kty":"EC","x":"%s","y":"%s"}`, encode(x), encode(y)))
+}
+
+// thumbprint returns the account key's JWK thumbprint (RFC 7638)
+func (c *client) thumbprint() string {
+	sum := sha256.Sum256(c.jwk())
+	return encode(sum[:])
+}
+
+// encode returns unpadded base64url, as JWS requires
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
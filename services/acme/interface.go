@@ -0,0 +1,25 @@
+package acme
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+)
+
+// Manager obtains and renews a certificate for the configured hosts from an
+// ACME certificate authority such as Let's Encrypt, answering http-01
+// challenges on the plain HTTP listener
+type Manager interface {
+	// GetCertificate returns the certificate for a TLS handshake, obtaining
+	// one first if there is no valid one yet and renewing one about to
+	// expire in the background. Use it as tls.Config.GetCertificate.
+	GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+
+	// HTTPHandler answers ACME http-01 challenges and passes every other
+	// request to fallback
+	HTTPHandler(fallback http.Handler) http.Handler
+
+	// Start obtains a certificate if needed and then renews it before it
+	// expires until ctx is cancelled
+	Start(ctx context.Context)
+}
@@ -0,0 +1,316 @@
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Let's Encrypt directories
+const (
+	LetsEncryptURL        = "https://acme-v02.api.letsencrypt.org/directory"
+	LetsEncryptStagingURL = "https://acme-staging-v02.api.letsencrypt.org/directory"
+)
+
+// challengePath is where http-01 challenges are served
+const challengePath = "/.well-known/acme-challenge/"
+
+// Delays before retrying a failed background renewal, doubling from the
+// first up to the last
+const (
+	renewRetryDelay    = time.Minute
+	maxRenewRetryDelay = time.Hour
+)
+
+// Config holds ACME certificate settings
+type Config struct {
+	DirectoryURL  string
+	Email         string   // Contact for expiry notices; optional
+	Hosts         []string // Names on the certificate; they must resolve to this server
+	CacheDir      string   // Holds the account key and certificate across restarts
+	RenewBefore   time.Duration
+	CheckInterval time.Duration // How often the certificate's expiry is checked
+	PollInterval  time.Duration // How often pending authorizations and orders are checked
+}
+
+// DefaultConfig returns a default configuration using Let's Encrypt
+func DefaultConfig() Config {
+	return Config{
+		DirectoryURL:  LetsEncryptURL,
+		CacheDir:      "./data/autocert",
+		RenewBefore:   30 * 24 * time.Hour,
+		CheckInterval: 12 * time.Hour,
+		PollInterval:  2 * time.Second,
+	}
+}
+
+// manager implements the Manager interface
+type manager struct {
+	config      Config
+	client      *client
+	cert        *tls.Certificate
+	certMutex   sync.RWMutex
+	obtainMutex sync.Mutex // One order at a time
+	tokens      map[string]string
+	tokenMutex  sync.RWMutex
+
+	// Background renewal started by GetCertificate
+	renewing      bool
+	renewFailures int
+	renewRetryAt  time.Time
+	renewMutex    sync.Mutex
+}
+
+// New creates a manager, loading the account key and any cached certificate
+// from config.CacheDir
+func New(config Config) (Manager, error) {
+	if len(config.Hosts) == 0 {
+		return nil, fmt.Errorf("at least one host is required")
+	}
+	if err := os.MkdirAll(config.CacheDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create certificate cache: %w", err)
+	}
+	accountKey, err := loadOrCreateKey(filepath.Join(config.CacheDir, "acme_account.key"))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &manager{
+		config: config,
+		client: &client{
+			directoryURL: config.DirectoryURL,
+			email:        config.Email,
+			key:          accountKey,
+			pollInterval: config.PollInterval,
+			httpClient:   &http.Client{Timeout: 30 * time.Second},
+		},
+		tokens: make(map[string]string),
+	}
+
+	if cert, err := tls.LoadX509KeyPair(m.certPath(), m.certPath()); err == nil && m.covers(&cert) {
+		m.cert = &cert
+	}
+	return m, nil
+}
+
+// GetCertificate returns the certificate, obtaining it first if there is no
+// valid one. A certificate about to expire is still served while it is
+// renewed in the background.
+func (m *manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if name := strings.ToLower(strings.TrimSuffix(hello.ServerName, ".")); name != "" && !m.hostAllowed(name) {
+		return nil, fmt.Errorf("acme: host %q not configured", name)
+	}
+	if cert := m.current(); cert != nil && time.Now().Before(cert.Leaf.NotAfter) {
+		if m.expiring(cert) {
+			m.renewInBackground()
+		}
+		return cert, nil
+	}
+	if err := m.renew(); err != nil {
+		return nil, err
+	}
+	return m.current(), nil
+}
+
+// renewInBackground starts a renewal unless one is running or a failed one
+// is waiting to be retried. Failures are retried after renewRetryDelay,
+// doubling up to maxRenewRetryDelay.
+func (m *manager) renewInBackground() {
+	m.renewMutex.Lock()
+	defer m.renewMutex.Unlock()
+	if m.renewing || time.Now().Before(m.renewRetryAt) {
+		return
+	}
+	m.renewing = true
+
+	go func() {
+		err := m.renew()
+
+		m.renewMutex.Lock()
+		defer m.renewMutex.Unlock()
+		m.renewing = false
+		if err == nil {
+			m.renewFailures = 0
+			return
+		}
+		delay := renewRetryDelay << m.renewFailures
+		if delay > maxRenewRetryDelay || delay <= 0 {
+			delay = maxRenewRetryDelay
+		}
+		m.renewFailures++
+		m.renewRetryAt = time.Now().Add(delay)
+		log.Printf("Certificate renewal failed, serving the current one and retrying in %s: %v", delay, err)
+	}()
+}
+
+// HTTPHandler answers http-01 challenges and passes other requests to fallback
+func (m *manager) HTTPHandler(fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, challengePath) {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		m.tokenMutex.RLock()
+		keyAuth, ok := m.tokens[strings.TrimPrefix(r.URL.Path, challengePath)]
+		m.tokenMutex.RUnlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(keyAuth))
+	})
+}
+
+// Start obtains a certificate if needed and then renews it every
+// CheckInterval when it is about to expire
+func (m *manager) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(m.config.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			if cert := m.current(); cert == nil || m.expiring(cert) {
+				if err := m.renew(); err != nil {
+					log.Printf("Failed to obtain certificate for %s: %v", strings.Join(m.config.Hosts, ", "), err)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// renew obtains a new certificate unless another caller just did
+func (m *manager) renew() error {
+	m.obtainMutex.Lock()
+	defer m.obtainMutex.Unlock()
+
+	if cert := m.current(); cert != nil && !m.expiring(cert) {
+		return nil
+	}
+
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	chain, err := m.client.obtain(m.config.Hosts, certKey, m.setToken, m.clearToken)
+	if err != nil {
+		return err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(certKey)
+	if err != nil {
+		return err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	for _, der := range chain {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	cert, err := tls.X509KeyPair(data, data)
+	if err != nil {
+		return fmt.Errorf("invalid certificate from ACME server: %w", err)
+	}
+	if err := os.WriteFile(m.certPath(), data, 0600); err != nil {
+		log.Printf("Failed to cache certificate: %v", err)
+	}
+
+	m.certMutex.Lock()
+	m.cert = &cert
+	m.certMutex.Unlock()
+	log.Printf("Obtained certificate for %s, valid until %s", strings.Join(m.config.Hosts, ", "), cert.Leaf.NotAfter.Format(time.RFC3339))
+	return nil
+}
+
+// current returns the loaded certificate, if any
+func (m *manager) current() *tls.Certificate {
+	m.certMutex.RLock()
+	defer m.certMutex.RUnlock()
+	return m.cert
+}
+
+// expiring reports whether cert should be renewed
+func (m *manager) expiring(cert *tls.Certificate) bool {
+	return time.Until(cert.Leaf.NotAfter) < m.config.RenewBefore
+}
+
+// covers reports whether cert is valid for every configured host
+func (m *manager) covers(cert *tls.Certificate) bool {
+	if cert.Leaf == nil {
+		return false
+	}
+	for _, host := range m.config.Hosts {
+		if cert.Leaf.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// hostAllowed reports whether name is one of the configured hosts
+func (m *manager) hostAllowed(name string) bool {
+	for _, host := range m.config.Hosts {
+		if strings.EqualFold(host, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *manager) setToken(token, keyAuth string) {
+	m.tokenMutex.Lock()
+	m.tokens[token] = keyAuth
+	m.tokenMutex.Unlock()
+}
+
+func (m *manager) clearToken(token string) {
+	m.tokenMutex.Lock()
+	delete(m.tokens, token)
+	m.tokenMutex.Unlock()
+}
+
+// certPath is where the certificate and its key are cached
+func (m *manager) certPath() string {
+	return filepath.Join(m.config.CacheDir, strings.Join(m.config.Hosts, "+")+".pem")
+}
+
+// loadOrCreateKey reads a PEM EC key from path, creating one if it doesn't exist
+func loadOrCreateKey(path string) (*ecdsa.PrivateKey, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("invalid ACME account key in %s", path)
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return nil, fmt.Errorf("failed to save ACME account key: %w", err)
+	}
+	return key, nil
+}
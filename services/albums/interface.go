@@ -0,0 +1,39 @@
+package albums
+
+import (
+	"backend/server/models"
+	"backend/services/mood"
+	"context"
+	"errors"
+)
+
+// ErrNoLyrics is returned when none of an album's tracks have lyrics
+var ErrNoLyrics = errors.New("no lyrics found for any track of the album")
+
+// Spotify fetches albums with their track lists
+type Spotify interface {
+	GetAlbum(albumID string) (*models.SpotifyAlbum, error)
+}
+
+// Lyrics fetches a track's lyrics with their mood analysis
+type Lyrics interface {
+	GetLyricsWithMood(trackName, artistName string) (*mood.LyricsWithMood, error)
+}
+
+// AIService writes the narrative
+type AIService interface {
+	GenerateJSON(prompt string) (string, error)
+}
+
+// Service analyzes albums
+type Service interface {
+	// Cached returns an album's analysis if it has one that hasn't expired
+	Cached(albumID string) (*models.AlbumAnalysis, bool)
+
+	// Analyze fetches the album's track list and the lyrics of every track,
+	// asks the AI for the album's narrative and caches the analysis. The
+	// track analyses are cached by the mood service, so a retry after a
+	// failure only repeats the missing ones. Once ctx is done no new tracks
+	// are analyzed.
+	Analyze(ctx context.Context, albumID string) (*models.AlbumAnalysis, error)
+}
@@ -0,0 +1,247 @@
+package albums
+
+import (
+	"backend/server/models"
+	"backend/services/prompts"
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTrackLyrics is how much of each track's lyrics goes in the prompt, in runes
+const maxTrackLyrics = 600
+
+// Config holds album analysis configuration
+type Config struct {
+	CacheTTL    time.Duration // How long an album's analysis is served before being redone
+	MaxEntries  int           // Albums with a cached analysis; least recently used ones are evicted beyond this
+	Concurrency int           // Tracks whose lyrics are fetched and analyzed at the same time
+}
+
+// DefaultConfig returns a default configuration for album analyses
+func DefaultConfig() Config {
+	return Config{
+		CacheTTL:    30 * 24 * time.Hour,
+		MaxEntries:  200,
+		Concurrency: 3,
+	}
+}
+
+// entry is an album's cached analysis
+type entry struct {
+	albumID   string
+	analysis  models.AlbumAnalysis
+	expiresAt time.Time
+}
+
+// service implements the albums Service interface
+type service struct {
+	spotify   Spotify
+	lyrics    Lyrics
+	ai        AIService
+	templates prompts.Service
+	config    Config
+	entries   map[string]*list.Element
+	order     *list.List // Front is most recently used
+	mutex     sync.Mutex
+}
+
+// New creates a new album analysis service. A nil templates uses the built-in prompts.
+func New(spotify Spotify, lyrics Lyrics, ai AIService, templates prompts.Service, config Config) Service {
+	if templates == nil {
+		templates = prompts.Default()
+	}
+	if config.Concurrency < 1 {
+		config.Concurrency = 1
+	}
+	return &service{
+		spotify:   spotify,
+		lyrics:    lyrics,
+		ai:        ai,
+		templates: templates,
+		config:    config,
+		entries:   make(map[string]*list.Element),
+		order:     list.New(),
+	}
+}
+
+// Cached returns an album's non-expired analysis and marks it recently used
+func (s *service) Cached(albumID string) (*models.AlbumAnalysis, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	element, ok := s.entries[albumID]
+	if !ok {
+		return nil, false
+	}
+	cached := element.Value.(*entry)
+	if time.Now().After(cached.expiresAt) {
+		s.order.Remove(element)
+		delete(s.entries, albumID)
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	analysis := cached.analysis
+	return &analysis, true
+}
+
+// Analyze analyzes an album and caches the analysis
+func (s *service) Analyze(ctx context.Context, albumID string) (*models.AlbumAnalysis, error) {
+	if analysis, ok := s.Cached(albumID); ok {
+		return analysis, nil
+	}
+
+	album, err := s.spotify.GetAlbum(albumID)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks, excerpts := s.analyzeTracks(ctx, album)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	promptTracks := make([]prompts.AlbumTrack, len(tracks))
+	withLyrics := 0
+	for i, track := range tracks {
+		promptTracks[i] = prompts.AlbumTrack{
+			Number: track.Number,
+			Name:   track.Name,
+			Themes: strings.Join(track.Themes, ", "),
+			Lyrics: excerpts[i],
+		}
+		if track.Mood != nil {
+			promptTracks[i].Mood = track.Mood.PrimaryMood
+		}
+		if excerpts[i] != "" {
+			withLyrics++
+		}
+	}
+	if withLyrics == 0 {
+		return nil, fmt.Errorf("%w: %s by %s", ErrNoLyrics, album.Name, album.Artist)
+	}
+
+	prompt, err := s.templates.Render(prompts.AlbumAnalysis, prompts.AlbumAnalysisData{
+		AlbumInfo:   fmt.Sprintf("%s by %s", album.Name, album.Artist),
+		ReleaseDate: album.ReleaseDate,
+		Tracks:      promptTracks,
+	})
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.ai.GenerateJSON(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write album narrative: %w", err)
+	}
+	var generated struct {
+		Narrative       string   `json:"narrative"`
+		RecurringThemes []string `json:"recurring_themes"`
+	}
+	if err := json.Unmarshal([]byte(response), &generated); err != nil {
+		return nil, fmt.Errorf("failed to parse album narrative: %w", err)
+	}
+
+	analysis := models.AlbumAnalysis{
+		AlbumID:         album.ID,
+		Name:            album.Name,
+		Artist:          album.Artist,
+		ReleaseDate:     album.ReleaseDate,
+		Narrative:       strings.TrimSpace(generated.Narrative),
+		RecurringThemes: make([]string, 0, len(generated.RecurringThemes)),
+		Tracks:          tracks,
+		AnalyzedAt:      time.Now(),
+	}
+	if analysis.AlbumID == "" {
+		analysis.AlbumID = albumID
+	}
+	if len(album.Images) > 0 {
+		analysis.ImageURL = album.Images[0].URL
+	}
+	for _, theme := range generated.RecurringThemes {
+		if theme = strings.TrimSpace(theme); theme != "" {
+			analysis.RecurringThemes = append(analysis.RecurringThemes, theme)
+		}
+	}
+
+	s.put(albumID, analysis)
+	return &analysis, nil
+}
+
+// analyzeTracks fetches the lyrics and mood of every track of the album, a
+// few at a time, returning the analyses and the lyrics excerpts for the prompt
+func (s *service) analyzeTracks(ctx context.Context, album *models.SpotifyAlbum) ([]models.AlbumTrackAnalysis, []string) {
+	tracks := make([]models.AlbumTrackAnalysis, len(album.Tracks))
+	excerpts := make([]string, len(album.Tracks))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, s.config.Concurrency)
+
+	for i, track := range album.Tracks {
+		tracks[i] = models.AlbumTrackAnalysis{
+			Number:   i + 1,
+			ID:       track.ID,
+			Name:     track.Name,
+			Explicit: track.Explicit,
+		}
+		artist := track.Artist
+		if artist == "" {
+			artist = album.Artist
+		}
+
+		wg.Add(1)
+		go func(i int, name, artist string) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				tracks[i].Error = "analysis cancelled"
+				return
+			}
+			defer func() { <-semaphore }()
+
+			analysis, err := s.lyrics.GetLyricsWithMood(name, artist)
+			if err != nil {
+				tracks[i].Error = err.Error()
+				return
+			}
+			tracks[i].Mood = analysis.MoodAnalysis
+			tracks[i].Themes = analysis.Themes
+			excerpts[i] = excerpt(analysis.Lyrics)
+		}(i, track.Name, artist)
+	}
+
+	wg.Wait()
+	return tracks, excerpts
+}
+
+// excerpt shortens lyrics for the prompt
+func excerpt(lyrics string) string {
+	lyrics = strings.TrimSpace(lyrics)
+	if runes := []rune(lyrics); len(runes) > maxTrackLyrics {
+		return string(runes[:maxTrackLyrics]) + "..."
+	}
+	return lyrics
+}
+
+// put caches an album's analysis, evicting the least recently used albums when full
+func (s *service) put(albumID string, analysis models.AlbumAnalysis) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.config.MaxEntries <= 0 {
+		return
+	}
+	if element, ok := s.entries[albumID]; ok {
+		s.order.Remove(element)
+	}
+	s.entries[albumID] = s.order.PushFront(&entry{albumID: albumID, analysis: analysis, expiresAt: time.Now().Add(s.config.CacheTTL)})
+	for s.order.Len() > s.config.MaxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*entry).albumID)
+	}
+}
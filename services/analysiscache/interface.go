@@ -0,0 +1,78 @@
+package analysiscache
+
+import (
+	"backend/server/models"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when rating an analysis that isn't stored
+var ErrNotFound = errors.New("analysis not found")
+
+// Key identifies the question a stored lyrics analysis answers
+type Key struct {
+	TrackID  string // The source's track ID, or the song's name and artist for tracks without one
+	Category string // Normalized question category, from Category
+	Language string // Answer language
+	Variant  string // "", "clean" or "restricted"; masked answers are stored apart
+}
+
+// Store persists lyrics analyses and their ratings
+type Store interface {
+	// List returns the analyses stored since since for the track and variant of
+	// key, best rated first. An empty category or language matches any.
+	List(key Key, since time.Time) ([]models.StoredAnalysis, error)
+
+	// Get returns a stored analysis, or ErrNotFound
+	Get(id int64) (models.StoredAnalysis, error)
+
+	// Put stores an analysis, returning it with its ID
+	Put(analysis models.StoredAnalysis) (models.StoredAnalysis, error)
+
+	// Rate records a user's rating of an analysis, replacing any earlier one by
+	// the user. It returns ErrNotFound if the analysis isn't stored.
+	Rate(id int64, userID string, helpful bool) error
+
+	// DeleteBefore removes analyses stored before cutoff, and their ratings,
+	// returning how many analyses were removed
+	DeleteBefore(cutoff time.Time) (int, error)
+}
+
+// AIService defines the AI operations needed to answer a question again
+type AIService interface {
+	GenerateResponse(prompt string) (string, error)
+}
+
+// Service serves answers to common questions about a song, such as "what
+// does this song mean", from stored analyses instead of asking the AI again.
+// Users rate the answers; the best rated one is served, and once every
+// answer to a question has been rated down, a new one is written.
+type Service interface {
+	// Category returns the normalized category of a question, or "" if the
+	// question is too specific for its answer to be shared
+	Category(query string) string
+
+	// Get returns the best rated answer stored under key that hasn't expired.
+	// If there is none, it returns false along with any answers that were
+	// rated down, which a new answer should avoid repeating. Rated down
+	// answers are still returned until the question may be answered again.
+	Get(key Key) (analysis models.StoredAnalysis, rejected []string, ok bool)
+
+	// Put stores an answer under key, returning it with its ID; the ID is 0 if
+	// it couldn't be stored
+	Put(key Key, answer string) models.StoredAnalysis
+
+	// Reanalyze answers a question again with the lyrics_reanalysis prompt,
+	// which asks for a different answer than the rejected ones
+	Reanalyze(ai AIService, query, lyrics, songInfo string, rejected []string) (string, error)
+
+	// Rate records a user's rating of an analysis and returns it with its
+	// updated ratings, or ErrNotFound
+	Rate(id int64, userID string, helpful bool) (models.StoredAnalysis, error)
+
+	// ForTrack returns the track's unexpired answers of a variant, best rated first
+	ForTrack(trackID, variant string) ([]models.StoredAnalysis, error)
+
+	// Purge removes expired answers, returning how many
+	Purge() (int, error)
+}
@@ -0,0 +1,122 @@
+package analysiscache
+
+import (
+	"backend/server/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryStore keeps analyses in memory, for development without a database and tests
+type memoryStore struct {
+	analyses map[int64]models.StoredAnalysis
+	ratings  map[int64]map[string]bool // Helpful or not, by analysis and user
+	nextID   int64
+	mutex    sync.RWMutex
+}
+
+// NewMemoryStore creates an in-memory Store
+func NewMemoryStore() Store {
+	return &memoryStore{
+		analyses: make(map[int64]models.StoredAnalysis),
+		ratings:  make(map[int64]map[string]bool),
+	}
+}
+
+// List returns the analyses stored since since for the track and variant of
+// key, best rated first
+func (m *memoryStore) List(key Key, since time.Time) ([]models.StoredAnalysis, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	analyses := []models.StoredAnalysis{}
+	for id, analysis := range m.analyses {
+		if analysis.TrackID != key.TrackID || analysis.Variant != key.Variant || analysis.CreatedAt.Before(since) {
+			continue
+		}
+		if (key.Category != "" && analysis.Category != key.Category) || (key.Language != "" && analysis.Language != key.Language) {
+			continue
+		}
+		analyses = append(analyses, m.rated(id))
+	}
+
+	sort.Slice(analyses, func(i, j int) bool {
+		a, b := analyses[i], analyses[j]
+		if scoreA, scoreB := a.Helpful-a.Unhelpful, b.Helpful-b.Unhelpful; scoreA != scoreB {
+			return scoreA > scoreB
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+	return analyses, nil
+}
+
+// Get returns a stored analysis, or ErrNotFound
+func (m *memoryStore) Get(id int64) (models.StoredAnalysis, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if _, ok := m.analyses[id]; !ok {
+		return models.StoredAnalysis{}, ErrNotFound
+	}
+	return m.rated(id), nil
+}
+
+// Put stores an analysis, returning it with its ID
+func (m *memoryStore) Put(analysis models.StoredAnalysis) (models.StoredAnalysis, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.nextID++
+	analysis.ID = m.nextID
+	analysis.Helpful = 0
+	analysis.Unhelpful = 0
+	m.analyses[analysis.ID] = analysis
+	return analysis, nil
+}
+
+// Rate records a user's rating of an analysis, replacing any earlier one by the user
+func (m *memoryStore) Rate(id int64, userID string, helpful bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.analyses[id]; !ok {
+		return ErrNotFound
+	}
+	if m.ratings[id] == nil {
+		m.ratings[id] = make(map[string]bool)
+	}
+	m.ratings[id][userID] = helpful
+	return nil
+}
+
+// DeleteBefore removes analyses stored before cutoff, and their ratings
+func (m *memoryStore) DeleteBefore(cutoff time.Time) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	deleted := 0
+	for id, analysis := range m.analyses {
+		if analysis.CreatedAt.Before(cutoff) {
+			delete(m.analyses, id)
+			delete(m.ratings, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// rated returns an analysis with its rating counts; the caller holds the mutex
+func (m *memoryStore) rated(id int64) models.StoredAnalysis {
+	analysis := m.analyses[id]
+	for _, helpful := range m.ratings[id] {
+		if helpful {
+			analysis.Helpful++
+		} else {
+			analysis.Unhelpful++
+		}
+	}
+	return analysis
+}
@@ -0,0 +1,165 @@
+package analysiscache
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Schema creates the lyrics_analyses and lyrics_analysis_ratings tables
+const Schema = `
+        CREATE TABLE IF NOT EXISTS lyrics_analyses (
+            id BIGSERIAL PRIMARY KEY,
+            track_id VARCHAR(255) NOT NULL,
+            category VARCHAR(32) NOT NULL,
+            language VARCHAR(16) NOT NULL,
+            variant VARCHAR(16) NOT NULL,
+            answer TEXT NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_lyrics_analyses_track ON lyrics_analyses(track_id, variant, category, language);
+        CREATE INDEX IF NOT EXISTS idx_lyrics_analyses_created_at ON lyrics_analyses(created_at);
+
+        CREATE TABLE IF NOT EXISTS lyrics_analysis_ratings (
+            analysis_id BIGINT NOT NULL REFERENCES lyrics_analyses(id) ON DELETE CASCADE,
+            user_id VARCHAR(255) NOT NULL,
+            helpful BOOLEAN NOT NULL,
+            rated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+            PRIMARY KEY (analysis_id, user_id)
+        );
+    `
+
+// selectAnalyses selects analyses with their rating counts; callers add the
+// WHERE clause before groupAnalyses
+const selectAnalyses = `
+        SELECT a.id, a.track_id, a.category, a.language, a.variant, a.answer, a.created_at,
+            COUNT(r.user_id) FILTER (WHERE r.helpful),
+            COUNT(r.user_id) FILTER (WHERE NOT r.helpful)
+        FROM lyrics_analyses a
+        LEFT JOIN lyrics_analysis_ratings r ON r.analysis_id = a.id
+    `
+
+// groupAnalyses ends a selectAnalyses query
+const groupAnalyses = `
+        GROUP BY a.id
+    `
+
+// postgresStore keeps analyses in the lyrics_analyses table, so they are
+// shared by every instance and survive restarts
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a Store backed by the tables in Schema
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+// List returns the analyses stored since since for the track and variant of
+// key, best rated first
+func (p *postgresStore) List(key Key, since time.Time) ([]models.StoredAnalysis, error) {
+	rows, err := p.db.Query(selectAnalyses+`
+        WHERE a.track_id = $1 AND a.variant = $2 AND a.created_at >= $3
+            AND ($4::text = '' OR a.category = $4::text) AND ($5::text = '' OR a.language = $5::text)
+    `+groupAnalyses+`
+        ORDER BY COUNT(r.user_id) FILTER (WHERE r.helpful) - COUNT(r.user_id) FILTER (WHERE NOT r.helpful) DESC,
+            a.created_at DESC, a.id DESC
+    `, key.TrackID, key.Variant, since, key.Category, key.Language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lyrics analyses: %w", err)
+	}
+	defer rows.Close()
+
+	analyses := []models.StoredAnalysis{}
+	for rows.Next() {
+		analysis, err := scanAnalysis(rows)
+		if err != nil {
+			return nil, err
+		}
+		analyses = append(analyses, analysis)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read lyrics analyses: %w", err)
+	}
+	return analyses, nil
+}
+
+// Get returns a stored analysis, or ErrNotFound
+func (p *postgresStore) Get(id int64) (models.StoredAnalysis, error) {
+	analysis, err := scanAnalysis(p.db.QueryRow(selectAnalyses+`
+        WHERE a.id = $1
+    `+groupAnalyses, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.StoredAnalysis{}, ErrNotFound
+	}
+	return analysis, err
+}
+
+// Put stores an analysis, returning it with its ID
+func (p *postgresStore) Put(analysis models.StoredAnalysis) (models.StoredAnalysis, error) {
+	err := p.db.QueryRow(`
+        INSERT INTO lyrics_analyses (track_id, category, language, variant, answer, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id
+    `, analysis.TrackID, analysis.Category, analysis.Language, analysis.Variant, analysis.Answer, analysis.CreatedAt).Scan(&analysis.ID)
+	if err != nil {
+		return models.StoredAnalysis{}, fmt.Errorf("failed to save lyrics analysis: %w", err)
+	}
+	analysis.Helpful = 0
+	analysis.Unhelpful = 0
+	return analysis, nil
+}
+
+// Rate records a user's rating of an analysis, replacing any earlier one by the user
+func (p *postgresStore) Rate(id int64, userID string, helpful bool) error {
+	result, err := p.db.Exec(`
+        INSERT INTO lyrics_analysis_ratings (analysis_id, user_id, helpful, rated_at)
+        SELECT id, $2, $3, NOW() FROM lyrics_analyses WHERE id = $1
+        ON CONFLICT (analysis_id, user_id) DO UPDATE SET
+            helpful = EXCLUDED.helpful,
+            rated_at = EXCLUDED.rated_at
+    `, id, userID, helpful)
+	if err != nil {
+		return fmt.Errorf("failed to save lyrics analysis rating: %w", err)
+	}
+	if rated, err := result.RowsAffected(); err == nil && rated == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteBefore removes analyses stored before cutoff; their ratings are
+// removed with them
+func (p *postgresStore) DeleteBefore(cutoff time.Time) (int, error) {
+	result, err := p.db.Exec(`DELETE FROM lyrics_analyses WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete lyrics analyses: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted lyrics analyses: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAnalysis scans a row of a selectAnalyses query
+func scanAnalysis(row rowScanner) (models.StoredAnalysis, error) {
+	var analysis models.StoredAnalysis
+	err := row.Scan(&analysis.ID, &analysis.TrackID, &analysis.Category, &analysis.Language, &analysis.Variant,
+		&analysis.Answer, &analysis.CreatedAt, &analysis.Helpful, &analysis.Unhelpful)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.StoredAnalysis{}, err
+	}
+	if err != nil {
+		return models.StoredAnalysis{}, fmt.Errorf("failed to scan lyrics analysis: %w", err)
+	}
+	return analysis, nil
+}
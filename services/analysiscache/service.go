@@ -0,0 +1,203 @@
+package analysiscache
+
+import (
+	"backend/server/models"
+	"backend/services/prompts"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxRejected caps the rated down answers sent with a reanalysis prompt
+const maxRejected = 3
+
+// Config holds lyrics analysis cache configuration
+type Config struct {
+	TTL time.Duration // How long a stored answer is served
+
+	// An answer is rated down once it has at least RejectAfter unhelpful
+	// ratings and more unhelpful than helpful ones
+	RejectAfter int
+
+	// A question is answered again at most once per ReanalyzeInterval; until
+	// then the best rated answer is served even if it was rated down. 0 lets
+	// every rated down answer be replaced at once.
+	ReanalyzeInterval time.Duration
+}
+
+// DefaultConfig returns a default configuration for the lyrics analysis cache
+func DefaultConfig() Config {
+	return Config{
+		TTL:               30 * 24 * time.Hour,
+		RejectAfter:       3,
+		ReanalyzeInterval: time.Hour,
+	}
+}
+
+// categories maps the words that make up a common question to its category.
+// A question is only categorized when all its words, besides fillers, belong
+// to one category, so "what does this song mean" is but "what does the
+// second verse mean" isn't.
+var categories = map[string][]string{
+	"meaning":     {"mean", "means", "meaning", "about", "message", "point"},
+	"themes":      {"theme", "themes", "topic", "topics", "subject"},
+	"mood":        {"mood", "feel", "feeling", "feelings", "emotion", "emotions", "vibe", "tone"},
+	"story":       {"story", "happens", "happening", "narrative", "plot"},
+	"inspiration": {"inspiration", "inspired", "written", "write", "wrote", "why"},
+	"symbolism":   {"symbolism", "symbol", "symbols", "metaphor", "metaphors", "imagery"},
+}
+
+// fillers are words that don't change what a common question asks
+var fillers = map[string]bool{
+	"what": true, "whats": true, "how": true, "does": true, "do": true, "did": true,
+	"is": true, "are": true, "was": true, "the": true, "this": true, "that": true,
+	"it": true, "its": true, "song": true, "songs": true, "track": true, "tracks": true,
+	"lyrics": true, "lyric": true, "tell": true, "me": true, "explain": true,
+	"can": true, "you": true, "please": true, "of": true, "a": true, "an": true,
+	"in": true, "behind": true, "main": true, "overall": true, "really": true,
+}
+
+// service implements the analysiscache Service interface
+type service struct {
+	store     Store
+	templates prompts.Service
+	config    Config
+	category  map[string]string // Category of each question word
+}
+
+// New creates a lyrics analysis cache backed by store. A nil templates uses
+// the built-in prompts.
+func New(store Store, templates prompts.Service, config Config) Service {
+	if templates == nil {
+		templates = prompts.Default()
+	}
+	if config.RejectAfter < 1 {
+		config.RejectAfter = 1
+	}
+	category := make(map[string]string)
+	for name, words := range categories {
+		for _, word := range words {
+			category[word] = name
+		}
+	}
+	return &service{
+		store:     store,
+		templates: templates,
+		config:    config,
+		category:  category,
+	}
+}
+
+// Category returns the normalized category of a question, or "" if the
+// question is too specific for its answer to be shared
+func (s *service) Category(query string) string {
+	query = strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(query))
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	found := ""
+	for _, word := range words {
+		if fillers[word] {
+			continue
+		}
+		category, ok := s.category[word]
+		if !ok || (found != "" && category != found) {
+			return ""
+		}
+		found = category
+	}
+	return found
+}
+
+// Get returns the best rated answer stored under key that hasn't expired, or
+// the answers that were rated down. While the newest answer is younger than
+// ReanalyzeInterval, the best rated one is returned even if it was rated
+// down, so ratings can't have the AI write answers over and over. Store
+// failures are logged and treated as misses, so the AI is asked instead.
+func (s *service) Get(key Key) (models.StoredAnalysis, []string, bool) {
+	analyses, err := s.store.List(key, s.cutoff())
+	if err != nil {
+		log.Printf("Analysis cache: failed to load %s answers for %s: %v", key.Category, key.TrackID, err)
+		return models.StoredAnalysis{}, nil, false
+	}
+
+	var rejected []string
+	var newest time.Time
+	for _, analysis := range analyses {
+		if !s.rejected(analysis) {
+			return analysis, nil, true
+		}
+		if len(rejected) < maxRejected {
+			rejected = append(rejected, analysis.Answer)
+		}
+		if analysis.CreatedAt.After(newest) {
+			newest = analysis.CreatedAt
+		}
+	}
+	if len(analyses) > 0 && time.Since(newest) < s.config.ReanalyzeInterval {
+		return analyses[0], nil, true
+	}
+	return models.StoredAnalysis{}, rejected, false
+}
+
+// Put stores an answer under key, logging failures
+func (s *service) Put(key Key, answer string) models.StoredAnalysis {
+	analysis, err := s.store.Put(models.StoredAnalysis{
+		TrackID:   key.TrackID,
+		Category:  key.Category,
+		Language:  key.Language,
+		Variant:   key.Variant,
+		Answer:    answer,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Analysis cache: failed to save %s answer for %s: %v", key.Category, key.TrackID, err)
+		return models.StoredAnalysis{}
+	}
+	return analysis
+}
+
+// Reanalyze answers a question again with the lyrics_reanalysis prompt
+func (s *service) Reanalyze(ai AIService, query, lyrics, songInfo string, rejected []string) (string, error) {
+	prompt, err := s.templates.Render(prompts.LyricsReanalysis, prompts.LyricsReanalysisData{
+		SongInfo: songInfo,
+		Query:    query,
+		Lyrics:   lyrics,
+		Rejected: rejected,
+	})
+	if err != nil {
+		return "", err
+	}
+	return ai.GenerateResponse(prompt)
+}
+
+// Rate records a user's rating of an analysis and returns it with its
+// updated ratings
+func (s *service) Rate(id int64, userID string, helpful bool) (models.StoredAnalysis, error) {
+	if err := s.store.Rate(id, userID, helpful); err != nil {
+		return models.StoredAnalysis{}, err
+	}
+	return s.store.Get(id)
+}
+
+// ForTrack returns the track's unexpired answers of a variant, best rated first
+func (s *service) ForTrack(trackID, variant string) ([]models.StoredAnalysis, error) {
+	return s.store.List(Key{TrackID: trackID, Variant: variant}, s.cutoff())
+}
+
+// Purge removes expired answers, returning how many
+func (s *service) Purge() (int, error) {
+	return s.store.DeleteBefore(s.cutoff())
+}
+
+// cutoff returns the time answers stored before have expired
+func (s *service) cutoff() time.Time {
+	return time.Now().Add(-s.config.TTL)
+}
+
+// rejected reports whether an answer was rated down
+func (s *service) rejected(analysis models.StoredAnalysis) bool {
+	return analysis.Unhelpful >= s.config.RejectAfter && analysis.Unhelpful > analysis.Helpful
+}
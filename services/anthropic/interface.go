@@ -0,0 +1,16 @@
+package anthropic
+
+// Service defines the interface for Anthropic Claude operations
+type Service interface {
+	// AnalyzeLyrics analyzes lyrics based on a user query
+	AnalyzeLyrics(query, lyrics, songInfo string) (string, error)
+
+	// GenerateResponse generates a general response without lyrics context
+	GenerateResponse(prompt string) (string, error)
+
+	// GenerateJSON generates a response constrained to a single JSON object
+	GenerateJSON(prompt string) (string, error)
+
+	// IsAvailable checks if the Anthropic service is available
+	IsAvailable() error
+}
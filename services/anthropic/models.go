@@ -0,0 +1,48 @@
+package anthropic
+
+// MessagesRequest represents an Anthropic Messages API request
+type MessagesRequest struct {
+	Model     string    `json:"model"`
+	Messages  []Message `json:"messages"`
+	MaxTokens int       `json:"max_tokens"`
+	// Optional parameters
+	System      string   `json:"system,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+}
+
+// Message represents a conversation turn
+type Message struct {
+	Role    string `json:"role"` // "user" or "assistant"
+	Content string `json:"content"`
+}
+
+// MessagesResponse represents an Anthropic Messages API response
+type MessagesResponse struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	Role       string         `json:"role"`
+	Model      string         `json:"model"`
+	Content    []ContentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      Usage          `json:"usage"`
+	Error      *APIError      `json:"error,omitempty"`
+}
+
+// ContentBlock represents a single block of response content
+type ContentBlock struct {
+	Type string `json:"type"` // "text"
+	Text string `json:"text"`
+}
+
+// Usage represents token usage information
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// APIError represents an Anthropic API error
+type APIError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,197 @@
+package anthropic
+
+import (
+	"backend/services/prompts"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// apiVersion is the Anthropic API version sent with every request
+const apiVersion = "2023-06-01"
+
+// Config holds Anthropic service configuration
+type Config struct {
+	APIKey      string
+	Model       string
+	BaseURL     string
+	Temperature float64
+	MaxTokens   int
+	TopP        float64 // Sent only if set, since Anthropic advises tuning it or Temperature, not both
+
+	// Prompts renders the lyrics analysis prompt; nil uses the built-in templates
+	Prompts prompts.Service
+}
+
+// DefaultConfig returns a default configuration for Anthropic
+func DefaultConfig() Config {
+	return Config{
+		Model:       "claude-3-5-haiku-latest",
+		BaseURL:     "https://api.anthropic.com/v1",
+		Temperature: 0.7,
+		MaxTokens:   500,
+	}
+}
+
+// service implements the Anthropic Service interface
+type service struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// New creates a new Anthropic service
+func New(config Config) Service {
+	if config.Prompts == nil {
+		config.Prompts = prompts.Default()
+	}
+	return &service{
+		config: config,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// IsAvailable checks if the Anthropic service is available
+func (s *service) IsAvailable() error {
+	if s.config.APIKey == "" {
+		return fmt.Errorf("Anthropic API key not provided")
+	}
+
+	// Test with a simple request
+	req := MessagesRequest{
+		Model: s.config.Model,
+		Messages: []Message{
+			{Role: "user", Content: "Test"},
+		},
+		MaxTokens: 1,
+	}
+
+	_, err := s.makeRequest(req)
+	if err != nil {
+		return fmt.Errorf("Anthropic service not available: %w", err)
+	}
+
+	return nil
+}
+
+// AnalyzeLyrics analyzes lyrics based on a user query
+func (s *service) AnalyzeLyrics(query, lyrics, songInfo string) (string, error) {
+	prompt, err := s.buildLyricsPrompt(query, lyrics, songInfo)
+	if err != nil {
+		return "", err
+	}
+	return s.generate(prompt, "")
+}
+
+// GenerateResponse generates a general response without lyrics context
+func (s *service) GenerateResponse(prompt string) (string, error) {
+	return s.generate(prompt, "")
+}
+
+// GenerateJSON generates a JSON response by prefilling the assistant turn
+// with an opening brace, since the Messages API has no dedicated JSON mode
+func (s *service) GenerateJSON(prompt string) (string, error) {
+	return s.generate(prompt, "{")
+}
+
+// buildLyricsPrompt creates a prompt for lyrics analysis
+func (s *service) buildLyricsPrompt(query, lyrics, songInfo string) (string, error) {
+	return s.config.Prompts.Render(prompts.LyricsAnalysis, prompts.LyricsAnalysisData{
+		SongInfo: songInfo,
+		Query:    query,
+		Lyrics:   lyrics,
+	})
+}
+
+// generate sends a request to Anthropic and returns the response.
+// A non-empty prefill is sent as the start of the assistant turn and
+// prepended to the returned text.
+func (s *service) generate(prompt, prefill string) (string, error) {
+	messages := []Message{
+		{Role: "user", Content: prompt},
+	}
+	if prefill != "" {
+		messages = append(messages, Message{Role: "assistant", Content: prefill})
+	}
+
+	req := MessagesRequest{
+		Model:       s.config.Model,
+		Messages:    messages,
+		MaxTokens:   s.config.MaxTokens,
+		Temperature: &s.config.Temperature,
+	}
+	if s.config.TopP > 0 {
+		req.TopP = &s.config.TopP
+	}
+
+	resp, err := s.makeRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no text content returned from Anthropic")
+	}
+
+	return prefill + text.String(), nil
+}
+
+// makeRequest sends a request to the Anthropic Messages API
+func (s *service) makeRequest(req MessagesRequest) (*MessagesResponse, error) {
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", s.config.BaseURL+"/messages", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", s.config.APIKey)
+	httpReq.Header.Set("anthropic-version", apiVersion)
+
+	// Send request
+	httpResp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Anthropic: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Parse response
+	var anthropicResp MessagesResponse
+	if err := json.Unmarshal(body, &anthropicResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Check for API errors
+	if anthropicResp.Error != nil {
+		return nil, fmt.Errorf("Anthropic API error: %s", anthropicResp.Error.Message)
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Anthropic API failed with status %d: %s", httpResp.StatusCode, string(body))
+	}
+
+	return &anthropicResp, nil
+}
@@ -0,0 +1,43 @@
+package avatars
+
+import "errors"
+
+var (
+	// ErrNotFound is returned for avatars that aren't stored
+	ErrNotFound = errors.New("avatar not found")
+
+	// ErrTooLarge is returned for images over the configured size
+	ErrTooLarge = errors.New("avatar exceeds the maximum size")
+
+	// ErrUnsupportedType is returned for anything but PNG, JPEG, GIF and WebP images
+	ErrUnsupportedType = errors.New("avatar must be a PNG, JPEG, GIF or WebP image")
+)
+
+// Storage keeps avatar images by key
+type Storage interface {
+	// Put stores an image under key
+	Put(key, contentType string, data []byte) error
+
+	// Get returns the image stored under key, or ErrNotFound
+	Get(key string) (data []byte, contentType string, err error)
+
+	// Delete removes the image stored under key; missing images aren't an error
+	Delete(key string) error
+}
+
+// Service validates and stores users' avatars
+type Service interface {
+	// Save checks that data is a supported image within the size limit and
+	// stores it for userID, returning its key. Keys change with the image,
+	// so what is served under one never changes.
+	Save(userID string, data []byte) (key string, err error)
+
+	// Open returns an avatar's image and content type, or ErrNotFound
+	Open(key string) (data []byte, contentType string, err error)
+
+	// Delete removes an avatar
+	Delete(key string) error
+
+	// MaxBytes is the largest image accepted
+	MaxBytes() int64
+}
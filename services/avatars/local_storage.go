@@ -0,0 +1,65 @@
+package avatars
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// localStorage keeps avatars as files in a directory
+type localStorage struct {
+	dir string
+}
+
+// NewLocalStorage creates a Storage of files in dir, creating it if needed
+func NewLocalStorage(dir string) (Storage, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create avatar directory: %w", err)
+	}
+	return &localStorage{dir: dir}, nil
+}
+
+// Put writes an image to a temporary file and renames it into place, so
+// readers never see part of one
+func (l *localStorage) Put(key, contentType string, data []byte) error {
+	tmp, err := os.CreateTemp(l.dir, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to store avatar: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store avatar: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to store avatar: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(l.dir, key)); err != nil {
+		return fmt.Errorf("failed to store avatar: %w", err)
+	}
+	return nil
+}
+
+// Get reads an image, taking its content type from the key
+func (l *localStorage) Get(key string) ([]byte, string, error) {
+	data, err := os.ReadFile(filepath.Join(l.dir, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read avatar: %w", err)
+	}
+	return data, contentTypeOf(key), nil
+}
+
+// Delete removes an image's file
+func (l *localStorage) Delete(key string) error {
+	err := os.Remove(filepath.Join(l.dir, key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete avatar: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package avatars
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config locates an S3 bucket, on AWS or any S3-compatible service such as
+// MinIO or Cloudflare R2
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.us-east-1.amazonaws.com; buckets are addressed by path
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+}
+
+// s3Storage keeps avatars as objects in an S3 bucket
+type s3Storage struct {
+	config S3Config
+	client *http.Client
+}
+
+// NewS3Storage creates a Storage of objects in an S3 bucket
+func NewS3Storage(config S3Config) Storage {
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	return &s3Storage{config: config, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Put uploads an image with its content type
+func (s *s3Storage) Put(key, contentType string, data []byte) error {
+	resp, err := s.do("PUT", key, contentType, data)
+	if err != nil {
+		return fmt.Errorf("failed to upload avatar: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to upload avatar: S3 returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Get downloads an image and its content type
+func (s *s3Storage) Get(key string) ([]byte, string, error) {
+	resp, err := s.do("GET", key, "", nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download avatar: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to download avatar: S3 returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download avatar: %w", err)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = contentTypeOf(key)
+	}
+	return data, contentType, nil
+}
+
+// Delete removes an image; S3 doesn't report missing objects
+func (s *s3Storage) Delete(key string) error {
+	resp, err := s.do("DELETE", key, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete avatar: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete avatar: S3 returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// do sends a signed request for an object
+func (s *s3Storage) do(method, key, contentType string, data []byte) (*http.Response, error) {
+	objectURL := s.config.Endpoint + "/" + url.PathEscape(s.config.Bucket) + "/" + url.PathEscape(key)
+	req, err := http.NewRequest(method, objectURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	signS3Request(req, data, s.config.Region, s.config.AccessKey, s.config.SecretKey, time.Now().UTC())
+	return s.client.Do(req)
+}
+
+// signS3Request adds AWS Signature Version 4 headers to an S3 request
+func signS3Request(req *http.Request, payload []byte, region, accessKey, secretKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("Host", req.URL.Host)
+
+	var names []string
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	req.Header.Del("Host") // net/http sends req.Host itself
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
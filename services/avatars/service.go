@@ -0,0 +1,102 @@
+package avatars
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"regexp"
+)
+
+// Config holds avatar configuration
+type Config struct {
+	MaxBytes int64 // Largest image accepted
+}
+
+// DefaultConfig returns a default configuration for avatars
+func DefaultConfig() Config {
+	return Config{
+		MaxBytes: 2 << 20,
+	}
+}
+
+// contentTypes maps the image types accepted to their key extensions
+var contentTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// keyPattern matches keys made by Save: a hash of the user, a hash of the
+// image and the image's extension. Nothing else is looked up in storage.
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{16}-[0-9a-f]{32}\.(png|jpg|gif|webp)$`)
+
+// service implements the avatars Service interface
+type service struct {
+	storage Storage
+	config  Config
+}
+
+// New creates a new avatars service
+func New(storage Storage, config Config) Service {
+	return &service{storage: storage, config: config}
+}
+
+// Save validates an image and stores it for userID, returning its key
+func (s *service) Save(userID string, data []byte) (string, error) {
+	if int64(len(data)) > s.config.MaxBytes {
+		return "", ErrTooLarge
+	}
+	// The type is sniffed from the data; what the client claims is ignored
+	contentType := http.DetectContentType(data)
+	ext, ok := contentTypes[contentType]
+	if !ok {
+		return "", ErrUnsupportedType
+	}
+
+	user := sha256.Sum256([]byte(userID))
+	image := sha256.Sum256(data)
+	key := hex.EncodeToString(user[:8]) + "-" + hex.EncodeToString(image[:16]) + ext
+	if err := s.storage.Put(key, contentType, data); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Open returns an avatar's image and content type
+func (s *service) Open(key string) ([]byte, string, error) {
+	if !ValidKey(key) {
+		return nil, "", ErrNotFound
+	}
+	return s.storage.Get(key)
+}
+
+// Delete removes an avatar
+func (s *service) Delete(key string) error {
+	if !ValidKey(key) {
+		return nil
+	}
+	return s.storage.Delete(key)
+}
+
+// MaxBytes is the largest image accepted
+func (s *service) MaxBytes() int64 {
+	return s.config.MaxBytes
+}
+
+// ValidKey reports whether key could have been made by Save
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
+
+// contentTypeOf returns the content type of a key's image from its extension
+func contentTypeOf(key string) string {
+	ext := path.Ext(key)
+	for contentType, typeExt := range contentTypes {
+		if typeExt == ext {
+			return contentType
+		}
+	}
+	return "application/octet-stream"
+}
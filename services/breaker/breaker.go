@@ -0,0 +1,117 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Config holds circuit breaker configuration
+type Config struct {
+	Name             string        // Used in errors and logs, e.g. "genius"
+	FailureThreshold int           // Consecutive failures that open the circuit
+	OpenTimeout      time.Duration // How long the circuit stays open before a probe
+	IgnoreErrors     []error       // Errors that don't indicate an unhealthy service, e.g. not found
+}
+
+// DefaultConfig returns a default configuration for the named service
+func DefaultConfig(name string) Config {
+	return Config{
+		Name:             name,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+// Breaker stops calling a failing service for a while so requests fail fast
+// instead of waiting on timeouts, then lets a single probe through to detect
+// recovery
+type Breaker struct {
+	config   Config
+	state    string
+	failures int
+	openedAt time.Time
+	mutex    sync.Mutex
+}
+
+// New creates a closed circuit breaker
+func New(config Config) *Breaker {
+	return &Breaker{
+		config: config,
+		state:  StateClosed,
+	}
+}
+
+// Execute calls fn unless the circuit is open, and records the outcome
+func (b *Breaker) Execute(fn func() error) error {
+	if !b.allow() {
+		return fmt.Errorf("%s: %w", b.config.Name, ErrOpen)
+	}
+
+	err := fn()
+	b.record(err)
+	return err
+}
+
+// State returns the current circuit state
+func (b *Breaker) State() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.state
+}
+
+// allow checks whether a call may proceed, moving an open circuit to
+// half-open once the open timeout has passed
+func (b *Breaker) allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.config.OpenTimeout {
+			return false
+		}
+		b.state = StateHalfOpen
+		return true
+	case StateHalfOpen:
+		return false // A probe is already in flight
+	default:
+		return true
+	}
+}
+
+// record updates the circuit with the outcome of a call
+func (b *Breaker) record(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err == nil || b.ignored(err) {
+		if b.state != StateClosed {
+			log.Printf("Circuit %s closed: service recovered", b.config.Name)
+		}
+		b.state = StateClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.config.FailureThreshold {
+		if b.state != StateOpen {
+			log.Printf("Circuit %s opened after %d consecutive failures: %v", b.config.Name, b.failures, err)
+		}
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// ignored checks if err is expected and says nothing about service health
+func (b *Breaker) ignored(err error) bool {
+	for _, target := range b.config.IgnoreErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
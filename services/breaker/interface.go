@@ -0,0 +1,29 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrOpen is returned without calling the wrapped service while the circuit is open
+var ErrOpen = errors.New("service temporarily unavailable")
+
+// Circuit states
+const (
+	StateClosed   = "closed"    // Calls pass through
+	StateOpen     = "open"      // Calls fail fast with ErrOpen
+	StateHalfOpen = "half-open" // A single probe call is let through
+)
+
+// AIService is the AI provider interface wrapped by NewAI
+type AIService interface {
+	AnalyzeLyrics(query, lyrics, songInfo string) (string, error)
+	GenerateResponse(prompt string) (string, error)
+	GenerateJSON(prompt string) (string, error)
+	IsAvailable() error
+}
+
+// streamingAIService is implemented by providers that can stream responses
+type streamingAIService interface {
+	GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error
+}
@@ -0,0 +1,192 @@
+package breaker
+
+import (
+	"backend/server/models"
+	"backend/services/genius"
+	"backend/services/spotify"
+	"context"
+)
+
+// geniusService guards a Genius service with a circuit breaker
+type geniusService struct {
+	genius  genius.Service
+	breaker *Breaker
+}
+
+// NewGenius wraps a Genius service with a circuit breaker
+func NewGenius(service genius.Service, breaker *Breaker) genius.Service {
+	return &geniusService{genius: service, breaker: breaker}
+}
+
+// GetLyrics fetches lyrics unless the circuit is open
+func (s *geniusService) GetLyrics(trackName, artistName string) (lyrics string, err error) {
+	err = s.breaker.Execute(func() error {
+		lyrics, err = s.genius.GetLyrics(trackName, artistName)
+		return err
+	})
+	return lyrics, err
+}
+
+// GetArtist fetches an artist unless the circuit is open
+func (s *geniusService) GetArtist(name string) (artist *models.GeniusArtist, err error) {
+	err = s.breaker.Execute(func() error {
+		artist, err = s.genius.GetArtist(name)
+		return err
+	})
+	return artist, err
+}
+
+// GetSong fetches a song's metadata unless the circuit is open
+func (s *geniusService) GetSong(trackName, artistName string) (song *models.GeniusSong, err error) {
+	err = s.breaker.Execute(func() error {
+		song, err = s.genius.GetSong(trackName, artistName)
+		return err
+	})
+	return song, err
+}
+
+// spotifyService guards a Spotify service with a circuit breaker
+type spotifyService struct {
+	spotify spotify.Service
+	breaker *Breaker
+}
+
+// NewSpotify wraps a Spotify service with a circuit breaker
+func NewSpotify(service spotify.Service, breaker *Breaker) spotify.Service {
+	return &spotifyService{spotify: service, breaker: breaker}
+}
+
+// GetAccessToken gets an access token unless the circuit is open
+func (s *spotifyService) GetAccessToken() (token string, err error) {
+	err = s.breaker.Execute(func() error {
+		token, err = s.spotify.GetAccessToken()
+		return err
+	})
+	return token, err
+}
+
+// GetTrackByID fetches a track unless the circuit is open
+func (s *spotifyService) GetTrackByID(trackID string) (track *models.SpotifyTrack, err error) {
+	err = s.breaker.Execute(func() error {
+		track, err = s.spotify.GetTrackByID(trackID)
+		return err
+	})
+	return track, err
+}
+
+// SearchTrack searches for a track unless the circuit is open
+func (s *spotifyService) SearchTrack(name, artist string) (track *models.UnifiedTrack, err error) {
+	err = s.breaker.Execute(func() error {
+		track, err = s.spotify.SearchTrack(name, artist)
+		return err
+	})
+	return track, err
+}
+
+// SearchArtist searches for an artist unless the circuit is open
+func (s *spotifyService) SearchArtist(name string) (artist *models.SpotifyArtist, err error) {
+	err = s.breaker.Execute(func() error {
+		artist, err = s.spotify.SearchArtist(name)
+		return err
+	})
+	return artist, err
+}
+
+// GetRelatedArtists fetches related artists unless the circuit is open
+func (s *spotifyService) GetRelatedArtists(artistID string) (artists []models.SpotifyArtist, err error) {
+	err = s.breaker.Execute(func() error {
+		artists, err = s.spotify.GetRelatedArtists(artistID)
+		return err
+	})
+	return artists, err
+}
+
+// GetArtistTopTracks fetches an artist's top tracks unless the circuit is open
+func (s *spotifyService) GetArtistTopTracks(artistID string) (tracks []models.UnifiedTrack, err error) {
+	err = s.breaker.Execute(func() error {
+		tracks, err = s.spotify.GetArtistTopTracks(artistID)
+		return err
+	})
+	return tracks, err
+}
+
+// GetAlbum fetches an album unless the circuit is open
+func (s *spotifyService) GetAlbum(albumID string) (album *models.SpotifyAlbum, err error) {
+	err = s.breaker.Execute(func() error {
+		album, err = s.spotify.GetAlbum(albumID)
+		return err
+	})
+	return album, err
+}
+
+// AddToQueue adds to a user's queue without the breaker, since a user's
+// rejected token says nothing about Spotify's health
+func (s *spotifyService) AddToQueue(userToken, trackID string) error {
+	return s.spotify.AddToQueue(userToken, trackID)
+}
+
+// CreatePlaylist creates a user's playlist without the breaker, for the same reason
+func (s *spotifyService) CreatePlaylist(userToken, name, description string, trackIDs []string) (*models.SpotifyPlaylist, error) {
+	return s.spotify.CreatePlaylist(userToken, name, description, trackIDs)
+}
+
+// aiService guards an AI provider with a circuit breaker
+type aiService struct {
+	ai      AIService
+	breaker *Breaker
+}
+
+// NewAI wraps an AI provider with a circuit breaker. Streaming is preserved
+// when the provider supports it.
+func NewAI(service AIService, breaker *Breaker) AIService {
+	return &aiService{ai: service, breaker: breaker}
+}
+
+// IsAvailable checks the provider directly, bypassing the circuit
+func (s *aiService) IsAvailable() error {
+	return s.ai.IsAvailable()
+}
+
+// AnalyzeLyrics analyzes lyrics unless the circuit is open
+func (s *aiService) AnalyzeLyrics(query, lyrics, songInfo string) (answer string, err error) {
+	err = s.breaker.Execute(func() error {
+		answer, err = s.ai.AnalyzeLyrics(query, lyrics, songInfo)
+		return err
+	})
+	return answer, err
+}
+
+// GenerateResponse generates a response unless the circuit is open
+func (s *aiService) GenerateResponse(prompt string) (response string, err error) {
+	err = s.breaker.Execute(func() error {
+		response, err = s.ai.GenerateResponse(prompt)
+		return err
+	})
+	return response, err
+}
+
+// GenerateJSON generates a JSON response unless the circuit is open
+func (s *aiService) GenerateJSON(prompt string) (response string, err error) {
+	err = s.breaker.Execute(func() error {
+		response, err = s.ai.GenerateJSON(prompt)
+		return err
+	})
+	return response, err
+}
+
+// GenerateStream streams a response unless the circuit is open. Providers
+// without streaming support deliver their full response as a single chunk.
+func (s *aiService) GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error {
+	streamer, ok := s.ai.(streamingAIService)
+	if !ok {
+		response, err := s.GenerateResponse(prompt)
+		if err != nil {
+			return err
+		}
+		return onChunk(response)
+	}
+
+	return s.breaker.Execute(func() error {
+		return streamer.GenerateStream(ctx, prompt, onChunk)
+	})
+}
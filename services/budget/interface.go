@@ -0,0 +1,37 @@
+package budget
+
+import "errors"
+
+// ErrBudgetExceeded is returned when a daily token budget is used up and no
+// fallback AI service is configured
+var ErrBudgetExceeded = errors.New("daily AI token budget exceeded")
+
+// AIService is the AI provider interface exposed by the budget
+type AIService interface {
+	AnalyzeLyrics(query, lyrics, songInfo string) (string, error)
+	GenerateResponse(prompt string) (string, error)
+	GenerateJSON(prompt string) (string, error)
+	IsAvailable() error
+}
+
+// Usage is a snapshot of today's token usage
+type Usage struct {
+	Day         string `json:"day"` // UTC date, e.g. 2024-05-01
+	GlobalUsed  int    `json:"global_used"`
+	GlobalLimit int    `json:"global_limit"`
+	UserUsed    int    `json:"user_used"`
+	UserLimit   int    `json:"user_limit"`
+}
+
+// Service is an AIService that enforces daily OpenAI token budgets.
+// Calls made through the Service itself only count against the global budget;
+// use ForUser to also charge a user's budget.
+type Service interface {
+	AIService
+
+	// ForUser returns a view of the service that charges usage to userID
+	ForUser(userID string) AIService
+
+	// Usage returns today's token usage for userID and overall
+	Usage(userID string) Usage
+}
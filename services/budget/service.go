@@ -0,0 +1,148 @@
+package budget
+
+import (
+	"backend/services/openai"
+	"sync"
+	"time"
+)
+
+// Config holds daily token budget configuration. A limit of 0 disables that budget.
+type Config struct {
+	GlobalDailyTokens int
+	UserDailyTokens   int
+}
+
+// ledger tracks token usage for the current UTC day
+type ledger struct {
+	day    string
+	global int
+	users  map[string]int
+	mutex  sync.Mutex
+}
+
+// service implements the budget Service interface
+type service struct {
+	primary  openai.Service
+	fallback AIService // Used once a budget is exceeded; nil rejects the request
+	config   Config
+	ledger   *ledger
+	userID   string // Empty for calls that are only charged globally
+}
+
+// New wraps an OpenAI service with daily token budgets. When a budget is
+// exceeded requests are sent to fallback, or rejected with ErrBudgetExceeded
+// if fallback is nil.
+func New(primary openai.Service, fallback AIService, config Config) Service {
+	return &service{
+		primary:  primary,
+		fallback: fallback,
+		config:   config,
+		ledger:   &ledger{users: make(map[string]int)},
+	}
+}
+
+// ForUser returns a view of the service that charges usage to userID
+func (s *service) ForUser(userID string) AIService {
+	return &service{
+		primary:  s.primary,
+		fallback: s.fallback,
+		config:   s.config,
+		ledger:   s.ledger,
+		userID:   userID,
+	}
+}
+
+// Usage returns today's token usage for userID and overall
+func (s *service) Usage(userID string) Usage {
+	s.ledger.mutex.Lock()
+	defer s.ledger.mutex.Unlock()
+	s.ledger.rollover()
+
+	return Usage{
+		Day:         s.ledger.day,
+		GlobalUsed:  s.ledger.global,
+		GlobalLimit: s.config.GlobalDailyTokens,
+		UserUsed:    s.ledger.users[userID],
+		UserLimit:   s.config.UserDailyTokens,
+	}
+}
+
+// IsAvailable checks if the primary AI service is available
+func (s *service) IsAvailable() error {
+	return s.primary.IsAvailable()
+}
+
+// AnalyzeLyrics analyzes lyrics within the remaining budget
+func (s *service) AnalyzeLyrics(query, lyrics, songInfo string) (string, error) {
+	ai, err := s.provider()
+	if err != nil {
+		return "", err
+	}
+	return ai.AnalyzeLyrics(query, lyrics, songInfo)
+}
+
+// GenerateResponse generates a response within the remaining budget
+func (s *service) GenerateResponse(prompt string) (string, error) {
+	ai, err := s.provider()
+	if err != nil {
+		return "", err
+	}
+	return ai.GenerateResponse(prompt)
+}
+
+// GenerateJSON generates a JSON response within the remaining budget
+func (s *service) GenerateJSON(prompt string) (string, error) {
+	ai, err := s.provider()
+	if err != nil {
+		return "", err
+	}
+	return ai.GenerateJSON(prompt)
+}
+
+// provider returns the AI service to use for the next request: the primary
+// service with usage tracking while within budget, otherwise the fallback
+func (s *service) provider() (AIService, error) {
+	if !s.exceeded() {
+		return s.primary.WithUsageHook(s.record), nil
+	}
+	if s.fallback == nil {
+		return nil, ErrBudgetExceeded
+	}
+	return s.fallback, nil
+}
+
+// exceeded checks whether the global or the user's budget is used up
+func (s *service) exceeded() bool {
+	s.ledger.mutex.Lock()
+	defer s.ledger.mutex.Unlock()
+	s.ledger.rollover()
+
+	if s.config.GlobalDailyTokens > 0 && s.ledger.global >= s.config.GlobalDailyTokens {
+		return true
+	}
+	return s.userID != "" && s.config.UserDailyTokens > 0 && s.ledger.users[s.userID] >= s.config.UserDailyTokens
+}
+
+// record charges the tokens of a completion to the global and user budgets
+func (s *service) record(usage openai.Usage) {
+	tokens := usage.PromptTokens + usage.CompletionTokens
+
+	s.ledger.mutex.Lock()
+	defer s.ledger.mutex.Unlock()
+	s.ledger.rollover()
+
+	s.ledger.global += tokens
+	if s.userID != "" {
+		s.ledger.users[s.userID] += tokens
+	}
+}
+
+// rollover resets usage when the UTC day changes. Callers must hold the mutex.
+func (l *ledger) rollover() {
+	today := time.Now().UTC().Format("2006-01-02")
+	if l.day != today {
+		l.day = today
+		l.global = 0
+		l.users = make(map[string]int)
+	}
+}
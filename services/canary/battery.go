@@ -0,0 +1,57 @@
+package canary
+
+// Query kinds in the battery
+const (
+	KindLyricsAnalysis = "lyrics_analysis"
+	KindMoodDetection  = "mood_detection"
+	KindSongRequest    = "song_request"
+)
+
+// query is one representative request sent to both configurations
+type query struct {
+	name     string
+	kind     string
+	text     string
+	lyrics   string // For lyrics analysis
+	songInfo string // For lyrics analysis
+}
+
+// sampleLyrics is an original verse, so the battery doesn't depend on Genius
+const sampleLyrics = `I kept the lights on in an empty room
+Counting the echoes that answer for you
+Every road I take bends back to the start
+Still I keep walking with a map of your heart`
+
+// battery is the fixed set of queries every candidate is tested with. It
+// covers the main ways the chat uses the AI provider.
+var battery = []query{
+	{
+		name:     "lyrics meaning",
+		kind:     KindLyricsAnalysis,
+		text:     "What is this song about?",
+		lyrics:   sampleLyrics,
+		songInfo: "Map of Your Heart by The Canaries",
+	},
+	{
+		name:     "lyrics line",
+		kind:     KindLyricsAnalysis,
+		text:     "What does 'every road I take bends back to the start' mean?",
+		lyrics:   sampleLyrics,
+		songInfo: "Map of Your Heart by The Canaries",
+	},
+	{
+		name: "sad mood",
+		kind: KindMoodDetection,
+		text: "I've been feeling really down and alone since my best friend moved away",
+	},
+	{
+		name: "energetic mood",
+		kind: KindMoodDetection,
+		text: "Finally finished my exams, I'm so pumped for tonight!",
+	},
+	{
+		name: "song request",
+		kind: KindSongRequest,
+		text: "Recommend three songs similar to Numb by Linkin Park, one line each",
+	},
+}
@@ -0,0 +1,26 @@
+package canary
+
+import (
+	"backend/server/models"
+	"errors"
+)
+
+// ErrInvalidCandidate is returned when a candidate configuration can't be built
+var ErrInvalidCandidate = errors.New("invalid candidate configuration")
+
+// AIService is the AI provider interface exercised by the battery
+type AIService interface {
+	AnalyzeLyrics(query, lyrics, songInfo string) (string, error)
+	GenerateResponse(prompt string) (string, error)
+	GenerateJSON(prompt string) (string, error)
+}
+
+// Factory builds an uncached AI service for a fully specified configuration
+type Factory func(config models.CanaryConfig) (AIService, error)
+
+// Service compares candidate AI configurations against the live one
+type Service interface {
+	// Run answers the battery of representative queries with both the live
+	// configuration and candidate, side by side
+	Run(candidate models.CanaryConfig) (models.CanaryReport, error)
+}
@@ -0,0 +1,215 @@
+package canary
+
+import (
+	"backend/server/models"
+	"backend/services/mood"
+	"backend/services/prompts"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// charsPerToken approximates tokenization for cost estimates across providers
+const charsPerToken = 4
+
+// Price is a model's price in USD per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Config holds canary configuration
+type Config struct {
+	Live    models.CanaryConfig // The configuration serving traffic, fully specified
+	Prices  map[string]Price    // By model name; models without a price get no cost estimate
+	DataDir string              // Data directory for the mood service used by mood detection queries
+	Prompts prompts.Service     // Renders the mood detection prompt; nil uses the built-in templates
+}
+
+// service implements the canary Service interface
+type service struct {
+	factory Factory
+	config  Config
+}
+
+// New creates a new canary service that builds AI services with factory
+func New(factory Factory, config Config) Service {
+	return &service{
+		factory: factory,
+		config:  config,
+	}
+}
+
+// Run answers the battery with the live and candidate configurations
+// concurrently. Failed queries are reported per case rather than failing the run.
+func (s *service) Run(candidate models.CanaryConfig) (models.CanaryReport, error) {
+	candidate = s.merge(candidate)
+
+	liveAI, err := s.factory(s.config.Live)
+	if err != nil {
+		return models.CanaryReport{}, fmt.Errorf("failed to build live configuration: %w", err)
+	}
+	candidateAI, err := s.factory(candidate)
+	if err != nil {
+		return models.CanaryReport{}, fmt.Errorf("%w: %v", ErrInvalidCandidate, err)
+	}
+
+	report := models.CanaryReport{
+		StartedAt: time.Now(),
+		Cases:     make([]models.CanaryCase, len(battery)),
+	}
+
+	var wg sync.WaitGroup
+	for i, q := range battery {
+		report.Cases[i] = models.CanaryCase{Name: q.name, Kind: q.kind, Query: q.text}
+		wg.Add(2)
+		go func(result *models.CanaryResult, q query) {
+			defer wg.Done()
+			*result = s.ask(liveAI, s.config.Live, q)
+		}(&report.Cases[i].Current, q)
+		go func(result *models.CanaryResult, q query) {
+			defer wg.Done()
+			*result = s.ask(candidateAI, candidate, q)
+		}(&report.Cases[i].Candidate, q)
+	}
+	wg.Wait()
+
+	report.FinishedAt = time.Now()
+	report.Current = summarize(s.config.Live, report.Cases, func(c models.CanaryCase) models.CanaryResult { return c.Current })
+	report.Candidate = summarize(candidate, report.Cases, func(c models.CanaryCase) models.CanaryResult { return c.Candidate })
+	return report, nil
+}
+
+// merge fills the candidate's unset fields from the live configuration. The
+// model is only inherited when the provider is unchanged.
+func (s *service) merge(candidate models.CanaryConfig) models.CanaryConfig {
+	live := s.config.Live
+	if candidate.Provider == "" {
+		candidate.Provider = live.Provider
+	}
+	if candidate.Model == "" && candidate.Provider == live.Provider {
+		candidate.Model = live.Model
+	}
+	if candidate.Temperature == nil {
+		candidate.Temperature = live.Temperature
+	}
+	if candidate.MaxTokens == nil {
+		candidate.MaxTokens = live.MaxTokens
+	}
+	if candidate.TopP == nil {
+		candidate.TopP = live.TopP
+	}
+	return candidate
+}
+
+// ask sends one query to an AI service and measures it
+func (s *service) ask(ai AIService, config models.CanaryConfig, q query) models.CanaryResult {
+	metered := &meteredAI{ai: ai, instructions: config.Instructions}
+
+	start := time.Now()
+	var output string
+	var err error
+	switch q.kind {
+	case KindLyricsAnalysis:
+		output, err = metered.AnalyzeLyrics(q.text, q.lyrics, q.songInfo)
+	case KindMoodDetection:
+		// Use the mood service so the production prompt and validation are tested
+		var analysis *models.MoodAnalysis
+		analysis, err = mood.New(nil, metered, s.config.DataDir, s.config.Prompts).DetectMood(q.text)
+		if err == nil {
+			encoded, _ := json.Marshal(analysis)
+			output = string(encoded)
+		}
+	default:
+		output, err = metered.GenerateResponse(q.text)
+	}
+
+	result := models.CanaryResult{
+		Output:           output,
+		LatencyMs:        time.Since(start).Milliseconds(),
+		PromptTokens:     metered.promptTokens(),
+		CompletionTokens: metered.completionTokens(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if price, ok := s.config.Prices[config.Model]; ok {
+		cost := (float64(result.PromptTokens)*price.Input + float64(result.CompletionTokens)*price.Output) / 1e6
+		result.CostUSD = &cost
+	}
+	return result
+}
+
+// summarize totals one configuration's results
+func summarize(config models.CanaryConfig, cases []models.CanaryCase, result func(models.CanaryCase) models.CanaryResult) models.CanarySummary {
+	summary := models.CanarySummary{Config: config}
+	for _, c := range cases {
+		r := result(c)
+		if r.Error != "" {
+			summary.Errors++
+		}
+		summary.LatencyMs += r.LatencyMs
+		summary.PromptTokens += r.PromptTokens
+		summary.CompletionTokens += r.CompletionTokens
+		if r.CostUSD != nil {
+			total := *r.CostUSD
+			if summary.CostUSD != nil {
+				total += *summary.CostUSD
+			}
+			summary.CostUSD = &total
+		}
+	}
+	return summary
+}
+
+// meteredAI prepends candidate instructions to every request and counts the
+// characters sent and received
+type meteredAI struct {
+	ai           AIService
+	instructions string
+	sent         int
+	received     int
+}
+
+// AnalyzeLyrics analyzes lyrics with the instructions prepended to the query
+func (m *meteredAI) AnalyzeLyrics(query, lyrics, songInfo string) (string, error) {
+	query = m.prefix(query)
+	m.sent += len(query) + len(lyrics) + len(songInfo)
+	answer, err := m.ai.AnalyzeLyrics(query, lyrics, songInfo)
+	m.received += len(answer)
+	return answer, err
+}
+
+// GenerateResponse answers a prompt with the instructions prepended
+func (m *meteredAI) GenerateResponse(prompt string) (string, error) {
+	prompt = m.prefix(prompt)
+	m.sent += len(prompt)
+	answer, err := m.ai.GenerateResponse(prompt)
+	m.received += len(answer)
+	return answer, err
+}
+
+// GenerateJSON answers a prompt in JSON mode with the instructions prepended
+func (m *meteredAI) GenerateJSON(prompt string) (string, error) {
+	prompt = m.prefix(prompt)
+	m.sent += len(prompt)
+	answer, err := m.ai.GenerateJSON(prompt)
+	m.received += len(answer)
+	return answer, err
+}
+
+func (m *meteredAI) prefix(prompt string) string {
+	if m.instructions == "" {
+		return prompt
+	}
+	return m.instructions + "\n\n" + prompt
+}
+
+func (m *meteredAI) promptTokens() int {
+	return (m.sent + charsPerToken - 1) / charsPerToken
+}
+
+func (m *meteredAI) completionTokens() int {
+	return (m.received + charsPerToken - 1) / charsPerToken
+}
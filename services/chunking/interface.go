@@ -0,0 +1,35 @@
+package chunking
+
+import "context"
+
+// AIService is the AI provider interface wrapped by the chunker
+type AIService interface {
+	AnalyzeLyrics(query, lyrics, songInfo string) (string, error)
+	GenerateResponse(prompt string) (string, error)
+	GenerateJSON(prompt string) (string, error)
+	IsAvailable() error
+}
+
+// streamingAIService is implemented by providers that can stream responses
+type streamingAIService interface {
+	GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error
+}
+
+// Service is an AIService that condenses lyrics too long for the model's
+// context window before analyzing them
+type Service interface {
+	AIService
+
+	// GenerateStream streams a response from the wrapped provider. Providers
+	// without streaming support are called once and their full response is
+	// delivered as a single chunk.
+	GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error
+
+	// FitLyrics returns lyrics unchanged when they fit the token budget, and
+	// otherwise a condensed version built from summaries of their sections
+	FitLyrics(lyrics string) (string, error)
+
+	// Wrap returns a Service with the same budget that calls ai, e.g. for a
+	// per-user view of the wrapped provider
+	Wrap(ai AIService) Service
+}
@@ -0,0 +1,235 @@
+package chunking
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// charsPerToken approximates how many characters a model token covers
+const charsPerToken = 4
+
+// maxReduceRounds bounds how often summaries are summarized again before the
+// result is truncated to the budget
+const maxReduceRounds = 3
+
+// condensedHeader introduces condensed lyrics so the model knows it is not
+// reading the full text
+const condensedHeader = "[These lyrics were too long to include in full. Below are summaries of each section, in order, with notable lines quoted.]"
+
+// Config holds lyrics token budget configuration
+type Config struct {
+	TokenBudget int // Estimated tokens of lyrics allowed in one prompt; 0 disables chunking
+	ChunkTokens int // Estimated tokens per section summarized on its own
+	Parallelism int // Sections summarized at the same time
+}
+
+// DefaultConfig returns a default configuration for the chunker
+func DefaultConfig() Config {
+	return Config{
+		TokenBudget: 1500,
+		ChunkTokens: 1000,
+		Parallelism: 3,
+	}
+}
+
+// service implements the chunking Service interface
+type service struct {
+	ai     AIService
+	config Config
+}
+
+// New wraps an AI service so lyrics beyond the token budget are condensed
+// with map-reduce summarization before they are sent to the model
+func New(ai AIService, config Config) Service {
+	if config.ChunkTokens < 1 {
+		config.ChunkTokens = DefaultConfig().ChunkTokens
+	}
+	if config.Parallelism < 1 {
+		config.Parallelism = 1
+	}
+	return &service{
+		ai:     ai,
+		config: config,
+	}
+}
+
+// CountTokens estimates how many model tokens text takes up
+func CountTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// Wrap returns a Service with the same budget that calls ai
+func (s *service) Wrap(ai AIService) Service {
+	return &service{
+		ai:     ai,
+		config: s.config,
+	}
+}
+
+// IsAvailable checks if the wrapped AI service is available
+func (s *service) IsAvailable() error {
+	return s.ai.IsAvailable()
+}
+
+// AnalyzeLyrics analyzes lyrics, condensing them first if they exceed the budget
+func (s *service) AnalyzeLyrics(query, lyrics, songInfo string) (string, error) {
+	fitted, err := s.FitLyrics(lyrics)
+	if err != nil {
+		return "", err
+	}
+	return s.ai.AnalyzeLyrics(query, fitted, songInfo)
+}
+
+// GenerateResponse generates a response with the wrapped service
+func (s *service) GenerateResponse(prompt string) (string, error) {
+	return s.ai.GenerateResponse(prompt)
+}
+
+// GenerateJSON generates a JSON response with the wrapped service
+func (s *service) GenerateJSON(prompt string) (string, error) {
+	return s.ai.GenerateJSON(prompt)
+}
+
+// GenerateStream streams a response from the wrapped service
+func (s *service) GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error {
+	if streamer, ok := s.ai.(streamingAIService); ok {
+		return streamer.GenerateStream(ctx, prompt, onChunk)
+	}
+	response, err := s.ai.GenerateResponse(prompt)
+	if err != nil {
+		return err
+	}
+	return onChunk(response)
+}
+
+// FitLyrics condenses lyrics that exceed the token budget. Sections are
+// summarized on their own (map), and the joined summaries are summarized
+// again while they are still too long (reduce).
+func (s *service) FitLyrics(lyrics string) (string, error) {
+	if s.config.TokenBudget <= 0 || CountTokens(lyrics) <= s.config.TokenBudget {
+		return lyrics, nil
+	}
+
+	text := lyrics
+	for round := 0; round < maxReduceRounds; round++ {
+		chunks := Split(text, s.config.ChunkTokens)
+		summaries, err := s.summarize(chunks, round > 0)
+		if err != nil {
+			return "", fmt.Errorf("failed to condense lyrics: %w", err)
+		}
+		text = strings.Join(summaries, "\n\n")
+		if CountTokens(condensedHeader)+CountTokens(text) <= s.config.TokenBudget || len(chunks) == 1 {
+			break
+		}
+	}
+
+	return condensedHeader + "\n\n" + truncate(text, s.config.TokenBudget-CountTokens(condensedHeader)), nil
+}
+
+// summarize summarizes each chunk, keeping their order
+func (s *service) summarize(chunks []string, ofSummaries bool) ([]string, error) {
+	summaries := make([]string, len(chunks))
+	errs := make([]error, len(chunks))
+	semaphore := make(chan struct{}, s.config.Parallelism)
+	var wg sync.WaitGroup
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			summary, err := s.ai.GenerateResponse(summaryPrompt(chunk, i+1, len(chunks), ofSummaries))
+			summaries[i], errs[i] = strings.TrimSpace(summary), err
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return summaries, nil
+}
+
+// summaryPrompt asks for a summary of one section, a fraction of its length
+func summaryPrompt(chunk string, part, parts int, ofSummaries bool) string {
+	words := len(strings.Fields(chunk)) / 4
+	if words < 30 {
+		words = 30
+	}
+	source := "song lyrics"
+	if ofSummaries {
+		source = "summaries of song lyrics"
+	}
+	return fmt.Sprintf(`The following text is part %d of %d of some %s. Summarize it in at most %d words: describe what happens, the feelings and themes, and quote the most striking lines word for word. Reply with the summary only.
+
+Text:
+%s`, part, parts, source, words, chunk)
+}
+
+// Split breaks text into chunks of at most maxTokens estimated tokens,
+// preferring to break between stanzas, then between lines
+func Split(text string, maxTokens int) []string {
+	var chunks []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+	}
+	add := func(piece, separator string) {
+		if current.Len() > 0 && CountTokens(current.String()+separator+piece) > maxTokens {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString(separator)
+		}
+		current.WriteString(piece)
+	}
+
+	for _, stanza := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		stanza = strings.TrimSpace(stanza)
+		if stanza == "" {
+			continue
+		}
+		if CountTokens(stanza) <= maxTokens {
+			add(stanza, "\n\n")
+			continue
+		}
+		// A stanza too long on its own is broken between lines, and a line too
+		// long on its own is cut at the token limit
+		flush()
+		for _, line := range strings.Split(stanza, "\n") {
+			for CountTokens(line) > maxTokens {
+				head := truncate(line, maxTokens)
+				add(head, "\n")
+				line = line[len(head):]
+			}
+			add(line, "\n")
+		}
+		flush()
+	}
+	flush()
+	return chunks
+}
+
+// truncate cuts text to at most maxTokens estimated tokens
+func truncate(text string, maxTokens int) string {
+	if maxTokens < 1 {
+		maxTokens = 1
+	}
+	limit := maxTokens * charsPerToken
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit])
+}
@@ -0,0 +1,39 @@
+package cleanmode
+
+import "backend/services/profanity"
+
+// SystemPrompt is prepended to every AI prompt in clean mode
+const SystemPrompt = `Keep this answer family-friendly:
+- Do not repeat profanity, slurs or sexual content, even when quoting lyrics; refer to such words or themes in general terms instead.
+- Mature themes in a song may still be explained, without graphic detail.
+
+`
+
+// cleanAI prepends the clean mode system prompt to every request and masks
+// profanity in lyrics and answers
+type cleanAI struct {
+	ai     AIService
+	filter profanity.Filter
+}
+
+// AnalyzeLyrics analyzes masked lyrics under the clean mode system prompt
+func (c *cleanAI) AnalyzeLyrics(query, lyrics, songInfo string) (string, error) {
+	answer, err := c.ai.AnalyzeLyrics(SystemPrompt+query, c.filter.Mask(lyrics), songInfo)
+	return c.filter.Mask(answer), err
+}
+
+// GenerateResponse answers under the clean mode system prompt
+func (c *cleanAI) GenerateResponse(prompt string) (string, error) {
+	answer, err := c.ai.GenerateResponse(SystemPrompt + prompt)
+	return c.filter.Mask(answer), err
+}
+
+// GenerateJSON answers in JSON mode under the clean mode system prompt
+func (c *cleanAI) GenerateJSON(prompt string) (string, error) {
+	return c.ai.GenerateJSON(SystemPrompt + prompt)
+}
+
+// IsAvailable checks the wrapped service
+func (c *cleanAI) IsAvailable() error {
+	return c.ai.IsAvailable()
+}
@@ -0,0 +1,32 @@
+package cleanmode
+
+import "backend/server/models"
+
+// AIService is the AI provider interface clean mode constrains
+type AIService interface {
+	AnalyzeLyrics(query, lyrics, songInfo string) (string, error)
+	GenerateResponse(prompt string) (string, error)
+	GenerateJSON(prompt string) (string, error)
+	IsAvailable() error
+}
+
+// Service decides which users are in clean mode, where profanity is masked in
+// lyrics and analyses are kept family-friendly. Unlike restricted mode, clean
+// mode leaves the rest of the experience alone, and users choose it themselves.
+type Service interface {
+	// IsClean reports whether userID turned clean mode on
+	IsClean(userID string) bool
+
+	// SetClean turns clean mode on or off for one user
+	SetClean(userID string, clean bool)
+
+	// Status returns the user's clean mode setting
+	Status(userID string) models.CleanModeStatus
+
+	// Mask replaces profanity in text with asterisks
+	Mask(text string) string
+
+	// WrapAI asks an AI service for family-friendly answers and masks
+	// profanity in the lyrics it is given and the answers it returns
+	WrapAI(ai AIService) AIService
+}
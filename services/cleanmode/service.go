@@ -0,0 +1,69 @@
+package cleanmode
+
+import (
+	"backend/server/models"
+	"backend/services/profanity"
+	"sync"
+)
+
+// Config holds clean mode configuration
+type Config struct {
+	Users []string // Users in clean mode from startup
+	Words []string // Words masked on top of the built-in profanity list
+}
+
+// service implements the clean mode Service interface
+type service struct {
+	filter profanity.Filter
+	users  map[string]bool // userID -> per-user setting
+	mutex  sync.RWMutex
+}
+
+// New creates a new clean mode service
+func New(config Config) Service {
+	users := make(map[string]bool, len(config.Users))
+	for _, userID := range config.Users {
+		users[userID] = true
+	}
+	return &service{
+		filter: profanity.New(profanity.Config{Words: config.Words}),
+		users:  users,
+	}
+}
+
+// IsClean reports whether userID turned clean mode on
+func (s *service) IsClean(userID string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.users[userID]
+}
+
+// SetClean turns clean mode on or off for one user
+func (s *service) SetClean(userID string, clean bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if clean {
+		s.users[userID] = true
+	} else {
+		delete(s.users, userID)
+	}
+}
+
+// Status returns the user's clean mode setting
+func (s *service) Status(userID string) models.CleanModeStatus {
+	return models.CleanModeStatus{
+		UserID: userID,
+		Clean:  s.IsClean(userID),
+	}
+}
+
+// Mask replaces profanity in text with asterisks
+func (s *service) Mask(text string) string {
+	return s.filter.Mask(text)
+}
+
+// WrapAI asks an AI service for family-friendly answers
+func (s *service) WrapAI(ai AIService) AIService {
+	return &cleanAI{ai: ai, filter: s.filter}
+}
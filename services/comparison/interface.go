@@ -0,0 +1,45 @@
+package comparison
+
+import (
+	"backend/server/models"
+	"backend/services/mood"
+	"fmt"
+)
+
+// SongError is returned when one of the songs couldn't be fetched or analyzed
+type SongError struct {
+	Track models.TrackReference
+	Err   error
+}
+
+func (e *SongError) Error() string {
+	return fmt.Sprintf("%s by %s: %v", e.Track.Name, e.Track.Artist, e.Err)
+}
+
+func (e *SongError) Unwrap() error {
+	return e.Err
+}
+
+// Lyrics fetches a song's lyrics with their mood analysis
+type Lyrics interface {
+	GetLyricsWithMood(trackName, artistName string) (*mood.LyricsWithMood, error)
+}
+
+// AIService writes the comparison
+type AIService interface {
+	GenerateJSON(prompt string) (string, error)
+}
+
+// Options adjusts a comparison to the request
+type Options struct {
+	Instruction string                     // Added to the prompt, e.g. to pick the language
+	MaskLyrics  func(lyrics string) string // Optional; applied to the lyrics before they go in the prompt
+}
+
+// Service compares songs
+type Service interface {
+	// Compare fetches both songs' lyrics and mood analyses and asks ai for a
+	// comparison of their themes, mood and era. Errors fetching the lyrics are
+	// returned in a SongError, e.g. wrapping genius.ErrSongNotFound.
+	Compare(first, second models.TrackReference, ai AIService, options Options) (*models.SongComparison, error)
+}
@@ -0,0 +1,131 @@
+package comparison
+
+import (
+	"backend/server/models"
+	"backend/services/mood"
+	"backend/services/prompts"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// maxLyrics is how much of each song's lyrics goes in the prompt, in runes
+const maxLyrics = 3000
+
+// service implements the comparison Service interface
+type service struct {
+	lyrics    Lyrics
+	templates prompts.Service
+}
+
+// New creates a new song comparison service. A nil templates uses the built-in prompts.
+func New(lyrics Lyrics, templates prompts.Service) Service {
+	if templates == nil {
+		templates = prompts.Default()
+	}
+	return &service{
+		lyrics:    lyrics,
+		templates: templates,
+	}
+}
+
+// Compare asks ai for a comparison of two songs
+func (s *service) Compare(first, second models.TrackReference, ai AIService, options Options) (*models.SongComparison, error) {
+	// Both songs are fetched and analyzed at the same time
+	tracks := []models.TrackReference{first, second}
+	analyses := make([]*mood.LyricsWithMood, len(tracks))
+	errs := make([]error, len(tracks))
+	var wg sync.WaitGroup
+	for i, track := range tracks {
+		wg.Add(1)
+		go func(i int, track models.TrackReference) {
+			defer wg.Done()
+			analyses[i], errs[i] = s.lyrics.GetLyricsWithMood(track.Name, track.Artist)
+		}(i, track)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, &SongError{Track: tracks[i], Err: err}
+		}
+	}
+
+	prompt, err := s.templates.Render(prompts.SongComparison, prompts.SongComparisonData{
+		First:  comparedSong(first, analyses[0], options.MaskLyrics),
+		Second: comparedSong(second, analyses[1], options.MaskLyrics),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if options.Instruction != "" {
+		prompt += "\n\n" + options.Instruction
+	}
+
+	response, err := ai.GenerateJSON(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare songs: %w", err)
+	}
+	var generated struct {
+		SharedThemes []string `json:"shared_themes"`
+		FirstThemes  []string `json:"first_themes"`
+		SecondThemes []string `json:"second_themes"`
+		Mood         string   `json:"mood"`
+		Era          string   `json:"era"`
+		Summary      string   `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(response), &generated); err != nil {
+		return nil, fmt.Errorf("failed to parse comparison: %w", err)
+	}
+
+	return &models.SongComparison{
+		First:        moodResult(first, analyses[0]),
+		Second:       moodResult(second, analyses[1]),
+		SharedThemes: nonEmpty(generated.SharedThemes),
+		FirstThemes:  nonEmpty(generated.FirstThemes),
+		SecondThemes: nonEmpty(generated.SecondThemes),
+		Mood:         strings.TrimSpace(generated.Mood),
+		Era:          strings.TrimSpace(generated.Era),
+		Summary:      strings.TrimSpace(generated.Summary),
+	}, nil
+}
+
+// comparedSong is a song's part of the prompt
+func comparedSong(track models.TrackReference, analysis *mood.LyricsWithMood, mask func(string) string) prompts.ComparedSong {
+	lyrics := analysis.Lyrics
+	if mask != nil {
+		lyrics = mask(lyrics)
+	}
+	if runes := []rune(lyrics); len(runes) > maxLyrics {
+		lyrics = string(runes[:maxLyrics]) + "..."
+	}
+	song := prompts.ComparedSong{
+		SongInfo: fmt.Sprintf("%s by %s", track.Name, track.Artist),
+		Lyrics:   lyrics,
+	}
+	if analysis.MoodAnalysis != nil {
+		song.Mood = analysis.MoodAnalysis.PrimaryMood
+	}
+	return song
+}
+
+// moodResult is a song's mood analysis as returned with the comparison
+func moodResult(track models.TrackReference, analysis *mood.LyricsWithMood) models.TrackMoodResult {
+	return models.TrackMoodResult{
+		Track:        track,
+		MoodAnalysis: analysis.MoodAnalysis,
+		Themes:       analysis.Themes,
+	}
+}
+
+// nonEmpty trims the items, dropping empty ones, and never returns nil so
+// the lists are encoded as arrays
+func nonEmpty(items []string) []string {
+	kept := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,17 @@
+package crypto
+
+import "errors"
+
+// ErrDecrypt is returned when a value can't be decrypted with the user's key,
+// e.g. because it belongs to another user or was tampered with
+var ErrDecrypt = errors.New("crypto: failed to decrypt value")
+
+// Service encrypts sensitive per-user data with a key derived for each user
+type Service interface {
+	// Encrypt encrypts plaintext with userID's key
+	Encrypt(userID, plaintext string) (string, error)
+
+	// Decrypt decrypts a value produced by Encrypt for the same user.
+	// Values that were never encrypted are returned unchanged.
+	Decrypt(userID, value string) (string, error)
+}
@@ -0,0 +1,145 @@
+package crypto
+
+import (
+	"container/list"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// prefix marks encrypted values and their format version
+	prefix = "enc:v1:"
+	// keySize is the AES-256 key size
+	keySize = 32
+	// MinMasterKeySize is the minimum master key length in bytes
+	MinMasterKeySize = 32
+	// maxCiphers caps the users whose cipher is kept; least recently used
+	// ones are derived again when needed
+	maxCiphers = 1000
+)
+
+// Config holds encryption configuration
+type Config struct {
+	// MasterKey is the secret user keys are derived from. It never leaves
+	// the server, so database access alone can't decrypt user data.
+	MasterKey []byte
+}
+
+// userCipher is a user's AEAD, kept in the service's LRU
+type userCipher struct {
+	userID string
+	aead   cipher.AEAD
+}
+
+// service implements the crypto Service interface with AES-256-GCM
+type service struct {
+	masterKey []byte
+	ciphers   map[string]*list.Element // userID -> *userCipher with the user's key
+	order     *list.List               // Front is most recently used
+	mutex     sync.Mutex
+}
+
+// New creates a crypto service. User keys are derived from the master key
+// and the user's identity from the auth provider (the X-User-ID subject)
+// with HKDF-SHA256, so each user's data is encrypted with its own key.
+func New(config Config) (Service, error) {
+	if len(config.MasterKey) < MinMasterKeySize {
+		return nil, fmt.Errorf("crypto: master key must be at least %d bytes, got %d", MinMasterKeySize, len(config.MasterKey))
+	}
+	return &service{
+		masterKey: config.MasterKey,
+		ciphers:   make(map[string]*list.Element),
+		order:     list.New(),
+	}, nil
+}
+
+// Encrypt encrypts plaintext with userID's key
+func (s *service) Encrypt(userID, plaintext string) (string, error) {
+	aead, err := s.cipherFor(userID)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("crypto: failed to generate nonce: %w", err)
+	}
+	// Binding the user ID means a value copied into another user's data won't decrypt
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(userID))
+	return prefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt for the same user.
+// Values that were never encrypted are returned unchanged.
+func (s *service) Decrypt(userID, value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	aead, err := s.cipherFor(userID)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(userID))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
+
+// cipherFor returns the AEAD for userID's derived key, keeping the
+// maxCiphers most recently used ones
+func (s *service) cipherFor(userID string) (cipher.AEAD, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if element, ok := s.ciphers[userID]; ok {
+		s.order.MoveToFront(element)
+		return element.Value.(*userCipher).aead, nil
+	}
+
+	block, err := aes.NewCipher(deriveKey(s.masterKey, userID))
+	if err != nil {
+		return nil, fmt.Errorf("crypto: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: %w", err)
+	}
+	s.ciphers[userID] = s.order.PushFront(&userCipher{userID: userID, aead: aead})
+	for s.order.Len() > maxCiphers {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.ciphers, oldest.Value.(*userCipher).userID)
+	}
+	return aead, nil
+}
+
+// deriveKey derives a user's key with HKDF-SHA256 (RFC 5869). A single
+// expand block is enough for a 32-byte key.
+func deriveKey(masterKey []byte, userID string) []byte {
+	extract := hmac.New(sha256.New, []byte("linkinsync-user-data"))
+	extract.Write(masterKey)
+	pseudoRandomKey := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, pseudoRandomKey)
+	expand.Write([]byte("user:" + userID))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)[:keySize]
+}
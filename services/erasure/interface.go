@@ -0,0 +1,22 @@
+package erasure
+
+import (
+	"backend/server/models"
+	"context"
+)
+
+// Forgetter drops what a service keeps in memory about a user. The weekly
+// reports service satisfies it.
+type Forgetter interface {
+	Forget(userID string)
+}
+
+// Service defines the interface for deleting a user's data on request.
+// Everything the server keeps under the user's ID is deleted, except rows
+// that feed shared aggregates, which are anonymized. Moderation records such
+// as bans and the audit log are kept.
+type Service interface {
+	// Erase deletes the user's data and returns a receipt. Every step can be
+	// repeated, so a failed erasure can simply be retried.
+	Erase(ctx context.Context, userID string) (models.DataDeletionReceipt, error)
+}
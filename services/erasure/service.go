@@ -0,0 +1,209 @@
+package erasure
+
+import (
+	"backend/server/models"
+	"backend/services/avatars"
+	"backend/services/users"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// notPerUser lists the data the server keeps for everyone, which receipts
+// name so users know it was not overlooked
+var notPerUser = []string{
+	"play_history", // The server records one shared listening history
+	"tokens",       // Spotify is called with app credentials and API keys aren't tied to users
+}
+
+// deletion is a statement deleting one kind of a user's rows. $1 is the
+// user's ID and $2 their lowercased email, which may be empty.
+type deletion struct {
+	kind  string
+	query string
+}
+
+// deletions run in order in one transaction. Reactions on the user's
+// messages and reports of them go with the messages.
+var deletions = []deletion{
+	{"messages", `DELETE FROM global_messages WHERE user_id = $1 OR ($2 <> '' AND LOWER(user_email) = $2)`},
+	{"reactions", `DELETE FROM message_reactions WHERE user_id = $1`},
+	{"reports", `DELETE FROM message_reports WHERE reporter_id = $1`},
+	{"blocks", `DELETE FROM user_blocks WHERE user_id = $1`},
+	{"read_markers", `DELETE FROM read_markers WHERE user_id = $1`},
+	{"moderation_verdicts", `DELETE FROM moderation_verdicts WHERE $2 <> '' AND LOWER(user_email) = $2`},
+	{"quiz_scores", `DELETE FROM quiz_scores WHERE user_id = $1`},
+	{"daily_stats", `DELETE FROM daily_user_stats WHERE user_id = $1`},
+	{"daily_moods", `DELETE FROM daily_user_moods WHERE user_id = $1`},
+	{"analysis_ratings", `DELETE FROM lyrics_analysis_ratings WHERE user_id = $1`},
+	{"jobs", `DELETE FROM queued_jobs WHERE user_id = $1 AND type <> '` + models.JobTypeDataDeletion + `'`},
+}
+
+// anonymizations keep rows that feed shared aggregates, clearing the user's ID
+var anonymizations = []deletion{
+	{"experiment_outcomes", `UPDATE prompt_experiment_outcomes SET user_id = '' WHERE user_id = $1`},
+}
+
+// Config holds the erasure configuration
+type Config struct {
+	DataDir string // Directory holding mood_history
+}
+
+// DefaultConfig returns a default configuration for erasure
+func DefaultConfig() Config {
+	return Config{DataDir: "./data"}
+}
+
+// service implements the erasure Service interface
+type service struct {
+	db         *sql.DB         // May be nil, in which case no rows are deleted
+	users      users.Service   // May be nil, in which case there are no profiles
+	avatars    avatars.Service // May be nil, in which case there are no avatars
+	forgetters []Forgetter
+	config     Config
+}
+
+// New creates a new erasure service. forgetters are told about every erased
+// user.
+func New(db *sql.DB, users users.Service, avatars avatars.Service, config Config, forgetters ...Forgetter) Service {
+	return &service{
+		db:         db,
+		users:      users,
+		avatars:    avatars,
+		forgetters: forgetters,
+		config:     config,
+	}
+}
+
+// Erase deletes the user's rows, mood history, profile and avatar. The
+// profile goes last, so a retry still finds the email the user's messages
+// were posted under.
+func (s *service) Erase(ctx context.Context, userID string) (models.DataDeletionReceipt, error) {
+	receipt := models.DataDeletionReceipt{
+		UserID:     userID,
+		Deleted:    make(map[string]int64),
+		Anonymized: make(map[string]int64),
+		NotPerUser: notPerUser,
+	}
+	if userID == "" {
+		return receipt, errors.New("user ID is required")
+	}
+
+	var profile models.User
+	if s.users != nil {
+		var err error
+		if profile, err = s.users.Get(userID); err != nil {
+			return receipt, fmt.Errorf("failed to load the profile: %w", err)
+		}
+	}
+	email := strings.ToLower(profile.Email)
+	if email == "" && strings.Contains(userID, "@") {
+		email = strings.ToLower(userID)
+	}
+
+	if s.db != nil {
+		if err := s.eraseRows(ctx, userID, email, &receipt); err != nil {
+			return receipt, err
+		}
+	}
+
+	files, err := s.eraseMoodHistory(userID)
+	if err != nil {
+		return receipt, err
+	}
+	receipt.Deleted["mood_history_files"] = files
+	for _, forgetter := range s.forgetters {
+		forgetter.Forget(userID)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return receipt, err
+	}
+	if s.avatars != nil && profile.AvatarURL != "" {
+		err := s.avatars.Delete(path.Base(profile.AvatarURL))
+		switch {
+		case err == nil:
+			receipt.Deleted["avatar"] = 1
+		case !errors.Is(err, avatars.ErrNotFound):
+			return receipt, fmt.Errorf("failed to delete the avatar: %w", err)
+		}
+	}
+	if s.users != nil {
+		_, err := s.users.Delete(userID)
+		switch {
+		case err == nil:
+			receipt.Deleted["profile"] = 1
+		case !errors.Is(err, users.ErrNotFound):
+			return receipt, fmt.Errorf("failed to delete the profile: %w", err)
+		}
+	}
+
+	receipt.CompletedAt = time.Now()
+	return receipt, nil
+}
+
+// eraseRows runs the deletions and anonymizations in one transaction, so a
+// failed attempt changes nothing
+func (s *service) eraseRows(ctx context.Context, userID, email string, receipt *models.DataDeletionReceipt) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	run := func(d deletion, counts map[string]int64, args ...interface{}) error {
+		result, err := tx.ExecContext(ctx, d.query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to erase %s: %w", d.kind, err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to count erased %s: %w", d.kind, err)
+		}
+		counts[d.kind] = affected
+		return nil
+	}
+	for _, d := range deletions {
+		if err := run(d, receipt.Deleted, userID, email); err != nil {
+			return err
+		}
+	}
+	for _, d := range anonymizations {
+		if err := run(d, receipt.Anonymized, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit erasure: %w", err)
+	}
+	return nil
+}
+
+// eraseMoodHistory removes the user's mood history and journal files,
+// returning how many there were
+func (s *service) eraseMoodHistory(userID string) (int64, error) {
+	// The ID names the files, so one that isn't a plain file name has none
+	if filepath.Base(userID) != userID {
+		return 0, nil
+	}
+
+	var removed int64
+	for _, name := range []string{"user_%s_mood_history.txt", "user_%s_mood_journal.txt"} {
+		file := filepath.Join(s.config.DataDir, "mood_history", fmt.Sprintf(name, userID))
+		err := os.Remove(file)
+		switch {
+		case err == nil:
+			removed++
+		case !os.IsNotExist(err):
+			return removed, fmt.Errorf("failed to delete mood history: %w", err)
+		}
+	}
+	return removed, nil
+}
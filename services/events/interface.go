@@ -0,0 +1,56 @@
+package events
+
+import (
+	"backend/server/models"
+	"time"
+)
+
+// Event types
+const (
+	TrackChanged         = "track_changed"         // Payload: models.UnifiedTrack
+	MoodDetected         = "mood_detected"         // Payload: MoodDetectedPayload
+	MessagePosted        = "message_posted"        // Payload: models.Message
+	MessageReacted       = "message_reacted"       // Payload: models.MessageReactions
+	MessageRemoved       = "message_removed"       // Payload: models.Message with only its ID
+	RecommendationServed = "recommendation_served" // Payload: RecommendationServedPayload
+	QuizRoundStarted     = "quiz_round_started"    // Payload: models.QuizRound
+	QuizRoundEnded       = "quiz_round_ended"      // Payload: models.QuizRoundResult
+	QuizEnded            = "quiz_ended"            // Payload: models.QuizGame
+)
+
+// Event is a message delivered to subscribers
+type Event struct {
+	Type    string
+	Time    time.Time
+	Payload interface{}
+}
+
+// Handler processes an event. Returned errors are logged with the subscriber
+// name and don't affect other subscribers or the publisher.
+type Handler func(event Event) error
+
+// MoodDetectedPayload is published when a chat message's mood is analyzed
+type MoodDetectedPayload struct {
+	UserID   string              `json:"user_id"`
+	Analysis models.MoodAnalysis `json:"analysis"`
+}
+
+// RecommendationServedPayload is published when tracks are recommended to a user
+type RecommendationServedPayload struct {
+	UserID string                `json:"user_id"`
+	Kind   string                `json:"kind"` // e.g. "mood_recommendation", "artist_radio"
+	Tracks []models.UnifiedTrack `json:"tracks"`
+}
+
+// Bus is an in-memory publish/subscribe event bus
+type Bus interface {
+	// Subscribe registers a named handler for an event type. Each subscriber
+	// receives events in publish order on its own goroutine.
+	Subscribe(eventType, name string, handler Handler)
+
+	// Publish delivers an event to the type's subscribers without waiting for them
+	Publish(eventType string, payload interface{})
+
+	// Close stops accepting events and waits for queued events to be handled
+	Close()
+}
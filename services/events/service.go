@@ -0,0 +1,119 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Config holds event bus configuration
+type Config struct {
+	QueueSize int // Events buffered per subscriber; further events are dropped
+}
+
+// DefaultConfig returns a default configuration for the event bus
+func DefaultConfig() Config {
+	return Config{
+		QueueSize: 100,
+	}
+}
+
+// subscriber is a registered handler with its own delivery queue
+type subscriber struct {
+	name    string
+	handler Handler
+	queue   chan Event
+}
+
+// bus implements the Bus interface
+type bus struct {
+	config      Config
+	subscribers map[string][]*subscriber
+	closed      bool
+	mutex       sync.RWMutex
+	wg          sync.WaitGroup
+}
+
+// New creates a new event bus
+func New(config Config) Bus {
+	return &bus{
+		config:      config,
+		subscribers: make(map[string][]*subscriber),
+	}
+}
+
+// Subscribe registers a named handler for an event type
+func (b *bus) Subscribe(eventType, name string, handler Handler) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	sub := &subscriber{
+		name:    name,
+		handler: handler,
+		queue:   make(chan Event, b.config.QueueSize),
+	}
+	b.subscribers[eventType] = append(b.subscribers[eventType], sub)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for event := range sub.queue {
+			sub.handle(event)
+		}
+	}()
+}
+
+// Publish delivers an event to the type's subscribers without waiting for them
+func (b *bus) Publish(eventType string, payload interface{}) {
+	event := Event{Type: eventType, Time: time.Now(), Payload: payload}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	for _, sub := range b.subscribers[eventType] {
+		select {
+		case sub.queue <- event:
+		default:
+			log.Printf("Event bus: dropped %s event for slow subscriber %s", eventType, sub.name)
+		}
+	}
+}
+
+// Close stops accepting events and waits for queued events to be handled
+func (b *bus) Close() {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return
+	}
+	b.closed = true
+	for _, subs := range b.subscribers {
+		for _, sub := range subs {
+			close(sub.queue)
+		}
+	}
+	b.mutex.Unlock()
+
+	b.wg.Wait()
+}
+
+// handle runs the subscriber's handler, logging errors and recovering panics
+func (s *subscriber) handle(event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Event bus: subscriber %s panicked on %s: %v", s.name, event.Type, r)
+		}
+	}()
+
+	if err := s.handler(event); err != nil {
+		log.Printf("Event bus: subscriber %s failed on %s: %v", s.name, event.Type, err)
+	}
+}
@@ -0,0 +1,75 @@
+package experiments
+
+import (
+	"backend/server/models"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned for feedback on a response that was not part of
+	// an experiment, or belongs to another user
+	ErrNotFound = errors.New("experiment response not found")
+	// ErrInvalidExperiment is returned for experiments without variants or
+	// with variants whose template isn't loaded
+	ErrInvalidExperiment = errors.New("invalid prompt experiment")
+)
+
+// Control is the variant that uses the template under test unchanged
+const Control = "control"
+
+// Variant is one arm of an experiment
+type Variant struct {
+	Name   string // Control, or the variant suffix of a "<template>.<variant>" prompt template
+	Weight int    // Share of users assigned, relative to the other variants
+}
+
+// Assignment is the variant a user's requests use in an experiment
+type Assignment struct {
+	Experiment string
+	Variant    string
+	Template   string // Prompt template to render
+}
+
+// Summary aggregates the outcomes of one variant
+type Summary struct {
+	Experiment     string
+	Variant        string
+	Requests       int
+	Failures       int
+	TotalLatencyMs int64
+	TotalLength    int64 // Of successful answers
+	Helpful        int
+	Unhelpful      int
+}
+
+// Store keeps experiment outcomes
+type Store interface {
+	// Save records an outcome
+	Save(outcome models.ExperimentOutcome) error
+
+	// SetFeedback records a user's feedback on one of their responses, or
+	// returns ErrNotFound
+	SetFeedback(userID, responseID string, helpful bool) error
+
+	// Summaries aggregates the outcomes of every variant with any
+	Summaries() ([]Summary, error)
+}
+
+// Service assigns users to prompt experiment variants and compares how the
+// variants do
+type Service interface {
+	// Assign returns the variant userID is assigned in the experiment on the
+	// named template. Assignments are sticky: a user always gets the same
+	// variant while the experiment's variants stay the same. ok is false when
+	// no experiment runs on the template.
+	Assign(template, userID string) (assignment Assignment, ok bool)
+
+	// Record saves the outcome of a request, returning its response ID
+	Record(assignment Assignment, userID string, latencyMs int64, answer string, failed bool) (string, error)
+
+	// Feedback records whether a user found a response helpful
+	Feedback(userID, responseID string, helpful bool) error
+
+	// Results compares the variants of every experiment
+	Results() ([]models.ExperimentResult, error)
+}
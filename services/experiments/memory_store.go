@@ -0,0 +1,81 @@
+package experiments
+
+import (
+	"backend/server/models"
+	"sort"
+	"sync"
+)
+
+// memoryStore keeps outcomes in memory, for development without a database and tests
+type memoryStore struct {
+	outcomes map[string]models.ExperimentOutcome // By response ID
+	mutex    sync.RWMutex
+}
+
+// NewMemoryStore creates an in-memory Store
+func NewMemoryStore() Store {
+	return &memoryStore{outcomes: make(map[string]models.ExperimentOutcome)}
+}
+
+// Save records an outcome
+func (m *memoryStore) Save(outcome models.ExperimentOutcome) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.outcomes[outcome.ResponseID] = outcome
+	return nil
+}
+
+// SetFeedback records a user's feedback on one of their responses
+func (m *memoryStore) SetFeedback(userID, responseID string, helpful bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	outcome, ok := m.outcomes[responseID]
+	if !ok || outcome.UserID != userID {
+		return ErrNotFound
+	}
+	outcome.Helpful = &helpful
+	m.outcomes[responseID] = outcome
+	return nil
+}
+
+// Summaries aggregates the outcomes of every variant with any
+func (m *memoryStore) Summaries() ([]Summary, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	byVariant := make(map[[2]string]*Summary)
+	for _, outcome := range m.outcomes {
+		key := [2]string{outcome.Experiment, outcome.Variant}
+		summary, ok := byVariant[key]
+		if !ok {
+			summary = &Summary{Experiment: outcome.Experiment, Variant: outcome.Variant}
+			byVariant[key] = summary
+		}
+		summary.Requests++
+		summary.TotalLatencyMs += outcome.LatencyMs
+		if outcome.Failed {
+			summary.Failures++
+		} else {
+			summary.TotalLength += int64(outcome.Length)
+		}
+		if outcome.Helpful != nil && *outcome.Helpful {
+			summary.Helpful++
+		} else if outcome.Helpful != nil {
+			summary.Unhelpful++
+		}
+	}
+
+	summaries := make([]Summary, 0, len(byVariant))
+	for _, summary := range byVariant {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Experiment != summaries[j].Experiment {
+			return summaries[i].Experiment < summaries[j].Experiment
+		}
+		return summaries[i].Variant < summaries[j].Variant
+	})
+	return summaries, nil
+}
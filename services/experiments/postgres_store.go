@@ -0,0 +1,93 @@
+package experiments
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"fmt"
+)
+
+// Schema creates the prompt_experiment_outcomes table
+const Schema = `
+        CREATE TABLE IF NOT EXISTS prompt_experiment_outcomes (
+            response_id VARCHAR(64) PRIMARY KEY,
+            experiment VARCHAR(64) NOT NULL,
+            variant VARCHAR(64) NOT NULL,
+            user_id VARCHAR(255) NOT NULL,
+            latency_ms BIGINT NOT NULL,
+            length INT NOT NULL,
+            failed BOOLEAN NOT NULL,
+            helpful BOOLEAN,
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_prompt_experiment_outcomes_variant ON prompt_experiment_outcomes(experiment, variant);
+    `
+
+// postgresStore keeps outcomes in the prompt_experiment_outcomes table, so
+// results cover every instance and survive restarts
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a Store backed by the table in Schema
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+// Save records an outcome
+func (p *postgresStore) Save(outcome models.ExperimentOutcome) error {
+	_, err := p.db.Exec(`
+        INSERT INTO prompt_experiment_outcomes (response_id, experiment, variant, user_id, latency_ms, length, failed, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    `, outcome.ResponseID, outcome.Experiment, outcome.Variant, outcome.UserID, outcome.LatencyMs, outcome.Length, outcome.Failed, outcome.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save experiment outcome: %w", err)
+	}
+	return nil
+}
+
+// SetFeedback records a user's feedback on one of their responses
+func (p *postgresStore) SetFeedback(userID, responseID string, helpful bool) error {
+	result, err := p.db.Exec(`
+        UPDATE prompt_experiment_outcomes SET helpful = $3
+        WHERE response_id = $1 AND user_id = $2
+    `, responseID, userID, helpful)
+	if err != nil {
+		return fmt.Errorf("failed to save experiment feedback: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Summaries aggregates the outcomes of every variant with any
+func (p *postgresStore) Summaries() ([]Summary, error) {
+	rows, err := p.db.Query(`
+        SELECT experiment, variant, COUNT(*),
+               COUNT(*) FILTER (WHERE failed),
+               COALESCE(SUM(latency_ms), 0),
+               COALESCE(SUM(length) FILTER (WHERE NOT failed), 0),
+               COUNT(*) FILTER (WHERE helpful),
+               COUNT(*) FILTER (WHERE NOT helpful)
+        FROM prompt_experiment_outcomes
+        GROUP BY experiment, variant
+        ORDER BY experiment, variant
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize experiment outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []Summary
+	for rows.Next() {
+		var summary Summary
+		err := rows.Scan(&summary.Experiment, &summary.Variant, &summary.Requests, &summary.Failures,
+			&summary.TotalLatencyMs, &summary.TotalLength, &summary.Helpful, &summary.Unhelpful)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan experiment summary: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
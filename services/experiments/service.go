@@ -0,0 +1,175 @@
+package experiments
+
+import (
+	"backend/server/models"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+)
+
+// Templates reports which prompt templates are loaded
+type Templates interface {
+	Has(name string) bool
+}
+
+// service implements the experiments Service interface
+type service struct {
+	store       Store
+	experiments map[string][]Variant // By template name
+}
+
+// New creates a service running experiments, by template name. Every
+// non-control variant needs a "<template>.<variant>" template in templates.
+func New(store Store, experiments map[string][]Variant, templates Templates) (Service, error) {
+	for template, variants := range experiments {
+		if len(variants) < 2 {
+			return nil, fmt.Errorf("%w: %s needs at least two variants", ErrInvalidExperiment, template)
+		}
+		for _, variant := range variants {
+			if variant.Weight < 1 {
+				return nil, fmt.Errorf("%w: %s variant %s needs a positive weight", ErrInvalidExperiment, template, variant.Name)
+			}
+			if !templates.Has(templateFor(template, variant.Name)) {
+				return nil, fmt.Errorf("%w: %s variant %s has no %s.tmpl template", ErrInvalidExperiment, template, variant.Name, templateFor(template, variant.Name))
+			}
+		}
+	}
+	return &service{
+		store:       store,
+		experiments: experiments,
+	}, nil
+}
+
+// Assign picks a variant by hashing the user ID, so assignments are sticky
+// without being stored
+func (s *service) Assign(template, userID string) (Assignment, bool) {
+	variants, ok := s.experiments[template]
+	if !ok {
+		return Assignment{}, false
+	}
+
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(template + ":" + userID))
+	bucket := int(hash.Sum32() % uint32(total))
+
+	for _, variant := range variants {
+		if bucket < variant.Weight {
+			return Assignment{
+				Experiment: template,
+				Variant:    variant.Name,
+				Template:   templateFor(template, variant.Name),
+			}, true
+		}
+		bucket -= variant.Weight
+	}
+	return Assignment{}, false
+}
+
+// Record saves the outcome of a request
+func (s *service) Record(assignment Assignment, userID string, latencyMs int64, answer string, failed bool) (string, error) {
+	responseID, err := newResponseID()
+	if err != nil {
+		return "", err
+	}
+	length := 0
+	if !failed {
+		length = len([]rune(answer))
+	}
+
+	err = s.store.Save(models.ExperimentOutcome{
+		ResponseID: responseID,
+		Experiment: assignment.Experiment,
+		Variant:    assignment.Variant,
+		UserID:     userID,
+		LatencyMs:  latencyMs,
+		Length:     length,
+		Failed:     failed,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return responseID, nil
+}
+
+// Feedback records whether a user found a response helpful
+func (s *service) Feedback(userID, responseID string, helpful bool) error {
+	return s.store.SetFeedback(userID, responseID, helpful)
+}
+
+// Results compares the variants of every experiment, including variants
+// without outcomes yet
+func (s *service) Results() ([]models.ExperimentResult, error) {
+	summaries, err := s.store.Summaries()
+	if err != nil {
+		return nil, err
+	}
+	byVariant := make(map[string]Summary, len(summaries))
+	for _, summary := range summaries {
+		byVariant[summary.Experiment+"."+summary.Variant] = summary
+	}
+
+	templates := make([]string, 0, len(s.experiments))
+	for template := range s.experiments {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+
+	results := make([]models.ExperimentResult, 0, len(templates))
+	for _, template := range templates {
+		result := models.ExperimentResult{Experiment: template}
+		for _, variant := range s.experiments[template] {
+			result.Variants = append(result.Variants, variantResult(template, variant, byVariant[template+"."+variant.Name]))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// variantResult turns a variant's summary into averages and rates
+func variantResult(template string, variant Variant, summary Summary) models.ExperimentVariantResult {
+	result := models.ExperimentVariantResult{
+		Variant:   variant.Name,
+		Template:  templateFor(template, variant.Name),
+		Weight:    variant.Weight,
+		Requests:  summary.Requests,
+		Failures:  summary.Failures,
+		Helpful:   summary.Helpful,
+		Unhelpful: summary.Unhelpful,
+	}
+	if summary.Requests > 0 {
+		result.AvgLatencyMs = float64(summary.TotalLatencyMs) / float64(summary.Requests)
+	}
+	if succeeded := summary.Requests - summary.Failures; succeeded > 0 {
+		result.AvgLength = float64(summary.TotalLength) / float64(succeeded)
+	}
+	if rated := summary.Helpful + summary.Unhelpful; rated > 0 {
+		rate := float64(summary.Helpful) / float64(rated)
+		result.HelpfulRate = &rate
+	}
+	return result
+}
+
+// templateFor returns the prompt template a variant renders
+func templateFor(template, variant string) string {
+	if variant == Control {
+		return template
+	}
+	return template + "." + variant
+}
+
+// newResponseID returns a random response ID
+func newResponseID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate response ID: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
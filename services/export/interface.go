@@ -0,0 +1,44 @@
+package export
+
+import (
+	"backend/server/models"
+	"backend/services/mood"
+	"context"
+	"errors"
+	"os"
+)
+
+// ErrNotFound is returned for bundles that don't exist or have expired
+var ErrNotFound = errors.New("export not found")
+
+// MoodSource provides users' mood history. The mood service satisfies it.
+type MoodSource interface {
+	GetUserMoodHistory(userID string) ([]mood.UserMoodEntry, error)
+}
+
+// PlaySource provides the play history. The music repository satisfies it.
+type PlaySource interface {
+	QueryPlayHistory(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error)
+}
+
+// ProfileSource provides users' profiles. The users service satisfies it.
+type ProfileSource interface {
+	Get(id string) (models.User, error)
+}
+
+// Service defines the interface for personal data exports. Each export is a
+// zip of JSON files written to disk under the hash of a random token (see
+// NewToken and BundleID); the token is all it takes to download it until it
+// expires.
+type Service interface {
+	// Export bundles the user's data as the bundle named bundleID and returns
+	// the bundle's details
+	Export(ctx context.Context, userID, bundleID string) (models.DataExport, error)
+
+	// Open returns the bundle named by token, or ErrNotFound if there is none
+	// or it expired. The caller closes the file.
+	Open(token string) (*os.File, error)
+
+	// Purge deletes expired bundles and returns how many it deleted
+	Purge() (int, error)
+}
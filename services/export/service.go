@@ -0,0 +1,339 @@
+package export
+
+import (
+	"archive/zip"
+	"backend/server/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// playPageSize is how many play history items are read at a time
+const playPageSize = 500
+
+// Config holds the export configuration
+type Config struct {
+	Dir      string        // Directory of bundles
+	TTL      time.Duration // How long a bundle can be downloaded
+	MaxPlays int           // Most play history items in a bundle, newest first
+}
+
+// DefaultConfig returns a default configuration for exports
+func DefaultConfig() Config {
+	return Config{
+		Dir:      "./data/exports",
+		TTL:      24 * time.Hour,
+		MaxPlays: 10000,
+	}
+}
+
+// recommendation is a mood history entry's library recommendations, which is
+// how recommendations are kept
+type recommendation struct {
+	Timestamp string   `json:"timestamp"`
+	Mood      string   `json:"mood"`
+	TrackIDs  []string `json:"track_ids"`
+}
+
+// bundleFile is one JSON file of a bundle
+type bundleFile struct {
+	name string
+	data interface{}
+}
+
+// service implements the export Service interface
+type service struct {
+	db       *sql.DB       // May be nil, in which case there are no messages
+	profiles ProfileSource // May be nil, in which case there is no profile
+	moods    MoodSource
+	plays    PlaySource // May be nil, in which case there is no play history
+	config   Config
+}
+
+// New creates a new export service
+func New(db *sql.DB, profiles ProfileSource, moods MoodSource, plays PlaySource, config Config) Service {
+	return &service{
+		db:       db,
+		profiles: profiles,
+		moods:    moods,
+		plays:    plays,
+		config:   config,
+	}
+}
+
+// Export bundles the user's profile, messages, mood history, recommendations
+// and the play history
+func (s *service) Export(ctx context.Context, userID, bundleID string) (models.DataExport, error) {
+	if userID == "" {
+		return models.DataExport{}, errors.New("user ID is required")
+	}
+	if !validBundleID(bundleID) {
+		return models.DataExport{}, errors.New("invalid bundle ID")
+	}
+
+	var profile models.User
+	if s.profiles != nil {
+		var err error
+		if profile, err = s.profiles.Get(userID); err != nil {
+			return models.DataExport{}, fmt.Errorf("failed to load the profile: %w", err)
+		}
+	}
+	messages, err := s.messages(ctx, userID, profile.Email)
+	if err != nil {
+		return models.DataExport{}, err
+	}
+	moodHistory, err := s.moods.GetUserMoodHistory(userID)
+	if err != nil {
+		return models.DataExport{}, fmt.Errorf("failed to load mood history: %w", err)
+	}
+	recommendations := []recommendation{}
+	for _, entry := range moodHistory {
+		if len(entry.PlayedSongs) > 0 {
+			recommendations = append(recommendations, recommendation{Timestamp: entry.Timestamp, Mood: entry.DetectedMood, TrackIDs: entry.PlayedSongs})
+		}
+	}
+	plays, err := s.playHistory(ctx)
+	if err != nil {
+		return models.DataExport{}, err
+	}
+
+	files := []bundleFile{
+		{"profile.json", profile},
+		{"messages.json", messages},
+		{"mood_history.json", moodHistory},
+		{"recommendations.json", recommendations},
+		{"play_history.json", plays},
+	}
+	return s.write(bundleID, files)
+}
+
+// messages returns the messages the user posted, under their ID or, for
+// messages from before profiles, their email, oldest first
+func (s *service) messages(ctx context.Context, userID, email string) ([]models.Message, error) {
+	messages := []models.Message{}
+	if s.db == nil {
+		return messages, nil
+	}
+	email = strings.ToLower(email)
+	if email == "" && strings.Contains(userID, "@") {
+		email = strings.ToLower(userID)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT id, COALESCE(user_id, ''), user_email, username, message_text, message_type, payload, parent_message_id, created_at
+        FROM global_messages
+        WHERE user_id = $1 OR ($2 <> '' AND LOWER(user_email) = $2)
+        ORDER BY id
+    `, userID, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var msg models.Message
+		var payload []byte
+		var parentID sql.NullInt64
+		if err := rows.Scan(&msg.ID, &msg.UserID, &msg.UserEmail, &msg.Username, &msg.Text, &msg.MessageType, &payload, &parentID, &msg.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		msg.Payload = payload
+		if parentID.Valid {
+			msg.ParentMessageID = &parentID.Int64
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+	return messages, nil
+}
+
+// playHistory returns up to MaxPlays items of the play history, which is
+// shared by the whole deployment
+func (s *service) playHistory(ctx context.Context) ([]models.PlayHistoryItem, error) {
+	plays := []models.PlayHistoryItem{}
+	if s.plays == nil {
+		return plays, nil
+	}
+	for len(plays) < s.config.MaxPlays {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		limit := playPageSize
+		if remaining := s.config.MaxPlays - len(plays); remaining < limit {
+			limit = remaining
+		}
+		page, _, err := s.plays.QueryPlayHistory(models.PlayHistoryFilter{}, len(plays), limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load play history: %w", err)
+		}
+		plays = append(plays, page...)
+		if len(page) < limit {
+			break
+		}
+	}
+	return plays, nil
+}
+
+// write zips files into the bundle named bundleID. The zip is written to a
+// temporary file and renamed, so a bundle that can be opened is complete.
+func (s *service) write(bundleID string, files []bundleFile) (models.DataExport, error) {
+	if err := os.MkdirAll(s.config.Dir, 0700); err != nil {
+		return models.DataExport{}, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	temp, err := os.CreateTemp(s.config.Dir, "export-*.tmp")
+	if err != nil {
+		return models.DataExport{}, fmt.Errorf("failed to create export: %w", err)
+	}
+	defer os.Remove(temp.Name()) // Fails harmlessly once renamed
+
+	archive := zip.NewWriter(temp)
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			temp.Close()
+			return models.DataExport{}, fmt.Errorf("failed to add %s: %w", file.name, err)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			temp.Close()
+			return models.DataExport{}, fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+		names = append(names, file.name)
+	}
+	if err := archive.Close(); err != nil {
+		temp.Close()
+		return models.DataExport{}, fmt.Errorf("failed to finish export: %w", err)
+	}
+	info, err := temp.Stat()
+	if err != nil {
+		temp.Close()
+		return models.DataExport{}, fmt.Errorf("failed to finish export: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return models.DataExport{}, fmt.Errorf("failed to finish export: %w", err)
+	}
+	if err := os.Rename(temp.Name(), s.bundlePath(bundleID)); err != nil {
+		return models.DataExport{}, fmt.Errorf("failed to store export: %w", err)
+	}
+
+	now := time.Now()
+	return models.DataExport{
+		Files:     names,
+		Bytes:     info.Size(),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.config.TTL),
+	}, nil
+}
+
+// Open returns an unexpired bundle
+func (s *service) Open(token string) (*os.File, error) {
+	if !validToken(token) {
+		return nil, ErrNotFound
+	}
+	file, err := os.Open(s.bundlePath(BundleID(token)))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	if s.expired(info) {
+		file.Close()
+		return nil, ErrNotFound
+	}
+	return file, nil
+}
+
+// Purge deletes expired bundles, and temporary files left by failed exports
+func (s *service) Purge() (int, error) {
+	entries, err := os.ReadDir(s.config.Dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to list exports: %w", err)
+	}
+
+	deleted := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".zip") && !strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !s.expired(info) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.config.Dir, name)); err != nil && !os.IsNotExist(err) {
+			return deleted, fmt.Errorf("failed to delete export: %w", err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// expired reports whether a bundle written at info's modification time has
+// outlived the TTL
+func (s *service) expired(info os.FileInfo) bool {
+	return time.Since(info.ModTime()) > s.config.TTL
+}
+
+// bundlePath returns the file of the bundle named bundleID
+func (s *service) bundlePath(bundleID string) string {
+	return filepath.Join(s.config.Dir, bundleID+".zip")
+}
+
+// NewToken returns a download token: 128 random bits, hex encoded
+func NewToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate export token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// BundleID returns the name of the bundle downloaded with token: its SHA-256
+// hash, hex encoded. Bundle IDs can be stored and shown, e.g. in job
+// payloads, without giving away the token.
+func BundleID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// validToken reports whether token could have been made by NewToken
+func validToken(token string) bool {
+	return validHex(token, 32)
+}
+
+// validBundleID reports whether id could have been made by BundleID
+func validBundleID(id string) bool {
+	return validHex(id, 64)
+}
+
+// validHex reports whether s is n lowercase hex digits
+func validHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil && strings.ToLower(s) == s
+}
@@ -0,0 +1,21 @@
+package facts
+
+import "backend/server/models"
+
+// Genius fetches song metadata
+type Genius interface {
+	GetSong(trackName, artistName string) (*models.GeniusSong, error)
+}
+
+// AIService writes the "did you know" blurb
+type AIService interface {
+	GenerateResponse(prompt string) (string, error)
+}
+
+// Service collects facts about songs
+type Service interface {
+	// Get returns the facts about a song, cached per song. If the AI can't
+	// write the blurb the facts are returned without it, and not cached, so
+	// the next request tries again.
+	Get(trackName, artistName string) (*models.SongFacts, error)
+}
@@ -0,0 +1,152 @@
+package facts
+
+import (
+	"backend/server/models"
+	"backend/services/prompts"
+	"container/list"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDescription is how much of a song's Genius description goes in the prompt
+const maxDescription = 2000
+
+// Config holds song facts configuration
+type Config struct {
+	CacheTTL   time.Duration // How long a song's facts are served before being collected again
+	MaxEntries int           // Songs with cached facts; least recently used ones are evicted beyond this, 0 disables the cache
+}
+
+// DefaultConfig returns a default configuration for song facts
+func DefaultConfig() Config {
+	return Config{
+		CacheTTL:   7 * 24 * time.Hour,
+		MaxEntries: 500,
+	}
+}
+
+// entry is a song's cached facts
+type entry struct {
+	key       string
+	facts     models.SongFacts
+	expiresAt time.Time
+}
+
+// service implements the facts Service interface
+type service struct {
+	genius    Genius
+	ai        AIService
+	templates prompts.Service
+	config    Config
+	entries   map[string]*list.Element
+	order     *list.List // Front is most recently used
+	mutex     sync.Mutex
+}
+
+// New creates a new song facts service. A nil templates uses the built-in prompts.
+func New(genius Genius, ai AIService, templates prompts.Service, config Config) Service {
+	if templates == nil {
+		templates = prompts.Default()
+	}
+	return &service{
+		genius:    genius,
+		ai:        ai,
+		templates: templates,
+		config:    config,
+		entries:   make(map[string]*list.Element),
+		order:     list.New(),
+	}
+}
+
+// Get returns the facts about a song
+func (s *service) Get(trackName, artistName string) (*models.SongFacts, error) {
+	key := strings.ToLower(trackName + "\x00" + artistName)
+	if facts, ok := s.cached(key); ok {
+		return &facts, nil
+	}
+
+	song, err := s.genius.GetSong(trackName, artistName)
+	if err != nil {
+		return nil, err
+	}
+	facts := models.SongFacts{
+		TrackName:   trackName,
+		Artist:      artistName,
+		Album:       song.Album,
+		ReleaseDate: song.ReleaseDate,
+		Producers:   song.Producers,
+		Writers:     song.Writers,
+		Samples:     song.Samples,
+		GeniusURL:   song.URL,
+	}
+
+	blurb, err := s.blurb(trackName, artistName, song)
+	if err != nil {
+		log.Printf("Failed to write facts blurb for %s by %s: %v", trackName, artistName, err)
+		return &facts, nil
+	}
+	facts.DidYouKnow = blurb
+	s.put(key, facts)
+	return &facts, nil
+}
+
+// blurb asks the AI for the "did you know" blurb, grounded in the Genius metadata
+func (s *service) blurb(trackName, artistName string, song *models.GeniusSong) (string, error) {
+	description := song.Description
+	if runes := []rune(description); len(runes) > maxDescription {
+		description = string(runes[:maxDescription]) + "..."
+	}
+	prompt, err := s.templates.Render(prompts.SongFacts, prompts.SongFactsData{
+		SongInfo:    fmt.Sprintf("%s by %s", trackName, artistName),
+		ReleaseDate: song.ReleaseDate,
+		Producers:   strings.Join(song.Producers, ", "),
+		Samples:     strings.Join(song.Samples, ", "),
+		Description: description,
+	})
+	if err != nil {
+		return "", err
+	}
+	blurb, err := s.ai.GenerateResponse(prompt)
+	return strings.TrimSpace(blurb), err
+}
+
+// cached returns a song's non-expired facts and marks them recently used
+func (s *service) cached(key string) (models.SongFacts, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return models.SongFacts{}, false
+	}
+	cached := element.Value.(*entry)
+	if time.Now().After(cached.expiresAt) {
+		s.order.Remove(element)
+		delete(s.entries, key)
+		return models.SongFacts{}, false
+	}
+	s.order.MoveToFront(element)
+	return cached.facts, true
+}
+
+// put caches a song's facts, evicting the least recently used songs when full
+func (s *service) put(key string, facts models.SongFacts) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.config.MaxEntries <= 0 {
+		return
+	}
+	if element, ok := s.entries[key]; ok {
+		s.order.Remove(element)
+	}
+	s.entries[key] = s.order.PushFront(&entry{key: key, facts: facts, expiresAt: time.Now().Add(s.config.CacheTTL)})
+	for s.order.Len() > s.config.MaxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*entry).key)
+	}
+}
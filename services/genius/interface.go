@@ -1,6 +1,23 @@
 package genius
 
+import (
+	"backend/server/models"
+	"errors"
+)
+
+var (
+	// ErrArtistNotFound is returned when Genius knows no artist by the name
+	ErrArtistNotFound = errors.New("genius artist not found")
+	// ErrSongNotFound is returned when a search finds no song
+	ErrSongNotFound = errors.New("genius song not found")
+)
+
 // Service defines the interface for Genius/lyrics operations
 type Service interface {
 	GetLyrics(trackName, artistName string) (string, error)
-}
\ No newline at end of file
+	// GetArtist returns the artist's Genius page, with their bio
+	GetArtist(name string) (*models.GeniusArtist, error)
+	// GetSong returns the song's Genius metadata: release date, credits,
+	// samples and description
+	GetSong(trackName, artistName string) (*models.GeniusSong, error)
+}
@@ -1,6 +1,7 @@
 package genius
 
 import (
+	"backend/server/models"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -36,13 +37,13 @@ func New(config Config) Service {
 // GetLyrics fetches lyrics for a given track and artist
 func (s *service) GetLyrics(trackName, artistName string) (string, error) {
 	// Search for the song on Genius
-	songURL, err := s.searchSong(trackName, artistName)
+	song, err := s.searchSong(trackName, artistName)
 	if err != nil {
 		return "", fmt.Errorf("failed to search song: %w", err)
 	}
 
 	// Scrape lyrics from the song page
-	lyrics, err := s.scrapeLyrics(songURL)
+	lyrics, err := s.scrapeLyrics(song.URL)
 	if err != nil {
 		return "", fmt.Errorf("failed to scrape lyrics: %w", err)
 	}
@@ -50,8 +51,14 @@ func (s *service) GetLyrics(trackName, artistName string) (string, error) {
 	return lyrics, nil
 }
 
-// searchSong searches for a song on Genius and returns the URL
-func (s *service) searchSong(trackName, artistName string) (string, error) {
+// songHit is a song found by searchSong
+type songHit struct {
+	ID  int64
+	URL string
+}
+
+// searchSong searches for a song on Genius and returns its ID and URL
+func (s *service) searchSong(trackName, artistName string) (songHit, error) {
 	// Build query
 	query := url.Values{}
 	query.Add("q", fmt.Sprintf("%s %s", trackName, artistName))
@@ -59,7 +66,7 @@ func (s *service) searchSong(trackName, artistName string) (string, error) {
 	// Create request
 	req, err := http.NewRequest("GET", "https://api.genius.com/search?"+query.Encode(), nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return songHit{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -68,30 +75,30 @@ func (s *service) searchSong(trackName, artistName string) (string, error) {
 	// Send request
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return songHit{}, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("genius API failed with status %d: %s", resp.StatusCode, string(body))
+		return songHit{}, fmt.Errorf("genius API failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
 	// Parse response
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return songHit{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	// Navigate through the JSON to get the first hit's URL
 	response, ok := result["response"].(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("invalid response format")
+		return songHit{}, fmt.Errorf("invalid response format")
 	}
 
 	hits, ok := response["hits"].([]interface{})
 	if !ok || len(hits) == 0 {
-		return "", fmt.Errorf("no results found for %s by %s", trackName, artistName)
+		return songHit{}, fmt.Errorf("%w: no results for %s by %s", ErrSongNotFound, trackName, artistName)
 	}
 
 	// Check first few results for best match
@@ -122,7 +129,7 @@ func (s *service) searchSong(trackName, artistName string) (string, error) {
 		
 		if strings.Contains(strings.ToLower(artistNameFromResult), strings.ToLower(artistName)) ||
 		   strings.Contains(strings.ToLower(artistName), strings.ToLower(artistNameFromResult)) {
-			return songURL, nil
+			return newSongHit(resultObj, songURL), nil
 		}
 	}
 
@@ -131,7 +138,13 @@ func (s *service) searchSong(trackName, artistName string) (string, error) {
 	resultObj := hit["result"].(map[string]interface{})
 	songURL := resultObj["url"].(string)
 	
-	return songURL, nil
+	return newSongHit(resultObj, songURL), nil
+}
+
+// newSongHit reads a search result's song ID; JSON numbers decode as float64
+func newSongHit(resultObj map[string]interface{}, songURL string) songHit {
+	id, _ := resultObj["id"].(float64)
+	return songHit{ID: int64(id), URL: songURL}
 }
 
 // scrapeLyrics scrapes lyrics from a Genius webpage
@@ -180,4 +193,164 @@ func (s *service) scrapeLyrics(geniusURL string) (string, error) {
 	}
 
 	return strings.TrimSpace(lyrics.String()), nil
-}
\ No newline at end of file
+}
+// GetArtist finds an artist through the songs search, preferring a hit whose
+// primary artist has exactly the name, and fetches their page
+func (s *service) GetArtist(name string) (*models.GeniusArtist, error) {
+	var search struct {
+		Response struct {
+			Hits []struct {
+				Result struct {
+					PrimaryArtist geniusArtistObject `json:"primary_artist"`
+				} `json:"result"`
+			} `json:"hits"`
+		} `json:"response"`
+	}
+	query := url.Values{}
+	query.Set("q", name)
+	if err := s.getJSON("https://api.genius.com/search?"+query.Encode(), &search); err != nil {
+		return nil, fmt.Errorf("failed to search artist: %w", err)
+	}
+
+	var artistID int64
+	for _, hit := range search.Response.Hits {
+		artist := hit.Result.PrimaryArtist
+		if strings.EqualFold(artist.Name, strings.TrimSpace(name)) {
+			artistID = artist.ID
+			break
+		}
+		if artistID == 0 && strings.Contains(strings.ToLower(artist.Name), strings.ToLower(strings.TrimSpace(name))) {
+			artistID = artist.ID
+		}
+	}
+	if artistID == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrArtistNotFound, name)
+	}
+
+	var page struct {
+		Response struct {
+			Artist geniusArtistObject `json:"artist"`
+		} `json:"response"`
+	}
+	if err := s.getJSON(fmt.Sprintf("https://api.genius.com/artists/%d?text_format=plain", artistID), &page); err != nil {
+		return nil, fmt.Errorf("failed to fetch artist: %w", err)
+	}
+
+	artist := page.Response.Artist
+	return &models.GeniusArtist{
+		ID:       artist.ID,
+		Name:     artist.Name,
+		URL:      artist.URL,
+		ImageURL: artist.ImageURL,
+		Bio:      strings.TrimSpace(artist.Description.Plain),
+	}, nil
+}
+
+// geniusArtistObject is an artist as the Genius API returns it
+type geniusArtistObject struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	ImageURL    string `json:"image_url"`
+	Description struct {
+		Plain string `json:"plain"`
+	} `json:"description"`
+}
+
+// getJSON sends an authorized GET request to the Genius API and decodes the response into v
+func (s *service) getJSON(urlStr string, v interface{}) error {
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.AccessToken))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("genius API failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// GetSong finds a song like GetLyrics does and fetches its metadata
+func (s *service) GetSong(trackName, artistName string) (*models.GeniusSong, error) {
+	hit, err := s.searchSong(trackName, artistName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search song: %w", err)
+	}
+	if hit.ID == 0 {
+		return nil, fmt.Errorf("%w: search result for %s by %s has no ID", ErrSongNotFound, trackName, artistName)
+	}
+
+	var page struct {
+		Response struct {
+			Song struct {
+				ID                    int64  `json:"id"`
+				Title                 string `json:"title"`
+				URL                   string `json:"url"`
+				ReleaseDateForDisplay string `json:"release_date_for_display"`
+				PrimaryArtist         struct {
+					Name string `json:"name"`
+				} `json:"primary_artist"`
+				Album *struct {
+					Name string `json:"name"`
+				} `json:"album"`
+				ProducerArtists []geniusArtistObject `json:"producer_artists"`
+				WriterArtists   []geniusArtistObject `json:"writer_artists"`
+				Relationships   []struct {
+					Type  string `json:"relationship_type"`
+					Songs []struct {
+						FullTitle string `json:"full_title"`
+					} `json:"songs"`
+				} `json:"song_relationships"`
+				Description struct {
+					Plain string `json:"plain"`
+				} `json:"description"`
+			} `json:"song"`
+		} `json:"response"`
+	}
+	if err := s.getJSON(fmt.Sprintf("https://api.genius.com/songs/%d?text_format=plain", hit.ID), &page); err != nil {
+		return nil, fmt.Errorf("failed to fetch song: %w", err)
+	}
+
+	song := page.Response.Song
+	result := &models.GeniusSong{
+		ID:          song.ID,
+		Title:       song.Title,
+		Artist:      song.PrimaryArtist.Name,
+		URL:         song.URL,
+		ReleaseDate: song.ReleaseDateForDisplay,
+		Description: strings.TrimSpace(song.Description.Plain),
+	}
+	if song.Album != nil {
+		result.Album = song.Album.Name
+	}
+	for _, producer := range song.ProducerArtists {
+		result.Producers = append(result.Producers, producer.Name)
+	}
+	for _, writer := range song.WriterArtists {
+		result.Writers = append(result.Writers, writer.Name)
+	}
+	for _, relationship := range song.Relationships {
+		if relationship.Type != "samples" {
+			continue
+		}
+		for _, sampled := range relationship.Songs {
+			result.Samples = append(result.Samples, sampled.FullTitle)
+		}
+	}
+	// Genius describes songs without a description as "?"
+	if result.Description == "?" {
+		result.Description = ""
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package jobqueue
+
+import (
+	"backend/server/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Errors returned by Service
+var (
+	ErrNotFound    = errors.New("job not found")
+	ErrUnknownType = errors.New("unknown job type")
+	ErrQueueFull   = errors.New("too many unfinished jobs")
+)
+
+// Handler runs one attempt of a job. Its result is stored as JSON; an error
+// fails the attempt, which is retried until the job runs out of attempts.
+type Handler func(ctx context.Context, payload json.RawMessage) (interface{}, error)
+
+// Store persists queued jobs so unfinished ones survive restarts
+type Store interface {
+	// Create saves a new job, returning it with its ID and creation time
+	Create(job models.QueuedJob) (models.QueuedJob, error)
+
+	// Update saves a job's status, attempts, result and error
+	Update(job models.QueuedJob) error
+
+	// Get returns a job, or ErrNotFound
+	Get(id int64) (models.QueuedJob, error)
+
+	// List returns up to limit of a user's jobs, newest first, optionally only
+	// those with the given status
+	List(userID, status string, limit int) ([]models.QueuedJob, error)
+
+	// Unfinished returns every queued or running job, oldest first
+	Unfinished() ([]models.QueuedJob, error)
+
+	// DeleteFinished removes jobs that finished before cutoff and returns how many
+	DeleteFinished(cutoff time.Time) (int, error)
+}
+
+// Service runs slow work on a pool of workers, retrying failed attempts
+// with exponential backoff
+type Service interface {
+	// Handle registers the handler for a job type. Handlers must be registered
+	// before Start.
+	Handle(jobType string, handler Handler)
+
+	// Enqueue saves a job for a user and queues it for a worker. The payload
+	// is stored as JSON.
+	Enqueue(userID, jobType string, payload interface{}) (models.QueuedJob, error)
+
+	// Get returns a job, or ErrNotFound
+	Get(id int64) (models.QueuedJob, error)
+
+	// List returns up to limit of a user's jobs, newest first, optionally only
+	// those with the given status
+	List(userID, status string, limit int) ([]models.QueuedJob, error)
+
+	// Start resumes unfinished jobs from the store and starts the workers
+	// until ctx is cancelled
+	Start(ctx context.Context) error
+
+	// Purge removes jobs that finished more than the retention period ago
+	Purge() (int, error)
+}
@@ -0,0 +1,103 @@
+package jobqueue
+
+import (
+	"backend/server/models"
+	"sync"
+	"time"
+)
+
+// memoryStore keeps jobs in memory, for development without a database and tests
+type memoryStore struct {
+	jobs   []models.QueuedJob // Oldest first
+	nextID int64
+	mutex  sync.RWMutex
+}
+
+// NewMemoryStore creates an in-memory Store
+func NewMemoryStore() Store {
+	return &memoryStore{}
+}
+
+// Create saves a new job, returning it with its ID and creation time
+func (m *memoryStore) Create(job models.QueuedJob) (models.QueuedJob, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.nextID++
+	job.ID = m.nextID
+	job.CreatedAt = time.Now()
+	m.jobs = append(m.jobs, job)
+	return job, nil
+}
+
+// Update saves a job's status, attempts, result and error
+func (m *memoryStore) Update(job models.QueuedJob) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i := range m.jobs {
+		if m.jobs[i].ID == job.ID {
+			m.jobs[i] = job
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// Get returns a job, or ErrNotFound
+func (m *memoryStore) Get(id int64) (models.QueuedJob, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, job := range m.jobs {
+		if job.ID == id {
+			return job, nil
+		}
+	}
+	return models.QueuedJob{}, ErrNotFound
+}
+
+// List returns up to limit of a user's jobs, newest first
+func (m *memoryStore) List(userID, status string, limit int) ([]models.QueuedJob, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	jobs := []models.QueuedJob{}
+	for i := len(m.jobs) - 1; i >= 0 && len(jobs) < limit; i-- {
+		job := m.jobs[i]
+		if job.UserID == userID && (status == "" || job.Status == status) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// Unfinished returns every queued or running job, oldest first
+func (m *memoryStore) Unfinished() ([]models.QueuedJob, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	jobs := []models.QueuedJob{}
+	for _, job := range m.jobs {
+		if !job.Finished() {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// DeleteFinished removes jobs that finished before cutoff
+func (m *memoryStore) DeleteFinished(cutoff time.Time) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	kept := m.jobs[:0]
+	for _, job := range m.jobs {
+		if job.FinishedAt == nil || !job.FinishedAt.Before(cutoff) {
+			kept = append(kept, job)
+		}
+	}
+	deleted := len(m.jobs) - len(kept)
+	m.jobs = kept
+	return deleted, nil
+}
@@ -0,0 +1,147 @@
+package jobqueue
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Schema creates the queued_jobs table
+const Schema = `
+        CREATE TABLE IF NOT EXISTS queued_jobs (
+            id BIGSERIAL PRIMARY KEY,
+            type VARCHAR(64) NOT NULL,
+            user_id VARCHAR(255) NOT NULL,
+            status VARCHAR(16) NOT NULL,
+            attempts INT NOT NULL DEFAULT 0,
+            max_attempts INT NOT NULL,
+            payload JSONB NOT NULL,
+            result JSONB,
+            error TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+            updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+            run_after TIMESTAMP WITH TIME ZONE NOT NULL,
+            finished_at TIMESTAMP WITH TIME ZONE
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_queued_jobs_user_id ON queued_jobs(user_id, id DESC);
+        CREATE INDEX IF NOT EXISTS idx_queued_jobs_unfinished ON queued_jobs(id) WHERE status IN ('queued', 'running');
+    `
+
+// jobColumns are selected by scanJob, in order
+const jobColumns = `id, type, user_id, status, attempts, max_attempts, payload, result, error, created_at, updated_at, run_after, finished_at`
+
+// postgresStore keeps jobs in the queued_jobs table
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a Store backed by the table in Schema
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+// Create saves a new job, returning it with its ID and creation time
+func (p *postgresStore) Create(job models.QueuedJob) (models.QueuedJob, error) {
+	err := p.db.QueryRow(`
+        INSERT INTO queued_jobs (type, user_id, status, max_attempts, payload, updated_at, run_after)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, created_at
+    `, job.Type, job.UserID, job.Status, job.MaxAttempts, []byte(job.Payload), job.UpdatedAt, job.RunAfter).Scan(&job.ID, &job.CreatedAt)
+	if err != nil {
+		return models.QueuedJob{}, fmt.Errorf("failed to insert job: %w", err)
+	}
+	return job, nil
+}
+
+// Update saves a job's status, attempts, result and error
+func (p *postgresStore) Update(job models.QueuedJob) error {
+	var result []byte
+	if len(job.Result) > 0 {
+		result = job.Result
+	}
+	_, err := p.db.Exec(`
+        UPDATE queued_jobs
+        SET status = $2, attempts = $3, result = $4, error = $5, updated_at = $6, run_after = $7, finished_at = $8
+        WHERE id = $1
+    `, job.ID, job.Status, job.Attempts, result, job.Error, job.UpdatedAt, job.RunAfter, job.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	return nil
+}
+
+// Get returns a job, or ErrNotFound
+func (p *postgresStore) Get(id int64) (models.QueuedJob, error) {
+	job, err := scanJob(p.db.QueryRow(`SELECT `+jobColumns+` FROM queued_jobs WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.QueuedJob{}, ErrNotFound
+	}
+	if err != nil {
+		return models.QueuedJob{}, fmt.Errorf("failed to query job: %w", err)
+	}
+	return job, nil
+}
+
+// List returns up to limit of a user's jobs, newest first
+func (p *postgresStore) List(userID, status string, limit int) ([]models.QueuedJob, error) {
+	return p.query(`SELECT `+jobColumns+` FROM queued_jobs
+        WHERE user_id = $1 AND ($2 = '' OR status = $2)
+        ORDER BY id DESC LIMIT $3`, userID, status, limit)
+}
+
+// Unfinished returns every queued or running job, oldest first
+func (p *postgresStore) Unfinished() ([]models.QueuedJob, error) {
+	return p.query(`SELECT ` + jobColumns + ` FROM queued_jobs WHERE status IN ('queued', 'running') ORDER BY id`)
+}
+
+// DeleteFinished removes jobs that finished before cutoff
+func (p *postgresStore) DeleteFinished(cutoff time.Time) (int, error) {
+	result, err := p.db.Exec(`DELETE FROM queued_jobs WHERE finished_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+// query returns the jobs selected by a query of jobColumns
+func (p *postgresStore) query(query string, args ...interface{}) ([]models.QueuedJob, error) {
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.QueuedJob{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanJob reads a row of jobColumns
+func scanJob(row rowScanner) (models.QueuedJob, error) {
+	var job models.QueuedJob
+	var payload, result []byte
+	var finishedAt sql.NullTime
+	err := row.Scan(&job.ID, &job.Type, &job.UserID, &job.Status, &job.Attempts, &job.MaxAttempts,
+		&payload, &result, &job.Error, &job.CreatedAt, &job.UpdatedAt, &job.RunAfter, &finishedAt)
+	job.Payload = payload
+	job.Result = result
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return job, err
+}
@@ -0,0 +1,291 @@
+package jobqueue
+
+import (
+	"backend/server/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Config holds job queue settings
+type Config struct {
+	Workers     int           // Jobs run at the same time
+	MaxAttempts int           // Attempts per job, including the first
+	RetryDelay  time.Duration // Delay before the first retry, doubling after each
+	Timeout     time.Duration // Time allowed for each attempt
+	MaxPending  int           // Unfinished jobs allowed at once; more are rejected with ErrQueueFull
+	Retention   time.Duration // How long finished jobs are kept; 0 keeps them forever
+}
+
+// DefaultConfig returns a default configuration for the job queue
+func DefaultConfig() Config {
+	return Config{
+		Workers:     2,
+		MaxAttempts: 3,
+		RetryDelay:  30 * time.Second,
+		Timeout:     30 * time.Minute,
+		MaxPending:  1000,
+		Retention:   7 * 24 * time.Hour,
+	}
+}
+
+// service implements Service with an in-process worker pool. Jobs are saved
+// to the store on every change, so the queue is rebuilt from it on startup.
+type service struct {
+	store      Store
+	config     Config
+	handlers   map[string]Handler
+	queue      chan int64 // IDs of jobs ready for a worker; created by Start
+	unfinished int        // Jobs queued, waiting for a retry or running
+	started    bool
+	mutex      sync.Mutex
+}
+
+// New creates a new job queue
+func New(store Store, config Config) Service {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.MaxPending < 1 {
+		config.MaxPending = 1
+	}
+	return &service{
+		store:    store,
+		config:   config,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers the handler for a job type
+func (s *service) Handle(jobType string, handler Handler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.handlers[jobType] = handler
+}
+
+// Enqueue saves a job and queues it for a worker. Jobs enqueued before Start
+// are only saved, and picked up by Start.
+func (s *service) Enqueue(userID, jobType string, payload interface{}) (models.QueuedJob, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return models.QueuedJob{}, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.handlers[jobType]; !ok {
+		return models.QueuedJob{}, fmt.Errorf("%w %q", ErrUnknownType, jobType)
+	}
+	if s.started && s.unfinished >= s.config.MaxPending {
+		return models.QueuedJob{}, ErrQueueFull
+	}
+
+	now := time.Now()
+	job, err := s.store.Create(models.QueuedJob{
+		Type:        jobType,
+		UserID:      userID,
+		Status:      models.JobQueued,
+		MaxAttempts: s.config.MaxAttempts,
+		Payload:     data,
+		UpdatedAt:   now,
+		RunAfter:    now,
+	})
+	if err != nil {
+		return models.QueuedJob{}, err
+	}
+	if s.started {
+		s.unfinished++
+		s.queue <- job.ID
+	}
+	return job, nil
+}
+
+// Get returns a job
+func (s *service) Get(id int64) (models.QueuedJob, error) {
+	return s.store.Get(id)
+}
+
+// List returns up to limit of a user's jobs, newest first
+func (s *service) List(userID, status string, limit int) ([]models.QueuedJob, error) {
+	return s.store.List(userID, status, limit)
+}
+
+// Start resumes unfinished jobs and starts the workers until ctx is cancelled.
+// Jobs that were running when the server stopped are attempted again.
+func (s *service) Start(ctx context.Context) error {
+	jobs, err := s.store.Unfinished()
+	if err != nil {
+		return fmt.Errorf("failed to load unfinished jobs: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.started {
+		return nil
+	}
+	s.started = true
+	s.unfinished = len(jobs)
+	capacity := s.config.MaxPending
+	if len(jobs) > capacity {
+		capacity = len(jobs)
+	}
+	s.queue = make(chan int64, capacity)
+
+	for _, job := range jobs {
+		s.schedule(job.ID, time.Until(job.RunAfter))
+	}
+	if len(jobs) > 0 {
+		log.Printf("Job queue: resumed %d unfinished jobs", len(jobs))
+	}
+
+	for i := 0; i < s.config.Workers; i++ {
+		go s.work(ctx)
+	}
+	return nil
+}
+
+// Purge removes jobs that finished more than the retention period ago
+func (s *service) Purge() (int, error) {
+	if s.config.Retention <= 0 {
+		return 0, nil
+	}
+	return s.store.DeleteFinished(time.Now().Add(-s.config.Retention))
+}
+
+// schedule queues a job for a worker after delay. The queue has room for
+// every unfinished job, so this never blocks.
+func (s *service) schedule(id int64, delay time.Duration) {
+	if delay <= 0 {
+		s.queue <- id
+		return
+	}
+	time.AfterFunc(delay, func() {
+		s.queue <- id
+	})
+}
+
+// work runs queued jobs until ctx is cancelled
+func (s *service) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.process(ctx, id)
+		}
+	}
+}
+
+// process runs one attempt of a job and saves the outcome, scheduling a
+// retry if the attempt failed and the job has attempts left
+func (s *service) process(ctx context.Context, id int64) {
+	job, err := s.store.Get(id)
+	if err != nil {
+		log.Printf("Job queue: failed to load job %d: %v", id, err)
+		s.finish()
+		return
+	}
+
+	s.mutex.Lock()
+	handler := s.handlers[job.Type]
+	s.mutex.Unlock()
+	if handler == nil {
+		s.fail(job, fmt.Errorf("%w %q", ErrUnknownType, job.Type))
+		return
+	}
+
+	job.Status = models.JobRunning
+	job.Attempts++
+	s.save(&job)
+
+	attemptCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	result, err := call(attemptCtx, handler, job.Payload)
+	cancel()
+
+	if ctx.Err() != nil {
+		// Shutting down; the attempt is made again after the restart
+		job.Status = models.JobQueued
+		job.Attempts--
+		s.save(&job)
+		return
+	}
+	if err == nil {
+		s.succeed(job, result)
+		return
+	}
+	if job.Attempts >= job.MaxAttempts {
+		s.fail(job, err)
+		return
+	}
+
+	delay := s.config.RetryDelay << (job.Attempts - 1)
+	log.Printf("Job queue: %s job %d failed attempt %d, retrying in %s: %v", job.Type, job.ID, job.Attempts, delay, err)
+	job.Status = models.JobQueued
+	job.Error = err.Error()
+	job.RunAfter = time.Now().Add(delay)
+	s.save(&job)
+	s.schedule(job.ID, delay)
+}
+
+// succeed saves a job's result
+func (s *service) succeed(job models.QueuedJob, result interface{}) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		s.fail(job, fmt.Errorf("failed to encode job result: %w", err))
+		return
+	}
+	now := time.Now()
+	job.Status = models.JobSucceeded
+	job.Result = data
+	job.Error = ""
+	job.FinishedAt = &now
+	s.save(&job)
+	s.finish()
+}
+
+// fail marks a job as failed for good
+func (s *service) fail(job models.QueuedJob, err error) {
+	log.Printf("Job queue: %s job %d failed after %d attempts: %v", job.Type, job.ID, job.Attempts, err)
+	now := time.Now()
+	job.Status = models.JobFailed
+	job.Error = err.Error()
+	job.FinishedAt = &now
+	s.save(&job)
+	s.finish()
+}
+
+// save stores a job's changes, logging failures; the job keeps running
+// either way, but a restart may then repeat or lose its latest state
+func (s *service) save(job *models.QueuedJob) {
+	job.UpdatedAt = time.Now()
+	if err := s.store.Update(*job); err != nil {
+		log.Printf("Job queue: failed to save job %d: %v", job.ID, err)
+	}
+}
+
+// finish counts a job as no longer unfinished
+func (s *service) finish() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.unfinished--
+}
+
+// call runs handler, turning a panic into an error
+func call(ctx context.Context, handler Handler, payload json.RawMessage) (result interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(ctx, payload)
+}
@@ -0,0 +1,30 @@
+package llmcache
+
+import "context"
+
+// AIService is the AI provider interface wrapped by the cache
+type AIService interface {
+	AnalyzeLyrics(query, lyrics, songInfo string) (string, error)
+	GenerateResponse(prompt string) (string, error)
+	GenerateJSON(prompt string) (string, error)
+	IsAvailable() error
+}
+
+// streamingAIService is implemented by providers that can stream responses
+type streamingAIService interface {
+	GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error
+}
+
+// Service is an AIService that serves repeated prompts from a cache
+type Service interface {
+	AIService
+
+	// GenerateStream streams a response. Cached responses are delivered as a
+	// single chunk; providers without streaming support are called once and
+	// their full response is delivered as a single chunk.
+	GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error
+
+	// Wrap returns a Service that calls ai on a cache miss but shares this
+	// service's cache, e.g. for a per-user view of the wrapped provider
+	Wrap(ai AIService) Service
+}
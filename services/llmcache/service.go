@@ -0,0 +1,193 @@
+package llmcache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config holds LLM response cache configuration
+type Config struct {
+	Model      string        // Included in the key so switching models never serves stale answers
+	TTL        time.Duration // How long a response stays valid
+	MaxEntries int           // Least recently used entries are evicted beyond this size
+}
+
+// entry is a cached response
+type entry struct {
+	key       string
+	response  string
+	expiresAt time.Time
+}
+
+// store holds cached responses; it is shared by every view created with Wrap
+type store struct {
+	entries map[string]*list.Element
+	order   *list.List // Front is most recently used
+	mutex   sync.Mutex
+}
+
+// service implements the llmcache Service interface
+type service struct {
+	ai     AIService
+	config Config
+	store  *store
+}
+
+// New wraps an AI service with an LRU response cache
+func New(ai AIService, config Config) Service {
+	return &service{
+		ai:     ai,
+		config: config,
+		store: &store{
+			entries: make(map[string]*list.Element),
+			order:   list.New(),
+		},
+	}
+}
+
+// Wrap returns a Service that calls ai on a miss but shares this cache
+func (s *service) Wrap(ai AIService) Service {
+	return &service{
+		ai:     ai,
+		config: s.config,
+		store:  s.store,
+	}
+}
+
+// IsAvailable checks if the wrapped AI service is available
+func (s *service) IsAvailable() error {
+	return s.ai.IsAvailable()
+}
+
+// AnalyzeLyrics analyzes lyrics, reusing a cached answer for the same song and question
+func (s *service) AnalyzeLyrics(query, lyrics, songInfo string) (string, error) {
+	key := s.key("lyrics", songInfo, strings.ToLower(strings.TrimSpace(query)), lyrics)
+	return s.cached(key, func() (string, error) {
+		return s.ai.AnalyzeLyrics(query, lyrics, songInfo)
+	})
+}
+
+// GenerateResponse generates a response, reusing a cached answer for the same prompt
+func (s *service) GenerateResponse(prompt string) (string, error) {
+	return s.cached(s.key("text", prompt), func() (string, error) {
+		return s.ai.GenerateResponse(prompt)
+	})
+}
+
+// GenerateJSON generates a JSON response, reusing a cached answer for the same prompt
+func (s *service) GenerateJSON(prompt string) (string, error) {
+	return s.cached(s.key("json", prompt), func() (string, error) {
+		return s.ai.GenerateJSON(prompt)
+	})
+}
+
+// GenerateStream streams a response, caching the full text once streaming completes
+func (s *service) GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error {
+	key := s.key("text", prompt)
+	if response, ok := s.get(key); ok {
+		return onChunk(response)
+	}
+
+	streamer, ok := s.ai.(streamingAIService)
+	if !ok {
+		response, err := s.GenerateResponse(prompt)
+		if err != nil {
+			return err
+		}
+		return onChunk(response)
+	}
+
+	var full strings.Builder
+	err := streamer.GenerateStream(ctx, prompt, func(chunk string) error {
+		full.WriteString(chunk)
+		return onChunk(chunk)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.put(key, full.String())
+	return nil
+}
+
+// cached returns the cached response for key, calling generate on a miss.
+// Errors are never cached.
+func (s *service) cached(key string, generate func() (string, error)) (string, error) {
+	if response, ok := s.get(key); ok {
+		return response, nil
+	}
+
+	response, err := generate()
+	if err != nil {
+		return "", err
+	}
+
+	s.put(key, response)
+	return response, nil
+}
+
+// key hashes the model and prompt parts into a cache key
+func (s *service) key(kind string, parts ...string) string {
+	hash := sha256.New()
+	hash.Write([]byte(s.config.Model))
+	hash.Write([]byte{0})
+	hash.Write([]byte(kind))
+	for _, part := range parts {
+		hash.Write([]byte{0})
+		hash.Write([]byte(part))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// get returns a non-expired cached response and marks it recently used
+func (s *service) get(key string) (string, bool) {
+	s.store.mutex.Lock()
+	defer s.store.mutex.Unlock()
+
+	element, ok := s.store.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	cached := element.Value.(*entry)
+	if time.Now().After(cached.expiresAt) {
+		s.store.order.Remove(element)
+		delete(s.store.entries, key)
+		return "", false
+	}
+
+	s.store.order.MoveToFront(element)
+	return cached.response, true
+}
+
+// put stores a response, evicting the least recently used entries when full
+func (s *service) put(key, response string) {
+	if s.config.MaxEntries <= 0 {
+		return
+	}
+
+	s.store.mutex.Lock()
+	defer s.store.mutex.Unlock()
+
+	expiresAt := time.Now().Add(s.config.TTL)
+	if element, ok := s.store.entries[key]; ok {
+		cached := element.Value.(*entry)
+		cached.response = response
+		cached.expiresAt = expiresAt
+		s.store.order.MoveToFront(element)
+		return
+	}
+
+	s.store.entries[key] = s.store.order.PushFront(&entry{key: key, response: response, expiresAt: expiresAt})
+
+	for s.store.order.Len() > s.config.MaxEntries {
+		oldest := s.store.order.Back()
+		s.store.order.Remove(oldest)
+		delete(s.store.entries, oldest.Value.(*entry).key)
+	}
+}
@@ -0,0 +1,135 @@
+package moderation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// classifyTimeout bounds each moderation request, which holds up a message being posted
+const classifyTimeout = 10 * time.Second
+
+// openAIClassifier calls an OpenAI-compatible /moderations endpoint
+type openAIClassifier struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAIClassifier creates a classifier using the OpenAI moderation API at
+// baseURL (e.g. https://api.openai.com/v1)
+func NewOpenAIClassifier(baseURL, apiKey, model string) Classifier {
+	return &openAIClassifier{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: classifyTimeout},
+	}
+}
+
+// Name identifies the classifier
+func (c *openAIClassifier) Name() string {
+	return "openai/" + c.model
+}
+
+// Classify returns the API's score per category
+func (c *openAIClassifier) Classify(text string) (map[string]float64, error) {
+	payload, err := json.Marshal(map[string]string{"model": c.model, "input": text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", c.baseURL+"/moderations", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai moderation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("openai moderation: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("openai moderation: status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var response struct {
+		Results []struct {
+			CategoryScores map[string]float64 `json:"category_scores"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("openai moderation: %w", err)
+	}
+	if len(response.Results) == 0 {
+		return nil, fmt.Errorf("openai moderation: no result")
+	}
+	return response.Results[0].CategoryScores, nil
+}
+
+// localPattern scores a category when its phrases appear in a message
+type localPattern struct {
+	category string
+	score    float64
+	pattern  *regexp.Regexp
+}
+
+// localPatterns are the phrases the local classifier knows. Slurs and threats
+// score 1, insults score lower so that they are flagged rather than rejected
+// with the default thresholds.
+var localPatterns = []localPattern{
+	{"hate", 1, phrases("nigger", "niggers", "faggot", "faggots", "kike", "kikes", "spic", "spics", "chink", "chinks", "tranny", "trannies", "wetback", "wetbacks")},
+	{"harassment", 1, phrases("kill yourself", "kill urself", "kys", "go die", "you should die", "hang yourself")},
+	{"harassment", 0.6, phrases("nobody likes you", "no one likes you", "you're worthless", "you are worthless", "you're pathetic", "you are pathetic", "you're disgusting", "you are disgusting", "shut up loser")},
+	{"harassment/threatening", 1, phrases("i will kill you", "i'll kill you", "i'm going to kill you", "im going to kill you", "i know where you live", "i will find you", "i'll find you")},
+}
+
+// phrases compiles a case-insensitive pattern matching any of the phrases as whole words
+func phrases(list ...string) *regexp.Regexp {
+	quoted := make([]string, len(list))
+	for i, phrase := range list {
+		quoted[i] = strings.ReplaceAll(regexp.QuoteMeta(phrase), " ", `\s+`)
+	}
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// localClassifier scores messages by known phrases, for deployments without
+// a moderation API. It only catches explicit slurs, threats and insults.
+type localClassifier struct{}
+
+// NewLocalClassifier creates a classifier that needs no external service
+func NewLocalClassifier() Classifier {
+	return localClassifier{}
+}
+
+// Name identifies the classifier
+func (localClassifier) Name() string {
+	return "local"
+}
+
+// Classify scores each category by the strongest of its phrases found in text
+func (localClassifier) Classify(text string) (map[string]float64, error) {
+	text = strings.ReplaceAll(text, "’", "'")
+	scores := make(map[string]float64)
+	for _, p := range localPatterns {
+		if _, ok := scores[p.category]; !ok {
+			scores[p.category] = 0
+		}
+		if p.score > scores[p.category] && p.pattern.MatchString(text) {
+			scores[p.category] = p.score
+		}
+	}
+	return scores, nil
+}
@@ -0,0 +1,65 @@
+package moderation
+
+import (
+	"backend/server/models"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned for verdicts that aren't stored
+	ErrNotFound = errors.New("moderation verdict not found")
+
+	// ErrReviewed is returned when reviewing a verdict that isn't pending
+	ErrReviewed = errors.New("moderation verdict already reviewed")
+)
+
+// Classifier scores text against moderation categories
+type Classifier interface {
+	// Name identifies the classifier in verdicts, e.g. "openai/omni-moderation-latest"
+	Name() string
+
+	// Classify returns a score from 0 to 1 per category, such as "hate" or
+	// "harassment/threatening". Categories the classifier doesn't know are left out.
+	Classify(text string) (map[string]float64, error)
+}
+
+// Store persists moderation verdicts
+type Store interface {
+	// Put stores a verdict, returning it with its ID
+	Put(verdict models.ModerationVerdict) (models.ModerationVerdict, error)
+
+	// Get returns a stored verdict, or ErrNotFound
+	Get(id int64) (models.ModerationVerdict, error)
+
+	// List returns up to limit verdicts with a status, oldest first; an empty
+	// status matches any
+	List(status string, limit int) ([]models.ModerationVerdict, error)
+
+	// Review sets the status of a pending verdict and who reviewed it. It
+	// returns ErrNotFound or ErrReviewed if there is no such pending verdict.
+	Review(id int64, status, moderator string) (models.ModerationVerdict, error)
+}
+
+// Service screens chat messages for hate and harassment and keeps the
+// verdicts for moderators. Messages scoring at or above the reject threshold
+// aren't posted; those at or above the flag threshold are posted and queued
+// for review.
+type Service interface {
+	// Moderate classifies a message's text and returns the action to take.
+	// Classifier failures are logged and the message is allowed.
+	Moderate(text string) models.ModerationVerdict
+
+	// Record stores the verdict on a flagged or rejected message, returning
+	// it with its ID
+	Record(verdict models.ModerationVerdict) (models.ModerationVerdict, error)
+
+	// Get returns a stored verdict, or ErrNotFound
+	Get(id int64) (models.ModerationVerdict, error)
+
+	// Queue returns up to limit verdicts with a status, oldest first
+	Queue(status string, limit int) ([]models.ModerationVerdict, error)
+
+	// Review approves or removes a pending flagged message, recording the
+	// moderator; decision is "approve" or "remove"
+	Review(id int64, decision, moderator string) (models.ModerationVerdict, error)
+}
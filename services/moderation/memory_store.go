@@ -0,0 +1,89 @@
+package moderation
+
+import (
+	"backend/server/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryStore keeps verdicts in memory, for development without a database and tests
+type memoryStore struct {
+	verdicts map[int64]models.ModerationVerdict
+	nextID   int64
+	mutex    sync.RWMutex
+}
+
+// NewMemoryStore creates an in-memory Store
+func NewMemoryStore() Store {
+	return &memoryStore{verdicts: make(map[int64]models.ModerationVerdict)}
+}
+
+// Put stores a verdict, returning it with its ID
+func (m *memoryStore) Put(verdict models.ModerationVerdict) (models.ModerationVerdict, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.nextID++
+	verdict.ID = m.nextID
+	if verdict.CreatedAt.IsZero() {
+		verdict.CreatedAt = time.Now()
+	}
+	m.verdicts[verdict.ID] = verdict
+	return verdict, nil
+}
+
+// Get returns a stored verdict, or ErrNotFound
+func (m *memoryStore) Get(id int64) (models.ModerationVerdict, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	verdict, ok := m.verdicts[id]
+	if !ok {
+		return models.ModerationVerdict{}, ErrNotFound
+	}
+	return verdict, nil
+}
+
+// List returns up to limit verdicts with a status, oldest first
+func (m *memoryStore) List(status string, limit int) ([]models.ModerationVerdict, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	verdicts := []models.ModerationVerdict{}
+	for _, verdict := range m.verdicts {
+		if status == "" || verdict.Status == status {
+			verdicts = append(verdicts, verdict)
+		}
+	}
+	sort.Slice(verdicts, func(i, j int) bool {
+		if !verdicts[i].CreatedAt.Equal(verdicts[j].CreatedAt) {
+			return verdicts[i].CreatedAt.Before(verdicts[j].CreatedAt)
+		}
+		return verdicts[i].ID < verdicts[j].ID
+	})
+	if len(verdicts) > limit {
+		verdicts = verdicts[:limit]
+	}
+	return verdicts, nil
+}
+
+// Review sets the status of a pending verdict and who reviewed it
+func (m *memoryStore) Review(id int64, status, moderator string) (models.ModerationVerdict, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	verdict, ok := m.verdicts[id]
+	if !ok {
+		return models.ModerationVerdict{}, ErrNotFound
+	}
+	if verdict.Status != models.ModerationPending {
+		return models.ModerationVerdict{}, ErrReviewed
+	}
+	now := time.Now()
+	verdict.Status = status
+	verdict.ReviewedBy = moderator
+	verdict.ReviewedAt = &now
+	m.verdicts[id] = verdict
+	return verdict, nil
+}
@@ -0,0 +1,170 @@
+package moderation
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Schema creates the moderation_verdicts table. It references global_messages,
+// so it runs after that table is created.
+const Schema = `
+        CREATE TABLE IF NOT EXISTS moderation_verdicts (
+            id BIGSERIAL PRIMARY KEY,
+            message_id INTEGER REFERENCES global_messages(id) ON DELETE SET NULL,
+            user_email VARCHAR(255) NOT NULL,
+            username VARCHAR(255) NOT NULL,
+            message_text TEXT NOT NULL,
+            action VARCHAR(16) NOT NULL,
+            categories JSONB NOT NULL,
+            scores JSONB,
+            classifier VARCHAR(128) NOT NULL,
+            status VARCHAR(16) NOT NULL,
+            reviewed_by VARCHAR(255),
+            reviewed_at TIMESTAMP WITH TIME ZONE,
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_moderation_verdicts_status ON moderation_verdicts(status, created_at);
+    `
+
+// selectVerdicts selects every column of moderation_verdicts in scanVerdict's order
+const selectVerdicts = `
+        SELECT id, message_id, user_email, username, message_text, action, categories, scores,
+            classifier, status, reviewed_by, reviewed_at, created_at
+        FROM moderation_verdicts
+    `
+
+// postgresStore keeps verdicts in the moderation_verdicts table
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a Store backed by the table in Schema
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+// Put stores a verdict, returning it with its ID
+func (p *postgresStore) Put(verdict models.ModerationVerdict) (models.ModerationVerdict, error) {
+	categories, err := json.Marshal(verdict.Categories)
+	if err != nil {
+		return models.ModerationVerdict{}, err
+	}
+	var scores interface{} // NULL when the classifier failed
+	if verdict.Scores != nil {
+		data, err := json.Marshal(verdict.Scores)
+		if err != nil {
+			return models.ModerationVerdict{}, err
+		}
+		scores = string(data)
+	}
+	if verdict.CreatedAt.IsZero() {
+		verdict.CreatedAt = time.Now()
+	}
+
+	err = p.db.QueryRow(`
+        INSERT INTO moderation_verdicts (message_id, user_email, username, message_text, action, categories, scores, classifier, status, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING id
+    `, verdict.MessageID, verdict.UserEmail, verdict.Username, verdict.Text, verdict.Action, string(categories), scores,
+		verdict.Classifier, verdict.Status, verdict.CreatedAt).Scan(&verdict.ID)
+	if err != nil {
+		return models.ModerationVerdict{}, fmt.Errorf("failed to save moderation verdict: %w", err)
+	}
+	return verdict, nil
+}
+
+// Get returns a stored verdict, or ErrNotFound
+func (p *postgresStore) Get(id int64) (models.ModerationVerdict, error) {
+	verdict, err := scanVerdict(p.db.QueryRow(selectVerdicts+`WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ModerationVerdict{}, ErrNotFound
+	}
+	return verdict, err
+}
+
+// List returns up to limit verdicts with a status, oldest first
+func (p *postgresStore) List(status string, limit int) ([]models.ModerationVerdict, error) {
+	rows, err := p.db.Query(selectVerdicts+`
+        WHERE $1::text = '' OR status = $1::text
+        ORDER BY created_at ASC, id ASC
+        LIMIT $2
+    `, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query moderation verdicts: %w", err)
+	}
+	defer rows.Close()
+
+	verdicts := []models.ModerationVerdict{}
+	for rows.Next() {
+		verdict, err := scanVerdict(rows)
+		if err != nil {
+			return nil, err
+		}
+		verdicts = append(verdicts, verdict)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read moderation verdicts: %w", err)
+	}
+	return verdicts, nil
+}
+
+// Review sets the status of a pending verdict and who reviewed it
+func (p *postgresStore) Review(id int64, status, moderator string) (models.ModerationVerdict, error) {
+	verdict, err := scanVerdict(p.db.QueryRow(`
+        UPDATE moderation_verdicts SET status = $2, reviewed_by = $3, reviewed_at = NOW()
+        WHERE id = $1 AND status = $4
+        RETURNING id, message_id, user_email, username, message_text, action, categories, scores,
+            classifier, status, reviewed_by, reviewed_at, created_at
+    `, id, status, moderator, models.ModerationPending))
+	if !errors.Is(err, sql.ErrNoRows) {
+		return verdict, err
+	}
+	if _, err := p.Get(id); err != nil {
+		return models.ModerationVerdict{}, err
+	}
+	return models.ModerationVerdict{}, ErrReviewed
+}
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanVerdict scans a row of a selectVerdicts query
+func scanVerdict(row rowScanner) (models.ModerationVerdict, error) {
+	var verdict models.ModerationVerdict
+	var messageID sql.NullInt64
+	var categories, scores []byte
+	var reviewedBy sql.NullString
+	var reviewedAt sql.NullTime
+	err := row.Scan(&verdict.ID, &messageID, &verdict.UserEmail, &verdict.Username, &verdict.Text, &verdict.Action,
+		&categories, &scores, &verdict.Classifier, &verdict.Status, &reviewedBy, &reviewedAt, &verdict.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ModerationVerdict{}, err
+	}
+	if err != nil {
+		return models.ModerationVerdict{}, fmt.Errorf("failed to scan moderation verdict: %w", err)
+	}
+
+	if messageID.Valid {
+		verdict.MessageID = &messageID.Int64
+	}
+	if err := json.Unmarshal(categories, &verdict.Categories); err != nil {
+		return models.ModerationVerdict{}, fmt.Errorf("failed to decode moderation categories: %w", err)
+	}
+	if len(scores) > 0 {
+		if err := json.Unmarshal(scores, &verdict.Scores); err != nil {
+			return models.ModerationVerdict{}, fmt.Errorf("failed to decode moderation scores: %w", err)
+		}
+	}
+	verdict.ReviewedBy = reviewedBy.String
+	if reviewedAt.Valid {
+		verdict.ReviewedAt = &reviewedAt.Time
+	}
+	return verdict, nil
+}
@@ -0,0 +1,125 @@
+package moderation
+
+import (
+	"backend/server/models"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// Review decisions
+const (
+	DecisionApprove = "approve"
+	DecisionRemove  = "remove"
+)
+
+// Config holds chat moderation configuration
+type Config struct {
+	Categories      []string // Categories acted on; others are ignored
+	FlagThreshold   float64  // Messages scoring at least this in a category are flagged
+	RejectThreshold float64  // Messages scoring at least this in a category are rejected
+}
+
+// DefaultConfig returns a default configuration for chat moderation
+func DefaultConfig() Config {
+	return Config{
+		Categories:      []string{"hate", "hate/threatening", "harassment", "harassment/threatening"},
+		FlagThreshold:   0.5,
+		RejectThreshold: 0.9,
+	}
+}
+
+// service implements the moderation Service interface
+type service struct {
+	classifier Classifier
+	store      Store
+	config     Config
+}
+
+// New creates a moderation service classifying messages with classifier and
+// keeping verdicts in store
+func New(classifier Classifier, store Store, config Config) Service {
+	return &service{
+		classifier: classifier,
+		store:      store,
+		config:     config,
+	}
+}
+
+// Moderate classifies a message's text and returns the action to take
+func (s *service) Moderate(text string) models.ModerationVerdict {
+	verdict := models.ModerationVerdict{
+		Text:       text,
+		Action:     models.ModerationAllow,
+		Categories: []string{},
+		Classifier: s.classifier.Name(),
+	}
+	scores, err := s.classifier.Classify(text)
+	if err != nil {
+		log.Printf("Moderation: %s failed, allowing the message: %v", s.classifier.Name(), err)
+		return verdict
+	}
+
+	verdict.Scores = make(map[string]float64)
+	var top float64
+	for _, category := range s.config.Categories {
+		score, ok := scores[category]
+		if !ok {
+			continue
+		}
+		verdict.Scores[category] = score
+		if score >= s.config.FlagThreshold {
+			verdict.Categories = append(verdict.Categories, category)
+		}
+		if score > top {
+			top = score
+		}
+	}
+	sort.Strings(verdict.Categories)
+
+	switch {
+	case top >= s.config.RejectThreshold:
+		verdict.Action = models.ModerationReject
+	case top >= s.config.FlagThreshold:
+		verdict.Action = models.ModerationFlag
+	}
+	return verdict
+}
+
+// Record stores the verdict on a flagged or rejected message. Flagged
+// messages await review; rejected ones have nothing left to review.
+func (s *service) Record(verdict models.ModerationVerdict) (models.ModerationVerdict, error) {
+	switch verdict.Action {
+	case models.ModerationFlag:
+		verdict.Status = models.ModerationPending
+	case models.ModerationReject:
+		verdict.Status = models.ModerationRejected
+	default:
+		return verdict, fmt.Errorf("only flagged and rejected messages are recorded, got %q", verdict.Action)
+	}
+	return s.store.Put(verdict)
+}
+
+// Get returns a stored verdict, or ErrNotFound
+func (s *service) Get(id int64) (models.ModerationVerdict, error) {
+	return s.store.Get(id)
+}
+
+// Queue returns up to limit verdicts with a status, oldest first
+func (s *service) Queue(status string, limit int) ([]models.ModerationVerdict, error) {
+	return s.store.List(status, limit)
+}
+
+// Review approves or removes a pending flagged message
+func (s *service) Review(id int64, decision, moderator string) (models.ModerationVerdict, error) {
+	var status string
+	switch decision {
+	case DecisionApprove:
+		status = models.ModerationApproved
+	case DecisionRemove:
+		status = models.ModerationRemoved
+	default:
+		return models.ModerationVerdict{}, fmt.Errorf("decision must be %s or %s, got %q", DecisionApprove, DecisionRemove, decision)
+	}
+	return s.store.Review(id, status, moderator)
+}
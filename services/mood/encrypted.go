@@ -0,0 +1,86 @@
+package mood
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Encryptor encrypts values with a per-user key
+type Encryptor interface {
+	Encrypt(userID, plaintext string) (string, error)
+	Decrypt(userID, value string) (string, error)
+}
+
+// encryptedService stores mood history encrypted with each user's key.
+// Timestamps stay in the clear so retention can expire entries.
+type encryptedService struct {
+	Service
+	encryptor Encryptor
+}
+
+// WithEncryption wraps a mood service so mood history is encrypted at rest
+func WithEncryption(service Service, encryptor Encryptor) Service {
+	return &encryptedService{
+		Service:   service,
+		encryptor: encryptor,
+	}
+}
+
+// SaveUserMoodHistory encrypts the mood and played songs before saving them
+func (e *encryptedService) SaveUserMoodHistory(userID string, mood string, playedSongs []string) error {
+	encryptedMood, err := e.encryptor.Encrypt(userID, mood)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt mood history: %w", err)
+	}
+	encryptedSongs, err := e.encryptor.Encrypt(userID, strings.Join(playedSongs, ","))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt mood history: %w", err)
+	}
+	return e.Service.SaveUserMoodHistory(userID, encryptedMood, []string{encryptedSongs})
+}
+
+// AddMoodNote encrypts the journal note before saving it
+func (e *encryptedService) AddMoodNote(userID, entryTimestamp, note string) (string, error) {
+	encryptedNote, err := e.encryptor.Encrypt(userID, note)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt mood journal: %w", err)
+	}
+	return e.Service.AddMoodNote(userID, entryTimestamp, encryptedNote)
+}
+
+// GetUserMoodHistory decrypts the user's mood history. Entries saved before
+// encryption was enabled are returned as they are.
+func (e *encryptedService) GetUserMoodHistory(userID string) ([]UserMoodEntry, error) {
+	entries, err := e.Service.GetUserMoodHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		mood, err := e.encryptor.Decrypt(userID, entries[i].DetectedMood)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt mood history: %w", err)
+		}
+		entries[i].DetectedMood = mood
+
+		if entries[i].Note != "" {
+			note, err := e.encryptor.Decrypt(userID, entries[i].Note)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt mood journal: %w", err)
+			}
+			entries[i].Note = note
+		}
+
+		if len(entries[i].PlayedSongs) == 1 {
+			songs, err := e.encryptor.Decrypt(userID, entries[i].PlayedSongs[0])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt mood history: %w", err)
+			}
+			entries[i].PlayedSongs = []string{}
+			if songs != "" {
+				entries[i].PlayedSongs = strings.Split(songs, ",")
+			}
+		}
+	}
+	return entries, nil
+}
@@ -9,17 +9,32 @@ type Service interface {
 	// DetectMood analyzes user message for emotional content
 	DetectMood(message string) (*models.MoodAnalysis, error)
 	
+	// DetectMoodWithPrompt analyzes a message with the named prompt template,
+	// e.g. a variant of mood_detection in a prompt experiment
+	DetectMoodWithPrompt(template, message string) (*models.MoodAnalysis, error)
+	
 	// MatchSongsToMood finds songs that match the detected mood
 	MatchSongsToMood(mood *models.MoodAnalysis, userTracks []models.UnifiedTrack, limit int) ([]models.MoodBasedRecommendation, error)
 	
 	// GetLyricsWithMood fetches lyrics and analyzes their mood
 	GetLyricsWithMood(trackName, artistName string) (*LyricsWithMood, error)
 	
+	// GetCachedLyricsMood returns a previously computed analysis without calling the AI service
+	GetCachedLyricsMood(trackName, artistName string) (*LyricsWithMood, bool)
+	
 	// SaveUserMoodHistory saves user's mood and played songs to history file
 	SaveUserMoodHistory(userID string, mood string, playedSongs []string) error
 	
-	// GetUserMoodHistory retrieves user's mood history
+	// ListMoodUsers returns the users who have a mood history
+	ListMoodUsers() ([]string, error)
+	
+	// GetUserMoodHistory retrieves user's mood history with its journal notes
 	GetUserMoodHistory(userID string) ([]UserMoodEntry, error)
+	
+	// AddMoodNote attaches a journal note to the mood history entry saved at
+	// entryTimestamp, or to the latest one if it is empty, and returns the
+	// entry's timestamp
+	AddMoodNote(userID, entryTimestamp, note string) (string, error)
 }
 
 // LyricsWithMood represents lyrics with mood analysis
@@ -34,6 +49,7 @@ type UserMoodEntry struct {
 	Timestamp    string   `json:"timestamp"`
 	DetectedMood string   `json:"detected_mood"`
 	PlayedSongs  []string `json:"played_songs"`
+	Note         string   `json:"note,omitempty"` // The user's journal note on the entry
 }
 
 // MoodKeywords defines keywords associated with different moods
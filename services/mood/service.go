@@ -3,32 +3,57 @@ package mood
 import (
 	"backend/server/models"
 	"backend/services/genius"
+	"backend/services/prompts"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 )
 
+// ErrMoodEntryNotFound is returned when a journal note names no mood history entry
+var ErrMoodEntryNotFound = errors.New("mood entry not found")
+
+// ErrInvalidUserID is returned for user IDs that can't name a mood history
+// file, such as those containing path separators
+var ErrInvalidUserID = errors.New("invalid user ID")
+
 // AIService defines the interface for AI services (both Ollama and OpenAI)
 type AIService interface {
 	GenerateResponse(prompt string) (string, error)
+	// GenerateJSON uses the provider's JSON mode so the reply is always parseable
+	GenerateJSON(prompt string) (string, error)
+}
+
+// lyricsFitter is implemented by AI services that condense lyrics too long
+// for the model's context window
+type lyricsFitter interface {
+	FitLyrics(lyrics string) (string, error)
 }
 
 // service implements the Mood Service interface
 type service struct {
 	geniusService genius.Service
 	aiService     AIService  // Can be either Ollama or OpenAI
+	prompts       prompts.Service
 	lyricsCache   map[string]*LyricsWithMood
 	cacheMutex    sync.RWMutex
 	dataDir       string
 }
 
-// New creates a new Mood service
-func New(geniusService genius.Service, aiService AIService, dataDir string) Service {
+// New creates a new Mood service. templates renders the mood prompts; nil uses
+// the built-in templates.
+func New(geniusService genius.Service, aiService AIService, dataDir string, templates prompts.Service) Service {
+	if templates == nil {
+		templates = prompts.Default()
+	}
 	// Create data directory if it doesn't exist
 	moodHistoryDir := filepath.Join(dataDir, "mood_history")
 	os.MkdirAll(moodHistoryDir, 0755)
@@ -36,6 +61,7 @@ func New(geniusService genius.Service, aiService AIService, dataDir string) Serv
 	return &service{
 		geniusService: geniusService,
 		aiService:     aiService,
+		prompts:       templates,
 		lyricsCache:   make(map[string]*LyricsWithMood),
 		dataDir:       dataDir,
 	}
@@ -43,63 +69,107 @@ func New(geniusService genius.Service, aiService AIService, dataDir string) Serv
 
 // DetectMood analyzes user message for emotional content
 func (s *service) DetectMood(message string) (*models.MoodAnalysis, error) {
-	// Create mood detection prompt
-	prompt := fmt.Sprintf(`Analyze the following message for emotional content and mood. Return a JSON response with:
-- primary_mood: The main emotion detected (must be one of: sad, happy, angry, lonely, anxious, nostalgic, energetic, calm)
-- mood_score: Confidence score between 0 and 1
-- emotion_tags: Array of related emotions/themes
-
-Important: Respond ONLY with valid JSON, no additional text.
+	return s.DetectMoodWithPrompt(prompts.MoodDetection, message)
+}
 
-User message: "%s"`, message)
+// DetectMoodWithPrompt analyzes user message with the named prompt template
+func (s *service) DetectMoodWithPrompt(template, message string) (*models.MoodAnalysis, error) {
+	// Create mood detection prompt
+	prompt, err := s.prompts.Render(template, prompts.MoodDetectionData{Message: message})
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect mood: %w", err)
+	}
 
-	// Get response from AI service
-	response, err := s.aiService.GenerateResponse(prompt)
+	// Get response from AI service in JSON mode
+	response, err := s.aiService.GenerateJSON(prompt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect mood: %w", err)
 	}
 
-	// Parse JSON response
+	// Parse and validate JSON response
 	var moodAnalysis models.MoodAnalysis
 	if err := json.Unmarshal([]byte(response), &moodAnalysis); err != nil {
-		// If JSON parsing fails, try to extract mood manually
-		return s.fallbackMoodDetection(message), nil
+		return nil, fmt.Errorf("failed to parse mood analysis: %w", err)
+	}
+	if err := validateMoodAnalysis(&moodAnalysis); err != nil {
+		return nil, fmt.Errorf("invalid mood analysis: %w", err)
 	}
 
 	return &moodAnalysis, nil
 }
 
-// fallbackMoodDetection provides basic mood detection if AI fails
-func (s *service) fallbackMoodDetection(message string) *models.MoodAnalysis {
-	lowerMessage := strings.ToLower(message)
-	
-	// Check for mood keywords
-	for mood, keywords := range MoodKeywords {
-		matchCount := 0
-		for _, keyword := range keywords {
-			if strings.Contains(lowerMessage, keyword) {
-				matchCount++
-			}
+// validateMoodAnalysis checks a model-produced analysis against the expected schema
+func validateMoodAnalysis(analysis *models.MoodAnalysis) error {
+	if _, ok := MoodKeywords[analysis.PrimaryMood]; !ok {
+		return fmt.Errorf("unknown primary_mood %q", analysis.PrimaryMood)
+	}
+	if analysis.MoodScore < 0 || analysis.MoodScore > 1 {
+		return fmt.Errorf("mood_score %v out of range [0, 1]", analysis.MoodScore)
+	}
+	if analysis.EmotionTags == nil {
+		analysis.EmotionTags = []string{}
+	}
+	return normalizeMoods(analysis)
+}
+
+// normalizeMoods validates the weighted moods of a mixed analysis, merging
+// duplicates and scaling the weights to sum to 1. The strongest mood becomes
+// the primary one, and an analysis left with a single mood drops the list.
+func normalizeMoods(analysis *models.MoodAnalysis) error {
+	if len(analysis.Moods) == 0 {
+		return nil
+	}
+
+	weights := make(map[string]float64)
+	var order []string
+	for _, weighted := range analysis.Moods {
+		if _, ok := MoodKeywords[weighted.Mood]; !ok {
+			return fmt.Errorf("unknown mood %q in moods", weighted.Mood)
 		}
-		
-		if matchCount > 0 {
-			score := float64(matchCount) / float64(len(keywords))
-			if score > 0.2 { // If at least 20% of keywords match
-				return &models.MoodAnalysis{
-					PrimaryMood: mood,
-					MoodScore:   score,
-					EmotionTags: RelatedMoods[mood],
-				}
-			}
+		if weighted.Weight < 0 || weighted.Weight > 1 {
+			return fmt.Errorf("weight %v for mood %q out of range [0, 1]", weighted.Weight, weighted.Mood)
 		}
+		if _, seen := weights[weighted.Mood]; !seen {
+			order = append(order, weighted.Mood)
+		}
+		weights[weighted.Mood] += weighted.Weight
 	}
-	
-	// Default to neutral/uncertain
-	return &models.MoodAnalysis{
-		PrimaryMood: "calm",
-		MoodScore:   0.3,
-		EmotionTags: []string{"neutral", "uncertain"},
+	if _, ok := weights[analysis.PrimaryMood]; !ok {
+		// The model named a primary mood it left out of the blend; keep it as the strongest
+		strongest := 0.0
+		for _, weight := range weights {
+			strongest = math.Max(strongest, weight)
+		}
+		order = append([]string{analysis.PrimaryMood}, order...)
+		weights[analysis.PrimaryMood] = strongest
 	}
+
+	total := 0.0
+	moods := make([]models.WeightedMood, 0, len(order))
+	for _, name := range order {
+		if weights[name] > 0 || name == analysis.PrimaryMood {
+			moods = append(moods, models.WeightedMood{Mood: name, Weight: weights[name]})
+			total += weights[name]
+		}
+	}
+	if len(moods) < 2 || total == 0 {
+		analysis.Moods = nil
+		return nil
+	}
+
+	for i := range moods {
+		moods[i].Weight /= total
+	}
+	// Ties keep the declared primary mood ahead of the others
+	sort.SliceStable(moods, func(i, j int) bool {
+		if moods[i].Weight == moods[j].Weight {
+			return moods[i].Mood == analysis.PrimaryMood
+		}
+		return moods[i].Weight > moods[j].Weight
+	})
+	analysis.PrimaryMood = moods[0].Mood
+	analysis.Moods = moods
+	return nil
 }
 
 // MatchSongsToMood finds songs that match the detected mood
@@ -130,14 +200,14 @@ func (s *service) MatchSongsToMood(mood *models.MoodAnalysis, userTracks []model
 			}
 			
 			// Calculate mood match score
-			matchScore := s.calculateMoodMatch(mood, lyricsData.MoodAnalysis)
+			matchScore, matchedMood := s.calculateMoodMatch(mood, lyricsData.MoodAnalysis)
 			
 			if matchScore > 0.5 { // Only include if match score is above threshold
 				mutex.Lock()
 				recommendations = append(recommendations, models.MoodBasedRecommendation{
 					Track:       t,
 					MoodScore:   matchScore,
-					MatchReason: s.generateMatchReason(mood.PrimaryMood, lyricsData.Themes),
+					MatchReason: s.generateMatchReason(matchedMood, lyricsData.Themes),
 				})
 				mutex.Unlock()
 			}
@@ -164,17 +234,27 @@ func (s *service) MatchSongsToMood(mood *models.MoodAnalysis, userTracks []model
 	return recommendations, nil
 }
 
+// GetCachedLyricsMood returns a previously computed analysis without calling the AI service
+func (s *service) GetCachedLyricsMood(trackName, artistName string) (*LyricsWithMood, bool) {
+	s.cacheMutex.RLock()
+	defer s.cacheMutex.RUnlock()
+	
+	cached, exists := s.lyricsCache[lyricsCacheKey(trackName, artistName)]
+	return cached, exists
+}
+
+// lyricsCacheKey builds the cache key for a track's lyrics analysis
+func lyricsCacheKey(trackName, artistName string) string {
+	return fmt.Sprintf("%s-%s", strings.ToLower(trackName), strings.ToLower(artistName))
+}
+
 // GetLyricsWithMood fetches lyrics and analyzes their mood
 func (s *service) GetLyricsWithMood(trackName, artistName string) (*LyricsWithMood, error) {
 	// Check cache first
-	cacheKey := fmt.Sprintf("%s-%s", strings.ToLower(trackName), strings.ToLower(artistName))
-	
-	s.cacheMutex.RLock()
-	if cached, exists := s.lyricsCache[cacheKey]; exists {
-		s.cacheMutex.RUnlock()
+	if cached, exists := s.GetCachedLyricsMood(trackName, artistName); exists {
 		return cached, nil
 	}
-	s.cacheMutex.RUnlock()
+	cacheKey := lyricsCacheKey(trackName, artistName)
 	
 	// Fetch lyrics from Genius
 	lyrics, err := s.geniusService.GetLyrics(trackName, artistName)
@@ -182,19 +262,21 @@ func (s *service) GetLyricsWithMood(trackName, artistName string) (*LyricsWithMo
 		return nil, fmt.Errorf("failed to fetch lyrics: %w", err)
 	}
 	
+	// Condense long lyrics so the prompt fits the model's context window
+	promptLyrics := lyrics
+	if fitter, ok := s.aiService.(lyricsFitter); ok {
+		if promptLyrics, err = fitter.FitLyrics(lyrics); err != nil {
+			return nil, fmt.Errorf("failed to analyze lyrics mood: %w", err)
+		}
+	}
+	
 	// Analyze mood of lyrics
-	moodPrompt := fmt.Sprintf(`Analyze the mood and themes of these song lyrics. Return a JSON response with:
-- primary_mood: The main emotion (must be one of: sad, happy, angry, lonely, anxious, nostalgic, energetic, calm)
-- mood_score: Confidence score between 0 and 1
-- emotion_tags: Array of related emotions
-- themes: Array of main themes in the song
-
-Important: Respond ONLY with valid JSON.
-
-Lyrics:
-%s`, lyrics)
+	moodPrompt, err := s.prompts.Render(prompts.LyricsMood, prompts.LyricsMoodData{Lyrics: promptLyrics})
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze lyrics mood: %w", err)
+	}
 
-	response, err := s.aiService.GenerateResponse(moodPrompt)
+	response, err := s.aiService.GenerateJSON(moodPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze lyrics mood: %w", err)
 	}
@@ -208,21 +290,22 @@ Lyrics:
 	}
 	
 	if err := json.Unmarshal([]byte(response), &analysis); err != nil {
-		// Fallback analysis
-		analysis.PrimaryMood = "calm"
-		analysis.MoodScore = 0.5
-		analysis.EmotionTags = []string{"uncertain"}
-		analysis.Themes = []string{"general"}
+		return nil, fmt.Errorf("failed to parse lyrics mood analysis: %w", err)
+	}
+	
+	moodAnalysis := &models.MoodAnalysis{
+		PrimaryMood: analysis.PrimaryMood,
+		MoodScore:   analysis.MoodScore,
+		EmotionTags: analysis.EmotionTags,
+	}
+	if err := validateMoodAnalysis(moodAnalysis); err != nil {
+		return nil, fmt.Errorf("invalid lyrics mood analysis: %w", err)
 	}
 	
 	result := &LyricsWithMood{
-		Lyrics: lyrics,
-		MoodAnalysis: &models.MoodAnalysis{
-			PrimaryMood: analysis.PrimaryMood,
-			MoodScore:   analysis.MoodScore,
-			EmotionTags: analysis.EmotionTags,
-		},
-		Themes: analysis.Themes,
+		Lyrics:       lyrics,
+		MoodAnalysis: moodAnalysis,
+		Themes:       analysis.Themes,
 	}
 	
 	// Cache the result
@@ -233,15 +316,30 @@ Lyrics:
 	return result, nil
 }
 
-// calculateMoodMatch calculates how well two moods match
-func (s *service) calculateMoodMatch(userMood, songMood *models.MoodAnalysis) float64 {
+// calculateMoodMatch calculates how well a song matches the user's moods. A mixed
+// mood scores the song against each of its moods, scaled by that mood's weight
+// relative to the strongest one, and returns the best score with its mood.
+func (s *service) calculateMoodMatch(userMood, songMood *models.MoodAnalysis) (float64, string) {
+	moods := userMood.WeightedMoods()
+	bestScore, bestMood := 0.0, userMood.PrimaryMood
+	for _, weighted := range moods {
+		score := s.singleMoodMatch(weighted.Mood, userMood.EmotionTags, songMood) * weighted.Weight / moods[0].Weight
+		if score > bestScore {
+			bestScore, bestMood = score, weighted.Mood
+		}
+	}
+	return bestScore, bestMood
+}
+
+// singleMoodMatch calculates how well a song matches one of the user's moods
+func (s *service) singleMoodMatch(userMood string, userTags []string, songMood *models.MoodAnalysis) float64 {
 	// Direct mood match
-	if userMood.PrimaryMood == songMood.PrimaryMood {
+	if userMood == songMood.PrimaryMood {
 		return 0.9 + (songMood.MoodScore * 0.1) // 90-100% match
 	}
 	
 	// Check if moods are related
-	relatedMoods, exists := RelatedMoods[userMood.PrimaryMood]
+	relatedMoods, exists := RelatedMoods[userMood]
 	if exists {
 		for _, related := range relatedMoods {
 			if related == songMood.PrimaryMood {
@@ -252,7 +350,7 @@ func (s *service) calculateMoodMatch(userMood, songMood *models.MoodAnalysis) fl
 	
 	// Check emotion tag overlap
 	overlapCount := 0
-	for _, userTag := range userMood.EmotionTags {
+	for _, userTag := range userTags {
 		for _, songTag := range songMood.EmotionTags {
 			if userTag == songTag {
 				overlapCount++
@@ -261,7 +359,7 @@ func (s *service) calculateMoodMatch(userMood, songMood *models.MoodAnalysis) fl
 	}
 	
 	if overlapCount > 0 {
-		overlapScore := float64(overlapCount) / float64(len(userMood.EmotionTags))
+		overlapScore := float64(overlapCount) / float64(len(userTags))
 		return 0.5 + (overlapScore * 0.3) // 50-80% match based on overlap
 	}
 	
@@ -299,11 +397,14 @@ func (s *service) generateMatchReason(mood string, themes []string) string {
 
 // SaveUserMoodHistory saves user's mood and played songs to history file
 func (s *service) SaveUserMoodHistory(userID string, mood string, playedSongs []string) error {
-	historyFile := filepath.Join(s.dataDir, "mood_history", fmt.Sprintf("user_%s_mood_history.txt", userID))
+	historyFile, err := s.userFile("user_%s_mood_history.txt", userID)
+	if err != nil {
+		return err
+	}
 	
 	// Create entry
 	entry := fmt.Sprintf("%s|%s|%s\n", 
-		time.Now().Format(time.RFC3339),
+		time.Now().UTC().Format(time.RFC3339Nano), // Precise enough to identify the entry for journal notes
 		mood,
 		strings.Join(playedSongs, ","))
 	
@@ -324,9 +425,27 @@ func (s *service) SaveUserMoodHistory(userID string, mood string, playedSongs []
 	return nil
 }
 
+// ListMoodUsers returns the users who have a mood history file
+func (s *service) ListMoodUsers() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(s.dataDir, "mood_history", "user_*_mood_history.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mood history: %w", err)
+	}
+	
+	users := make([]string, 0, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), "user_"), "_mood_history.txt")
+		users = append(users, name)
+	}
+	return users, nil
+}
+
 // GetUserMoodHistory retrieves user's mood history
 func (s *service) GetUserMoodHistory(userID string) ([]UserMoodEntry, error) {
-	historyFile := filepath.Join(s.dataDir, "mood_history", fmt.Sprintf("user_%s_mood_history.txt", userID))
+	historyFile, err := s.userFile("user_%s_mood_history.txt", userID)
+	if err != nil {
+		return nil, err
+	}
 	
 	// Check if file exists
 	if _, err := os.Stat(historyFile); os.IsNotExist(err) {
@@ -365,9 +484,98 @@ func (s *service) GetUserMoodHistory(userID string) ([]UserMoodEntry, error) {
 		})
 	}
 	
+	notes, err := s.readMoodNotes(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Note = notes[entries[i].Timestamp]
+	}
+	
 	return entries, nil
 }
 
+// moodJournalFile returns the file holding a user's journal notes. It sits
+// next to the mood history, and its lines start with the entry's timestamp so
+// retention expires notes along with their entries.
+func (s *service) moodJournalFile(userID string) (string, error) {
+	return s.userFile("user_%s_mood_journal.txt", userID)
+}
+
+// userFile returns the file in mood_history that pattern names for a user.
+// The user ID comes from a request header, so IDs that would name a file
+// anywhere else are rejected.
+func (s *service) userFile(pattern, userID string) (string, error) {
+	if strings.ContainsAny(userID, `/\`) || filepath.Base(userID) != userID {
+		return "", ErrInvalidUserID
+	}
+	return filepath.Join(s.dataDir, "mood_history", fmt.Sprintf(pattern, userID)), nil
+}
+
+// AddMoodNote attaches a journal note to the mood history entry saved at
+// entryTimestamp, or to the latest one if it is empty. A later note on the
+// same entry replaces the earlier one.
+func (s *service) AddMoodNote(userID, entryTimestamp, note string) (string, error) {
+	entries, err := s.GetUserMoodHistory(userID)
+	if err != nil {
+		return "", err
+	}
+	
+	found := false
+	for i := len(entries) - 1; i >= 0 && !found; i-- {
+		if entryTimestamp == "" || entries[i].Timestamp == entryTimestamp {
+			entryTimestamp, found = entries[i].Timestamp, true
+		}
+	}
+	if !found {
+		return "", ErrMoodEntryNotFound
+	}
+	
+	// Quoting keeps notes with newlines on one line
+	journalFile, err := s.moodJournalFile(userID)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(journalFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to open journal file: %w", err)
+	}
+	defer f.Close()
+	
+	if _, err := fmt.Fprintf(f, "%s|%s\n", entryTimestamp, strconv.Quote(note)); err != nil {
+		return "", fmt.Errorf("failed to write journal note: %w", err)
+	}
+	return entryTimestamp, nil
+}
+
+// readMoodNotes returns the latest journal note on each of the user's mood
+// entries, by entry timestamp
+func (s *service) readMoodNotes(userID string) (map[string]string, error) {
+	notes := make(map[string]string)
+	journalFile, err := s.moodJournalFile(userID)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(journalFile)
+	if os.IsNotExist(err) {
+		return notes, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	
+	for _, line := range strings.Split(string(content), "\n") {
+		timestamp, quoted, found := strings.Cut(line, "|")
+		if !found {
+			continue
+		}
+		if note, err := strconv.Unquote(quoted); err == nil {
+			notes[timestamp] = note
+		}
+	}
+	return notes, nil
+}
+
 // compactHistoryIfNeeded compacts history file if it's older than 30 days
 func (s *service) compactHistoryIfNeeded(historyFile string) {
 	// Implementation for 30-day compaction would go here
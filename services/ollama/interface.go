@@ -1,5 +1,7 @@
 package ollama
 
+import "context"
+
 // Service defines the interface for Ollama/AI operations
 type Service interface {
 	// AnalyzeLyrics analyzes lyrics based on a user query
@@ -8,6 +10,14 @@ type Service interface {
 	// GenerateResponse generates a general response without lyrics context
 	GenerateResponse(prompt string) (string, error)
 	
+	// GenerateJSON generates a response constrained to valid JSON
+	GenerateJSON(prompt string) (string, error)
+	
+	// GenerateStream generates a response, calling onChunk for each partial
+	// piece as it arrives. Generation stops early when ctx is cancelled or
+	// onChunk returns an error.
+	GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error
+	
 	// IsAvailable checks if the Ollama service is available
 	IsAvailable() error
 }
\ No newline at end of file
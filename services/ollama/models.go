@@ -5,6 +5,7 @@ type Request struct {
 	Model   string                 `json:"model"`
 	Prompt  string                 `json:"prompt"`
 	Stream  bool                   `json:"stream"`
+	Format  string                 `json:"format,omitempty"` // "json" enables JSON mode
 	Options map[string]interface{} `json:"options,omitempty"`
 }
 
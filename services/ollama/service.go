@@ -1,7 +1,10 @@
 package ollama
 
 import (
+	"backend/services/prompts"
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -16,6 +19,9 @@ type Config struct {
 	Temperature float64
 	TopP        float64
 	TopK        int
+
+	// Prompts renders the lyrics analysis prompt; nil uses the built-in templates
+	Prompts prompts.Service
 }
 
 // DefaultConfig returns a default configuration
@@ -33,15 +39,21 @@ func DefaultConfig() Config {
 type service struct {
 	config     Config
 	httpClient *http.Client
+	// streamClient has no overall timeout; streams are bounded by their context
+	streamClient *http.Client
 }
 
 // New creates a new Ollama service
 func New(config Config) Service {
+	if config.Prompts == nil {
+		config.Prompts = prompts.Default()
+	}
 	return &service{
 		config: config,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		streamClient: &http.Client{},
 	}
 }
 
@@ -62,7 +74,10 @@ func (s *service) IsAvailable() error {
 
 // AnalyzeLyrics analyzes lyrics based on a user query
 func (s *service) AnalyzeLyrics(query, lyrics, songInfo string) (string, error) {
-	prompt := s.buildLyricsPrompt(query, lyrics, songInfo)
+	prompt, err := s.buildLyricsPrompt(query, lyrics, songInfo)
+	if err != nil {
+		return "", err
+	}
 	return s.generate(prompt)
 }
 
@@ -71,27 +86,110 @@ func (s *service) GenerateResponse(prompt string) (string, error) {
 	return s.generate(prompt)
 }
 
-// buildLyricsPrompt creates a prompt for lyrics analysis
-func (s *service) buildLyricsPrompt(query, lyrics, songInfo string) string {
-	return fmt.Sprintf(`You are analyzing "%s". Answer in EXACTLY 2 short paragraphs only. Be concise.
+// GenerateJSON generates a response using Ollama's JSON format mode
+func (s *service) GenerateJSON(prompt string) (string, error) {
+	return s.generateWithFormat(prompt, "json")
+}
 
-Question: %s
+// GenerateStream generates a response, calling onChunk for each partial piece
+func (s *service) GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error {
+	req := Request{
+		Model:   s.config.Model,
+		Prompt:  prompt,
+		Stream:  true,
+		Options: s.options(),
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", s.config.BaseURL+"/api/generate", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.streamClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
 
-Keep it brief - maximum 4-5 sentences per paragraph. Focus only on the most important points.`, songInfo, query)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ollama API failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	// Ollama streams one JSON object per line
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var chunk Response
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("ollama error: %s", chunk.Error)
+		}
+
+		if chunk.Response != "" {
+			if err := onChunk(chunk.Response); err != nil {
+				return err
+			}
+		}
+		if chunk.Done {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	return nil
+}
+
+// options returns the sampling options sent with every request
+func (s *service) options() map[string]interface{} {
+	return map[string]interface{}{
+		"temperature": s.config.Temperature,
+		"top_p":       s.config.TopP,
+		"top_k":       s.config.TopK,
+	}
+}
+
+// buildLyricsPrompt creates a prompt for lyrics analysis
+func (s *service) buildLyricsPrompt(query, lyrics, songInfo string) (string, error) {
+	return s.config.Prompts.Render(prompts.LyricsAnalysis, prompts.LyricsAnalysisData{
+		SongInfo: songInfo,
+		Query:    query,
+		Lyrics:   lyrics,
+	})
 }
 
 // generate sends a request to Ollama and returns the response
 func (s *service) generate(prompt string) (string, error) {
+	return s.generateWithFormat(prompt, "")
+}
+
+// generateWithFormat sends a request to Ollama with an optional output format
+func (s *service) generateWithFormat(prompt, format string) (string, error) {
 	// Prepare the request
 	req := Request{
 		Model:  s.config.Model,
 		Prompt: prompt,
 		Stream: false,
-		Options: map[string]interface{}{
-			"temperature": s.config.Temperature,
-			"top_p":       s.config.TopP,
-			"top_k":       s.config.TopK,
-		},
+		Format: format,
+		Options: s.options(),
 	}
 
 	reqBody, err := json.Marshal(req)
@@ -8,6 +8,12 @@ type Service interface {
 	// GenerateResponse generates a general response without lyrics context
 	GenerateResponse(prompt string) (string, error)
 	
+	// GenerateJSON generates a response constrained to a single JSON object
+	GenerateJSON(prompt string) (string, error)
+	
+	// WithUsageHook returns a copy of the service that reports token usage to hook
+	WithUsageHook(hook func(Usage)) Service
+	
 	// IsAvailable checks if the OpenAI service is available
 	IsAvailable() error
 }
\ No newline at end of file
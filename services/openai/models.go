@@ -8,6 +8,13 @@ type ChatCompletionRequest struct {
 	Temperature *float64 `json:"temperature,omitempty"`
 	MaxTokens   *int     `json:"max_tokens,omitempty"`
 	TopP        *float64 `json:"top_p,omitempty"`
+	// ResponseFormat constrains the output, e.g. {"type": "json_object"}
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat represents the response_format request parameter
+type ResponseFormat struct {
+	Type string `json:"type"` // "text" or "json_object"
 }
 
 // Message represents a chat message
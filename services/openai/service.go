@@ -1,14 +1,25 @@
 package openai
 
 import (
+	"backend/services/prompts"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// API types supported by the service
+const (
+	APITypeOpenAI = "openai"
+	APITypeAzure  = "azure"
+)
+
 // Config holds OpenAI service configuration
 type Config struct {
 	APIKey      string
@@ -17,6 +28,22 @@ type Config struct {
 	Temperature float64
 	MaxTokens   int
 	TopP        float64
+
+	// Azure OpenAI settings, used when APIType is APITypeAzure. BaseURL is then
+	// the resource endpoint, e.g. https://my-resource.openai.azure.com
+	APIType    string
+	Deployment string
+	APIVersion string
+
+	// Retries for rate limits (429), server errors (5xx) and network failures.
+	// Delays grow exponentially from RetryBaseDelay up to RetryMaxDelay with
+	// jitter; a Retry-After header from the API takes precedence.
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// Prompts renders the lyrics analysis prompt; nil uses the built-in templates
+	Prompts prompts.Service
 }
 
 // DefaultConfig returns a default configuration for OpenAI
@@ -27,6 +54,11 @@ func DefaultConfig() Config {
 		Temperature: 0.7,
 		MaxTokens:   500,
 		TopP:        0.9,
+		APIType:     APITypeOpenAI,
+
+		MaxRetries:     3,
+		RetryBaseDelay: 500 * time.Millisecond,
+		RetryMaxDelay:  8 * time.Second,
 	}
 }
 
@@ -34,10 +66,14 @@ func DefaultConfig() Config {
 type service struct {
 	config     Config
 	httpClient *http.Client
+	onUsage    func(Usage) // Optional; called with the token usage of each completion
 }
 
 // New creates a new OpenAI service
 func New(config Config) Service {
+	if config.Prompts == nil {
+		config.Prompts = prompts.Default()
+	}
 	return &service{
 		config: config,
 		httpClient: &http.Client{
@@ -46,11 +82,24 @@ func New(config Config) Service {
 	}
 }
 
+// WithUsageHook returns a copy of the service that reports the token usage of
+// every completion to hook. The copy shares the underlying HTTP client.
+func (s *service) WithUsageHook(hook func(Usage)) Service {
+	return &service{
+		config:     s.config,
+		httpClient: s.httpClient,
+		onUsage:    hook,
+	}
+}
+
 // IsAvailable checks if the OpenAI service is available
 func (s *service) IsAvailable() error {
 	if s.config.APIKey == "" {
 		return fmt.Errorf("OpenAI API key not provided")
 	}
+	if s.config.APIType == APITypeAzure && s.config.Deployment == "" {
+		return fmt.Errorf("Azure OpenAI deployment name not provided")
+	}
 	
 	// Test with a simple request
 	req := ChatCompletionRequest{
@@ -71,7 +120,10 @@ func (s *service) IsAvailable() error {
 
 // AnalyzeLyrics analyzes lyrics based on a user query
 func (s *service) AnalyzeLyrics(query, lyrics, songInfo string) (string, error) {
-	prompt := s.buildLyricsPrompt(query, lyrics, songInfo)
+	prompt, err := s.buildLyricsPrompt(query, lyrics, songInfo)
+	if err != nil {
+		return "", err
+	}
 	return s.generate(prompt)
 }
 
@@ -80,25 +132,36 @@ func (s *service) GenerateResponse(prompt string) (string, error) {
 	return s.generate(prompt)
 }
 
-// buildLyricsPrompt creates a prompt for lyrics analysis
-func (s *service) buildLyricsPrompt(query, lyrics, songInfo string) string {
-	return fmt.Sprintf(`You are analyzing "%s". Answer in EXACTLY 2 short paragraphs only. Be concise.
-
-Question: %s
+// GenerateJSON generates a response using OpenAI's JSON mode
+func (s *service) GenerateJSON(prompt string) (string, error) {
+	return s.generateWithFormat(prompt, &ResponseFormat{Type: "json_object"})
+}
 
-Keep it brief - maximum 4-5 sentences per paragraph. Focus only on the most important points.`, songInfo, query)
+// buildLyricsPrompt creates a prompt for lyrics analysis
+func (s *service) buildLyricsPrompt(query, lyrics, songInfo string) (string, error) {
+	return s.config.Prompts.Render(prompts.LyricsAnalysis, prompts.LyricsAnalysisData{
+		SongInfo: songInfo,
+		Query:    query,
+		Lyrics:   lyrics,
+	})
 }
 
 // generate sends a request to OpenAI and returns the response
 func (s *service) generate(prompt string) (string, error) {
+	return s.generateWithFormat(prompt, nil)
+}
+
+// generateWithFormat sends a request to OpenAI with an optional response format
+func (s *service) generateWithFormat(prompt string, format *ResponseFormat) (string, error) {
 	req := ChatCompletionRequest{
 		Model: s.config.Model,
 		Messages: []Message{
 			{Role: "user", Content: prompt},
 		},
-		Temperature: &s.config.Temperature,
-		MaxTokens:   &s.config.MaxTokens,
-		TopP:        &s.config.TopP,
+		Temperature:    &s.config.Temperature,
+		MaxTokens:      &s.config.MaxTokens,
+		TopP:           &s.config.TopP,
+		ResponseFormat: format,
 	}
 	
 	resp, err := s.makeRequest(req)
@@ -106,6 +169,10 @@ func (s *service) generate(prompt string) (string, error) {
 		return "", err
 	}
 	
+	if s.onUsage != nil {
+		s.onUsage(resp.Usage)
+	}
+	
 	if len(resp.Choices) == 0 {
 		return "", fmt.Errorf("no response choices returned from OpenAI")
 	}
@@ -113,49 +180,122 @@ func (s *service) generate(prompt string) (string, error) {
 	return resp.Choices[0].Message.Content, nil
 }
 
-// makeRequest sends a request to OpenAI API
+// completionsURL returns the chat completions endpoint. Azure routes requests
+// by deployment name and requires an api-version query parameter.
+func (s *service) completionsURL() string {
+	if s.config.APIType == APITypeAzure {
+		return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+			strings.TrimSuffix(s.config.BaseURL, "/"),
+			url.PathEscape(s.config.Deployment),
+			url.QueryEscape(s.config.APIVersion),
+		)
+	}
+	return s.config.BaseURL + "/chat/completions"
+}
+
+// makeRequest sends a request to OpenAI API, retrying transient failures
 func (s *service) makeRequest(req ChatCompletionRequest) (*ChatCompletionResponse, error) {
 	reqBody, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 	
-	httpReq, err := http.NewRequest("POST", s.config.BaseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	for attempt := 0; ; attempt++ {
+		resp, retryAfter, err := s.send(reqBody)
+		if err == nil || retryAfter < 0 || attempt >= s.config.MaxRetries {
+			return resp, err
+		}
+		
+		delay := retryAfter
+		if delay == 0 {
+			delay = s.backoff(attempt)
+		}
+		time.Sleep(delay)
+	}
+}
+
+// backoff returns the delay before retry attempt+1: exponential growth capped
+// at RetryMaxDelay, with jitter so concurrent clients don't retry in lockstep
+func (s *service) backoff(attempt int) time.Duration {
+	delay := s.config.RetryBaseDelay << attempt
+	if delay <= 0 || (s.config.RetryMaxDelay > 0 && delay > s.config.RetryMaxDelay) {
+		delay = s.config.RetryMaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// send performs a single request. On failure it also returns how long to wait
+// before retrying: the Retry-After delay, 0 to use backoff, or -1 if the error
+// is permanent.
+func (s *service) send(reqBody []byte) (*ChatCompletionResponse, time.Duration, error) {
+	httpReq, err := http.NewRequest("POST", s.completionsURL(), bytes.NewBuffer(reqBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, -1, fmt.Errorf("failed to create request: %w", err)
 	}
 	
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.APIKey))
+	if s.config.APIType == APITypeAzure {
+		httpReq.Header.Set("api-key", s.config.APIKey)
+	} else {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.APIKey))
+	}
 	
 	// Send request
 	httpResp, err := s.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request to OpenAI: %w", err)
+		return nil, 0, fmt.Errorf("failed to send request to OpenAI: %w", err)
 	}
 	defer httpResp.Body.Close()
 	
+	retryAfter := time.Duration(-1)
+	if httpResp.StatusCode == http.StatusTooManyRequests || httpResp.StatusCode >= 500 {
+		retryAfter = parseRetryAfter(httpResp.Header.Get("Retry-After"))
+	}
+	
 	// Read response body
 	body, err := io.ReadAll(httpResp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, retryAfter, fmt.Errorf("failed to read response: %w", err)
 	}
 	
 	// Parse response
 	var openaiResp ChatCompletionResponse
 	if err := json.Unmarshal(body, &openaiResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		if httpResp.StatusCode != http.StatusOK {
+			return nil, retryAfter, fmt.Errorf("OpenAI API failed with status %d: %s", httpResp.StatusCode, string(body))
+		}
+		return nil, -1, fmt.Errorf("failed to decode response: %w", err)
 	}
 	
 	// Check for API errors
 	if openaiResp.Error != nil {
-		return nil, fmt.Errorf("OpenAI API error: %s", openaiResp.Error.Message)
+		return nil, retryAfter, fmt.Errorf("OpenAI API error: %s", openaiResp.Error.Message)
 	}
 	
 	if httpResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OpenAI API failed with status %d: %s", httpResp.StatusCode, string(body))
+		return nil, retryAfter, fmt.Errorf("OpenAI API failed with status %d: %s", httpResp.StatusCode, string(body))
 	}
 	
-	return &openaiResp, nil
-}
\ No newline at end of file
+	return &openaiResp, -1, nil
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP
+// date. It returns 0 if the header is missing or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
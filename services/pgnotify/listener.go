@@ -0,0 +1,101 @@
+package pgnotify
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// minReconnect and maxReconnect bound the wait between reconnection
+	// attempts, which doubles after each failure
+	minReconnect = time.Second
+	maxReconnect = time.Minute
+	// pingInterval checks an idle connection is still alive, so a dropped one
+	// is noticed and reconnected
+	pingInterval = 90 * time.Second
+)
+
+// Handler processes the payload of a notification
+type Handler func(payload string)
+
+// Listener delivers Postgres notifications (NOTIFY) to handlers. It holds one
+// connection of its own, outside the database pool, and reconnects after
+// failures; notifications sent while it is disconnected are lost.
+type Listener struct {
+	listener *pq.Listener
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New opens a listener on the database at dsn, a connection string or
+// postgres:// URL
+func New(dsn string) *Listener {
+	l := &Listener{
+		handlers: make(map[string]Handler),
+		done:     make(chan struct{}),
+	}
+	l.listener = pq.NewListener(dsn, minReconnect, maxReconnect, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			log.Printf("Postgres listener disconnected: %v", err)
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Printf("Postgres listener failed to reconnect: %v", err)
+		case pq.ListenerEventReconnected:
+			log.Printf("Postgres listener reconnected; notifications sent while disconnected were missed")
+		}
+	})
+
+	l.wg.Add(1)
+	go l.run()
+	return l
+}
+
+// Listen calls handler with the payload of every notification on channel,
+// one at a time and in the order they were sent. It blocks until the
+// database acknowledges the LISTEN.
+func (l *Listener) Listen(channel string, handler Handler) error {
+	l.mu.Lock()
+	l.handlers[channel] = handler
+	l.mu.Unlock()
+	return l.listener.Listen(channel)
+}
+
+// Close stops listening and waits for the notification being handled
+func (l *Listener) Close() error {
+	close(l.done)
+	err := l.listener.Close()
+	l.wg.Wait()
+	return err
+}
+
+// run dispatches notifications to their channel's handler until closed
+func (l *Listener) run() {
+	defer l.wg.Done()
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case n := <-l.listener.Notify:
+			if n == nil {
+				// Sent after reconnecting
+				continue
+			}
+			l.mu.RLock()
+			handler := l.handlers[n.Channel]
+			l.mu.RUnlock()
+			if handler != nil {
+				handler(n.Extra)
+			}
+		case <-ping.C:
+			go l.listener.Ping()
+		}
+	}
+}
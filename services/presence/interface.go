@@ -0,0 +1,18 @@
+package presence
+
+import "backend/server/models"
+
+// Service tracks which users are online, from their heartbeats and open
+// WebSocket connections. Nothing is persisted.
+type Service interface {
+	// Heartbeat marks userID online until the timeout passes without another
+	// heartbeat. The track is kept only if the user shares their listening.
+	Heartbeat(userID string, heartbeat models.PresenceHeartbeat)
+
+	// Connect marks userID online until the returned function is called, as
+	// long as no other connection of theirs is open
+	Connect(userID string) (disconnect func())
+
+	// Online returns the users online, most recently seen first
+	Online() []models.Presence
+}
@@ -0,0 +1,133 @@
+package presence
+
+import (
+	"backend/server/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Config holds presence configuration
+type Config struct {
+	Timeout time.Duration // How long a heartbeat keeps a user online
+}
+
+// DefaultConfig returns a default configuration for presence
+func DefaultConfig() Config {
+	return Config{
+		Timeout: 60 * time.Second,
+	}
+}
+
+// user is what the service knows of one user
+type user struct {
+	username      string
+	track         *models.PresenceTrack // Set while they share what they play
+	lastHeartbeat time.Time
+	lastSeen      time.Time
+	connections   int
+}
+
+// service implements the presence Service interface
+type service struct {
+	config Config
+	users  map[string]*user
+	mutex  sync.Mutex
+}
+
+// New creates a new presence service
+func New(config Config) Service {
+	return &service{
+		config: config,
+		users:  make(map[string]*user),
+	}
+}
+
+// Heartbeat marks userID online until the timeout passes
+func (s *service) Heartbeat(userID string, heartbeat models.PresenceHeartbeat) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	u := s.user(userID)
+	now := time.Now()
+	u.lastHeartbeat = now
+	u.lastSeen = now
+	if heartbeat.Username != "" {
+		u.username = heartbeat.Username
+	}
+	u.track = nil
+	if heartbeat.ShareListening && heartbeat.NowPlaying != nil {
+		track := *heartbeat.NowPlaying
+		u.track = &track
+	}
+}
+
+// Connect marks userID online until the returned function is called
+func (s *service) Connect(userID string) func() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	u := s.user(userID)
+	u.connections++
+	u.lastSeen = time.Now()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			u.connections--
+			u.lastSeen = time.Now()
+		})
+	}
+}
+
+// Online returns the users online, most recently seen first. Users who went
+// offline are forgotten.
+func (s *service) Online() []models.Presence {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	online := []models.Presence{}
+	for userID, u := range s.users {
+		heartbeating := now.Sub(u.lastHeartbeat) < s.config.Timeout
+		if !heartbeating && u.connections == 0 {
+			delete(s.users, userID)
+			continue
+		}
+
+		presence := models.Presence{
+			UserID:   userID,
+			Username: u.username,
+			LastSeen: u.lastSeen,
+		}
+		if u.connections > 0 {
+			presence.LastSeen = now
+		}
+		// A track is only as fresh as the heartbeat reporting it
+		if heartbeating && u.track != nil {
+			track := *u.track
+			presence.NowPlaying = &track
+		}
+		online = append(online, presence)
+	}
+
+	sort.Slice(online, func(i, j int) bool {
+		if !online[i].LastSeen.Equal(online[j].LastSeen) {
+			return online[i].LastSeen.After(online[j].LastSeen)
+		}
+		return online[i].UserID < online[j].UserID
+	})
+	return online
+}
+
+// user returns the entry for userID, creating it. The mutex must be held.
+func (s *service) user(userID string) *user {
+	u, ok := s.users[userID]
+	if !ok {
+		u = &user{}
+		s.users[userID] = u
+	}
+	return u
+}
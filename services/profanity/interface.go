@@ -0,0 +1,10 @@
+package profanity
+
+// Filter finds and masks profane words in text
+type Filter interface {
+	// Mask replaces profane words with asterisks, keeping their first letter
+	Mask(text string) string
+
+	// Contains reports whether text has a profane word
+	Contains(text string) bool
+}
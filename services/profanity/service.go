@@ -0,0 +1,50 @@
+package profanity
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Words is the built-in list of masked words
+var Words = []string{
+	"fuck", "fucking", "fucked", "motherfucker", "shit", "shitty", "bitch", "bitches",
+	"bastard", "asshole", "dick", "cunt", "pussy", "slut", "whore", "damn", "goddamn",
+	"nigga", "nigger", "faggot", "cock",
+}
+
+// Config holds profanity filter configuration
+type Config struct {
+	Words []string // Words masked on top of the built-in list
+}
+
+// filter implements Filter with one case-insensitive whole-word pattern
+type filter struct {
+	pattern *regexp.Regexp
+}
+
+// New creates a filter masking the built-in words and the configured ones
+func New(config Config) Filter {
+	words := make([]string, 0, len(Words)+len(config.Words))
+	for _, word := range append(append([]string{}, Words...), config.Words...) {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, regexp.QuoteMeta(word))
+		}
+	}
+	return &filter{
+		pattern: regexp.MustCompile(`(?i)\b(` + strings.Join(words, "|") + `)\b`),
+	}
+}
+
+// Mask replaces profane words with asterisks, keeping their first letter
+func (f *filter) Mask(text string) string {
+	return f.pattern.ReplaceAllStringFunc(text, func(word string) string {
+		_, size := utf8.DecodeRuneInString(word)
+		return word[:size] + strings.Repeat("*", utf8.RuneCountInString(word)-1)
+	})
+}
+
+// Contains reports whether text has a profane word
+func (f *filter) Contains(text string) bool {
+	return f.pattern.MatchString(text)
+}
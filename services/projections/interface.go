@@ -0,0 +1,36 @@
+package projections
+
+import (
+	"backend/server/models"
+	"backend/services/events"
+)
+
+// Delta is an increment to one user's aggregates for one day
+type Delta struct {
+	UserID                string
+	Day                   string // UTC date, e.g. 2024-05-01
+	TracksPlayed          int
+	MessagesPosted        int
+	MoodsDetected         int
+	RecommendationsServed int
+	Mood                  string // Detected mood to count, if any
+}
+
+// Store persists daily per-user aggregates
+type Store interface {
+	// Apply adds a delta to the user's aggregates for the day
+	Apply(delta Delta) error
+
+	// DailyStats returns the user's aggregates for days in [fromDay, toDay], oldest first
+	DailyStats(userID, fromDay, toDay string) ([]models.DailyUserStats, error)
+}
+
+// Service maintains read-model projections from bus events so stats
+// requests never scan raw history
+type Service interface {
+	// Register subscribes the projections to the event bus
+	Register(bus events.Bus)
+
+	// UserStats returns the user's aggregates for the last days days, including today
+	UserStats(userID string, days int) (models.UserStats, error)
+}
@@ -0,0 +1,66 @@
+package projections
+
+import (
+	"backend/server/models"
+	"sort"
+	"sync"
+)
+
+// memoryStore keeps aggregates in memory, for development without a database and tests
+type memoryStore struct {
+	stats map[string]*models.DailyUserStats // Keyed by user ID and day
+	mutex sync.RWMutex
+}
+
+// NewMemoryStore creates an in-memory Store
+func NewMemoryStore() Store {
+	return &memoryStore{
+		stats: make(map[string]*models.DailyUserStats),
+	}
+}
+
+// Apply adds a delta to the user's aggregates for the day
+func (m *memoryStore) Apply(delta Delta) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := delta.UserID + "|" + delta.Day
+	stats, ok := m.stats[key]
+	if !ok {
+		stats = &models.DailyUserStats{UserID: delta.UserID, Day: delta.Day, Moods: map[string]int{}}
+		m.stats[key] = stats
+	}
+
+	stats.TracksPlayed += delta.TracksPlayed
+	stats.MessagesPosted += delta.MessagesPosted
+	stats.MoodsDetected += delta.MoodsDetected
+	stats.RecommendationsServed += delta.RecommendationsServed
+	if delta.Mood != "" {
+		stats.Moods[delta.Mood]++
+	}
+	return nil
+}
+
+// DailyStats returns the user's aggregates for days in [fromDay, toDay], oldest first
+func (m *memoryStore) DailyStats(userID, fromDay, toDay string) ([]models.DailyUserStats, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	daily := []models.DailyUserStats{}
+	for _, stats := range m.stats {
+		if stats.UserID != userID || stats.Day < fromDay || stats.Day > toDay {
+			continue
+		}
+		copied := *stats
+		copied.Moods = make(map[string]int, len(stats.Moods))
+		for mood, count := range stats.Moods {
+			copied.Moods[mood] = count
+		}
+		daily = append(daily, copied)
+	}
+
+	sort.Slice(daily, func(i, j int) bool {
+		return daily[i].Day < daily[j].Day
+	})
+	return daily, nil
+}
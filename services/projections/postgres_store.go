@@ -0,0 +1,123 @@
+package projections
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"fmt"
+)
+
+// Schema creates the projection tables
+const Schema = `
+        CREATE TABLE IF NOT EXISTS daily_user_stats (
+            user_id VARCHAR(255) NOT NULL,
+            day DATE NOT NULL,
+            tracks_played INTEGER NOT NULL DEFAULT 0,
+            messages_posted INTEGER NOT NULL DEFAULT 0,
+            moods_detected INTEGER NOT NULL DEFAULT 0,
+            recommendations_served INTEGER NOT NULL DEFAULT 0,
+            PRIMARY KEY (user_id, day)
+        );
+
+        CREATE TABLE IF NOT EXISTS daily_user_moods (
+            user_id VARCHAR(255) NOT NULL,
+            day DATE NOT NULL,
+            mood VARCHAR(50) NOT NULL,
+            count INTEGER NOT NULL DEFAULT 0,
+            PRIMARY KEY (user_id, day, mood)
+        );
+    `
+
+// postgresStore keeps aggregates in PostgreSQL projection tables
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a Store backed by the projection tables in Schema
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+// Apply adds a delta to the user's aggregates for the day
+func (p *postgresStore) Apply(delta Delta) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin projection update: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+        INSERT INTO daily_user_stats (user_id, day, tracks_played, messages_posted, moods_detected, recommendations_served)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (user_id, day) DO UPDATE SET
+            tracks_played = daily_user_stats.tracks_played + EXCLUDED.tracks_played,
+            messages_posted = daily_user_stats.messages_posted + EXCLUDED.messages_posted,
+            moods_detected = daily_user_stats.moods_detected + EXCLUDED.moods_detected,
+            recommendations_served = daily_user_stats.recommendations_served + EXCLUDED.recommendations_served
+    `, delta.UserID, delta.Day, delta.TracksPlayed, delta.MessagesPosted, delta.MoodsDetected, delta.RecommendationsServed)
+	if err != nil {
+		return fmt.Errorf("failed to update daily stats: %w", err)
+	}
+
+	if delta.Mood != "" {
+		_, err = tx.Exec(`
+            INSERT INTO daily_user_moods (user_id, day, mood, count)
+            VALUES ($1, $2, $3, 1)
+            ON CONFLICT (user_id, day, mood) DO UPDATE SET count = daily_user_moods.count + 1
+        `, delta.UserID, delta.Day, delta.Mood)
+		if err != nil {
+			return fmt.Errorf("failed to update daily moods: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DailyStats returns the user's aggregates for days in [fromDay, toDay], oldest first
+func (p *postgresStore) DailyStats(userID, fromDay, toDay string) ([]models.DailyUserStats, error) {
+	rows, err := p.db.Query(`
+        SELECT to_char(day, 'YYYY-MM-DD'), tracks_played, messages_posted, moods_detected, recommendations_served
+        FROM daily_user_stats
+        WHERE user_id = $1 AND day BETWEEN $2 AND $3
+        ORDER BY day ASC
+    `, userID, fromDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily stats: %w", err)
+	}
+	defer rows.Close()
+
+	daily := []models.DailyUserStats{}
+	byDay := make(map[string]int)
+	for rows.Next() {
+		stats := models.DailyUserStats{UserID: userID, Moods: map[string]int{}}
+		if err := rows.Scan(&stats.Day, &stats.TracksPlayed, &stats.MessagesPosted, &stats.MoodsDetected, &stats.RecommendationsServed); err != nil {
+			return nil, fmt.Errorf("failed to scan daily stats: %w", err)
+		}
+		byDay[stats.Day] = len(daily)
+		daily = append(daily, stats)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read daily stats: %w", err)
+	}
+
+	moodRows, err := p.db.Query(`
+        SELECT to_char(day, 'YYYY-MM-DD'), mood, count
+        FROM daily_user_moods
+        WHERE user_id = $1 AND day BETWEEN $2 AND $3
+    `, userID, fromDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily moods: %w", err)
+	}
+	defer moodRows.Close()
+
+	for moodRows.Next() {
+		var day, mood string
+		var count int
+		if err := moodRows.Scan(&day, &mood, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan daily moods: %w", err)
+		}
+		if i, ok := byDay[day]; ok {
+			daily[i].Moods[mood] = count
+		}
+	}
+	return daily, moodRows.Err()
+}
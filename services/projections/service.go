@@ -0,0 +1,115 @@
+package projections
+
+import (
+	"backend/server/models"
+	"backend/services/events"
+	"time"
+)
+
+// dayFormat is the layout of Delta.Day and DailyUserStats.Day
+const dayFormat = "2006-01-02"
+
+// Config holds projection configuration
+type Config struct {
+	// DefaultUserID receives events that don't identify a user, such as
+	// track changes from the shared now-playing state
+	DefaultUserID string
+}
+
+// DefaultConfig returns a default configuration for projections
+func DefaultConfig() Config {
+	return Config{
+		DefaultUserID: "default_user",
+	}
+}
+
+// service implements the projections Service interface
+type service struct {
+	store  Store
+	config Config
+}
+
+// New creates a projection service backed by store
+func New(store Store, config Config) Service {
+	return &service{
+		store:  store,
+		config: config,
+	}
+}
+
+// Register subscribes the projections to the event bus
+func (s *service) Register(bus events.Bus) {
+	bus.Subscribe(events.TrackChanged, "stats-projection", func(event events.Event) error {
+		return s.store.Apply(Delta{
+			UserID:       s.config.DefaultUserID,
+			Day:          day(event.Time),
+			TracksPlayed: 1,
+		})
+	})
+
+	bus.Subscribe(events.MessagePosted, "stats-projection", func(event events.Event) error {
+		message := event.Payload.(models.Message)
+		return s.store.Apply(Delta{
+			UserID:         s.userID(message.UserEmail),
+			Day:            day(event.Time),
+			MessagesPosted: 1,
+		})
+	})
+
+	bus.Subscribe(events.MoodDetected, "stats-projection", func(event events.Event) error {
+		payload := event.Payload.(events.MoodDetectedPayload)
+		return s.store.Apply(Delta{
+			UserID:        s.userID(payload.UserID),
+			Day:           day(event.Time),
+			MoodsDetected: 1,
+			Mood:          payload.Analysis.PrimaryMood,
+		})
+	})
+
+	bus.Subscribe(events.RecommendationServed, "stats-projection", func(event events.Event) error {
+		payload := event.Payload.(events.RecommendationServedPayload)
+		return s.store.Apply(Delta{
+			UserID:                s.userID(payload.UserID),
+			Day:                   day(event.Time),
+			RecommendationsServed: len(payload.Tracks),
+		})
+	})
+}
+
+// UserStats returns the user's aggregates for the last days days, including today
+func (s *service) UserStats(userID string, days int) (models.UserStats, error) {
+	now := time.Now().UTC()
+	daily, err := s.store.DailyStats(userID, day(now.AddDate(0, 0, -(days-1))), day(now))
+	if err != nil {
+		return models.UserStats{}, err
+	}
+
+	stats := models.UserStats{
+		UserID: userID,
+		Days:   daily,
+		Total:  models.DailyUserStats{UserID: userID, Moods: map[string]int{}},
+	}
+	for _, d := range daily {
+		stats.Total.TracksPlayed += d.TracksPlayed
+		stats.Total.MessagesPosted += d.MessagesPosted
+		stats.Total.MoodsDetected += d.MoodsDetected
+		stats.Total.RecommendationsServed += d.RecommendationsServed
+		for mood, count := range d.Moods {
+			stats.Total.Moods[mood] += count
+		}
+	}
+	return stats, nil
+}
+
+// userID falls back to the default user for anonymous events
+func (s *service) userID(userID string) string {
+	if userID == "" {
+		return s.config.DefaultUserID
+	}
+	return userID
+}
+
+// day formats a time as a UTC date
+func day(t time.Time) string {
+	return t.UTC().Format(dayFormat)
+}
@@ -0,0 +1,122 @@
+package prompts
+
+import "errors"
+
+// ErrUnknownTemplate is returned for template names without a built-in template
+var ErrUnknownTemplate = errors.New("unknown prompt template")
+
+// Template names
+const (
+	LyricsAnalysis   = "lyrics_analysis"   // Answering a question about a song
+	LyricsReanalysis = "lyrics_reanalysis" // Answering a question again after earlier answers were rated down
+	MoodDetection    = "mood_detection"    // Detecting the mood of a chat message
+	LyricsMood       = "lyrics_mood"       // Detecting the mood and themes of a song's lyrics
+	SongFacts        = "song_facts"        // Writing a "did you know" blurb about a song
+	TriviaQuiz       = "trivia_quiz"       // Writing multiple-choice questions for a chat quiz
+	SongComparison   = "song_comparison"   // Comparing the themes, mood and era of two songs
+	AlbumAnalysis    = "album_analysis"    // Describing an album's arc and recurring themes
+	ArtistStyle      = "artist_style"      // Describing an artist's lyrical style
+)
+
+// LyricsAnalysisData is the data of the lyrics_analysis template
+type LyricsAnalysisData struct {
+	SongInfo string // "Song by Artist"
+	Query    string
+	Lyrics   string // Not used by the built-in template, which relies on the model knowing the song
+}
+
+// LyricsReanalysisData is the data of the lyrics_reanalysis template
+type LyricsReanalysisData struct {
+	SongInfo string // "Song by Artist"
+	Query    string
+	Lyrics   string
+	Rejected []string // Earlier answers that were rated down
+}
+
+// MoodDetectionData is the data of the mood_detection template
+type MoodDetectionData struct {
+	Message string
+}
+
+// LyricsMoodData is the data of the lyrics_mood template
+type LyricsMoodData struct {
+	Lyrics string
+}
+
+// SongFactsData is the data of the song_facts template. Fields Genius has
+// nothing for are empty.
+type SongFactsData struct {
+	SongInfo    string // "Song by Artist"
+	ReleaseDate string
+	Producers   string // Comma-separated
+	Samples     string // Comma-separated full titles
+	Description string // The song's Genius description, shortened
+}
+
+// TriviaQuizData is the data of the trivia_quiz template
+type TriviaQuizData struct {
+	Topic string // What the questions are about, e.g. "the artist Linkin Park"
+	Count int    // Number of questions
+}
+
+// SongComparisonData is the data of the song_comparison template
+type SongComparisonData struct {
+	First  ComparedSong
+	Second ComparedSong
+}
+
+// ComparedSong is one of the songs of the song_comparison template
+type ComparedSong struct {
+	SongInfo string // "Song by Artist"
+	Mood     string // The mood detected in the lyrics, if any
+	Lyrics   string // Shortened if long
+}
+
+// AlbumAnalysisData is the data of the album_analysis template
+type AlbumAnalysisData struct {
+	AlbumInfo   string // "Album by Artist"
+	ReleaseDate string
+	Tracks      []AlbumTrack
+}
+
+// AlbumTrack is one of the tracks of the album_analysis template
+type AlbumTrack struct {
+	Number int // 1-based position on the album
+	Name   string
+	Mood   string // The mood detected in the lyrics; empty without lyrics
+	Themes string // Comma-separated
+	Lyrics string // An excerpt; empty if the track has none
+}
+
+// ArtistStyleData is the data of the artist_style template
+type ArtistStyleData struct {
+	Artist string
+	Songs  []StyleSong // Oldest first
+}
+
+// StyleSong is one of the songs of the artist_style template
+type StyleSong struct {
+	Name        string
+	Album       string
+	ReleaseDate string
+	Lyrics      string // An excerpt
+}
+
+// Service renders the AI prompt templates
+type Service interface {
+	// Render executes the named template with data. Variants of a template,
+	// loaded from <name>.<variant>.tmpl override files, are named
+	// "<name>.<variant>" and take the same data.
+	Render(name string, data interface{}) (string, error)
+
+	// Has reports whether a template or variant is loaded
+	Has(name string) bool
+
+	// Reload reads the override templates again. If any of them is invalid the
+	// templates in use are kept and the error is returned.
+	Reload() error
+
+	// ReloadIfChanged reloads when an override file was added, removed or
+	// modified since the last load, reporting whether it did
+	ReloadIfChanged() (bool, error)
+}
@@ -0,0 +1,212 @@
+package prompts
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var builtins embed.FS
+
+// samples holds example data for each template, used to check that overrides
+// only refer to fields their data has
+var samples = map[string]interface{}{
+	LyricsAnalysis:   LyricsAnalysisData{},
+	LyricsReanalysis: LyricsReanalysisData{Rejected: []string{""}},
+	MoodDetection:    MoodDetectionData{},
+	LyricsMood:       LyricsMoodData{},
+	SongFacts:        SongFactsData{},
+	TriviaQuiz:       TriviaQuizData{},
+	SongComparison:   SongComparisonData{},
+	AlbumAnalysis:    AlbumAnalysisData{Tracks: []AlbumTrack{{}}},
+	ArtistStyle:      ArtistStyleData{Songs: []StyleSong{{}}},
+}
+
+// Config holds prompt template configuration
+type Config struct {
+	Dir   string            // Directory of <name>.tmpl files overriding built-in templates; empty for none
+	Files map[string]string // Files overriding single templates by name, taking precedence over Dir
+}
+
+// service implements the prompts Service interface
+type service struct {
+	config    Config
+	templates map[string]*template.Template
+	signature string // Override files with their sizes and modification times at the last load
+	mutex     sync.RWMutex
+}
+
+var (
+	defaultService     Service
+	defaultServiceOnce sync.Once
+)
+
+// Default returns a Service with the built-in templates only
+func Default() Service {
+	defaultServiceOnce.Do(func() {
+		service, err := New(Config{})
+		if err != nil {
+			panic(fmt.Sprintf("built-in prompt templates are invalid: %v", err))
+		}
+		defaultService = service
+	})
+	return defaultService
+}
+
+// New loads the built-in templates and the overrides in config
+func New(config Config) (Service, error) {
+	s := &service{config: config}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Has reports whether a template or variant is loaded
+func (s *service) Has(name string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, ok := s.templates[name]
+	return ok
+}
+
+// Render executes the named template with data
+func (s *service) Render(name string, data interface{}) (string, error) {
+	s.mutex.RLock()
+	tmpl, ok := s.templates[name]
+	s.mutex.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", name, err)
+	}
+	return prompt.String(), nil
+}
+
+// Reload reads the built-in and override templates
+func (s *service) Reload() error {
+	files, signature, err := s.overrides()
+	if err != nil {
+		return err
+	}
+
+	templates := make(map[string]*template.Template, len(samples))
+	for name := range samples {
+		source, err := builtins.ReadFile("templates/" + name + ".tmpl")
+		if err != nil {
+			return fmt.Errorf("missing built-in prompt template %s: %w", name, err)
+		}
+		if path, ok := files[name]; ok {
+			if source, err = os.ReadFile(path); err != nil {
+				return fmt.Errorf("failed to read prompt template %s: %w", name, err)
+			}
+		}
+		if templates[name], err = parse(name, string(source)); err != nil {
+			return err
+		}
+	}
+	for name, path := range files {
+		if _, builtin := samples[name]; builtin {
+			continue
+		}
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt template %s: %w", name, err)
+		}
+		if templates[name], err = parse(name, string(source)); err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.templates = templates
+	s.signature = signature
+	return nil
+}
+
+// ReloadIfChanged reloads when the override files changed since the last load
+func (s *service) ReloadIfChanged() (bool, error) {
+	_, signature, err := s.overrides()
+	if err != nil {
+		return false, err
+	}
+
+	s.mutex.RLock()
+	unchanged := signature == s.signature
+	s.mutex.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	return true, s.Reload()
+}
+
+// overrides returns the override file of each template that has one, and a
+// signature that changes whenever one of them does
+func (s *service) overrides() (map[string]string, string, error) {
+	files := make(map[string]string)
+	if s.config.Dir != "" {
+		paths, err := filepath.Glob(filepath.Join(s.config.Dir, "*.tmpl"))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list prompt templates: %w", err)
+		}
+		for _, path := range paths {
+			files[strings.TrimSuffix(filepath.Base(path), ".tmpl")] = path
+		}
+	}
+	for name, path := range s.config.Files {
+		files[name] = path
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if _, ok := samples[baseName(name)]; !ok {
+			return nil, "", fmt.Errorf("%w: %s (from %s)", ErrUnknownTemplate, name, files[name])
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var signature strings.Builder
+	for _, name := range names {
+		info, err := os.Stat(files[name])
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read prompt template %s: %w", name, err)
+		}
+		fmt.Fprintf(&signature, "%s=%s:%d:%s;", name, files[name], info.Size(), info.ModTime().Format(time.RFC3339Nano))
+	}
+	return files, signature.String(), nil
+}
+
+// parse parses a template and checks it renders with its sample data, so a
+// misspelt field is reported when the template is loaded rather than used
+func parse(name, source string) (*template.Template, error) {
+	// Files end with a newline that isn't part of the prompt
+	source = strings.TrimSuffix(source, "\n")
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template %s: %w", name, err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, samples[baseName(name)]); err != nil {
+		return nil, fmt.Errorf("invalid prompt template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// baseName returns the template a variant name belongs to
+func baseName(name string) string {
+	base, _, _ := strings.Cut(name, ".")
+	return base
+}
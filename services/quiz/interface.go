@@ -0,0 +1,52 @@
+package quiz
+
+import (
+	"backend/server/models"
+	"errors"
+)
+
+// Errors returned by Service
+var (
+	ErrGameRunning     = errors.New("a quiz is already running in this room")
+	ErrNotEnoughTracks = errors.New("not enough recently played songs with lyrics for a quiz")
+	ErrNoGame          = errors.New("no quiz is running in this room")
+	ErrNoRound         = errors.New("no round is open")
+	ErrAlreadyAnswered = errors.New("already answered this round")
+	ErrInvalidAnswer   = errors.New("answer must be one of the round's choices")
+)
+
+// History provides the recent plays questions are drawn from, most recent first
+type History interface {
+	GetPlayHistory() []models.PlayHistoryItem
+}
+
+// Lyrics fetches the lyrics snippets are taken from
+type Lyrics interface {
+	GetLyrics(trackName, artistName string) (string, error)
+}
+
+// Store persists per-room leaderboards
+type Store interface {
+	// AddScores adds a finished game's scores to the room's leaderboard
+	AddScores(room string, scores []models.QuizScore) error
+
+	// Leaderboard returns the room's top limit players, highest first
+	Leaderboard(room string, limit int) ([]models.QuizScore, error)
+}
+
+// Service runs lyrics quiz games, one at a time per room. Rounds, results and
+// final standings are published on the event bus for WebSocket clients.
+type Service interface {
+	// Start picks the game's questions and starts it in the background
+	Start(room string) (models.QuizGame, error)
+
+	// Answer scores a player's answer to the open round; faster correct
+	// answers earn more points
+	Answer(room, userID, answer string) (models.QuizAnswerResult, error)
+
+	// Game returns the state of the room's running game
+	Game(room string) (models.QuizGame, bool)
+
+	// Leaderboard returns the room's top limit players across all games
+	Leaderboard(room string, limit int) ([]models.QuizScore, error)
+}
@@ -0,0 +1,58 @@
+package quiz
+
+import (
+	"backend/server/models"
+	"sync"
+)
+
+// memoryStore keeps leaderboards in memory, for development without a database and tests
+type memoryStore struct {
+	scores map[string]map[string]*models.QuizScore // Keyed by room and user ID
+	mutex  sync.RWMutex
+}
+
+// NewMemoryStore creates an in-memory Store
+func NewMemoryStore() Store {
+	return &memoryStore{
+		scores: make(map[string]map[string]*models.QuizScore),
+	}
+}
+
+// AddScores adds a finished game's scores to the room's leaderboard
+func (m *memoryStore) AddScores(room string, scores []models.QuizScore) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	board, ok := m.scores[room]
+	if !ok {
+		board = make(map[string]*models.QuizScore)
+		m.scores[room] = board
+	}
+	for _, score := range scores {
+		total, ok := board[score.UserID]
+		if !ok {
+			total = &models.QuizScore{UserID: score.UserID}
+			board[score.UserID] = total
+		}
+		total.Points += score.Points
+		total.CorrectAnswers += score.CorrectAnswers
+		total.GamesPlayed += score.GamesPlayed
+	}
+	return nil
+}
+
+// Leaderboard returns the room's top limit players, highest first
+func (m *memoryStore) Leaderboard(room string, limit int) ([]models.QuizScore, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	scores := []models.QuizScore{}
+	for _, score := range m.scores[room] {
+		scores = append(scores, *score)
+	}
+	sortScores(scores)
+	if len(scores) > limit {
+		scores = scores[:limit]
+	}
+	return scores, nil
+}
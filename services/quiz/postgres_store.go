@@ -0,0 +1,83 @@
+package quiz
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"fmt"
+)
+
+// Schema creates the leaderboard table
+const Schema = `
+        CREATE TABLE IF NOT EXISTS quiz_scores (
+            room VARCHAR(255) NOT NULL,
+            user_id VARCHAR(255) NOT NULL,
+            points INTEGER NOT NULL DEFAULT 0,
+            correct_answers INTEGER NOT NULL DEFAULT 0,
+            games_played INTEGER NOT NULL DEFAULT 0,
+            updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+            PRIMARY KEY (room, user_id)
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_quiz_scores_room_points ON quiz_scores(room, points DESC);
+    `
+
+// postgresStore keeps leaderboards in the quiz_scores table
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a Store backed by the table in Schema
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+// AddScores adds a finished game's scores to the room's leaderboard
+func (p *postgresStore) AddScores(room string, scores []models.QuizScore) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin leaderboard update: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, score := range scores {
+		_, err := tx.Exec(`
+            INSERT INTO quiz_scores (room, user_id, points, correct_answers, games_played, updated_at)
+            VALUES ($1, $2, $3, $4, $5, NOW())
+            ON CONFLICT (room, user_id) DO UPDATE SET
+                points = quiz_scores.points + EXCLUDED.points,
+                correct_answers = quiz_scores.correct_answers + EXCLUDED.correct_answers,
+                games_played = quiz_scores.games_played + EXCLUDED.games_played,
+                updated_at = NOW()
+        `, room, score.UserID, score.Points, score.CorrectAnswers, score.GamesPlayed)
+		if err != nil {
+			return fmt.Errorf("failed to update quiz score: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Leaderboard returns the room's top limit players, highest first
+func (p *postgresStore) Leaderboard(room string, limit int) ([]models.QuizScore, error) {
+	rows, err := p.db.Query(`
+        SELECT user_id, points, correct_answers, games_played
+        FROM quiz_scores
+        WHERE room = $1
+        ORDER BY points DESC, user_id
+        LIMIT $2
+    `, room, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	scores := []models.QuizScore{}
+	for rows.Next() {
+		var score models.QuizScore
+		if err := rows.Scan(&score.UserID, &score.Points, &score.CorrectAnswers, &score.GamesPlayed); err != nil {
+			return nil, fmt.Errorf("failed to scan quiz score: %w", err)
+		}
+		scores = append(scores, score)
+	}
+	return scores, rows.Err()
+}
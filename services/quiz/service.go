@@ -0,0 +1,379 @@
+package quiz
+
+import (
+	"backend/server/models"
+	"backend/services/events"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Config holds quiz settings
+type Config struct {
+	Rounds       int           // Questions per game; fewer if not enough songs have lyrics
+	Choices      int           // Track names offered per question, including the answer
+	AnswerTime   time.Duration // How long each round stays open
+	RevealTime   time.Duration // Pause after a round's answer is revealed
+	SnippetLines int           // Lyric lines shown per question
+	MaxPoints    int           // For a correct answer given immediately
+	MinPoints    int           // For a correct answer given at the last moment
+}
+
+// DefaultConfig returns a default configuration for quiz games
+func DefaultConfig() Config {
+	return Config{
+		Rounds:       5,
+		Choices:      4,
+		AnswerTime:   20 * time.Second,
+		RevealTime:   5 * time.Second,
+		SnippetLines: 2,
+		MaxPoints:    1000,
+		MinPoints:    100,
+	}
+}
+
+// question is a round prepared before the game starts
+type question struct {
+	track   models.PlayHistoryItem
+	snippet string
+	choices []string
+}
+
+// game is a room's running quiz
+type game struct {
+	room        string
+	questions   []question
+	startedAt   time.Time
+	round       int // Index of the current round
+	open        bool
+	endsAt      time.Time
+	answered    map[string]bool // Players who answered the current round
+	roundPoints map[string]int
+	scores      map[string]*models.QuizScore
+	mutex       sync.Mutex
+}
+
+// service implements the quiz Service interface
+type service struct {
+	history    History
+	lyrics     Lyrics
+	store      Store
+	bus        events.Bus
+	config     Config
+	games      map[string]*game // Running games by room
+	gamesMutex sync.Mutex
+	startMutex sync.Mutex // Serializes starts, which fetch lyrics
+}
+
+// New creates a new quiz service
+func New(history History, lyrics Lyrics, store Store, bus events.Bus, config Config) Service {
+	return &service{
+		history: history,
+		lyrics:  lyrics,
+		store:   store,
+		bus:     bus,
+		config:  config,
+		games:   make(map[string]*game),
+	}
+}
+
+// Start picks the game's questions and starts it in the background
+func (s *service) Start(room string) (models.QuizGame, error) {
+	s.startMutex.Lock()
+	defer s.startMutex.Unlock()
+
+	if _, running := s.game(room); running {
+		return models.QuizGame{}, ErrGameRunning
+	}
+
+	questions := s.prepare()
+	if len(questions) == 0 {
+		return models.QuizGame{}, ErrNotEnoughTracks
+	}
+
+	g := &game{
+		room:      room,
+		questions: questions,
+		startedAt: time.Now(),
+		scores:    make(map[string]*models.QuizScore),
+	}
+	s.gamesMutex.Lock()
+	s.games[room] = g
+	s.gamesMutex.Unlock()
+
+	log.Printf("Quiz started in %s with %d rounds", room, len(questions))
+	go s.run(g)
+	return g.state(), nil
+}
+
+// Answer scores a player's answer to the open round
+func (s *service) Answer(room, userID, answer string) (models.QuizAnswerResult, error) {
+	g, ok := s.game(room)
+	if !ok {
+		return models.QuizAnswerResult{}, ErrNoGame
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	now := time.Now()
+	if !g.open || !now.Before(g.endsAt) {
+		return models.QuizAnswerResult{}, ErrNoRound
+	}
+	if g.answered[userID] {
+		return models.QuizAnswerResult{}, ErrAlreadyAnswered
+	}
+
+	q := g.questions[g.round]
+	chosen := ""
+	for _, choice := range q.choices {
+		if normalize(choice) == normalize(answer) {
+			chosen = choice
+		}
+	}
+	if chosen == "" {
+		return models.QuizAnswerResult{}, ErrInvalidAnswer
+	}
+
+	result := models.QuizAnswerResult{Number: g.round + 1, Correct: chosen == q.track.TrackName}
+	if result.Correct {
+		// Points fall linearly from MaxPoints to MinPoints over the round
+		remaining := float64(g.endsAt.Sub(now)) / float64(s.config.AnswerTime)
+		result.Points = s.config.MinPoints + int(float64(s.config.MaxPoints-s.config.MinPoints)*remaining)
+	}
+
+	g.answered[userID] = true
+	g.roundPoints[userID] = result.Points
+	score, ok := g.scores[userID]
+	if !ok {
+		score = &models.QuizScore{UserID: userID, GamesPlayed: 1}
+		g.scores[userID] = score
+	}
+	score.Points += result.Points
+	if result.Correct {
+		score.CorrectAnswers++
+	}
+	return result, nil
+}
+
+// Game returns the state of the room's running game
+func (s *service) Game(room string) (models.QuizGame, bool) {
+	g, ok := s.game(room)
+	if !ok {
+		return models.QuizGame{}, false
+	}
+	return g.state(), true
+}
+
+// Leaderboard returns the room's top limit players across all games
+func (s *service) Leaderboard(room string, limit int) ([]models.QuizScore, error) {
+	return s.store.Leaderboard(room, limit)
+}
+
+// game returns the room's running game
+func (s *service) game(room string) (*game, bool) {
+	s.gamesMutex.Lock()
+	defer s.gamesMutex.Unlock()
+	g, ok := s.games[room]
+	return g, ok
+}
+
+// run plays the game's rounds, then records the scores
+func (s *service) run(g *game) {
+	for i := range g.questions {
+		s.bus.Publish(events.QuizRoundStarted, g.openRound(i, s.config.AnswerTime))
+		time.Sleep(s.config.AnswerTime)
+		s.bus.Publish(events.QuizRoundEnded, g.closeRound())
+		if i < len(g.questions)-1 {
+			time.Sleep(s.config.RevealTime)
+		}
+	}
+
+	final := g.state()
+	final.Finished = true
+	if len(final.Standings) > 0 {
+		if err := s.store.AddScores(g.room, final.Standings); err != nil {
+			log.Printf("Error saving quiz scores for %s: %v", g.room, err)
+		}
+	}
+
+	s.gamesMutex.Lock()
+	delete(s.games, g.room)
+	s.gamesMutex.Unlock()
+
+	log.Printf("Quiz in %s finished with %d players", g.room, len(final.Standings))
+	s.bus.Publish(events.QuizEnded, final)
+}
+
+// prepare picks questions from recently played songs that have lyrics. Each
+// question offers the answer among the names of other recent songs.
+func (s *service) prepare() []question {
+	var tracks []models.PlayHistoryItem
+	seen := make(map[string]bool)
+	for _, track := range s.history.GetPlayHistory() {
+		key := normalize(track.TrackName)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		tracks = append(tracks, track)
+	}
+	if len(tracks) < 2 {
+		return nil
+	}
+
+	var questions []question
+	for _, i := range rand.Perm(len(tracks)) {
+		if len(questions) == s.config.Rounds {
+			break
+		}
+		track := tracks[i]
+		lyrics, err := s.lyrics.GetLyrics(track.TrackName, track.Artist)
+		if err != nil {
+			log.Printf("Skipping %s by %s for the quiz: %v", track.TrackName, track.Artist, err)
+			continue
+		}
+		snippet := pickSnippet(lyrics, track.TrackName, s.config.SnippetLines)
+		if snippet == "" {
+			continue
+		}
+		questions = append(questions, question{
+			track:   track,
+			snippet: snippet,
+			choices: pickChoices(tracks, i, s.config.Choices),
+		})
+	}
+	return questions
+}
+
+// openRound opens round i for answers and returns it
+func (g *game) openRound(i int, answerTime time.Duration) models.QuizRound {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.round = i
+	g.open = true
+	g.endsAt = time.Now().Add(answerTime)
+	g.answered = make(map[string]bool)
+	g.roundPoints = make(map[string]int)
+	return g.roundState()
+}
+
+// closeRound stops accepting answers and returns the round's result
+func (g *game) closeRound() models.QuizRoundResult {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.open = false
+	q := g.questions[g.round]
+	return models.QuizRoundResult{
+		Room:      g.room,
+		Number:    g.round + 1,
+		TrackName: q.track.TrackName,
+		Artist:    q.track.Artist,
+		Points:    g.roundPoints,
+	}
+}
+
+// state returns a snapshot of the game
+func (g *game) state() models.QuizGame {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	state := models.QuizGame{
+		Room:      g.room,
+		Rounds:    len(g.questions),
+		StartedAt: g.startedAt,
+		Standings: []models.QuizScore{},
+	}
+	if g.open {
+		round := g.roundState()
+		state.Round = &round
+	}
+	for _, score := range g.scores {
+		state.Standings = append(state.Standings, *score)
+	}
+	sortScores(state.Standings)
+	return state
+}
+
+// roundState describes the current round; callers hold the mutex
+func (g *game) roundState() models.QuizRound {
+	q := g.questions[g.round]
+	return models.QuizRound{
+		Room:    g.room,
+		Number:  g.round + 1,
+		Of:      len(g.questions),
+		Snippet: q.snippet,
+		Choices: q.choices,
+		EndsAt:  g.endsAt,
+	}
+}
+
+// pickSnippet picks consecutive lyric lines that don't give away the title,
+// or returns "" if the lyrics have none
+func pickSnippet(lyrics, title string, lines int) string {
+	var verses []string
+	for _, line := range strings.Split(lyrics, "\n") {
+		line = strings.TrimSpace(line)
+		// Skip blank lines and section headers like [Chorus]
+		if line == "" || strings.HasPrefix(line, "[") {
+			continue
+		}
+		verses = append(verses, line)
+	}
+
+	var candidates []string
+	name := normalize(title)
+	for i := 0; i+lines <= len(verses); i++ {
+		snippet := strings.Join(verses[i:i+lines], "\n")
+		if !strings.Contains(normalize(snippet), name) {
+			candidates = append(candidates, snippet)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+// pickChoices returns the name of tracks[answer] among up to count-1 other
+// track names, shuffled
+func pickChoices(tracks []models.PlayHistoryItem, answer, count int) []string {
+	choices := []string{tracks[answer].TrackName}
+	for _, i := range rand.Perm(len(tracks)) {
+		if len(choices) == count {
+			break
+		}
+		if i != answer {
+			choices = append(choices, tracks[i].TrackName)
+		}
+	}
+	rand.Shuffle(len(choices), func(i, j int) {
+		choices[i], choices[j] = choices[j], choices[i]
+	})
+	return choices
+}
+
+// normalize reduces a track name or answer to lowercase letters and digits
+// separated by single spaces, so punctuation and case don't matter
+func normalize(text string) string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// sortScores orders scores highest first, breaking ties by user ID
+func sortScores(scores []models.QuizScore) {
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Points != scores[j].Points {
+			return scores[i].Points > scores[j].Points
+		}
+		return scores[i].UserID < scores[j].UserID
+	})
+}
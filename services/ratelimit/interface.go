@@ -0,0 +1,18 @@
+package ratelimit
+
+import "time"
+
+// Result is the outcome of a rate limit check
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int           // Requests left in the current window
+	RetryAfter time.Duration // When a rejected request may be retried
+}
+
+// Limiter counts requests per key over a sliding window
+type Limiter interface {
+	// Allow records a request for key and reports whether it is within
+	// limit requests per window
+	Allow(key string, limit int, window time.Duration) (Result, error)
+}
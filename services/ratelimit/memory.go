@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many calls pass between removals of idle keys
+const sweepEvery = 1000
+
+// memoryLimiter keeps a sliding log of request times per key in process.
+// It only limits a single instance; use Redis when running replicas.
+type memoryLimiter struct {
+	requests map[string][]time.Time // key -> request times, oldest first
+	windows  map[string]time.Duration
+	calls    int
+	mutex    sync.Mutex
+}
+
+// NewMemory creates an in-process sliding-window limiter
+func NewMemory() Limiter {
+	return &memoryLimiter{
+		requests: make(map[string][]time.Time),
+		windows:  make(map[string]time.Duration),
+	}
+}
+
+// Allow records a request for key and reports whether it is within the limit
+func (m *memoryLimiter) Allow(key string, limit int, window time.Duration) (Result, error) {
+	now := time.Now()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.calls++
+	if m.calls%sweepEvery == 0 {
+		m.sweep(now)
+	}
+
+	times := prune(m.requests[key], now.Add(-window))
+	m.windows[key] = window
+
+	if len(times) >= limit {
+		m.requests[key] = times
+		return Result{
+			Limit:      limit,
+			RetryAfter: times[0].Add(window).Sub(now),
+		}, nil
+	}
+
+	m.requests[key] = append(times, now)
+	return Result{
+		Allowed:   true,
+		Limit:     limit,
+		Remaining: limit - len(times) - 1,
+	}, nil
+}
+
+// sweep removes keys with no requests left in their window
+func (m *memoryLimiter) sweep(now time.Time) {
+	for key, times := range m.requests {
+		if len(prune(times, now.Add(-m.windows[key]))) == 0 {
+			delete(m.requests, key)
+			delete(m.windows, key)
+		}
+	}
+}
+
+// prune drops request times at or before cutoff
+func prune(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
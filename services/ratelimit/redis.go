@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"backend/services/redis"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// slidingWindowScript keeps a sorted set of request times per key and
+// atomically trims, counts and records, so every replica shares one window.
+// It returns {allowed, remaining, retry after in ms}.
+const slidingWindowScript = `
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+if count < limit then
+  redis.call('ZADD', key, now, ARGV[4])
+  redis.call('PEXPIRE', key, window)
+  return {1, limit - count - 1, 0}
+end
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+return {0, 0, tonumber(oldest[2]) + window - now}
+`
+
+// redisLimiter is a sliding-window limiter shared by all instances through Redis
+type redisLimiter struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedis creates a limiter backed by the Redis server at rawURL
+// (redis://[:password@]host[:port][/db]). Keys are prefixed with keyPrefix.
+func NewRedis(rawURL, keyPrefix string) (Limiter, error) {
+	client, err := redis.New(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &redisLimiter{client: client, keyPrefix: keyPrefix}, nil
+}
+
+// Allow records a request for key and reports whether it is within the limit
+func (r *redisLimiter) Allow(key string, limit int, window time.Duration) (Result, error) {
+	now := time.Now()
+	nowMs := now.UnixMilli()
+	// Unique member, so concurrent requests in the same millisecond all count
+	member := strconv.FormatInt(now.UnixNano(), 36) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+
+	reply, err := r.client.Do("EVAL", slidingWindowScript, "1", r.keyPrefix+key,
+		strconv.FormatInt(nowMs, 10),
+		strconv.FormatInt(window.Milliseconds(), 10),
+		strconv.Itoa(limit),
+		member,
+	)
+	if err != nil {
+		return Result{}, fmt.Errorf("redis rate limit: %w", err)
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 3 {
+		return Result{}, fmt.Errorf("redis rate limit: unexpected reply %v", reply)
+	}
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	retryAfterMs, _ := values[2].(int64)
+
+	return Result{
+		Allowed:    allowed == 1,
+		Limit:      limit,
+		Remaining:  int(remaining),
+		RetryAfter: time.Duration(retryAfterMs) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule limits requests to the routes matching Method and Path
+type Rule struct {
+	Method string // Empty matches every method
+	Path   string // Exact path, or a prefix when it ends with "*"
+	Limit  int
+	Window time.Duration
+}
+
+// Matches reports whether the rule applies to a request
+func (r Rule) Matches(method, path string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.Path, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return path == r.Path
+}
+
+// Key identifies the rule in limiter keys, so every route has its own counter
+func (r Rule) Key() string {
+	return r.Method + " " + r.Path
+}
+
+// ParseRules parses comma-separated rules such as
+//
+//	POST /api/chat=30/1m, POST /api/messages=20/1m, /api/*=600/1m
+//
+// Rules are matched in order and the first match applies.
+func ParseRules(value string) ([]Rule, error) {
+	var rules []Rule
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, limitSpec, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("rate limit %q: expected \"[METHOD] /path=limit/window\"", entry)
+		}
+
+		var rule Rule
+		fields := strings.Fields(route)
+		switch len(fields) {
+		case 1:
+			rule.Path = fields[0]
+		case 2:
+			rule.Method, rule.Path = strings.ToUpper(fields[0]), fields[1]
+		default:
+			return nil, fmt.Errorf("rate limit %q: expected \"[METHOD] /path\"", entry)
+		}
+		if !strings.HasPrefix(rule.Path, "/") {
+			return nil, fmt.Errorf("rate limit %q: path must start with /", entry)
+		}
+
+		limit, window, found := strings.Cut(strings.TrimSpace(limitSpec), "/")
+		if !found {
+			return nil, fmt.Errorf("rate limit %q: expected limit/window, e.g. 30/1m", entry)
+		}
+		var err error
+		if rule.Limit, err = strconv.Atoi(limit); err != nil || rule.Limit < 1 {
+			return nil, fmt.Errorf("rate limit %q: limit must be a positive integer", entry)
+		}
+		if rule.Window, err = time.ParseDuration(window); err != nil || rule.Window <= 0 {
+			return nil, fmt.Errorf("rate limit %q: window must be a positive duration", entry)
+		}
+
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,389 @@
+package realtime
+
+import (
+	"backend/server/apierror"
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Frame opcodes (RFC 6455 section 5.2)
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close codes (RFC 6455 section 7.4.1)
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+)
+
+// acceptGUID is appended to the client's key to compute Sec-WebSocket-Accept
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Config holds WebSocket limits
+type Config struct {
+	AllowedOrigins    []string      // Browser origins allowed to connect; requests without an Origin are not from browsers
+	MaxMessageBytes   int64         // Larger messages close the connection
+	MessagesPerMinute int           // Messages a client may send per minute, with bursts of up to a tenth of that; 0 disables
+	PingInterval      time.Duration // Clients that don't answer pings within two intervals are disconnected
+	SendBuffer        int           // Outgoing messages queued per connection before it is considered too slow
+	TypingTimeout     time.Duration // How long a typing indicator lasts unless the client refreshes it
+}
+
+// DefaultConfig returns a default configuration for WebSocket connections
+func DefaultConfig() Config {
+	return Config{
+		AllowedOrigins:    []string{"http://localhost:3000", "http://127.0.0.1:3000"},
+		MaxMessageBytes:   4096,
+		MessagesPerMinute: 60,
+		PingInterval:      30 * time.Second,
+		SendBuffer:        32,
+		TypingTimeout:     5 * time.Second,
+	}
+}
+
+// Conn is a server-side WebSocket connection. Reads must come from a single
+// goroutine; Send may be called from any goroutine.
+type Conn struct {
+	conn       net.Conn
+	reader     *bufio.Reader
+	config     Config
+	send       chan []byte
+	writeMutex sync.Mutex // Serializes frames written by the write loop and the reader
+	done       chan struct{}
+	closeOnce  sync.Once
+
+	// Token bucket for incoming messages
+	tokens     float64
+	lastRefill time.Time
+}
+
+// Upgrade validates the Origin and handshake of a WebSocket request and takes
+// over the connection. On failure an HTTP error has been written.
+// Authentication is left to middleware on the route.
+func Upgrade(w http.ResponseWriter, r *http.Request, config Config) (*Conn, error) {
+	if !originAllowed(r.Header.Get("Origin"), config.AllowedOrigins) {
+		apierror.Write(w, http.StatusForbidden, apierror.Forbidden, "Origin not allowed")
+		return nil, fmt.Errorf("origin %q not allowed", r.Header.Get("Origin"))
+	}
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Expected a WebSocket upgrade")
+		return nil, fmt.Errorf("not a WebSocket upgrade")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		apierror.Write(w, http.StatusUpgradeRequired, apierror.InvalidRequest, "Unsupported WebSocket version")
+		return nil, fmt.Errorf("unsupported WebSocket version %q", r.Header.Get("Sec-WebSocket-Version"))
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.InvalidRequest, "Missing Sec-WebSocket-Key")
+		return nil, fmt.Errorf("missing Sec-WebSocket-Key")
+	}
+
+	netConn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, apierror.Internal, "WebSocket upgrade not supported")
+		return nil, fmt.Errorf("failed to hijack connection: %w", err)
+	}
+	// Clear the server's request deadlines; the connection manages its own
+	netConn.SetDeadline(time.Time{})
+
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n"
+	if _, err := netConn.Write([]byte(response)); err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("failed to complete handshake: %w", err)
+	}
+
+	if config.SendBuffer < 1 {
+		config.SendBuffer = 1
+	}
+	c := &Conn{
+		conn:       netConn,
+		reader:     rw.Reader,
+		config:     config,
+		send:       make(chan []byte, config.SendBuffer),
+		done:       make(chan struct{}),
+		tokens:     burst(config.MessagesPerMinute),
+		lastRefill: time.Now(),
+	}
+	go c.writeLoop()
+	return c, nil
+}
+
+// Send queues data as a text message. A connection whose queue is full is too
+// slow to keep up and is closed.
+func (c *Conn) Send(data []byte) {
+	select {
+	case <-c.done:
+	case c.send <- data:
+	default:
+		c.Close(ClosePolicyViolation, "too slow")
+	}
+}
+
+// Done is closed when the connection closes
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close sends a close frame with code and reason and closes the connection
+func (c *Conn) Close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		payload := make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		payload = append(payload, reason...)
+
+		c.writeMutex.Lock()
+		c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+		c.writeFrame(opClose, payload)
+		c.writeMutex.Unlock()
+
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// ReadMessage returns the next text or binary message, answering pings and
+// enforcing the size and rate limits. It returns io.EOF when the client
+// closes the connection.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var message []byte
+	started := false
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(2 * c.config.PingInterval))
+
+		final, opcode, payload, err := c.readFrame(int64(len(message)))
+		if err != nil {
+			return nil, c.fail(err)
+		}
+
+		switch opcode {
+		case opPing:
+			c.writeMutex.Lock()
+			c.writeFrame(opPong, payload)
+			c.writeMutex.Unlock()
+			continue
+		case opPong:
+			continue
+		case opClose:
+			c.Close(CloseNormal, "")
+			return nil, io.EOF
+		case opText, opBinary:
+			if started {
+				return nil, c.fail(fmt.Errorf("%w: new message before the previous one ended", ErrProtocol))
+			}
+			started = true
+		case opContinuation:
+			if !started {
+				return nil, c.fail(fmt.Errorf("%w: continuation without a message", ErrProtocol))
+			}
+		default:
+			return nil, c.fail(fmt.Errorf("%w: unknown opcode %d", ErrProtocol, opcode))
+		}
+
+		message = append(message, payload...)
+		if final {
+			if !c.allow() {
+				return nil, c.fail(ErrRateLimited)
+			}
+			return message, nil
+		}
+	}
+}
+
+// fail closes the connection with the close code for err and returns err
+func (c *Conn) fail(err error) error {
+	switch {
+	case errors.Is(err, ErrMessageTooBig):
+		c.Close(CloseMessageTooBig, "message too big")
+	case errors.Is(err, ErrRateLimited):
+		c.Close(ClosePolicyViolation, "too many messages")
+	case errors.Is(err, ErrProtocol):
+		c.Close(CloseProtocolError, "protocol error")
+	default:
+		c.Close(CloseGoingAway, "")
+	}
+	return err
+}
+
+// readFrame reads one frame. buffered is the size of the message so far,
+// counted towards the size limit before the payload is read.
+func (c *Conn) readFrame(buffered int64) (bool, byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	final := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, fmt.Errorf("%w: reserved bits set", ErrProtocol)
+	}
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, fmt.Errorf("%w: client frames must be masked", ErrProtocol)
+	}
+
+	length := int64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(extended[:]) & (1<<63 - 1))
+	}
+
+	if opcode >= opClose {
+		if !final || length > 125 {
+			return false, 0, nil, fmt.Errorf("%w: invalid control frame", ErrProtocol)
+		}
+	} else if buffered+length > c.config.MaxMessageBytes {
+		return false, 0, nil, ErrMessageTooBig
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return final, opcode, payload, nil
+}
+
+// writeLoop writes queued messages and pings the client until the connection closes
+func (c *Conn) writeLoop() {
+	ticker := time.NewTicker(c.config.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		var opcode byte
+		var payload []byte
+		select {
+		case <-c.done:
+			return
+		case payload = <-c.send:
+			opcode = opText
+		case <-ticker.C:
+			opcode = opPing
+		}
+
+		c.writeMutex.Lock()
+		c.conn.SetWriteDeadline(time.Now().Add(c.config.PingInterval))
+		err := c.writeFrame(opcode, payload)
+		c.writeMutex.Unlock()
+		if err != nil {
+			log.Printf("WebSocket write to %s failed: %v", c.conn.RemoteAddr(), err)
+			c.Close(CloseGoingAway, "")
+			return
+		}
+	}
+}
+
+// writeFrame writes a single unmasked frame; callers must hold writeMutex
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length <= 125:
+		header = append(header, byte(length))
+	case length <= 0xFFFF:
+		header = append(header, 126, byte(length>>8), byte(length))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// allow takes a token from the connection's message bucket
+func (c *Conn) allow() bool {
+	if c.config.MessagesPerMinute <= 0 {
+		return true
+	}
+	now := time.Now()
+	rate := float64(c.config.MessagesPerMinute) / 60
+	c.tokens += now.Sub(c.lastRefill).Seconds() * rate
+	if max := burst(c.config.MessagesPerMinute); c.tokens > max {
+		c.tokens = max
+	}
+	c.lastRefill = now
+
+	if c.tokens < 1 {
+		return false
+	}
+	c.tokens--
+	return true
+}
+
+// burst is how many messages a client may send at once
+func burst(messagesPerMinute int) float64 {
+	if b := float64(messagesPerMinute) / 10; b > 1 {
+		return b
+	}
+	return 1
+}
+
+// originAllowed reports whether a request from origin may connect. Browsers
+// always send an Origin, so requests without one come from other clients and
+// rely on authentication alone.
+func originAllowed(origin string, allowed []string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, candidate := range allowed {
+		if strings.EqualFold(origin, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerContains reports whether a comma-separated header includes token
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
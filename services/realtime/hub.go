@@ -0,0 +1,56 @@
+package realtime
+
+import "sync"
+
+// hub implements the Hub interface
+type hub struct {
+	channels map[string]map[*Conn]struct{}
+	mutex    sync.RWMutex
+}
+
+// NewHub creates a new hub
+func NewHub() Hub {
+	return &hub{channels: make(map[string]map[*Conn]struct{})}
+}
+
+// Subscribe adds conn to channel until the returned function is called
+func (h *hub) Subscribe(channel string, conn *Conn) func() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.channels[channel] == nil {
+		h.channels[channel] = make(map[*Conn]struct{})
+	}
+	h.channels[channel][conn] = struct{}{}
+
+	return func() {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+
+		delete(h.channels[channel], conn)
+		if len(h.channels[channel]) == 0 {
+			delete(h.channels, channel)
+		}
+	}
+}
+
+// Broadcast queues data on every connection in channel
+func (h *hub) Broadcast(channel string, data []byte) {
+	h.mutex.RLock()
+	conns := make([]*Conn, 0, len(h.channels[channel]))
+	for conn := range h.channels[channel] {
+		conns = append(conns, conn)
+	}
+	h.mutex.RUnlock()
+
+	for _, conn := range conns {
+		conn.Send(data)
+	}
+}
+
+// Count returns the number of connections subscribed to channel
+func (h *hub) Count(channel string) int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.channels[channel])
+}
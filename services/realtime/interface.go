@@ -0,0 +1,38 @@
+package realtime
+
+import "errors"
+
+// Errors returned by Conn.ReadMessage. The connection has been closed with
+// the matching close code when they are returned.
+var (
+	ErrMessageTooBig = errors.New("message exceeds the maximum size")
+	ErrRateLimited   = errors.New("too many messages")
+	ErrProtocol      = errors.New("websocket protocol error")
+)
+
+// Hub fans out messages to the WebSocket connections subscribed to a channel
+type Hub interface {
+	// Subscribe adds conn to channel until the returned function is called
+	Subscribe(channel string, conn *Conn) (unsubscribe func())
+
+	// Broadcast queues data as a text message on every connection in channel.
+	// Connections too slow to keep up are closed.
+	Broadcast(channel string, data []byte)
+
+	// Count returns the number of connections subscribed to channel
+	Count(channel string) int
+}
+
+// TypingTracker tracks which users are typing in each room. A user stops
+// typing when told to or when their typing expires without being refreshed.
+// Nothing is persisted.
+type TypingTracker interface {
+	// Start marks user as typing in room, or refreshes their expiry
+	Start(room, user string)
+
+	// Stop marks user as no longer typing in room
+	Stop(room, user string)
+
+	// Typing returns the users typing in room
+	Typing(room string) []string
+}
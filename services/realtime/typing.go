@@ -0,0 +1,106 @@
+package realtime
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// typingKey identifies a user typing in a room
+type typingKey struct {
+	room string
+	user string
+}
+
+// typingEntry is a user's typing, ending when its timer fires
+type typingEntry struct {
+	timer *time.Timer
+}
+
+// typingTracker implements the TypingTracker interface
+type typingTracker struct {
+	timeout  time.Duration
+	onChange func(room, user string, typing bool)
+	typing   map[typingKey]*typingEntry
+	mutex    sync.Mutex
+}
+
+// NewTypingTracker creates a new typing tracker. onChange is called when a
+// user starts or stops typing, including when their typing expires after
+// timeout; refreshes don't call it.
+func NewTypingTracker(timeout time.Duration, onChange func(room, user string, typing bool)) TypingTracker {
+	return &typingTracker{
+		timeout:  timeout,
+		onChange: onChange,
+		typing:   make(map[typingKey]*typingEntry),
+	}
+}
+
+// Start marks user as typing in room, or refreshes their expiry
+func (t *typingTracker) Start(room, user string) {
+	key := typingKey{room: room, user: user}
+
+	t.mutex.Lock()
+	current, typing := t.typing[key]
+	if typing && current.timer.Stop() {
+		current.timer.Reset(t.timeout)
+		t.mutex.Unlock()
+		return
+	}
+	// An entry whose timer already fired is waiting to expire; replacing it
+	// keeps the user typing
+	entry := &typingEntry{}
+	entry.timer = time.AfterFunc(t.timeout, func() { t.expire(key, entry) })
+	t.typing[key] = entry
+	t.mutex.Unlock()
+
+	if !typing {
+		t.onChange(room, user, true)
+	}
+}
+
+// Stop marks user as no longer typing in room
+func (t *typingTracker) Stop(room, user string) {
+	key := typingKey{room: room, user: user}
+
+	t.mutex.Lock()
+	entry, ok := t.typing[key]
+	if ok {
+		entry.timer.Stop()
+		delete(t.typing, key)
+	}
+	t.mutex.Unlock()
+
+	if ok {
+		t.onChange(room, user, false)
+	}
+}
+
+// Typing returns the users typing in room, sorted
+func (t *typingTracker) Typing(room string) []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	users := []string{}
+	for key := range t.typing {
+		if key.room == room {
+			users = append(users, key.user)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// expire stops a user's typing when its timer fires, unless the entry was
+// already stopped or replaced by a later Start
+func (t *typingTracker) expire(key typingKey, entry *typingEntry) {
+	t.mutex.Lock()
+	if t.typing[key] != entry {
+		t.mutex.Unlock()
+		return
+	}
+	delete(t.typing, key)
+	t.mutex.Unlock()
+
+	t.onChange(key.room, key.user, false)
+}
@@ -0,0 +1,193 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// poolSize is the number of idle connections kept open
+	poolSize = 10
+	// timeout bounds dialing and each command
+	timeout = 2 * time.Second
+)
+
+// Client is a minimal Redis client speaking RESP over a small connection
+// pool, shared by features that keep state in Redis
+type Client struct {
+	address  string
+	password string
+	db       int
+	idle     chan *redisConn
+}
+
+// redisConn is one connection speaking RESP
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// New connects to the Redis server at rawURL (redis://[:password@]host[:port][/db]),
+// failing at startup rather than on the first command
+func New(rawURL string) (*Client, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "redis" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid Redis URL %q (expected redis://host:port/db)", rawURL)
+	}
+
+	c := &Client{
+		address: parsed.Host,
+		idle:    make(chan *redisConn, poolSize),
+	}
+	if parsed.Port() == "" {
+		c.address = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+	if parsed.User != nil {
+		c.password, _ = parsed.User.Password()
+	}
+	if db := strings.TrimPrefix(parsed.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid Redis database %q", db)
+		}
+	}
+
+	conn, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	c.put(conn)
+	return c, nil
+}
+
+// Do sends a command on a pooled connection and returns its reply: integers
+// as int64, strings as string, arrays as []interface{} and nil bulk strings
+// or arrays as nil. Error replies are returned as Error.
+func (c *Client) Do(args ...string) (interface{}, error) {
+	conn, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.do(args...)
+	var redisErr Error
+	if err != nil && !errors.As(err, &redisErr) {
+		// The connection is in an unknown state; don't reuse it
+		conn.conn.Close()
+		return nil, err
+	}
+	c.put(conn)
+	return reply, err
+}
+
+// get takes an idle connection or dials a new one
+func (c *Client) get() (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	netConn, err := net.DialTimeout("tcp", c.address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if c.password != "" {
+		if _, err := conn.do("AUTH", c.password); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("failed to authenticate with Redis: %w", err)
+		}
+	}
+	if c.db != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(c.db)); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("failed to select Redis database: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// put returns a connection to the pool, closing it if the pool is full
+func (c *Client) put(conn *redisConn) {
+	select {
+	case c.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+}
+
+// Error is an error reply from the server; the connection is still usable
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// do writes a command as a RESP array of bulk strings and reads the reply
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	c.conn.SetDeadline(time.Now().Add(timeout))
+
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, command.String()); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// readReply reads one RESP reply: integers as int64, strings as string,
+// arrays as []interface{} and nil bulk strings or arrays as nil
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty Redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		values := make([]interface{}, count)
+		for i := range values {
+			if values[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unexpected Redis reply %q", line)
+	}
+}
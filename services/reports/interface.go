@@ -0,0 +1,39 @@
+package reports
+
+import (
+	"backend/server/models"
+	"backend/services/mood"
+	"time"
+)
+
+// MoodSource provides users' mood history. The mood service satisfies it.
+type MoodSource interface {
+	ListMoodUsers() ([]string, error)
+	GetUserMoodHistory(userID string) ([]mood.UserMoodEntry, error)
+}
+
+// PlaySource provides the play history. The music repository satisfies it.
+type PlaySource interface {
+	QueryPlayHistory(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error)
+}
+
+// Writer writes a report's summary. The AI services satisfy it.
+type Writer interface {
+	GenerateResponse(prompt string) (string, error)
+}
+
+// Service defines the interface for weekly mood and listening reports
+type Service interface {
+	// Generate builds and keeps a user's report for the week starting at weekStart
+	Generate(userID string, weekStart time.Time) (models.WeeklyReport, error)
+
+	// Run reports last week for every user with mood history who has no
+	// report for it yet, and returns how many reports were generated
+	Run() int
+
+	// Latest returns the user's most recent report, or false if there is none
+	Latest(userID string) (models.WeeklyReport, bool)
+
+	// Forget drops the user's reports, e.g. once their mood history is deleted
+	Forget(userID string)
+}
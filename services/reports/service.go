@@ -0,0 +1,300 @@
+package reports
+
+import (
+	"backend/server/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// week is the period a report covers
+	week = 7 * 24 * time.Hour
+	// correlationWindow is how long after a mood entry plays count toward it
+	correlationWindow = 2 * time.Hour
+	// maxWeekPlays caps the play history items read for a week
+	maxWeekPlays = 1000
+	// maxSummaryLength caps AI summaries, in bytes; longer ones use the template
+	maxSummaryLength = 1200
+
+	// Lengths of the report's lists
+	dominantMoodCount = 3
+	topArtistCount    = 3
+	notableTrackCount = 5
+)
+
+// service implements the reports Service interface
+type service struct {
+	moods    MoodSource
+	plays    PlaySource                     // May be nil, in which case reports only cover moods
+	writer   Writer                         // May be nil, in which case summaries come from a template
+	reports  map[string]models.WeeklyReport // userID -> latest report
+	mutex    sync.RWMutex
+	runMutex sync.Mutex // Prevents overlapping passes
+}
+
+// New creates a new weekly report service. Reports are kept in memory, so the
+// sensitive data they summarize is only stored in the (possibly encrypted)
+// mood history; after a restart the first pass regenerates last week's.
+func New(moods MoodSource, plays PlaySource, writer Writer) Service {
+	return &service{
+		moods:   moods,
+		plays:   plays,
+		writer:  writer,
+		reports: make(map[string]models.WeeklyReport),
+	}
+}
+
+// WeekStart returns the Monday 00:00 UTC starting the week that t is in
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+// Run reports last week for every user with mood history who has no report
+// for it yet
+func (s *service) Run() int {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	users, err := s.moods.ListMoodUsers()
+	if err != nil {
+		log.Printf("Weekly reports: %v", err)
+		return 0
+	}
+
+	weekStart := WeekStart(time.Now()).Add(-week)
+	generated := 0
+	for _, userID := range users {
+		if latest, ok := s.Latest(userID); ok && !latest.WeekStart.Before(weekStart) {
+			continue
+		}
+		report, err := s.Generate(userID, weekStart)
+		if err != nil {
+			log.Printf("Weekly reports: user %s: %v", userID, err)
+			continue
+		}
+		if report.MoodEntries > 0 {
+			generated++
+		}
+	}
+
+	log.Printf("Weekly reports: generated %d reports for the week of %s", generated, weekStart.Format("2006-01-02"))
+	return generated
+}
+
+// Latest returns the user's most recent report
+func (s *service) Latest(userID string) (models.WeeklyReport, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	report, ok := s.reports[userID]
+	return report, ok
+}
+
+// Forget drops the user's reports
+func (s *service) Forget(userID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.reports, userID)
+}
+
+// Generate builds the user's report for the week starting at weekStart. A
+// week without mood entries has nothing to report, so its report isn't kept.
+func (s *service) Generate(userID string, weekStart time.Time) (models.WeeklyReport, error) {
+	report := models.WeeklyReport{
+		UserID:        userID,
+		WeekStart:     weekStart.UTC(),
+		WeekEnd:       weekStart.UTC().Add(week),
+		MoodCounts:    make(map[string]int),
+		DominantMoods: []string{},
+		Correlations:  []models.MoodMusicCorrelation{},
+		NotableTracks: []models.ReportTrack{},
+	}
+
+	history, err := s.moods.GetUserMoodHistory(userID)
+	if err != nil {
+		return report, fmt.Errorf("failed to read mood history: %w", err)
+	}
+	var moodTimes []time.Time
+	var moodNames []string
+	for _, entry := range history {
+		at, err := time.Parse(time.RFC3339, entry.Timestamp)
+		if err != nil || at.Before(report.WeekStart) || !at.Before(report.WeekEnd) {
+			continue
+		}
+		report.MoodCounts[entry.DetectedMood]++
+		moodTimes = append(moodTimes, at)
+		moodNames = append(moodNames, entry.DetectedMood)
+	}
+	report.MoodEntries = len(moodNames)
+	if report.MoodEntries == 0 {
+		return report, nil
+	}
+	report.DominantMoods = rank(report.MoodCounts, dominantMoodCount)
+
+	// Plays up to correlationWindow past the week still follow its last moods
+	plays, err := s.weekPlays(report.WeekStart, report.WeekEnd.Add(correlationWindow))
+	if err != nil {
+		return report, err
+	}
+	correlate(&report, moodTimes, moodNames, plays)
+	notableTracks(&report, plays)
+	report.Summary = s.summarize(report)
+	report.GeneratedAt = time.Now()
+
+	s.mutex.Lock()
+	s.reports[userID] = report
+	s.mutex.Unlock()
+
+	return report, nil
+}
+
+// weekPlays returns the plays between from and to
+func (s *service) weekPlays(from, to time.Time) ([]models.PlayHistoryItem, error) {
+	if s.plays == nil {
+		return nil, nil
+	}
+	plays, _, err := s.plays.QueryPlayHistory(models.PlayHistoryFilter{From: from, To: to}, 0, maxWeekPlays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read play history: %w", err)
+	}
+	return plays, nil
+}
+
+// correlate counts the artists played within correlationWindow after each
+// mood entry. A play counts toward the latest mood detected before it.
+func correlate(report *models.WeeklyReport, moodTimes []time.Time, moodNames []string, plays []models.PlayHistoryItem) {
+	artists := make(map[string]map[string]int) // mood -> artist -> plays
+	totals := make(map[string]int)
+	for _, play := range plays {
+		latest := -1
+		for i, at := range moodTimes {
+			inWindow := !play.PlayedAt.Before(at) && play.PlayedAt.Before(at.Add(correlationWindow))
+			if inWindow && (latest < 0 || at.After(moodTimes[latest])) {
+				latest = i
+			}
+		}
+		if latest < 0 || play.Artist == "" {
+			continue
+		}
+
+		mood := moodNames[latest]
+		if artists[mood] == nil {
+			artists[mood] = make(map[string]int)
+		}
+		artists[mood][play.Artist] += playCount(play)
+		totals[mood] += playCount(play)
+	}
+
+	for _, mood := range rank(report.MoodCounts, len(report.MoodCounts)) {
+		if totals[mood] == 0 {
+			continue
+		}
+		report.Correlations = append(report.Correlations, models.MoodMusicCorrelation{
+			Mood:       mood,
+			Plays:      totals[mood],
+			TopArtists: rank(artists[mood], topArtistCount),
+		})
+	}
+}
+
+// notableTracks lists the week's most played tracks
+func notableTracks(report *models.WeeklyReport, plays []models.PlayHistoryItem) {
+	counts := make(map[string]int)
+	tracks := make(map[string]models.ReportTrack)
+	for _, play := range plays {
+		if !play.PlayedAt.Before(report.WeekEnd) {
+			continue
+		}
+		key := play.TrackID
+		if key == "" {
+			key = strings.ToLower(play.TrackName + "|" + play.Artist)
+		}
+		counts[key] += playCount(play)
+		tracks[key] = models.ReportTrack{TrackID: play.TrackID, Name: play.TrackName, Artist: play.Artist}
+	}
+
+	for _, key := range rank(counts, notableTrackCount) {
+		track := tracks[key]
+		track.Plays = counts[key]
+		report.NotableTracks = append(report.NotableTracks, track)
+	}
+}
+
+// summarize asks the writer for a short summary of the report, falling back
+// to a template
+func (s *service) summarize(report models.WeeklyReport) string {
+	fallback := templateSummary(report)
+	if s.writer == nil {
+		return fallback
+	}
+
+	stats, _ := json.Marshal(struct {
+		MoodCounts    map[string]int                `json:"mood_counts"`
+		DominantMoods []string                      `json:"dominant_moods"`
+		Correlations  []models.MoodMusicCorrelation `json:"music_after_moods"`
+		NotableTracks []models.ReportTrack          `json:"most_played_tracks"`
+	}{report.MoodCounts, report.DominantMoods, report.Correlations, report.NotableTracks})
+	prompt := "Write a warm, short weekly recap (at most 4 sentences) for a music listener, addressed to them as \"you\". " +
+		"Mention their dominant moods, how the music they played related to those moods, and a notable track. " +
+		"Don't give advice or draw conclusions beyond these statistics. Reply with the recap only.\n\n" +
+		"Statistics for the week of " + report.WeekStart.Format("January 2, 2006") + ":\n" + string(stats)
+
+	response, err := s.writer.GenerateResponse(prompt)
+	if err != nil {
+		log.Printf("Weekly reports: failed to write summary, using a template: %v", err)
+		return fallback
+	}
+	summary := strings.TrimSpace(response)
+	if summary == "" || len(summary) > maxSummaryLength {
+		return fallback
+	}
+	return summary
+}
+
+// templateSummary describes the report without the AI provider
+func templateSummary(report models.WeeklyReport) string {
+	summary := fmt.Sprintf("This week you mostly felt %s, across %d mood check-ins.", strings.Join(report.DominantMoods, ", "), report.MoodEntries)
+	if len(report.Correlations) > 0 {
+		correlation := report.Correlations[0]
+		summary += fmt.Sprintf(" When you felt %s, you listened to %s.", correlation.Mood, strings.Join(correlation.TopArtists, ", "))
+	}
+	if len(report.NotableTracks) > 0 {
+		track := report.NotableTracks[0]
+		summary += fmt.Sprintf(" Your most played track was %s by %s.", track.Name, track.Artist)
+	}
+	return summary
+}
+
+// playCount returns how many plays a history item stands for
+func playCount(play models.PlayHistoryItem) int {
+	if play.PlayCount > 0 {
+		return play.PlayCount
+	}
+	return 1
+}
+
+// rank returns up to limit keys by descending count, ties in key order
+func rank(counts map[string]int, limit int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys
+}
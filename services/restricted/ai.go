@@ -0,0 +1,29 @@
+package restricted
+
+// restrictedAI prepends the restricted system prompt to every request and
+// masks profanity in lyrics and answers
+type restrictedAI struct {
+	ai AIService
+}
+
+// AnalyzeLyrics analyzes masked lyrics under the restricted system prompt
+func (r *restrictedAI) AnalyzeLyrics(query, lyrics, songInfo string) (string, error) {
+	answer, err := r.ai.AnalyzeLyrics(SystemPrompt+query, MaskProfanity(lyrics), songInfo)
+	return MaskProfanity(answer), err
+}
+
+// GenerateResponse answers under the restricted system prompt
+func (r *restrictedAI) GenerateResponse(prompt string) (string, error) {
+	answer, err := r.ai.GenerateResponse(SystemPrompt + prompt)
+	return MaskProfanity(answer), err
+}
+
+// GenerateJSON answers in JSON mode under the restricted system prompt
+func (r *restrictedAI) GenerateJSON(prompt string) (string, error) {
+	return r.ai.GenerateJSON(SystemPrompt + prompt)
+}
+
+// IsAvailable checks the wrapped service
+func (r *restrictedAI) IsAvailable() error {
+	return r.ai.IsAvailable()
+}
@@ -0,0 +1,31 @@
+package restricted
+
+import (
+	"backend/services/profanity"
+	"regexp"
+)
+
+// SystemPrompt is prepended to every AI prompt for restricted users
+const SystemPrompt = `You are talking with a young listener. Keep every answer suitable for ages 13 and up:
+- Do not repeat profanity, slurs, sexual content or graphic violence, even when quoting lyrics; describe such themes only in general, age-appropriate terms.
+- Do not discuss drugs, alcohol, self-harm or suicide beyond gently suggesting talking to a trusted adult.
+- Keep emotional support warm but brief, and never encourage acting on anger.
+- Do not ask for or mention personal information, and do not suggest contacting other users.
+
+`
+
+// profanityFilter masks the built-in profanity list for restricted users
+var profanityFilter = profanity.New(profanity.Config{})
+
+// mentionPattern matches @mentions of other users
+var mentionPattern = regexp.MustCompile(`(^|\s)@[\w.\-]+`)
+
+// MaskProfanity replaces profane words with asterisks, keeping the first letter
+func MaskProfanity(text string) string {
+	return profanityFilter.Mask(text)
+}
+
+// ContainsMention reports whether text @mentions another user
+func ContainsMention(text string) bool {
+	return mentionPattern.MatchString(text)
+}
@@ -0,0 +1,32 @@
+package restricted
+
+import "backend/server/models"
+
+// AIService is the AI provider interface restricted mode constrains
+type AIService interface {
+	AnalyzeLyrics(query, lyrics, songInfo string) (string, error)
+	GenerateResponse(prompt string) (string, error)
+	GenerateJSON(prompt string) (string, error)
+	IsAvailable() error
+}
+
+// Service decides which users are in restricted (parental/teen) mode and
+// applies its content rules
+type Service interface {
+	// IsRestricted reports whether userID is in restricted mode, either
+	// because the whole deployment is or because it was enabled for the user
+	IsRestricted(userID string) bool
+
+	// SetRestricted enables or disables restricted mode for one user
+	SetRestricted(userID string, restricted bool)
+
+	// Status returns the user's restricted mode setting
+	Status(userID string) models.RestrictedModeStatus
+
+	// FilterTracks removes explicit tracks
+	FilterTracks(tracks []models.UnifiedTrack) []models.UnifiedTrack
+
+	// WrapAI constrains an AI service with the restricted system prompt
+	// and masks profanity in what goes in and out of it
+	WrapAI(ai AIService) AIService
+}
@@ -0,0 +1,79 @@
+package restricted
+
+import (
+	"backend/server/models"
+	"sync"
+)
+
+// Config holds restricted mode configuration
+type Config struct {
+	Deployment bool     // Restrict every user
+	Users      []string // Users restricted from startup
+}
+
+// service implements the restricted Service interface
+type service struct {
+	config Config
+	users  map[string]bool // userID -> per-user setting
+	mutex  sync.RWMutex
+}
+
+// New creates a new restricted mode service
+func New(config Config) Service {
+	users := make(map[string]bool, len(config.Users))
+	for _, userID := range config.Users {
+		users[userID] = true
+	}
+	return &service{
+		config: config,
+		users:  users,
+	}
+}
+
+// IsRestricted reports whether userID is in restricted mode
+func (s *service) IsRestricted(userID string) bool {
+	if s.config.Deployment {
+		return true
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.users[userID]
+}
+
+// SetRestricted enables or disables restricted mode for one user. It has no
+// effect while the whole deployment is restricted.
+func (s *service) SetRestricted(userID string, restricted bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if restricted {
+		s.users[userID] = true
+	} else {
+		delete(s.users, userID)
+	}
+}
+
+// Status returns the user's restricted mode setting
+func (s *service) Status(userID string) models.RestrictedModeStatus {
+	return models.RestrictedModeStatus{
+		UserID:     userID,
+		Restricted: s.IsRestricted(userID),
+		Deployment: s.config.Deployment,
+	}
+}
+
+// FilterTracks removes explicit tracks
+func (s *service) FilterTracks(tracks []models.UnifiedTrack) []models.UnifiedTrack {
+	filtered := make([]models.UnifiedTrack, 0, len(tracks))
+	for _, track := range tracks {
+		if !track.Explicit {
+			filtered = append(filtered, track)
+		}
+	}
+	return filtered
+}
+
+// WrapAI constrains an AI service with the restricted system prompt
+func (s *service) WrapAI(ai AIService) AIService {
+	return &restrictedAI{ai: ai}
+}
@@ -0,0 +1,20 @@
+package retention
+
+import "backend/server/models"
+
+// Sink stores archives of expired raw data
+type Sink interface {
+	// Write stores a compressed archive under name, e.g. "messages/global_messages-20240501.jsonl.gz"
+	Write(name string, data []byte) error
+}
+
+// Service defines the interface for history retention. Raw data older than
+// its retention period is archived to a Sink and then deleted; aggregates
+// such as the daily stats projections are kept forever.
+type Service interface {
+	// Run archives and deletes expired raw data once and returns the report
+	Run() models.RetentionReport
+
+	// LastReport returns the most recent report, or false if no pass has completed
+	LastReport() (models.RetentionReport, bool)
+}
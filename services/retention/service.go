@@ -0,0 +1,283 @@
+package retention
+
+import (
+	"backend/server/models"
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config holds retention policies. A zero period keeps that data forever.
+type Config struct {
+	DataDir              string        // Directory holding mood_history
+	MoodHistoryRetention time.Duration // How long raw mood history entries are kept
+	MessageRetention     time.Duration // How long global chat messages are kept
+}
+
+// DefaultConfig returns a default configuration for retention
+func DefaultConfig() Config {
+	return Config{
+		DataDir:              "./data",
+		MoodHistoryRetention: 2 * 365 * 24 * time.Hour,
+		MessageRetention:     2 * 365 * 24 * time.Hour,
+	}
+}
+
+// service implements the retention Service interface
+type service struct {
+	db          *sql.DB // May be nil, in which case messages are not expired
+	sink        Sink
+	config      Config
+	lastReport  *models.RetentionReport
+	reportMutex sync.RWMutex
+	runMutex    sync.Mutex // Prevents overlapping retention passes
+}
+
+// New creates a new retention service
+func New(db *sql.DB, sink Sink, config Config) Service {
+	return &service{
+		db:     db,
+		sink:   sink,
+		config: config,
+	}
+}
+
+// Run archives and deletes expired raw data once and returns the report
+func (s *service) Run() models.RetentionReport {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	report := models.RetentionReport{
+		StartedAt: time.Now(),
+		Archives:  []string{},
+	}
+
+	if s.config.MoodHistoryRetention > 0 {
+		s.expireMoodHistory(report.StartedAt.Add(-s.config.MoodHistoryRetention), &report)
+	}
+	if s.config.MessageRetention > 0 && s.db != nil {
+		s.expireMessages(report.StartedAt.Add(-s.config.MessageRetention), &report)
+	}
+
+	report.FinishedAt = time.Now()
+
+	s.reportMutex.Lock()
+	s.lastReport = &report
+	s.reportMutex.Unlock()
+
+	log.Printf("Retention: archived %d mood entries and %d messages into %d archives, %d errors",
+		report.MoodEntriesArchived, report.MessagesArchived, len(report.Archives), len(report.Errors))
+
+	return report
+}
+
+// LastReport returns the most recent report, or false if no pass has completed
+func (s *service) LastReport() (models.RetentionReport, bool) {
+	s.reportMutex.RLock()
+	defer s.reportMutex.RUnlock()
+
+	if s.lastReport == nil {
+		return models.RetentionReport{}, false
+	}
+	return *s.lastReport, true
+}
+
+// expireMoodHistory moves mood history entries older than cutoff from each
+// user's history file into an archive
+func (s *service) expireMoodHistory(cutoff time.Time, report *models.RetentionReport) {
+	files, err := filepath.Glob(filepath.Join(s.config.DataDir, "mood_history", "*.txt"))
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return
+	}
+
+	for _, file := range files {
+		archived, name, err := s.expireMoodHistoryFile(file, cutoff)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", filepath.Base(file), err))
+			continue
+		}
+		if archived > 0 {
+			report.MoodEntriesArchived += archived
+			report.Archives = append(report.Archives, name)
+		}
+	}
+}
+
+// expireMoodHistoryFile archives the expired entries of one history file and
+// rewrites it with the rest. Entries are "RFC3339 timestamp|mood|songs" lines.
+func (s *service) expireMoodHistoryFile(file string, cutoff time.Time) (int, string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var expired, kept []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if line == "" {
+			continue
+		}
+		timestamp, _, _ := strings.Cut(line, "|")
+		if at, err := time.Parse(time.RFC3339, timestamp); err == nil && at.Before(cutoff) {
+			expired = append(expired, line)
+		} else {
+			kept = append(kept, line)
+		}
+	}
+	if len(expired) == 0 {
+		return 0, "", nil
+	}
+
+	name := fmt.Sprintf("mood_history/%s-%s.txt.gz",
+		strings.TrimSuffix(filepath.Base(file), ".txt"), cutoff.UTC().Format("20060102T150405Z"))
+	data, err := compress([]byte(strings.Join(expired, "\n") + "\n"))
+	if err != nil {
+		return 0, "", err
+	}
+	if err := s.sink.Write(name, data); err != nil {
+		return 0, "", err
+	}
+
+	remaining := ""
+	if len(kept) > 0 {
+		remaining = strings.Join(kept, "\n") + "\n"
+	}
+	temp := file + ".tmp"
+	if err := os.WriteFile(temp, []byte(remaining), 0644); err != nil {
+		return 0, "", fmt.Errorf("archived to %s but failed to rewrite history: %w", name, err)
+	}
+
+	// An entry appended while we were archiving would be lost by the rename;
+	// leave the file alone and retry on the next pass
+	if info, err := os.Stat(file); err != nil || info.Size() != int64(len(content)) {
+		os.Remove(temp)
+		return 0, "", fmt.Errorf("history changed during archival, retrying next pass (archive %s may be duplicated)", name)
+	}
+	if err := os.Rename(temp, file); err != nil {
+		return 0, "", fmt.Errorf("archived to %s but failed to rewrite history: %w", name, err)
+	}
+	return len(expired), name, nil
+}
+
+// expireMessages archives global chat messages older than cutoff as JSON
+// lines and deletes them once the archive is stored
+func (s *service) expireMessages(cutoff time.Time, report *models.RetentionReport) {
+	rows, err := s.db.Query(`
+        SELECT id, user_email, username, message_text, message_type, payload, parent_message_id, created_at
+        FROM global_messages
+        WHERE created_at < $1
+        ORDER BY id ASC
+    `, cutoff)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("messages: %v", err))
+		return
+	}
+
+	var messages []models.Message
+	for rows.Next() {
+		var message models.Message
+		var payload []byte
+		var parentID sql.NullInt64
+		if err := rows.Scan(&message.ID, &message.UserEmail, &message.Username, &message.Text, &message.MessageType, &payload, &parentID, &message.CreatedAt); err != nil {
+			rows.Close()
+			report.Errors = append(report.Errors, fmt.Sprintf("messages: %v", err))
+			return
+		}
+		if len(payload) > 0 {
+			message.Payload = payload
+		}
+		if parentID.Valid {
+			message.ParentMessageID = &parentID.Int64
+		}
+		messages = append(messages, message)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("messages: %v", err))
+		return
+	}
+	if len(messages) == 0 {
+		return
+	}
+	maxID := messages[len(messages)-1].ID
+
+	// Reactions are deleted with their messages, so they go in the archive too
+	reactions, err := s.messageReactions(maxID, cutoff)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("messages: %v", err))
+		return
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, message := range messages {
+		message.Reactions = reactions[message.ID]
+		encoder.Encode(message)
+	}
+
+	name := fmt.Sprintf("messages/global_messages-%s.jsonl.gz", cutoff.UTC().Format("20060102T150405Z"))
+	data, err := compress(buf.Bytes())
+	if err == nil {
+		err = s.sink.Write(name, data)
+	}
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("messages: %v", err))
+		return
+	}
+
+	if _, err := s.db.Exec(`DELETE FROM global_messages WHERE id <= $1 AND created_at < $2`, maxID, cutoff); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("messages: archived to %s but failed to delete: %v", name, err))
+		return
+	}
+	report.MessagesArchived += len(messages)
+	report.Archives = append(report.Archives, name)
+}
+
+// messageReactions returns the reaction counts of the messages expireMessages
+// archives, by message
+func (s *service) messageReactions(maxID int64, cutoff time.Time) (map[int64][]models.ReactionCount, error) {
+	rows, err := s.db.Query(`
+        SELECT r.message_id, r.emoji, COUNT(*)
+        FROM message_reactions r
+        JOIN global_messages m ON m.id = r.message_id
+        WHERE m.id <= $1 AND m.created_at < $2
+        GROUP BY r.message_id, r.emoji
+        ORDER BY r.message_id, MIN(r.created_at)
+    `, maxID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reactions := make(map[int64][]models.ReactionCount)
+	for rows.Next() {
+		var messageID int64
+		var count models.ReactionCount
+		if err := rows.Scan(&messageID, &count.Emoji, &count.Count); err != nil {
+			return nil, err
+		}
+		reactions[messageID] = append(reactions[messageID], count)
+	}
+	return reactions, rows.Err()
+}
+
+// compress gzips data
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
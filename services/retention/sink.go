@@ -0,0 +1,81 @@
+package retention
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dirSink writes archives below a local directory
+type dirSink struct {
+	dir string
+}
+
+// NewDirSink creates a Sink that writes archives below dir
+func NewDirSink(dir string) Sink {
+	return &dirSink{dir: dir}
+}
+
+// Write stores an archive, refusing to overwrite an existing one
+func (d *dirSink) Write(name string, data []byte) error {
+	path := filepath.Join(d.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return f.Close()
+}
+
+// httpSink uploads archives to object storage with HTTP PUT, e.g. an S3 or
+// GCS bucket URL behind a signing proxy, or MinIO
+type httpSink struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewHTTPSink creates a Sink that PUTs archives to baseURL/name, sending
+// token as a bearer token if it is set
+func NewHTTPSink(baseURL, token string) Sink {
+	return &httpSink{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// Write uploads an archive
+func (h *httpSink) Write(name string, data []byte) error {
+	req, err := http.NewRequest("PUT", h.baseURL+"/"+name, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload archive: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("archive upload returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package safety
+
+import (
+	"backend/server/models"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseHelplines parses semicolon-separated helplines such as
+//
+//	US=988 Suicide & Crisis Lifeline|988|https://988lifeline.org; *=Find A Helpline||https://findahelpline.com
+//
+// Each entry is a region code, or "*" for every region, followed by the
+// helpline's name, phone number and URL. Either the phone or the URL may be empty.
+func ParseHelplines(value string) ([]models.Helpline, error) {
+	var helplines []models.Helpline
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		region, details, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("helpline %q: expected \"REGION=name|phone|url\"", entry)
+		}
+		region = strings.ToUpper(strings.TrimSpace(region))
+		if region != internationalRegion && (len(region) != 2 || strings.Trim(region, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "") {
+			return nil, fmt.Errorf("helpline %q: region must be a two-letter country code or *", entry)
+		}
+
+		fields := strings.Split(details, "|")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("helpline %q: expected \"name|phone|url\"", entry)
+		}
+		helpline := models.Helpline{
+			Region: region,
+			Name:   strings.TrimSpace(fields[0]),
+			Phone:  strings.TrimSpace(fields[1]),
+			URL:    strings.TrimSpace(fields[2]),
+		}
+		if helpline.Name == "" {
+			return nil, fmt.Errorf("helpline %q: name is required", entry)
+		}
+		if helpline.Phone == "" && helpline.URL == "" {
+			return nil, fmt.Errorf("helpline %q: a phone number or URL is required", entry)
+		}
+		if helpline.URL != "" {
+			parsed, err := url.Parse(helpline.URL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return nil, fmt.Errorf("helpline %q: URL must be an absolute http or https URL", entry)
+			}
+		}
+
+		helplines = append(helplines, helpline)
+	}
+	return helplines, nil
+}
@@ -0,0 +1,14 @@
+package safety
+
+import "backend/server/models"
+
+// Service recognizes messages indicating severe distress or self-harm and
+// provides the support resources to answer them with
+type Service interface {
+	// Screen reports whether a message indicates severe distress or self-harm
+	Screen(message string) bool
+
+	// Resources returns the helplines for a region, falling back to the
+	// default region and then to international resources
+	Resources(region string) []models.Helpline
+}
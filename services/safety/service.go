@@ -0,0 +1,96 @@
+package safety
+
+import (
+	"backend/server/models"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Config holds crisis support settings
+type Config struct {
+	Helplines     []models.Helpline
+	DefaultRegion string // Region used when a request doesn't give one or it has no helplines
+}
+
+// internationalRegion marks helplines offered whatever the region
+const internationalRegion = "*"
+
+// crisisPhrases indicate severe distress or self-harm, in the languages the
+// chat answers in. They are matched case-insensitively as whole words, erring
+// on the side of offering support.
+var crisisPhrases = []string{
+	// English
+	"suicide", "suicidal", "kill myself", "killing myself", "end my life", "ending my life",
+	"take my own life", "want to die", "wanna die", "don't want to live", "don't want to be alive",
+	"no reason to live", "not worth living", "better off dead", "end it all",
+	"self harm", "self-harm", "hurt myself", "hurting myself", "cut myself", "cutting myself",
+	// Spanish
+	"suicidarme", "suicidio", "matarme", "quiero morir", "no quiero vivir", "hacerme daño",
+	// French
+	"me suicider", "veux me tuer", "vais me tuer", "envie de mourir", "veux mourir", "me faire du mal",
+	// German
+	"selbstmord", "suizid", "mich umbringen", "will sterben", "nicht mehr leben", "mir etwas antun",
+	// Portuguese
+	"me matar", "suicídio", "quero morrer", "não quero viver", "me machucar",
+}
+
+// service implements the safety Service interface
+type service struct {
+	helplines     map[string][]models.Helpline // region -> helplines
+	defaultRegion string
+}
+
+// New creates a new safety service
+func New(config Config) Service {
+	helplines := make(map[string][]models.Helpline)
+	for _, helpline := range config.Helplines {
+		helplines[helpline.Region] = append(helplines[helpline.Region], helpline)
+	}
+	return &service{
+		helplines:     helplines,
+		defaultRegion: strings.ToUpper(config.DefaultRegion),
+	}
+}
+
+// Screen reports whether a message indicates severe distress or self-harm
+func (s *service) Screen(message string) bool {
+	normalized := strings.ToLower(strings.ReplaceAll(message, "’", "'"))
+	for _, phrase := range crisisPhrases {
+		if containsWords(normalized, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWords reports whether phrase occurs in text between word
+// boundaries, so "want to die" doesn't match "want to diet"
+func containsWords(text, phrase string) bool {
+	for offset := 0; ; {
+		index := strings.Index(text[offset:], phrase)
+		if index < 0 {
+			return false
+		}
+		start, end := offset+index, offset+index+len(phrase)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !unicode.IsLetter(before) && !unicode.IsLetter(after) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// Resources returns the helplines for a region, falling back to the default
+// region, followed by the international ones
+func (s *service) Resources(region string) []models.Helpline {
+	var resources []models.Helpline
+	for _, candidate := range []string{strings.ToUpper(strings.TrimSpace(region)), s.defaultRegion} {
+		if local, ok := s.helplines[candidate]; ok && candidate != "" {
+			resources = append(resources, local...)
+			break
+		}
+	}
+	return append(resources, s.helplines[internationalRegion]...)
+}
@@ -0,0 +1,37 @@
+package sanitize
+
+import (
+	"backend/server/models"
+	"backend/services/genius"
+)
+
+// geniusService sanitizes the lyrics a Genius service fetches
+type geniusService struct {
+	genius    genius.Service
+	sanitizer Service
+}
+
+// NewGenius wraps a Genius service so that its lyrics are sanitized before
+// anything caches them or puts them in a prompt
+func NewGenius(service genius.Service, sanitizer Service) genius.Service {
+	return &geniusService{genius: service, sanitizer: sanitizer}
+}
+
+// GetLyrics fetches lyrics and sanitizes them
+func (s *geniusService) GetLyrics(trackName, artistName string) (string, error) {
+	lyrics, err := s.genius.GetLyrics(trackName, artistName)
+	if err != nil {
+		return "", err
+	}
+	return s.sanitizer.Sanitize(lyrics), nil
+}
+
+// GetArtist fetches an artist as is
+func (s *geniusService) GetArtist(name string) (*models.GeniusArtist, error) {
+	return s.genius.GetArtist(name)
+}
+
+// GetSong fetches a song's metadata as is
+func (s *geniusService) GetSong(trackName, artistName string) (*models.GeniusSong, error) {
+	return s.genius.GetSong(trackName, artistName)
+}
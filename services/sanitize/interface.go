@@ -0,0 +1,7 @@
+package sanitize
+
+// Service cleans up scraped lyrics
+type Service interface {
+	// Sanitize runs lyrics through the configured steps
+	Sanitize(lyrics string) string
+}
@@ -0,0 +1,103 @@
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Config selects the sanitization steps
+type Config struct {
+	StripSectionMarkers bool // Remove [Verse 1], [Chorus: Artist] and similar markers
+	RemoveNoise         bool // Remove Genius contributor headers, "Embed" trailers and ads
+	CollapseWhitespace  bool // Trim lines, collapse runs of spaces and keep at most one blank line between stanzas
+}
+
+// DefaultConfig returns a default configuration for the sanitizer. Section
+// markers are kept, since they help the AI see the song's structure.
+func DefaultConfig() Config {
+	return Config{
+		RemoveNoise:        true,
+		CollapseWhitespace: true,
+	}
+}
+
+var (
+	// contributorHeader matches the header Genius puts before the lyrics, e.g.
+	// "42 ContributorsTranslationsEspañolNumb Lyrics"
+	contributorHeader = regexp.MustCompile(`(?i)^\s*\d*\s*contributors?[^\n]*?lyrics\b[ \t]*`)
+	// embedTrailer matches the "Embed" label after the lyrics, with or without
+	// the count of pyongs glued to it
+	embedTrailer = regexp.MustCompile(`(?i)\s*(you might also like)?\s*\d*\s*embed\s*$`)
+	// ads match the texts Genius inserts between stanzas
+	ads = regexp.MustCompile(`(?i)you might also like|see [^\n]*? live\s*get tickets as low as \$\d+`)
+	// sectionMarker matches a bracketed section marker and the spaces after it
+	sectionMarker = regexp.MustCompile(`\[[^\]\n]*\][ \t]*`)
+	// spaceRun matches runs of spaces and tabs
+	spaceRun = regexp.MustCompile(`[ \t]+`)
+	// blankLines matches two or more blank lines
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// service implements Service as a pipeline of text steps
+type service struct {
+	steps []func(string) string
+}
+
+// New creates a sanitizer running the steps enabled in config
+func New(config Config) Service {
+	steps := []func(string) string{normalizeNewlines}
+	if config.RemoveNoise {
+		steps = append(steps, removeNoise)
+	}
+	if config.StripSectionMarkers {
+		steps = append(steps, stripSectionMarkers)
+	}
+	if config.CollapseWhitespace {
+		steps = append(steps, collapseWhitespace)
+	}
+	return &service{steps: steps}
+}
+
+// Sanitize runs lyrics through the configured steps
+func (s *service) Sanitize(lyrics string) string {
+	for _, step := range s.steps {
+		lyrics = step(lyrics)
+	}
+	return strings.TrimSpace(lyrics)
+}
+
+// normalizeNewlines turns Windows and old Mac line endings into \n
+func normalizeNewlines(text string) string {
+	return strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(text)
+}
+
+// removeNoise removes the contributor header, the Embed trailer and ads
+func removeNoise(text string) string {
+	text = contributorHeader.ReplaceAllString(text, "")
+	text = embedTrailer.ReplaceAllString(text, "")
+	return ads.ReplaceAllString(text, "\n")
+}
+
+// stripSectionMarkers removes section markers, and lines left empty by them
+func stripSectionMarkers(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		stripped := sectionMarker.ReplaceAllString(line, "")
+		if strings.TrimSpace(stripped) == "" && strings.TrimSpace(line) != "" {
+			continue
+		}
+		kept = append(kept, stripped)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// collapseWhitespace trims lines, collapses runs of spaces and limits blank
+// lines to one between stanzas
+func collapseWhitespace(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaceRun.ReplaceAllString(line, " "))
+	}
+	return blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
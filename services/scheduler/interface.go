@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"backend/server/models"
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrDuplicateJob is returned when a job is registered under a name already in use
+	ErrDuplicateJob = errors.New("job already registered")
+	// ErrInvalidJob is returned for jobs without a name, interval or function
+	ErrInvalidJob = errors.New("invalid job")
+)
+
+// Job is periodic background work
+type Job struct {
+	Name     string        // Identifies the job in logs, the jobs endpoint and JOBS_DISABLED
+	Interval time.Duration // Time between the starts of two runs, before jitter
+	Run      func(ctx context.Context) error
+}
+
+// Service defines the interface for the background job scheduler
+type Service interface {
+	// Register adds a job. Jobs must be registered before Start.
+	Register(job Job) error
+
+	// Start runs every enabled job once and then every interval, plus jitter,
+	// until ctx is cancelled. A run that is due while the job's previous run is
+	// still going is skipped.
+	Start(ctx context.Context)
+
+	// Jobs describes the registered jobs, sorted by name
+	Jobs() []models.ScheduledJob
+}
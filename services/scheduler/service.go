@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"backend/server/models"
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Config holds scheduler configuration
+type Config struct {
+	Jitter   float64  // Share of the interval each run may be delayed by at random, spreading load across instances
+	Disabled []string // Names of jobs that are registered but never run
+}
+
+// DefaultConfig returns a default configuration for the scheduler
+func DefaultConfig() Config {
+	return Config{
+		Jitter: 0.1,
+	}
+}
+
+// job is a registered job and its run state
+type job struct {
+	Job
+	enabled bool
+	status  models.ScheduledJob
+}
+
+// service implements the scheduler Service interface with one timer per job
+type service struct {
+	config  Config
+	jobs    map[string]*job
+	started bool
+	mutex   sync.Mutex
+}
+
+// New creates a new scheduler
+func New(config Config) Service {
+	return &service{
+		config: config,
+		jobs:   make(map[string]*job),
+	}
+}
+
+// Register adds a job
+func (s *service) Register(j Job) error {
+	if j.Name == "" || j.Interval <= 0 || j.Run == nil {
+		return fmt.Errorf("%w: %q needs a name, a positive interval and a function", ErrInvalidJob, j.Name)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.started {
+		return fmt.Errorf("%w: %q registered after the scheduler started", ErrInvalidJob, j.Name)
+	}
+	if _, exists := s.jobs[j.Name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, j.Name)
+	}
+
+	enabled := true
+	for _, name := range s.config.Disabled {
+		if name == j.Name {
+			enabled = false
+		}
+	}
+	s.jobs[j.Name] = &job{
+		Job:     j,
+		enabled: enabled,
+		status: models.ScheduledJob{
+			Name:     j.Name,
+			Interval: j.Interval.String(),
+			Enabled:  enabled,
+		},
+	}
+	return nil
+}
+
+// Start runs every enabled job once and then every interval until ctx is cancelled
+func (s *service) Start(ctx context.Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	for _, name := range s.config.Disabled {
+		if _, ok := s.jobs[name]; !ok {
+			log.Printf("Scheduler: disabled job %q is not registered", name)
+		}
+	}
+	for _, j := range s.jobs {
+		if !j.enabled {
+			log.Printf("Scheduler: job %s is disabled", j.Name)
+			continue
+		}
+		go s.loop(ctx, j)
+	}
+}
+
+// Jobs describes the registered jobs, sorted by name
+func (s *service) Jobs() []models.ScheduledJob {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	jobs := make([]models.ScheduledJob, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j.status)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].Name < jobs[k].Name
+	})
+	return jobs
+}
+
+// loop starts the job's runs until ctx is cancelled. Runs happen in their own
+// goroutine, so a slow run doesn't delay the schedule; due runs are skipped
+// while it lasts.
+func (s *service) loop(ctx context.Context, j *job) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		delay := s.delay(j.Interval)
+		if s.begin(j, time.Now().Add(delay)) {
+			go s.run(ctx, j)
+		} else {
+			log.Printf("Scheduler: skipped %s, its previous run is still going", j.Name)
+		}
+		timer.Reset(delay)
+	}
+}
+
+// delay returns the interval plus a random share of up to Jitter of it
+func (s *service) delay(interval time.Duration) time.Duration {
+	if s.config.Jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Float64()*s.config.Jitter*float64(interval))
+}
+
+// begin marks a run of the job as started and records when the next one is
+// due. It returns false, counting a skipped run, if the job is already running.
+func (s *service) begin(j *job, next time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	j.status.NextRunAt = &next
+	if j.status.Running {
+		j.status.Skipped++
+		return false
+	}
+	now := time.Now()
+	j.status.Running = true
+	j.status.LastStartedAt = &now
+	return true
+}
+
+// run runs the job once and records the outcome
+func (s *service) run(ctx context.Context, j *job) {
+	err := call(ctx, j.Run)
+	if err != nil {
+		log.Printf("Scheduler: job %s failed: %v", j.Name, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	j.status.Running = false
+	j.status.Runs++
+	j.status.LastFinishedAt = &now
+	j.status.LastError = ""
+	if err != nil {
+		j.status.Failures++
+		j.status.LastError = err.Error()
+	}
+}
+
+// call runs fn, turning a panic into an error so one broken job can't take
+// the server down
+func call(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,12 @@
+package search
+
+import "backend/server/models"
+
+// Service defines the interface for search-as-you-type suggestions
+type Service interface {
+	// Add indexes a track, increasing its popularity if it is already known
+	Add(track models.UnifiedTrack)
+
+	// Suggest returns up to limit tracks whose name or artist words start with the query terms
+	Suggest(query string, limit int) []models.SearchSuggestion
+}
@@ -0,0 +1,198 @@
+package search
+
+import (
+	"backend/server/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	// maxPrefixLength bounds how long an indexed prefix can be
+	maxPrefixLength = 20
+	// cacheTTL is how long a suggestion result is reused for the same query
+	cacheTTL = time.Minute
+	// maxCacheEntries caps the result cache; it is reset when full
+	maxCacheEntries = 1000
+)
+
+// indexedTrack is a track in the index together with its popularity
+type indexedTrack struct {
+	suggestion models.SearchSuggestion
+	plays      int
+}
+
+// cacheEntry is a cached suggestion result
+type cacheEntry struct {
+	results    []models.SearchSuggestion
+	generation uint64
+	expiresAt  time.Time
+}
+
+// service implements the search Service interface with an in-memory prefix index
+type service struct {
+	tracks     map[string]*indexedTrack       // track key -> track
+	prefixes   map[string]map[string]struct{} // word prefix -> track keys
+	generation uint64                         // bumped on every index change to invalidate the cache
+	cache      map[string]cacheEntry
+	mutex      sync.RWMutex
+	cacheMutex sync.Mutex
+}
+
+// New creates a new search service
+func New() Service {
+	return &service{
+		tracks:   make(map[string]*indexedTrack),
+		prefixes: make(map[string]map[string]struct{}),
+		cache:    make(map[string]cacheEntry),
+	}
+}
+
+// Add indexes a track, increasing its popularity if it is already known
+func (s *service) Add(track models.UnifiedTrack) {
+	if track.ID == "" || track.Name == "" {
+		return
+	}
+	key := track.Source + ":" + track.ID
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.generation++
+
+	if existing, ok := s.tracks[key]; ok {
+		existing.plays++
+		return
+	}
+
+	s.tracks[key] = &indexedTrack{
+		suggestion: models.SearchSuggestion{
+			ID:       track.ID,
+			Name:     track.Name,
+			Artist:   track.Artist,
+			Source:   track.Source,
+			ImageURL: track.ImageURL,
+		},
+	}
+
+	for _, word := range tokenize(track.Name + " " + track.Artist) {
+		runes := []rune(word)
+		for i := 1; i <= len(runes) && i <= maxPrefixLength; i++ {
+			prefix := string(runes[:i])
+			if s.prefixes[prefix] == nil {
+				s.prefixes[prefix] = make(map[string]struct{})
+			}
+			s.prefixes[prefix][key] = struct{}{}
+		}
+	}
+}
+
+// Suggest returns up to limit tracks whose name or artist words start with the query terms
+func (s *service) Suggest(query string, limit int) []models.SearchSuggestion {
+	terms := tokenize(query)
+	if len(terms) == 0 || limit <= 0 {
+		return []models.SearchSuggestion{}
+	}
+	cacheKey := strings.Join(terms, " ")
+
+	s.mutex.RLock()
+	generation := s.generation
+	s.mutex.RUnlock()
+
+	if results, ok := s.cached(cacheKey, generation); ok {
+		return truncate(results, limit)
+	}
+
+	s.mutex.RLock()
+	results, generation := s.lookup(terms), s.generation
+	s.mutex.RUnlock()
+
+	s.store(cacheKey, results, generation)
+	return truncate(results, limit)
+}
+
+// lookup finds all tracks matching every term, most played first; callers must hold the read lock
+func (s *service) lookup(terms []string) []models.SearchSuggestion {
+	var matches []*indexedTrack
+
+	for key := range s.prefixes[truncatePrefix(terms[0])] {
+		matched := true
+		for _, term := range terms[1:] {
+			if _, ok := s.prefixes[truncatePrefix(term)][key]; !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, s.tracks[key])
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].plays != matches[j].plays {
+			return matches[i].plays > matches[j].plays
+		}
+		return matches[i].suggestion.Name < matches[j].suggestion.Name
+	})
+
+	results := make([]models.SearchSuggestion, len(matches))
+	for i, match := range matches {
+		results[i] = match.suggestion
+	}
+	return results
+}
+
+// cached returns a non-expired cache entry built from the current index generation
+func (s *service) cached(key string, generation uint64) ([]models.SearchSuggestion, bool) {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+
+	entry, ok := s.cache[key]
+	if !ok || entry.generation != generation || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.results, true
+}
+
+// store caches a suggestion result, resetting the cache when it is full
+func (s *service) store(key string, results []models.SearchSuggestion, generation uint64) {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+
+	if len(s.cache) >= maxCacheEntries {
+		s.cache = make(map[string]cacheEntry)
+	}
+	s.cache[key] = cacheEntry{
+		results:    results,
+		generation: generation,
+		expiresAt:  time.Now().Add(cacheTTL),
+	}
+}
+
+// tokenize lowercases text and splits it into alphanumeric words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// truncatePrefix limits a query term to the longest indexed prefix length
+func truncatePrefix(term string) string {
+	runes := []rune(term)
+	if len(runes) > maxPrefixLength {
+		return string(runes[:maxPrefixLength])
+	}
+	return term
+}
+
+// truncate returns at most limit results without modifying the cached slice
+func truncate(results []models.SearchSuggestion, limit int) []models.SearchSuggestion {
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	out := make([]models.SearchSuggestion, len(results))
+	copy(out, results)
+	return out
+}
@@ -1,9 +1,37 @@
 package spotify
 
-import "backend/server/models"
+import (
+	"backend/server/models"
+	"errors"
+)
+
+// ErrTrackNotFound is returned when Spotify does not know a track ID
+var ErrTrackNotFound = errors.New("spotify track not found")
+
+// ErrArtistNotFound is returned when a search finds no artist
+var ErrArtistNotFound = errors.New("spotify artist not found")
+
+// ErrAlbumNotFound is returned when Spotify does not know an album ID
+var ErrAlbumNotFound = errors.New("spotify album not found")
+
+// ErrUserTokenRejected is returned when Spotify refuses a user's access token,
+// e.g. because it expired or lacks the needed scope
+var ErrUserTokenRejected = errors.New("spotify user token rejected")
 
 // Service defines the Spotify service interface
 type Service interface {
 	GetAccessToken() (string, error)
 	GetTrackByID(trackID string) (*models.SpotifyTrack, error)
+	SearchTrack(name, artist string) (*models.UnifiedTrack, error)
+	SearchArtist(name string) (*models.SpotifyArtist, error)
+	GetRelatedArtists(artistID string) ([]models.SpotifyArtist, error)
+	GetArtistTopTracks(artistID string) ([]models.UnifiedTrack, error)
+	// GetAlbum gets an album with its full track list, in album order
+	GetAlbum(albumID string) (*models.SpotifyAlbum, error)
+	// AddToQueue adds a track to the queue of the user's active Spotify
+	// player, authorized by the user's own access token
+	AddToQueue(userToken, trackID string) error
+	// CreatePlaylist creates a private playlist of trackIDs on the user's
+	// account, authorized by the user's own access token
+	CreatePlaylist(userToken, name, description string, trackIDs []string) (*models.SpotifyPlaylist, error)
 }
\ No newline at end of file
@@ -2,8 +2,10 @@ package spotify
 
 import (
 	"backend/server/models"
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -110,6 +112,11 @@ func (s *service) GetTrackByID(trackID string) (*models.SpotifyTrack, error) {
 	}
 	defer resp.Body.Close()
 
+	// Spotify answers 400 for malformed IDs and 404 for unknown ones
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		return nil, fmt.Errorf("%w: %s", ErrTrackNotFound, trackID)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("spotify API failed with status %d: %s", resp.StatusCode, string(body))
@@ -141,10 +148,337 @@ func (s *service) GetTrackByID(trackID string) (*models.SpotifyTrack, error) {
 
 	// Extract preview URL if available
 	track.PreviewURL = s.getString(result, "preview_url")
+	track.Explicit, _ = result["explicit"].(bool)
 
 	return track, nil
 }
 
+// SearchTrack finds the best matching track for a name and artist
+func (s *service) SearchTrack(name, artist string) (*models.UnifiedTrack, error) {
+	query := url.Values{}
+	query.Set("q", fmt.Sprintf("track:%s artist:%s", name, artist))
+	query.Set("type", "track")
+	query.Set("limit", "1")
+
+	var result struct {
+		Tracks struct {
+			Items []spotifyTrackObject `json:"items"`
+		} `json:"tracks"`
+	}
+	if err := s.getJSON("https://api.spotify.com/v1/search?"+query.Encode(), &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Tracks.Items) == 0 {
+		return nil, fmt.Errorf("%w: no match for %q by %q", ErrTrackNotFound, name, artist)
+	}
+
+	track := result.Tracks.Items[0].toUnifiedTrack()
+	return &track, nil
+}
+
+// SearchArtist finds the best matching artist for a name
+func (s *service) SearchArtist(name string) (*models.SpotifyArtist, error) {
+	query := url.Values{}
+	query.Set("q", name)
+	query.Set("type", "artist")
+	query.Set("limit", "1")
+
+	var result struct {
+		Artists struct {
+			Items []models.SpotifyArtist `json:"items"`
+		} `json:"artists"`
+	}
+	if err := s.getJSON("https://api.spotify.com/v1/search?"+query.Encode(), &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Artists.Items) == 0 {
+		return nil, fmt.Errorf("%w: no match for %q", ErrArtistNotFound, name)
+	}
+
+	return &result.Artists.Items[0], nil
+}
+
+// GetRelatedArtists gets artists similar to the given artist
+func (s *service) GetRelatedArtists(artistID string) ([]models.SpotifyArtist, error) {
+	var result struct {
+		Artists []models.SpotifyArtist `json:"artists"`
+	}
+	urlStr := fmt.Sprintf("https://api.spotify.com/v1/artists/%s/related-artists", url.PathEscape(artistID))
+	if err := s.getJSON(urlStr, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Artists, nil
+}
+
+// GetArtistTopTracks gets an artist's most popular tracks
+func (s *service) GetArtistTopTracks(artistID string) ([]models.UnifiedTrack, error) {
+	var result struct {
+		Tracks []spotifyTrackObject `json:"tracks"`
+	}
+	urlStr := fmt.Sprintf("https://api.spotify.com/v1/artists/%s/top-tracks?market=US", url.PathEscape(artistID))
+	if err := s.getJSON(urlStr, &result); err != nil {
+		return nil, err
+	}
+
+	tracks := make([]models.UnifiedTrack, 0, len(result.Tracks))
+	for _, t := range result.Tracks {
+		tracks = append(tracks, t.toUnifiedTrack())
+	}
+
+	return tracks, nil
+}
+
+// maxAlbumTracks caps the tracks fetched for an album, e.g. for box sets
+const maxAlbumTracks = 200
+
+// GetAlbum gets an album with its track list, following the list's pages
+func (s *service) GetAlbum(albumID string) (*models.SpotifyAlbum, error) {
+	var result struct {
+		ID          string                `json:"id"`
+		Name        string                `json:"name"`
+		ReleaseDate string                `json:"release_date"`
+		Images      []models.SpotifyImage `json:"images"`
+		Artists     []struct {
+			Name string `json:"name"`
+		} `json:"artists"`
+		ExternalURLs struct {
+			Spotify string `json:"spotify"`
+		} `json:"external_urls"`
+		Tracks spotifyAlbumTracks `json:"tracks"`
+	}
+	urlStr := fmt.Sprintf("https://api.spotify.com/v1/albums/%s", url.PathEscape(albumID))
+	if err := s.getJSON(urlStr, &result); err != nil {
+		// Spotify answers 400 for malformed IDs and 404 for unknown ones
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusBadRequest) {
+			return nil, fmt.Errorf("%w: %s", ErrAlbumNotFound, albumID)
+		}
+		return nil, err
+	}
+
+	album := &models.SpotifyAlbum{
+		ID:          result.ID,
+		Name:        result.Name,
+		ReleaseDate: result.ReleaseDate,
+		Images:      result.Images,
+		URL:         result.ExternalURLs.Spotify,
+	}
+	if len(result.Artists) > 0 {
+		album.Artist = result.Artists[0].Name
+	}
+
+	page := result.Tracks
+	for {
+		for _, t := range page.Items {
+			track := t.toUnifiedTrack()
+			track.Album = album.Name
+			track.ReleaseDate = album.ReleaseDate
+			if len(album.Images) > 0 {
+				track.ImageURL = album.Images[0].URL
+			}
+			album.Tracks = append(album.Tracks, track)
+		}
+		if page.Next == "" || len(album.Tracks) >= maxAlbumTracks {
+			break
+		}
+		next := page.Next
+		page = spotifyAlbumTracks{}
+		if err := s.getJSON(next, &page); err != nil {
+			return nil, fmt.Errorf("failed to get album tracks: %w", err)
+		}
+	}
+	if len(album.Tracks) > maxAlbumTracks {
+		album.Tracks = album.Tracks[:maxAlbumTracks]
+	}
+
+	return album, nil
+}
+
+// spotifyAlbumTracks is a page of an album's tracks
+type spotifyAlbumTracks struct {
+	Items []spotifyTrackObject `json:"items"`
+	Next  string               `json:"next"` // URL of the next page; empty on the last
+}
+
+// AddToQueue adds a track to the user's playback queue. The token needs the
+// user-modify-playback-state scope, and the user needs an active device.
+func (s *service) AddToQueue(userToken, trackID string) error {
+	query := url.Values{}
+	query.Set("uri", "spotify:track:"+trackID)
+	return s.userRequest("POST", "https://api.spotify.com/v1/me/player/queue?"+query.Encode(), userToken, nil, nil)
+}
+
+// CreatePlaylist creates a private playlist on the user's account holding
+// trackIDs, at most 100 of them. The token needs the playlist-modify-private scope.
+func (s *service) CreatePlaylist(userToken, name, description string, trackIDs []string) (*models.SpotifyPlaylist, error) {
+	var user struct {
+		ID string `json:"id"`
+	}
+	if err := s.userRequest("GET", "https://api.spotify.com/v1/me", userToken, nil, &user); err != nil {
+		return nil, err
+	}
+
+	var created struct {
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		ExternalURLs struct {
+			Spotify string `json:"spotify"`
+		} `json:"external_urls"`
+	}
+	urlStr := fmt.Sprintf("https://api.spotify.com/v1/users/%s/playlists", url.PathEscape(user.ID))
+	body := map[string]interface{}{"name": name, "description": description, "public": false}
+	if err := s.userRequest("POST", urlStr, userToken, body, &created); err != nil {
+		return nil, err
+	}
+
+	uris := make([]string, len(trackIDs))
+	for i, trackID := range trackIDs {
+		uris[i] = "spotify:track:" + trackID
+	}
+	urlStr = fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks", url.PathEscape(created.ID))
+	if err := s.userRequest("POST", urlStr, userToken, map[string]interface{}{"uris": uris}, nil); err != nil {
+		return nil, fmt.Errorf("playlist %s was created but its tracks could not be added: %w", created.ID, err)
+	}
+
+	return &models.SpotifyPlaylist{
+		ID:         created.ID,
+		Name:       created.Name,
+		URL:        created.ExternalURLs.Spotify,
+		TrackCount: len(trackIDs),
+	}, nil
+}
+
+// userRequest sends a request authorized by a user's access token, encoding
+// body and decoding the response into out when they are set
+func (s *service) userRequest(method, urlStr, userToken string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, urlStr, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", userToken))
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrUserTokenRejected
+	case resp.StatusCode/100 != 2:
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("spotify API failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// spotifyTrackObject is the subset of Spotify's track object we use
+type spotifyTrackObject struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	PreviewURL string `json:"preview_url"`
+	DurationMS int    `json:"duration_ms"`
+	Explicit   bool   `json:"explicit"`
+	Artists    []struct {
+		Name string `json:"name"`
+	} `json:"artists"`
+	Album struct {
+		Name        string `json:"name"`
+		ReleaseDate string `json:"release_date"`
+		Images      []struct {
+			URL string `json:"url"`
+		} `json:"images"`
+	} `json:"album"`
+	ExternalURLs struct {
+		Spotify string `json:"spotify"`
+	} `json:"external_urls"`
+}
+
+// toUnifiedTrack converts a Spotify track object to a UnifiedTrack
+func (t spotifyTrackObject) toUnifiedTrack() models.UnifiedTrack {
+	track := models.UnifiedTrack{
+		ID:          t.ID,
+		Name:        t.Name,
+		Album:       t.Album.Name,
+		ReleaseDate: t.Album.ReleaseDate,
+		Source:      "spotify",
+		PreviewURL:  t.PreviewURL,
+		ExternalURL: t.ExternalURLs.Spotify,
+		Duration:    t.DurationMS / 1000,
+		Explicit:    t.Explicit,
+	}
+	if len(t.Artists) > 0 {
+		track.Artist = t.Artists[0].Name
+	}
+	if len(t.Album.Images) > 0 {
+		track.ImageURL = t.Album.Images[0].URL
+	}
+	return track
+}
+
+// getJSON sends an authenticated GET request and decodes the JSON response
+func (s *service) getJSON(urlStr string, out interface{}) error {
+	token, err := s.GetAccessToken()
+	if err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &statusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// statusError is returned by getJSON when Spotify answers with an error status
+type statusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("spotify API failed with status %d: %s", e.StatusCode, e.Body)
+}
+
 // getString safely extracts a string from a map
 func (s *service) getString(m map[string]interface{}, key string) string {
 	if val, ok := m[key].(string); ok {
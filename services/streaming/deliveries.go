@@ -0,0 +1,211 @@
+package streaming
+
+import (
+	"backend/server/models"
+	"errors"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrDeliveryNotFound is returned when redelivering a delivery that isn't
+// remembered, e.g. because it has been evicted
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+// payloadPreviewBytes is how much of each payload is shown in listings
+const payloadPreviewBytes = 512
+
+// DeliveryLogConfig holds retry and history settings for a DeliveryLog
+type DeliveryLogConfig struct {
+	Backend     string        // Shown on each delivery
+	Size        int           // Number of recent deliveries remembered
+	MaxAttempts int           // Attempts per publish, including the first
+	RetryDelay  time.Duration // Delay before the first retry, doubling after each
+}
+
+// DefaultDeliveryLogConfig returns a default configuration for a delivery log
+func DefaultDeliveryLogConfig() DeliveryLogConfig {
+	return DeliveryLogConfig{
+		Size:        200,
+		MaxAttempts: 3,
+		RetryDelay:  500 * time.Millisecond,
+	}
+}
+
+// deliveryEntry is a remembered delivery and its full payload for redelivery
+type deliveryEntry struct {
+	delivery models.EventDelivery
+	payload  []byte
+}
+
+// deliveryLog implements DeliveryLog around another Publisher
+type deliveryLog struct {
+	publisher Publisher
+	config    DeliveryLogConfig
+	entries   []*deliveryEntry // Oldest first
+	nextID    int64
+	mutex     sync.RWMutex
+}
+
+// NewDeliveryLog wraps publisher so every publish is retried and recorded
+func NewDeliveryLog(publisher Publisher, config DeliveryLogConfig) DeliveryLog {
+	if config.Size < 1 {
+		config.Size = 1
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	return &deliveryLog{
+		publisher: publisher,
+		config:    config,
+	}
+}
+
+// Publish sends data to topic, retrying failures with exponential backoff,
+// and records the outcome
+func (l *deliveryLog) Publish(topic string, data []byte) error {
+	entry := l.record(topic, data)
+
+	delay := l.config.RetryDelay
+	var err error
+	for attempt := 1; attempt <= l.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		if err = l.attempt(entry); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// Close closes the underlying publisher
+func (l *deliveryLog) Close() error {
+	return l.publisher.Close()
+}
+
+// Deliveries returns up to limit recent deliveries, newest first
+func (l *deliveryLog) Deliveries(status string, limit int) []models.EventDelivery {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	deliveries := []models.EventDelivery{}
+	for i := len(l.entries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if status == "" || l.entries[i].delivery.Status == status {
+			deliveries = append(deliveries, l.entries[i].delivery)
+		}
+	}
+	return deliveries
+}
+
+// Failed returns the number of remembered deliveries that failed
+func (l *deliveryLog) Failed() int {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	failed := 0
+	for _, entry := range l.entries {
+		if entry.delivery.Status == models.DeliveryFailed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Delivery returns a remembered delivery by ID
+func (l *deliveryLog) Delivery(id int64) (models.EventDelivery, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	if entry := l.find(id); entry != nil {
+		return entry.delivery, true
+	}
+	return models.EventDelivery{}, false
+}
+
+// Redeliver publishes a remembered delivery's payload once more. A failed
+// attempt is recorded on the delivery and also returned as an error.
+func (l *deliveryLog) Redeliver(id int64) (models.EventDelivery, error) {
+	l.mutex.RLock()
+	entry := l.find(id)
+	l.mutex.RUnlock()
+	if entry == nil {
+		return models.EventDelivery{}, ErrDeliveryNotFound
+	}
+
+	err := l.attempt(entry)
+
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return entry.delivery, err
+}
+
+// record remembers a new delivery, evicting the oldest when full
+func (l *deliveryLog) record(topic string, data []byte) *deliveryEntry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.nextID++
+	entry := &deliveryEntry{
+		delivery: models.EventDelivery{
+			ID:             l.nextID,
+			Backend:        l.config.Backend,
+			Topic:          topic,
+			Status:         models.DeliveryFailed,
+			PayloadBytes:   len(data),
+			PayloadPreview: preview(data),
+			CreatedAt:      time.Now(),
+		},
+		payload: data,
+	}
+	if len(l.entries) >= l.config.Size {
+		l.entries = append(l.entries[:0], l.entries[len(l.entries)-l.config.Size+1:]...)
+	}
+	l.entries = append(l.entries, entry)
+	return entry
+}
+
+// attempt publishes an entry's payload once and records the outcome
+func (l *deliveryLog) attempt(entry *deliveryEntry) error {
+	start := time.Now()
+	err := l.publisher.Publish(entry.delivery.Topic, entry.payload)
+	latency := time.Since(start)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entry.delivery.Attempts++
+	entry.delivery.LatencyMs = latency.Milliseconds()
+	entry.delivery.LastAttemptAt = start
+	if err != nil {
+		entry.delivery.Status = models.DeliveryFailed
+		entry.delivery.Error = err.Error()
+	} else {
+		entry.delivery.Status = models.DeliveryDelivered
+		entry.delivery.Error = ""
+	}
+	return err
+}
+
+// find returns the entry with id; callers must hold the mutex
+func (l *deliveryLog) find(id int64) *deliveryEntry {
+	for _, entry := range l.entries {
+		if entry.delivery.ID == id {
+			return entry
+		}
+	}
+	return nil
+}
+
+// preview returns the start of a payload, cut on a UTF-8 boundary
+func preview(data []byte) string {
+	if len(data) <= payloadPreviewBytes {
+		return string(data)
+	}
+	cut := payloadPreviewBytes
+	for cut > 0 && !utf8.RuneStart(data[cut]) {
+		cut--
+	}
+	return string(data[:cut]) + "…"
+}
@@ -0,0 +1,37 @@
+package streaming
+
+import "backend/server/models"
+
+// Backends supported by New
+const (
+	BackendNATS  = "nats"
+	BackendKafka = "kafka"
+)
+
+// Publisher sends messages to an external event-streaming system
+type Publisher interface {
+	// Publish sends data to a subject (NATS) or topic (Kafka)
+	Publish(topic string, data []byte) error
+
+	// Close releases the connection to the streaming system
+	Close() error
+}
+
+// DeliveryLog is a Publisher that retries failed publishes and remembers
+// recent deliveries so they can be inspected and redelivered
+type DeliveryLog interface {
+	Publisher
+
+	// Deliveries returns up to limit recent deliveries, newest first,
+	// optionally only those with the given status
+	Deliveries(status string, limit int) []models.EventDelivery
+
+	// Failed returns the number of remembered deliveries that failed
+	Failed() int
+
+	// Delivery returns a remembered delivery by ID
+	Delivery(id int64) (models.EventDelivery, bool)
+
+	// Redeliver publishes a remembered delivery's payload again
+	Redeliver(id int64) (models.EventDelivery, error)
+}
@@ -0,0 +1,61 @@
+package streaming
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// kafkaPublisher publishes to Kafka through a Confluent-compatible REST Proxy
+type kafkaPublisher struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// newKafkaPublisher creates a publisher for the REST Proxy at baseURL
+func newKafkaPublisher(baseURL string) *kafkaPublisher {
+	return &kafkaPublisher{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Publish produces a single JSON record to topic
+func (p *kafkaPublisher) Publish(topic string, data []byte) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"records": []map[string]json.RawMessage{{"value": data}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode Kafka record: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", p.baseURL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish to Kafka: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Kafka REST Proxy returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// Close is a no-op; the REST Proxy is stateless
+func (p *kafkaPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,112 @@
+package streaming
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsPublisher publishes using the NATS text protocol over TCP
+type natsPublisher struct {
+	address string
+	conn    net.Conn
+	mutex   sync.Mutex // Serializes writes and reconnects
+}
+
+// newNATSPublisher connects to the NATS server at rawURL (nats://host:port)
+func newNATSPublisher(rawURL string) (*natsPublisher, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q", rawURL)
+	}
+
+	address := parsed.Host
+	if parsed.Port() == "" {
+		address = net.JoinHostPort(parsed.Hostname(), "4222")
+	}
+
+	p := &natsPublisher{address: address}
+	if err := p.connect(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// connect opens a connection and sends the CONNECT handshake; callers must
+// hold the mutex or own p exclusively
+func (p *natsPublisher) connect() error {
+	conn, err := net.DialTimeout("tcp", p.address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	if _, err := fmt.Fprint(conn, "CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"linkinsync\"}\r\n"); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to send NATS handshake: %w", err)
+	}
+
+	p.conn = conn
+	go p.readLoop(conn)
+	return nil
+}
+
+// readLoop answers server PINGs so the connection stays open, and discards
+// everything else (INFO, +OK, -ERR)
+func (p *natsPublisher) readLoop(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if strings.HasPrefix(line, "PING") {
+			p.mutex.Lock()
+			if p.conn == conn {
+				fmt.Fprint(conn, "PONG\r\n")
+			}
+			p.mutex.Unlock()
+		}
+	}
+}
+
+// Publish sends data to a subject, reconnecting once if the connection dropped
+func (p *natsPublisher) Publish(topic string, data []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	frame := append([]byte(fmt.Sprintf("PUB %s %d\r\n", topic, len(data))), data...)
+	frame = append(frame, '\r', '\n')
+
+	if p.conn != nil {
+		if _, err := p.conn.Write(frame); err == nil {
+			return nil
+		}
+		p.conn.Close()
+		p.conn = nil
+	}
+
+	if err := p.connect(); err != nil {
+		return err
+	}
+	if _, err := p.conn.Write(frame); err != nil {
+		return fmt.Errorf("failed to publish to NATS: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection
+func (p *natsPublisher) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
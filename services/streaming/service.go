@@ -0,0 +1,56 @@
+package streaming
+
+import (
+	"backend/services/events"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Config holds event-streaming configuration
+type Config struct {
+	Backend string // BackendNATS or BackendKafka
+	URL     string // nats://host:4222, or the Kafka REST Proxy URL, e.g. http://host:8082
+}
+
+// message is the JSON envelope published for each bus event
+type message struct {
+	Type    string      `json:"type"`
+	Time    time.Time   `json:"time"`
+	Payload interface{} `json:"payload"`
+}
+
+// mirroredEvents are the bus events forwarded to the streaming system
+var mirroredEvents = []string{
+	events.TrackChanged,
+	events.MoodDetected,
+	events.MessagePosted,
+	events.RecommendationServed,
+}
+
+// New creates a publisher for the configured backend
+func New(config Config) (Publisher, error) {
+	switch config.Backend {
+	case BackendNATS:
+		return newNATSPublisher(config.URL)
+	case BackendKafka:
+		return newKafkaPublisher(config.URL), nil
+	default:
+		return nil, fmt.Errorf("unknown event stream backend %q (expected nats or kafka)", config.Backend)
+	}
+}
+
+// Mirror subscribes to the bus and publishes every event to the streaming
+// system as JSON on topic prefix+event type, e.g. "linkinsync.track_changed". Publish failures are logged by
+// the bus and never affect other subscribers.
+func Mirror(bus events.Bus, publisher Publisher, topicPrefix string) {
+	for _, eventType := range mirroredEvents {
+		bus.Subscribe(eventType, "event-stream", func(event events.Event) error {
+			data, err := json.Marshal(message{Type: event.Type, Time: event.Time, Payload: event.Payload})
+			if err != nil {
+				return fmt.Errorf("failed to encode event: %w", err)
+			}
+			return publisher.Publish(topicPrefix+event.Type, data)
+		})
+	}
+}
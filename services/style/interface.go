@@ -0,0 +1,33 @@
+package style
+
+import (
+	"backend/server/models"
+	"errors"
+)
+
+// ErrNoLyrics is returned when none of an artist's top songs have lyrics
+var ErrNoLyrics = errors.New("no lyrics found for any of the artist's top songs")
+
+// Spotify finds artists and their top songs
+type Spotify interface {
+	SearchArtist(name string) (*models.SpotifyArtist, error)
+	GetArtistTopTracks(artistID string) ([]models.UnifiedTrack, error)
+}
+
+// Lyrics fetches a song's lyrics
+type Lyrics interface {
+	GetLyrics(trackName, artistName string) (string, error)
+}
+
+// AIService writes the style summary
+type AIService interface {
+	GenerateJSON(prompt string) (string, error)
+}
+
+// Service analyzes artists' lyrical style
+type Service interface {
+	// Get returns the style of an artist found by name, cached per artist.
+	// Errors finding the artist are returned as they are, e.g. wrapping
+	// spotify.ErrArtistNotFound.
+	Get(artistName string) (*models.ArtistStyle, error)
+}
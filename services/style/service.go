@@ -0,0 +1,241 @@
+package style
+
+import (
+	"backend/server/models"
+	"backend/services/prompts"
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSongLyrics is how much of each song's lyrics goes in the prompt, in runes
+const maxSongLyrics = 800
+
+// Config holds artist style configuration
+type Config struct {
+	Songs      int           // Top songs whose lyrics are sampled
+	CacheTTL   time.Duration // How long an artist's style is served before being analyzed again
+	MaxEntries int           // Artists with a cached style; least recently used ones are evicted beyond this, 0 disables the cache
+}
+
+// DefaultConfig returns a default configuration for artist styles
+func DefaultConfig() Config {
+	return Config{
+		Songs:      8,
+		CacheTTL:   7 * 24 * time.Hour,
+		MaxEntries: 200,
+	}
+}
+
+// entry is an artist's cached style
+type entry struct {
+	key       string
+	style     models.ArtistStyle
+	expiresAt time.Time
+}
+
+// sample is a top song with its lyrics
+type sample struct {
+	track  models.UnifiedTrack
+	lyrics string
+}
+
+// service implements the style Service interface
+type service struct {
+	spotify   Spotify
+	lyrics    Lyrics
+	ai        AIService
+	templates prompts.Service
+	config    Config
+	entries   map[string]*list.Element
+	order     *list.List // Front is most recently used
+	mutex     sync.Mutex
+}
+
+// New creates a new artist style service. A nil templates uses the built-in prompts.
+func New(spotify Spotify, lyrics Lyrics, ai AIService, templates prompts.Service, config Config) Service {
+	if templates == nil {
+		templates = prompts.Default()
+	}
+	if config.Songs < 1 {
+		config.Songs = 1
+	}
+	return &service{
+		spotify:   spotify,
+		lyrics:    lyrics,
+		ai:        ai,
+		templates: templates,
+		config:    config,
+		entries:   make(map[string]*list.Element),
+		order:     list.New(),
+	}
+}
+
+// Get returns the style of an artist
+func (s *service) Get(artistName string) (*models.ArtistStyle, error) {
+	key := strings.ToLower(strings.TrimSpace(artistName))
+	if style, ok := s.cached(key); ok {
+		return &style, nil
+	}
+
+	artist, err := s.spotify.SearchArtist(artistName)
+	if err != nil {
+		return nil, err
+	}
+	topTracks, err := s.spotify.GetArtistTopTracks(artist.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top tracks: %w", err)
+	}
+
+	samples := s.sampleLyrics(artist.Name, topTracks)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoLyrics, artist.Name)
+	}
+	// Oldest first, so the AI can follow the artist's evolution
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].track.ReleaseDate < samples[j].track.ReleaseDate
+	})
+
+	data := prompts.ArtistStyleData{Artist: artist.Name}
+	songs := make([]models.StyleSong, len(samples))
+	for i, sample := range samples {
+		data.Songs = append(data.Songs, prompts.StyleSong{
+			Name:        sample.track.Name,
+			Album:       sample.track.Album,
+			ReleaseDate: sample.track.ReleaseDate,
+			Lyrics:      sample.lyrics,
+		})
+		songs[i] = models.StyleSong{
+			ID:          sample.track.ID,
+			Name:        sample.track.Name,
+			Album:       sample.track.Album,
+			ReleaseDate: sample.track.ReleaseDate,
+		}
+	}
+	prompt, err := s.templates.Render(prompts.ArtistStyle, data)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.ai.GenerateJSON(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze style: %w", err)
+	}
+	var generated struct {
+		Themes           []string `json:"themes"`
+		Evolution        string   `json:"evolution"`
+		SignaturePhrases []string `json:"signature_phrases"`
+		Summary          string   `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(response), &generated); err != nil {
+		return nil, fmt.Errorf("failed to parse style: %w", err)
+	}
+
+	style := models.ArtistStyle{
+		Artist:           artist.Name,
+		SpotifyID:        artist.ID,
+		Themes:           nonEmpty(generated.Themes),
+		Evolution:        strings.TrimSpace(generated.Evolution),
+		SignaturePhrases: nonEmpty(generated.SignaturePhrases),
+		Summary:          strings.TrimSpace(generated.Summary),
+		Songs:            songs,
+		AnalyzedAt:       time.Now(),
+	}
+	s.put(key, style)
+	return &style, nil
+}
+
+// sampleLyrics fetches the lyrics of up to Config.Songs of the top tracks at
+// the same time, keeping the tracks that have lyrics in their original order
+func (s *service) sampleLyrics(artistName string, topTracks []models.UnifiedTrack) []sample {
+	if len(topTracks) > s.config.Songs {
+		topTracks = topTracks[:s.config.Songs]
+	}
+
+	lyrics := make([]string, len(topTracks))
+	var wg sync.WaitGroup
+	for i, track := range topTracks {
+		wg.Add(1)
+		go func(i int, track models.UnifiedTrack) {
+			defer wg.Done()
+			text, err := s.lyrics.GetLyrics(track.Name, artistName)
+			if err != nil {
+				log.Printf("Style of %s: no lyrics for %s: %v", artistName, track.Name, err)
+				return
+			}
+			lyrics[i] = excerpt(text)
+		}(i, track)
+	}
+	wg.Wait()
+
+	var samples []sample
+	for i, track := range topTracks {
+		if lyrics[i] != "" {
+			samples = append(samples, sample{track: track, lyrics: lyrics[i]})
+		}
+	}
+	return samples
+}
+
+// excerpt shortens lyrics for the prompt
+func excerpt(lyrics string) string {
+	lyrics = strings.TrimSpace(lyrics)
+	if runes := []rune(lyrics); len(runes) > maxSongLyrics {
+		return string(runes[:maxSongLyrics]) + "..."
+	}
+	return lyrics
+}
+
+// nonEmpty trims the items, dropping empty ones, and never returns nil so
+// the lists are encoded as arrays
+func nonEmpty(items []string) []string {
+	kept := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// cached returns an artist's non-expired style and marks it recently used
+func (s *service) cached(key string) (models.ArtistStyle, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return models.ArtistStyle{}, false
+	}
+	cached := element.Value.(*entry)
+	if time.Now().After(cached.expiresAt) {
+		s.order.Remove(element)
+		delete(s.entries, key)
+		return models.ArtistStyle{}, false
+	}
+	s.order.MoveToFront(element)
+	return cached.style, true
+}
+
+// put caches an artist's style, evicting the least recently used artists when full
+func (s *service) put(key string, style models.ArtistStyle) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.config.MaxEntries <= 0 {
+		return
+	}
+	if element, ok := s.entries[key]; ok {
+		s.order.Remove(element)
+	}
+	s.entries[key] = s.order.PushFront(&entry{key: key, style: style, expiresAt: time.Now().Add(s.config.CacheTTL)})
+	for s.order.Len() > s.config.MaxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*entry).key)
+	}
+}
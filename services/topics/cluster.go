@@ -0,0 +1,97 @@
+package topics
+
+import (
+	"math"
+	"sort"
+)
+
+// cluster groups vectors whose cosine similarity to their cluster's center is
+// at least threshold and returns the members of each cluster. Vectors are
+// normalized in place. A greedy pass creates the clusters; a second pass
+// moves every vector to its closest center, dropping those close to none.
+func cluster(vectors [][]float64, threshold float64) [][]int {
+	for _, vector := range vectors {
+		normalize(vector)
+	}
+
+	var centers [][]float64
+	for _, vector := range vectors {
+		best, similarity := closest(centers, vector)
+		if best >= 0 && similarity >= threshold {
+			add(centers[best], vector)
+			continue
+		}
+		center := make([]float64, len(vector))
+		add(center, vector)
+		centers = append(centers, center)
+	}
+	for _, center := range centers {
+		normalize(center)
+	}
+
+	members := make([][]int, len(centers))
+	for i, vector := range vectors {
+		if best, similarity := closest(centers, vector); best >= 0 && similarity >= threshold {
+			members[best] = append(members[best], i)
+		}
+	}
+
+	var clusters [][]int
+	for _, indexes := range members {
+		if len(indexes) > 0 {
+			clusters = append(clusters, indexes)
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i]) > len(clusters[j])
+	})
+	return clusters
+}
+
+// center returns the normalized mean of the given vectors
+func center(vectors [][]float64, indexes []int) []float64 {
+	result := make([]float64, len(vectors[indexes[0]]))
+	for _, index := range indexes {
+		add(result, vectors[index])
+	}
+	normalize(result)
+	return result
+}
+
+// closest returns the index of the center most similar to vector and the
+// similarity, or -1 if there are no centers. Centers need not be normalized.
+func closest(centers [][]float64, vector []float64) (int, float64) {
+	best, bestSimilarity := -1, 0.0
+	for i, center := range centers {
+		if similarity := cosine(center, vector); best < 0 || similarity > bestSimilarity {
+			best, bestSimilarity = i, similarity
+		}
+	}
+	return best, bestSimilarity
+}
+
+// cosine returns the cosine similarity of a and b
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// add adds vector to sum in place
+func add(sum, vector []float64) {
+	for i := range sum {
+		if i < len(vector) {
+			sum[i] += vector[i]
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package topics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// embedTimeout bounds each embeddings request
+const embedTimeout = 30 * time.Second
+
+// openAIEmbedder calls an OpenAI-compatible /embeddings endpoint
+type openAIEmbedder struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAIEmbedder creates an embedder using the OpenAI embeddings API at
+// baseURL (e.g. https://api.openai.com/v1)
+func NewOpenAIEmbedder(baseURL, apiKey, model string) Embedder {
+	return &openAIEmbedder{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: embedTimeout},
+	}
+}
+
+// Name identifies the embedder
+func (e *openAIEmbedder) Name() string {
+	return "openai/" + e.model
+}
+
+// Embed returns one vector per text
+func (e *openAIEmbedder) Embed(texts []string) ([][]float64, error) {
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	headers := map[string]string{"Authorization": "Bearer " + e.apiKey}
+	body := map[string]interface{}{"model": e.model, "input": texts}
+	if err := postJSON(e.httpClient, e.baseURL+"/embeddings", headers, body, &response); err != nil {
+		return nil, fmt.Errorf("openai embeddings: %w", err)
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, item := range response.Data {
+		if item.Index >= 0 && item.Index < len(vectors) {
+			vectors[item.Index] = item.Embedding
+		}
+	}
+	for i, vector := range vectors {
+		if vector == nil {
+			return nil, fmt.Errorf("openai embeddings: no embedding for input %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+// ollamaEmbedder calls Ollama's /api/embed endpoint
+type ollamaEmbedder struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOllamaEmbedder creates an embedder using an Ollama embedding model
+// (e.g. nomic-embed-text) at baseURL
+func NewOllamaEmbedder(baseURL, model string) Embedder {
+	return &ollamaEmbedder{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      model,
+		httpClient: &http.Client{Timeout: embedTimeout},
+	}
+}
+
+// Name identifies the embedder
+func (e *ollamaEmbedder) Name() string {
+	return "ollama/" + e.model
+}
+
+// Embed returns one vector per text
+func (e *ollamaEmbedder) Embed(texts []string) ([][]float64, error) {
+	var response struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	body := map[string]interface{}{"model": e.model, "input": texts}
+	if err := postJSON(e.httpClient, e.baseURL+"/api/embed", nil, body, &response); err != nil {
+		return nil, fmt.Errorf("ollama embeddings: %w", err)
+	}
+	if len(response.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama embeddings: got %d embeddings for %d inputs", len(response.Embeddings), len(texts))
+	}
+	return response.Embeddings, nil
+}
+
+// hashingEmbedder embeds texts locally as hashed bags of words. It only
+// captures shared vocabulary, but needs no model.
+type hashingEmbedder struct {
+	dimensions int
+}
+
+// NewHashingEmbedder creates a local embedder with the given number of dimensions
+func NewHashingEmbedder(dimensions int) Embedder {
+	return &hashingEmbedder{dimensions: dimensions}
+}
+
+// Name identifies the embedder
+func (e *hashingEmbedder) Name() string {
+	return "local"
+}
+
+// Embed returns one vector per text
+func (e *hashingEmbedder) Embed(texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vector := make([]float64, e.dimensions)
+		for _, word := range words(text) {
+			hash := fnv.New32a()
+			hash.Write([]byte(word))
+			vector[hash.Sum32()%uint32(e.dimensions)]++
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// stopWords are left out of keywords and local embeddings
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true, "you": true,
+	"all": true, "any": true, "can": true, "had": true, "her": true, "was": true, "one": true,
+	"our": true, "out": true, "has": true, "have": true, "his": true, "how": true, "its": true,
+	"just": true, "like": true, "this": true, "that": true, "with": true, "what": true, "when": true,
+	"from": true, "they": true, "them": true, "then": true, "than": true, "there": true, "their": true,
+	"been": true, "were": true, "will": true, "would": true, "could": true, "should": true, "about": true,
+	"really": true, "into": true, "your": true, "yours": true, "some": true, "does": true, "did": true,
+	"dont": true, "i'm": true, "im": true, "it's": true, "lol": true, "yeah": true,
+	"anyone": true, "who": true, "why": true, "get": true, "got": true, "too": true, "also": true,
+	"very": true, "much": true, "more": true, "here": true, "where": true, "which": true, "still": true,
+}
+
+// words returns the lower-cased words of text worth comparing
+func words(text string) []string {
+	var result []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	}) {
+		word = strings.Trim(word, "'")
+		if len(word) >= 3 && !stopWords[word] {
+			result = append(result, word)
+		}
+	}
+	return result
+}
+
+// normalize scales vector to unit length in place
+func normalize(vector []float64) {
+	var sum float64
+	for _, value := range vector {
+		sum += value * value
+	}
+	if sum == 0 {
+		return
+	}
+	length := math.Sqrt(sum)
+	for i := range vector {
+		vector[i] /= length
+	}
+}
+
+// postJSON sends body as JSON and decodes a 2xx response into out
+func postJSON(client *http.Client, url string, headers map[string]string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,48 @@
+package topics
+
+import (
+	"backend/server/models"
+	"time"
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// related the texts are
+type Embedder interface {
+	// Name identifies the embedder in reports, e.g. "openai/text-embedding-3-small"
+	Name() string
+
+	// Embed returns one vector per text, in order
+	Embed(texts []string) ([][]float64, error)
+}
+
+// MessageSource provides recent global chat messages
+type MessageSource interface {
+	RecentMessages(since time.Time, limit int) ([]models.Message, error)
+}
+
+// Labeler writes a topic label from sample messages. The AI services satisfy it.
+type Labeler interface {
+	GenerateResponse(prompt string) (string, error)
+}
+
+// Poster posts a digest message to global chat
+type Poster interface {
+	PostDigest(text string) error
+}
+
+// PosterFunc adapts a function to Poster
+type PosterFunc func(text string) error
+
+// PostDigest calls f(text)
+func (f PosterFunc) PostDigest(text string) error {
+	return f(text)
+}
+
+// Service defines the interface for community topic detection
+type Service interface {
+	// Run clusters recent messages once and returns the report
+	Run() models.CommunityTopicsReport
+
+	// LastReport returns the most recent report, or false if no pass has completed
+	LastReport() (models.CommunityTopicsReport, bool)
+}
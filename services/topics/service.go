@@ -0,0 +1,260 @@
+package topics
+
+import (
+	"backend/server/models"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config holds topic detection settings
+type Config struct {
+	Window         time.Duration // How far back messages are clustered
+	MaxMessages    int           // Newest messages clustered per pass
+	Similarity     float64       // Minimum cosine similarity of a message to its topic's center
+	MinClusterSize int           // Smaller clusters aren't reported as topics
+	MaxTopics      int
+	SampleSize     int    // Sample messages kept per topic
+	DigestUsername string // Author of digest messages, whose messages are not clustered
+}
+
+// DefaultConfig returns a default configuration for topic detection
+func DefaultConfig() Config {
+	return Config{
+		Window:         24 * time.Hour,
+		MaxMessages:    500,
+		Similarity:     0.75,
+		MinClusterSize: 3,
+		MaxTopics:      5,
+		SampleSize:     3,
+		DigestUsername: "LinkinSync",
+	}
+}
+
+// LocalSimilarity is a suitable Config.Similarity for the hashing embedder,
+// whose vectors only capture shared words
+const LocalSimilarity = 0.3
+
+// keywordCount is the number of keywords kept per topic
+const keywordCount = 3
+
+// service implements the topics Service interface
+type service struct {
+	source      MessageSource
+	embedder    Embedder
+	labeler     Labeler // May be nil, in which case topics are labeled with keywords
+	poster      Poster  // May be nil, in which case no digest is posted
+	config      Config
+	lastReport  *models.CommunityTopicsReport
+	lastDigest  string // Keywords of the last digest, so unchanged topics aren't posted twice
+	reportMutex sync.RWMutex
+	runMutex    sync.Mutex // Prevents overlapping passes
+}
+
+// New creates a new topic detection service
+func New(source MessageSource, embedder Embedder, labeler Labeler, poster Poster, config Config) Service {
+	return &service{
+		source:   source,
+		embedder: embedder,
+		labeler:  labeler,
+		poster:   poster,
+		config:   config,
+	}
+}
+
+// Run clusters recent messages once and returns the report
+func (s *service) Run() models.CommunityTopicsReport {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	report := models.CommunityTopicsReport{
+		StartedAt: time.Now(),
+		Embedder:  s.embedder.Name(),
+		Topics:    []models.CommunityTopic{},
+	}
+	report.Since = report.StartedAt.Add(-s.config.Window)
+
+	if err := s.detect(&report); err != nil {
+		report.Error = err.Error()
+		log.Printf("Community topics: %v", err)
+	} else {
+		s.postDigest(&report)
+	}
+	report.FinishedAt = time.Now()
+
+	s.reportMutex.Lock()
+	s.lastReport = &report
+	s.reportMutex.Unlock()
+
+	return report
+}
+
+// LastReport returns the most recent report, or false if no pass has completed
+func (s *service) LastReport() (models.CommunityTopicsReport, bool) {
+	s.reportMutex.RLock()
+	defer s.reportMutex.RUnlock()
+
+	if s.lastReport == nil {
+		return models.CommunityTopicsReport{}, false
+	}
+	return *s.lastReport, true
+}
+
+// detect clusters the messages in the report's window into its topics
+func (s *service) detect(report *models.CommunityTopicsReport) error {
+	recent, err := s.source.RecentMessages(report.Since, s.config.MaxMessages)
+	if err != nil {
+		return fmt.Errorf("failed to load messages: %w", err)
+	}
+
+	var messages []models.Message
+	var texts []string
+	for _, msg := range recent {
+		if msg.Username == s.config.DigestUsername || strings.TrimSpace(msg.Text) == "" {
+			continue
+		}
+		messages = append(messages, msg)
+		texts = append(texts, msg.Text)
+	}
+	report.MessagesAnalyzed = len(messages)
+	if len(messages) < s.config.MinClusterSize {
+		return nil
+	}
+
+	vectors, err := s.embedder.Embed(texts)
+	if err != nil {
+		return err
+	}
+
+	for _, members := range cluster(vectors, s.config.Similarity) {
+		if len(members) < s.config.MinClusterSize || len(report.Topics) >= s.config.MaxTopics {
+			break
+		}
+		report.Topics = append(report.Topics, s.describe(messages, vectors, members))
+	}
+	return nil
+}
+
+// describe summarizes the messages of one cluster as a topic
+func (s *service) describe(messages []models.Message, vectors [][]float64, members []int) models.CommunityTopic {
+	topic := models.CommunityTopic{
+		MessageCount: len(members),
+		FirstAt:      messages[members[0]].CreatedAt,
+		LastAt:       messages[members[0]].CreatedAt,
+	}
+
+	participants := make(map[string]bool)
+	var texts []string
+	for _, index := range members {
+		msg := messages[index]
+		participants[msg.UserEmail+"|"+msg.Username] = true
+		texts = append(texts, msg.Text)
+		if msg.CreatedAt.Before(topic.FirstAt) {
+			topic.FirstAt = msg.CreatedAt
+		}
+		if msg.CreatedAt.After(topic.LastAt) {
+			topic.LastAt = msg.CreatedAt
+		}
+	}
+	topic.Participants = len(participants)
+	topic.Keywords = keywords(texts, keywordCount)
+
+	// The messages closest to the center represent the topic best
+	topicCenter := center(vectors, members)
+	samples := append([]int(nil), members...)
+	sort.SliceStable(samples, func(i, j int) bool {
+		return cosine(topicCenter, vectors[samples[i]]) > cosine(topicCenter, vectors[samples[j]])
+	})
+	if len(samples) > s.config.SampleSize {
+		samples = samples[:s.config.SampleSize]
+	}
+	for _, index := range samples {
+		topic.SampleMessages = append(topic.SampleMessages, messages[index].Text)
+	}
+
+	topic.Label = s.label(topic)
+	return topic
+}
+
+// label asks the labeler to name a topic, falling back to its keywords
+func (s *service) label(topic models.CommunityTopic) string {
+	fallback := "People are talking about " + strings.Join(topic.Keywords, ", ")
+	if s.labeler == nil {
+		return fallback
+	}
+
+	prompt := "These chat messages from a music listening community share a topic:\n- " +
+		strings.Join(topic.SampleMessages, "\n- ") +
+		"\n\nDescribe the topic in one short sentence starting with \"People are talking about\". Reply with the sentence only."
+	response, err := s.labeler.GenerateResponse(prompt)
+	if err != nil {
+		log.Printf("Community topics: failed to label topic, using keywords: %v", err)
+		return fallback
+	}
+
+	label, _, _ := strings.Cut(strings.TrimSpace(response), "\n")
+	label = strings.Trim(strings.TrimSpace(label), `"`)
+	if label == "" || len(label) > 160 {
+		return fallback
+	}
+	return label
+}
+
+// postDigest posts the report's topics to chat unless they haven't changed
+// since the last digest
+func (s *service) postDigest(report *models.CommunityTopicsReport) {
+	if s.poster == nil || len(report.Topics) == 0 {
+		return
+	}
+
+	var keys, lines []string
+	for _, topic := range report.Topics {
+		keys = append(keys, strings.Join(topic.Keywords, ","))
+		lines = append(lines, "• "+topic.Label)
+	}
+	key := strings.Join(keys, "|")
+	if key == s.lastDigest {
+		return
+	}
+
+	text := "What the community is talking about:\n" + strings.Join(lines, "\n")
+	if err := s.poster.PostDigest(text); err != nil {
+		report.Error = fmt.Sprintf("failed to post digest: %v", err)
+		log.Printf("Community topics: %s", report.Error)
+		return
+	}
+	s.lastDigest = key
+	report.Digest = text
+}
+
+// keywords returns the count words used in the most texts
+func keywords(texts []string, count int) []string {
+	frequency := make(map[string]int)
+	for _, text := range texts {
+		seen := make(map[string]bool)
+		for _, word := range words(text) {
+			if !seen[word] {
+				seen[word] = true
+				frequency[word]++
+			}
+		}
+	}
+
+	ranked := make([]string, 0, len(frequency))
+	for word := range frequency {
+		ranked = append(ranked, word)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if frequency[ranked[i]] != frequency[ranked[j]] {
+			return frequency[ranked[i]] > frequency[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+	if len(ranked) > count {
+		ranked = ranked[:count]
+	}
+	return ranked
+}
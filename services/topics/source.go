@@ -0,0 +1,47 @@
+package topics
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"time"
+)
+
+// postgresSource reads messages from the global_messages table
+type postgresSource struct {
+	db *sql.DB
+}
+
+// NewPostgresSource creates a MessageSource backed by global_messages
+func NewPostgresSource(db *sql.DB) MessageSource {
+	return &postgresSource{db: db}
+}
+
+// RecentMessages returns up to limit of the newest messages posted since then,
+// oldest first, leaving out those moderators hid
+func (s *postgresSource) RecentMessages(since time.Time, limit int) ([]models.Message, error) {
+	rows, err := s.db.Query(`
+        SELECT id, user_email, username, message_text, created_at
+        FROM (
+            SELECT id, user_email, username, message_text, created_at
+            FROM global_messages
+            WHERE created_at >= $1 AND hidden_at IS NULL
+            ORDER BY created_at DESC
+            LIMIT $2
+        ) recent
+        ORDER BY created_at ASC
+    `, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.Message
+	for rows.Next() {
+		var msg models.Message
+		if err := rows.Scan(&msg.ID, &msg.UserEmail, &msg.Username, &msg.Text, &msg.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,18 @@
+package trending
+
+import (
+	"backend/server/models"
+	"time"
+)
+
+// Service defines the interface for trending tracks over a sliding window
+type Service interface {
+	// Record counts a play of track at the given time
+	Record(track models.UnifiedTrack, at time.Time)
+
+	// Top returns up to limit tracks with the most plays in the window, most played first
+	Top(limit int) []models.TrendingTrack
+
+	// Window returns how far back plays are counted
+	Window() time.Duration
+}
@@ -0,0 +1,161 @@
+package trending
+
+import (
+	"backend/server/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Config holds trending configuration
+type Config struct {
+	Window  time.Duration // Plays older than this no longer count
+	Buckets int           // Number of ring buffer slots the window is divided into
+}
+
+// DefaultConfig returns a default configuration for trending
+func DefaultConfig() Config {
+	return Config{
+		Window:  time.Hour,
+		Buckets: 60,
+	}
+}
+
+// bucket counts the plays in one slot of the window
+type bucket struct {
+	counts map[string]int // track key -> plays
+}
+
+// service implements the trending Service interface with a ring buffer of
+// per-bucket counters and running totals, so recording a play and reading
+// the ranking never aggregate over raw history
+type service struct {
+	config  Config
+	width   time.Duration
+	buckets []bucket
+	head    int64                          // Newest bucket index the ring has advanced to
+	totals  map[string]int                 // track key -> plays across the window
+	tracks  map[string]models.UnifiedTrack // track key -> latest track metadata
+	ranking []models.TrendingTrack         // Cached ranking, nil when stale
+	mutex   sync.Mutex
+}
+
+// New creates a new trending service
+func New(config Config) Service {
+	if config.Buckets < 1 {
+		config.Buckets = 1
+	}
+	width := config.Window / time.Duration(config.Buckets)
+	if width <= 0 {
+		width = time.Nanosecond
+	}
+
+	return &service{
+		config:  config,
+		width:   width,
+		buckets: make([]bucket, config.Buckets),
+		totals:  make(map[string]int),
+		tracks:  make(map[string]models.UnifiedTrack),
+	}
+}
+
+// Record counts a play of track at the given time. Plays that already fell
+// out of the window are ignored.
+func (s *service) Record(track models.UnifiedTrack, at time.Time) {
+	if track.ID == "" {
+		return
+	}
+	key := track.Source + ":" + track.ID
+	index := at.UnixNano() / int64(s.width)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.advance(time.Now())
+	if index > s.head {
+		s.advance(at)
+	}
+	if index <= s.head-int64(len(s.buckets)) {
+		return
+	}
+
+	slot := &s.buckets[s.slot(index)]
+	if slot.counts == nil {
+		slot.counts = make(map[string]int)
+	}
+	slot.counts[key]++
+	s.totals[key]++
+	s.tracks[key] = track
+	s.ranking = nil
+}
+
+// Top returns up to limit tracks with the most plays in the window, most played first
+func (s *service) Top(limit int) []models.TrendingTrack {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.advance(time.Now())
+	if s.ranking == nil {
+		s.ranking = make([]models.TrendingTrack, 0, len(s.totals))
+		for key, plays := range s.totals {
+			s.ranking = append(s.ranking, models.TrendingTrack{Track: s.tracks[key], Plays: plays})
+		}
+		sort.Slice(s.ranking, func(i, j int) bool {
+			if s.ranking[i].Plays != s.ranking[j].Plays {
+				return s.ranking[i].Plays > s.ranking[j].Plays
+			}
+			return s.ranking[i].Track.Name < s.ranking[j].Track.Name
+		})
+	}
+
+	if limit > len(s.ranking) {
+		limit = len(s.ranking)
+	}
+	top := make([]models.TrendingTrack, limit)
+	copy(top, s.ranking)
+	return top
+}
+
+// Window returns how far back plays are counted
+func (s *service) Window() time.Duration {
+	return s.config.Window
+}
+
+// advance moves the ring forward to now, expiring buckets that left the window.
+// Callers must hold the mutex.
+func (s *service) advance(now time.Time) {
+	index := now.UnixNano() / int64(s.width)
+	if index <= s.head {
+		return
+	}
+
+	from := s.head + 1
+	if index-from >= int64(len(s.buckets)) {
+		from = index - int64(len(s.buckets)) + 1
+	}
+	for i := from; i <= index; i++ {
+		s.expire(&s.buckets[s.slot(i)])
+	}
+	s.head = index
+}
+
+// expire removes a bucket's plays from the totals
+func (s *service) expire(b *bucket) {
+	if b.counts == nil {
+		return
+	}
+	for key, plays := range b.counts {
+		s.totals[key] -= plays
+		if s.totals[key] <= 0 {
+			delete(s.totals, key)
+			delete(s.tracks, key)
+		}
+	}
+	b.counts = nil
+	s.ranking = nil
+}
+
+// slot maps an absolute bucket index onto the ring
+func (s *service) slot(index int64) int {
+	return int(index % int64(len(s.buckets)))
+}
@@ -0,0 +1,40 @@
+package trivia
+
+import (
+	"backend/server/models"
+	"errors"
+)
+
+var (
+	// ErrNoQuiz is returned when the user has no quiz in progress
+	ErrNoQuiz = errors.New("no quiz in progress")
+	// ErrNotAnAnswer is returned when a message doesn't pick one of the choices
+	ErrNotAnAnswer = errors.New("not an answer to the question")
+	// ErrNoQuestions is returned when the AI wrote no usable questions
+	ErrNoQuestions = errors.New("no usable quiz questions")
+)
+
+// AIService writes the questions
+type AIService interface {
+	GenerateJSON(prompt string) (string, error)
+}
+
+// Service runs multiple-choice quizzes in chat, one per user. The correct
+// answers never leave the server; answers are checked here.
+type Service interface {
+	// Start asks ai for questions about topic and starts a quiz with them,
+	// replacing the user's quiz in progress. instruction, if any, is added to
+	// the prompt, e.g. to pick the language.
+	Start(userID string, ai AIService, topic, instruction string) (models.TriviaQuiz, error)
+
+	// Answer checks a message as the answer to the current question: a
+	// choice's letter (A-D), number (1-4) or text. Messages that are none of
+	// these get ErrNotAnAnswer and leave the quiz as it is.
+	Answer(userID, message string) (models.TriviaQuiz, error)
+
+	// Active reports whether the user has a quiz in progress
+	Active(userID string) bool
+
+	// Stop ends the user's quiz, returning its final state
+	Stop(userID string) (models.TriviaQuiz, error)
+}
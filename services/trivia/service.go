@@ -0,0 +1,268 @@
+package trivia
+
+import (
+	"backend/server/models"
+	"backend/services/prompts"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// choiceCount is the number of choices every question has
+const choiceCount = 4
+
+// Config holds chat quiz configuration
+type Config struct {
+	Questions  int           // Questions per quiz
+	SessionTTL time.Duration // A quiz is dropped after this long without an answer
+}
+
+// DefaultConfig returns a default configuration for chat quizzes
+func DefaultConfig() Config {
+	return Config{
+		Questions:  5,
+		SessionTTL: 30 * time.Minute,
+	}
+}
+
+// question is a question with its answer
+type question struct {
+	Question    string   `json:"question"`
+	Choices     []string `json:"choices"`
+	Answer      int      `json:"answer"`
+	Explanation string   `json:"explanation"`
+}
+
+// session is a user's quiz in progress
+type session struct {
+	topic     string
+	questions []question
+	answered  int
+	score     int
+	expiresAt time.Time
+}
+
+// service implements the trivia Service interface with sessions in memory
+type service struct {
+	templates prompts.Service
+	config    Config
+	sessions  map[string]*session // userID -> quiz in progress
+	mutex     sync.Mutex
+}
+
+// New creates a new chat quiz service. A nil templates uses the built-in prompts.
+func New(templates prompts.Service, config Config) Service {
+	if templates == nil {
+		templates = prompts.Default()
+	}
+	if config.Questions < 1 {
+		config.Questions = 1
+	}
+	return &service{
+		templates: templates,
+		config:    config,
+		sessions:  make(map[string]*session),
+	}
+}
+
+// Start asks ai for questions about topic and starts a quiz with them
+func (s *service) Start(userID string, ai AIService, topic, instruction string) (models.TriviaQuiz, error) {
+	prompt, err := s.templates.Render(prompts.TriviaQuiz, prompts.TriviaQuizData{
+		Topic: topic,
+		Count: s.config.Questions,
+	})
+	if err != nil {
+		return models.TriviaQuiz{}, err
+	}
+	if instruction != "" {
+		prompt += "\n\n" + instruction
+	}
+
+	response, err := ai.GenerateJSON(prompt)
+	if err != nil {
+		return models.TriviaQuiz{}, fmt.Errorf("failed to generate questions: %w", err)
+	}
+	var generated struct {
+		Questions []question `json:"questions"`
+	}
+	if err := json.Unmarshal([]byte(response), &generated); err != nil {
+		return models.TriviaQuiz{}, fmt.Errorf("failed to parse questions: %w", err)
+	}
+
+	questions := usableQuestions(generated.Questions, s.config.Questions)
+	if len(questions) == 0 {
+		return models.TriviaQuiz{}, ErrNoQuestions
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	quiz := &session{
+		topic:     topic,
+		questions: questions,
+		expiresAt: time.Now().Add(s.config.SessionTTL),
+	}
+	s.sessions[userID] = quiz
+	return quiz.state(nil), nil
+}
+
+// Answer checks a message as the answer to the current question
+func (s *service) Answer(userID, message string) (models.TriviaQuiz, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	quiz := s.session(userID)
+	if quiz == nil {
+		return models.TriviaQuiz{}, ErrNoQuiz
+	}
+	current := quiz.questions[quiz.answered]
+	choice, ok := parseChoice(message, current.Choices)
+	if !ok {
+		return models.TriviaQuiz{}, ErrNotAnAnswer
+	}
+
+	result := &models.TriviaResult{
+		Correct:     choice == current.Answer,
+		Answer:      current.Answer,
+		Choice:      current.Choices[current.Answer],
+		Explanation: current.Explanation,
+	}
+	if result.Correct {
+		quiz.score++
+	}
+	quiz.answered++
+	quiz.expiresAt = time.Now().Add(s.config.SessionTTL)
+	if quiz.answered == len(quiz.questions) {
+		delete(s.sessions, userID)
+	}
+	return quiz.state(result), nil
+}
+
+// Active reports whether the user has a quiz in progress
+func (s *service) Active(userID string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.session(userID) != nil
+}
+
+// Stop ends the user's quiz
+func (s *service) Stop(userID string) (models.TriviaQuiz, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	quiz := s.session(userID)
+	if quiz == nil {
+		return models.TriviaQuiz{}, ErrNoQuiz
+	}
+	delete(s.sessions, userID)
+	state := quiz.state(nil)
+	state.Question = nil
+	state.Finished = true
+	return state, nil
+}
+
+// session returns the user's quiz in progress, dropping it if it expired;
+// callers must hold the lock
+func (s *service) session(userID string) *session {
+	quiz, ok := s.sessions[userID]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(quiz.expiresAt) {
+		delete(s.sessions, userID)
+		return nil
+	}
+	return quiz
+}
+
+// state describes the quiz after result, with the next question if any
+func (q *session) state(result *models.TriviaResult) models.TriviaQuiz {
+	state := models.TriviaQuiz{
+		Topic:    q.topic,
+		Score:    q.score,
+		Answered: q.answered,
+		Total:    len(q.questions),
+		Result:   result,
+		Finished: q.answered == len(q.questions),
+	}
+	if !state.Finished {
+		next := q.questions[q.answered]
+		state.Question = &models.TriviaQuestion{
+			Number:   q.answered + 1,
+			Question: next.Question,
+			Choices:  next.Choices,
+		}
+	}
+	return state
+}
+
+// usableQuestions keeps up to limit questions that have a text, four
+// distinct choices and an answer among them
+func usableQuestions(questions []question, limit int) []question {
+	usable := make([]question, 0, limit)
+	for _, q := range questions {
+		if len(usable) == limit {
+			break
+		}
+		q.Question = strings.TrimSpace(q.Question)
+		if q.Question == "" || len(q.Choices) != choiceCount || q.Answer < 0 || q.Answer >= choiceCount {
+			continue
+		}
+		distinct := make(map[string]bool, choiceCount)
+		for i, choice := range q.Choices {
+			q.Choices[i] = strings.TrimSpace(choice)
+			if q.Choices[i] != "" {
+				distinct[strings.ToLower(q.Choices[i])] = true
+			}
+		}
+		if len(distinct) != choiceCount {
+			continue
+		}
+		usable = append(usable, q)
+	}
+	return usable
+}
+
+// answerPrefixes are stripped from messages before they are read as a choice
+var answerPrefixes = []string{"my answer is", "the answer is", "answer", "it's", "it is", "option", "choice"}
+
+// parseChoice reads a message as a choice's letter, number or text
+func parseChoice(message string, choices []string) (int, bool) {
+	if i, ok := matchChoice(message, choices); ok {
+		return i, true
+	}
+
+	text := strings.ToLower(strings.TrimSpace(message))
+	for _, prefix := range answerPrefixes {
+		if rest, ok := strings.CutPrefix(text, prefix); ok {
+			text = strings.TrimSpace(strings.TrimLeft(rest, ":"))
+			break
+		}
+	}
+	text = strings.Trim(text, " .!)(\"'")
+
+	if len(text) == 1 {
+		if text[0] >= 'a' && int(text[0]-'a') < len(choices) {
+			return int(text[0] - 'a'), true
+		}
+		if text[0] >= '1' && int(text[0]-'1') < len(choices) {
+			return int(text[0] - '1'), true
+		}
+	}
+	return matchChoice(text, choices)
+}
+
+// matchChoice finds the choice whose text is the message, ignoring case and
+// surrounding punctuation
+func matchChoice(message string, choices []string) (int, bool) {
+	text := strings.Trim(message, " .!?\"'")
+	for i, choice := range choices {
+		if strings.EqualFold(text, strings.Trim(choice, " .!?\"'")) {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,54 @@
+package users
+
+import (
+	"backend/server/models"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned for users that aren't registered
+	ErrNotFound = errors.New("user not found")
+
+	// ErrUsernameTaken is returned when another user has the username
+	ErrUsernameTaken = errors.New("username already taken")
+)
+
+// Store persists user profiles
+type Store interface {
+	// Register creates a user unless they exist, returning their profile
+	Register(id, email string) (models.User, error)
+
+	// Get returns a user's profile, or ErrNotFound
+	Get(id string) (models.User, error)
+
+	// Update applies the fields set in update to a user's profile, returning
+	// ErrNotFound or ErrUsernameTaken
+	Update(id string, update models.UserUpdate) (models.User, error)
+
+	// SetAvatar sets or, with an empty URL, clears a user's avatar, returning
+	// ErrNotFound
+	SetAvatar(id, avatarURL string) (models.User, error)
+
+	// Delete removes a user's profile, returning it, or ErrNotFound
+	Delete(id string) (models.User, error)
+}
+
+// Service registers users and manages their profiles
+type Service interface {
+	// Register creates the profile of a user on their first request. Users
+	// registered before are remembered, so later calls are cheap.
+	Register(id string) error
+
+	// Get returns a user's profile, registering them if needed
+	Get(id string) (models.User, error)
+
+	// Update changes a user's profile, registering them if needed
+	Update(id string, update models.UserUpdate) (models.User, error)
+
+	// SetAvatar sets or clears a user's avatar, registering them if needed
+	SetAvatar(id, avatarURL string) (models.User, error)
+
+	// Delete removes a user's profile, returning it, or ErrNotFound. A later
+	// request from the user registers a new, empty profile.
+	Delete(id string) (models.User, error)
+}
@@ -0,0 +1,116 @@
+package users
+
+import (
+	"backend/server/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryStore keeps users in memory, for development without a database and tests
+type memoryStore struct {
+	users map[string]models.User
+	mutex sync.RWMutex
+}
+
+// NewMemoryStore creates an in-memory Store
+func NewMemoryStore() Store {
+	return &memoryStore{users: make(map[string]models.User)}
+}
+
+// Register creates a user unless they exist, returning their profile
+func (m *memoryStore) Register(id, email string) (models.User, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		now := time.Now()
+		user = models.User{ID: id, Email: email, FavoriteGenres: []string{}, CreatedAt: now, UpdatedAt: now}
+		m.users[id] = user
+	}
+	return copyUser(user), nil
+}
+
+// Get returns a user's profile, or ErrNotFound
+func (m *memoryStore) Get(id string) (models.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return copyUser(user), nil
+}
+
+// Update applies the fields set in update to a user's profile
+func (m *memoryStore) Update(id string, update models.UserUpdate) (models.User, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	if update.Username != nil && *update.Username != "" {
+		for otherID, other := range m.users {
+			if otherID != id && strings.EqualFold(other.Username, *update.Username) {
+				return models.User{}, ErrUsernameTaken
+			}
+		}
+	}
+
+	if update.Email != nil {
+		user.Email = *update.Email
+	}
+	if update.Username != nil {
+		user.Username = *update.Username
+	}
+	if update.DisplayName != nil {
+		user.DisplayName = *update.DisplayName
+	}
+	if update.Bio != nil {
+		user.Bio = *update.Bio
+	}
+	if update.FavoriteGenres != nil {
+		user.FavoriteGenres = append([]string{}, *update.FavoriteGenres...)
+	}
+	user.UpdatedAt = time.Now()
+	m.users[id] = user
+	return copyUser(user), nil
+}
+
+// SetAvatar sets or clears a user's avatar
+func (m *memoryStore) SetAvatar(id, avatarURL string) (models.User, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	user.AvatarURL = avatarURL
+	user.UpdatedAt = time.Now()
+	m.users[id] = user
+	return copyUser(user), nil
+}
+
+// Delete removes a user's profile, returning it
+func (m *memoryStore) Delete(id string) (models.User, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	delete(m.users, id)
+	return user, nil
+}
+
+// copyUser copies a user so callers can't change the stored genres
+func copyUser(user models.User) models.User {
+	user.FavoriteGenres = append([]string{}, user.FavoriteGenres...)
+	return user
+}
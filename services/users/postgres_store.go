@@ -0,0 +1,136 @@
+package users
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Schema creates the users table. Usernames are unique ignoring case; users
+// without one don't conflict.
+const Schema = `
+        CREATE TABLE IF NOT EXISTS users (
+            id VARCHAR(255) PRIMARY KEY,
+            email VARCHAR(255) NOT NULL DEFAULT '',
+            username VARCHAR(32) NOT NULL DEFAULT '',
+            display_name VARCHAR(64) NOT NULL DEFAULT '',
+            bio TEXT NOT NULL DEFAULT '',
+            favorite_genres TEXT[] NOT NULL DEFAULT '{}',
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+            updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+        );
+
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users(LOWER(username)) WHERE username <> '';
+
+        -- Uploaded avatars, as the path they're served from
+        ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url VARCHAR(255) NOT NULL DEFAULT '';
+    `
+
+// userColumns lists every column of users in scanUser's order
+const userColumns = `id, email, username, display_name, bio, favorite_genres, avatar_url, created_at, updated_at`
+
+// postgresStore keeps users in the users table
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a Store backed by the table in Schema
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+// Register creates a user unless they exist, returning their profile
+func (p *postgresStore) Register(id, email string) (models.User, error) {
+	_, err := p.db.Exec(`
+        INSERT INTO users (id, email, created_at, updated_at)
+        VALUES ($1, $2, NOW(), NOW())
+        ON CONFLICT (id) DO NOTHING
+    `, id, email)
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to register user: %w", err)
+	}
+	return p.Get(id)
+}
+
+// Get returns a user's profile, or ErrNotFound
+func (p *postgresStore) Get(id string) (models.User, error) {
+	user, err := scanUser(p.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, ErrNotFound
+	}
+	return user, err
+}
+
+// Update applies the fields set in update to a user's profile
+func (p *postgresStore) Update(id string, update models.UserUpdate) (models.User, error) {
+	var genres interface{} // NULL keeps the stored genres
+	if update.FavoriteGenres != nil {
+		genres = pq.Array(*update.FavoriteGenres)
+	}
+	user, err := scanUser(p.db.QueryRow(`
+        UPDATE users SET
+            email = COALESCE($2, email),
+            username = COALESCE($3, username),
+            display_name = COALESCE($4, display_name),
+            bio = COALESCE($5, bio),
+            favorite_genres = COALESCE($6::text[], favorite_genres),
+            updated_at = NOW()
+        WHERE id = $1
+        RETURNING `+userColumns,
+		id, update.Email, update.Username, update.DisplayName, update.Bio, genres))
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return models.User{}, ErrNotFound
+	case errors.As(err, &pqErr) && pqErr.Code == "23505":
+		return models.User{}, ErrUsernameTaken
+	}
+	return user, err
+}
+
+// SetAvatar sets or clears a user's avatar
+func (p *postgresStore) SetAvatar(id, avatarURL string) (models.User, error) {
+	user, err := scanUser(p.db.QueryRow(`
+        UPDATE users SET avatar_url = $2, updated_at = NOW()
+        WHERE id = $1
+        RETURNING `+userColumns, id, avatarURL))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, ErrNotFound
+	}
+	return user, err
+}
+
+// Delete removes a user's profile, returning it
+func (p *postgresStore) Delete(id string) (models.User, error) {
+	user, err := scanUser(p.db.QueryRow(`DELETE FROM users WHERE id = $1 RETURNING `+userColumns, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, ErrNotFound
+	}
+	return user, err
+}
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser scans a row of userColumns
+func scanUser(row rowScanner) (models.User, error) {
+	var user models.User
+	genres := pq.StringArray{}
+	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.DisplayName, &user.Bio, &genres, &user.AvatarURL, &user.CreatedAt, &user.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, err
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to scan user: %w", err)
+	}
+	user.FavoriteGenres = []string(genres)
+	if user.FavoriteGenres == nil {
+		user.FavoriteGenres = []string{}
+	}
+	return user, nil
+}
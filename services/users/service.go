@@ -0,0 +1,102 @@
+package users
+
+import (
+	"backend/server/models"
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// maxRegistered caps the users remembered as registered; least recently seen
+// ones are upserted again on their next request
+const maxRegistered = 10000
+
+// service implements the users Service interface
+type service struct {
+	store      Store
+	registered map[string]*list.Element // Users known to be registered, by ID
+	order      *list.List               // Front is most recently seen
+	mutex      sync.Mutex
+}
+
+// New creates a new users service
+func New(store Store) Service {
+	return &service{
+		store:      store,
+		registered: make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Register creates the profile of a user on their first request
+func (s *service) Register(id string) error {
+	s.mutex.Lock()
+	element, registered := s.registered[id]
+	if registered {
+		s.order.MoveToFront(element)
+	}
+	s.mutex.Unlock()
+	if registered {
+		return nil
+	}
+	_, err := s.register(id)
+	return err
+}
+
+// Get returns a user's profile, registering them if needed
+func (s *service) Get(id string) (models.User, error) {
+	return s.register(id)
+}
+
+// Update changes a user's profile, registering them if needed
+func (s *service) Update(id string, update models.UserUpdate) (models.User, error) {
+	if err := s.Register(id); err != nil {
+		return models.User{}, err
+	}
+	return s.store.Update(id, update)
+}
+
+// SetAvatar sets or clears a user's avatar, registering them if needed
+func (s *service) SetAvatar(id, avatarURL string) (models.User, error) {
+	if err := s.Register(id); err != nil {
+		return models.User{}, err
+	}
+	return s.store.SetAvatar(id, avatarURL)
+}
+
+// Delete removes a user's profile and forgets that they registered
+func (s *service) Delete(id string) (models.User, error) {
+	s.mutex.Lock()
+	if element, ok := s.registered[id]; ok {
+		s.order.Remove(element)
+		delete(s.registered, id)
+	}
+	s.mutex.Unlock()
+	return s.store.Delete(id)
+}
+
+// register upserts a user, seeding their email from an ID that is one
+func (s *service) register(id string) (models.User, error) {
+	var email string
+	if strings.Contains(id, "@") {
+		email = strings.ToLower(id)
+	}
+	user, err := s.store.Register(id, email)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	s.mutex.Lock()
+	if element, ok := s.registered[id]; ok {
+		s.order.MoveToFront(element)
+	} else {
+		s.registered[id] = s.order.PushFront(id)
+	}
+	for s.order.Len() > maxRegistered {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.registered, oldest.Value.(string))
+	}
+	s.mutex.Unlock()
+	return user, nil
+}
@@ -0,0 +1,18 @@
+package validation
+
+import "backend/server/models"
+
+// TrackCatalog is a store of tracks whose Spotify IDs can be validated
+type TrackCatalog interface {
+	Tracks() []models.UnifiedTrack
+	ReplaceTrack(oldID string, track models.UnifiedTrack) int
+}
+
+// Service defines the interface for stored Spotify ID validation
+type Service interface {
+	// Run validates every Spotify track in the catalog once and returns the report
+	Run() models.CatalogValidationReport
+
+	// LastReport returns the most recent report, or false if no pass has completed
+	LastReport() (models.CatalogValidationReport, bool)
+}
@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"backend/server/models"
+	"backend/services/spotify"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// service implements the validation Service interface
+type service struct {
+	spotifyService spotify.Service
+	catalog        TrackCatalog
+	lastReport     *models.CatalogValidationReport
+	reportMutex    sync.RWMutex
+	runMutex       sync.Mutex // Prevents overlapping validation passes
+}
+
+// New creates a new validation service
+func New(spotifyService spotify.Service, catalog TrackCatalog) Service {
+	return &service{
+		spotifyService: spotifyService,
+		catalog:        catalog,
+	}
+}
+
+// Run validates every Spotify track in the catalog once and returns the report
+func (s *service) Run() models.CatalogValidationReport {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	report := models.CatalogValidationReport{
+		StartedAt: time.Now(),
+		Issues:    []models.CatalogValidationItem{},
+	}
+
+	for _, track := range s.catalog.Tracks() {
+		if track.Source != "spotify" {
+			continue
+		}
+		report.Checked++
+
+		_, err := s.spotifyService.GetTrackByID(track.ID)
+		if err == nil {
+			report.Valid++
+			continue
+		}
+
+		item := models.CatalogValidationItem{
+			TrackID: track.ID,
+			Name:    track.Name,
+			Artist:  track.Artist,
+		}
+
+		if !errors.Is(err, spotify.ErrTrackNotFound) {
+			// Transient failure; don't treat the ID as dead
+			item.Status = "error"
+			item.Error = err.Error()
+			report.Errors++
+			report.Issues = append(report.Issues, item)
+			continue
+		}
+
+		// Dead ID: look for the same song by name and artist
+		replacement, err := s.spotifyService.SearchTrack(track.Name, track.Artist)
+		if err != nil {
+			item.Status = "unresolved"
+			item.Error = err.Error()
+			report.Unresolved++
+			report.Issues = append(report.Issues, item)
+			continue
+		}
+
+		// Keep curated metadata; only the ID and links come from Spotify
+		corrected := track
+		corrected.ID = replacement.ID
+		corrected.ExternalURL = replacement.ExternalURL
+		corrected.PreviewURL = replacement.PreviewURL
+		s.catalog.ReplaceTrack(track.ID, corrected)
+
+		item.Status = "replaced"
+		item.ReplacementID = replacement.ID
+		report.Replaced++
+		report.Issues = append(report.Issues, item)
+	}
+
+	report.FinishedAt = time.Now()
+
+	s.reportMutex.Lock()
+	s.lastReport = &report
+	s.reportMutex.Unlock()
+
+	log.Printf("Catalog validation: %d checked, %d valid, %d replaced, %d unresolved, %d errors",
+		report.Checked, report.Valid, report.Replaced, report.Unresolved, report.Errors)
+
+	return report
+}
+
+// LastReport returns the most recent report, or false if no pass has completed
+func (s *service) LastReport() (models.CatalogValidationReport, bool) {
+	s.reportMutex.RLock()
+	defer s.reportMutex.RUnlock()
+
+	if s.lastReport == nil {
+		return models.CatalogValidationReport{}, false
+	}
+	return *s.lastReport, true
+}
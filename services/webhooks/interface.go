@@ -0,0 +1,50 @@
+package webhooks
+
+import (
+	"backend/server/models"
+	"errors"
+)
+
+// Errors returned by Service
+var (
+	ErrNotFound     = errors.New("webhook not found")
+	ErrInvalidURL   = errors.New("webhook URL must be an absolute http or https URL")
+	ErrInvalidEvent = errors.New("unknown webhook event")
+)
+
+// Store persists registered webhooks
+type Store interface {
+	// Create saves a webhook, returning it with its ID and creation time
+	Create(webhook models.Webhook) (models.Webhook, error)
+
+	// List returns every webhook, secrets included, oldest first
+	List() ([]models.Webhook, error)
+
+	// Delete removes a webhook, returning ErrNotFound if it doesn't exist
+	Delete(id int64) error
+}
+
+// Service delivers bus events to registered webhooks. Each POST is signed
+// with the webhook's secret and retried on failure; recent deliveries are
+// kept per webhook so they can be inspected and redelivered.
+type Service interface {
+	// Register adds a webhook for events, or for all webhook events if none
+	// are given. The returned webhook includes its secret.
+	Register(request models.CreateWebhookRequest) (models.Webhook, error)
+
+	// List returns the registered webhooks without their secrets
+	List() ([]models.Webhook, error)
+
+	// Delete removes a webhook and its delivery log
+	Delete(id int64) error
+
+	// Deliveries returns up to limit of a webhook's recent deliveries,
+	// newest first, optionally only those with the given status
+	Deliveries(id int64, status string, limit int) (models.EventDeliveriesResponse, error)
+
+	// Redeliver posts one of a webhook's remembered deliveries again
+	Redeliver(id, deliveryID int64) (models.EventDelivery, error)
+
+	// Close waits for deliveries in progress to finish
+	Close()
+}
@@ -0,0 +1,55 @@
+package webhooks
+
+import (
+	"backend/server/models"
+	"sync"
+	"time"
+)
+
+// memoryStore keeps webhooks in memory, for development without a database and tests
+type memoryStore struct {
+	webhooks []models.Webhook // Oldest first
+	nextID   int64
+	mutex    sync.RWMutex
+}
+
+// NewMemoryStore creates an in-memory Store
+func NewMemoryStore() Store {
+	return &memoryStore{}
+}
+
+// Create saves a webhook, returning it with its ID and creation time
+func (m *memoryStore) Create(webhook models.Webhook) (models.Webhook, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.nextID++
+	webhook.ID = m.nextID
+	webhook.CreatedAt = time.Now()
+	m.webhooks = append(m.webhooks, webhook)
+	return webhook, nil
+}
+
+// List returns every webhook, secrets included, oldest first
+func (m *memoryStore) List() ([]models.Webhook, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	webhooks := make([]models.Webhook, len(m.webhooks))
+	copy(webhooks, m.webhooks)
+	return webhooks, nil
+}
+
+// Delete removes a webhook, returning ErrNotFound if it doesn't exist
+func (m *memoryStore) Delete(id int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i, webhook := range m.webhooks {
+		if webhook.ID == id {
+			m.webhooks = append(m.webhooks[:i], m.webhooks[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
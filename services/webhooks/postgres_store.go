@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"backend/server/models"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Schema creates the webhooks table. Secrets are stored as given, since
+// they are needed to sign every delivery.
+const Schema = `
+        CREATE TABLE IF NOT EXISTS webhooks (
+            id BIGSERIAL PRIMARY KEY,
+            url TEXT NOT NULL,
+            events TEXT NOT NULL,
+            secret TEXT NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+        );
+    `
+
+// postgresStore keeps webhooks in the webhooks table
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a Store backed by the table in Schema
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+// Create saves a webhook, returning it with its ID and creation time
+func (p *postgresStore) Create(webhook models.Webhook) (models.Webhook, error) {
+	err := p.db.QueryRow(`
+        INSERT INTO webhooks (url, events, secret)
+        VALUES ($1, $2, $3)
+        RETURNING id, created_at
+    `, webhook.URL, strings.Join(webhook.Events, ","), webhook.Secret).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("failed to insert webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// List returns every webhook, secrets included, oldest first
+func (p *postgresStore) List() ([]models.Webhook, error) {
+	rows, err := p.db.Query(`
+        SELECT id, url, events, secret, created_at
+        FROM webhooks
+        ORDER BY id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		var webhook models.Webhook
+		var eventTypes string
+		if err := rows.Scan(&webhook.ID, &webhook.URL, &eventTypes, &webhook.Secret, &webhook.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhook.Events = strings.Split(eventTypes, ",")
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// Delete removes a webhook, returning ErrNotFound if it doesn't exist
+func (p *postgresStore) Delete(id int64) error {
+	result, err := p.db.Exec(`DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
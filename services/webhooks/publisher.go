@@ -0,0 +1,66 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Headers sent with every webhook POST
+const (
+	// EventHeader names the event type, e.g. "track_changed"
+	EventHeader = "X-LinkinSync-Event"
+	// SignatureHeader holds Sign of the body with the webhook's secret;
+	// receivers should compare it in constant time before trusting the body
+	SignatureHeader = "X-LinkinSync-Signature-256"
+)
+
+// Sign returns the signature of body for secret: "sha256=" followed by the
+// hex-encoded HMAC-SHA256
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// publisher posts events to one webhook's URL. It implements
+// streaming.Publisher, so streaming.NewDeliveryLog can retry and record its
+// deliveries; the topic is the event type.
+type publisher struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+// Publish posts data to the webhook, treating any non-2xx status as a failure
+func (p *publisher) Publish(topic string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LinkinSync-Webhooks")
+	req.Header.Set(EventHeader, topic)
+	req.Header.Set(SignatureHeader, Sign(p.secret, data))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Let the connection be reused
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close does nothing; the HTTP client is shared by all webhooks
+func (p *publisher) Close() error {
+	return nil
+}
@@ -0,0 +1,261 @@
+package webhooks
+
+import (
+	"backend/server/models"
+	"backend/services/events"
+	"backend/services/streaming"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// backend is shown on every webhook delivery
+const backend = "webhook"
+
+// Config holds webhook delivery settings
+type Config struct {
+	MaxAttempts     int           // Attempts per delivery, including the first
+	RetryDelay      time.Duration // Delay before the first retry, doubling after each
+	Timeout         time.Duration // Time allowed for each POST
+	DeliveryLogSize int           // Recent deliveries kept per webhook
+}
+
+// DefaultConfig returns a default configuration for webhook delivery
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts:     5,
+		RetryDelay:      time.Second,
+		Timeout:         10 * time.Second,
+		DeliveryLogSize: 50,
+	}
+}
+
+// Events are the bus events webhooks can receive
+var Events = []string{
+	events.TrackChanged,
+	events.MoodDetected,
+}
+
+// message is the JSON body posted for each event
+type message struct {
+	WebhookID int64       `json:"webhook_id"`
+	Type      string      `json:"type"`
+	Time      time.Time   `json:"time"`
+	Payload   interface{} `json:"payload"`
+}
+
+// service implements Service
+type service struct {
+	store  Store
+	config Config
+	client *http.Client
+	logs   map[int64]streaming.DeliveryLog // Keyed by webhook ID, created on first delivery
+	mutex  sync.Mutex
+	wg     sync.WaitGroup
+}
+
+// New creates a webhook service delivering events from bus
+func New(store Store, bus events.Bus, config Config) Service {
+	s := &service{
+		store:  store,
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		logs:   make(map[int64]streaming.DeliveryLog),
+	}
+	for _, eventType := range Events {
+		bus.Subscribe(eventType, "webhooks", s.handle)
+	}
+	return s
+}
+
+// Register adds a webhook after validating its URL and events
+func (s *service) Register(request models.CreateWebhookRequest) (models.Webhook, error) {
+	parsed, err := url.Parse(request.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return models.Webhook{}, ErrInvalidURL
+	}
+
+	eventTypes := []string{}
+	for _, eventType := range request.Events {
+		if !contains(Events, eventType) {
+			return models.Webhook{}, fmt.Errorf("%w %q", ErrInvalidEvent, eventType)
+		}
+		if !contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	if len(eventTypes) == 0 {
+		eventTypes = append(eventTypes, Events...)
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return models.Webhook{}, err
+		}
+	}
+
+	return s.store.Create(models.Webhook{
+		URL:    parsed.String(),
+		Events: eventTypes,
+		Secret: secret,
+	})
+}
+
+// List returns the registered webhooks without their secrets
+func (s *service) List() ([]models.Webhook, error) {
+	webhooks, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// Delete removes a webhook and its delivery log
+func (s *service) Delete(id int64) error {
+	if err := s.store.Delete(id); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	delete(s.logs, id)
+	s.mutex.Unlock()
+	return nil
+}
+
+// Deliveries returns up to limit of a webhook's recent deliveries
+func (s *service) Deliveries(id int64, status string, limit int) (models.EventDeliveriesResponse, error) {
+	if _, err := s.find(id); err != nil {
+		return models.EventDeliveriesResponse{}, err
+	}
+
+	s.mutex.Lock()
+	deliveryLog, ok := s.logs[id]
+	s.mutex.Unlock()
+	if !ok {
+		return models.EventDeliveriesResponse{Deliveries: []models.EventDelivery{}}, nil
+	}
+	return models.EventDeliveriesResponse{
+		Deliveries: deliveryLog.Deliveries(status, limit),
+		Failed:     deliveryLog.Failed(),
+	}, nil
+}
+
+// Redeliver posts a remembered delivery again. A failed attempt is recorded
+// on the delivery and also returned as an error.
+func (s *service) Redeliver(id, deliveryID int64) (models.EventDelivery, error) {
+	if _, err := s.find(id); err != nil {
+		return models.EventDelivery{}, err
+	}
+
+	s.mutex.Lock()
+	deliveryLog, ok := s.logs[id]
+	s.mutex.Unlock()
+	if !ok {
+		return models.EventDelivery{}, streaming.ErrDeliveryNotFound
+	}
+	return deliveryLog.Redeliver(deliveryID)
+}
+
+// Close waits for deliveries in progress, including their retries
+func (s *service) Close() {
+	s.wg.Wait()
+}
+
+// handle posts an event to every webhook registered for it. Each webhook is
+// delivered to on its own goroutine, so a slow or failing endpoint doesn't
+// hold up the others; retries may deliver a webhook's events out of order.
+func (s *service) handle(event events.Event) error {
+	webhooks, err := s.store.List()
+	if err != nil {
+		return fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	for _, webhook := range webhooks {
+		if !contains(webhook.Events, event.Type) {
+			continue
+		}
+		data, err := json.Marshal(message{
+			WebhookID: webhook.ID,
+			Type:      event.Type,
+			Time:      event.Time,
+			Payload:   event.Payload,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+
+		deliveryLog := s.logFor(webhook)
+		s.wg.Add(1)
+		go func(id int64) {
+			defer s.wg.Done()
+			if err := deliveryLog.Publish(event.Type, data); err != nil {
+				log.Printf("Webhook %d failed to receive %s: %v", id, event.Type, err)
+			}
+		}(webhook.ID)
+	}
+	return nil
+}
+
+// logFor returns the webhook's delivery log, creating it on first use
+func (s *service) logFor(webhook models.Webhook) streaming.DeliveryLog {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	deliveryLog, ok := s.logs[webhook.ID]
+	if !ok {
+		deliveryLog = streaming.NewDeliveryLog(&publisher{
+			client: s.client,
+			url:    webhook.URL,
+			secret: webhook.Secret,
+		}, streaming.DeliveryLogConfig{
+			Backend:     backend,
+			Size:        s.config.DeliveryLogSize,
+			MaxAttempts: s.config.MaxAttempts,
+			RetryDelay:  s.config.RetryDelay,
+		})
+		s.logs[webhook.ID] = deliveryLog
+	}
+	return deliveryLog
+}
+
+// find returns a registered webhook by ID
+func (s *service) find(id int64) (models.Webhook, error) {
+	webhooks, err := s.store.List()
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	for _, webhook := range webhooks {
+		if webhook.ID == id {
+			return webhook, nil
+		}
+	}
+	return models.Webhook{}, ErrNotFound
+}
+
+// generateSecret returns a random signing secret
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// contains reports whether values contains value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -34,7 +34,7 @@ func setupTestServer() *httptest.Server {
 	
 	// Create repositories and handlers
 	musicRepo := repositories.NewMusicRepository(mockGenius)
-	lyricsHandler := handlers.NewLyricsHandler(musicRepo, mockOllama)
+	lyricsHandler := handlers.NewLyricsHandler(musicRepo, mockOllama, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
 	
 	// Setup router
 	r := mux.NewRouter()
@@ -43,8 +43,12 @@ func setupTestServer() *httptest.Server {
 	// Add routes
 	api.HandleFunc("/now-playing", lyricsHandler.UpdateNowPlaying).Methods("POST")
 	api.HandleFunc("/now-playing", lyricsHandler.GetNowPlaying).Methods("GET")
+	api.HandleFunc("/now-playing", lyricsHandler.DeleteNowPlaying).Methods("DELETE")
+	api.HandleFunc("/now-playing/state", lyricsHandler.UpdatePlaybackState).Methods("POST")
+	api.HandleFunc("/now-playing/heartbeat", lyricsHandler.Heartbeat).Methods("POST")
 	api.HandleFunc("/history", lyricsHandler.GetPlayHistory).Methods("GET")
 	api.HandleFunc("/chat", lyricsHandler.HandleChat).Methods("POST")
+	api.HandleFunc("/tracks/moods", lyricsHandler.GetTrackMoods).Methods("POST")
 	api.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
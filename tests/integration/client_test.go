@@ -0,0 +1,107 @@
+package integration_test
+
+import (
+	"backend/client"
+	"backend/server/models"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClient_NowPlayingWorkflow(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+	c := client.New(client.Config{BaseURL: server.URL + "/", APIKey: "test-key", UserID: "bot"})
+	ctx := context.Background()
+
+	if err := c.Health(ctx); err != nil {
+		t.Fatalf("Expected the server to be healthy, got %v", err)
+	}
+
+	if _, err := c.NowPlaying(ctx); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound when nothing is playing, got %v", err)
+	}
+
+	track := models.UnifiedTrack{ID: "numb", Name: "Numb", Artist: "Linkin Park", Source: "spotify"}
+	version, err := c.UpdateNowPlaying(ctx, track)
+	if err != nil {
+		t.Fatalf("Failed to update now playing: %v", err)
+	}
+
+	nowPlaying, err := c.NowPlaying(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get now playing: %v", err)
+	}
+	if nowPlaying.TrackName != "Numb" || nowPlaying.Version != version {
+		t.Errorf("Expected Numb at version %d, got %s at %d", version, nowPlaying.TrackName, nowPlaying.Version)
+	}
+
+	// A stale version is rejected
+	next := models.UnifiedTrack{ID: "faint", Name: "Faint", Artist: "Linkin Park", Source: "spotify"}
+	if _, err := c.UpdateNowPlayingIfVersion(ctx, next, version-1); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a stale version, got %v", err)
+	}
+	if _, err := c.UpdateNowPlayingIfVersion(ctx, next, version); err != nil {
+		t.Errorf("Expected the update at the current version to succeed, got %v", err)
+	}
+
+	if err := c.Heartbeat(ctx, "faint", 30000, 162000); err != nil {
+		t.Errorf("Failed to send heartbeat: %v", err)
+	}
+	if err := c.SetPlaybackState(ctx, models.PlaybackPaused); err != nil {
+		t.Errorf("Failed to pause: %v", err)
+	}
+
+	history, err := c.History(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(history) != 2 || history[0].TrackID != "faint" {
+		t.Errorf("Expected Faint then Numb in history, got %+v", history)
+	}
+
+	filtered, total, err := c.FilterHistory(ctx, models.PlayHistoryFilter{Artist: "linkin park"}, 1, 10)
+	if err != nil {
+		t.Fatalf("Failed to filter history: %v", err)
+	}
+	if total != 2 || len(filtered) != 1 || filtered[0].TrackID != "numb" {
+		t.Errorf("Expected the second of 2 matching songs, got %+v of %d", filtered, total)
+	}
+
+	if err := c.StopNowPlaying(ctx); err != nil {
+		t.Errorf("Failed to stop playback: %v", err)
+	}
+	if _, err := c.NowPlaying(ctx); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after stopping, got %v", err)
+	}
+}
+
+func TestClient_ChatAndMoods(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+	c := client.New(client.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	response, err := c.Chat(ctx, "What's the weather like?")
+	if err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+	if !strings.Contains(response.Answer, "music") {
+		t.Errorf("Expected a music-only answer, got %q", response.Answer)
+	}
+
+	moods, err := c.TrackMoods(ctx, []models.TrackReference{{Name: "Numb", Artist: "Linkin Park"}}, false)
+	if err != nil {
+		t.Fatalf("Failed to look up moods: %v", err)
+	}
+	if len(moods.Results) != 1 || moods.Results[0].Cached {
+		t.Errorf("Expected one uncached result, got %+v", moods.Results)
+	}
+
+	_, err = c.Chat(ctx, "")
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 || apiErr.Code != "invalid_request" {
+		t.Errorf("Expected a 400 invalid_request APIError for an empty query, got %v", err)
+	}
+}
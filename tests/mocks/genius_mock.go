@@ -1,10 +1,15 @@
 package mocks
 
-import "backend/services/genius"
+import (
+	"backend/server/models"
+	"backend/services/genius"
+)
 
 // MockGeniusService implements genius.Service for testing
 type MockGeniusService struct {
 	GetLyricsFunc func(trackName, artistName string) (string, error)
+	GetArtistFunc func(name string) (*models.GeniusArtist, error)
+	GetSongFunc   func(trackName, artistName string) (*models.GeniusSong, error)
 }
 
 // Ensure MockGeniusService implements genius.Service
@@ -16,4 +21,20 @@ func (m *MockGeniusService) GetLyrics(trackName, artistName string) (string, err
 		return m.GetLyricsFunc(trackName, artistName)
 	}
 	return "Mock lyrics for " + trackName + " by " + artistName, nil
-}
\ No newline at end of file
+}
+
+// GetArtist calls the mock function if set, otherwise returns an artist with the given name
+func (m *MockGeniusService) GetArtist(name string) (*models.GeniusArtist, error) {
+	if m.GetArtistFunc != nil {
+		return m.GetArtistFunc(name)
+	}
+	return &models.GeniusArtist{ID: 1, Name: name, URL: "https://genius.com/artists/mock"}, nil
+}
+
+// GetSong calls the mock function if set, otherwise returns a song with the given title
+func (m *MockGeniusService) GetSong(trackName, artistName string) (*models.GeniusSong, error) {
+	if m.GetSongFunc != nil {
+		return m.GetSongFunc(trackName, artistName)
+	}
+	return &models.GeniusSong{ID: 1, Title: trackName, Artist: artistName, URL: "https://genius.com/mock"}, nil
+}
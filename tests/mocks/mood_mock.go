@@ -8,10 +8,14 @@ import (
 // MockMoodService implements mood.Service for testing
 type MockMoodService struct {
 	DetectMoodFunc       func(message string) (*models.MoodAnalysis, error)
+	DetectMoodWithPromptFunc func(template, message string) (*models.MoodAnalysis, error)
 	MatchSongsToMoodFunc func(moodAnalysis *models.MoodAnalysis, userTracks []models.UnifiedTrack, limit int) ([]models.MoodBasedRecommendation, error)
 	GetLyricsWithMoodFunc func(trackName, artistName string) (*mood.LyricsWithMood, error)
+	GetCachedLyricsMoodFunc func(trackName, artistName string) (*mood.LyricsWithMood, bool)
 	SaveUserMoodHistoryFunc func(userID string, mood string, playedSongs []string) error
 	GetUserMoodHistoryFunc func(userID string) ([]mood.UserMoodEntry, error)
+	AddMoodNoteFunc      func(userID, entryTimestamp, note string) (string, error)
+	ListMoodUsersFunc    func() ([]string, error)
 }
 
 // Ensure MockMoodService implements mood.Service
@@ -29,6 +33,14 @@ func (m *MockMoodService) DetectMood(message string) (*models.MoodAnalysis, erro
 	}, nil
 }
 
+// DetectMoodWithPrompt calls the mock function if set, otherwise DetectMood
+func (m *MockMoodService) DetectMoodWithPrompt(template, message string) (*models.MoodAnalysis, error) {
+	if m.DetectMoodWithPromptFunc != nil {
+		return m.DetectMoodWithPromptFunc(template, message)
+	}
+	return m.DetectMood(message)
+}
+
 // MatchSongsToMood calls the mock function if set, otherwise returns default values
 func (m *MockMoodService) MatchSongsToMood(moodAnalysis *models.MoodAnalysis, userTracks []models.UnifiedTrack, limit int) ([]models.MoodBasedRecommendation, error) {
 	if m.MatchSongsToMoodFunc != nil {
@@ -53,6 +65,14 @@ func (m *MockMoodService) GetLyricsWithMood(trackName, artistName string) (*mood
 	}, nil
 }
 
+// GetCachedLyricsMood calls the mock function if set, otherwise reports a cache miss
+func (m *MockMoodService) GetCachedLyricsMood(trackName, artistName string) (*mood.LyricsWithMood, bool) {
+	if m.GetCachedLyricsMoodFunc != nil {
+		return m.GetCachedLyricsMoodFunc(trackName, artistName)
+	}
+	return nil, false
+}
+
 // SaveUserMoodHistory calls the mock function if set, otherwise returns nil
 func (m *MockMoodService) SaveUserMoodHistory(userID string, mood string, playedSongs []string) error {
 	if m.SaveUserMoodHistoryFunc != nil {
@@ -67,4 +87,20 @@ func (m *MockMoodService) GetUserMoodHistory(userID string) ([]mood.UserMoodEntr
 		return m.GetUserMoodHistoryFunc(userID)
 	}
 	return []mood.UserMoodEntry{}, nil
-}
\ No newline at end of file
+}
+
+// AddMoodNote calls the mock function if set, otherwise reports no mood entry
+func (m *MockMoodService) AddMoodNote(userID, entryTimestamp, note string) (string, error) {
+	if m.AddMoodNoteFunc != nil {
+		return m.AddMoodNoteFunc(userID, entryTimestamp, note)
+	}
+	return "", mood.ErrMoodEntryNotFound
+}
+
+// ListMoodUsers calls the mock function if set, otherwise returns no users
+func (m *MockMoodService) ListMoodUsers() ([]string, error) {
+	if m.ListMoodUsersFunc != nil {
+		return m.ListMoodUsersFunc()
+	}
+	return []string{}, nil
+}
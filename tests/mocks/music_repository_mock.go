@@ -0,0 +1,124 @@
+package mocks
+
+import (
+	"backend/repositories"
+	"backend/server/models"
+	"errors"
+)
+
+// MockMusicRepository implements repositories.MusicRepository for testing.
+// Without mock functions nothing is playing and the history is empty.
+type MockMusicRepository struct {
+	UpdateNowPlayingUnifiedFunc   func(track models.UnifiedTrack) bool
+	UpdateNowPlayingIfVersionFunc func(track models.UnifiedTrack, version int64) bool
+	GetNowPlayingFunc             func() models.NowPlaying
+	SetPlaybackStateFunc          func(state string) bool
+	HeartbeatFunc                 func(trackID string, positionMs, durationMs int64) bool
+	GetPlayHistoryFunc            func() []models.PlayHistoryItem
+	QueryPlayHistoryFunc          func(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error)
+	GetLyricsForCurrentSongFunc   func() (string, error)
+	Listeners                     []repositories.TrackListener
+}
+
+// Ensure MockMusicRepository implements repositories.MusicRepository
+var _ repositories.MusicRepository = (*MockMusicRepository)(nil)
+
+// AddTrackListener records the listener in Listeners
+func (m *MockMusicRepository) AddTrackListener(listener repositories.TrackListener) {
+	m.Listeners = append(m.Listeners, listener)
+}
+
+// UpdateNowPlaying calls UpdateNowPlayingUnified with the converted track
+func (m *MockMusicRepository) UpdateNowPlaying(track models.SpotifyTrack) bool {
+	return m.UpdateNowPlayingUnified(models.FromSpotifyTrack(track))
+}
+
+// UpdateNowPlayingUnified calls the mock function if set, otherwise accepts the update
+func (m *MockMusicRepository) UpdateNowPlayingUnified(track models.UnifiedTrack) bool {
+	if m.UpdateNowPlayingUnifiedFunc != nil {
+		return m.UpdateNowPlayingUnifiedFunc(track)
+	}
+	return true
+}
+
+// UpdateNowPlayingIfVersion calls the mock function if set, otherwise accepts the update
+func (m *MockMusicRepository) UpdateNowPlayingIfVersion(track models.UnifiedTrack, version int64) bool {
+	if m.UpdateNowPlayingIfVersionFunc != nil {
+		return m.UpdateNowPlayingIfVersionFunc(track, version)
+	}
+	return true
+}
+
+// GetNowPlaying calls the mock function if set, otherwise returns nothing playing
+func (m *MockMusicRepository) GetNowPlaying() models.NowPlaying {
+	if m.GetNowPlayingFunc != nil {
+		return m.GetNowPlayingFunc()
+	}
+	return models.NowPlaying{}
+}
+
+// IsPlaying reports whether GetNowPlaying has an unpaused track
+func (m *MockMusicRepository) IsPlaying() bool {
+	current := m.GetNowPlaying()
+	return current.TrackID != "" && current.State != models.PlaybackPaused
+}
+
+// HasCurrentTrack reports whether GetNowPlaying has a track
+func (m *MockMusicRepository) HasCurrentTrack() bool {
+	current := m.GetNowPlaying()
+	return current.TrackID != ""
+}
+
+// SetPlaybackState calls the mock function if set, otherwise reports that no track is loaded
+func (m *MockMusicRepository) SetPlaybackState(state string) bool {
+	if m.SetPlaybackStateFunc != nil {
+		return m.SetPlaybackStateFunc(state)
+	}
+	return false
+}
+
+// StopNowPlaying sets the stopped playback state
+func (m *MockMusicRepository) StopNowPlaying() bool {
+	return m.SetPlaybackState(models.PlaybackStopped)
+}
+
+// Heartbeat calls the mock function if set, otherwise reports that no track is loaded
+func (m *MockMusicRepository) Heartbeat(trackID string, positionMs, durationMs int64) bool {
+	if m.HeartbeatFunc != nil {
+		return m.HeartbeatFunc(trackID, positionMs, durationMs)
+	}
+	return false
+}
+
+// GetPlayHistory calls the mock function if set, otherwise returns an empty history
+func (m *MockMusicRepository) GetPlayHistory() []models.PlayHistoryItem {
+	if m.GetPlayHistoryFunc != nil {
+		return m.GetPlayHistoryFunc()
+	}
+	return []models.PlayHistoryItem{}
+}
+
+// QueryPlayHistory calls the mock function if set, otherwise returns an empty page
+func (m *MockMusicRepository) QueryPlayHistory(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error) {
+	if m.QueryPlayHistoryFunc != nil {
+		return m.QueryPlayHistoryFunc(filter, offset, limit)
+	}
+	return []models.PlayHistoryItem{}, 0, nil
+}
+
+// GetLyricsForCurrentSong calls the mock function if set, otherwise fails as nothing is playing
+func (m *MockMusicRepository) GetLyricsForCurrentSong() (string, error) {
+	if m.GetLyricsForCurrentSongFunc != nil {
+		return m.GetLyricsForCurrentSongFunc()
+	}
+	return "", errors.New("no song is currently playing")
+}
+
+// GetCurrentSongInfo describes the track returned by GetNowPlaying
+func (m *MockMusicRepository) GetCurrentSongInfo() string {
+	current := m.GetNowPlaying()
+	if current.TrackName == "" || current.Artist == "" {
+		return ""
+	}
+	return current.TrackName + " by " + current.Artist
+}
@@ -1,11 +1,17 @@
 package mocks
 
-import "backend/services/ollama"
+import (
+	"backend/services/ollama"
+	"context"
+	"strings"
+)
 
 // MockOllamaService implements ollama.Service for testing
 type MockOllamaService struct {
 	AnalyzeLyricsFunc    func(query, lyrics, songInfo string) (string, error)
 	GenerateResponseFunc func(prompt string) (string, error)
+	GenerateJSONFunc     func(prompt string) (string, error)
+	GenerateStreamFunc   func(ctx context.Context, prompt string, onChunk func(chunk string) error) error
 	IsAvailableFunc      func() error
 }
 
@@ -28,6 +34,34 @@ func (m *MockOllamaService) GenerateResponse(prompt string) (string, error) {
 	return "Mock response for: " + prompt, nil
 }
 
+// GenerateJSON calls the mock function if set, otherwise returns a valid mood analysis
+func (m *MockOllamaService) GenerateJSON(prompt string) (string, error) {
+	if m.GenerateJSONFunc != nil {
+		return m.GenerateJSONFunc(prompt)
+	}
+	return `{"primary_mood": "happy", "mood_score": 0.8, "emotion_tags": ["positive"], "themes": ["joy"]}`, nil
+}
+
+// GenerateStream calls the mock function if set, otherwise streams the GenerateResponse result word by word
+func (m *MockOllamaService) GenerateStream(ctx context.Context, prompt string, onChunk func(chunk string) error) error {
+	if m.GenerateStreamFunc != nil {
+		return m.GenerateStreamFunc(ctx, prompt, onChunk)
+	}
+	response, err := m.GenerateResponse(prompt)
+	if err != nil {
+		return err
+	}
+	for _, word := range strings.SplitAfter(response, " ") {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := onChunk(word); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // IsAvailable calls the mock function if set, otherwise returns nil
 func (m *MockOllamaService) IsAvailable() error {
 	if m.IsAvailableFunc != nil {
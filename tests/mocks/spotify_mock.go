@@ -9,6 +9,13 @@ import (
 type MockSpotifyService struct {
 	GetAccessTokenFunc func() (string, error)
 	GetTrackByIDFunc   func(trackID string) (*models.SpotifyTrack, error)
+	SearchTrackFunc        func(name, artist string) (*models.UnifiedTrack, error)
+	SearchArtistFunc       func(name string) (*models.SpotifyArtist, error)
+	GetRelatedArtistsFunc  func(artistID string) ([]models.SpotifyArtist, error)
+	GetArtistTopTracksFunc func(artistID string) ([]models.UnifiedTrack, error)
+	GetAlbumFunc           func(albumID string) (*models.SpotifyAlbum, error)
+	AddToQueueFunc         func(userToken, trackID string) error
+	CreatePlaylistFunc     func(userToken, name, description string, trackIDs []string) (*models.SpotifyPlaylist, error)
 }
 
 // Ensure MockSpotifyService implements spotify.Service
@@ -33,4 +40,60 @@ func (m *MockSpotifyService) GetTrackByID(trackID string) (*models.SpotifyTrack,
 		Artist: "Mock Artist",
 		Album:  "Mock Album",
 	}, nil
-}
\ No newline at end of file
+}
+
+// SearchTrack calls the mock function if set, otherwise returns a track with the given name
+func (m *MockSpotifyService) SearchTrack(name, artist string) (*models.UnifiedTrack, error) {
+	if m.SearchTrackFunc != nil {
+		return m.SearchTrackFunc(name, artist)
+	}
+	return &models.UnifiedTrack{ID: "mock_track", Name: name, Artist: artist, Source: "spotify"}, nil
+}
+
+// SearchArtist calls the mock function if set, otherwise returns an artist with the given name
+func (m *MockSpotifyService) SearchArtist(name string) (*models.SpotifyArtist, error) {
+	if m.SearchArtistFunc != nil {
+		return m.SearchArtistFunc(name)
+	}
+	return &models.SpotifyArtist{ID: "mock_artist", Name: name}, nil
+}
+
+// GetRelatedArtists calls the mock function if set, otherwise returns an empty slice
+func (m *MockSpotifyService) GetRelatedArtists(artistID string) ([]models.SpotifyArtist, error) {
+	if m.GetRelatedArtistsFunc != nil {
+		return m.GetRelatedArtistsFunc(artistID)
+	}
+	return []models.SpotifyArtist{}, nil
+}
+
+// GetArtistTopTracks calls the mock function if set, otherwise returns an empty slice
+func (m *MockSpotifyService) GetArtistTopTracks(artistID string) ([]models.UnifiedTrack, error) {
+	if m.GetArtistTopTracksFunc != nil {
+		return m.GetArtistTopTracksFunc(artistID)
+	}
+	return []models.UnifiedTrack{}, nil
+}
+
+// GetAlbum calls the mock function if set, otherwise returns an album without tracks
+func (m *MockSpotifyService) GetAlbum(albumID string) (*models.SpotifyAlbum, error) {
+	if m.GetAlbumFunc != nil {
+		return m.GetAlbumFunc(albumID)
+	}
+	return &models.SpotifyAlbum{ID: albumID, Name: "Mock Album", Artist: "Mock Artist", Tracks: []models.UnifiedTrack{}}, nil
+}
+
+// AddToQueue calls the mock function if set, otherwise succeeds
+func (m *MockSpotifyService) AddToQueue(userToken, trackID string) error {
+	if m.AddToQueueFunc != nil {
+		return m.AddToQueueFunc(userToken, trackID)
+	}
+	return nil
+}
+
+// CreatePlaylist calls the mock function if set, otherwise returns a playlist with the given name
+func (m *MockSpotifyService) CreatePlaylist(userToken, name, description string, trackIDs []string) (*models.SpotifyPlaylist, error) {
+	if m.CreatePlaylistFunc != nil {
+		return m.CreatePlaylistFunc(userToken, name, description, trackIDs)
+	}
+	return &models.SpotifyPlaylist{ID: "mock_playlist", Name: name, URL: "https://open.spotify.com/playlist/mock_playlist", TrackCount: len(trackIDs)}, nil
+}
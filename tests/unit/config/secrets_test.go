@@ -0,0 +1,128 @@
+package config_test
+
+import (
+	"backend/config"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_ResolvesDockerSecretsAndFiles(t *testing.T) {
+	setRequiredEnv(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "db_password"), []byte("from-docker\n"), 0600)
+	keyFile := filepath.Join(dir, "openai_key")
+	os.WriteFile(keyFile, []byte("sk-from-file"), 0600)
+
+	t.Setenv("DOCKER_SECRETS_DIR", dir)
+	t.Setenv("DB_PASSWORD", "docker-secret://db_password")
+	t.Setenv("OPENAI_API_KEY", "file://"+keyFile)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Database.Password != "from-docker" {
+		t.Errorf("Expected password from Docker secret, got %q", cfg.Database.Password)
+	}
+	if cfg.OpenAI.APIKey != "sk-from-file" || cfg.AI.Provider != "openai" {
+		t.Errorf("Expected OpenAI key from file, got %q (provider %s)", cfg.OpenAI.APIKey, cfg.AI.Provider)
+	}
+}
+
+func TestLoad_ResolvesVaultSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/linkinsync" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data": map[string]string{"genius": "genius-from-vault"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	setRequiredEnv(t)
+	t.Setenv("AI_PROVIDER", "ollama")
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "vault-token")
+	t.Setenv("GENIUS_ACCESS_TOKEN", "vault://secret/data/linkinsync#genius")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Genius.AccessToken != "genius-from-vault" {
+		t.Errorf("Expected Genius token from Vault, got %q", cfg.Genius.AccessToken)
+	}
+}
+
+func TestLoad_ResolvesAWSSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDTEST/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var input struct{ SecretId string }
+		json.NewDecoder(r.Body).Decode(&input)
+		if input.SecretId != "prod/linkinsync" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"SecretString": `{"spotify_secret":"spotify-from-aws"}`,
+		})
+	}))
+	defer server.Close()
+
+	setRequiredEnv(t)
+	t.Setenv("AI_PROVIDER", "ollama")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SECRETSMANAGER_ENDPOINT", server.URL)
+	t.Setenv("SPOTIFY_CLIENT_SECRET", "awssm://prod/linkinsync#spotify_secret")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Spotify.ClientSecret != "spotify-from-aws" {
+		t.Errorf("Expected Spotify secret from AWS, got %q", cfg.Spotify.ClientSecret)
+	}
+}
+
+func TestLoad_ReportsUnresolvableSecrets(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("AI_PROVIDER", "ollama")
+	t.Setenv("DOCKER_SECRETS_DIR", t.TempDir())
+	t.Setenv("DB_PASSWORD", "docker-secret://missing")
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("GENIUS_ACCESS_TOKEN", "vault://secret/data/linkinsync#genius")
+
+	_, err := config.Load()
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+	if len(validationErr.Problems) != 2 {
+		t.Errorf("Expected both secrets reported, got %v", validationErr.Problems)
+	}
+	for _, problem := range validationErr.Problems {
+		if !strings.Contains(problem, "failed to resolve secret") {
+			t.Errorf("Unexpected problem: %s", problem)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/albums"
+	"backend/services/jobqueue"
+	"backend/services/mood"
+	"backend/tests/mocks"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func albumAnalysisRequest(handler func(http.ResponseWriter, *http.Request), method, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "hybrid"})
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestLyricsHandler_AlbumAnalysis(t *testing.T) {
+	mockSpotify := &mocks.MockSpotifyService{
+		GetAlbumFunc: func(albumID string) (*models.SpotifyAlbum, error) {
+			return &models.SpotifyAlbum{ID: albumID, Name: "Hybrid Theory", Artist: "Linkin Park", Tracks: []models.UnifiedTrack{{ID: "t1", Name: "Papercut"}}}, nil
+		},
+	}
+	mockMood := &mocks.MockMoodService{
+		GetLyricsWithMoodFunc: func(trackName, artistName string) (*mood.LyricsWithMood, error) {
+			return &mood.LyricsWithMood{Lyrics: "Why does it feel like night today", MoodAnalysis: &models.MoodAnalysis{PrimaryMood: "anxious"}}, nil
+		},
+	}
+	mockAI := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(prompt string) (string, error) {
+			return `{"narrative": "A damn restless debut.", "recurring_themes": ["paranoia"]}`, nil
+		},
+	}
+	handler := handlers.NewLyricsHandler(repositories.NewMusicRepository(&mocks.MockGeniusService{}), mockAI, mockMood, mockSpotify)
+
+	if w := albumAnalysisRequest(handler.AnalyzeAlbum, "POST", "/api/albums/hybrid/analysis"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without the albums service, got %d", w.Code)
+	}
+	handler.SetAlbums(albums.New(mockSpotify, mockMood, mockAI, nil, albums.DefaultConfig()))
+
+	if w := albumAnalysisRequest(handler.GetAlbumAnalysis, "GET", "/api/albums/hybrid/analysis"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 before the album is analyzed, got %d", w.Code)
+	}
+	if w := albumAnalysisRequest(handler.AnalyzeAlbum, "POST", "/api/albums/hybrid/analysis"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a job queue, got %d", w.Code)
+	}
+
+	queue := jobqueue.New(jobqueue.NewMemoryStore(), jobqueue.DefaultConfig())
+	queue.Handle(models.JobTypeAlbumAnalysis, handler.AlbumAnalysisJob)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue.Start(ctx)
+	handler.SetJobQueue(queue)
+
+	w := albumAnalysisRequest(handler.AnalyzeAlbum, "POST", "/api/albums/hybrid/analysis")
+	var job models.QueuedJob
+	json.Unmarshal(w.Body.Bytes(), &job)
+	if w.Code != http.StatusAccepted || job.Type != models.JobTypeAlbumAnalysis || w.Header().Get("Location") != fmt.Sprintf("/api/jobs/%d", job.ID) {
+		t.Fatalf("Expected 202 with the queued job, got %d: %s", w.Code, w.Body.String())
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for job.Status != models.JobSucceeded && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		job, _ = queue.Get(job.ID)
+	}
+	var result models.AlbumAnalysis
+	json.Unmarshal(job.Result, &result)
+	if job.Status != models.JobSucceeded || result.Narrative != "A damn restless debut." || len(result.Tracks) != 1 {
+		t.Fatalf("Expected the analysis in the job result, got %+v", job)
+	}
+
+	w = albumAnalysisRequest(handler.GetAlbumAnalysis, "GET", "/api/albums/hybrid/analysis")
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if w.Code != http.StatusOK || result.Name != "Hybrid Theory" || result.Tracks[0].Mood.PrimaryMood != "anxious" {
+		t.Errorf("Expected the cached analysis, got %d %+v", w.Code, result)
+	}
+
+	w = albumAnalysisRequest(handler.AnalyzeAlbum, "POST", "/api/albums/hybrid/analysis?clean=true")
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if w.Code != http.StatusOK || result.Narrative != "A d*** restless debut." {
+		t.Errorf("Expected the cached analysis at once, masked in clean mode, got %d %+v", w.Code, result)
+	}
+}
@@ -0,0 +1,147 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/analysiscache"
+	"backend/services/restricted"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func rateAnalysis(handler *handlers.LyricsHandler, userID, id string, helpful bool) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(models.RateAnalysisRequest{Helpful: &helpful})
+	req := httptest.NewRequest("POST", "/api/analyses/"+id+"/rating", bytes.NewBuffer(body))
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	handler.RateAnalysis(w, req)
+	return w
+}
+
+func TestLyricsHandler_AnalysisCache_SharesCommonAnswers(t *testing.T) {
+	calls := 0
+	mockAI := &mocks.MockOllamaService{
+		AnalyzeLyricsFunc: func(query, lyrics, songInfo string) (string, error) {
+			calls++
+			return "It's about letting go", nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{
+		GetLyricsFunc: func(trackName, artistName string) (string, error) { return "lyrics", nil },
+	})
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "t1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+	handler := handlers.NewLyricsHandler(musicRepo, mockAI, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+	handler.SetRestrictions(restricted.New(restricted.Config{Users: []string{"teen"}}))
+	handler.SetAnalysisCache(analysiscache.New(analysiscache.NewMemoryStore(), nil, analysiscache.DefaultConfig()))
+
+	first := sendChatAs(handler, "alice", "What does this song mean?")
+	second := sendChatAs(handler, "bob", "what is this song about")
+	if calls != 1 || first.Answer != "It's about letting go" || second.Answer != first.Answer {
+		t.Errorf("Expected one AI call answering both users, got %d calls and %q, %q", calls, first.Answer, second.Answer)
+	}
+	if first.AnalysisID == 0 || second.AnalysisID != first.AnalysisID {
+		t.Errorf("Expected both answers to carry the stored analysis ID, got %d and %d", first.AnalysisID, second.AnalysisID)
+	}
+
+	specific := sendChatAs(handler, "bob", "What does the chorus mean?")
+	if calls != 2 || specific.AnalysisID != 0 {
+		t.Errorf("Expected a specific question to go to the AI, got %d calls", calls)
+	}
+
+	sendChatAs(handler, "teen", "What does this song mean?")
+	if calls != 3 {
+		t.Errorf("Expected restricted users to get their own answer, got %d calls", calls)
+	}
+
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "t2", Name: "Faint", Artist: "Linkin Park", Source: "spotify"})
+	sendChatAs(handler, "alice", "What does this song mean?")
+	if calls != 4 {
+		t.Errorf("Expected another track to get its own answer, got %d calls", calls)
+	}
+}
+
+func TestLyricsHandler_AnalysisRatings_RegenerateRatedDownAnswers(t *testing.T) {
+	var prompt string
+	mockAI := &mocks.MockOllamaService{
+		AnalyzeLyricsFunc: func(query, lyrics, songInfo string) (string, error) {
+			return "A vague answer", nil
+		},
+		GenerateResponseFunc: func(p string) (string, error) {
+			prompt = p
+			return "A sharper answer", nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{
+		GetLyricsFunc: func(trackName, artistName string) (string, error) { return "lyrics", nil },
+	})
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "t1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+	handler := handlers.NewLyricsHandler(musicRepo, mockAI, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+	handler.SetAnalysisCache(analysiscache.New(analysiscache.NewMemoryStore(), nil, analysiscache.Config{TTL: time.Hour, RejectAfter: 2}))
+
+	first := sendChatAs(handler, "alice", "What does this song mean?")
+	id := strconv.FormatInt(first.AnalysisID, 10)
+	w := rateAnalysis(handler, "alice", id, false)
+	var rated models.StoredAnalysis
+	json.Unmarshal(w.Body.Bytes(), &rated)
+	if w.Code != http.StatusOK || rated.Unhelpful != 1 {
+		t.Fatalf("Expected the rating to be recorded, got %d %s", w.Code, w.Body.String())
+	}
+	rateAnalysis(handler, "bob", id, false)
+
+	second := sendChatAs(handler, "carol", "What does this song mean?")
+	if second.Answer != "A sharper answer" || second.AnalysisID == first.AnalysisID {
+		t.Fatalf("Expected a new answer once the stored one was rated down, got %+v", second)
+	}
+	if !strings.Contains(prompt, "A vague answer") {
+		t.Errorf("Expected the reanalysis prompt to include the rejected answer, got %q", prompt)
+	}
+
+	third := sendChatAs(handler, "dave", "What does this song mean?")
+	if third.AnalysisID != second.AnalysisID {
+		t.Errorf("Expected the new answer to be served next, got %+v", third)
+	}
+
+	req := httptest.NewRequest("GET", "/api/tracks/t1/analyses", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "t1"})
+	w = httptest.NewRecorder()
+	handler.GetTrackAnalyses(w, req)
+	var analyses []models.StoredAnalysis
+	json.Unmarshal(w.Body.Bytes(), &analyses)
+	if len(analyses) != 2 || analyses[0].ID != second.AnalysisID {
+		t.Errorf("Expected both answers, best rated first, got %s", w.Body.String())
+	}
+}
+
+func TestLyricsHandler_AnalysisRatings_RejectsInvalidRatings(t *testing.T) {
+	handler := handlers.NewLyricsHandler(repositories.NewMusicRepository(&mocks.MockGeniusService{}), &mocks.MockOllamaService{}, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+	if w := rateAnalysis(handler, "alice", "1", true); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without stored analyses, got %d", w.Code)
+	}
+
+	handler.SetAnalysisCache(analysiscache.New(analysiscache.NewMemoryStore(), nil, analysiscache.DefaultConfig()))
+	if w := rateAnalysis(handler, "alice", "42", true); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown analysis, got %d", w.Code)
+	}
+	if w := rateAnalysis(handler, "alice", "abc", true); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid ID, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("POST", "/api/analyses/1/rating", strings.NewReader("{}"))
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+	handler.RateAnalysis(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without helpful, got %d", w.Code)
+	}
+}
@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func sendChat(handler *handlers.LyricsHandler, query string) models.ChatResponse {
+	body, _ := json.Marshal(models.ChatRequest{Query: query})
+	req := httptest.NewRequest("POST", "/api/chat", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.HandleChat(w, req)
+
+	var resp models.ChatResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp
+}
+
+func TestLyricsHandler_ArtistRadio(t *testing.T) {
+	mockSpotify := &mocks.MockSpotifyService{
+		SearchArtistFunc: func(name string) (*models.SpotifyArtist, error) {
+			if name != "Radiohead" {
+				t.Errorf("Expected seed artist Radiohead, got %s", name)
+			}
+			return &models.SpotifyArtist{ID: "radiohead", Name: "Radiohead"}, nil
+		},
+		GetRelatedArtistsFunc: func(artistID string) ([]models.SpotifyArtist, error) {
+			return []models.SpotifyArtist{{ID: "muse", Name: "Muse"}}, nil
+		},
+		GetArtistTopTracksFunc: func(artistID string) ([]models.UnifiedTrack, error) {
+			if artistID == "muse" {
+				return []models.UnifiedTrack{
+					{ID: "m1", Name: "Hysteria", Artist: "Muse", Source: "spotify"},
+					{ID: "m2", Name: "Uprising", Artist: "Muse", Source: "spotify"},
+					{ID: "m3", Name: "Starlight", Artist: "Muse", Source: "spotify"},
+				}, nil
+			}
+			return []models.UnifiedTrack{
+				{ID: "r1", Name: "Creep", Artist: "Radiohead", Source: "spotify"},
+			}, nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	musicRepo.UpdateNowPlaying(models.SpotifyTrack{ID: "x", Name: "Time Is Running Out", Artist: "Muse"})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, &mocks.MockMoodService{}, mockSpotify)
+
+	resp := sendChat(handler, "Give me something like Radiohead?")
+
+	if resp.Type != "artist_radio" {
+		t.Fatalf("Expected type artist_radio, got %s", resp.Type)
+	}
+	if resp.Radio.SeedArtist != "Radiohead" {
+		t.Errorf("Expected seed artist Radiohead, got %s", resp.Radio.SeedArtist)
+	}
+	if len(resp.Radio.Tracks) != 3 {
+		t.Fatalf("Expected 1 seed track and 2 related tracks, got %d", len(resp.Radio.Tracks))
+	}
+
+	// Muse was played recently, so its tracks are boosted to the top
+	if resp.Radio.Tracks[0].Track.Artist != "Muse" {
+		t.Errorf("Expected recently played artist first, got %s", resp.Radio.Tracks[0].Track.Artist)
+	}
+	for _, track := range resp.Radio.Tracks {
+		if track.Reason == "" {
+			t.Errorf("Expected a reason for track %s", track.Track.Name)
+		}
+	}
+}
+
+func TestLyricsHandler_ArtistRadio_UnknownArtist(t *testing.T) {
+	mockSpotify := &mocks.MockSpotifyService{
+		SearchArtistFunc: func(name string) (*models.SpotifyArtist, error) {
+			return nil, errors.New("no artist found")
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, &mocks.MockMoodService{}, mockSpotify)
+
+	resp := sendChat(handler, "play songs similar to Qwertyuiop")
+
+	if resp.Type == "artist_radio" {
+		t.Error("Expected no radio for unknown artist")
+	}
+	if resp.Answer == "" {
+		t.Error("Expected an explanation for unknown artist")
+	}
+}
@@ -0,0 +1,173 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/cleanmode"
+	"backend/services/genius"
+	"backend/services/restricted"
+	"backend/services/spotify"
+	"backend/services/style"
+	"backend/tests/mocks"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func getArtist(handler *handlers.ArtistsHandler, userID, name string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/artists/"+url.PathEscape(name), nil)
+	req = mux.SetURLVars(req, map[string]string{"name": name})
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	handler.GetArtist(w, req)
+	return w
+}
+
+func TestArtistsHandler_CombinesGeniusAndSpotify(t *testing.T) {
+	mockGenius := &mocks.MockGeniusService{
+		GetArtistFunc: func(name string) (*models.GeniusArtist, error) {
+			return &models.GeniusArtist{ID: 7, Name: "linkin park", URL: "https://genius.com/artists/Linkin-park", ImageURL: "https://genius.com/lp.jpg", Bio: "American rock band."}, nil
+		},
+	}
+	mockSpotify := &mocks.MockSpotifyService{
+		SearchArtistFunc: func(name string) (*models.SpotifyArtist, error) {
+			return &models.SpotifyArtist{
+				ID:         "lp",
+				Name:       "Linkin Park",
+				Genres:     []string{"nu metal"},
+				Images:     []models.SpotifyImage{{URL: "https://i.scdn.co/lp.jpg", Width: 640, Height: 640}},
+				Followers:  &models.SpotifyFollowers{Total: 28000000},
+				Popularity: 86,
+			}, nil
+		},
+		GetArtistTopTracksFunc: func(artistID string) ([]models.UnifiedTrack, error) {
+			return []models.UnifiedTrack{
+				{ID: "t1", Name: "Numb", Artist: "Linkin Park"},
+				{ID: "t2", Name: "Given Up", Artist: "Linkin Park", Explicit: true},
+			}, nil
+		},
+	}
+	handler := handlers.NewArtistsHandler(mockGenius, mockSpotify)
+	handler.SetRestrictions(restricted.New(restricted.Config{Users: []string{"teen"}}))
+
+	w := getArtist(handler, "adult", "linkin park")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var info models.ArtistInfo
+	json.Unmarshal(w.Body.Bytes(), &info)
+	if info.Name != "Linkin Park" || info.Bio != "American rock band." || info.GeniusURL == "" || info.SpotifyID != "lp" {
+		t.Errorf("Expected both sources combined, got %+v", info)
+	}
+	if info.Followers != 28000000 || info.Popularity != 86 || len(info.Genres) != 1 || len(info.Images) != 1 || info.Images[0].Width != 640 {
+		t.Errorf("Expected the Spotify profile, got %+v", info)
+	}
+	if len(info.TopTracks) != 2 {
+		t.Errorf("Expected both top tracks, got %+v", info.TopTracks)
+	}
+
+	json.Unmarshal(getArtist(handler, "teen", "linkin park").Body.Bytes(), &info)
+	if len(info.TopTracks) != 1 || info.TopTracks[0].ID != "t1" {
+		t.Errorf("Expected explicit top tracks left out in restricted mode, got %+v", info.TopTracks)
+	}
+}
+
+func TestArtistsHandler_PartialAndMissing(t *testing.T) {
+	mockGenius := &mocks.MockGeniusService{
+		GetArtistFunc: func(name string) (*models.GeniusArtist, error) {
+			if name == "down" {
+				return nil, errors.New("genius API failed with status 503")
+			}
+			if name == "nobody" {
+				return nil, genius.ErrArtistNotFound
+			}
+			return &models.GeniusArtist{Name: name, URL: "https://genius.com/artists/x", ImageURL: "https://genius.com/x.jpg", Bio: "Bio"}, nil
+		},
+	}
+	mockSpotify := &mocks.MockSpotifyService{
+		SearchArtistFunc: func(name string) (*models.SpotifyArtist, error) {
+			return nil, errors.New("no artist found")
+		},
+	}
+	handler := handlers.NewArtistsHandler(mockGenius, mockSpotify)
+
+	w := getArtist(handler, "u", "Obscure Band")
+	var info models.ArtistInfo
+	json.Unmarshal(w.Body.Bytes(), &info)
+	if w.Code != http.StatusOK || info.Bio != "Bio" || info.SpotifyID != "" || len(info.Images) != 1 || info.Images[0].URL != "https://genius.com/x.jpg" {
+		t.Errorf("Expected the Genius details alone, got %d %+v", w.Code, info)
+	}
+
+	if w := getArtist(handler, "u", "nobody"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 when neither source knows the artist, got %d", w.Code)
+	}
+	if w := getArtist(handler, "u", "down"); w.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502 when the sources fail, got %d", w.Code)
+	}
+}
+
+func getArtistStyle(handler *handlers.ArtistsHandler, userID, target, name string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	req = mux.SetURLVars(req, map[string]string{"name": name})
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	handler.GetArtistStyle(w, req)
+	return w
+}
+
+func TestArtistsHandler_GetArtistStyle(t *testing.T) {
+	mockSpotify := &mocks.MockSpotifyService{
+		SearchArtistFunc: func(name string) (*models.SpotifyArtist, error) {
+			if name == "Nobody" {
+				return nil, spotify.ErrArtistNotFound
+			}
+			return &models.SpotifyArtist{ID: "lp", Name: "Linkin Park"}, nil
+		},
+		GetArtistTopTracksFunc: func(artistID string) ([]models.UnifiedTrack, error) {
+			return []models.UnifiedTrack{{ID: "t1", Name: "Numb", ReleaseDate: "2003"}}, nil
+		},
+	}
+	mockGenius := &mocks.MockGeniusService{
+		GetLyricsFunc: func(trackName, artistName string) (string, error) {
+			return "I've become so numb", nil
+		},
+	}
+	mockAI := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(prompt string) (string, error) {
+			return `{"themes": ["numbness"], "evolution": "Less shouting.", "signature_phrases": ["damn it all"], "summary": "Damn honest."}`, nil
+		},
+	}
+	handler := handlers.NewArtistsHandler(mockGenius, mockSpotify)
+
+	if w := getArtistStyle(handler, "user1", "/api/artists/Linkin%20Park/style", "Linkin Park"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without the style service, got %d", w.Code)
+	}
+	handler.SetStyle(style.New(mockSpotify, mockGenius, mockAI, nil, style.DefaultConfig()))
+	handler.SetCleanMode(cleanmode.New(cleanmode.Config{}))
+
+	w := getArtistStyle(handler, "user1", "/api/artists/Linkin%20Park/style", "Linkin Park")
+	var artistStyle models.ArtistStyle
+	json.Unmarshal(w.Body.Bytes(), &artistStyle)
+	if w.Code != http.StatusOK || artistStyle.Summary != "Damn honest." || len(artistStyle.Songs) != 1 {
+		t.Errorf("Expected the artist's style, got %d %+v", w.Code, artistStyle)
+	}
+
+	w = getArtistStyle(handler, "user1", "/api/artists/Linkin%20Park/style?clean=true", "Linkin Park")
+	json.Unmarshal(w.Body.Bytes(), &artistStyle)
+	if artistStyle.Summary != "D*** honest." || artistStyle.SignaturePhrases[0] != "d*** it all" {
+		t.Errorf("Expected the style masked in clean mode, got %+v", artistStyle)
+	}
+	json.Unmarshal(getArtistStyle(handler, "user1", "/api/artists/Linkin%20Park/style", "Linkin Park").Body.Bytes(), &artistStyle)
+	if artistStyle.Summary != "Damn honest." {
+		t.Errorf("Expected masking not to change the cached style, got %q", artistStyle.Summary)
+	}
+
+	if w := getArtistStyle(handler, "user1", "/api/artists/Nobody/style", "Nobody"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown artist, got %d", w.Code)
+	}
+}
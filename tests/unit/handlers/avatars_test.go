@@ -0,0 +1,110 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/avatars"
+	"backend/services/users"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func newAvatarsHandler(t *testing.T) *handlers.UsersHandler {
+	storage, err := avatars.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	handler := handlers.NewUsersHandler(users.New(users.NewMemoryStore()))
+	handler.SetAvatars(avatars.New(storage, avatars.Config{MaxBytes: 1024}))
+	return handler
+}
+
+func uploadAvatar(handler *handlers.UsersHandler, field string, image []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile(field, "avatar.png")
+	part.Write(image)
+	form.Close()
+
+	req := httptest.NewRequest("PUT", "/api/users/me/avatar", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("X-User-ID", "alice")
+	w := httptest.NewRecorder()
+	handler.UploadAvatar(w, req)
+	return w
+}
+
+func TestUsersHandler_UploadsAndServesAvatars(t *testing.T) {
+	handler := newAvatarsHandler(t)
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	w := uploadAvatar(handler, "avatar", png)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var user models.User
+	json.NewDecoder(w.Body).Decode(&user)
+	if !strings.HasPrefix(user.AvatarURL, "/api/avatars/") {
+		t.Fatalf("Expected an avatar URL on the profile, got %+v", user)
+	}
+
+	key := strings.TrimPrefix(user.AvatarURL, "/api/avatars/")
+	req := mux.SetURLVars(httptest.NewRequest("GET", user.AvatarURL, nil), map[string]string{"key": key})
+	w = httptest.NewRecorder()
+	handler.GetAvatar(w, req)
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), png) {
+		t.Fatalf("Expected the avatar, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Type") != "image/png" || !strings.Contains(w.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("Expected cacheable PNG headers, got %v", w.Header())
+	}
+
+	req = mux.SetURLVars(httptest.NewRequest("GET", user.AvatarURL, nil), map[string]string{"key": key})
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	handler.GetAvatar(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for a matching ETag, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("DELETE", "/api/users/me/avatar", nil)
+	req.Header.Set("X-User-ID", "alice")
+	w = httptest.NewRecorder()
+	handler.DeleteAvatar(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", w.Code)
+	}
+	req = mux.SetURLVars(httptest.NewRequest("GET", user.AvatarURL, nil), map[string]string{"key": key})
+	w = httptest.NewRecorder()
+	handler.GetAvatar(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected the deleted avatar to be gone, got %d", w.Code)
+	}
+}
+
+func TestUsersHandler_RejectsInvalidAvatars(t *testing.T) {
+	handler := newAvatarsHandler(t)
+
+	if w := uploadAvatar(handler, "picture", []byte("\x89PNG\r\n\x1a\n")); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without an avatar file, got %d", w.Code)
+	}
+	if w := uploadAvatar(handler, "avatar", []byte("GIF89a"+strings.Repeat("x", 2000))); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a large avatar, got %d", w.Code)
+	}
+	if w := uploadAvatar(handler, "avatar", []byte("<html><script>alert(1)</script></html>")); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415 for HTML, got %d", w.Code)
+	}
+
+	// Without avatar storage the endpoints don't exist
+	disabled := handlers.NewUsersHandler(users.New(users.NewMemoryStore()))
+	if w := uploadAvatar(disabled, "avatar", []byte("\x89PNG\r\n\x1a\n")); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without avatar storage, got %d", w.Code)
+	}
+}
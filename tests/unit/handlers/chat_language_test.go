@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postChat(handler *handlers.LyricsHandler, chatReq models.ChatRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(chatReq)
+	req := httptest.NewRequest("POST", "/api/chat", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.HandleChat(w, req)
+	return w
+}
+
+func TestLyricsHandler_HandleChat_AnswersInQueryLanguage(t *testing.T) {
+	handler := createTestHandler()
+
+	w := postChat(handler, models.ChatRequest{Query: "¿De qué trata esta canción?"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response models.ChatResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Language != "es" {
+		t.Errorf("Expected Spanish to be detected, got %q", response.Language)
+	}
+	if !strings.HasPrefix(response.Answer, "No se está reproduciendo") {
+		t.Errorf("Expected the Spanish no song message, got %s", response.Answer)
+	}
+}
+
+func TestLyricsHandler_HandleChat_LangOverridesDetection(t *testing.T) {
+	var prompt string
+	mockOllama := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(p string) (string, error) {
+			prompt = p
+			return "Le jazz est né à La Nouvelle-Orléans.", nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, mockOllama, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+
+	w := postChat(handler, models.ChatRequest{Query: "What is jazz music?", Lang: "fr-CA"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response models.ChatResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Language != "fr" {
+		t.Errorf("Expected lang to be normalized to fr, got %q", response.Language)
+	}
+	if !strings.Contains(prompt, "Answer in French.") {
+		t.Errorf("Expected the prompt to ask for French, got %q", prompt)
+	}
+}
+
+func TestLyricsHandler_HandleChat_EnglishPromptUnchanged(t *testing.T) {
+	var prompt string
+	mockOllama := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(p string) (string, error) {
+			prompt = p
+			return "Jazz started in New Orleans.", nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, mockOllama, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+
+	postChat(handler, models.ChatRequest{Query: "What is jazz music?"})
+	if strings.Contains(prompt, "Answer in") {
+		t.Errorf("Expected no language instruction for English, got %q", prompt)
+	}
+}
+
+func TestLyricsHandler_HandleChat_UnsupportedLang(t *testing.T) {
+	handler := createTestHandler()
+
+	w := postChat(handler, models.ChatRequest{Query: "What is jazz music?", Lang: "klingon"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestLyricsHandler_HandleChatStream_ContentLanguage(t *testing.T) {
+	handler := createTestHandler()
+
+	body, _ := json.Marshal(models.ChatRequest{Query: "Was ist das für Musik?"})
+	req := httptest.NewRequest("POST", "/api/chat/stream", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleChatStream(w, req)
+
+	if lang := w.Header().Get("Content-Language"); lang != "de" {
+		t.Errorf("Expected Content-Language de, got %q", lang)
+	}
+}
@@ -0,0 +1,154 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/services/restricted"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	_ "github.com/lib/pq"
+)
+
+func postMessageAs(handler *handlers.ChatHandler, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/messages", strings.NewReader(body))
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	handler.PostMessage(w, req)
+	return w
+}
+
+func TestChatHandler_PostMessage_RejectsInvalidTrackCards(t *testing.T) {
+	// Invalid messages are rejected before they reach the database
+	handler := handlers.NewChatHandler(nil)
+
+	cases := map[string]string{
+		"missing payload":   `{"username": "a", "message_type": "track"}`,
+		"incomplete track":  `{"username": "a", "message_type": "track", "payload": {"id": "t1", "name": "Numb", "source": "spotify"}}`,
+		"unknown source":    `{"username": "a", "message_type": "track", "payload": {"id": "t1", "name": "Numb", "artist": "Linkin Park", "source": "tidal"}}`,
+		"payload on text":   `{"username": "a", "text": "hi", "payload": {"id": "t1"}}`,
+		"unknown type":      `{"username": "a", "message_type": "poll"}`,
+		"payload not track": `{"username": "a", "message_type": "track", "payload": "Numb"}`,
+	}
+	for name, body := range cases {
+		if w := postMessageAs(handler, "alice", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestChatHandler_PostMessage_RestrictedUsersCantShareExplicitTracks(t *testing.T) {
+	handler := handlers.NewChatHandler(nil)
+	handler.SetRestrictions(restricted.New(restricted.Config{Users: []string{"teen"}}))
+
+	w := postMessageAs(handler, "teen", `{"username": "t", "message_type": "track", "payload": {"id": "e1", "name": "Explicit Hit", "artist": "Eminem", "source": "spotify", "explicit": true}}`)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestChatHandler_Reactions_RejectInvalidRequests(t *testing.T) {
+	// Invalid reactions are rejected before they reach the database
+	handler := handlers.NewChatHandler(nil)
+
+	react := func(id, body string) int {
+		req := httptest.NewRequest("POST", "/api/messages/"+id+"/reactions", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		handler.AddReaction(w, req)
+		return w.Code
+	}
+	if code := react("abc", `{"emoji": "🔥"}`); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid message ID, got %d", code)
+	}
+	for _, emoji := range []string{"", "  ", "lol", "🔥 fire", "!", strings.Repeat("🔥", 10)} {
+		if code := react("1", `{"emoji": "`+emoji+`"}`); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for emoji %q, got %d", emoji, code)
+		}
+	}
+
+	req := httptest.NewRequest("DELETE", "/api/messages/1/reactions", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+	handler.RemoveReaction(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without an emoji, got %d", w.Code)
+	}
+}
+
+func TestChatHandler_GetThread_RejectsInvalidIDs(t *testing.T) {
+	handler := handlers.NewChatHandler(nil)
+
+	for _, id := range []string{"abc", "0", "-3"} {
+		req := httptest.NewRequest("GET", "/api/messages/"+id+"/thread", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		handler.GetThread(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for message ID %q, got %d", id, w.Code)
+		}
+	}
+}
+
+func TestChatHandler_Block_RejectsInvalidRequests(t *testing.T) {
+	// Invalid blocks are rejected before they reach the database
+	handler := handlers.NewChatHandler(nil)
+
+	block := func(body string) int {
+		req := httptest.NewRequest("POST", "/api/blocks", strings.NewReader(body))
+		req.Header.Set("X-User-ID", "Alice@example.com")
+		w := httptest.NewRecorder()
+		handler.Block(w, req)
+		return w.Code
+	}
+	cases := map[string]string{
+		"invalid body":  `{`,
+		"missing user":  `{"kind": "mute"}`,
+		"blank user":    `{"user_email": "  "}`,
+		"unknown kind":  `{"user_email": "bob@example.com", "kind": "ignore"}`,
+		"too long":      `{"user_email": "` + strings.Repeat("b", 256) + `"}`,
+		"blocking self": `{"user_email": "alice@example.com"}`,
+	}
+	for name, body := range cases {
+		if code := block(body); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, code)
+		}
+	}
+
+	req := httptest.NewRequest("DELETE", "/api/blocks/%20", nil)
+	req = mux.SetURLVars(req, map[string]string{"user": " "})
+	w := httptest.NewRecorder()
+	handler.Unblock(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a blank user, got %d", w.Code)
+	}
+}
+
+func TestChatHandler_GetMessages_RejectsInvalidPages(t *testing.T) {
+	// Invalid pages are rejected before they reach the database
+	handler := handlers.NewChatHandler(nil)
+
+	for _, query := range []string{"limit=0", "limit=many", "cursor=bogus", "limit=10&cursor=WyJ4Il0"} {
+		w := httptest.NewRecorder()
+		handler.GetMessages(w, httptest.NewRequest("GET", "/api/messages?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestChatHandler_Prepare_ReportsFailures(t *testing.T) {
+	// Nothing listens on port 1, so preparing the first statement fails
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 user=postgres dbname=linkinsync sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatalf("Expected no error opening the database, got %v", err)
+	}
+	defer db.Close()
+
+	if err := handlers.NewChatHandler(db).Prepare(); err == nil || !strings.Contains(err.Error(), "preparing") {
+		t.Errorf("Expected the failed statement to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package handlers_test
+
+import (
+	"backend/server/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLyricsHandler_HandleChatStream_GeneralQuery(t *testing.T) {
+	handler := createTestHandler()
+
+	body, _ := json.Marshal(models.ChatRequest{Query: "What is jazz music?"})
+	req := httptest.NewRequest("POST", "/api/chat/stream", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleChatStream(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte("What is jazz music?")) {
+		t.Errorf("Expected streamed mock response to contain the query, got %s", w.Body.String())
+	}
+	if !w.Flushed {
+		t.Error("Expected response to be flushed while streaming")
+	}
+}
+
+func TestLyricsHandler_HandleChatStream_NonMusic(t *testing.T) {
+	handler := createTestHandler()
+
+	body, _ := json.Marshal(models.ChatRequest{Query: "How do I cook pasta?"})
+	req := httptest.NewRequest("POST", "/api/chat/stream", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleChatStream(w, req)
+
+	expected := "I can only help with questions about music, songs, lyrics, and artists. Please ask me something related to music!"
+	if w.Body.String() != expected {
+		t.Errorf("Expected restriction message, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,126 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/cleanmode"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func newCleanModeHandler(cleanMode cleanmode.Service, analyze func(query, lyrics, songInfo string) (string, error)) *handlers.LyricsHandler {
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{
+		GetLyricsFunc: func(trackName, artistName string) (string, error) { return "Damn, what a day", nil },
+	})
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "t1", Name: "Song", Artist: "Artist", Source: "spotify"})
+	musicRepo.GetLyricsForCurrentSong()
+
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{AnalyzeLyricsFunc: analyze}, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+	handler.SetCleanMode(cleanMode)
+	return handler
+}
+
+func getNowPlayingLyrics(t *testing.T, handler *handlers.LyricsHandler, userID, target string) (string, string) {
+	req := httptest.NewRequest("GET", target, nil)
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	handler.GetNowPlaying(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var nowPlaying models.NowPlaying
+	json.Unmarshal(w.Body.Bytes(), &nowPlaying)
+	return nowPlaying.Lyrics, w.Header().Get("ETag")
+}
+
+func TestLyricsHandler_CleanMode_MasksNowPlayingLyrics(t *testing.T) {
+	handler := newCleanModeHandler(cleanmode.New(cleanmode.Config{Users: []string{"kid"}}), nil)
+
+	lyrics, etag := getNowPlayingLyrics(t, handler, "adult", "/api/now-playing")
+	if lyrics != "Damn, what a day" {
+		t.Errorf("Expected the lyrics as they are, got %q", lyrics)
+	}
+
+	masked, maskedETag := getNowPlayingLyrics(t, handler, "adult", "/api/now-playing?clean=true")
+	if masked != "D***, what a day" {
+		t.Errorf("Expected masked lyrics for a clean request, got %q", masked)
+	}
+	if maskedETag == etag {
+		t.Error("Expected masked lyrics to have their own ETag")
+	}
+
+	if masked, _ := getNowPlayingLyrics(t, handler, "kid", "/api/now-playing"); masked != "D***, what a day" {
+		t.Errorf("Expected masked lyrics for a user in clean mode, got %q", masked)
+	}
+}
+
+func TestLyricsHandler_CleanMode_ChatRequest(t *testing.T) {
+	var gotQuery, gotLyrics string
+	handler := newCleanModeHandler(cleanmode.New(cleanmode.Config{}), func(query, lyrics, songInfo string) (string, error) {
+		gotQuery, gotLyrics = query, lyrics
+		return "It opens with a damn", nil
+	})
+
+	body, _ := json.Marshal(models.ChatRequest{Query: "what do the lyrics mean?", Clean: true})
+	w := httptest.NewRecorder()
+	handler.HandleChat(w, httptest.NewRequest("POST", "/api/chat", bytes.NewBuffer(body)))
+
+	var resp models.ChatResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if !strings.HasPrefix(gotQuery, cleanmode.SystemPrompt) || gotLyrics != "D***, what a day" {
+		t.Errorf("Expected a family-friendly prompt with masked lyrics, got %q and %q", gotQuery, gotLyrics)
+	}
+	if resp.Answer != "It opens with a d***" {
+		t.Errorf("Expected profanity masked in the answer, got %q", resp.Answer)
+	}
+
+	// Without the flag, the same user gets the lyrics as they are
+	sendChatAs(handler, "default_user", "what do the lyrics mean?")
+	if strings.HasPrefix(gotQuery, cleanmode.SystemPrompt) || gotLyrics != "Damn, what a day" {
+		t.Errorf("Expected clean mode to apply to the flagged request only, got %q and %q", gotQuery, gotLyrics)
+	}
+}
+
+func TestCleanModeHandler_SetCleanMode(t *testing.T) {
+	cleanMode := cleanmode.New(cleanmode.Config{})
+	handler := handlers.NewCleanModeHandler(cleanMode)
+
+	req := httptest.NewRequest("PUT", "/api/users/sam/clean-mode", strings.NewReader(`{"clean": true}`))
+	req.Header.Set("X-User-ID", "sam")
+	req = mux.SetURLVars(req, map[string]string{"userID": "sam"})
+	w := httptest.NewRecorder()
+	handler.SetCleanMode(w, req)
+
+	var status models.CleanModeStatus
+	json.Unmarshal(w.Body.Bytes(), &status)
+	if w.Code != http.StatusOK || !status.Clean || !cleanMode.IsClean("sam") {
+		t.Errorf("Expected clean mode turned on, got %d %+v", w.Code, status)
+	}
+
+	req = httptest.NewRequest("PUT", "/api/users/sam/clean-mode", strings.NewReader(`not json`))
+	req.Header.Set("X-User-ID", "sam")
+	req = mux.SetURLVars(req, map[string]string{"userID": "sam"})
+	w = httptest.NewRecorder()
+	handler.SetCleanMode(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid body, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("PUT", "/api/users/sam/clean-mode", strings.NewReader(`{"clean": false}`))
+	req.Header.Set("X-User-ID", "mallory")
+	req = mux.SetURLVars(req, map[string]string{"userID": "sam"})
+	w = httptest.NewRecorder()
+	handler.SetCleanMode(w, req)
+	if w.Code != http.StatusForbidden || !cleanMode.IsClean("sam") {
+		t.Errorf("Expected 403 for another user's clean mode, got %d", w.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/comparison"
+	"backend/services/genius"
+	"backend/services/mood"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func compareSongs(handler *handlers.LyricsHandler, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.CompareSongs(w, httptest.NewRequest("POST", "/api/analyze/compare", bytes.NewBufferString(body)))
+	return w
+}
+
+func TestLyricsHandler_CompareSongs(t *testing.T) {
+	var prompt string
+	mockMood := &mocks.MockMoodService{
+		GetLyricsWithMoodFunc: func(trackName, artistName string) (*mood.LyricsWithMood, error) {
+			if trackName == "Unknown" {
+				return nil, fmt.Errorf("failed to fetch lyrics: %w", genius.ErrSongNotFound)
+			}
+			return &mood.LyricsWithMood{Lyrics: "Damn it all", MoodAnalysis: &models.MoodAnalysis{PrimaryMood: "angry"}}, nil
+		},
+	}
+	mockAI := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(p string) (string, error) {
+			prompt = p
+			return `{"shared_themes": ["anger"], "mood": "Both songs are angry, damn.", "era": "Both from the 2000s.", "summary": "Close cousins."}`, nil
+		},
+	}
+	handler := handlers.NewLyricsHandler(repositories.NewMusicRepository(&mocks.MockGeniusService{}), mockAI, mockMood, &mocks.MockSpotifyService{})
+
+	if w := compareSongs(handler, `{}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without the comparison service, got %d", w.Code)
+	}
+	handler.SetComparison(comparison.New(mockMood, nil))
+
+	if w := compareSongs(handler, `{"first": {"name": "Numb", "artist": "Linkin Park"}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a second song, got %d", w.Code)
+	}
+
+	w := compareSongs(handler, `{"first": {"name": "Numb", "artist": "Linkin Park"}, "second": {"name": "Faint", "artist": "Linkin Park"}, "lang": "es"}`)
+	var result models.SongComparison
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if w.Code != http.StatusOK || result.Summary != "Close cousins." || result.Language != "es" || result.First.MoodAnalysis.PrimaryMood != "angry" {
+		t.Errorf("Expected the comparison, got %d %+v", w.Code, result)
+	}
+	if !strings.Contains(prompt, "Damn it all") || !strings.Contains(prompt, "Spanish") {
+		t.Errorf("Expected the lyrics and a language instruction in the prompt, got %q", prompt)
+	}
+
+	w = compareSongs(handler, `{"first": {"name": "Numb", "artist": "Linkin Park"}, "second": {"name": "Faint", "artist": "Linkin Park"}, "clean": true}`)
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if strings.Contains(prompt, "Damn it") || result.Mood != "Both songs are angry, d***." {
+		t.Errorf("Expected the lyrics and answer masked in clean mode, got %q and %q", prompt, result.Mood)
+	}
+
+	w = compareSongs(handler, `{"first": {"name": "Numb", "artist": "Linkin Park"}, "second": {"name": "Unknown", "artist": "Nobody"}}`)
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "Unknown by Nobody") {
+		t.Errorf("Expected 404 naming the missing song, got %d %s", w.Code, w.Body.String())
+	}
+}
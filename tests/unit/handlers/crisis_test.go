@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/events"
+	"backend/services/safety"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newCrisisHandler(moodService *mocks.MockMoodService) *handlers.LyricsHandler {
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, moodService, &mocks.MockSpotifyService{})
+	handler.SetSafety(safety.New(safety.Config{
+		Helplines: []models.Helpline{
+			{Region: "GB", Name: "Samaritans", Phone: "116 123", URL: "https://www.samaritans.org"},
+			{Region: "*", Name: "Find A Helpline", URL: "https://findahelpline.com"},
+		},
+	}))
+	return handler
+}
+
+func sendChatRequest(handler *handlers.LyricsHandler, chatReq models.ChatRequest) models.ChatResponse {
+	body, _ := json.Marshal(chatReq)
+	w := httptest.NewRecorder()
+	handler.HandleChat(w, httptest.NewRequest("POST", "/api/chat", bytes.NewBuffer(body)))
+
+	var resp models.ChatResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp
+}
+
+func TestLyricsHandler_CrisisSupport(t *testing.T) {
+	var savedMood string
+	moodService := &mocks.MockMoodService{
+		SaveUserMoodHistoryFunc: func(userID string, mood string, playedSongs []string) error {
+			savedMood = mood
+			return nil
+		},
+	}
+	handler := newCrisisHandler(moodService)
+	bus := events.New(events.DefaultConfig())
+	handler.SetEventBus(bus)
+
+	var mutex sync.Mutex
+	var received []events.Event
+	record := func(event events.Event) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, event)
+		return nil
+	}
+	bus.Subscribe(events.MoodDetected, "test", record)
+	bus.Subscribe(events.RecommendationServed, "test", record)
+
+	resp := sendChatRequest(handler, models.ChatRequest{Query: "I feel like I want to die", Region: "gb"})
+	bus.Close()
+
+	if resp.Type != "crisis_support" {
+		t.Fatalf("Expected crisis support, got %+v", resp)
+	}
+	if len(resp.Resources) != 2 || resp.Resources[0].Name != "Samaritans" {
+		t.Errorf("Expected the UK and international helplines, got %+v", resp.Resources)
+	}
+	if !strings.Contains(resp.Answer, "Samaritans: 116 123, https://www.samaritans.org") {
+		t.Errorf("Expected the helplines in the answer, got %q", resp.Answer)
+	}
+	if resp.MoodAnalysis != nil {
+		t.Errorf("Expected the mood not to be returned, got %+v", resp.MoodAnalysis)
+	}
+	if savedMood == "" {
+		t.Error("Expected the mood to be saved to the user's history")
+	}
+	if len(received) != 0 {
+		t.Errorf("Expected no events to be published, got %d", len(received))
+	}
+}
+
+func TestLyricsHandler_CrisisFlaggedByMoodDetection(t *testing.T) {
+	moodService := &mocks.MockMoodService{
+		DetectMoodFunc: func(message string) (*models.MoodAnalysis, error) {
+			return &models.MoodAnalysis{PrimaryMood: "sad", MoodScore: 0.95, EmotionTags: []string{}, Crisis: true}, nil
+		},
+	}
+	handler := newCrisisHandler(moodService)
+
+	resp := sendChatRequest(handler, models.ChatRequest{Query: "I feel like nobody would notice if I disappeared", Lang: "es"})
+
+	if resp.Type != "crisis_support" || resp.Language != "es" {
+		t.Fatalf("Expected crisis support in Spanish, got %+v", resp)
+	}
+	if len(resp.Resources) != 1 || resp.Resources[0].Region != "*" {
+		t.Errorf("Expected only the international helpline without a region, got %+v", resp.Resources)
+	}
+	if !strings.HasPrefix(resp.Answer, "Siento mucho") {
+		t.Errorf("Expected the Spanish support message, got %q", resp.Answer)
+	}
+}
@@ -0,0 +1,45 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/breaker"
+	"backend/tests/mocks"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestLyricsHandler_CustomResponses(t *testing.T) {
+	moodService := &mocks.MockMoodService{
+		DetectMoodFunc: func(message string) (*models.MoodAnalysis, error) {
+			return nil, fmt.Errorf("failed to detect mood: ai: %w", breaker.ErrOpen)
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, moodService, &mocks.MockSpotifyService{})
+	handler.SetCustomization(handlers.Customization{
+		AssistantName: "Hybrid Bot",
+		Responses: map[string]string{
+			"music_only":       "{assistant} only talks music, friend.",
+			"empathetic_sad":   "Feeling {mood}? {assistant} has you covered:",
+			"no_song_playing":  "Put something on first!",
+			"empathetic_happy": "Unused",
+		},
+	})
+
+	if resp := sendChat(handler, "How do I cook pasta?"); resp.Answer != "Hybrid Bot only talks music, friend." {
+		t.Errorf("Expected the custom refusal, got %q", resp.Answer)
+	}
+	if resp := sendChat(handler, "I feel so sad and alone, I just want to cry"); resp.Answer != "Feeling sad? Hybrid Bot has you covered:" {
+		t.Errorf("Expected the custom empathetic response, got %q", resp.Answer)
+	}
+
+	// Custom answers are used whatever the query's language
+	var resp models.ChatResponse
+	json.Unmarshal(postChat(handler, models.ChatRequest{Query: "tell me about this song", Lang: "es"}).Body.Bytes(), &resp)
+	if resp.Answer != "Put something on first!" {
+		t.Errorf("Expected the custom no song answer, got %q", resp.Answer)
+	}
+}
@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/erasure"
+	"backend/services/jobqueue"
+	"backend/services/users"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUsersHandler_DeleteMyData(t *testing.T) {
+	usersService := users.New(users.NewMemoryStore())
+	handler := handlers.NewUsersHandler(usersService)
+
+	deleteData := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("DELETE", "/api/users/me/data", nil)
+		req.Header.Set("X-User-ID", "alice@example.com")
+		w := httptest.NewRecorder()
+		handler.DeleteMyData(w, req)
+		return w
+	}
+	if w := deleteData(); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without data deletion, got %d", w.Code)
+	}
+
+	queue := jobqueue.New(jobqueue.NewMemoryStore(), jobqueue.DefaultConfig())
+	queue.Handle(models.JobTypeDataDeletion, handler.DataDeletionJob)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue.Start(ctx)
+	handler.SetJobQueue(queue)
+	handler.SetDataDeletion(erasure.New(nil, usersService, nil, erasure.Config{DataDir: t.TempDir()}))
+
+	w := httptest.NewRecorder()
+	handler.DeleteMyData(w, httptest.NewRequest("DELETE", "/api/users/me/data", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without X-User-ID, got %d", w.Code)
+	}
+
+	if _, err := usersService.Get("alice@example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	w = deleteData()
+	var job models.QueuedJob
+	json.Unmarshal(w.Body.Bytes(), &job)
+	if w.Code != http.StatusAccepted || job.Type != models.JobTypeDataDeletion || job.UserID != "alice@example.com" {
+		t.Fatalf("Expected 202 with the queued job, got %d: %s", w.Code, w.Body.String())
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for job.Status != models.JobSucceeded && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		job, _ = queue.Get(job.ID)
+	}
+	var receipt models.DataDeletionReceipt
+	json.Unmarshal(job.Result, &receipt)
+	if job.Status != models.JobSucceeded || receipt.UserID != "alice@example.com" || receipt.Deleted["profile"] != 1 {
+		t.Fatalf("Expected a receipt for the deleted profile, got %+v: %s", job, job.Result)
+	}
+	if _, err := usersService.Delete("alice@example.com"); !errors.Is(err, users.ErrNotFound) {
+		t.Errorf("Expected the profile to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/export"
+	"backend/services/jobqueue"
+	"backend/services/users"
+	"backend/tests/mocks"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestUsersHandler_ExportMyData(t *testing.T) {
+	usersService := users.New(users.NewMemoryStore())
+	handler := handlers.NewUsersHandler(usersService)
+
+	exportData := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/users/me/export", nil)
+		if userID != "" {
+			req.Header.Set("X-User-ID", userID)
+		}
+		w := httptest.NewRecorder()
+		handler.ExportMyData(w, req)
+		return w
+	}
+	download := func(token string) *httptest.ResponseRecorder {
+		req := mux.SetURLVars(httptest.NewRequest("GET", "/api/exports/"+token, nil), map[string]string{"token": token})
+		w := httptest.NewRecorder()
+		handler.DownloadExport(w, req)
+		return w
+	}
+	if w := exportData("alice"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without data export, got %d", w.Code)
+	}
+
+	queue := jobqueue.New(jobqueue.NewMemoryStore(), jobqueue.DefaultConfig())
+	queue.Handle(models.JobTypeDataExport, handler.DataExportJob)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue.Start(ctx)
+	config := export.DefaultConfig()
+	config.Dir = t.TempDir()
+	handler.SetJobQueue(queue)
+	handler.SetDataExport(export.New(nil, usersService, &mocks.MockMoodService{}, nil, config))
+
+	if w := exportData(""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without X-User-ID, got %d", w.Code)
+	}
+	w := exportData("alice")
+	var queued models.DataExportQueued
+	json.Unmarshal(w.Body.Bytes(), &queued)
+	if w.Code != http.StatusAccepted || queued.Type != models.JobTypeDataExport || !strings.HasPrefix(queued.DownloadURL, "/api/exports/") {
+		t.Fatalf("Expected 202 with the queued job and a download URL, got %d: %s", w.Code, w.Body.String())
+	}
+	token := strings.TrimPrefix(queued.DownloadURL, "/api/exports/")
+	if strings.Contains(string(queued.Payload), token) {
+		t.Errorf("Expected the job payload not to contain the token, got %s", queued.Payload)
+	}
+
+	job := queued.QueuedJob
+
+	deadline := time.Now().Add(2 * time.Second)
+	for job.Status != models.JobSucceeded && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		job, _ = queue.Get(job.ID)
+	}
+	var bundle models.DataExport
+	json.Unmarshal(job.Result, &bundle)
+	if job.Status != models.JobSucceeded || len(bundle.Files) != 5 {
+		t.Fatalf("Expected the bundle's details, got %+v: %s", job, job.Result)
+	}
+	if strings.Contains(string(job.Result), token) {
+		t.Errorf("Expected the job result not to contain the token, got %s", job.Result)
+	}
+
+	w = download(token)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/zip" || !strings.HasPrefix(w.Body.String(), "PK") {
+		t.Errorf("Expected the zip, got %d with %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if w := download("0123456789abcdef0123456789abcdef"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown token, got %d", w.Code)
+	}
+}
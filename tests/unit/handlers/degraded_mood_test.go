@@ -0,0 +1,39 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/breaker"
+	"backend/tests/mocks"
+	"fmt"
+	"testing"
+)
+
+func TestLyricsHandler_MoodQueryWhileAIUnavailable(t *testing.T) {
+	moodService := &mocks.MockMoodService{
+		DetectMoodFunc: func(message string) (*models.MoodAnalysis, error) {
+			return nil, fmt.Errorf("failed to detect mood: ai: %w", breaker.ErrOpen)
+		},
+	}
+	aiService := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			t.Error("Expected the AI provider not to be called while unavailable")
+			return "", nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, aiService, moodService, &mocks.MockSpotifyService{})
+
+	resp := sendChat(handler, "I feel so sad and alone, I just want to cry")
+
+	if resp.Type != "mood_recommendation" || resp.MoodAnalysis == nil {
+		t.Fatalf("Expected a canned mood recommendation, got %+v", resp)
+	}
+	if resp.MoodAnalysis.PrimaryMood != "sad" {
+		t.Errorf("Expected mood sad from keywords, got %s", resp.MoodAnalysis.PrimaryMood)
+	}
+	if len(resp.Recommendations.Suggested) == 0 {
+		t.Error("Expected curated suggestions for the mood")
+	}
+}
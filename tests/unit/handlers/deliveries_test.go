@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/streaming"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// failingPublisher rejects every publish
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(topic string, data []byte) error {
+	return errors.New("broker unavailable")
+}
+
+func (failingPublisher) Close() error {
+	return nil
+}
+
+func deliveriesRouter(deliveryLog streaming.DeliveryLog) *mux.Router {
+	handler := handlers.NewDeliveriesHandler(deliveryLog)
+	router := mux.NewRouter()
+	router.HandleFunc("/api/admin/deliveries", handler.ListDeliveries).Methods("GET")
+	router.HandleFunc("/api/admin/deliveries/{id}", handler.GetDelivery).Methods("GET")
+	router.HandleFunc("/api/admin/deliveries/{id}/redeliver", handler.Redeliver).Methods("POST")
+	return router
+}
+
+func TestDeliveries_ListAndRedeliver(t *testing.T) {
+	deliveryLog := streaming.NewDeliveryLog(failingPublisher{}, streaming.DeliveryLogConfig{Backend: "nats", Size: 10, MaxAttempts: 1})
+	deliveryLog.Publish("linkinsync.track_changed", []byte(`{"type":"track_changed"}`))
+	router := deliveriesRouter(deliveryLog)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/admin/deliveries?status=failed", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rr.Code)
+	}
+	var response models.EventDeliveriesResponse
+	json.NewDecoder(rr.Body).Decode(&response)
+	if len(response.Deliveries) != 1 || response.Failed != 1 || response.Deliveries[0].Error != "broker unavailable" {
+		t.Fatalf("Expected one failed delivery, got %+v", response)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/admin/deliveries/1/redeliver", nil))
+	if rr.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502 when redelivery fails, got %d", rr.Code)
+	}
+	var delivery models.EventDelivery
+	json.NewDecoder(rr.Body).Decode(&delivery)
+	if delivery.Attempts != 2 {
+		t.Errorf("Expected the redelivery to count as an attempt, got %d", delivery.Attempts)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/admin/deliveries/42/redeliver", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown delivery, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/admin/deliveries?status=pending", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown status, got %d", rr.Code)
+	}
+}
+
+func TestDeliveries_NoEventStream(t *testing.T) {
+	rr := httptest.NewRecorder()
+	deliveriesRouter(nil).ServeHTTP(rr, httptest.NewRequest("GET", "/api/admin/deliveries", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without an event stream, got %d", rr.Code)
+	}
+}
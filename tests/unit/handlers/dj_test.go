@@ -0,0 +1,165 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/spotify"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postDJ(handler *handlers.LyricsHandler, req models.DJRequest, spotifyToken string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest("POST", "/api/dj", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	if spotifyToken != "" {
+		httpReq.Header.Set("X-Spotify-Token", spotifyToken)
+	}
+	w := httptest.NewRecorder()
+	handler.DJ(w, httpReq)
+	return w
+}
+
+// djSuggestions returns an AI reply suggesting count tracks
+func djSuggestions(count int) string {
+	var tracks []string
+	for i := 0; i < count; i++ {
+		tracks = append(tracks, fmt.Sprintf(`{"name": "Song %d", "artist": "Artist %d"}`, i, i%4))
+	}
+	return `{"tracks": [` + strings.Join(tracks, ", ") + `]}`
+}
+
+func TestLyricsHandler_DJ(t *testing.T) {
+	var prompt string
+	mockAI := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(p string) (string, error) {
+			prompt = p
+			return djSuggestions(30), nil
+		},
+	}
+	mockSpotify := &mocks.MockSpotifyService{
+		SearchTrackFunc: func(name, artist string) (*models.UnifiedTrack, error) {
+			if name == "Song 1" {
+				return nil, spotify.ErrTrackNotFound
+			}
+			return &models.UnifiedTrack{ID: "id-" + name, Name: name, Artist: artist, Source: "spotify"}, nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, mockAI, &mocks.MockMoodService{}, mockSpotify)
+
+	w := postDJ(handler, models.DJRequest{Vibe: "late night coding"}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if !strings.Contains(prompt, "late night coding") {
+		t.Errorf("Expected the vibe in the prompt, got %q", prompt)
+	}
+
+	var resp models.DJResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(resp.Tracks) != 20 {
+		t.Fatalf("Expected 20 tracks, got %d", len(resp.Tracks))
+	}
+	// The AI's order is kept, skipping tracks Spotify doesn't know
+	if resp.Tracks[0].Name != "Song 0" || resp.Tracks[1].Name != "Song 2" {
+		t.Errorf("Expected the suggested order, got %s, %s", resp.Tracks[0].Name, resp.Tracks[1].Name)
+	}
+	if resp.Queued != 0 {
+		t.Errorf("Expected nothing pushed to Spotify, got %d", resp.Queued)
+	}
+}
+
+func TestLyricsHandler_DJ_TopsUpWithArtistHits(t *testing.T) {
+	mockAI := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(prompt string) (string, error) {
+			return djSuggestions(4), nil
+		},
+	}
+	mockSpotify := &mocks.MockSpotifyService{
+		GetArtistTopTracksFunc: func(artistID string) ([]models.UnifiedTrack, error) {
+			return []models.UnifiedTrack{
+				{ID: artistID + "-hit1", Name: "Hit 1", Source: "spotify"},
+				{ID: artistID + "-hit2", Name: "Hit 2", Source: "spotify"},
+				{ID: artistID + "-hit3", Name: "Hit 3", Source: "spotify"},
+			}, nil
+		},
+		SearchArtistFunc: func(name string) (*models.SpotifyArtist, error) {
+			return &models.SpotifyArtist{ID: name, Name: name}, nil
+		},
+		SearchTrackFunc: func(name, artist string) (*models.UnifiedTrack, error) {
+			return &models.UnifiedTrack{ID: "id-" + name, Name: name, Artist: artist, Source: "spotify"}, nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, mockAI, &mocks.MockMoodService{}, mockSpotify)
+
+	w := postDJ(handler, models.DJRequest{Vibe: "road trip"}, "")
+	var resp models.DJResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	// 4 suggestions plus 2 hits from each of the 4 artists
+	if w.Code != http.StatusOK || len(resp.Tracks) != 12 {
+		t.Fatalf("Expected 12 tracks, got %d (status %d)", len(resp.Tracks), w.Code)
+	}
+	if resp.Tracks[4].ID != "Artist 0-hit1" {
+		t.Errorf("Expected the first artist's hits after the suggestions, got %s", resp.Tracks[4].ID)
+	}
+}
+
+func TestLyricsHandler_DJ_PushToSpotify(t *testing.T) {
+	mockAI := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(prompt string) (string, error) {
+			return djSuggestions(3), nil
+		},
+	}
+	var queued []string
+	mockSpotify := &mocks.MockSpotifyService{
+		SearchTrackFunc: func(name, artist string) (*models.UnifiedTrack, error) {
+			return &models.UnifiedTrack{ID: "id-" + name, Name: name, Artist: artist, Source: "spotify"}, nil
+		},
+		AddToQueueFunc: func(userToken, trackID string) error {
+			if userToken != "user-token" {
+				t.Errorf("Expected the user's token, got %q", userToken)
+			}
+			if len(queued) == 2 {
+				return spotify.ErrUserTokenRejected
+			}
+			queued = append(queued, trackID)
+			return nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, mockAI, &mocks.MockMoodService{}, mockSpotify)
+
+	if w := postDJ(handler, models.DJRequest{Vibe: "focus", PushToSpotify: true}, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without a Spotify token, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w := postDJ(handler, models.DJRequest{Vibe: "focus", PushToSpotify: true}, "user-token")
+	var resp models.DJResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Queued != 2 || len(queued) != 2 || queued[0] != "id-Song 0" || resp.QueueError == "" {
+		t.Errorf("Expected two tracks queued in order before the token was rejected, got %+v, %v", resp, queued)
+	}
+}
+
+func TestLyricsHandler_DJ_Validation(t *testing.T) {
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+
+	for _, vibe := range []string{"", "   ", strings.Repeat("x", 201)} {
+		if w := postDJ(handler, models.DJRequest{Vibe: vibe}, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for vibe %q, got %d", http.StatusBadRequest, vibe, w.Code)
+		}
+	}
+}
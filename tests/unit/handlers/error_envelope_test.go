@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"backend/middleware"
+	"backend/server/apierror"
+	"backend/server/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func decodeErrorEnvelope(t *testing.T, w *httptest.ResponseRecorder) models.ErrorDetail {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected application/json, got %q", ct)
+	}
+	var body models.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal error envelope %q: %v", w.Body.String(), err)
+	}
+	return body.Error
+}
+
+func TestErrorEnvelope_NotPlaying(t *testing.T) {
+	handler, _ := createTestHandlerWithRepo()
+
+	req := httptest.NewRequest("GET", "/api/now-playing", nil)
+	w := httptest.NewRecorder()
+	handler.GetNowPlaying(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+	detail := decodeErrorEnvelope(t, w)
+	if detail.Code != apierror.NotPlaying || detail.Message == "" {
+		t.Errorf("Expected code %s with a message, got %+v", apierror.NotPlaying, detail)
+	}
+}
+
+func TestErrorEnvelope_InvalidBody(t *testing.T) {
+	handler, _ := createTestHandlerWithRepo()
+
+	req := httptest.NewRequest("POST", "/api/now-playing", strings.NewReader("{not json"))
+	w := httptest.NewRecorder()
+	handler.UpdateNowPlaying(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if detail := decodeErrorEnvelope(t, w); detail.Code != apierror.InvalidRequest {
+		t.Errorf("Expected code %s, got %+v", apierror.InvalidRequest, detail)
+	}
+}
+
+func TestErrorEnvelope_RecoveredPanicHidesDetails(t *testing.T) {
+	handler := middleware.Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("database password is hunter2")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/stats", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	detail := decodeErrorEnvelope(t, w)
+	if detail.Code != apierror.Internal {
+		t.Errorf("Expected code %s, got %+v", apierror.Internal, detail)
+	}
+	if strings.Contains(w.Body.String(), "hunter2") {
+		t.Errorf("Expected the panic value to stay out of the response, got %q", w.Body.String())
+	}
+}
+
+func TestErrorEnvelope_UnknownRoute(t *testing.T) {
+	w := httptest.NewRecorder()
+	apierror.NotFoundHandler().ServeHTTP(w, httptest.NewRequest("GET", "/api/nope", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+	if detail := decodeErrorEnvelope(t, w); detail.Code != apierror.NotFound {
+		t.Errorf("Expected code %s, got %+v", apierror.NotFound, detail)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers_test
+
+import (
+	"backend/server/models"
+	"backend/services/events"
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestLyricsHandler_PublishesMoodEvents(t *testing.T) {
+	handler, _ := createTestHandlerWithRepo()
+	bus := events.New(events.DefaultConfig())
+	handler.SetEventBus(bus)
+
+	var mutex sync.Mutex
+	var received []events.Event
+	record := func(event events.Event) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, event)
+		return nil
+	}
+	bus.Subscribe(events.MoodDetected, "test", record)
+	bus.Subscribe(events.RecommendationServed, "test", record)
+
+	body, _ := json.Marshal(models.ChatRequest{Query: "I'm feeling really sad today"})
+	req := httptest.NewRequest("POST", "/api/chat", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", "user-42")
+	handler.HandleChat(httptest.NewRecorder(), req)
+	bus.Close()
+
+	if len(received) != 2 {
+		t.Fatalf("Expected mood and recommendation events, got %d", len(received))
+	}
+	for _, event := range received {
+		switch payload := event.Payload.(type) {
+		case events.MoodDetectedPayload:
+			if payload.UserID != "user-42" {
+				t.Errorf("Expected mood event for user-42, got %s", payload.UserID)
+			}
+		case events.RecommendationServedPayload:
+			if payload.UserID != "user-42" || payload.Kind != "mood_recommendation" || len(payload.Tracks) == 0 {
+				t.Errorf("Unexpected recommendation event %+v", payload)
+			}
+		default:
+			t.Errorf("Unexpected event payload %T", payload)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/experiments"
+	"backend/services/prompts"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func postChatFeedback(handler *handlers.LyricsHandler, userID string, feedback models.ChatFeedbackRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(feedback)
+	req := httptest.NewRequest("POST", "/api/chat/feedback", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	handler.HandleChatFeedback(w, req)
+	return w
+}
+
+func TestLyricsHandler_PromptExperiment(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "lyrics_analysis.concise.tmpl"), []byte("CONCISE {{.SongInfo}}"), 0o644)
+	templates, err := prompts.New(prompts.Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	experimentsService, err := experiments.New(experiments.NewMemoryStore(), map[string][]experiments.Variant{
+		prompts.LyricsAnalysis: {{Name: experiments.Control, Weight: 1}, {Name: "concise", Weight: 1}},
+	}, templates)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var prompt string
+	ai := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(p string) (string, error) {
+			prompt = p
+			return "An answer", nil
+		},
+		AnalyzeLyricsFunc: func(query, lyrics, songInfo string) (string, error) {
+			t.Error("Expected experiment prompts to be rendered by the handler")
+			return "", nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, ai, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+	handler.SetExperiments(experimentsService, templates)
+	musicRepo.UpdateNowPlaying(models.SpotifyTrack{ID: "1", Name: "Numb", Artist: "Linkin Park"})
+
+	var response models.ChatResponse
+	json.Unmarshal(postChat(handler, models.ChatRequest{Query: "what does this song mean?"}).Body.Bytes(), &response)
+	if response.Answer != "An answer" || response.ResponseID == "" {
+		t.Fatalf("Expected an answer with a response ID, got %+v", response)
+	}
+	assignment, _ := experimentsService.Assign(prompts.LyricsAnalysis, "default_user")
+	if concise := len(prompt) >= 7 && prompt[:7] == "CONCISE"; concise != (assignment.Variant == "concise") {
+		t.Errorf("Expected the %s prompt, got %q", assignment.Variant, prompt)
+	}
+
+	helpful := true
+	if w := postChatFeedback(handler, "someone_else", models.ChatFeedbackRequest{ResponseID: response.ResponseID, Helpful: &helpful}); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for another user's response, got %d", w.Code)
+	}
+	if w := postChatFeedback(handler, "default_user", models.ChatFeedbackRequest{ResponseID: response.ResponseID}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a rating, got %d", w.Code)
+	}
+	if w := postChatFeedback(handler, "default_user", models.ChatFeedbackRequest{ResponseID: response.ResponseID, Helpful: &helpful}); w.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := httptest.NewRecorder()
+	handlers.NewExperimentsHandler(experimentsService).ListExperiments(w, httptest.NewRequest("GET", "/api/admin/experiments", nil))
+	var results models.ExperimentsResponse
+	json.Unmarshal(w.Body.Bytes(), &results)
+	if len(results.Experiments) != 1 {
+		t.Fatalf("Expected one experiment, got %s", w.Body.String())
+	}
+	for _, variant := range results.Experiments[0].Variants {
+		served := variant.Variant == assignment.Variant
+		if served && (variant.Requests != 1 || variant.Helpful != 1) || !served && variant.Requests != 0 {
+			t.Errorf("Unexpected result for %s: %+v", variant.Variant, variant)
+		}
+	}
+}
+
+func TestLyricsHandler_ChatFeedback_NoExperiments(t *testing.T) {
+	helpful := false
+	if w := postChatFeedback(createTestHandler(), "default_user", models.ChatFeedbackRequest{ResponseID: "abc", Helpful: &helpful}); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without experiments, got %d", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	handlers.NewExperimentsHandler(nil).ListExperiments(w, httptest.NewRequest("GET", "/api/admin/experiments", nil))
+	if w.Body.String() != "{\"experiments\":[]}\n" {
+		t.Errorf("Expected no experiments, got %s", w.Body.String())
+	}
+}
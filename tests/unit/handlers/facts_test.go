@@ -0,0 +1,62 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/facts"
+	"backend/services/genius"
+	"backend/tests/mocks"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func getCurrentTrackFacts(handler *handlers.LyricsHandler, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.GetCurrentTrackFacts(w, httptest.NewRequest("GET", target, nil))
+	return w
+}
+
+func TestLyricsHandler_GetCurrentTrackFacts(t *testing.T) {
+	mockGenius := &mocks.MockGeniusService{
+		GetSongFunc: func(trackName, artistName string) (*models.GeniusSong, error) {
+			if trackName == "Unknown" {
+				return nil, fmt.Errorf("failed to search song: %w", genius.ErrSongNotFound)
+			}
+			return &models.GeniusSong{Title: trackName, Album: "Meteora", ReleaseDate: "2003"}, nil
+		},
+	}
+	mockAI := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			return "The damn chorus took months to finish.", nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(mockGenius)
+	handler := handlers.NewLyricsHandler(musicRepo, mockAI, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+	handler.SetFacts(facts.New(mockGenius, mockAI, nil, facts.DefaultConfig()))
+
+	if w := getCurrentTrackFacts(handler, "/api/tracks/current/facts"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 with no song playing, got %d", w.Code)
+	}
+
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "t1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+	w := getCurrentTrackFacts(handler, "/api/tracks/current/facts")
+	var songFacts models.SongFacts
+	json.Unmarshal(w.Body.Bytes(), &songFacts)
+	if w.Code != http.StatusOK || songFacts.TrackName != "Numb" || songFacts.Album != "Meteora" || songFacts.DidYouKnow != "The damn chorus took months to finish." {
+		t.Errorf("Expected the current song's facts, got %d %+v", w.Code, songFacts)
+	}
+
+	json.Unmarshal(getCurrentTrackFacts(handler, "/api/tracks/current/facts?clean=true").Body.Bytes(), &songFacts)
+	if songFacts.DidYouKnow != "The d*** chorus took months to finish." {
+		t.Errorf("Expected the blurb masked in clean mode, got %q", songFacts.DidYouKnow)
+	}
+
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "t2", Name: "Unknown", Artist: "Nobody", Source: "spotify"})
+	if w := getCurrentTrackFacts(handler, "/api/tracks/current/facts"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a song Genius doesn't know, got %d", w.Code)
+	}
+}
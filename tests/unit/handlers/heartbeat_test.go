@@ -0,0 +1,129 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func postHeartbeat(handler *handlers.LyricsHandler, trackID string, positionMs, durationMs int64) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]interface{}{
+		"track_id":    trackID,
+		"position_ms": positionMs,
+		"duration_ms": durationMs,
+	})
+	req := httptest.NewRequest("POST", "/api/now-playing/heartbeat", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	handler.Heartbeat(w, req)
+	return w
+}
+
+func TestLyricsHandler_Heartbeat_TracksListeningTime(t *testing.T) {
+	handler, musicRepo := createTestHandlerWithRepo()
+	musicRepo.UpdateNowPlaying(models.SpotifyTrack{ID: "track1", Name: "Numb", Artist: "Linkin Park"})
+	version := musicRepo.GetNowPlaying().Version
+
+	if w := postHeartbeat(handler, "track1", 0, 185000); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	postHeartbeat(handler, "track1", 20, 185000)
+
+	// A seek far ahead only counts the elapsed wall-clock time
+	time.Sleep(30 * time.Millisecond)
+	postHeartbeat(handler, "track1", 120000, 185000)
+
+	nowPlaying := musicRepo.GetNowPlaying()
+	if nowPlaying.PositionMs != 120000 || nowPlaying.DurationMs != 185000 {
+		t.Errorf("Expected position 120000/185000, got %d/%d", nowPlaying.PositionMs, nowPlaying.DurationMs)
+	}
+	if nowPlaying.ListenedMs < 20 || nowPlaying.ListenedMs > 1000 {
+		t.Errorf("Expected listening time to exclude the seek, got %dms", nowPlaying.ListenedMs)
+	}
+	if nowPlaying.Version != version {
+		t.Error("Expected heartbeats not to change the version")
+	}
+
+	history := musicRepo.GetPlayHistory()
+	if history[0].ListenedMs != nowPlaying.ListenedMs {
+		t.Errorf("Expected history listening time %d, got %d", nowPlaying.ListenedMs, history[0].ListenedMs)
+	}
+}
+
+func TestLyricsHandler_Heartbeat_Errors(t *testing.T) {
+	handler, musicRepo := createTestHandlerWithRepo()
+
+	if w := postHeartbeat(handler, "track1", 1000, 185000); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d without a track, got %d", http.StatusNotFound, w.Code)
+	}
+
+	musicRepo.UpdateNowPlaying(models.SpotifyTrack{ID: "track1", Name: "Numb", Artist: "Linkin Park"})
+
+	if w := postHeartbeat(handler, "other", 1000, 185000); w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d for another track, got %d", http.StatusConflict, w.Code)
+	}
+	if w := postHeartbeat(handler, "track1", 200000, 185000); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for position past the end, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestLyricsHandler_UpdateNowPlaying_WithProgress(t *testing.T) {
+	handler, musicRepo := createTestHandlerWithRepo()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":          "track1",
+		"name":        "Numb",
+		"artist":      "Linkin Park",
+		"progress_ms": 60000,
+		"duration_ms": 185000,
+		"is_paused":   true,
+	})
+	req := httptest.NewRequest("POST", "/api/now-playing", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	handler.UpdateNowPlaying(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// Paused, so the progress doesn't advance
+	time.Sleep(20 * time.Millisecond)
+	nowPlaying := musicRepo.GetNowPlaying()
+	if nowPlaying.ProgressMs != 60000 || nowPlaying.DurationMs != 185000 || !nowPlaying.Paused || nowPlaying.State != models.PlaybackPaused {
+		t.Errorf("Expected paused at 60000/185000, got %d/%d paused=%v", nowPlaying.ProgressMs, nowPlaying.DurationMs, nowPlaying.Paused)
+	}
+
+	getReq := httptest.NewRequest("GET", "/api/now-playing", nil)
+	getW := httptest.NewRecorder()
+	handler.GetNowPlaying(getW, getReq)
+	var served map[string]interface{}
+	json.Unmarshal(getW.Body.Bytes(), &served)
+	if served["progress_ms"] != float64(60000) || served["is_paused"] != true {
+		t.Errorf("Expected progress_ms and is_paused in GET, got %v", served)
+	}
+
+	// Playing tracks extrapolate their progress
+	musicRepo.SetPlaybackState(models.PlaybackPlaying)
+	postHeartbeat(handler, "track1", 60000, 185000)
+	time.Sleep(30 * time.Millisecond)
+	if progress := musicRepo.GetNowPlaying().ProgressMs; progress < 60030 || progress > 61000 {
+		t.Errorf("Expected progress to advance while playing, got %d", progress)
+	}
+}
+
+func TestLyricsHandler_UpdateNowPlaying_InvalidProgress(t *testing.T) {
+	handler, _ := createTestHandlerWithRepo()
+
+	body, _ := json.Marshal(map[string]interface{}{"id": "track1", "name": "Numb", "progress_ms": 200000, "duration_ms": 185000})
+	req := httptest.NewRequest("POST", "/api/now-playing", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	handler.UpdateNowPlaying(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,124 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/tests/mocks"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func getHistory(t *testing.T, url string) ([]models.PlayHistoryItem, *httptest.ResponseRecorder) {
+	t.Helper()
+	handler, musicRepo := createTestHandlerWithRepo()
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "yt1", Name: "Faint", Artist: "Linkin Park", Source: "youtube"})
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp2", Name: "Yellow", Artist: "Coldplay", Source: "spotify"})
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp3", Name: "In the End", Artist: "Linkin Park", Source: "spotify"})
+
+	rr := httptest.NewRecorder()
+	handler.GetPlayHistory(rr, httptest.NewRequest("GET", url, nil))
+
+	var history []models.PlayHistoryItem
+	json.Unmarshal(rr.Body.Bytes(), &history)
+	return history, rr
+}
+
+func TestGetPlayHistory_FiltersAndPages(t *testing.T) {
+	history, rr := getHistory(t, "/api/history?source=spotify&artist=linkin%20park&limit=1&offset=1")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rr.Code)
+	}
+	if rr.Header().Get("X-Total-Count") != "2" {
+		t.Errorf("Expected 2 matching items, got %q", rr.Header().Get("X-Total-Count"))
+	}
+	if len(history) != 1 || history[0].TrackID != "sp1" {
+		t.Errorf("Expected the second matching item, got %+v", history)
+	}
+
+	history, rr = getHistory(t, "/api/history?from=2000-01-01T00:00:00Z&to=2001-01-01T00:00:00Z")
+	if rr.Header().Get("X-Total-Count") != "0" || len(history) != 0 {
+		t.Errorf("Expected no items played in 2000, got %+v", history)
+	}
+
+	history, rr = getHistory(t, "/api/history")
+	if rr.Header().Get("X-Total-Count") != "4" || len(history) != 4 || history[0].TrackID != "sp3" {
+		t.Errorf("Expected the whole history, most recent first, got %+v", history)
+	}
+}
+
+func TestGetPlayHistory_PagesWithCursors(t *testing.T) {
+	// Plays at the same time are split across pages without repeats or gaps
+	played := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	items := []models.PlayHistoryItem{
+		{TrackID: "t5", PlayedAt: played.Add(2 * time.Minute)},
+		{TrackID: "t4", PlayedAt: played.Add(time.Minute)},
+		{TrackID: "t3", PlayedAt: played.Add(time.Minute)},
+		{TrackID: "t2", PlayedAt: played.Add(time.Minute)},
+		{TrackID: "t1", PlayedAt: played},
+	}
+	musicRepo := &mocks.MockMusicRepository{
+		QueryPlayHistoryFunc: func(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error) {
+			page, total := filter.Page(items, offset, limit)
+			return page, total, nil
+		},
+	}
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+
+	var seen []string
+	target := "/api/history?limit=2"
+	for pages := 0; target != "" && pages < 5; pages++ {
+		rr := httptest.NewRecorder()
+		handler.GetPlayHistory(rr, httptest.NewRequest("GET", target, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var history []models.PlayHistoryItem
+		json.Unmarshal(rr.Body.Bytes(), &history)
+		for _, item := range history {
+			seen = append(seen, item.TrackID)
+		}
+
+		target = ""
+		if cursor := rr.Header().Get("X-Next-Cursor"); cursor != "" {
+			target = "/api/history?limit=2&cursor=" + cursor
+		}
+	}
+	if strings.Join(seen, ",") != "t5,t4,t3,t2,t1" {
+		t.Errorf("Expected every item once, most recent first, got %v", seen)
+	}
+}
+
+func TestGetPlayHistory_StoreError(t *testing.T) {
+	var gotFilter models.PlayHistoryFilter
+	musicRepo := &mocks.MockMusicRepository{
+		QueryPlayHistoryFunc: func(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error) {
+			gotFilter = filter
+			return nil, 0, errors.New("connection refused")
+		},
+	}
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+
+	rr := httptest.NewRecorder()
+	handler.GetPlayHistory(rr, httptest.NewRequest("GET", "/api/history?source=youtube", nil))
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 when the history store fails, got %d", rr.Code)
+	}
+	if gotFilter.Source != "youtube" {
+		t.Errorf("Expected the source filter to reach the repository, got %+v", gotFilter)
+	}
+}
+
+func TestGetPlayHistory_InvalidQuery(t *testing.T) {
+	for _, query := range []string{"limit=0", "offset=-1", "from=yesterday", "from=2001-01-01T00:00:00Z&to=2000-01-01T00:00:00Z", "cursor=bogus", "cursor=WyIyMDI0LTA1LTAxVDEyOjAwOjAwWiIsMV0&offset=2"} {
+		if _, rr := getHistory(t, "/api/history?"+query); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestChatHandler_ReportMessage_RejectsInvalidRequests(t *testing.T) {
+	// Invalid reports are rejected before they reach the database
+	handler := handlers.NewChatHandler(nil)
+
+	report := func(id, body string) int {
+		req := httptest.NewRequest("POST", "/api/messages/"+id+"/report", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		handler.ReportMessage(w, req)
+		return w.Code
+	}
+	cases := map[string][2]string{
+		"invalid id":       {"abc", `{"reason": "spam"}`},
+		"invalid body":     {"1", `{`},
+		"missing reason":   {"1", `{"details": "rude"}`},
+		"unknown reason":   {"1", `{"reason": "boring"}`},
+		"details too long": {"1", `{"reason": "other", "details": "` + strings.Repeat("x", 1001) + `"}`},
+	}
+	for name, c := range cases {
+		if code := report(c[0], c[1]); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, code)
+		}
+	}
+}
+
+func TestChatHandler_AdminEndpoints_RejectInvalidRequests(t *testing.T) {
+	handler := handlers.NewChatHandler(nil)
+
+	ban := func(body string) int {
+		w := httptest.NewRecorder()
+		handler.BanUser(w, httptest.NewRequest("POST", "/api/admin/bans", strings.NewReader(body)))
+		return w.Code
+	}
+	for name, body := range map[string]string{
+		"invalid body":      `{`,
+		"missing user":      `{"duration": "1h"}`,
+		"missing duration":  `{"user_id": "bob"}`,
+		"invalid duration":  `{"user_id": "bob", "duration": "a week"}`,
+		"negative duration": `{"user_id": "bob", "duration": "-1h"}`,
+	} {
+		if code := ban(body); code != http.StatusBadRequest {
+			t.Errorf("ban with %s: expected 400, got %d", name, code)
+		}
+	}
+
+	for _, query := range []string{"?status=closed", "?limit=0", "?limit=many"} {
+		w := httptest.NewRecorder()
+		handler.ListReports(w, httptest.NewRequest("GET", "/api/admin/reports"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("reports%s: expected 400, got %d", query, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ListAudit(w, httptest.NewRequest("GET", "/api/admin/audit?limit=-5", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid audit limit, got %d", w.Code)
+	}
+
+	req := mux.SetURLVars(httptest.NewRequest("POST", "/api/admin/messages/x/hide", nil), map[string]string{"id": "x"})
+	w = httptest.NewRecorder()
+	handler.HideMessage(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid message ID, got %d", w.Code)
+	}
+}
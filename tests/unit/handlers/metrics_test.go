@@ -0,0 +1,41 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMetricsHandler_GetMetrics_ReportsDatabasePool(t *testing.T) {
+	handler := handlers.NewMetricsHandler(func() sql.DBStats {
+		return sql.DBStats{
+			MaxOpenConnections: 25,
+			OpenConnections:    7,
+			InUse:              5,
+			Idle:               2,
+			WaitCount:          3,
+			WaitDuration:       1500 * time.Millisecond,
+			MaxLifetimeClosed:  4,
+		}
+	})
+
+	w := httptest.NewRecorder()
+	handler.GetMetrics(w, httptest.NewRequest("GET", "/api/admin/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+
+	var metrics models.Metrics
+	if err := json.NewDecoder(w.Body).Decode(&metrics); err != nil {
+		t.Fatalf("Expected JSON metrics, got %v", err)
+	}
+	expected := models.DatabasePoolStats{MaxOpen: 25, Open: 7, InUse: 5, Idle: 2, WaitCount: 3, WaitMs: 1500, MaxLifetimeClosed: 4}
+	if metrics.Database != expected {
+		t.Errorf("Expected %+v, got %+v", expected, metrics.Database)
+	}
+}
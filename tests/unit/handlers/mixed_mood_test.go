@@ -0,0 +1,53 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/tests/mocks"
+	"fmt"
+	"testing"
+)
+
+func TestLyricsHandler_MixedMoodBlendsSuggestions(t *testing.T) {
+	catalog := map[string][]models.MoodBasedRecommendation{}
+	for _, mood := range []string{"happy", "nostalgic"} {
+		for i := 0; i < 10; i++ {
+			catalog[mood] = append(catalog[mood], models.MoodBasedRecommendation{
+				Track:     models.UnifiedTrack{ID: fmt.Sprintf("%s-%d", mood, i), Name: mood},
+				MoodScore: 1 - float64(i)/100,
+			})
+		}
+	}
+	moodService := &mocks.MockMoodService{
+		DetectMoodFunc: func(message string) (*models.MoodAnalysis, error) {
+			return &models.MoodAnalysis{
+				PrimaryMood: "happy",
+				MoodScore:   0.8,
+				Moods:       []models.WeightedMood{{Mood: "happy", Weight: 0.7}, {Mood: "nostalgic", Weight: 0.3}},
+			}, nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, moodService, &mocks.MockSpotifyService{})
+	handler.SetMoodCatalog(repositories.NewMoodCatalog(catalog))
+
+	resp := sendChat(handler, "I feel happy but nostalgic, like I remember the old days")
+
+	if resp.Recommendations == nil || len(resp.Recommendations.Suggested) != 10 {
+		t.Fatalf("Expected 10 suggestions, got %+v", resp.Recommendations)
+	}
+	counts := map[string]int{}
+	for _, suggestion := range resp.Recommendations.Suggested {
+		counts[suggestion.Track.Name]++
+	}
+	if counts["happy"] != 7 || counts["nostalgic"] != 3 {
+		t.Errorf("Expected 7 happy and 3 nostalgic suggestions, got %v", counts)
+	}
+	if resp.Recommendations.Suggested[1].Track.Name != "nostalgic" {
+		t.Errorf("Expected the moods to be interleaved, got %+v", resp.Recommendations.Suggested[:2])
+	}
+	if len(resp.MoodAnalysis.Moods) != 2 {
+		t.Errorf("Expected both moods in the analysis, got %+v", resp.MoodAnalysis)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/moderation"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func reviewModeration(handler *handlers.ChatHandler, id, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/admin/moderation/"+id+"/review", strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	req.Header.Set("X-User-ID", "mod")
+	w := httptest.NewRecorder()
+	handler.ReviewModeration(w, req)
+	return w
+}
+
+func TestChatHandler_Moderation_NotEnabled(t *testing.T) {
+	handler := handlers.NewChatHandler(nil)
+
+	w := httptest.NewRecorder()
+	handler.ModerationQueue(w, httptest.NewRequest("GET", "/api/admin/moderation", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without moderation, got %d", w.Code)
+	}
+	if w := reviewModeration(handler, "1", `{"decision": "approve"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without moderation, got %d", w.Code)
+	}
+}
+
+func TestChatHandler_PostMessage_RejectedByModeration(t *testing.T) {
+	// Rejected messages never reach the database
+	service := moderation.New(moderation.NewLocalClassifier(), moderation.NewMemoryStore(), moderation.DefaultConfig())
+	handler := handlers.NewChatHandler(nil)
+	handler.SetModeration(service)
+
+	w := postMessageAs(handler, "alice", `{"username": "a", "user_email": "a@example.com", "text": "kill yourself"}`)
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "harassment") {
+		t.Fatalf("Expected 403 naming the category, got %d %s", w.Code, w.Body.String())
+	}
+
+	verdicts, _ := service.Queue(models.ModerationRejected, 10)
+	if len(verdicts) != 1 || verdicts[0].Username != "a" || verdicts[0].UserEmail != "a@example.com" || verdicts[0].Text != "kill yourself" {
+		t.Errorf("Expected the rejected message to be recorded, got %+v", verdicts)
+	}
+}
+
+func TestChatHandler_ModerationQueueAndReview(t *testing.T) {
+	service := moderation.New(moderation.NewLocalClassifier(), moderation.NewMemoryStore(), moderation.DefaultConfig())
+	handler := handlers.NewChatHandler(nil)
+	handler.SetModeration(service)
+
+	flagged, _ := service.Record(models.ModerationVerdict{Username: "a", Text: "nobody likes you", Action: models.ModerationFlag})
+	service.Record(models.ModerationVerdict{Username: "b", Text: "rejected", Action: models.ModerationReject})
+
+	queue := func(query string) (int, []models.ModerationVerdict) {
+		w := httptest.NewRecorder()
+		handler.ModerationQueue(w, httptest.NewRequest("GET", "/api/admin/moderation"+query, nil))
+		var verdicts []models.ModerationVerdict
+		json.NewDecoder(w.Body).Decode(&verdicts)
+		return w.Code, verdicts
+	}
+	if code, verdicts := queue(""); code != http.StatusOK || len(verdicts) != 1 || verdicts[0].ID != flagged.ID {
+		t.Fatalf("Expected the pending verdict, got %d %+v", code, verdicts)
+	}
+	if _, verdicts := queue("?status=all"); len(verdicts) != 2 {
+		t.Errorf("Expected every verdict, got %+v", verdicts)
+	}
+	if code, _ := queue("?status=open"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown status, got %d", code)
+	}
+	if code, _ := queue("?limit=0"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid limit, got %d", code)
+	}
+
+	if w := reviewModeration(handler, "1", `{"decision": "ignore"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown decision, got %d", w.Code)
+	}
+	if w := reviewModeration(handler, "99", `{"decision": "approve"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown verdict, got %d", w.Code)
+	}
+
+	w := reviewModeration(handler, "1", `{"decision": "approve"}`)
+	var verdict models.ModerationVerdict
+	json.NewDecoder(w.Body).Decode(&verdict)
+	if w.Code != http.StatusOK || verdict.Status != models.ModerationApproved || verdict.ReviewedBy != "mod" {
+		t.Fatalf("Expected the verdict to be approved by mod, got %d %+v", w.Code, verdict)
+	}
+	if w := reviewModeration(handler, "1", `{"decision": "remove"}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a reviewed verdict, got %d", w.Code)
+	}
+}
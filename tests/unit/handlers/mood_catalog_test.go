@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func moodCatalogRouter(catalog *repositories.MoodCatalog) *mux.Router {
+	handler := handlers.NewMoodCatalogHandler(catalog)
+	router := mux.NewRouter()
+	router.HandleFunc("/api/admin/mood-suggestions", handler.ListMoodSuggestions).Methods("GET")
+	router.HandleFunc("/api/admin/mood-suggestions", handler.CreateMoodSuggestion).Methods("POST")
+	router.HandleFunc("/api/admin/mood-suggestions/{id}", handler.UpdateMoodSuggestion).Methods("PUT")
+	router.HandleFunc("/api/admin/mood-suggestions/{id}", handler.DeleteMoodSuggestion).Methods("DELETE")
+	return router
+}
+
+func sendMoodSuggestion(router *mux.Router, method, path string, suggestion interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(suggestion)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(method, path, bytes.NewBuffer(body)))
+	return rr
+}
+
+func TestMoodCatalogHandler_CRUD(t *testing.T) {
+	catalog := repositories.NewMoodCatalog(nil)
+	router := moodCatalogRouter(catalog)
+
+	rr := sendMoodSuggestion(router, "POST", "/api/admin/mood-suggestions", models.MoodSuggestion{
+		Mood:      "Angry",
+		Track:     models.UnifiedTrack{ID: "t1", Name: "Given Up", Artist: "Linkin Park"},
+		MoodScore: 0.9,
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created models.MoodSuggestion
+	json.NewDecoder(rr.Body).Decode(&created)
+	if created.ID == 0 || created.Mood != "angry" || created.Track.Source != "spotify" {
+		t.Errorf("Expected a lower-cased Spotify suggestion with an ID, got %+v", created)
+	}
+
+	created.MatchReason = "Pure release"
+	rr = sendMoodSuggestion(router, "PUT", "/api/admin/mood-suggestions/1", created)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if suggestions, _ := catalog.GetSuggestions("angry"); len(suggestions) != 1 || suggestions[0].MatchReason != "Pure release" {
+		t.Errorf("Expected the update to reach chat suggestions, got %+v", suggestions)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/admin/mood-suggestions?mood=angry", nil))
+	var listed models.MoodSuggestionsResponse
+	json.NewDecoder(rr.Body).Decode(&listed)
+	if rr.Code != http.StatusOK || len(listed.Suggestions) != 1 {
+		t.Errorf("Expected one listed suggestion, got %d: %+v", rr.Code, listed)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("DELETE", "/api/admin/mood-suggestions/1", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("DELETE", "/api/admin/mood-suggestions/1", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a deleted suggestion, got %d", rr.Code)
+	}
+}
+
+func TestMoodCatalogHandler_Validation(t *testing.T) {
+	router := moodCatalogRouter(repositories.NewMoodCatalog(nil))
+	track := models.UnifiedTrack{ID: "t1", Name: "Numb", Artist: "Linkin Park"}
+
+	tests := []struct {
+		name       string
+		suggestion models.MoodSuggestion
+	}{
+		{"unknown mood", models.MoodSuggestion{Mood: "hungry", Track: track, MoodScore: 0.5}},
+		{"missing track", models.MoodSuggestion{Mood: "sad", MoodScore: 0.5}},
+		{"score out of range", models.MoodSuggestion{Mood: "sad", Track: track, MoodScore: 1.5}},
+	}
+	for _, tt := range tests {
+		if rr := sendMoodSuggestion(router, "POST", "/api/admin/mood-suggestions", tt.suggestion); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", tt.name, rr.Code)
+		}
+	}
+
+	if rr := sendMoodSuggestion(router, "PUT", "/api/admin/mood-suggestions/abc", tests[0].suggestion); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid ID, got %d", rr.Code)
+	}
+}
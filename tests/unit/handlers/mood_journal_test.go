@@ -0,0 +1,90 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/mood"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newJournalHandler(t *testing.T) *handlers.LyricsHandler {
+	moodService := mood.New(&mocks.MockGeniusService{}, &mocks.MockOllamaService{}, t.TempDir(), nil)
+	moodService.SaveUserMoodHistory("alice", "sad", []string{"track-1"})
+	moodService.SaveUserMoodHistory("alice", "happy", nil)
+	moodService.SaveUserMoodHistory("alice", "sad", nil)
+
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	return handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, moodService, &mocks.MockSpotifyService{})
+}
+
+func postJournalNote(handler *handlers.LyricsHandler, userID string, req models.MoodJournalRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	r := httptest.NewRequest("POST", "/api/mood/journal", bytes.NewBuffer(body))
+	r.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	handler.AddMoodJournalNote(w, r)
+	return w
+}
+
+func TestLyricsHandler_MoodJournal(t *testing.T) {
+	handler := newJournalHandler(t)
+
+	w := postJournalNote(handler, "alice", models.MoodJournalRequest{Note: "  Long week at work  "})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var saved models.MoodJournalResponse
+	json.NewDecoder(w.Body).Decode(&saved)
+	if saved.Note != "Long week at work" || saved.Entry == "" {
+		t.Errorf("Expected the trimmed note on an entry, got %+v", saved)
+	}
+
+	r := httptest.NewRequest("GET", "/api/mood/insights", nil)
+	r.Header.Set("X-User-ID", "alice")
+	w = httptest.NewRecorder()
+	handler.GetMoodInsights(w, r)
+
+	var insights models.MoodInsights
+	json.NewDecoder(w.Body).Decode(&insights)
+	if len(insights.Entries) != 3 || insights.Entries[2].Note != "Long week at work" || insights.Notes != 1 {
+		t.Errorf("Expected the note on the latest entry, got %+v", insights.Entries)
+	}
+	if insights.MoodCounts["sad"] != 2 || insights.MoodCounts["happy"] != 1 || insights.TopMood != "sad" {
+		t.Errorf("Unexpected mood counts %v and top mood %q", insights.MoodCounts, insights.TopMood)
+	}
+
+	w = httptest.NewRecorder()
+	handler.GetMoodInsights(w, httptest.NewRequest("GET", "/api/mood/insights", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without X-User-ID, got %d", w.Code)
+	}
+}
+
+func TestLyricsHandler_MoodJournalValidation(t *testing.T) {
+	handler := newJournalHandler(t)
+
+	tests := []struct {
+		name   string
+		userID string
+		req    models.MoodJournalRequest
+		want   int
+	}{
+		{"empty note", "alice", models.MoodJournalRequest{Note: "   "}, http.StatusBadRequest},
+		{"long note", "alice", models.MoodJournalRequest{Note: strings.Repeat("a", 2001)}, http.StatusBadRequest},
+		{"unknown entry", "alice", models.MoodJournalRequest{Note: "Hi", Entry: "2001-01-01T00:00:00Z"}, http.StatusNotFound},
+		{"no mood history", "bob", models.MoodJournalRequest{Note: "Hi"}, http.StatusNotFound},
+		{"no user ID", "", models.MoodJournalRequest{Note: "Hi"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := postJournalNote(handler, tt.userID, tt.req); w.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, w.Code)
+		}
+	}
+}
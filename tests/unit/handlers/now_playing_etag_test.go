@@ -0,0 +1,139 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createTestHandlerWithRepo() (*handlers.LyricsHandler, *repositories.MemoryMusicRepository) {
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+	return handler, musicRepo
+}
+
+func postNowPlaying(handler *handlers.LyricsHandler, track models.SpotifyTrack, ifMatch string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(track)
+	req := httptest.NewRequest("POST", "/api/now-playing", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	w := httptest.NewRecorder()
+	handler.UpdateNowPlaying(w, req)
+	return w
+}
+
+func TestLyricsHandler_UpdateNowPlaying_IfMatch(t *testing.T) {
+	handler, _ := createTestHandlerWithRepo()
+
+	first := postNowPlaying(handler, models.SpotifyTrack{ID: "track1", Name: "Song 1", Artist: "Artist"}, "")
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected ETag header on update")
+	}
+
+	// Matching version is accepted
+	second := postNowPlaying(handler, models.SpotifyTrack{ID: "track2", Name: "Song 2", Artist: "Artist"}, etag)
+	if second.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, second.Code)
+	}
+
+	// Reusing the now stale version is rejected with the current state
+	stale := postNowPlaying(handler, models.SpotifyTrack{ID: "track3", Name: "Song 3", Artist: "Artist"}, etag)
+	if stale.Code != http.StatusConflict {
+		t.Fatalf("Expected status %d, got %d", http.StatusConflict, stale.Code)
+	}
+
+	var current models.NowPlaying
+	if err := json.Unmarshal(stale.Body.Bytes(), &current); err != nil {
+		t.Fatalf("Failed to unmarshal conflict response: %v", err)
+	}
+	if current.TrackID != "track2" {
+		t.Errorf("Expected current TrackID track2, got %s", current.TrackID)
+	}
+	if stale.Header().Get("ETag") != second.Header().Get("ETag") {
+		t.Errorf("Expected conflict ETag %s, got %s", second.Header().Get("ETag"), stale.Header().Get("ETag"))
+	}
+}
+
+func TestLyricsHandler_UpdateNowPlaying_InvalidIfMatch(t *testing.T) {
+	handler, _ := createTestHandlerWithRepo()
+
+	w := postNowPlaying(handler, models.SpotifyTrack{ID: "track1", Name: "Song 1", Artist: "Artist"}, `"abc"`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestLyricsHandler_GetNowPlaying_ETag(t *testing.T) {
+	handler, musicRepo := createTestHandlerWithRepo()
+	musicRepo.UpdateNowPlaying(models.SpotifyTrack{ID: "track1", Name: "Song 1", Artist: "Artist"})
+
+	req := httptest.NewRequest("GET", "/api/now-playing", nil)
+	w := httptest.NewRecorder()
+	handler.GetNowPlaying(w, req)
+
+	if etag := w.Header().Get("ETag"); !strings.HasPrefix(etag, `"1-`) {
+		t.Errorf("Expected an ETag for version 1, got %s", etag)
+	}
+}
+
+func getNowPlaying(handler *handlers.LyricsHandler, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/now-playing", nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	w := httptest.NewRecorder()
+	handler.GetNowPlaying(w, req)
+	return w
+}
+
+func TestLyricsHandler_GetNowPlaying_IfNoneMatch(t *testing.T) {
+	handler, musicRepo := createTestHandlerWithRepo()
+	musicRepo.UpdateNowPlaying(models.SpotifyTrack{ID: "track1", Name: "Song 1", Artist: "Artist"})
+
+	etag := getNowPlaying(handler, "").Header().Get("ETag")
+
+	// Unchanged state is not sent again
+	cached := getNowPlaying(handler, etag)
+	if cached.Code != http.StatusNotModified {
+		t.Fatalf("Expected status %d, got %d", http.StatusNotModified, cached.Code)
+	}
+	if cached.Body.Len() != 0 || cached.Header().Get("ETag") != etag {
+		t.Errorf("Expected an empty 304 with ETag %s, got %q with %s", etag, cached.Body.String(), cached.Header().Get("ETag"))
+	}
+
+	// Weak validators and lists match too
+	if w := getNowPlaying(handler, `"other", W/`+etag); w.Code != http.StatusNotModified {
+		t.Errorf("Expected status %d for a weak match in a list, got %d", http.StatusNotModified, w.Code)
+	}
+
+	// A heartbeat moves the position without changing the version, so the
+	// cached copy is stale
+	time.Sleep(time.Millisecond)
+	if w := postHeartbeat(handler, "track1", 5000, 180000); w.Code != http.StatusNoContent {
+		t.Fatalf("Heartbeat failed with status %d", w.Code)
+	}
+	fresh := getNowPlaying(handler, etag)
+	if fresh.Code != http.StatusOK {
+		t.Fatalf("Expected status %d after a heartbeat, got %d", http.StatusOK, fresh.Code)
+	}
+	if fresh.Header().Get("ETag") == etag {
+		t.Error("Expected a new ETag after a heartbeat")
+	}
+
+	// The GET ETag is still accepted by If-Match
+	update := postNowPlaying(handler, models.SpotifyTrack{ID: "track2", Name: "Song 2", Artist: "Artist"}, fresh.Header().Get("ETag"))
+	if update.Code != http.StatusOK {
+		t.Errorf("Expected If-Match with the GET ETag to succeed, got %d", update.Code)
+	}
+}
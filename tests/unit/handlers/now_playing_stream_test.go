@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/events"
+	"backend/services/realtime"
+	"backend/tests/mocks"
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readSSEEvent reads the next event's name and data, skipping comments
+func readSSEEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+	var name, data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && name != "":
+			return name, data
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestRealtimeHandler_NowPlayingStream(t *testing.T) {
+	bus := events.New(events.DefaultConfig())
+	defer bus.Close()
+
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	musicRepo.AddTrackListener(func(track models.UnifiedTrack) {
+		bus.Publish(events.TrackChanged, track)
+	})
+	musicRepo.UpdateNowPlaying(models.SpotifyTrack{ID: "track1", Name: "Numb", Artist: "Linkin Park"})
+
+	handler := handlers.NewRealtimeHandler(realtime.NewHub(), realtime.DefaultConfig(), musicRepo, nil)
+	handler.Subscribe(bus)
+	server := httptest.NewServer(http.HandlerFunc(handler.NowPlayingStream))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %q", contentType)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	name, data := readSSEEvent(t, reader)
+	if name != models.RealtimeNowPlaying || !strings.Contains(data, "Numb") {
+		t.Errorf("Expected the current track on connect, got %s %s", name, data)
+	}
+
+	// The client is registered once the first event has been flushed
+	musicRepo.UpdateNowPlaying(models.SpotifyTrack{ID: "track2", Name: "Faint", Artist: "Linkin Park"})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		name, data = readSSEEvent(t, reader)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the track change")
+	}
+	if name != models.RealtimeTrackChanged || !strings.Contains(data, "Faint") {
+		t.Errorf("Expected the track change, got %s %s", name, data)
+	}
+}
@@ -0,0 +1,72 @@
+package handlers_test
+
+import (
+	"backend/server/pagination"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPagination_ParsesLimits(t *testing.T) {
+	page, err := pagination.Parse(url.Values{}, 50, 200)
+	if err != nil || page.Limit != 50 || page.Cursor != "" {
+		t.Errorf("Expected the default limit, got %+v, %v", page, err)
+	}
+	page, err = pagination.Parse(url.Values{"limit": {"1000"}}, 50, 200)
+	if err != nil || page.Limit != 200 {
+		t.Errorf("Expected the limit to be capped, got %+v, %v", page, err)
+	}
+	for _, limit := range []string{"0", "-1", "ten"} {
+		if _, err := pagination.Parse(url.Values{"limit": {limit}}, 50, 200); err == nil {
+			t.Errorf("Expected an error for limit %q", limit)
+		}
+	}
+
+	if pagination.Requested(url.Values{}) || !pagination.Requested(url.Values{"cursor": {"x"}}) {
+		t.Error("Expected only queries with limit or cursor to request a page")
+	}
+}
+
+func TestPagination_RoundTripsCursors(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)
+	cursor := pagination.Encode(createdAt, int64(42))
+	if strings.ContainsAny(cursor, "+/=") {
+		t.Errorf("Expected a URL-safe cursor, got %q", cursor)
+	}
+
+	var gotTime time.Time
+	var gotID int64
+	ok, err := pagination.Page{Cursor: cursor}.Decode(&gotTime, &gotID)
+	if !ok || err != nil || !gotTime.Equal(createdAt) || gotID != 42 {
+		t.Errorf("Expected the sort keys back, got %v, %d, %v, %v", gotTime, gotID, ok, err)
+	}
+
+	if ok, err := (pagination.Page{}).Decode(&gotTime, &gotID); ok || err != nil {
+		t.Errorf("Expected the first page to have no cursor, got %v, %v", ok, err)
+	}
+	for _, bad := range []string{"not base64!", pagination.Encode("x"), pagination.Encode("x", 1), pagination.Encode(createdAt, int64(1), 2)} {
+		if _, err := (pagination.Page{Cursor: bad}).Decode(&gotTime, &gotID); !errors.Is(err, pagination.ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestPagination_SetsNextPageHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	pagination.SetNext(w, httptest.NewRequest("GET", "/api/history?source=spotify&limit=10&cursor=old", nil), "new")
+	if w.Header().Get(pagination.NextCursorHeader) != "new" {
+		t.Errorf("Expected the next cursor, got %q", w.Header().Get(pagination.NextCursorHeader))
+	}
+	if link := w.Header().Get("Link"); link != `</api/history?cursor=new&limit=10&source=spotify>; rel="next"` {
+		t.Errorf("Expected a link to the next page, got %q", link)
+	}
+
+	w = httptest.NewRecorder()
+	pagination.SetNext(w, httptest.NewRequest("GET", "/api/history", nil), "")
+	if len(w.Header()) != 0 {
+		t.Errorf("Expected no headers on the last page, got %v", w.Header())
+	}
+}
@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"backend/server/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func setPlaybackState(t *testing.T, state string, handle http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"state": state})
+	req := httptest.NewRequest("POST", "/api/now-playing/state", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	handle(w, req)
+	return w
+}
+
+func TestLyricsHandler_PauseKeepsTrack(t *testing.T) {
+	handler, musicRepo := createTestHandlerWithRepo()
+	musicRepo.UpdateNowPlaying(models.SpotifyTrack{ID: "track1", Name: "Numb", Artist: "Linkin Park"})
+
+	if w := setPlaybackState(t, models.PlaybackPaused, handler.UpdatePlaybackState); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	if musicRepo.IsPlaying() {
+		t.Error("Expected IsPlaying to be false while paused")
+	}
+
+	req := httptest.NewRequest("GET", "/api/now-playing", nil)
+	w := httptest.NewRecorder()
+	handler.GetNowPlaying(w, req)
+
+	var nowPlaying models.NowPlaying
+	json.Unmarshal(w.Body.Bytes(), &nowPlaying)
+	if w.Code != http.StatusOK || nowPlaying.State != models.PlaybackPaused {
+		t.Errorf("Expected paused track to be returned, got %d %q", w.Code, nowPlaying.State)
+	}
+
+	// The assistant still knows about a paused song
+	resp := sendChat(handler, "what does this song mean?")
+	if !strings.HasPrefix(resp.Answer, "Mock analysis") {
+		t.Errorf("Expected lyrics analysis for the paused song, got %s", resp.Answer)
+	}
+}
+
+func TestLyricsHandler_DeleteNowPlaying(t *testing.T) {
+	handler, musicRepo := createTestHandlerWithRepo()
+	musicRepo.UpdateNowPlaying(models.SpotifyTrack{ID: "track1", Name: "Numb", Artist: "Linkin Park"})
+
+	req := httptest.NewRequest("DELETE", "/api/now-playing", nil)
+	w := httptest.NewRecorder()
+	handler.DeleteNowPlaying(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+	}
+	if musicRepo.IsPlaying() || musicRepo.HasCurrentTrack() {
+		t.Error("Expected no track after DELETE")
+	}
+
+	req = httptest.NewRequest("GET", "/api/now-playing", nil)
+	w = httptest.NewRecorder()
+	handler.GetNowPlaying(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d after stop, got %d", http.StatusNotFound, w.Code)
+	}
+
+	if len(musicRepo.GetPlayHistory()) != 1 {
+		t.Error("Expected play history to be kept after stop")
+	}
+}
+
+func TestLyricsHandler_UpdatePlaybackState_Invalid(t *testing.T) {
+	handler, musicRepo := createTestHandlerWithRepo()
+
+	if w := setPlaybackState(t, models.PlaybackPaused, handler.UpdatePlaybackState); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d without a track, got %d", http.StatusNotFound, w.Code)
+	}
+
+	musicRepo.UpdateNowPlaying(models.SpotifyTrack{ID: "track1", Name: "Numb", Artist: "Linkin Park"})
+	if w := setPlaybackState(t, "rewinding", handler.UpdatePlaybackState); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for unknown state, got %d", http.StatusBadRequest, w.Code)
+	}
+}
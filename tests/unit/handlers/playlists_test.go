@@ -0,0 +1,123 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/spotify"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postPlaylist(handler *handlers.LyricsHandler, req models.PlaylistRequest, spotifyToken string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest("POST", "/api/playlists", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	if spotifyToken != "" {
+		httpReq.Header.Set("X-Spotify-Token", spotifyToken)
+	}
+	w := httptest.NewRecorder()
+	handler.CreatePlaylist(w, httpReq)
+	return w
+}
+
+func moodRecommendations() models.MoodRecommendations {
+	return models.MoodRecommendations{
+		FromLibrary: []models.MoodBasedRecommendation{
+			{Track: models.UnifiedTrack{ID: "lib1", Name: "Numb", Source: "spotify"}},
+			{Track: models.UnifiedTrack{ID: "yt1", Name: "Faint", Source: "youtube"}},
+		},
+		Suggested: []models.MoodBasedRecommendation{
+			{Track: models.UnifiedTrack{ID: "sug1", Name: "Creep", Source: "spotify"}},
+			{Track: models.UnifiedTrack{ID: "lib1", Name: "Numb", Source: "spotify"}},
+		},
+	}
+}
+
+func TestLyricsHandler_CreatePlaylist(t *testing.T) {
+	var gotName, gotDescription string
+	var gotTracks []string
+	mockSpotify := &mocks.MockSpotifyService{
+		CreatePlaylistFunc: func(userToken, name, description string, trackIDs []string) (*models.SpotifyPlaylist, error) {
+			if userToken != "user-token" {
+				t.Errorf("Expected the user's token, got %q", userToken)
+			}
+			gotName, gotDescription, gotTracks = name, description, trackIDs
+			return &models.SpotifyPlaylist{ID: "pl1", Name: name, URL: "https://open.spotify.com/playlist/pl1", TrackCount: len(trackIDs)}, nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, &mocks.MockMoodService{}, mockSpotify)
+
+	w := postPlaylist(handler, models.PlaylistRequest{Recommendations: moodRecommendations(), Mood: "nostalgic"}, "user-token")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	// Spotify tracks only, library first, without duplicates
+	if len(gotTracks) != 2 || gotTracks[0] != "lib1" || gotTracks[1] != "sug1" {
+		t.Errorf("Expected lib1 and sug1, got %v", gotTracks)
+	}
+	if gotName != "Feeling nostalgic" || !strings.Contains(gotDescription, "nostalgic") || !strings.Contains(gotDescription, "LinkinSync") {
+		t.Errorf("Unexpected name %q or description %q", gotName, gotDescription)
+	}
+
+	var resp models.ChatResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if resp.Type != "playlist" || resp.Playlist == nil || !strings.Contains(resp.Answer, "https://open.spotify.com/playlist/pl1") {
+		t.Errorf("Expected a playlist answer with the URL, got %+v", resp)
+	}
+}
+
+func TestLyricsHandler_CreatePlaylist_CustomNameAndLanguage(t *testing.T) {
+	var gotName string
+	mockSpotify := &mocks.MockSpotifyService{
+		CreatePlaylistFunc: func(userToken, name, description string, trackIDs []string) (*models.SpotifyPlaylist, error) {
+			gotName = name
+			return &models.SpotifyPlaylist{ID: "pl1", Name: name, URL: "https://open.spotify.com/playlist/pl1", TrackCount: len(trackIDs)}, nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, &mocks.MockMoodService{}, mockSpotify)
+
+	w := postPlaylist(handler, models.PlaylistRequest{Recommendations: moodRecommendations(), Mood: "sad", Name: "Rainy day", Lang: "es"}, "user-token")
+	var resp models.ChatResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if gotName != "Rainy day" || resp.Language != "es" || !strings.HasPrefix(resp.Answer, "He guardado 2 canciones") {
+		t.Errorf("Expected the custom name and a Spanish answer, got %q, %+v", gotName, resp)
+	}
+}
+
+func TestLyricsHandler_CreatePlaylist_Errors(t *testing.T) {
+	mockSpotify := &mocks.MockSpotifyService{
+		CreatePlaylistFunc: func(userToken, name, description string, trackIDs []string) (*models.SpotifyPlaylist, error) {
+			return nil, spotify.ErrUserTokenRejected
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, &mocks.MockMoodService{}, mockSpotify)
+
+	tests := []struct {
+		name   string
+		req    models.PlaylistRequest
+		token  string
+		status int
+	}{
+		{"missing token", models.PlaylistRequest{Recommendations: moodRecommendations(), Mood: "sad"}, "", http.StatusBadRequest},
+		{"missing name and mood", models.PlaylistRequest{Recommendations: moodRecommendations()}, "user-token", http.StatusBadRequest},
+		{"no spotify tracks", models.PlaylistRequest{Mood: "sad"}, "user-token", http.StatusBadRequest},
+		{"rejected token", models.PlaylistRequest{Recommendations: moodRecommendations(), Mood: "sad"}, "expired", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if w := postPlaylist(handler, tt.req, tt.token); w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+		}
+	}
+}
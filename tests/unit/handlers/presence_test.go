@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/presence"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPresenceHandler_ListsListeningUsers(t *testing.T) {
+	handler := handlers.NewPresenceHandler(presence.New(presence.DefaultConfig()))
+
+	heartbeat := func(userID, body string) int {
+		req := httptest.NewRequest("POST", "/api/presence/heartbeat", strings.NewReader(body))
+		req.Header.Set("X-User-ID", userID)
+		w := httptest.NewRecorder()
+		handler.Heartbeat(w, req)
+		return w.Code
+	}
+	if code := heartbeat("alice", `{"share_listening": true, "now_playing": {"track_id": "t1", "track_name": "Numb", "artist": "Linkin Park"}}`); code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", code)
+	}
+	if code := heartbeat("bob", `{}`); code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", code)
+	}
+
+	list := func(query string) []models.Presence {
+		w := httptest.NewRecorder()
+		handler.GetPresence(w, httptest.NewRequest("GET", "/api/presence"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", w.Code)
+		}
+		var online []models.Presence
+		json.NewDecoder(w.Body).Decode(&online)
+		return online
+	}
+	if online := list(""); len(online) != 2 {
+		t.Errorf("Expected 2 users online, got %+v", online)
+	}
+	if listening := list("?listening=true"); len(listening) != 1 || listening[0].UserID != "alice" {
+		t.Errorf("Expected only alice listening, got %+v", listening)
+	}
+}
+
+func TestPresenceHandler_RejectsInvalidRequests(t *testing.T) {
+	handler := handlers.NewPresenceHandler(presence.New(presence.DefaultConfig()))
+
+	for name, body := range map[string]string{
+		"invalid body":      `{`,
+		"long username":     `{"username": "` + strings.Repeat("x", 256) + `"}`,
+		"track without id":  `{"now_playing": {"track_name": "Numb"}}`,
+		"long track artist": `{"now_playing": {"track_id": "t1", "track_name": "Numb", "artist": "` + strings.Repeat("x", 256) + `"}}`,
+	} {
+		w := httptest.NewRecorder()
+		handler.Heartbeat(w, httptest.NewRequest("POST", "/api/presence/heartbeat", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.GetPresence(w, httptest.NewRequest("GET", "/api/presence?listening=maybe", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid listening filter, got %d", w.Code)
+	}
+}
@@ -0,0 +1,53 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/mood"
+	"backend/services/reports"
+	"backend/tests/mocks"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReportsHandler_GetWeeklyReport(t *testing.T) {
+	moodService := &mocks.MockMoodService{
+		GetUserMoodHistoryFunc: func(userID string) ([]mood.UserMoodEntry, error) {
+			if userID != "alice" {
+				return nil, nil
+			}
+			return []mood.UserMoodEntry{{Timestamp: "2026-10-06T10:00:00Z", DetectedMood: "happy"}}, nil
+		},
+	}
+	reportsService := reports.New(moodService, nil, nil)
+	reportsService.Generate("alice", time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC))
+	handler := handlers.NewReportsHandler(reportsService)
+
+	req := httptest.NewRequest("GET", "/api/reports/weekly", nil)
+	req.Header.Set("X-User-ID", "alice")
+	w := httptest.NewRecorder()
+	handler.GetWeeklyReport(w, req)
+
+	var report models.WeeklyReport
+	json.NewDecoder(w.Body).Decode(&report)
+	if w.Code != http.StatusOK || report.UserID != "alice" || report.MoodCounts["happy"] != 1 {
+		t.Errorf("Expected alice's report, got %d: %+v", w.Code, report)
+	}
+
+	req = httptest.NewRequest("GET", "/api/reports/weekly", nil)
+	req.Header.Set("X-User-ID", "bob")
+	w = httptest.NewRecorder()
+	handler.GetWeeklyReport(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a report, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.GetWeeklyReport(w, httptest.NewRequest("GET", "/api/reports/weekly", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without X-User-ID, got %d", w.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/restricted"
+	"backend/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func sendChatAs(handler *handlers.LyricsHandler, userID, query string) models.ChatResponse {
+	body, _ := json.Marshal(models.ChatRequest{Query: query})
+	req := httptest.NewRequest("POST", "/api/chat", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	handler.HandleChat(w, req)
+
+	var resp models.ChatResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp
+}
+
+func TestLyricsHandler_RestrictedMode_FiltersExplicitRadioTracks(t *testing.T) {
+	mockSpotify := &mocks.MockSpotifyService{
+		SearchArtistFunc: func(name string) (*models.SpotifyArtist, error) {
+			return &models.SpotifyArtist{ID: "seed", Name: name}, nil
+		},
+		GetArtistTopTracksFunc: func(artistID string) ([]models.UnifiedTrack, error) {
+			return []models.UnifiedTrack{
+				{ID: "e1", Name: "Explicit Hit", Artist: "Eminem", Source: "spotify", Explicit: true},
+				{ID: "c1", Name: "Clean Hit", Artist: "Eminem", Source: "spotify"},
+			}, nil
+		},
+	}
+	handler := handlers.NewLyricsHandler(repositories.NewMusicRepository(&mocks.MockGeniusService{}), &mocks.MockOllamaService{}, &mocks.MockMoodService{}, mockSpotify)
+	handler.SetRestrictions(restricted.New(restricted.Config{Users: []string{"teen"}}))
+
+	teen := sendChatAs(handler, "teen", "songs like Eminem")
+	if teen.Radio == nil || len(teen.Radio.Tracks) != 1 || teen.Radio.Tracks[0].Track.ID != "c1" {
+		t.Errorf("Expected only the clean track for a restricted user, got %+v", teen.Radio)
+	}
+
+	adult := sendChatAs(handler, "adult", "songs like Eminem")
+	if adult.Radio == nil || len(adult.Radio.Tracks) != 2 {
+		t.Errorf("Expected both tracks for an unrestricted user, got %+v", adult.Radio)
+	}
+}
+
+func TestLyricsHandler_RestrictedMode_RefusesExplicitLyrics(t *testing.T) {
+	analyzed := false
+	mockAI := &mocks.MockOllamaService{
+		AnalyzeLyricsFunc: func(query, lyrics, songInfo string) (string, error) {
+			analyzed = true
+			return "analysis", nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{
+		GetLyricsFunc: func(trackName, artistName string) (string, error) { return "lyrics", nil },
+	})
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "e1", Name: "Explicit Hit", Artist: "Eminem", Source: "spotify", Explicit: true})
+	handler := handlers.NewLyricsHandler(musicRepo, mockAI, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+	handler.SetRestrictions(restricted.New(restricted.Config{Deployment: true}))
+
+	resp := sendChatAs(handler, "teen", "what does this song mean?")
+	if analyzed || !strings.Contains(resp.Answer, "restricted mode") {
+		t.Errorf("Expected explicit lyrics to be refused, got %q", resp.Answer)
+	}
+}
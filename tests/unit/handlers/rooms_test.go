@@ -0,0 +1,44 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestChatHandler_ReadMarkers_RejectInvalidRequests(t *testing.T) {
+	// Unknown rooms and invalid markers are rejected before they reach the database
+	handler := handlers.NewChatHandler(nil)
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/api/rooms/lobby/read", nil), map[string]string{"room": "lobby"})
+	w := httptest.NewRecorder()
+	handler.GetReadMarker(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown room, got %d", w.Code)
+	}
+
+	setMarker := func(room, body string) int {
+		req := httptest.NewRequest("PUT", "/api/rooms/"+room+"/read", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"room": room})
+		w := httptest.NewRecorder()
+		handler.SetReadMarker(w, req)
+		return w.Code
+	}
+	if code := setMarker("lobby", `{"message_id": 1}`); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown room, got %d", code)
+	}
+	for name, body := range map[string]string{
+		"invalid body":        `{`,
+		"missing message":     `{}`,
+		"negative message":    `{"message_id": -3}`,
+		"non-numeric message": `{"message_id": "latest"}`,
+	} {
+		if code := setMarker("global", body); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, code)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/events"
+	"backend/services/projections"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatsHandler_GetStats_ScopedToCaller(t *testing.T) {
+	service := projections.New(projections.NewMemoryStore(), projections.DefaultConfig())
+	bus := events.New(events.DefaultConfig())
+	service.Register(bus)
+	bus.Publish(events.MoodDetected, events.MoodDetectedPayload{UserID: "alice", Analysis: models.MoodAnalysis{PrimaryMood: "sad"}})
+	bus.Close()
+	handler := handlers.NewStatsHandler(service)
+
+	getStats := func(userID, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		if userID != "" {
+			req.Header.Set("X-User-ID", userID)
+		}
+		w := httptest.NewRecorder()
+		handler.GetStats(w, req)
+		return w
+	}
+
+	if w := getStats("", "/api/stats?user_id=alice"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without X-User-ID, got %d", w.Code)
+	}
+
+	w := getStats("bob", "/api/stats?user_id=alice")
+	var stats models.UserStats
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	if w.Code != http.StatusOK || stats.UserID != "bob" || len(stats.Days) != 0 {
+		t.Errorf("Expected the caller's own empty stats, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = getStats("alice", "/api/stats")
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	if w.Code != http.StatusOK || stats.UserID != "alice" || len(stats.Days) != 1 {
+		t.Errorf("Expected alice's stats, got %d: %s", w.Code, w.Body.String())
+	}
+}
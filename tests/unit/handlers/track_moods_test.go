@@ -0,0 +1,175 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/jobqueue"
+	"backend/services/mood"
+	"backend/tests/mocks"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func postTrackMoods(handler *handlers.LyricsHandler, req models.TrackMoodsRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest("POST", "/api/tracks/moods", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.GetTrackMoods(w, httpReq)
+	return w
+}
+
+func TestLyricsHandler_GetTrackMoods_CachedOnly(t *testing.T) {
+	analyzeCalls := 0
+	mockMood := &mocks.MockMoodService{
+		GetCachedLyricsMoodFunc: func(trackName, artistName string) (*mood.LyricsWithMood, bool) {
+			if trackName == "Numb" {
+				return &mood.LyricsWithMood{
+					MoodAnalysis: &models.MoodAnalysis{PrimaryMood: "sad", MoodScore: 0.9},
+					Themes:       []string{"pressure"},
+				}, true
+			}
+			return nil, false
+		},
+		GetLyricsWithMoodFunc: func(trackName, artistName string) (*mood.LyricsWithMood, error) {
+			analyzeCalls++
+			return nil, nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, mockMood, &mocks.MockSpotifyService{})
+
+	w := postTrackMoods(handler, models.TrackMoodsRequest{
+		Tracks: []models.TrackReference{
+			{Name: "Numb", Artist: "Linkin Park"},
+			{Name: "Unknown", Artist: "Nobody"},
+		},
+	})
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp models.TrackMoodsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(resp.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(resp.Results))
+	}
+	if !resp.Results[0].Cached || resp.Results[0].MoodAnalysis.PrimaryMood != "sad" {
+		t.Errorf("Expected cached sad analysis for first track, got %+v", resp.Results[0])
+	}
+	if resp.Results[1].Cached || resp.Results[1].MoodAnalysis != nil {
+		t.Errorf("Expected no analysis for uncached track, got %+v", resp.Results[1])
+	}
+	if analyzeCalls != 0 {
+		t.Errorf("Expected no AI analysis without analyze flag, got %d calls", analyzeCalls)
+	}
+}
+
+func TestLyricsHandler_GetTrackMoods_Analyze(t *testing.T) {
+	handler := createTestHandler()
+
+	w := postTrackMoods(handler, models.TrackMoodsRequest{
+		Tracks:  []models.TrackReference{{Name: "Numb", Artist: "Linkin Park"}},
+		Analyze: true,
+	})
+
+	var resp models.TrackMoodsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if resp.Results[0].Cached {
+		t.Error("Expected freshly analyzed track not to be marked cached")
+	}
+	if resp.Results[0].MoodAnalysis == nil {
+		t.Error("Expected analysis when analyze flag is set")
+	}
+}
+
+func TestLyricsHandler_GetTrackMoods_TooManyTracks(t *testing.T) {
+	handler := createTestHandler()
+
+	tracks := make([]models.TrackReference, 51)
+	for i := range tracks {
+		tracks[i] = models.TrackReference{Name: "Song", Artist: "Artist"}
+	}
+
+	w := postTrackMoods(handler, models.TrackMoodsRequest{Tracks: tracks})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestLyricsHandler_GetTrackMoods_Async(t *testing.T) {
+	mockMood := &mocks.MockMoodService{
+		GetLyricsWithMoodFunc: func(trackName, artistName string) (*mood.LyricsWithMood, error) {
+			if trackName == "Missing" {
+				return nil, errors.New("lyrics not found")
+			}
+			return &mood.LyricsWithMood{MoodAnalysis: &models.MoodAnalysis{PrimaryMood: "sad"}}, nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, &mocks.MockOllamaService{}, mockMood, &mocks.MockSpotifyService{})
+
+	if w := postTrackMoods(handler, models.TrackMoodsRequest{Tracks: []models.TrackReference{{Name: "Numb", Artist: "Linkin Park"}}, Async: true}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a job queue, got %d", w.Code)
+	}
+
+	queue := jobqueue.New(jobqueue.NewMemoryStore(), jobqueue.DefaultConfig())
+	queue.Handle(models.JobTypeTrackMoods, handler.TrackMoodsJob)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue.Start(ctx)
+	handler.SetJobQueue(queue)
+	queueHandler := handlers.NewQueueHandler(queue)
+
+	tracks := make([]models.TrackReference, 60) // More than a synchronous lookup allows
+	for i := range tracks {
+		tracks[i] = models.TrackReference{Name: fmt.Sprintf("Song %d", i), Artist: "Linkin Park"}
+	}
+	tracks[0].Name = "Missing"
+	w := postTrackMoods(handler, models.TrackMoodsRequest{Tracks: tracks, Async: true})
+	var job models.QueuedJob
+	json.Unmarshal(w.Body.Bytes(), &job)
+	if w.Code != http.StatusAccepted || job.Type != models.JobTypeTrackMoods || w.Header().Get("Location") != fmt.Sprintf("/api/jobs/%d", job.ID) {
+		t.Fatalf("Expected 202 with the queued job, got %d: %s", w.Code, w.Body.String())
+	}
+
+	getJob := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/jobs/%d", job.ID), nil)
+		req = mux.SetURLVars(req, map[string]string{"id": fmt.Sprint(job.ID)})
+		req.Header.Set("X-User-ID", userID)
+		w := httptest.NewRecorder()
+		queueHandler.GetQueuedJob(w, req)
+		return w
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for job.Status != models.JobSucceeded && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		json.Unmarshal(getJob("default_user").Body.Bytes(), &job)
+	}
+
+	var result models.TrackMoodsResponse
+	json.Unmarshal(job.Result, &result)
+	if job.Status != models.JobSucceeded || len(result.Results) != 60 || result.Results[0].Error != "lyrics not found" || result.Results[1].MoodAnalysis == nil {
+		t.Errorf("Expected the analyses in the job result, got %+v", job)
+	}
+	if w := getJob("someone_else"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for another user's job, got %d", w.Code)
+	}
+}
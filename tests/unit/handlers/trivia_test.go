@@ -0,0 +1,84 @@
+package handlers_test
+
+import (
+	"backend/repositories"
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/trivia"
+	"backend/tests/mocks"
+	"strings"
+	"testing"
+)
+
+func newTriviaHandler(prompt *string) (*handlers.LyricsHandler, *repositories.MemoryMusicRepository) {
+	mockAI := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(p string) (string, error) {
+			*prompt = p
+			return `{"questions": [
+				{"question": "Which album is Numb on?", "choices": ["Meteora", "Hybrid Theory", "Minutes to Midnight", "One More Light"], "answer": 0},
+				{"question": "Who produced Meteora?", "choices": ["Rick Rubin", "Don Gilmore", "Butch Vig", "Max Martin"], "answer": 1}
+			]}`, nil
+		},
+		GenerateResponseFunc: func(p string) (string, error) {
+			return "A general answer", nil
+		},
+	}
+	musicRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	handler := handlers.NewLyricsHandler(musicRepo, mockAI, &mocks.MockMoodService{}, &mocks.MockSpotifyService{})
+	handler.SetTrivia(trivia.New(nil, trivia.DefaultConfig()))
+	return handler, musicRepo
+}
+
+func TestLyricsHandler_TriviaQuiz(t *testing.T) {
+	var prompt string
+	handler, musicRepo := newTriviaHandler(&prompt)
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "t1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+
+	resp := sendChatAs(handler, "user1", "Quiz me!")
+	if resp.Type != "quiz" || resp.Quiz == nil || resp.Quiz.Total != 2 || !strings.Contains(resp.Answer, "B) Hybrid Theory") {
+		t.Fatalf("Expected the first question, got %+v", resp)
+	}
+	if !strings.Contains(prompt, "the artist Linkin Park") {
+		t.Errorf("Expected a quiz about the current artist, got %q", prompt)
+	}
+
+	resp = sendChatAs(handler, "user1", "what genre is this band?")
+	if resp.Type == "quiz" {
+		t.Errorf("Expected other messages to be answered as usual, got %+v", resp)
+	}
+
+	resp = sendChatAs(handler, "user1", "A")
+	if resp.Quiz == nil || !resp.Quiz.Result.Correct || resp.Quiz.Question.Number != 2 || !strings.HasPrefix(resp.Answer, "Correct!") {
+		t.Errorf("Expected a correct answer and the next question, got %+v", resp)
+	}
+
+	resp = sendChatAs(handler, "user1", "c")
+	if resp.Quiz == nil || !resp.Quiz.Finished || resp.Quiz.Score != 1 || !strings.Contains(resp.Answer, "B) Don Gilmore") || !strings.Contains(resp.Answer, "1 out of 2") {
+		t.Errorf("Expected the final score, got %+v", resp)
+	}
+}
+
+func TestLyricsHandler_TriviaQuiz_History(t *testing.T) {
+	var prompt string
+	handler, musicRepo := newTriviaHandler(&prompt)
+
+	resp := sendChatAs(handler, "user1", "Start a quiz")
+	if resp.Type != "quiz" || resp.Quiz != nil {
+		t.Errorf("Expected a quiz needs a song or history, got %+v", resp)
+	}
+
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "t1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+	musicRepo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "t2", Name: "Yellow", Artist: "Coldplay", Source: "spotify"})
+	resp = sendChatAs(handler, "user1", "Give me trivia about my recent songs")
+	if resp.Quiz == nil || !strings.Contains(prompt, `"Numb" by Linkin Park`) || !strings.Contains(prompt, `"Yellow" by Coldplay`) {
+		t.Errorf("Expected a quiz about the recent songs, got %+v with prompt %q", resp, prompt)
+	}
+
+	resp = sendChatAs(handler, "user1", "stop the quiz")
+	if resp.Quiz == nil || !resp.Quiz.Finished || !strings.HasPrefix(resp.Answer, "Quiz stopped") {
+		t.Errorf("Expected the quiz stopped, got %+v", resp)
+	}
+	if resp = sendChatAs(handler, "user1", "A"); resp.Type == "quiz" {
+		t.Errorf("Expected no quiz after stopping, got %+v", resp)
+	}
+}
@@ -0,0 +1,62 @@
+package handlers_test
+
+import (
+	"backend/server/handlers"
+	"backend/server/models"
+	"backend/services/users"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func updateMe(handler *handlers.UsersHandler, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("PATCH", "/api/users/me", strings.NewReader(body))
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	handler.UpdateMe(w, req)
+	return w
+}
+
+func TestUsersHandler_UpdatesTheCallersProfile(t *testing.T) {
+	handler := handlers.NewUsersHandler(users.New(users.NewMemoryStore()))
+
+	w := updateMe(handler, "alice@example.com", `{"username": "alice", "display_name": " Alice ", "favorite_genres": ["Rock", "rock", "Nu Metal"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	req := httptest.NewRequest("GET", "/api/users/me", nil)
+	req.Header.Set("X-User-ID", "alice@example.com")
+	w = httptest.NewRecorder()
+	handler.GetMe(w, req)
+	var user models.User
+	json.NewDecoder(w.Body).Decode(&user)
+	if user.Email != "alice@example.com" || user.DisplayName != "Alice" || len(user.FavoriteGenres) != 2 || user.FavoriteGenres[1] != "nu metal" {
+		t.Errorf("Expected the normalized profile, got %+v", user)
+	}
+
+	if w := updateMe(handler, "bob", `{"username": "Alice"}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a taken username, got %d", w.Code)
+	}
+}
+
+func TestUsersHandler_RejectsInvalidProfiles(t *testing.T) {
+	handler := handlers.NewUsersHandler(users.New(users.NewMemoryStore()))
+
+	for name, body := range map[string]string{
+		"invalid body":      `{`,
+		"invalid email":     `{"email": "alice"}`,
+		"short username":    `{"username": "al"}`,
+		"username with @":   `{"username": "al@ice"}`,
+		"long display name": `{"display_name": "` + strings.Repeat("x", 65) + `"}`,
+		"long bio":          `{"bio": "` + strings.Repeat("x", 501) + `"}`,
+		"too many genres":   `{"favorite_genres": ["a","b","c","d","e","f","g","h","i","j","k"]}`,
+		"empty genre":       `{"favorite_genres": [" "]}`,
+	} {
+		if w := updateMe(handler, "alice", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, w.Code)
+		}
+	}
+}
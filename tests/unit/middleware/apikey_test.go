@@ -0,0 +1,88 @@
+package middleware_test
+
+import (
+	"backend/middleware"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// keyStoreFunc adapts a function to middleware.KeyStore
+type keyStoreFunc func(key string) (bool, error)
+
+func (f keyStoreFunc) ValidAPIKey(key string) (bool, error) {
+	return f(key)
+}
+
+func serveWithAPIKey(stores []middleware.KeyStore, setHeaders func(r *http.Request)) *httptest.ResponseRecorder {
+	handler := middleware.APIKey(stores...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest("POST", "/api/now-playing", nil)
+	setHeaders(req)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAPIKey_AcceptsConfiguredKeys(t *testing.T) {
+	stores := []middleware.KeyStore{middleware.StaticKeys{"key-1", "key-2"}}
+
+	rr := serveWithAPIKey(stores, func(r *http.Request) { r.Header.Set("X-API-Key", "key-2") })
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("Expected X-API-Key to be accepted, got %d", rr.Code)
+	}
+
+	rr = serveWithAPIKey(stores, func(r *http.Request) { r.Header.Set("Authorization", "Bearer key-1") })
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("Expected bearer token to be accepted, got %d", rr.Code)
+	}
+}
+
+func TestAPIKey_RejectsMissingAndInvalidKeys(t *testing.T) {
+	stores := []middleware.KeyStore{middleware.StaticKeys{"key-1"}}
+
+	if rr := serveWithAPIKey(stores, func(r *http.Request) {}); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a key, got %d", rr.Code)
+	}
+	rr := serveWithAPIKey(stores, func(r *http.Request) { r.Header.Set("X-API-Key", "wrong") })
+	if rr.Code != http.StatusUnauthorized || rr.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected 401 with WWW-Authenticate for a wrong key, got %d", rr.Code)
+	}
+}
+
+func TestAPIKey_FallsBackToOtherStores(t *testing.T) {
+	dbKeys := keyStoreFunc(func(key string) (bool, error) { return key == "db-key", nil })
+	stores := []middleware.KeyStore{middleware.StaticKeys{}, dbKeys}
+
+	if rr := serveWithAPIKey(stores, func(r *http.Request) { r.Header.Set("X-API-Key", "db-key") }); rr.Code != http.StatusNoContent {
+		t.Errorf("Expected key from the second store to be accepted, got %d", rr.Code)
+	}
+
+	failing := keyStoreFunc(func(key string) (bool, error) { return false, errors.New("db down") })
+	if rr := serveWithAPIKey([]middleware.KeyStore{failing}, func(r *http.Request) { r.Header.Set("X-API-Key", "k") }); rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 when the store fails, got %d", rr.Code)
+	}
+}
+
+func TestAPIKey_AcceptsQueryTokenOnlyForWebSocketUpgrades(t *testing.T) {
+	handler := middleware.APIKey(middleware.StaticKeys{"key-1"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest("GET", "/api/ws/chat?token=key-1", nil)
+	req.Header.Set("Upgrade", "websocket")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("Expected token to be accepted on a WebSocket upgrade, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/api/messages?token=key-1", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected token to be ignored on plain requests, got %d", rr.Code)
+	}
+}
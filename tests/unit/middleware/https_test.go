@@ -0,0 +1,30 @@
+package middleware_test
+
+import (
+	"backend/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectHTTPS(t *testing.T) {
+	tests := []struct {
+		method    string
+		url       string
+		port      string
+		wantCode  int
+		wantToURL string
+	}{
+		{"GET", "http://example.com/api/now-playing?x=1", "443", http.StatusMovedPermanently, "https://example.com/api/now-playing?x=1"},
+		{"GET", "http://example.com:80/", "8443", http.StatusMovedPermanently, "https://example.com:8443/"},
+		{"POST", "http://example.com/api/messages", "443", http.StatusPermanentRedirect, "https://example.com/api/messages"},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		middleware.RedirectHTTPS(tt.port).ServeHTTP(rr, httptest.NewRequest(tt.method, tt.url, nil))
+		if rr.Code != tt.wantCode || rr.Header().Get("Location") != tt.wantToURL {
+			t.Errorf("%s %s: expected %d to %s, got %d to %s", tt.method, tt.url, tt.wantCode, tt.wantToURL, rr.Code, rr.Header().Get("Location"))
+		}
+	}
+}
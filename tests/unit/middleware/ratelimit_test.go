@@ -0,0 +1,120 @@
+package middleware_test
+
+import (
+	"backend/middleware"
+	"backend/services/ratelimit"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// limiterFunc adapts a function to ratelimit.Limiter
+type limiterFunc func(key string, limit int, window time.Duration) (ratelimit.Result, error)
+
+func (f limiterFunc) Allow(key string, limit int, window time.Duration) (ratelimit.Result, error) {
+	return f(key, limit, window)
+}
+
+func serveRateLimited(limiter ratelimit.Limiter, rules []ratelimit.Rule, req *http.Request) *httptest.ResponseRecorder {
+	handler := middleware.RateLimit(limiter, rules)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestRateLimit_RejectsOverLimit(t *testing.T) {
+	rules, _ := ratelimit.ParseRules("POST /api/chat=2/1m")
+	limiter := ratelimit.NewMemory()
+
+	for i := 0; i < 2; i++ {
+		rr := serveRateLimited(limiter, rules, httptest.NewRequest("POST", "/api/chat", nil))
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected request %d to pass, got %d", i+1, rr.Code)
+		}
+		if rr.Header().Get("X-RateLimit-Limit") != "2" {
+			t.Errorf("Expected X-RateLimit-Limit 2, got %q", rr.Header().Get("X-RateLimit-Limit"))
+		}
+	}
+
+	rr := serveRateLimited(limiter, rules, httptest.NewRequest("POST", "/api/chat", nil))
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", rr.Code)
+	}
+	if rr.Header().Get("Retry-After") == "" || rr.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("Expected Retry-After and no remaining requests, got headers %v", rr.Header())
+	}
+
+	// Unmatched routes are not limited
+	rr = serveRateLimited(limiter, rules, httptest.NewRequest("GET", "/api/chat", nil))
+	if rr.Code != http.StatusNoContent || rr.Header().Get("X-RateLimit-Limit") != "" {
+		t.Errorf("Expected unmatched routes to pass unlimited, got %d", rr.Code)
+	}
+}
+
+func TestRateLimit_KeysByUserThenIP(t *testing.T) {
+	rules, _ := ratelimit.ParseRules("/api/*=10/1m")
+	var keys []string
+	limiter := limiterFunc(func(key string, limit int, window time.Duration) (ratelimit.Result, error) {
+		keys = append(keys, key)
+		return ratelimit.Result{Allowed: true, Limit: limit, Remaining: limit - 1}, nil
+	})
+	serve := func(req *http.Request) {
+		handler := middleware.RateLimit(limiter, rules, middleware.StaticKeys{"secret"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	req := httptest.NewRequest("GET", "/api/trending", nil)
+	req.Header.Set("X-User-ID", "alice")
+	req.Header.Set("X-API-Key", "secret")
+	serve(req)
+
+	req = httptest.NewRequest("GET", "/api/trending", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	serve(req)
+
+	// Without a valid API key, X-User-ID is ignored
+	req = httptest.NewRequest("GET", "/api/trending", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("X-User-ID", "alice")
+	req.Header.Set("X-API-Key", "wrong")
+	serve(req)
+
+	want := []string{" /api/*|user:alice", " /api/*|ip:203.0.113.7", " /api/*|ip:203.0.113.7"}
+	if len(keys) != len(want) || keys[0] != want[0] || keys[1] != want[1] || keys[2] != want[2] {
+		t.Errorf("Unexpected limiter keys: %q", keys)
+	}
+}
+
+func TestRateLimit_RotatingUserIDDoesNotReset(t *testing.T) {
+	rules, _ := ratelimit.ParseRules("POST /api/chat=2/1m")
+	limiter := ratelimit.NewMemory()
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("POST", "/api/chat", nil)
+		req.Header.Set("X-User-ID", fmt.Sprintf("user-%d", i))
+		rr := serveRateLimited(limiter, rules, req)
+		if i < 2 && rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected request %d to pass, got %d", i+1, rr.Code)
+		}
+		if i == 2 && rr.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected a new X-User-ID without an API key to stay limited, got %d", rr.Code)
+		}
+	}
+}
+
+func TestRateLimit_FailsOpen(t *testing.T) {
+	rules, _ := ratelimit.ParseRules("/api/*=10/1m")
+	limiter := limiterFunc(func(string, int, time.Duration) (ratelimit.Result, error) {
+		return ratelimit.Result{}, errors.New("redis unavailable")
+	})
+
+	rr := serveRateLimited(limiter, rules, httptest.NewRequest("GET", "/api/trending", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("Expected requests to pass when the limiter fails, got %d", rr.Code)
+	}
+}
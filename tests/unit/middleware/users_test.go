@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"backend/middleware"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// registrarFunc adapts a function to middleware.UserRegistrar
+type registrarFunc func(userID string) error
+
+func (f registrarFunc) Register(userID string) error {
+	return f(userID)
+}
+
+func TestRegisterUsers_RegistersIdentifiedUsers(t *testing.T) {
+	var registered []string
+	registrar := registrarFunc(func(userID string) error {
+		registered = append(registered, userID)
+		if userID == "broken" {
+			return errors.New("database down")
+		}
+		return nil
+	})
+	handler := middleware.RegisterUsers(registrar)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(req *http.Request) int {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	req := httptest.NewRequest("GET", "/api/users/me", nil)
+	req.Header.Set("X-User-ID", " alice@example.com ")
+	if code := serve(req); code != http.StatusNoContent {
+		t.Errorf("Expected the request to be passed on, got %d", code)
+	}
+
+	// Anonymous requests aren't registered
+	if code := serve(httptest.NewRequest("GET", "/api/users/me", nil)); code != http.StatusNoContent {
+		t.Errorf("Expected the request to be passed on, got %d", code)
+	}
+
+	// Browsers name their user in the query on WebSocket upgrades
+	req = httptest.NewRequest("GET", "/api/ws/chat?user_id=bob", nil)
+	req.Header.Set("Upgrade", "websocket")
+	serve(req)
+
+	// Failing to register doesn't fail the request
+	req = httptest.NewRequest("GET", "/api/users/me", nil)
+	req.Header.Set("X-User-ID", "broken")
+	if code := serve(req); code != http.StatusNoContent {
+		t.Errorf("Expected the request to be passed on despite the error, got %d", code)
+	}
+
+	if len(registered) != 3 || registered[0] != "alice@example.com" || registered[1] != "bob" || registered[2] != "broken" {
+		t.Errorf("Expected alice, bob and broken to be registered, got %v", registered)
+	}
+}
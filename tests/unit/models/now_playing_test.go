@@ -153,4 +153,27 @@ func TestNowPlaying_ConcurrentAccess(t *testing.T) {
 			t.Fatal("Timeout waiting for goroutines")
 		}
 	}
-}
\ No newline at end of file
+}
+func TestNowPlaying_StopIfStale(t *testing.T) {
+	np := models.NewNowPlaying()
+	np.Update(models.SpotifyTrack{ID: "test123", Name: "Numb", Artist: "Linkin Park"})
+	
+	if np.StopIfStale(time.Hour) {
+		t.Error("Expected a fresh track not to be stopped")
+	}
+	
+	time.Sleep(10 * time.Millisecond)
+	if !np.StopIfStale(5 * time.Millisecond) {
+		t.Fatal("Expected a stale track to be stopped")
+	}
+	
+	if !np.IsEmpty() {
+		t.Error("Expected no track after expiry")
+	}
+	if state := np.Get().State; state != models.PlaybackStopped {
+		t.Errorf("Expected state %q, got %q", models.PlaybackStopped, state)
+	}
+	if np.StopIfStale(0) {
+		t.Error("Expected nothing to expire without a track")
+	}
+}
@@ -167,4 +167,46 @@ func TestPlayHistory_ConcurrentAccess(t *testing.T) {
 	if len(items) == 0 {
 		t.Error("Should have some items after concurrent operations")
 	}
-}
\ No newline at end of file
+}
+func TestPlayHistory_CollapsesRepeats(t *testing.T) {
+	ph := models.NewPlayHistory(10)
+	ph.SetRepeatWindow(30 * time.Minute)
+
+	start := time.Now()
+	track := models.UnifiedTrack{ID: "track1", Name: "Numb", Artist: "Linkin Park", Duration: 185}
+
+	ph.AddPlayed(track, start, 0)
+	ph.AddPlayed(track, start.Add(10*time.Second), 0)  // Re-sync
+	ph.AddPlayed(track, start.Add(185*time.Second), 0) // Loop
+	ph.AddPlayed(track, start.Add(370*time.Second), 0) // Loop
+
+	items := ph.GetItems()
+	if len(items) != 1 {
+		t.Fatalf("Expected repeats collapsed into one item, got %d", len(items))
+	}
+	if items[0].PlayCount != 3 || !items[0].PlayedAt.Equal(start) || !items[0].LastPlayedAt.Equal(start.Add(370*time.Second)) {
+		t.Errorf("Expected 3 plays ending with the last loop, got %+v", items[0])
+	}
+
+	// Repeats outside the window or after another track are new items
+	ph.AddPlayed(track, start.Add(time.Hour), 0)
+	ph.AddPlayed(models.UnifiedTrack{ID: "track2", Name: "Faint"}, start.Add(time.Hour+time.Minute), 0)
+	ph.AddPlayed(track, start.Add(time.Hour+2*time.Minute), 0)
+
+	items = ph.GetItems()
+	if len(items) != 4 || items[0].PlayCount != 1 {
+		t.Errorf("Expected separate items for non-consecutive repeats, got %+v", items)
+	}
+}
+
+func TestPlayHistory_RepeatWindowDisabled(t *testing.T) {
+	ph := models.NewPlayHistory(10)
+	track := models.UnifiedTrack{ID: "track1", Name: "Numb"}
+
+	ph.AddUnified(track)
+	ph.AddUnified(track)
+
+	if items := ph.GetItems(); len(items) != 2 {
+		t.Errorf("Expected every repeat to be added without a window, got %d items", len(items))
+	}
+}
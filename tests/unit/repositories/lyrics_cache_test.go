@@ -0,0 +1,95 @@
+package repositories_test
+
+import (
+	"backend/repositories"
+	"backend/server/models"
+	"backend/tests/mocks"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingGenius returns a MockGeniusService counting lyrics fetches per track
+func countingGenius() (*mocks.MockGeniusService, func(track string) int) {
+	var mutex sync.Mutex
+	fetches := make(map[string]int)
+	genius := &mocks.MockGeniusService{
+		GetLyricsFunc: func(trackName, artistName string) (string, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			fetches[trackName]++
+			return "Lyrics of " + trackName, nil
+		},
+	}
+	return genius, func(track string) int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return fetches[track]
+	}
+}
+
+func playAndFetchLyrics(t *testing.T, repo *repositories.MemoryMusicRepository, name string) {
+	t.Helper()
+	repo.StopNowPlaying() // A re-sync of the current track would keep its lyrics
+	repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: name, Name: name, Artist: "Linkin Park", Source: "spotify"})
+	if _, err := repo.GetLyricsForCurrentSong(); err != nil {
+		t.Fatalf("Failed to get lyrics for %s: %v", name, err)
+	}
+}
+
+func TestMusicRepository_LyricsCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	genius, fetches := countingGenius()
+	repo := repositories.NewMusicRepository(genius)
+	repo.SetLyricsCacheLimits(2, time.Hour)
+
+	playAndFetchLyrics(t, repo, "Numb")
+	playAndFetchLyrics(t, repo, "Faint")
+	playAndFetchLyrics(t, repo, "Numb") // Numb is now the most recently used
+	playAndFetchLyrics(t, repo, "Papercut")
+
+	if fetches("Numb") != 1 {
+		t.Errorf("Expected Numb's lyrics to be served from the cache, got %d fetches", fetches("Numb"))
+	}
+
+	playAndFetchLyrics(t, repo, "Numb")
+	playAndFetchLyrics(t, repo, "Faint")
+	if fetches("Numb") != 1 || fetches("Faint") != 2 {
+		t.Errorf("Expected only Faint to be evicted, got %d and %d fetches", fetches("Numb"), fetches("Faint"))
+	}
+}
+
+func TestMusicRepository_LyricsCacheExpires(t *testing.T) {
+	genius, fetches := countingGenius()
+	repo := repositories.NewMusicRepository(genius)
+	repo.SetLyricsCacheLimits(10, 20*time.Millisecond)
+
+	playAndFetchLyrics(t, repo, "Numb")
+	playAndFetchLyrics(t, repo, "Numb")
+	if fetches("Numb") != 1 {
+		t.Fatalf("Expected cached lyrics before they expire, got %d fetches", fetches("Numb"))
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	playAndFetchLyrics(t, repo, "Numb")
+	if fetches("Numb") != 2 {
+		t.Errorf("Expected expired lyrics to be fetched again, got %d fetches", fetches("Numb"))
+	}
+}
+
+func TestMusicRepository_LyricsCacheConcurrentAccess(t *testing.T) {
+	genius, _ := countingGenius()
+	repo := repositories.NewMusicRepository(genius)
+	repo.SetLyricsCacheLimits(3, time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := string(rune('A' + i%5))
+			repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: name, Name: name, Artist: "Linkin Park", Source: "spotify"})
+			repo.GetLyricsForCurrentSong()
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,70 @@
+package repositories_test
+
+import (
+	"backend/repositories"
+	"backend/server/models"
+	"errors"
+	"testing"
+)
+
+func TestMoodCatalog_SeedsEmptyStore(t *testing.T) {
+	store := repositories.NewMemoryMoodSuggestionStore()
+	catalog, err := repositories.NewStoredMoodCatalog(store, map[string][]models.MoodBasedRecommendation{
+		"sad": {
+			{Track: models.UnifiedTrack{ID: "t1", Name: "Hurt"}, MoodScore: 0.8},
+			{Track: models.UnifiedTrack{ID: "t2", Name: "Numb"}, MoodScore: 0.9},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	suggestions, ok := catalog.GetSuggestions("sad")
+	if !ok || len(suggestions) != 2 || suggestions[0].Track.ID != "t2" {
+		t.Errorf("Expected both seeded suggestions, best first, got %+v", suggestions)
+	}
+
+	// A store that already holds suggestions is left as curated
+	catalog.Delete(catalog.List("sad")[0].ID)
+	reloaded, _ := repositories.NewStoredMoodCatalog(store, repositories.DefaultMoodCatalog())
+	if all := reloaded.List(""); len(all) != 1 || all[0].Track.ID != "t2" {
+		t.Errorf("Expected only the remaining suggestion, got %+v", all)
+	}
+}
+
+func TestMoodCatalog_Changes(t *testing.T) {
+	store := repositories.NewMemoryMoodSuggestionStore()
+	catalog, _ := repositories.NewStoredMoodCatalog(store, nil)
+
+	added, err := catalog.Add(models.MoodSuggestion{Mood: "angry", Track: models.UnifiedTrack{ID: "t1", Name: "Given Up"}, MoodScore: 0.7})
+	if err != nil || added.ID == 0 {
+		t.Fatalf("Expected the suggestion to get an ID, got %+v, %v", added, err)
+	}
+
+	added.MoodScore = 0.95
+	if _, err := catalog.Update(added); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := catalog.Update(models.MoodSuggestion{ID: 99, Mood: "angry"}); !errors.Is(err, repositories.ErrMoodSuggestionNotFound) {
+		t.Errorf("Expected ErrMoodSuggestionNotFound, got %v", err)
+	}
+
+	// Validation fixes are written to the store
+	if replaced := catalog.ReplaceTrack("t1", models.UnifiedTrack{ID: "t1-fixed", Name: "Given Up"}); replaced != 1 {
+		t.Errorf("Expected one replaced entry, got %d", replaced)
+	}
+	stored, _ := store.List()
+	if len(stored) != 1 || stored[0].Track.ID != "t1-fixed" || stored[0].MoodScore != 0.95 {
+		t.Errorf("Expected the changes in the store, got %+v", stored)
+	}
+
+	if err := catalog.Delete(added.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := catalog.GetSuggestions("angry"); ok {
+		t.Error("Expected no suggestions after the delete")
+	}
+	if err := catalog.Delete(added.ID); !errors.Is(err, repositories.ErrMoodSuggestionNotFound) {
+		t.Errorf("Expected ErrMoodSuggestionNotFound, got %v", err)
+	}
+}
@@ -261,4 +261,35 @@ func TestMusicRepository_PlayHistory(t *testing.T) {
 	if history[2].Source != "spotify" {
 		t.Errorf("Expected last item source to be spotify, got %s", history[2].Source)
 	}
-}
\ No newline at end of file
+}
+func TestMusicRepository_ResyncIsNotATrackChange(t *testing.T) {
+	repo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	changes := 0
+	repo.AddTrackListener(func(track models.UnifiedTrack) { changes++ })
+	track := models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"}
+
+	repo.UpdateNowPlayingUnified(track)
+	version := repo.GetNowPlaying().Version
+	repo.UpdateNowPlayingUnified(track)
+	if !repo.UpdateNowPlayingIfVersion(track, version) {
+		t.Fatal("Expected a re-sync with the current version to be accepted")
+	}
+	repo.UpdateNowPlayingUnified(track)
+
+	if changes != 1 {
+		t.Errorf("Expected one track change event, got %d", changes)
+	}
+	if current := repo.GetNowPlaying(); current.Version != version {
+		t.Errorf("Expected re-syncs to keep version %d, got %d", version, current.Version)
+	}
+	if history := repo.GetPlayHistory(); len(history) != 1 {
+		t.Errorf("Expected one history row, got %+v", history)
+	}
+
+	// Changed details of the same track are a new version, but not a new play
+	track.Album = "Meteora"
+	repo.UpdateNowPlayingUnified(track)
+	if current := repo.GetNowPlaying(); current.Version == version || changes != 1 {
+		t.Errorf("Expected a new version without a track change, got version %d and %d changes", current.Version, changes)
+	}
+}
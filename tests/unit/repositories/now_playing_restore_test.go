@@ -0,0 +1,59 @@
+package repositories_test
+
+import (
+	"backend/repositories"
+	"backend/server/models"
+	"backend/tests/mocks"
+	"testing"
+	"time"
+)
+
+func TestMusicRepository_RestoreNowPlaying(t *testing.T) {
+	store := repositories.NewMemoryNowPlayingStore()
+
+	repo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	if restored, err := repo.RestoreNowPlaying(store, time.Minute); restored || err != nil {
+		t.Fatalf("Expected nothing to restore from an empty store, got %v, %v", restored, err)
+	}
+	repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+	repo.Heartbeat("sp1", 30000, 185000)
+	repo.SetPlaybackState(models.PlaybackPaused)
+	before := repo.GetNowPlaying()
+
+	// A new repository, as after a restart, picks up where the last one left off
+	restartedRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	restored, err := restartedRepo.RestoreNowPlaying(store, time.Minute)
+	if !restored || err != nil {
+		t.Fatalf("Expected the track to be restored, got %v, %v", restored, err)
+	}
+
+	after := restartedRepo.GetNowPlaying()
+	if after.TrackID != "sp1" || after.State != models.PlaybackPaused || after.Version != before.Version || after.PositionMs != 30000 || after.DurationMs != 185000 {
+		t.Errorf("Expected the saved state to be restored, got %+v", &after)
+	}
+	if restartedRepo.IsPlaying() || !restartedRepo.HasCurrentTrack() {
+		t.Error("Expected the restored track to be loaded and paused")
+	}
+
+	// Stopping is saved too
+	restartedRepo.StopNowPlaying()
+	if restored, _ := repositories.NewMusicRepository(&mocks.MockGeniusService{}).RestoreNowPlaying(store, time.Minute); restored {
+		t.Error("Expected nothing to be restored after playback stopped")
+	}
+}
+
+func TestMusicRepository_RestoreNowPlaying_Stale(t *testing.T) {
+	store := repositories.NewMemoryNowPlayingStore()
+	repo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	repo.RestoreNowPlaying(store, time.Minute)
+	repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+
+	time.Sleep(20 * time.Millisecond)
+	restartedRepo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	if restored, _ := restartedRepo.RestoreNowPlaying(store, 10*time.Millisecond); restored {
+		t.Error("Expected a track older than maxAge not to be restored")
+	}
+	if restartedRepo.HasCurrentTrack() {
+		t.Error("Expected nothing to be playing")
+	}
+}
@@ -0,0 +1,110 @@
+package repositories_test
+
+import (
+	"backend/repositories"
+	"backend/server/models"
+	"backend/tests/mocks"
+	"testing"
+	"time"
+)
+
+func TestMusicRepository_ScrobblePolicy_Heartbeats(t *testing.T) {
+	repo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	repo.SetScrobblePolicy(repositories.ScrobblePolicy{Fraction: 0.5, MaxListen: 4 * time.Minute})
+
+	repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+	if history := repo.GetPlayHistory(); len(history) != 0 {
+		t.Fatalf("Expected the track to be held back until it has played, got %d items", len(history))
+	}
+
+	// 40ms of a 100ms track is not enough, 60ms is
+	repo.Heartbeat("sp1", 0, 100)
+	time.Sleep(40 * time.Millisecond)
+	repo.Heartbeat("sp1", 40, 100)
+	if history := repo.GetPlayHistory(); len(history) != 0 {
+		t.Fatalf("Expected the track to be held back below half its duration, got %d items", len(history))
+	}
+	time.Sleep(20 * time.Millisecond)
+	repo.Heartbeat("sp1", 60, 100)
+
+	history := repo.GetPlayHistory()
+	if len(history) != 1 || history[0].TrackID != "sp1" || history[0].ListenedMs != 60 {
+		t.Fatalf("Expected the track once it passed half its duration, got %+v", history)
+	}
+
+	// Later heartbeats keep updating the listening time
+	time.Sleep(20 * time.Millisecond)
+	repo.Heartbeat("sp1", 80, 100)
+	if history := repo.GetPlayHistory(); len(history) != 1 || history[0].ListenedMs != 80 {
+		t.Errorf("Expected the listening time to keep updating, got %+v", history)
+	}
+}
+
+func TestMusicRepository_ScrobblePolicy_SkippedTracks(t *testing.T) {
+	repo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	repo.SetScrobblePolicy(repositories.ScrobblePolicy{Fraction: 0.5, MaxListen: 30 * time.Millisecond})
+
+	// A skipped track with heartbeats is left out
+	repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp1", Name: "Numb", Source: "spotify", Duration: 180})
+	repo.Heartbeat("sp1", 0, 0)
+	repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp2", Name: "Faint", Source: "spotify", Duration: 180})
+
+	// Without heartbeats, a track counts as played until the next one
+	time.Sleep(40 * time.Millisecond)
+	repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp3", Name: "In the End", Source: "spotify", Duration: 180})
+
+	// Stopping a track that was just started leaves it out
+	repo.StopNowPlaying()
+
+	history := repo.GetPlayHistory()
+	if len(history) != 1 || history[0].TrackID != "sp2" {
+		t.Errorf("Expected only the track that played past MaxListen, got %+v", history)
+	}
+}
+
+func TestMusicRepository_ScrobblePolicy_Disabled(t *testing.T) {
+	repo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+
+	repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp1", Name: "Numb", Source: "spotify"})
+	repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp2", Name: "Faint", Source: "spotify"})
+
+	if history := repo.GetPlayHistory(); len(history) != 2 {
+		t.Errorf("Expected every track to be added as it starts, got %d items", len(history))
+	}
+}
+
+func TestMusicRepository_ScrobblePolicy_ResyncKeepsPendingPlay(t *testing.T) {
+	repo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	repo.SetScrobblePolicy(repositories.ScrobblePolicy{Fraction: 0.5, MaxListen: 4 * time.Minute})
+	track := models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify", Duration: 1}
+
+	// A client re-posting the same one second track every 200ms
+	repo.UpdateNowPlayingUnified(track)
+	repo.Heartbeat("sp1", 0, 1000)
+	for i := 0; i < 4; i++ {
+		time.Sleep(200 * time.Millisecond)
+		repo.UpdateNowPlayingUnified(track)
+	}
+	if current := repo.GetNowPlaying(); current.PositionAt.IsZero() || current.DurationMs != 1000 {
+		t.Errorf("Expected a re-sync to keep the playback progress, got position at %v of %dms", current.PositionAt, current.DurationMs)
+	}
+	repo.Heartbeat("sp1", 800, 1000)
+
+	history := repo.GetPlayHistory()
+	if len(history) != 1 || history[0].TrackID != "sp1" || history[0].ListenedMs < 500 {
+		t.Errorf("Expected the track once it played past half its duration, got %+v", history)
+	}
+
+	// Without heartbeats, the play counts from the first post
+	repo = repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	repo.SetScrobblePolicy(repositories.ScrobblePolicy{Fraction: 0.5, MaxListen: 4 * time.Minute})
+	repo.UpdateNowPlayingUnified(track)
+	for i := 0; i < 4; i++ {
+		time.Sleep(200 * time.Millisecond)
+		repo.UpdateNowPlayingUnified(track)
+	}
+	repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp2", Name: "Faint", Source: "spotify", Duration: 1})
+	if history := repo.GetPlayHistory(); len(history) != 1 || history[0].TrackID != "sp1" {
+		t.Errorf("Expected the re-synced track in the history, got %+v", history)
+	}
+}
@@ -0,0 +1,270 @@
+package repositories_test
+
+import (
+	"backend/repositories"
+	"backend/server/models"
+	"backend/tests/mocks"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis implements the Redis commands used by the shared state stores.
+// EVAL runs the Go equivalent of the stores' compare-and-set scripts.
+type fakeRedis struct {
+	mutex   sync.Mutex
+	strings map[string]string
+	lists   map[string][]string
+
+	// beforeEval, if set, is called once before the next script runs, e.g.
+	// to let another replica write in between
+	beforeEval func()
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{strings: make(map[string]string), lists: make(map[string][]string)}
+}
+
+func (f *fakeRedis) Do(args ...string) (interface{}, error) {
+	if args[0] == "EVAL" {
+		return f.eval(args[1], args[3], args[4:])
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	key := args[1]
+	list := f.lists[key]
+	index := func(i int) int {
+		n, _ := strconv.Atoi(args[i])
+		if n < 0 {
+			n += len(list)
+		}
+		return n
+	}
+	switch args[0] {
+	case "SET":
+		f.strings[key] = args[2]
+		return "OK", nil
+	case "GET":
+		if value, ok := f.strings[key]; ok {
+			return value, nil
+		}
+		return nil, nil
+	case "LPUSH":
+		f.lists[key] = append([]string{args[2]}, list...)
+		return int64(len(f.lists[key])), nil
+	case "LTRIM":
+		if stop := index(3) + 1; stop < len(list) {
+			f.lists[key] = list[:stop]
+		}
+		return "OK", nil
+	case "LINDEX":
+		if i := index(2); i < len(list) {
+			return list[i], nil
+		}
+		return nil, nil
+	case "LSET":
+		list[index(2)] = args[3]
+		return "OK", nil
+	case "LRANGE":
+		values := []interface{}{}
+		for i := index(2); i <= index(3) && i < len(list); i++ {
+			values = append(values, list[i])
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("unsupported command %s", args[0])
+}
+
+func (f *fakeRedis) eval(script, key string, argv []string) (interface{}, error) {
+	f.mutex.Lock()
+	hook := f.beforeEval
+	f.beforeEval = nil
+	f.mutex.Unlock()
+	if hook != nil {
+		hook()
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !strings.Contains(script, "LINDEX") {
+		if f.strings[key] != argv[0] {
+			return int64(0), nil
+		}
+		f.strings[key] = argv[1]
+		return int64(1), nil
+	}
+
+	list := f.lists[key]
+	latest := ""
+	if len(list) > 0 {
+		latest = list[0]
+	}
+	if latest != argv[0] {
+		return int64(0), nil
+	}
+	if argv[2] == "push" {
+		size, _ := strconv.Atoi(argv[3])
+		list = append([]string{argv[1]}, list...)
+		if len(list) > size {
+			list = list[:size]
+		}
+		f.lists[key] = list
+	} else {
+		list[0] = argv[1]
+	}
+	return int64(1), nil
+}
+
+func newSharedRepository(redis *fakeRedis) *repositories.MemoryMusicRepository {
+	repo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	repo.SetHistoryStore(repositories.NewRedisHistoryStore(redis, "test:", 3, 0))
+	repo.ShareNowPlaying(repositories.NewRedisNowPlayingStore(redis, "test:"))
+	return repo
+}
+
+func TestMusicRepository_ShareNowPlaying(t *testing.T) {
+	redis := newFakeRedis()
+	first := newSharedRepository(redis)
+	second := newSharedRepository(redis)
+
+	first.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+	if current := second.GetNowPlaying(); current.TrackID != "sp1" || !second.IsPlaying() {
+		t.Fatalf("Expected the other replica to see the new track, got %+v", &current)
+	}
+
+	// Updates through either replica apply to the shared state
+	second.SetPlaybackState(models.PlaybackPaused)
+	if first.IsPlaying() || !first.HasCurrentTrack() {
+		t.Error("Expected the pause to be seen by the first replica")
+	}
+	current := first.GetNowPlaying()
+	if !second.UpdateNowPlayingIfVersion(models.UnifiedTrack{ID: "sp2", Name: "Faint", Artist: "Linkin Park", Source: "spotify"}, current.Version) {
+		t.Fatal("Expected the version read from one replica to be accepted by the other")
+	}
+	if first.GetCurrentSongInfo() != second.GetCurrentSongInfo() {
+		t.Errorf("Expected both replicas to describe the same song, got %q and %q", first.GetCurrentSongInfo(), second.GetCurrentSongInfo())
+	}
+
+	first.StopNowPlaying()
+	if second.HasCurrentTrack() {
+		t.Error("Expected the stop to be seen by the other replica")
+	}
+
+	history := second.GetPlayHistory()
+	if len(history) != 2 || history[0].TrackID != "sp2" || history[1].TrackID != "sp1" {
+		t.Errorf("Expected both replicas' plays in the shared history, got %+v", history)
+	}
+}
+
+func TestRedisHistoryStore_KeepsLatest(t *testing.T) {
+	store := repositories.NewRedisHistoryStore(newFakeRedis(), "test:", 2, 0)
+	for _, id := range []string{"sp1", "sp2", "sp3"} {
+		if err := store.Add(models.UnifiedTrack{ID: id, Name: id, Artist: "Linkin Park", Source: "spotify"}, time.Now(), 0); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := store.UpdateListened("sp3", 1500); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	items, total, err := store.Query(models.PlayHistoryFilter{}, 0, 10)
+	if err != nil || total != 2 || len(items) != 2 || items[0].TrackID != "sp3" || items[0].ListenedMs != 1500 || items[1].TrackID != "sp2" {
+		t.Errorf("Expected the latest two plays, got %+v, %d, %v", items, total, err)
+	}
+}
+
+func TestMusicRepository_ShareNowPlaying_ConcurrentIfVersion(t *testing.T) {
+	redis := newFakeRedis()
+	first := newSharedRepository(redis)
+	second := newSharedRepository(redis)
+
+	first.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+	version := first.GetNowPlaying().Version
+
+	// The second replica saves between the first one's check and save
+	var secondUpdated bool
+	redis.beforeEval = func() {
+		secondUpdated = second.UpdateNowPlayingIfVersion(models.UnifiedTrack{ID: "sp3", Name: "In the End", Artist: "Linkin Park", Source: "spotify"}, version)
+	}
+	firstUpdated := first.UpdateNowPlayingIfVersion(models.UnifiedTrack{ID: "sp2", Name: "Faint", Artist: "Linkin Park", Source: "spotify"}, version)
+
+	if !secondUpdated || firstUpdated {
+		t.Fatalf("Expected only the first save at the version to succeed, got %v and %v", secondUpdated, firstUpdated)
+	}
+	if current := first.GetNowPlaying(); current.TrackID != "sp3" {
+		t.Errorf("Expected the second replica's track, got %s", current.TrackID)
+	}
+	history := first.GetPlayHistory()
+	if len(history) != 2 || history[0].TrackID != "sp3" || history[1].TrackID != "sp1" {
+		t.Errorf("Expected the rejected track to be left out of the history, got %+v", history)
+	}
+}
+
+func TestMusicRepository_ShareNowPlaying_RetriesConflicts(t *testing.T) {
+	redis := newFakeRedis()
+	first := newSharedRepository(redis)
+	second := newSharedRepository(redis)
+
+	first.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+
+	// An update overtaken by another replica is applied again on top of it
+	redis.beforeEval = func() {
+		second.SetPlaybackState(models.PlaybackPaused)
+	}
+	first.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp2", Name: "Faint", Artist: "Linkin Park", Source: "spotify"})
+
+	if current := second.GetNowPlaying(); current.TrackID != "sp2" || current.State != models.PlaybackPlaying {
+		t.Errorf("Expected the retried update to win, got %s %s", current.TrackID, current.State)
+	}
+	history := second.GetPlayHistory()
+	if len(history) != 2 || history[0].TrackID != "sp2" {
+		t.Errorf("Expected the track to be added once, got %+v", history)
+	}
+}
+
+func TestMusicRepository_ShareNowPlaying_PendingPlay(t *testing.T) {
+	redis := newFakeRedis()
+	first := newSharedRepository(redis)
+	second := newSharedRepository(redis)
+	for _, repo := range []*repositories.MemoryMusicRepository{first, second} {
+		repo.SetScrobblePolicy(repositories.ScrobblePolicy{Fraction: 0.5, MaxListen: 30 * time.Millisecond})
+	}
+
+	// A track started on one replica and replaced on another still counts
+	first.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify", Duration: 180})
+	time.Sleep(40 * time.Millisecond)
+	second.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp2", Name: "Faint", Artist: "Linkin Park", Source: "spotify", Duration: 180})
+
+	history := first.GetPlayHistory()
+	if len(history) != 1 || history[0].TrackID != "sp1" {
+		t.Errorf("Expected the track finished by the other replica in the history, got %+v", history)
+	}
+}
+
+func TestRedisHistoryStore_ConcurrentRepeat(t *testing.T) {
+	redis := newFakeRedis()
+	first := repositories.NewRedisHistoryStore(redis, "test:", 10, time.Hour)
+	second := repositories.NewRedisHistoryStore(redis, "test:", 10, time.Hour)
+	track := models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"}
+
+	// Both replicas record the same play; the one adding second must see the first's item
+	playedAt := time.Now()
+	redis.beforeEval = func() {
+		if err := second.Add(track, playedAt, 0); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+	if err := first.Add(track, playedAt, 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	items, err := first.Recent()
+	if err != nil || len(items) != 1 || items[0].PlayCount != 1 {
+		t.Errorf("Expected one item for the repeated play, got %+v, %v", items, err)
+	}
+}
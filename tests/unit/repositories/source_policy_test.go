@@ -0,0 +1,78 @@
+package repositories_test
+
+import (
+	"backend/repositories"
+	"backend/server/models"
+	"backend/tests/mocks"
+	"testing"
+	"time"
+)
+
+func TestMusicRepository_SourcePolicy_MinDwell(t *testing.T) {
+	repo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	repo.SetSourcePolicy(repositories.SourcePolicy{MinDwell: 50 * time.Millisecond})
+
+	spotifyTrack := models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"}
+	youtubeTrack := models.UnifiedTrack{ID: "yt1", Name: "Faint", Artist: "Linkin Park", Source: "youtube"}
+
+	if !repo.UpdateNowPlayingUnified(spotifyTrack) {
+		t.Fatal("Expected first update to be accepted")
+	}
+
+	// Interleaved update from another source within the dwell time is rejected
+	if repo.UpdateNowPlayingUnified(youtubeTrack) {
+		t.Error("Expected competing source to be rejected within dwell time")
+	}
+
+	// The current source can keep updating
+	spotifyNext := models.UnifiedTrack{ID: "sp2", Name: "In the End", Artist: "Linkin Park", Source: "spotify"}
+	if !repo.UpdateNowPlayingUnified(spotifyNext) {
+		t.Error("Expected current source to keep updating")
+	}
+	if repo.GetNowPlaying().TrackID != "sp2" {
+		t.Errorf("Expected sp2 to be playing, got %s", repo.GetNowPlaying().TrackID)
+	}
+
+	// Once the current source goes quiet, the other source takes over
+	time.Sleep(60 * time.Millisecond)
+	if !repo.UpdateNowPlayingUnified(youtubeTrack) {
+		t.Error("Expected competing source to take over after dwell time")
+	}
+
+	history := repo.GetPlayHistory()
+	if len(history) != 3 {
+		t.Errorf("Expected rejected update to be left out of history, got %d items", len(history))
+	}
+}
+
+func TestMusicRepository_SourcePolicy_Priority(t *testing.T) {
+	repo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+	repo.SetSourcePolicy(repositories.SourcePolicy{
+		MinDwell:   time.Hour,
+		Priorities: map[string]int{"spotify": 1},
+	})
+
+	youtubeTrack := models.UnifiedTrack{ID: "yt1", Name: "Faint", Artist: "Linkin Park", Source: "youtube"}
+	spotifyTrack := models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"}
+
+	repo.UpdateNowPlayingUnified(youtubeTrack)
+
+	if !repo.UpdateNowPlayingUnified(spotifyTrack) {
+		t.Error("Expected higher priority source to take over immediately")
+	}
+	if repo.UpdateNowPlayingUnified(youtubeTrack) {
+		t.Error("Expected lower priority source to be rejected within dwell time")
+	}
+	if repo.GetNowPlaying().Source != "spotify" {
+		t.Errorf("Expected spotify to own playback, got %s", repo.GetNowPlaying().Source)
+	}
+}
+
+func TestMusicRepository_SourcePolicy_DefaultMostRecentWins(t *testing.T) {
+	repo := repositories.NewMusicRepository(&mocks.MockGeniusService{})
+
+	repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "sp1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+	if !repo.UpdateNowPlayingUnified(models.UnifiedTrack{ID: "yt1", Name: "Faint", Artist: "Linkin Park", Source: "youtube"}) {
+		t.Error("Expected every update to be accepted without a policy")
+	}
+}
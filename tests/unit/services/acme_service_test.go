@@ -0,0 +1,377 @@
+package services_test
+
+import (
+	"backend/services/acme"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeACME is a minimal ACME server issuing certificates from a test CA. It
+// verifies JWS signatures and nonces, and validates http-01 challenges
+// against challengeHandler.
+type fakeACME struct {
+	t                *testing.T
+	server           *httptest.Server
+	challengeHandler http.Handler
+	caKey            *ecdsa.PrivateKey
+	caCert           *x509.Certificate
+
+	mutex       sync.Mutex
+	nonces      map[string]bool
+	nextNonce   int
+	accountKey  *ecdsa.PublicKey
+	thumbprint  string
+	authzStatus string
+	orderStatus string
+	certPEM     []byte
+	requests    int
+	badNonceOn  string // Path rejected once with badNonce
+}
+
+func newFakeACME(t *testing.T) *fakeACME {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	caCert, _ := x509.ParseCertificate(der)
+
+	f := &fakeACME{t: t, caKey: caKey, caCert: caCert, nonces: map[string]bool{}, authzStatus: "pending", orderStatus: "pending"}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeACME) serve(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.requests++
+
+	base := f.server.URL
+	f.nextNonce++
+	nonce := fmt.Sprintf("nonce-%d", f.nextNonce)
+	f.nonces[nonce] = true
+	w.Header().Set("Replay-Nonce", nonce)
+
+	switch r.URL.Path {
+	case "/directory":
+		json.NewEncoder(w).Encode(map[string]string{
+			"newNonce": base + "/nonce", "newAccount": base + "/account", "newOrder": base + "/order",
+		})
+		return
+	case "/nonce":
+		return
+	}
+
+	payload, ok := f.verify(r)
+	if !ok {
+		http.Error(w, `{"type":"urn:ietf:params:acme:error:malformed"}`, http.StatusBadRequest)
+		return
+	}
+	if f.badNonceOn == r.URL.Path {
+		f.badNonceOn = ""
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"type":"urn:ietf:params:acme:error:badNonce","detail":"stale"}`))
+		return
+	}
+
+	order := func() map[string]interface{} {
+		o := map[string]interface{}{
+			"status":         f.orderStatus,
+			"authorizations": []string{base + "/authz/1"},
+			"finalize":       base + "/finalize/1",
+		}
+		if f.orderStatus == "valid" {
+			o["certificate"] = base + "/cert/1"
+		}
+		return o
+	}
+
+	switch r.URL.Path {
+	case "/account":
+		w.Header().Set("Location", base+"/account/1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"status":"valid"}`))
+	case "/order":
+		w.Header().Set("Location", base+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(order())
+	case "/order/1":
+		json.NewEncoder(w).Encode(order())
+	case "/authz/1":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":     f.authzStatus,
+			"identifier": map[string]string{"type": "dns", "value": "example.com"},
+			"challenges": []map[string]string{
+				{"type": "dns-01", "url": base + "/chall/dns", "token": "dns-token"},
+				{"type": "http-01", "url": base + "/chall/1", "token": "http-token"},
+			},
+		})
+	case "/chall/1":
+		rr := httptest.NewRecorder()
+		f.challengeHandler.ServeHTTP(rr, httptest.NewRequest("GET", "http://example.com/.well-known/acme-challenge/http-token", nil))
+		if rr.Body.String() == "http-token."+f.thumbprint {
+			f.authzStatus = "valid"
+		} else {
+			f.authzStatus = "invalid"
+		}
+		w.Write([]byte(`{"status":"processing"}`))
+	case "/finalize/1":
+		var req struct {
+			CSR string `json:"csr"`
+		}
+		json.Unmarshal(payload, &req)
+		csrDER, _ := base64.RawURLEncoding.DecodeString(req.CSR)
+		csr, err := x509.ParseCertificateRequest(csrDER)
+		if err != nil || f.authzStatus != "valid" {
+			http.Error(w, `{"type":"urn:ietf:params:acme:error:badCSR"}`, http.StatusForbidden)
+			return
+		}
+		leaf := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: csr.DNSNames[0]},
+			DNSNames:     csr.DNSNames,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		}
+		der, _ := x509.CreateCertificate(rand.Reader, leaf, f.caCert, csr.PublicKey, f.caKey)
+		f.certPEM = append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.caCert.Raw})...)
+		f.orderStatus = "processing"
+		json.NewEncoder(w).Encode(order())
+		f.orderStatus = "valid" // Ready on the next poll
+	case "/cert/1":
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		w.Write(f.certPEM)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// verify checks a JWS request's nonce and signature and returns its payload
+func (f *fakeACME) verify(r *http.Request) ([]byte, bool) {
+	var jws struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+		Signature string `json:"signature"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jws); err != nil {
+		return nil, false
+	}
+	protectedJSON, _ := base64.RawURLEncoding.DecodeString(jws.Protected)
+	var protected struct {
+		Alg   string          `json:"alg"`
+		Nonce string          `json:"nonce"`
+		URL   string          `json:"url"`
+		KID   string          `json:"kid"`
+		JWK   json.RawMessage `json:"jwk"`
+	}
+	json.Unmarshal(protectedJSON, &protected)
+	if protected.Alg != "ES256" || !f.nonces[protected.Nonce] || protected.URL != f.server.URL+r.URL.Path {
+		f.t.Errorf("Bad protected header %s", protectedJSON)
+		return nil, false
+	}
+	delete(f.nonces, protected.Nonce)
+
+	if protected.JWK != nil {
+		var jwk struct{ X, Y string }
+		json.Unmarshal(protected.JWK, &jwk)
+		x, _ := base64.RawURLEncoding.DecodeString(jwk.X)
+		y, _ := base64.RawURLEncoding.DecodeString(jwk.Y)
+		f.accountKey = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		sum := sha256.Sum256([]byte(fmt.Sprintf(`{"crv":"P-256","kty":"EC","x":"%s","y":"%s"}`, jwk.X, jwk.Y)))
+		f.thumbprint = base64.RawURLEncoding.EncodeToString(sum[:])
+	} else if protected.KID != f.server.URL+"/account/1" {
+		f.t.Errorf("Expected the account URL as kid, got %q", protected.KID)
+		return nil, false
+	}
+
+	signature, _ := base64.RawURLEncoding.DecodeString(jws.Signature)
+	digest := sha256.Sum256([]byte(jws.Protected + "." + jws.Payload))
+	if f.accountKey == nil || len(signature) != 64 ||
+		!ecdsa.Verify(f.accountKey, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+		f.t.Errorf("Invalid signature for %s", r.URL.Path)
+		return nil, false
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(jws.Payload)
+	return payload, true
+}
+
+func newTestACMEConfig(t *testing.T, f *fakeACME, cacheDir string) acme.Config {
+	config := acme.DefaultConfig()
+	config.DirectoryURL = f.server.URL + "/directory"
+	config.Email = "ops@example.com"
+	config.Hosts = []string{"example.com"}
+	config.CacheDir = cacheDir
+	config.PollInterval = time.Millisecond
+	return config
+}
+
+func TestACMEManager_ObtainsAndCachesCertificate(t *testing.T) {
+	f := newFakeACME(t)
+	f.badNonceOn = "/order"
+	cacheDir := t.TempDir()
+
+	manager, err := acme.New(newTestACMEConfig(t, f, cacheDir))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	f.challengeHandler = manager.HTTPHandler(http.NotFoundHandler())
+
+	cert, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	if err != nil {
+		t.Fatalf("Expected a certificate, got %v", err)
+	}
+	if cert.Leaf == nil || cert.Leaf.VerifyHostname("example.com") != nil || len(cert.Certificate) != 2 {
+		t.Fatalf("Expected a certificate chain for example.com, got %+v", cert.Leaf)
+	}
+
+	// Tokens are only served while their challenge is being validated
+	rr := httptest.NewRecorder()
+	f.challengeHandler.ServeHTTP(rr, httptest.NewRequest("GET", "/.well-known/acme-challenge/http-token", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected the challenge token to be removed, got %d", rr.Code)
+	}
+
+	// A restarted server reuses the cached certificate
+	requests := f.requests
+	restarted, err := acme.New(newTestACMEConfig(t, f, cacheDir))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	cached, err := restarted.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	if err != nil || cached.Leaf.SerialNumber.Cmp(cert.Leaf.SerialNumber) != 0 {
+		t.Errorf("Expected the cached certificate, got %v", err)
+	}
+	if f.requests != requests {
+		t.Errorf("Expected no ACME requests after a restart, got %d", f.requests-requests)
+	}
+}
+
+func TestACMEManager_RejectsUnknownHosts(t *testing.T) {
+	f := newFakeACME(t)
+	manager, err := acme.New(newTestACMEConfig(t, f, t.TempDir()))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "attacker.example"}); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("Expected unknown hosts to be rejected, got %v", err)
+	}
+	if f.requests != 0 {
+		t.Errorf("Expected no ACME requests, got %d", f.requests)
+	}
+}
+
+func TestACMEManager_FailsWhenChallengeFails(t *testing.T) {
+	f := newFakeACME(t)
+	manager, err := acme.New(newTestACMEConfig(t, f, t.TempDir()))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Challenges never reach the manager
+	f.challengeHandler = http.NotFoundHandler()
+
+	if _, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"}); err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Errorf("Expected the failed authorization to be reported, got %v", err)
+	}
+}
+
+func TestACMEManager_ServesCertificateWhileRenewalFails(t *testing.T) {
+	f := newFakeACME(t)
+	cacheDir := t.TempDir()
+	first, err := acme.New(newTestACMEConfig(t, f, cacheDir))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	f.challengeHandler = first.HTTPHandler(http.NotFoundHandler())
+	cert, err := first.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	if err != nil {
+		t.Fatalf("Expected a certificate, got %v", err)
+	}
+
+	// The cached certificate is now due for renewal, which fails
+	config := newTestACMEConfig(t, f, cacheDir)
+	config.RenewBefore = 100 * 24 * time.Hour
+	manager, err := acme.New(config)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	f.mutex.Lock()
+	f.challengeHandler = http.NotFoundHandler()
+	f.authzStatus, f.orderStatus = "pending", "pending"
+	f.mutex.Unlock()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.TLS = &tls.Config{GetCertificate: manager.GetCertificate}
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(f.caCert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{ServerName: "example.com", RootCAs: roots}}}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expected the handshake to succeed during renewal, got %v", err)
+		}
+		if peer := resp.TLS.PeerCertificates[0]; peer.SerialNumber.Cmp(cert.Leaf.SerialNumber) != 0 {
+			t.Errorf("Expected the current certificate, got serial %v", peer.SerialNumber)
+		}
+		resp.Body.Close()
+		client.CloseIdleConnections()
+	}
+
+	// Wait for the renewal to fail, then check it isn't retried right away
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		f.mutex.Lock()
+		failed := f.authzStatus == "invalid"
+		f.mutex.Unlock()
+		if failed || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	f.mutex.Lock()
+	requests := f.requests
+	f.mutex.Unlock()
+
+	if _, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"}); err != nil {
+		t.Fatalf("Expected the current certificate after the failed renewal, got %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.authzStatus != "invalid" {
+		t.Error("Expected a renewal to be attempted")
+	}
+	if f.requests != requests {
+		t.Errorf("Expected the failed renewal to back off, got %d more ACME requests", f.requests-requests)
+	}
+}
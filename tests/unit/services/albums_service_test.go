@@ -0,0 +1,94 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/albums"
+	"backend/services/mood"
+	"backend/tests/mocks"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func hybridTheory(albumID string) (*models.SpotifyAlbum, error) {
+	return &models.SpotifyAlbum{
+		ID:          albumID,
+		Name:        "Hybrid Theory",
+		Artist:      "Linkin Park",
+		ReleaseDate: "2000-10-24",
+		Images:      []models.SpotifyImage{{URL: "https://i.scdn.co/image/hybrid"}},
+		Tracks: []models.UnifiedTrack{
+			{ID: "t1", Name: "Papercut", Artist: "Linkin Park"},
+			{ID: "t2", Name: "Cure for the Itch", Artist: "Linkin Park"},
+			{ID: "t3", Name: "In the End", Artist: "Linkin Park", Explicit: true},
+		},
+	}, nil
+}
+
+func albumLyrics(trackName, artistName string) (*mood.LyricsWithMood, error) {
+	if trackName == "Cure for the Itch" {
+		return nil, errors.New("lyrics not found")
+	}
+	return &mood.LyricsWithMood{
+		Lyrics:       "Lyrics of " + trackName,
+		MoodAnalysis: &models.MoodAnalysis{PrimaryMood: "anxious"},
+		Themes:       []string{"paranoia"},
+	}, nil
+}
+
+func TestAlbums_AnalyzeAndCache(t *testing.T) {
+	var prompt string
+	var calls int32
+	ai := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(p string) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			prompt = p
+			return `{"narrative": " From paranoia to regret. ", "recurring_themes": ["paranoia", ""]}`, nil
+		},
+	}
+	service := albums.New(&mocks.MockSpotifyService{GetAlbumFunc: hybridTheory}, &mocks.MockMoodService{GetLyricsWithMoodFunc: albumLyrics}, ai, nil, albums.DefaultConfig())
+
+	if _, ok := service.Cached("hybrid"); ok {
+		t.Fatal("Expected no analysis before Analyze")
+	}
+	analysis, err := service.Analyze(context.Background(), "hybrid")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if analysis.Narrative != "From paranoia to regret." || len(analysis.RecurringThemes) != 1 || analysis.ImageURL == "" {
+		t.Errorf("Expected the parsed narrative, got %+v", analysis)
+	}
+	if len(analysis.Tracks) != 3 || analysis.Tracks[0].Mood == nil || analysis.Tracks[1].Error != "lyrics not found" || !analysis.Tracks[2].Explicit || analysis.Tracks[2].Number != 3 {
+		t.Errorf("Expected every track in album order, got %+v", analysis.Tracks)
+	}
+	for _, want := range []string{"Hybrid Theory by Linkin Park", "2000-10-24", `1. "Papercut" (mood: anxious; themes: paranoia)`, "Lyrics of In the End", "(no lyrics)"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q, got %q", want, prompt)
+		}
+	}
+
+	cached, ok := service.Cached("hybrid")
+	if !ok || cached.Narrative != analysis.Narrative {
+		t.Errorf("Expected the analysis cached, got %+v", cached)
+	}
+	service.Analyze(context.Background(), "hybrid")
+	if calls != 1 {
+		t.Errorf("Expected the cached analysis to be reused, got %d AI calls", calls)
+	}
+}
+
+func TestAlbums_NoLyrics(t *testing.T) {
+	noLyrics := func(trackName, artistName string) (*mood.LyricsWithMood, error) {
+		return nil, errors.New("lyrics not found")
+	}
+	service := albums.New(&mocks.MockSpotifyService{GetAlbumFunc: hybridTheory}, &mocks.MockMoodService{GetLyricsWithMoodFunc: noLyrics}, &mocks.MockOllamaService{}, nil, albums.DefaultConfig())
+
+	if _, err := service.Analyze(context.Background(), "hybrid"); !errors.Is(err, albums.ErrNoLyrics) {
+		t.Errorf("Expected ErrNoLyrics, got %v", err)
+	}
+	if _, ok := service.Cached("hybrid"); ok {
+		t.Error("Expected a failed analysis not to be cached")
+	}
+}
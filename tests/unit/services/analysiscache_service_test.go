@@ -0,0 +1,159 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/analysiscache"
+	"backend/tests/mocks"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalysisCache_CategorizesCommonQuestions(t *testing.T) {
+	service := analysiscache.New(analysiscache.NewMemoryStore(), nil, analysiscache.DefaultConfig())
+
+	cases := map[string]string{
+		"What does this song mean?":              "meaning",
+		"what's the song about":                  "meaning",
+		"What are the main themes?":              "themes",
+		"How does this track feel":               "mood",
+		"Tell me the story behind the song":      "story",
+		"Why was it written?":                    "inspiration",
+		"explain the symbolism in the lyrics":    "symbolism",
+		"What does the second verse mean?":       "",
+		"What does it mean and how does it feel": "",
+		"who wrote this song":                    "",
+		"":                                       "",
+	}
+	for query, expected := range cases {
+		if got := service.Category(query); got != expected {
+			t.Errorf("Category(%q) = %q, expected %q", query, got, expected)
+		}
+	}
+}
+
+func TestAnalysisCache_StoresAnswersPerKey(t *testing.T) {
+	service := analysiscache.New(analysiscache.NewMemoryStore(), nil, analysiscache.DefaultConfig())
+	key := analysiscache.Key{TrackID: "t1", Category: "meaning", Language: "en"}
+
+	if _, _, ok := service.Get(key); ok {
+		t.Fatal("Expected a miss before anything is stored")
+	}
+	stored := service.Put(key, "It's about loss")
+	if analysis, _, ok := service.Get(key); !ok || analysis.Answer != "It's about loss" || analysis.ID != stored.ID {
+		t.Errorf("Expected the stored answer, got %+v, %v", analysis, ok)
+	}
+
+	clean := key
+	clean.Variant = "clean"
+	if _, _, ok := service.Get(clean); ok {
+		t.Error("Expected clean mode answers to be stored apart")
+	}
+	spanish := key
+	spanish.Language = "es"
+	if _, _, ok := service.Get(spanish); ok {
+		t.Error("Expected answers in other languages to be stored apart")
+	}
+}
+
+func TestAnalysisCache_ExpiresAndPurgesOldAnswers(t *testing.T) {
+	store := analysiscache.NewMemoryStore()
+	service := analysiscache.New(store, nil, analysiscache.Config{TTL: time.Hour})
+	old := analysiscache.Key{TrackID: "t1", Category: "meaning", Language: "en"}
+	fresh := analysiscache.Key{TrackID: "t2", Category: "meaning", Language: "en"}
+	store.Put(models.StoredAnalysis{TrackID: old.TrackID, Category: old.Category, Language: old.Language, Answer: "old", CreatedAt: time.Now().Add(-2 * time.Hour)})
+	service.Put(fresh, "fresh")
+
+	if _, _, ok := service.Get(old); ok {
+		t.Error("Expected an expired answer to be a miss")
+	}
+	deleted, err := service.Purge()
+	if err != nil || deleted != 1 {
+		t.Fatalf("Expected one expired answer purged, got %d, %v", deleted, err)
+	}
+	if _, _, ok := service.Get(fresh); !ok {
+		t.Error("Expected the fresh answer to be kept")
+	}
+}
+
+func TestAnalysisCache_ServesTheBestRatedAnswer(t *testing.T) {
+	service := analysiscache.New(analysiscache.NewMemoryStore(), nil, analysiscache.DefaultConfig())
+	key := analysiscache.Key{TrackID: "t1", Category: "meaning", Language: "en"}
+	first := service.Put(key, "first")
+	second := service.Put(key, "second")
+
+	if analysis, _, _ := service.Get(key); analysis.ID != second.ID {
+		t.Errorf("Expected the newest answer while none is rated, got %q", analysis.Answer)
+	}
+	service.Rate(first.ID, "alice", true)
+	rated, err := service.Rate(first.ID, "bob", true)
+	if err != nil || rated.Helpful != 2 || rated.Unhelpful != 0 {
+		t.Fatalf("Expected two helpful ratings, got %+v, %v", rated, err)
+	}
+	if analysis, _, _ := service.Get(key); analysis.ID != first.ID {
+		t.Errorf("Expected the best rated answer, got %q", analysis.Answer)
+	}
+
+	rated, _ = service.Rate(first.ID, "bob", false)
+	if rated.Helpful != 1 || rated.Unhelpful != 1 {
+		t.Errorf("Expected a second rating by the same user to replace the first, got %+v", rated)
+	}
+	if _, err := service.Rate(99, "alice", true); !errors.Is(err, analysiscache.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound rating an unknown analysis, got %v", err)
+	}
+}
+
+func TestAnalysisCache_ReturnsRejectedAnswersOnceAllAreRatedDown(t *testing.T) {
+	service := analysiscache.New(analysiscache.NewMemoryStore(), nil, analysiscache.Config{TTL: time.Hour, RejectAfter: 2})
+	key := analysiscache.Key{TrackID: "t1", Category: "meaning", Language: "en"}
+	bad := service.Put(key, "bad answer")
+
+	service.Rate(bad.ID, "alice", false)
+	if _, _, ok := service.Get(key); !ok {
+		t.Fatal("Expected one unhelpful rating to keep the answer")
+	}
+	service.Rate(bad.ID, "bob", false)
+	_, rejected, ok := service.Get(key)
+	if ok || len(rejected) != 1 || rejected[0] != "bad answer" {
+		t.Fatalf("Expected the rated down answer to be rejected, got %v, %v", rejected, ok)
+	}
+
+	better := service.Put(key, "better answer")
+	if analysis, _, ok := service.Get(key); !ok || analysis.ID != better.ID {
+		t.Errorf("Expected the new answer to be served, got %+v, %v", analysis, ok)
+	}
+}
+
+func TestAnalysisCache_ReanalyzeSendsRejectedAnswers(t *testing.T) {
+	service := analysiscache.New(analysiscache.NewMemoryStore(), nil, analysiscache.DefaultConfig())
+	var prompt string
+	ai := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(p string) (string, error) {
+			prompt = p
+			return "fresh take", nil
+		},
+	}
+
+	answer, err := service.Reanalyze(ai, "what does it mean", "some lyrics", "Numb by Linkin Park", []string{"bad answer"})
+	if err != nil || answer != "fresh take" {
+		t.Fatalf("Expected the AI's answer, got %q, %v", answer, err)
+	}
+	for _, part := range []string{"Numb by Linkin Park", "what does it mean", "bad answer", "some lyrics"} {
+		if !strings.Contains(prompt, part) {
+			t.Errorf("Expected the prompt to contain %q, got %q", part, prompt)
+		}
+	}
+}
+
+func TestAnalysisCache_ReanalyzesAtMostOncePerInterval(t *testing.T) {
+	service := analysiscache.New(analysiscache.NewMemoryStore(), nil, analysiscache.Config{TTL: time.Hour, RejectAfter: 1, ReanalyzeInterval: time.Minute})
+	key := analysiscache.Key{TrackID: "t1", Category: "meaning", Language: "en"}
+	bad := service.Put(key, "bad answer")
+	service.Rate(bad.ID, "alice", false)
+
+	analysis, rejected, ok := service.Get(key)
+	if !ok || analysis.ID != bad.ID || rejected != nil {
+		t.Errorf("Expected the rated down answer to be served within the interval, got %+v, %v, %v", analysis, rejected, ok)
+	}
+}
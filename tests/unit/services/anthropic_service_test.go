@@ -0,0 +1,129 @@
+package services_test
+
+import (
+	"backend/services/anthropic"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newAnthropicTestServer(t *testing.T, text string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Errorf("Expected path /messages, got %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("Expected x-api-key header test-key, got %s", r.Header.Get("x-api-key"))
+		}
+		if r.Header.Get("anthropic-version") == "" {
+			t.Error("Expected anthropic-version header to be set")
+		}
+
+		var req anthropic.MessagesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		json.NewEncoder(w).Encode(anthropic.MessagesResponse{
+			Type:    "message",
+			Role:    "assistant",
+			Content: []anthropic.ContentBlock{{Type: "text", Text: text}},
+		})
+	}))
+}
+
+func TestAnthropicConfig_DefaultConfig(t *testing.T) {
+	config := anthropic.DefaultConfig()
+
+	if config.BaseURL != "https://api.anthropic.com/v1" {
+		t.Errorf("Expected BaseURL https://api.anthropic.com/v1, got %s", config.BaseURL)
+	}
+
+	if config.MaxTokens != 500 {
+		t.Errorf("Expected MaxTokens 500, got %d", config.MaxTokens)
+	}
+}
+
+func TestAnthropicService_GenerateResponse(t *testing.T) {
+	server := newAnthropicTestServer(t, "Jazz is a genre of music.")
+	defer server.Close()
+
+	config := anthropic.DefaultConfig()
+	config.APIKey = "test-key"
+	config.BaseURL = server.URL
+	service := anthropic.New(config)
+
+	answer, err := service.GenerateResponse("What is jazz?")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if answer != "Jazz is a genre of music." {
+		t.Errorf("Unexpected answer: %s", answer)
+	}
+}
+
+func TestAnthropicService_GenerateJSON_PrependsPrefill(t *testing.T) {
+	server := newAnthropicTestServer(t, `"primary_mood": "calm"}`)
+	defer server.Close()
+
+	config := anthropic.DefaultConfig()
+	config.APIKey = "test-key"
+	config.BaseURL = server.URL
+	service := anthropic.New(config)
+
+	answer, err := service.GenerateJSON("Return JSON")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var parsed map[string]string
+	if err := json.Unmarshal([]byte(answer), &parsed); err != nil {
+		t.Fatalf("Expected valid JSON, got %s: %v", answer, err)
+	}
+	if parsed["primary_mood"] != "calm" {
+		t.Errorf("Expected primary_mood calm, got %s", parsed["primary_mood"])
+	}
+}
+
+func TestAnthropicService_IsAvailable_NoAPIKey(t *testing.T) {
+	service := anthropic.New(anthropic.DefaultConfig())
+
+	if err := service.IsAvailable(); err == nil {
+		t.Error("Expected error when API key is missing")
+	}
+}
+
+func TestAnthropicService_SendsTopPOnlyIfSet(t *testing.T) {
+	var sent []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		sent = append(sent, req)
+		json.NewEncoder(w).Encode(anthropic.MessagesResponse{
+			Type:    "message",
+			Role:    "assistant",
+			Content: []anthropic.ContentBlock{{Type: "text", Text: "Hi"}},
+		})
+	}))
+	defer server.Close()
+
+	config := anthropic.DefaultConfig()
+	config.APIKey = "test-key"
+	config.BaseURL = server.URL
+	if _, err := anthropic.New(config).GenerateResponse("Hello"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	config.TopP = 0.8
+	if _, err := anthropic.New(config).GenerateResponse("Hello"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, ok := sent[0]["top_p"]; ok || sent[0]["temperature"] != 0.7 {
+		t.Errorf("Expected only temperature by default, got %v", sent[0])
+	}
+	if sent[1]["top_p"] != 0.8 {
+		t.Errorf("Expected the configured top_p, got %v", sent[1]["top_p"])
+	}
+}
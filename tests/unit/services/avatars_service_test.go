@@ -0,0 +1,104 @@
+package services_test
+
+import (
+	"backend/services/avatars"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// pngHeader is enough of a PNG for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestAvatars_ValidatesAndStoresLocally(t *testing.T) {
+	storage, err := avatars.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	service := avatars.New(storage, avatars.Config{MaxBytes: 64})
+
+	if _, err := service.Save("alice", []byte("<svg onload=alert(1)></svg>")); !errors.Is(err, avatars.ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType, got %v", err)
+	}
+	if _, err := service.Save("alice", append(pngHeader, make([]byte, 64)...)); !errors.Is(err, avatars.ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+
+	key, err := service.Save("alice", pngHeader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasSuffix(key, ".png") || !avatars.ValidKey(key) {
+		t.Errorf("Expected a valid PNG key, got %q", key)
+	}
+	if other, _ := service.Save("bob", pngHeader); other == key {
+		t.Errorf("Expected users' keys to differ for the same image")
+	}
+
+	data, contentType, err := service.Open(key)
+	if err != nil || !bytes.Equal(data, pngHeader) || contentType != "image/png" {
+		t.Errorf("Expected the stored PNG, got %q, %q, %v", data, contentType, err)
+	}
+	if _, _, err := service.Open("../../etc/passwd"); !errors.Is(err, avatars.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an invalid key, got %v", err)
+	}
+
+	if err := service.Delete(key); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, _, err := service.Open(key); !errors.Is(err, avatars.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after deleting, got %v", err)
+	}
+}
+
+func TestAvatars_StoresInS3(t *testing.T) {
+	var mutex sync.Mutex
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") || r.Header.Get("X-Amz-Content-Sha256") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch r.Method {
+		case "PUT":
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case "GET":
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(data)
+		case "DELETE":
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	storage := avatars.NewS3Storage(avatars.S3Config{Endpoint: server.URL, Bucket: "avatars", Region: "us-east-1", AccessKey: "AKID", SecretKey: "secret"})
+	if err := storage.Put("a.png", "image/png", pngHeader); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := objects["/avatars/a.png"]; !ok {
+		t.Errorf("Expected the object in the bucket's path, got %v", objects)
+	}
+	data, contentType, err := storage.Get("a.png")
+	if err != nil || !bytes.Equal(data, pngHeader) || contentType != "image/png" {
+		t.Errorf("Expected the stored PNG, got %q, %q, %v", data, contentType, err)
+	}
+	if err := storage.Delete("a.png"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, _, err := storage.Get("a.png"); !errors.Is(err, avatars.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
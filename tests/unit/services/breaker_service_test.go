@@ -0,0 +1,93 @@
+package services_test
+
+import (
+	"backend/services/breaker"
+	"backend/tests/mocks"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	config := breaker.DefaultConfig("genius")
+	config.FailureThreshold = 2
+	config.OpenTimeout = 20 * time.Millisecond
+	b := breaker.New(config)
+
+	calls := 0
+	failing := func() error {
+		calls++
+		return errors.New("timeout")
+	}
+
+	b.Execute(failing)
+	b.Execute(failing)
+	if b.State() != breaker.StateOpen {
+		t.Fatalf("Expected circuit to open, got %s", b.State())
+	}
+
+	// Open circuit fails fast without calling the service
+	if err := b.Execute(failing); !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("Expected ErrOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+
+	// After the timeout a successful probe closes the circuit
+	time.Sleep(30 * time.Millisecond)
+	if err := b.Execute(func() error { return nil }); err != nil {
+		t.Errorf("Expected probe to succeed, got %v", err)
+	}
+	if b.State() != breaker.StateClosed {
+		t.Errorf("Expected circuit to close after recovery, got %s", b.State())
+	}
+}
+
+func TestBreaker_FailedProbeReopens(t *testing.T) {
+	config := breaker.DefaultConfig("ai")
+	config.FailureThreshold = 1
+	config.OpenTimeout = 10 * time.Millisecond
+	b := breaker.New(config)
+
+	b.Execute(func() error { return errors.New("down") })
+	time.Sleep(20 * time.Millisecond)
+	b.Execute(func() error { return errors.New("still down") })
+
+	if b.State() != breaker.StateOpen {
+		t.Errorf("Expected failed probe to reopen the circuit, got %s", b.State())
+	}
+}
+
+func TestBreaker_IgnoredErrorsKeepCircuitClosed(t *testing.T) {
+	notFound := errors.New("not found")
+	config := breaker.DefaultConfig("spotify")
+	config.FailureThreshold = 1
+	config.IgnoreErrors = []error{notFound}
+	b := breaker.New(config)
+
+	b.Execute(func() error { return notFound })
+	if b.State() != breaker.StateClosed {
+		t.Errorf("Expected ignored error to keep the circuit closed, got %s", b.State())
+	}
+}
+
+func TestBreaker_WrapsGenius(t *testing.T) {
+	config := breaker.DefaultConfig("genius")
+	config.FailureThreshold = 1
+	calls := 0
+	service := breaker.NewGenius(&mocks.MockGeniusService{
+		GetLyricsFunc: func(trackName, artistName string) (string, error) {
+			calls++
+			return "", errors.New("genius down")
+		},
+	}, breaker.New(config))
+
+	service.GetLyrics("Numb", "Linkin Park")
+	if _, err := service.GetLyrics("Numb", "Linkin Park"); !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("Expected ErrOpen, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected Genius to be called once, got %d", calls)
+	}
+}
@@ -0,0 +1,86 @@
+package services_test
+
+import (
+	"backend/services/budget"
+	"backend/services/openai"
+	"backend/tests/mocks"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newBudgetTestOpenAI(t *testing.T, tokens int, calls *int) (openai.Service, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.Choice{{Message: openai.Message{Role: "assistant", Content: "from openai"}}},
+			Usage:   openai.Usage{PromptTokens: tokens / 2, CompletionTokens: tokens - tokens/2, TotalTokens: tokens},
+		})
+	}))
+
+	config := openai.DefaultConfig()
+	config.APIKey = "test-key"
+	config.BaseURL = server.URL
+	return openai.New(config), server.Close
+}
+
+func TestBudgetService_RejectsWhenGlobalBudgetExceeded(t *testing.T) {
+	calls := 0
+	primary, closeServer := newBudgetTestOpenAI(t, 60, &calls)
+	defer closeServer()
+
+	service := budget.New(primary, nil, budget.Config{GlobalDailyTokens: 100})
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.GenerateResponse("hello"); err != nil {
+			t.Fatalf("Expected request %d within budget, got %v", i+1, err)
+		}
+	}
+
+	if _, err := service.GenerateResponse("hello"); !errors.Is(err, budget.ErrBudgetExceeded) {
+		t.Errorf("Expected ErrBudgetExceeded, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 OpenAI calls, got %d", calls)
+	}
+
+	usage := service.Usage("")
+	if usage.GlobalUsed != 120 || usage.GlobalLimit != 100 {
+		t.Errorf("Expected 120/100 tokens used, got %d/%d", usage.GlobalUsed, usage.GlobalLimit)
+	}
+}
+
+func TestBudgetService_UserBudgetFallsBack(t *testing.T) {
+	calls := 0
+	primary, closeServer := newBudgetTestOpenAI(t, 50, &calls)
+	defer closeServer()
+
+	fallback := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			return "from ollama", nil
+		},
+	}
+	service := budget.New(primary, fallback, budget.Config{UserDailyTokens: 50})
+
+	alice := service.ForUser("alice")
+	if answer, _ := alice.GenerateResponse("hello"); answer != "from openai" {
+		t.Errorf("Expected OpenAI answer within budget, got %s", answer)
+	}
+	if answer, _ := alice.GenerateResponse("hello"); answer != "from ollama" {
+		t.Errorf("Expected fallback answer once the user budget is spent, got %s", answer)
+	}
+
+	// Other users have their own budget
+	if answer, _ := service.ForUser("bob").GenerateResponse("hello"); answer != "from openai" {
+		t.Errorf("Expected OpenAI answer for another user, got %s", answer)
+	}
+
+	if used := service.Usage("alice").UserUsed; used != 50 {
+		t.Errorf("Expected alice to have used 50 tokens, got %d", used)
+	}
+	if used := service.Usage("bob").GlobalUsed; used != 100 {
+		t.Errorf("Expected 100 tokens used overall, got %d", used)
+	}
+}
@@ -0,0 +1,118 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/canary"
+	"backend/tests/mocks"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// canaryAI answers as the model it was built for, so outputs can be told apart
+func canaryAI(config models.CanaryConfig) *mocks.MockOllamaService {
+	return &mocks.MockOllamaService{
+		AnalyzeLyricsFunc: func(query, lyrics, songInfo string) (string, error) {
+			return config.Model + ": lyrics answer", nil
+		},
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			return config.Model + ": " + prompt, nil
+		},
+		GenerateJSONFunc: func(prompt string) (string, error) {
+			if config.Model == "broken" {
+				return "not json", nil
+			}
+			return `{"primary_mood":"sad","mood_score":0.8,"emotion_tags":["lonely"]}`, nil
+		},
+	}
+}
+
+func TestCanary_ComparesCandidateWithLive(t *testing.T) {
+	temperature := 0.7
+	var built []models.CanaryConfig
+	factory := func(config models.CanaryConfig) (canary.AIService, error) {
+		built = append(built, config)
+		return canaryAI(config), nil
+	}
+
+	service := canary.New(factory, canary.Config{
+		Live:    models.CanaryConfig{Provider: "openai", Model: "gpt-live", Temperature: &temperature},
+		Prices:  map[string]canary.Price{"gpt-live": {Input: 1, Output: 2}},
+		DataDir: t.TempDir(),
+	})
+
+	report, err := service.Run(models.CanaryConfig{Model: "gpt-candidate", Instructions: "Be brief."})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(built) != 2 || built[1].Provider != "openai" || built[1].Temperature == nil || *built[1].Temperature != 0.7 {
+		t.Errorf("Expected the candidate to inherit unset fields from the live configuration, got %+v", built)
+	}
+	if len(report.Cases) == 0 {
+		t.Fatal("Expected the battery to produce cases")
+	}
+
+	kinds := map[string]bool{}
+	for _, c := range report.Cases {
+		kinds[c.Kind] = true
+		if c.Current.Error != "" || c.Candidate.Error != "" {
+			t.Errorf("Unexpected errors in case %s: %+v", c.Name, c)
+		}
+		if c.Kind == canary.KindSongRequest {
+			if !strings.HasPrefix(c.Current.Output, "gpt-live: ") || !strings.HasPrefix(c.Candidate.Output, "gpt-candidate: Be brief.") {
+				t.Errorf("Expected side-by-side outputs with candidate instructions, got %q and %q", c.Current.Output, c.Candidate.Output)
+			}
+		}
+		if c.Current.PromptTokens == 0 || c.Current.CostUSD == nil {
+			t.Errorf("Expected estimated tokens and cost for the priced live model, got %+v", c.Current)
+		}
+		if c.Candidate.CostUSD != nil {
+			t.Errorf("Expected no cost for the unpriced candidate model, got %v", *c.Candidate.CostUSD)
+		}
+	}
+	for _, kind := range []string{canary.KindLyricsAnalysis, canary.KindMoodDetection, canary.KindSongRequest} {
+		if !kinds[kind] {
+			t.Errorf("Expected the battery to cover %s", kind)
+		}
+	}
+
+	if report.Current.CostUSD == nil || *report.Current.CostUSD <= 0 || report.Current.Config.Model != "gpt-live" {
+		t.Errorf("Unexpected live summary %+v", report.Current)
+	}
+	if report.Candidate.Config.Model != "gpt-candidate" || report.Candidate.Errors != 0 {
+		t.Errorf("Unexpected candidate summary %+v", report.Candidate)
+	}
+}
+
+func TestCanary_ReportsCandidateFailuresPerCase(t *testing.T) {
+	factory := func(config models.CanaryConfig) (canary.AIService, error) {
+		return canaryAI(config), nil
+	}
+	service := canary.New(factory, canary.Config{
+		Live:    models.CanaryConfig{Provider: "ollama", Model: "llama"},
+		DataDir: t.TempDir(),
+	})
+
+	report, err := service.Run(models.CanaryConfig{Model: "broken"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Candidate.Errors == 0 || report.Current.Errors != 0 {
+		t.Errorf("Expected only the candidate's mood detection to fail, got %d and %d errors", report.Current.Errors, report.Candidate.Errors)
+	}
+}
+
+func TestCanary_RejectsUnbuildableCandidate(t *testing.T) {
+	factory := func(config models.CanaryConfig) (canary.AIService, error) {
+		if config.Provider != "openai" {
+			return nil, errors.New("unknown provider")
+		}
+		return canaryAI(config), nil
+	}
+	service := canary.New(factory, canary.Config{Live: models.CanaryConfig{Provider: "openai", Model: "gpt"}})
+
+	if _, err := service.Run(models.CanaryConfig{Provider: "nope"}); !errors.Is(err, canary.ErrInvalidCandidate) {
+		t.Errorf("Expected ErrInvalidCandidate, got %v", err)
+	}
+}
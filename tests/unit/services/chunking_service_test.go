@@ -0,0 +1,126 @@
+package services_test
+
+import (
+	"backend/services/chunking"
+	"backend/tests/mocks"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// longLyrics builds lyrics of the given number of stanzas, about 100 tokens each
+func longLyrics(stanzas int) string {
+	parts := make([]string, stanzas)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(strings.Repeat(fmt.Sprintf("Stanza %d keeps on going\n", i), 16))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func TestCountTokens(t *testing.T) {
+	if tokens := chunking.CountTokens(""); tokens != 0 {
+		t.Errorf("Expected 0 tokens, got %d", tokens)
+	}
+	if tokens := chunking.CountTokens("In the end"); tokens != 3 {
+		t.Errorf("Expected 3 tokens, got %d", tokens)
+	}
+	if tokens := chunking.CountTokens("ことばことば"); tokens != 2 {
+		t.Errorf("Expected characters rather than bytes to be counted, got %d", tokens)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	chunks := chunking.Split(longLyrics(5), 250)
+	if len(chunks) != 3 || !strings.HasPrefix(chunks[1], "Stanza 2") {
+		t.Fatalf("Expected stanzas to be grouped into 3 chunks, got %d", len(chunks))
+	}
+	for _, chunk := range chunks {
+		if chunking.CountTokens(chunk) > 250 {
+			t.Errorf("Expected chunks within 250 tokens, got %d", chunking.CountTokens(chunk))
+		}
+	}
+
+	// A single stanza over the limit is broken between lines, and a single
+	// line over the limit is cut
+	chunks = chunking.Split(longLyrics(1)+"\n"+strings.Repeat("x", 500), 50)
+	for _, chunk := range chunks {
+		if chunking.CountTokens(chunk) > 50 {
+			t.Errorf("Expected chunks within 50 tokens, got %d", chunking.CountTokens(chunk))
+		}
+	}
+	if joined := strings.Join(chunks, ""); strings.Count(joined, "x") != 500 {
+		t.Errorf("Expected no text to be lost, got %d of 500 characters", strings.Count(joined, "x"))
+	}
+}
+
+func TestChunking_ShortLyricsPassThrough(t *testing.T) {
+	ai := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			t.Error("Expected no summaries for short lyrics")
+			return "", nil
+		},
+		AnalyzeLyricsFunc: func(query, lyrics, songInfo string) (string, error) {
+			return lyrics, nil
+		},
+	}
+	chunker := chunking.New(ai, chunking.DefaultConfig())
+
+	answer, err := chunker.AnalyzeLyrics("meaning?", "I tried so hard", "Linkin Park - In the End")
+	if err != nil || answer != "I tried so hard" {
+		t.Errorf("Expected the lyrics unchanged, got %q, %v", answer, err)
+	}
+}
+
+func TestChunking_CondensesLongLyrics(t *testing.T) {
+	var summaries atomic.Int32
+	ai := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			n := summaries.Add(1)
+			if strings.Contains(prompt, "summaries of song lyrics") {
+				return "A short summary of summaries.", nil
+			}
+			// Summaries that together stay over budget force a reduce round
+			return fmt.Sprintf("Summary %d: %s", n, strings.Repeat("word ", 150)), nil
+		},
+		AnalyzeLyricsFunc: func(query, lyrics, songInfo string) (string, error) {
+			return lyrics, nil
+		},
+	}
+	chunker := chunking.New(ai, chunking.Config{TokenBudget: 500, ChunkTokens: 250, Parallelism: 2})
+
+	fitted, err := chunker.AnalyzeLyrics("meaning?", longLyrics(20), "Some Song")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if chunking.CountTokens(fitted) > 500 {
+		t.Errorf("Expected the condensed lyrics within budget, got %d tokens", chunking.CountTokens(fitted))
+	}
+	if !strings.Contains(fitted, "too long to include in full") || !strings.Contains(fitted, "A short summary of summaries.") {
+		t.Errorf("Expected the reduced summaries, got %q", fitted)
+	}
+	if summaries.Load() < 11 {
+		t.Errorf("Expected a map and a reduce round of summaries, got %d", summaries.Load())
+	}
+}
+
+func TestChunking_Disabled(t *testing.T) {
+	chunker := chunking.New(&mocks.MockOllamaService{}, chunking.Config{TokenBudget: 0})
+	lyrics := longLyrics(50)
+	if fitted, err := chunker.FitLyrics(lyrics); err != nil || fitted != lyrics {
+		t.Errorf("Expected lyrics in full with chunking disabled, got %v", err)
+	}
+}
+
+func TestChunking_SummaryFailure(t *testing.T) {
+	ai := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			return "", errors.New("model overloaded")
+		},
+	}
+	chunker := chunking.New(ai, chunking.Config{TokenBudget: 500, ChunkTokens: 250})
+	if _, err := chunker.AnalyzeLyrics("meaning?", longLyrics(20), "Some Song"); err == nil || !strings.Contains(err.Error(), "model overloaded") {
+		t.Errorf("Expected the summary error, got %v", err)
+	}
+}
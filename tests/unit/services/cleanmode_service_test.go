@@ -0,0 +1,52 @@
+package services_test
+
+import (
+	"backend/services/cleanmode"
+	"backend/tests/mocks"
+	"strings"
+	"testing"
+)
+
+func TestCleanMode_PerUserSetting(t *testing.T) {
+	service := cleanmode.New(cleanmode.Config{Users: []string{"kid"}})
+	if !service.IsClean("kid") || service.IsClean("adult") {
+		t.Fatal("Expected only the configured user to be in clean mode")
+	}
+
+	service.SetClean("adult", true)
+	service.SetClean("kid", false)
+	if status := service.Status("adult"); !status.Clean || status.UserID != "adult" {
+		t.Errorf("Expected clean mode to be turned on, got %+v", status)
+	}
+	if service.IsClean("kid") {
+		t.Error("Expected clean mode to be turned off")
+	}
+}
+
+func TestCleanMode_WrapAIKeepsAnswersFamilyFriendly(t *testing.T) {
+	var gotQuery, gotLyrics, gotPrompt string
+	ai := &mocks.MockOllamaService{
+		AnalyzeLyricsFunc: func(query, lyrics, songInfo string) (string, error) {
+			gotQuery, gotLyrics = query, lyrics
+			return "The frak in the chorus is about anger", nil
+		},
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			gotPrompt = prompt
+			return "A shitty day, basically", nil
+		},
+	}
+	wrapped := cleanmode.New(cleanmode.Config{Words: []string{"frak"}}).WrapAI(ai)
+
+	answer, _ := wrapped.AnalyzeLyrics("meaning?", "frak this, damn it", "Song by Artist")
+	if !strings.HasPrefix(gotQuery, cleanmode.SystemPrompt) || gotLyrics != "f*** this, d*** it" {
+		t.Errorf("Expected the clean prompt and masked lyrics, got %q and %q", gotQuery, gotLyrics)
+	}
+	if answer != "The f*** in the chorus is about anger" {
+		t.Errorf("Expected profanity masked in the analysis, got %q", answer)
+	}
+
+	answer, _ = wrapped.GenerateResponse("Tell me about Numb")
+	if !strings.HasPrefix(gotPrompt, cleanmode.SystemPrompt) || answer != "A s***** day, basically" {
+		t.Errorf("Expected a clean prompt and answer, got %q and %q", gotPrompt, answer)
+	}
+}
@@ -0,0 +1,77 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/comparison"
+	"backend/services/genius"
+	"backend/services/mood"
+	"backend/tests/mocks"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func comparisonLyrics(trackName, artistName string) (*mood.LyricsWithMood, error) {
+	if trackName == "Unknown" {
+		return nil, fmt.Errorf("failed to fetch lyrics: %w", genius.ErrSongNotFound)
+	}
+	return &mood.LyricsWithMood{
+		Lyrics:       "Lyrics of " + trackName + ", damn",
+		MoodAnalysis: &models.MoodAnalysis{PrimaryMood: "sad", MoodScore: 0.8},
+		Themes:       []string{"loss"},
+	}, nil
+}
+
+func TestComparison_Compare(t *testing.T) {
+	var prompt string
+	ai := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(p string) (string, error) {
+			prompt = p
+			return `{"shared_themes": ["loss", " "], "first_themes": ["pressure"], "second_themes": [], "mood": " Both are heavy. ", "era": "2003 and 1996.", "summary": "Two sad songs."}`, nil
+		},
+	}
+	service := comparison.New(&mocks.MockMoodService{GetLyricsWithMoodFunc: comparisonLyrics}, nil)
+
+	result, err := service.Compare(
+		models.TrackReference{Name: "Numb", Artist: "Linkin Park"},
+		models.TrackReference{Name: "Hurt", Artist: "Nine Inch Nails"},
+		ai,
+		comparison.Options{Instruction: "Answer in French.", MaskLyrics: strings.ToUpper},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.SharedThemes) != 1 || len(result.FirstThemes) != 1 || result.SecondThemes == nil || result.Mood != "Both are heavy." || result.Summary != "Two sad songs." {
+		t.Errorf("Expected the parsed comparison, got %+v", result)
+	}
+	if result.First.Track.Name != "Numb" || result.Second.MoodAnalysis == nil || result.Second.Themes[0] != "loss" {
+		t.Errorf("Expected each song's mood analysis, got %+v and %+v", result.First, result.Second)
+	}
+	for _, want := range []string{"Numb by Linkin Park", "Hurt by Nine Inch Nails", "LYRICS OF HURT", "detected mood: sad", "Answer in French."} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q, got %q", want, prompt)
+		}
+	}
+}
+
+func TestComparison_SongNotFound(t *testing.T) {
+	ai := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(p string) (string, error) {
+			t.Error("Expected no AI call when a song is missing")
+			return "{}", nil
+		},
+	}
+	service := comparison.New(&mocks.MockMoodService{GetLyricsWithMoodFunc: comparisonLyrics}, nil)
+
+	_, err := service.Compare(
+		models.TrackReference{Name: "Numb", Artist: "Linkin Park"},
+		models.TrackReference{Name: "Unknown", Artist: "Nobody"},
+		ai,
+		comparison.Options{},
+	)
+	var songErr *comparison.SongError
+	if !errors.As(err, &songErr) || songErr.Track.Name != "Unknown" || !errors.Is(err, genius.ErrSongNotFound) {
+		t.Errorf("Expected a SongError for the missing song, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"backend/services/crypto"
+	"backend/services/mood"
+	"backend/tests/mocks"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestCrypto(t *testing.T) crypto.Service {
+	service, err := crypto.New(crypto.Config{MasterKey: bytes.Repeat([]byte("k"), 32)})
+	if err != nil {
+		t.Fatalf("Failed to create crypto service: %v", err)
+	}
+	return service
+}
+
+func TestCrypto_RoundTripsPerUser(t *testing.T) {
+	service := newTestCrypto(t)
+
+	encrypted, err := service.Encrypt("alice", "sad")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if strings.Contains(encrypted, "sad") {
+		t.Errorf("Expected ciphertext not to contain the plaintext, got %s", encrypted)
+	}
+
+	decrypted, err := service.Decrypt("alice", encrypted)
+	if err != nil || decrypted != "sad" {
+		t.Errorf("Expected sad, got %q (%v)", decrypted, err)
+	}
+
+	if _, err := service.Decrypt("bob", encrypted); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected another user's key to fail, got %v", err)
+	}
+
+	if plain, err := service.Decrypt("alice", "happy"); err != nil || plain != "happy" {
+		t.Errorf("Expected unencrypted values to pass through, got %q (%v)", plain, err)
+	}
+}
+
+func TestCrypto_DecryptsAfterCipherEviction(t *testing.T) {
+	service := newTestCrypto(t)
+
+	encrypted, err := service.Encrypt("alice", "sad")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	// Enough other users to push alice's cipher out of the cache
+	for i := 0; i < 1500; i++ {
+		service.Encrypt(fmt.Sprintf("user-%d", i), "happy")
+	}
+
+	if decrypted, err := service.Decrypt("alice", encrypted); err != nil || decrypted != "sad" {
+		t.Errorf("Expected sad with the key derived again, got %q (%v)", decrypted, err)
+	}
+}
+
+func TestCrypto_RejectsShortMasterKey(t *testing.T) {
+	if _, err := crypto.New(crypto.Config{MasterKey: []byte("short")}); err == nil {
+		t.Error("Expected an error for a short master key")
+	}
+}
+
+func TestMood_WithEncryptionStoresCiphertext(t *testing.T) {
+	dataDir := t.TempDir()
+	service := mood.WithEncryption(mood.New(&mocks.MockGeniusService{}, &mocks.MockOllamaService{}, dataDir, nil), newTestCrypto(t))
+
+	if err := service.SaveUserMoodHistory("alice", "lonely", []string{"track-1", "track-2"}); err != nil {
+		t.Fatalf("SaveUserMoodHistory failed: %v", err)
+	}
+
+	raw, _ := os.ReadFile(filepath.Join(dataDir, "mood_history", "user_alice_mood_history.txt"))
+	if strings.Contains(string(raw), "lonely") || strings.Contains(string(raw), "track-1") {
+		t.Errorf("Expected mood history to be encrypted on disk, got %q", raw)
+	}
+
+	entries, err := service.GetUserMoodHistory("alice")
+	if err != nil {
+		t.Fatalf("GetUserMoodHistory failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].DetectedMood != "lonely" || len(entries[0].PlayedSongs) != 2 {
+		t.Errorf("Expected decrypted history, got %+v", entries)
+	}
+
+	if _, err := service.AddMoodNote("alice", "", "Missing my friends"); err != nil {
+		t.Fatalf("AddMoodNote failed: %v", err)
+	}
+	raw, _ = os.ReadFile(filepath.Join(dataDir, "mood_history", "user_alice_mood_journal.txt"))
+	if len(raw) == 0 || strings.Contains(string(raw), "friends") {
+		t.Errorf("Expected the journal note to be encrypted on disk, got %q", raw)
+	}
+	if entries, _ = service.GetUserMoodHistory("alice"); entries[0].Note != "Missing my friends" {
+		t.Errorf("Expected the decrypted note, got %+v", entries)
+	}
+}
@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"backend/services/avatars"
+	"backend/services/erasure"
+	"backend/services/users"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordingForgetter records the users it was told to forget
+type recordingForgetter struct {
+	forgotten []string
+}
+
+func (f *recordingForgetter) Forget(userID string) {
+	f.forgotten = append(f.forgotten, userID)
+}
+
+func TestErasure_DeletesFilesProfileAndAvatar(t *testing.T) {
+	dataDir := t.TempDir()
+	historyDir := filepath.Join(dataDir, "mood_history")
+	os.MkdirAll(historyDir, 0755)
+	for _, name := range []string{"user_alice_mood_history.txt", "user_alice_mood_journal.txt", "user_bob_mood_history.txt"} {
+		os.WriteFile(filepath.Join(historyDir, name), []byte("2024-05-01T00:00:00Z|happy|\n"), 0644)
+	}
+
+	storage, err := avatars.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	avatarService := avatars.New(storage, avatars.Config{MaxBytes: 1024})
+	key, err := avatarService.Save("alice", pngHeader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	usersService := users.New(users.NewMemoryStore())
+	if _, err := usersService.SetAvatar("alice", "/api/avatars/"+key); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	forgetter := &recordingForgetter{}
+	service := erasure.New(nil, usersService, avatarService, erasure.Config{DataDir: dataDir}, forgetter)
+
+	receipt, err := service.Erase(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if receipt.Deleted["mood_history_files"] != 2 || receipt.Deleted["avatar"] != 1 || receipt.Deleted["profile"] != 1 {
+		t.Errorf("Expected the files, avatar and profile to be counted, got %+v", receipt.Deleted)
+	}
+	if receipt.CompletedAt.IsZero() || len(receipt.NotPerUser) == 0 {
+		t.Errorf("Expected a completed receipt naming shared data, got %+v", receipt)
+	}
+	if _, err := os.Stat(filepath.Join(historyDir, "user_alice_mood_journal.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected alice's journal to be deleted, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(historyDir, "user_bob_mood_history.txt")); err != nil {
+		t.Errorf("Expected bob's history to be kept, got %v", err)
+	}
+	if _, _, err := avatarService.Open(key); !errors.Is(err, avatars.ErrNotFound) {
+		t.Errorf("Expected the avatar to be deleted, got %v", err)
+	}
+	if len(forgetter.forgotten) != 1 || forgetter.forgotten[0] != "alice" {
+		t.Errorf("Expected alice to be forgotten, got %v", forgetter.forgotten)
+	}
+
+	// Erasing again is harmless
+	receipt, err = service.Erase(context.Background(), "alice")
+	if err != nil || receipt.Deleted["mood_history_files"] != 0 {
+		t.Errorf("Expected nothing left to delete, got %+v, %v", receipt.Deleted, err)
+	}
+}
+
+func TestErasure_IgnoresIDsThatAreNotFileNames(t *testing.T) {
+	dataDir := t.TempDir()
+	os.MkdirAll(filepath.Join(dataDir, "mood_history"), 0755)
+	outside := filepath.Join(dataDir, "user_x_mood_history.txt")
+	os.WriteFile(outside, []byte("keep"), 0644)
+
+	service := erasure.New(nil, nil, nil, erasure.Config{DataDir: dataDir})
+	receipt, err := service.Erase(context.Background(), "../user_x")
+	if err != nil || receipt.Deleted["mood_history_files"] != 0 {
+		t.Errorf("Expected nothing to be deleted, got %+v, %v", receipt.Deleted, err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("Expected the file outside mood_history to be kept, got %v", err)
+	}
+
+	if _, err := service.Erase(context.Background(), ""); err == nil {
+		t.Error("Expected an error for an empty user ID")
+	}
+}
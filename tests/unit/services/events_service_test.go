@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"backend/services/events"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestEventBus_DeliversInOrderToSubscribers(t *testing.T) {
+	bus := events.New(events.DefaultConfig())
+
+	var mutex sync.Mutex
+	var received []string
+	bus.Subscribe(events.TrackChanged, "recorder", func(event events.Event) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, event.Payload.(string))
+		return nil
+	})
+	otherCalls := 0
+	bus.Subscribe(events.MessagePosted, "other", func(event events.Event) error {
+		otherCalls++
+		return nil
+	})
+
+	bus.Publish(events.TrackChanged, "a")
+	bus.Publish(events.TrackChanged, "b")
+	bus.Publish(events.TrackChanged, "c")
+	bus.Close()
+
+	if len(received) != 3 || received[0] != "a" || received[2] != "c" {
+		t.Errorf("Expected events a, b, c in order, got %v", received)
+	}
+	if otherCalls != 0 {
+		t.Errorf("Expected no events for other types, got %d", otherCalls)
+	}
+}
+
+func TestEventBus_IsolatesFailingSubscribers(t *testing.T) {
+	bus := events.New(events.DefaultConfig())
+
+	bus.Subscribe(events.MoodDetected, "failing", func(event events.Event) error {
+		return errors.New("webhook unreachable")
+	})
+	bus.Subscribe(events.MoodDetected, "panicking", func(event events.Event) error {
+		panic("boom")
+	})
+	delivered := 0
+	bus.Subscribe(events.MoodDetected, "healthy", func(event events.Event) error {
+		delivered++
+		return nil
+	})
+
+	bus.Publish(events.MoodDetected, events.MoodDetectedPayload{UserID: "u1"})
+	bus.Publish(events.MoodDetected, events.MoodDetectedPayload{UserID: "u1"})
+	bus.Close()
+
+	if delivered != 2 {
+		t.Errorf("Expected healthy subscriber to get 2 events, got %d", delivered)
+	}
+
+	// Publishing after close is a no-op
+	bus.Publish(events.MoodDetected, events.MoodDetectedPayload{})
+}
@@ -0,0 +1,108 @@
+package services_test
+
+import (
+	"backend/services/experiments"
+	"backend/services/prompts"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fakeTemplates reports the named templates as loaded
+type fakeTemplates map[string]bool
+
+func (f fakeTemplates) Has(name string) bool {
+	return f[name]
+}
+
+func newExperiments(t *testing.T) experiments.Service {
+	t.Helper()
+	service, err := experiments.New(experiments.NewMemoryStore(), map[string][]experiments.Variant{
+		prompts.LyricsAnalysis: {{Name: experiments.Control, Weight: 1}, {Name: "concise", Weight: 3}},
+	}, fakeTemplates{prompts.LyricsAnalysis: true, "lyrics_analysis.concise": true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return service
+}
+
+func TestExperiments_New_Validation(t *testing.T) {
+	templates := fakeTemplates{prompts.MoodDetection: true}
+	for name, variants := range map[string][]experiments.Variant{
+		"one variant":      {{Name: experiments.Control, Weight: 1}},
+		"missing template": {{Name: experiments.Control, Weight: 1}, {Name: "strict", Weight: 1}},
+		"zero weight":      {{Name: experiments.Control, Weight: 0}, {Name: experiments.Control, Weight: 1}},
+	} {
+		_, err := experiments.New(experiments.NewMemoryStore(), map[string][]experiments.Variant{prompts.MoodDetection: variants}, templates)
+		if !errors.Is(err, experiments.ErrInvalidExperiment) {
+			t.Errorf("%s: expected ErrInvalidExperiment, got %v", name, err)
+		}
+	}
+}
+
+func TestExperiments_Assign(t *testing.T) {
+	service := newExperiments(t)
+
+	if _, ok := service.Assign(prompts.MoodDetection, "alice"); ok {
+		t.Error("Expected no assignment without an experiment")
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		assignment, ok := service.Assign(prompts.LyricsAnalysis, userID)
+		if !ok {
+			t.Fatal("Expected an assignment")
+		}
+		if again, _ := service.Assign(prompts.LyricsAnalysis, userID); again != assignment {
+			t.Fatalf("Expected a sticky assignment for %s, got %+v then %+v", userID, assignment, again)
+		}
+		counts[assignment.Template]++
+	}
+
+	// Weighted 1:3, so about 500 control users
+	if counts[prompts.LyricsAnalysis] < 400 || counts[prompts.LyricsAnalysis] > 600 || counts["lyrics_analysis.concise"] != 2000-counts[prompts.LyricsAnalysis] {
+		t.Errorf("Expected users split 1:3, got %v", counts)
+	}
+}
+
+func TestExperiments_RecordAndResults(t *testing.T) {
+	service := newExperiments(t)
+	control := experiments.Assignment{Experiment: prompts.LyricsAnalysis, Variant: experiments.Control, Template: prompts.LyricsAnalysis}
+	concise := experiments.Assignment{Experiment: prompts.LyricsAnalysis, Variant: "concise", Template: "lyrics_analysis.concise"}
+
+	first, err := service.Record(control, "alice", 100, "A long answer", false)
+	if err != nil || first == "" {
+		t.Fatalf("Expected a response ID, got %q, %v", first, err)
+	}
+	service.Record(control, "bob", 300, "", true)
+	second, _ := service.Record(concise, "carol", 50, "Short", false)
+
+	if err := service.Feedback("alice", first, true); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := service.Feedback("carol", second, false); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := service.Feedback("mallory", first, false); !errors.Is(err, experiments.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another user's response, got %v", err)
+	}
+	if err := service.Feedback("alice", "unknown", true); !errors.Is(err, experiments.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	results, err := service.Results()
+	if err != nil || len(results) != 1 || len(results[0].Variants) != 2 {
+		t.Fatalf("Expected one experiment with two variants, got %+v, %v", results, err)
+	}
+	controlResult, conciseResult := results[0].Variants[0], results[0].Variants[1]
+	if controlResult.Requests != 2 || controlResult.Failures != 1 || controlResult.AvgLatencyMs != 200 || controlResult.AvgLength != 13 {
+		t.Errorf("Unexpected control result %+v", controlResult)
+	}
+	if controlResult.Helpful != 1 || controlResult.HelpfulRate == nil || *controlResult.HelpfulRate != 1 {
+		t.Errorf("Expected control's feedback to be counted, got %+v", controlResult)
+	}
+	if conciseResult.Weight != 3 || conciseResult.Unhelpful != 1 || *conciseResult.HelpfulRate != 0 || conciseResult.AvgLength != 5 {
+		t.Errorf("Unexpected concise result %+v", conciseResult)
+	}
+}
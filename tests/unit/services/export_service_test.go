@@ -0,0 +1,155 @@
+package services_test
+
+import (
+	"archive/zip"
+	"backend/server/models"
+	"backend/services/export"
+	"backend/services/mood"
+	"backend/services/users"
+	"backend/tests/mocks"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExport_BundlesUserData(t *testing.T) {
+	moods := &mocks.MockMoodService{
+		GetUserMoodHistoryFunc: func(userID string) ([]mood.UserMoodEntry, error) {
+			return []mood.UserMoodEntry{
+				{Timestamp: "2024-05-01T00:00:00Z", DetectedMood: "sad", PlayedSongs: []string{"t1", "t2"}, Note: "rough day"},
+				{Timestamp: "2024-05-02T00:00:00Z", DetectedMood: "anxious"},
+			}, nil
+		},
+	}
+	var offsets []int
+	plays := &mocks.MockMusicRepository{
+		QueryPlayHistoryFunc: func(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error) {
+			offsets = append(offsets, offset)
+			items := make([]models.PlayHistoryItem, limit)
+			for i := range items {
+				items[i].TrackID = "t"
+			}
+			return items, 100000, nil
+		},
+	}
+	usersService := users.New(users.NewMemoryStore())
+	dir := t.TempDir()
+	config := export.DefaultConfig()
+	config.Dir = dir
+	config.MaxPlays = 700
+	service := export.New(nil, usersService, moods, plays, config)
+
+	token, _ := export.NewToken()
+	bundle, err := service.Export(context.Background(), "alice@example.com", export.BundleID(token))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if bundle.Bytes == 0 || len(bundle.Files) != 5 || !bundle.ExpiresAt.After(bundle.CreatedAt) {
+		t.Fatalf("Expected a bundle of five files, got %+v", bundle)
+	}
+	if len(offsets) != 2 || offsets[1] != 500 {
+		t.Errorf("Expected the play history to be read in two pages, got offsets %v", offsets)
+	}
+
+	if _, err := service.Open(export.BundleID(token)); !errors.Is(err, export.ErrNotFound) {
+		t.Errorf("Expected the bundle ID not to open the bundle, got %v", err)
+	}
+	file, err := service.Open(token)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer file.Close()
+	info, _ := file.Stat()
+	archive, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		t.Fatalf("Expected a zip, got %v", err)
+	}
+	contents := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		contents[f.Name] = f
+	}
+	decode := func(name string, v interface{}) {
+		f, ok := contents[name]
+		if !ok {
+			t.Fatalf("Expected %s in the bundle", name)
+		}
+		r, _ := f.Open()
+		defer r.Close()
+		if err := json.NewDecoder(r).Decode(v); err != nil {
+			t.Fatalf("Expected %s to be JSON, got %v", name, err)
+		}
+	}
+
+	var profile models.User
+	decode("profile.json", &profile)
+	if profile.Email != "alice@example.com" {
+		t.Errorf("Expected alice's profile, got %+v", profile)
+	}
+	var recommendations []struct {
+		Mood     string   `json:"mood"`
+		TrackIDs []string `json:"track_ids"`
+	}
+	decode("recommendations.json", &recommendations)
+	if len(recommendations) != 1 || recommendations[0].Mood != "sad" || len(recommendations[0].TrackIDs) != 2 {
+		t.Errorf("Expected the one entry with recommendations, got %+v", recommendations)
+	}
+	var history []mood.UserMoodEntry
+	decode("mood_history.json", &history)
+	if len(history) != 2 || history[0].Note != "rough day" {
+		t.Errorf("Expected the mood history with notes, got %+v", history)
+	}
+	var playHistory []models.PlayHistoryItem
+	decode("play_history.json", &playHistory)
+	if len(playHistory) != 700 {
+		t.Errorf("Expected MaxPlays plays, got %d", len(playHistory))
+	}
+	var messages []models.Message
+	decode("messages.json", &messages)
+	if messages == nil || len(messages) != 0 {
+		t.Errorf("Expected no messages without a database, got %v", messages)
+	}
+
+	for _, token := range []string{"", "../secret", "ABCDEF0123456789ABCDEF0123456789", "0123456789abcdef0123456789abcdef"} {
+		if _, err := service.Open(token); !errors.Is(err, export.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for %q, got %v", token, err)
+		}
+	}
+}
+
+func TestExport_ExpiresBundles(t *testing.T) {
+	dir := t.TempDir()
+	config := export.DefaultConfig()
+	config.Dir = dir
+	config.TTL = time.Hour
+	service := export.New(nil, nil, &mocks.MockMoodService{}, nil, config)
+
+	fresh, _ := export.NewToken()
+	old, _ := export.NewToken()
+	if _, err := service.Export(context.Background(), "alice", export.BundleID(fresh)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.Export(context.Background(), "bob", export.BundleID(old)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.Export(context.Background(), "bob", "../secret"); err == nil {
+		t.Error("Expected an invalid bundle ID to be rejected")
+	}
+	past := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(filepath.Join(dir, export.BundleID(old)+".zip"), past, past)
+
+	if _, err := service.Open(old); !errors.Is(err, export.ErrNotFound) {
+		t.Errorf("Expected an expired bundle to be unavailable, got %v", err)
+	}
+	if deleted, err := service.Purge(); err != nil || deleted != 1 {
+		t.Errorf("Expected one bundle to be purged, got %d, %v", deleted, err)
+	}
+	file, err := service.Open(fresh)
+	if err != nil {
+		t.Fatalf("Expected the fresh bundle to be kept, got %v", err)
+	}
+	file.Close()
+}
@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/facts"
+	"backend/tests/mocks"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func numbSong(trackName, artistName string) (*models.GeniusSong, error) {
+	return &models.GeniusSong{
+		Title:       trackName,
+		Artist:      artistName,
+		URL:         "https://genius.com/Linkin-park-numb-lyrics",
+		Album:       "Meteora",
+		ReleaseDate: "March 25, 2003",
+		Producers:   []string{"Don Gilmore", "Linkin Park"},
+		Samples:     []string{"Some Song by Someone"},
+		Description: "The final track on Meteora.",
+	}, nil
+}
+
+func TestFacts_CombinesMetadataAndBlurb(t *testing.T) {
+	var prompt string
+	ai := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(p string) (string, error) {
+			prompt = p
+			return "  Numb was the last song recorded for Meteora.  ", nil
+		},
+	}
+	service := facts.New(&mocks.MockGeniusService{GetSongFunc: numbSong}, ai, nil, facts.DefaultConfig())
+
+	songFacts, err := service.Get("Numb", "Linkin Park")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if songFacts.Album != "Meteora" || songFacts.ReleaseDate != "March 25, 2003" || len(songFacts.Producers) != 2 || songFacts.GeniusURL == "" {
+		t.Errorf("Expected the Genius metadata, got %+v", songFacts)
+	}
+	if songFacts.DidYouKnow != "Numb was the last song recorded for Meteora." {
+		t.Errorf("Expected the trimmed blurb, got %q", songFacts.DidYouKnow)
+	}
+	for _, want := range []string{"Numb by Linkin Park", "Don Gilmore, Linkin Park", "Some Song by Someone", "The final track on Meteora."} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q, got %q", want, prompt)
+		}
+	}
+}
+
+func TestFacts_CachesPerSong(t *testing.T) {
+	lookups, blurbs := 0, 0
+	genius := &mocks.MockGeniusService{
+		GetSongFunc: func(trackName, artistName string) (*models.GeniusSong, error) {
+			lookups++
+			return numbSong(trackName, artistName)
+		},
+	}
+	failing := true
+	ai := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			blurbs++
+			if failing {
+				return "", errors.New("AI down")
+			}
+			return "A fact.", nil
+		},
+	}
+	service := facts.New(genius, ai, nil, facts.Config{CacheTTL: time.Hour, MaxEntries: 10})
+
+	songFacts, err := service.Get("Numb", "Linkin Park")
+	if err != nil || songFacts.DidYouKnow != "" || songFacts.Album != "Meteora" {
+		t.Fatalf("Expected the facts without a blurb when the AI fails, got %+v, %v", songFacts, err)
+	}
+
+	failing = false
+	service.Get("Numb", "Linkin Park")
+	songFacts, _ = service.Get("numb", "LINKIN PARK")
+	if lookups != 2 || blurbs != 2 || songFacts.DidYouKnow != "A fact." {
+		t.Errorf("Expected facts without a blurb to be collected again, then cached; got %d lookups, %d blurbs, %+v", lookups, blurbs, songFacts)
+	}
+}
+
+func TestFacts_GeniusErrorsArePassedOn(t *testing.T) {
+	genius := &mocks.MockGeniusService{
+		GetSongFunc: func(trackName, artistName string) (*models.GeniusSong, error) {
+			return nil, errors.New("genius API failed with status 503")
+		},
+	}
+	service := facts.New(genius, &mocks.MockOllamaService{}, nil, facts.DefaultConfig())
+
+	if _, err := service.Get("Numb", "Linkin Park"); err == nil {
+		t.Error("Expected the Genius error to be returned")
+	}
+}
@@ -0,0 +1,170 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/jobqueue"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForQueuedJob polls the queue until the job is finished
+func waitForQueuedJob(t *testing.T, queue jobqueue.Service, id int64) models.QueuedJob {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		job, err := queue.Get(id)
+		if err == nil && job.Finished() {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for job %d: %+v, %v", id, job, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func testQueueConfig() jobqueue.Config {
+	config := jobqueue.DefaultConfig()
+	config.RetryDelay = time.Millisecond
+	return config
+}
+
+func TestJobQueue_RunsJobs(t *testing.T) {
+	queue := jobqueue.New(jobqueue.NewMemoryStore(), testQueueConfig())
+	queue.Handle("double", func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		var n int
+		json.Unmarshal(payload, &n)
+		return n * 2, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := queue.Start(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	job, err := queue.Enqueue("alice", "double", 21)
+	if err != nil || job.Status != models.JobQueued || job.ID == 0 {
+		t.Fatalf("Expected a queued job, got %+v, %v", job, err)
+	}
+	job = waitForQueuedJob(t, queue, job.ID)
+	if job.Status != models.JobSucceeded || string(job.Result) != "42" || job.Attempts != 1 || job.FinishedAt == nil {
+		t.Errorf("Expected the job to succeed with 42, got %+v", job)
+	}
+
+	if _, err := queue.Enqueue("alice", "unknown", nil); !errors.Is(err, jobqueue.ErrUnknownType) {
+		t.Errorf("Expected ErrUnknownType, got %v", err)
+	}
+	jobs, _ := queue.List("alice", models.JobSucceeded, 10)
+	if len(jobs) != 1 {
+		t.Errorf("Expected alice's succeeded job to be listed, got %+v", jobs)
+	}
+	if jobs, _ := queue.List("bob", "", 10); len(jobs) != 0 {
+		t.Errorf("Expected no jobs for bob, got %+v", jobs)
+	}
+}
+
+func TestJobQueue_RetriesFailedAttempts(t *testing.T) {
+	queue := jobqueue.New(jobqueue.NewMemoryStore(), testQueueConfig())
+	var calls atomic.Int32
+	queue.Handle("flaky", func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		if calls.Add(1) < 3 {
+			return nil, errors.New("upstream down")
+		}
+		return "ok", nil
+	})
+	queue.Handle("broken", func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		panic("boom")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue.Start(ctx)
+
+	flaky, _ := queue.Enqueue("alice", "flaky", nil)
+	broken, _ := queue.Enqueue("alice", "broken", nil)
+
+	flakyJob := waitForQueuedJob(t, queue, flaky.ID)
+	if flakyJob.Status != models.JobSucceeded || flakyJob.Attempts != 3 || flakyJob.Error != "" {
+		t.Errorf("Expected the third attempt to succeed, got %+v", flakyJob)
+	}
+	brokenJob := waitForQueuedJob(t, queue, broken.ID)
+	if brokenJob.Status != models.JobFailed || brokenJob.Attempts != 3 || brokenJob.Error != "panic: boom" {
+		t.Errorf("Expected the job to fail after 3 attempts, got %+v", brokenJob)
+	}
+}
+
+func TestJobQueue_ResumesUnfinishedJobs(t *testing.T) {
+	store := jobqueue.NewMemoryStore()
+	interrupted, _ := store.Create(models.QueuedJob{Type: "echo", UserID: "alice", Status: models.JobRunning, Attempts: 1, MaxAttempts: 3, Payload: json.RawMessage(`"hi"`)})
+	done, _ := store.Create(models.QueuedJob{Type: "echo", UserID: "alice", Status: models.JobSucceeded, Payload: json.RawMessage(`"bye"`)})
+
+	queue := jobqueue.New(store, testQueueConfig())
+	var calls atomic.Int32
+	queue.Handle("echo", func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		calls.Add(1)
+		return payload, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue.Start(ctx)
+
+	job := waitForQueuedJob(t, queue, interrupted.ID)
+	if job.Status != models.JobSucceeded || string(job.Result) != `"hi"` || job.Attempts != 2 {
+		t.Errorf("Expected the interrupted job to be attempted again, got %+v", job)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if calls.Load() != 1 {
+		t.Errorf("Expected only the unfinished job to run, got %d runs", calls.Load())
+	}
+	if job, _ := queue.Get(done.ID); job.Attempts != 0 {
+		t.Errorf("Expected the finished job to be left alone, got %+v", job)
+	}
+}
+
+func TestJobQueue_RejectsJobsWhenFull(t *testing.T) {
+	config := testQueueConfig()
+	config.Workers = 1
+	config.MaxPending = 2
+	queue := jobqueue.New(jobqueue.NewMemoryStore(), config)
+	release := make(chan struct{})
+	queue.Handle("wait", func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue.Start(ctx)
+
+	first, _ := queue.Enqueue("alice", "wait", nil)
+	queue.Enqueue("alice", "wait", nil)
+	if _, err := queue.Enqueue("alice", "wait", nil); !errors.Is(err, jobqueue.ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+
+	close(release)
+	waitForQueuedJob(t, queue, first.ID)
+	if _, err := queue.Enqueue("alice", "wait", nil); err != nil {
+		t.Errorf("Expected room once a job finished, got %v", err)
+	}
+}
+
+func TestJobQueue_Purge(t *testing.T) {
+	store := jobqueue.NewMemoryStore()
+	old := time.Now().Add(-8 * 24 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+	store.Create(models.QueuedJob{Status: models.JobSucceeded, FinishedAt: &old})
+	store.Create(models.QueuedJob{Status: models.JobFailed, FinishedAt: &recent})
+	store.Create(models.QueuedJob{Status: models.JobQueued})
+
+	queue := jobqueue.New(store, jobqueue.DefaultConfig())
+	deleted, err := queue.Purge()
+	if err != nil || deleted != 1 {
+		t.Errorf("Expected 1 job deleted, got %d, %v", deleted, err)
+	}
+	if unfinished, _ := store.Unfinished(); len(unfinished) != 1 {
+		t.Errorf("Expected the queued job to be kept, got %+v", unfinished)
+	}
+}
@@ -0,0 +1,108 @@
+package services_test
+
+import (
+	"backend/services/llmcache"
+	"backend/tests/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLLMCache_GenerateResponse_CachesByPrompt(t *testing.T) {
+	calls := 0
+	mockAI := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			calls++
+			return "answer to " + prompt, nil
+		},
+	}
+	cache := llmcache.New(mockAI, llmcache.Config{Model: "test", TTL: time.Minute, MaxEntries: 10})
+
+	first, _ := cache.GenerateResponse("What is jazz?")
+	second, _ := cache.GenerateResponse("What is jazz?")
+	cache.GenerateResponse("What is blues?")
+
+	if first != second {
+		t.Errorf("Expected identical cached response, got %q and %q", first, second)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 AI calls for 2 distinct prompts, got %d", calls)
+	}
+}
+
+func TestLLMCache_DoesNotCacheErrors(t *testing.T) {
+	calls := 0
+	mockAI := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			calls++
+			if calls == 1 {
+				return "", errors.New("rate limited")
+			}
+			return "ok", nil
+		},
+	}
+	cache := llmcache.New(mockAI, llmcache.Config{Model: "test", TTL: time.Minute, MaxEntries: 10})
+
+	if _, err := cache.GenerateResponse("prompt"); err == nil {
+		t.Fatal("Expected first call to fail")
+	}
+	if response, err := cache.GenerateResponse("prompt"); err != nil || response != "ok" {
+		t.Errorf("Expected retry to reach the AI service, got %q, %v", response, err)
+	}
+}
+
+func TestLLMCache_ExpiryAndEviction(t *testing.T) {
+	calls := 0
+	mockAI := &mocks.MockOllamaService{
+		AnalyzeLyricsFunc: func(query, lyrics, songInfo string) (string, error) {
+			calls++
+			return "analysis", nil
+		},
+	}
+
+	expiring := llmcache.New(mockAI, llmcache.Config{Model: "test", TTL: time.Nanosecond, MaxEntries: 10})
+	expiring.AnalyzeLyrics("meaning?", "lyrics", "Numb by Linkin Park")
+	time.Sleep(time.Millisecond)
+	expiring.AnalyzeLyrics("meaning?", "lyrics", "Numb by Linkin Park")
+	if calls != 2 {
+		t.Errorf("Expected expired entry to be regenerated, got %d calls", calls)
+	}
+
+	calls = 0
+	small := llmcache.New(mockAI, llmcache.Config{Model: "test", TTL: time.Minute, MaxEntries: 1})
+	small.AnalyzeLyrics("meaning?", "lyrics", "Song A")
+	small.AnalyzeLyrics("meaning?", "lyrics", "Song B")
+	small.AnalyzeLyrics("meaning?", "lyrics", "Song A")
+	if calls != 3 {
+		t.Errorf("Expected least recently used entry to be evicted, got %d calls", calls)
+	}
+}
+
+func TestLLMCache_GenerateStream_ServesCachedResponse(t *testing.T) {
+	streams := 0
+	mockAI := &mocks.MockOllamaService{
+		GenerateStreamFunc: func(ctx context.Context, prompt string, onChunk func(chunk string) error) error {
+			streams++
+			onChunk("Jazz ")
+			return onChunk("swings.")
+		},
+	}
+	cache := llmcache.New(mockAI, llmcache.Config{Model: "test", TTL: time.Minute, MaxEntries: 10})
+
+	var chunks []string
+	collect := func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	}
+	cache.GenerateStream(context.Background(), "What is jazz?", collect)
+	chunks = nil
+	cache.GenerateStream(context.Background(), "What is jazz?", collect)
+
+	if streams != 1 {
+		t.Errorf("Expected a single upstream stream, got %d", streams)
+	}
+	if len(chunks) != 1 || chunks[0] != "Jazz swings." {
+		t.Errorf("Expected cached response as one chunk, got %v", chunks)
+	}
+}
@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/moderation"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeClassifier returns fixed scores
+type fakeClassifier struct {
+	scores map[string]float64
+	err    error
+}
+
+func (f fakeClassifier) Name() string { return "fake" }
+
+func (f fakeClassifier) Classify(text string) (map[string]float64, error) {
+	return f.scores, f.err
+}
+
+func TestModeration_ActsOnTheConfiguredCategories(t *testing.T) {
+	cases := []struct {
+		scores     map[string]float64
+		action     string
+		categories int
+	}{
+		{map[string]float64{"hate": 0.1, "harassment": 0.2}, models.ModerationAllow, 0},
+		{map[string]float64{"hate": 0.1, "harassment": 0.6}, models.ModerationFlag, 1},
+		{map[string]float64{"hate": 0.95, "harassment/threatening": 0.7}, models.ModerationReject, 2},
+		{map[string]float64{"sexual": 0.99}, models.ModerationAllow, 0}, // Not a configured category
+	}
+	for _, c := range cases {
+		service := moderation.New(fakeClassifier{scores: c.scores}, moderation.NewMemoryStore(), moderation.DefaultConfig())
+		verdict := service.Moderate("text")
+		if verdict.Action != c.action || len(verdict.Categories) != c.categories {
+			t.Errorf("Scores %v: expected %s with %d categories, got %s %v", c.scores, c.action, c.categories, verdict.Action, verdict.Categories)
+		}
+	}
+}
+
+func TestModeration_AllowsMessagesWhenTheClassifierFails(t *testing.T) {
+	service := moderation.New(fakeClassifier{err: errors.New("unavailable")}, moderation.NewMemoryStore(), moderation.DefaultConfig())
+	if verdict := service.Moderate("text"); verdict.Action != models.ModerationAllow {
+		t.Errorf("Expected the message to be allowed, got %s", verdict.Action)
+	}
+}
+
+func TestModeration_ReviewQueue(t *testing.T) {
+	service := moderation.New(fakeClassifier{}, moderation.NewMemoryStore(), moderation.DefaultConfig())
+
+	messageID := int64(7)
+	flagged, err := service.Record(models.ModerationVerdict{MessageID: &messageID, Text: "flagged", Action: models.ModerationFlag})
+	if err != nil || flagged.Status != models.ModerationPending {
+		t.Fatalf("Expected a pending verdict, got %+v, %v", flagged, err)
+	}
+	if rejected, err := service.Record(models.ModerationVerdict{Text: "rejected", Action: models.ModerationReject}); err != nil || rejected.Status != models.ModerationRejected {
+		t.Fatalf("Expected a rejected verdict, got %+v, %v", rejected, err)
+	}
+	if _, err := service.Record(models.ModerationVerdict{Action: models.ModerationAllow}); err == nil {
+		t.Error("Expected allowed messages not to be recorded")
+	}
+
+	queue, _ := service.Queue(models.ModerationPending, 50)
+	if len(queue) != 1 || queue[0].ID != flagged.ID {
+		t.Fatalf("Expected only the flagged message in the queue, got %+v", queue)
+	}
+	if all, _ := service.Queue("", 50); len(all) != 2 {
+		t.Errorf("Expected both verdicts without a status, got %d", len(all))
+	}
+
+	if _, err := service.Review(flagged.ID, "ignore", "mod"); err == nil {
+		t.Error("Expected an unknown decision to fail")
+	}
+	reviewed, err := service.Review(flagged.ID, moderation.DecisionApprove, "mod")
+	if err != nil || reviewed.Status != models.ModerationApproved || reviewed.ReviewedBy != "mod" || reviewed.ReviewedAt == nil {
+		t.Fatalf("Expected the verdict to be approved by mod, got %+v, %v", reviewed, err)
+	}
+	if _, err := service.Review(flagged.ID, moderation.DecisionRemove, "mod"); !errors.Is(err, moderation.ErrReviewed) {
+		t.Errorf("Expected ErrReviewed, got %v", err)
+	}
+	if _, err := service.Review(99, moderation.DecisionRemove, "mod"); !errors.Is(err, moderation.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if queue, _ := service.Queue(models.ModerationPending, 50); len(queue) != 0 {
+		t.Errorf("Expected an empty queue after review, got %+v", queue)
+	}
+}
+
+func TestLocalClassifier_ScoresKnownPhrases(t *testing.T) {
+	classifier := moderation.NewLocalClassifier()
+	service := moderation.New(classifier, moderation.NewMemoryStore(), moderation.DefaultConfig())
+
+	cases := map[string]string{
+		"Numb is my favourite song":     models.ModerationAllow,
+		"You’re worthless":              models.ModerationFlag,
+		"just KILL   yourself":          models.ModerationReject,
+		"I know where you live":         models.ModerationReject,
+		"skill yourself up on guitar":   models.ModerationAllow,
+		"the kids all like this record": models.ModerationAllow,
+	}
+	for text, expected := range cases {
+		if verdict := service.Moderate(text); verdict.Action != expected {
+			t.Errorf("Moderate(%q) = %s, expected %s", text, verdict.Action, expected)
+		}
+	}
+}
+
+func TestOpenAIClassifier_Classify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+			Input string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/v1/moderations" || r.Header.Get("Authorization") != "Bearer sk-test" || req.Model != "omni-moderation-latest" || req.Input != "hello" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"results": [{"flagged": false, "category_scores": {"hate": 0.01, "harassment": 0.7}}]}`))
+	}))
+	defer server.Close()
+
+	classifier := moderation.NewOpenAIClassifier(server.URL+"/v1/", "sk-test", "omni-moderation-latest")
+	if classifier.Name() != "openai/omni-moderation-latest" {
+		t.Errorf("Unexpected name %q", classifier.Name())
+	}
+	scores, err := classifier.Classify("hello")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if scores["harassment"] != 0.7 || scores["hate"] != 0.01 {
+		t.Errorf("Unexpected scores %v", scores)
+	}
+	if _, err := classifier.Classify("other"); err == nil {
+		t.Error("Expected an error status to fail")
+	}
+}
@@ -0,0 +1,200 @@
+package services_test
+
+import (
+	"backend/services/chunking"
+	"backend/services/mood"
+	"backend/tests/mocks"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newMoodService(t *testing.T, jsonResponse string) mood.Service {
+	mockAI := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(prompt string) (string, error) {
+			return jsonResponse, nil
+		},
+	}
+	return mood.New(&mocks.MockGeniusService{}, mockAI, t.TempDir(), nil)
+}
+
+func TestMoodService_DetectMood_ValidJSON(t *testing.T) {
+	service := newMoodService(t, `{"primary_mood": "lonely", "mood_score": 0.85, "emotion_tags": ["isolated"]}`)
+
+	analysis, err := service.DetectMood("I feel so alone")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if analysis.PrimaryMood != "lonely" {
+		t.Errorf("Expected primary mood lonely, got %s", analysis.PrimaryMood)
+	}
+	if analysis.MoodScore != 0.85 {
+		t.Errorf("Expected mood score 0.85, got %f", analysis.MoodScore)
+	}
+}
+
+func TestMoodService_DetectMood_InvalidJSON(t *testing.T) {
+	service := newMoodService(t, `I think you are feeling sad`)
+
+	if _, err := service.DetectMood("I feel sad"); err == nil {
+		t.Error("Expected error for non-JSON response")
+	}
+}
+
+func TestMoodService_DetectMood_SchemaViolations(t *testing.T) {
+	responses := []string{
+		`{"primary_mood": "melancholy", "mood_score": 0.5, "emotion_tags": []}`,
+		`{"primary_mood": "sad", "mood_score": 1.5, "emotion_tags": []}`,
+		`{"primary_mood": "sad", "mood_score": -0.1, "emotion_tags": []}`,
+	}
+
+	for _, response := range responses {
+		service := newMoodService(t, response)
+		if _, err := service.DetectMood("I feel sad"); err == nil {
+			t.Errorf("Expected validation error for response %s", response)
+		}
+	}
+}
+
+func TestMoodService_GetLyricsWithMood_InvalidJSON(t *testing.T) {
+	service := newMoodService(t, `not json`)
+
+	if _, err := service.GetLyricsWithMood("Numb", "Linkin Park"); err == nil {
+		t.Error("Expected error when lyrics analysis is not valid JSON")
+	}
+}
+
+func TestMoodService_GetLyricsWithMood_CondensesLongLyrics(t *testing.T) {
+	lyrics := strings.Repeat("Crawling in my skin, these wounds they will not heal\n", 200)
+	var prompt string
+	mockAI := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(string) (string, error) {
+			return "A summary of the section.", nil
+		},
+		GenerateJSONFunc: func(p string) (string, error) {
+			prompt = p
+			return `{"primary_mood": "anxious", "mood_score": 0.9, "emotion_tags": [], "themes": []}`, nil
+		},
+	}
+	genius := &mocks.MockGeniusService{
+		GetLyricsFunc: func(trackName, artistName string) (string, error) {
+			return lyrics, nil
+		},
+	}
+	service := mood.New(genius, chunking.New(mockAI, chunking.DefaultConfig()), t.TempDir(), nil)
+
+	result, err := service.GetLyricsWithMood("Crawling", "Linkin Park")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(prompt, lyrics) || !strings.Contains(prompt, "A summary of the section.") {
+		t.Errorf("Expected the condensed lyrics in the prompt, got %d tokens", chunking.CountTokens(prompt))
+	}
+	if result.Lyrics != lyrics {
+		t.Error("Expected the full lyrics in the result")
+	}
+}
+
+func TestMoodService_DetectMood_MixedMoods(t *testing.T) {
+	service := newMoodService(t, `{"primary_mood": "happy", "mood_score": 0.8, "emotion_tags": [],
+		"moods": [{"mood": "happy", "weight": 0.3}, {"mood": "nostalgic", "weight": 0.6}, {"mood": "happy", "weight": 0.3}, {"mood": "calm", "weight": 0.2}]}`)
+
+	analysis, err := service.DetectMood("Happy but nostalgic tonight")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Duplicates merge, weights scale to sum to 1, and the declared primary wins a tie
+	if len(analysis.Moods) != 3 || analysis.Moods[0].Mood != "happy" || analysis.Moods[1].Mood != "nostalgic" {
+		t.Fatalf("Expected happy, nostalgic, calm, got %+v", analysis.Moods)
+	}
+	if math.Abs(analysis.Moods[0].Weight-0.6/1.4) > 1e-9 || math.Abs(analysis.Moods[2].Weight-0.2/1.4) > 1e-9 {
+		t.Errorf("Expected normalized weights, got %+v", analysis.Moods)
+	}
+	if analysis.PrimaryMood != "happy" {
+		t.Errorf("Expected primary mood happy, got %s", analysis.PrimaryMood)
+	}
+}
+
+func TestMoodService_DetectMood_SingleMoodDropsList(t *testing.T) {
+	service := newMoodService(t, `{"primary_mood": "sad", "mood_score": 0.9, "emotion_tags": [], "moods": [{"mood": "sad", "weight": 1}]}`)
+
+	analysis, err := service.DetectMood("I feel sad")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if analysis.Moods != nil {
+		t.Errorf("Expected no mood list for a single mood, got %+v", analysis.Moods)
+	}
+}
+
+func TestMoodService_DetectMood_InvalidMoods(t *testing.T) {
+	responses := []string{
+		`{"primary_mood": "sad", "mood_score": 0.5, "emotion_tags": [], "moods": [{"mood": "gloomy", "weight": 0.5}]}`,
+		`{"primary_mood": "sad", "mood_score": 0.5, "emotion_tags": [], "moods": [{"mood": "happy", "weight": 2}]}`,
+	}
+
+	for _, response := range responses {
+		service := newMoodService(t, response)
+		if _, err := service.DetectMood("I feel sad"); err == nil {
+			t.Errorf("Expected validation error for response %s", response)
+		}
+	}
+}
+
+func TestMoodService_AddMoodNote(t *testing.T) {
+	service := newMoodService(t, `{}`)
+
+	if _, err := service.AddMoodNote("alice", "", "Rough day"); !errors.Is(err, mood.ErrMoodEntryNotFound) {
+		t.Errorf("Expected ErrMoodEntryNotFound without any mood entry, got %v", err)
+	}
+
+	service.SaveUserMoodHistory("alice", "sad", []string{"track-1"})
+	entries, _ := service.GetUserMoodHistory("alice")
+
+	entry, err := service.AddMoodNote("alice", "", "Rough day|at work\nbut better now")
+	if err != nil || entry != entries[0].Timestamp {
+		t.Fatalf("Expected the note on the latest entry, got %q, %v", entry, err)
+	}
+	service.AddMoodNote("alice", entry, "Better by the evening")
+
+	entries, err = service.GetUserMoodHistory("alice")
+	if err != nil || len(entries) != 1 || entries[0].Note != "Better by the evening" {
+		t.Errorf("Expected the latest note on the entry, got %+v, %v", entries, err)
+	}
+
+	if _, err := service.AddMoodNote("alice", "2001-01-01T00:00:00Z", "Too old"); !errors.Is(err, mood.ErrMoodEntryNotFound) {
+		t.Errorf("Expected ErrMoodEntryNotFound for an unknown entry, got %v", err)
+	}
+}
+
+func TestMoodService_RejectsTraversalUserIDs(t *testing.T) {
+	dataDir := t.TempDir()
+	service := mood.New(&mocks.MockGeniusService{}, &mocks.MockOllamaService{}, filepath.Join(dataDir, "data"), nil)
+
+	for _, userID := range []string{"x/../../pwn", `x\..\pwn`, "../pwn"} {
+		if err := service.SaveUserMoodHistory(userID, "sad", nil); !errors.Is(err, mood.ErrInvalidUserID) {
+			t.Errorf("Expected ErrInvalidUserID saving for %q, got %v", userID, err)
+		}
+		if _, err := service.GetUserMoodHistory(userID); !errors.Is(err, mood.ErrInvalidUserID) {
+			t.Errorf("Expected ErrInvalidUserID reading for %q, got %v", userID, err)
+		}
+		if _, err := service.AddMoodNote(userID, "", "note"); !errors.Is(err, mood.ErrInvalidUserID) {
+			t.Errorf("Expected ErrInvalidUserID noting for %q, got %v", userID, err)
+		}
+	}
+	if files, _ := filepath.Glob(filepath.Join(dataDir, "*.txt")); len(files) != 0 {
+		t.Errorf("Expected nothing written outside the data directory, got %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "pwn_mood_history.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected no traversal file, got %v", err)
+	}
+
+	if err := service.SaveUserMoodHistory("alice@example.com", "happy", nil); err != nil {
+		t.Errorf("Expected plain IDs to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package services_test
+
+import (
+	"backend/services/openai"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newOpenAITestServer(t *testing.T, check func(r *http.Request)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.Choice{{Message: openai.Message{Role: "assistant", Content: "ok"}}},
+		})
+	}))
+}
+
+func TestOpenAIService_StandardRouting(t *testing.T) {
+	server := newOpenAITestServer(t, func(r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("Expected path /chat/completions, got %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("Expected bearer auth, got %s", r.Header.Get("Authorization"))
+		}
+	})
+	defer server.Close()
+
+	config := openai.DefaultConfig()
+	config.APIKey = "test-key"
+	config.BaseURL = server.URL
+
+	if _, err := openai.New(config).GenerateResponse("hello"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestOpenAIService_AzureRouting(t *testing.T) {
+	server := newOpenAITestServer(t, func(r *http.Request) {
+		if r.URL.Path != "/openai/deployments/my-gpt/chat/completions" {
+			t.Errorf("Expected deployment path, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("api-version") != "2024-06-01" {
+			t.Errorf("Expected api-version 2024-06-01, got %s", r.URL.Query().Get("api-version"))
+		}
+		if r.Header.Get("api-key") != "azure-key" {
+			t.Errorf("Expected api-key header, got %s", r.Header.Get("api-key"))
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Expected no Authorization header, got %s", r.Header.Get("Authorization"))
+		}
+	})
+	defer server.Close()
+
+	config := openai.DefaultConfig()
+	config.APIKey = "azure-key"
+	config.BaseURL = server.URL + "/"
+	config.APIType = openai.APITypeAzure
+	config.Deployment = "my-gpt"
+	config.APIVersion = "2024-06-01"
+
+	if _, err := openai.New(config).GenerateResponse("hello"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestOpenAIService_AzureRequiresDeployment(t *testing.T) {
+	config := openai.DefaultConfig()
+	config.APIKey = "azure-key"
+	config.APIType = openai.APITypeAzure
+
+	if err := openai.New(config).IsAvailable(); err == nil {
+		t.Error("Expected error when Azure deployment is missing")
+	}
+}
+
+func TestOpenAIService_RetriesRateLimits(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]string{"message": "Rate limit reached"},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.Choice{{Message: openai.Message{Role: "assistant", Content: "ok"}}},
+		})
+	}))
+	defer server.Close()
+
+	config := openai.DefaultConfig()
+	config.APIKey = "test-key"
+	config.BaseURL = server.URL
+	config.RetryBaseDelay = time.Millisecond
+	config.RetryMaxDelay = 5 * time.Millisecond
+
+	response, err := openai.New(config).GenerateResponse("hello")
+	if err != nil || response != "ok" {
+		t.Fatalf("Expected success after retries, got %q, %v", response, err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestOpenAIService_GivesUpAfterMaxRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	config := openai.DefaultConfig()
+	config.APIKey = "test-key"
+	config.BaseURL = server.URL
+	config.MaxRetries = 2
+	config.RetryBaseDelay = time.Millisecond
+	config.RetryMaxDelay = 5 * time.Millisecond
+
+	if _, err := openai.New(config).GenerateResponse("hello"); err == nil {
+		t.Fatal("Expected an error after exhausting retries")
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 1 attempt plus 2 retries, got %d", attempts)
+	}
+}
+
+func TestOpenAIService_DoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]string{"message": "Invalid API key"},
+		})
+	}))
+	defer server.Close()
+
+	config := openai.DefaultConfig()
+	config.BaseURL = server.URL
+	config.APIKey = "bad-key"
+
+	if _, err := openai.New(config).GenerateResponse("hello"); err == nil {
+		t.Fatal("Expected an error for an invalid API key")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected no retries for a 401, got %d attempts", attempts)
+	}
+}
@@ -0,0 +1,66 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/presence"
+	"testing"
+	"time"
+)
+
+func TestPresence_HeartbeatsExpire(t *testing.T) {
+	service := presence.New(presence.Config{Timeout: 50 * time.Millisecond})
+	track := &models.PresenceTrack{TrackID: "t1", TrackName: "Numb", Artist: "Linkin Park"}
+
+	service.Heartbeat("alice", models.PresenceHeartbeat{Username: "Alice", ShareListening: true, NowPlaying: track})
+	service.Heartbeat("bob", models.PresenceHeartbeat{NowPlaying: track}) // Doesn't share
+
+	online := service.Online()
+	if len(online) != 2 {
+		t.Fatalf("Expected 2 users online, got %+v", online)
+	}
+	for _, p := range online {
+		switch p.UserID {
+		case "alice":
+			if p.Username != "Alice" || p.NowPlaying == nil || p.NowPlaying.TrackName != "Numb" {
+				t.Errorf("Expected alice to share her track, got %+v", p)
+			}
+		case "bob":
+			if p.NowPlaying != nil {
+				t.Errorf("Expected bob's track to stay private, got %+v", p.NowPlaying)
+			}
+		}
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	if online := service.Online(); len(online) != 0 {
+		t.Errorf("Expected heartbeats to expire, got %+v", online)
+	}
+}
+
+func TestPresence_ConnectionsKeepUsersOnline(t *testing.T) {
+	service := presence.New(presence.Config{Timeout: 50 * time.Millisecond})
+	track := &models.PresenceTrack{TrackID: "t1", TrackName: "Numb"}
+
+	service.Heartbeat("alice", models.PresenceHeartbeat{ShareListening: true, NowPlaying: track})
+	first := service.Connect("alice")
+	second := service.Connect("alice")
+	time.Sleep(80 * time.Millisecond)
+
+	online := service.Online()
+	if len(online) != 1 || online[0].UserID != "alice" {
+		t.Fatalf("Expected alice online while connected, got %+v", online)
+	}
+	if online[0].NowPlaying != nil {
+		t.Errorf("Expected the track to expire with the heartbeat, got %+v", online[0].NowPlaying)
+	}
+
+	first()
+	first() // Disconnecting twice has no effect
+	if online := service.Online(); len(online) != 1 {
+		t.Errorf("Expected alice online with a connection left, got %+v", online)
+	}
+	second()
+	if online := service.Online(); len(online) != 0 {
+		t.Errorf("Expected alice offline, got %+v", online)
+	}
+}
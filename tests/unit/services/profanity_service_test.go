@@ -0,0 +1,31 @@
+package services_test
+
+import (
+	"backend/services/profanity"
+	"testing"
+)
+
+func TestProfanity_MasksWholeWords(t *testing.T) {
+	filter := profanity.New(profanity.Config{})
+
+	if masked := filter.Mask("What the Fuck is this shit"); masked != "What the F*** is this s***" {
+		t.Errorf("Unexpected masking: %q", masked)
+	}
+	if masked := filter.Mask("Shitake mushrooms"); masked != "Shitake mushrooms" {
+		t.Errorf("Expected only whole words to be masked, got %q", masked)
+	}
+	if !filter.Contains("DAMN it") || filter.Contains("Numb by Linkin Park") {
+		t.Error("Expected Contains to match listed words only")
+	}
+}
+
+func TestProfanity_ExtraWords(t *testing.T) {
+	filter := profanity.New(profanity.Config{Words: []string{" frak ", "", "smeg-head"}})
+
+	if masked := filter.Mask("frak this smeg-head, damn"); masked != "f*** this s********, d***" {
+		t.Errorf("Expected extra words masked with the built-in ones, got %q", masked)
+	}
+	if profanity.New(profanity.Config{}).Contains("frak") {
+		t.Error("Expected extra words to apply only to their own filter")
+	}
+}
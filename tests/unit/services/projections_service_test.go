@@ -0,0 +1,73 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/events"
+	"backend/services/projections"
+	"testing"
+	"time"
+)
+
+func TestProjections_AggregatesEventsPerUserAndDay(t *testing.T) {
+	service := projections.New(projections.NewMemoryStore(), projections.DefaultConfig())
+	bus := events.New(events.DefaultConfig())
+	service.Register(bus)
+
+	bus.Publish(events.TrackChanged, models.UnifiedTrack{Name: "Numb"})
+	bus.Publish(events.TrackChanged, models.UnifiedTrack{Name: "In the End"})
+	bus.Publish(events.MessagePosted, models.Message{UserEmail: "a@example.com", Text: "hi"})
+	bus.Publish(events.MoodDetected, events.MoodDetectedPayload{
+		UserID:   "a@example.com",
+		Analysis: models.MoodAnalysis{PrimaryMood: "sad"},
+	})
+	bus.Publish(events.MoodDetected, events.MoodDetectedPayload{
+		UserID:   "a@example.com",
+		Analysis: models.MoodAnalysis{PrimaryMood: "sad"},
+	})
+	bus.Publish(events.RecommendationServed, events.RecommendationServedPayload{
+		UserID: "a@example.com",
+		Tracks: []models.UnifiedTrack{{Name: "One"}, {Name: "Two"}, {Name: "Three"}},
+	})
+	bus.Close()
+
+	stats, err := service.UserStats("a@example.com", 7)
+	if err != nil {
+		t.Fatalf("UserStats failed: %v", err)
+	}
+	if len(stats.Days) != 1 || stats.Days[0].Day != time.Now().UTC().Format("2006-01-02") {
+		t.Fatalf("Expected a single row for today, got %+v", stats.Days)
+	}
+	total := stats.Total
+	if total.MessagesPosted != 1 || total.MoodsDetected != 2 || total.RecommendationsServed != 3 || total.TracksPlayed != 0 {
+		t.Errorf("Unexpected totals: %+v", total)
+	}
+	if total.Moods["sad"] != 2 {
+		t.Errorf("Expected 2 sad detections, got %d", total.Moods["sad"])
+	}
+
+	defaultStats, _ := service.UserStats("default_user", 1)
+	if defaultStats.Total.TracksPlayed != 2 {
+		t.Errorf("Expected track changes attributed to default_user, got %d", defaultStats.Total.TracksPlayed)
+	}
+}
+
+func TestProjections_WindowExcludesOlderDays(t *testing.T) {
+	store := projections.NewMemoryStore()
+	service := projections.New(store, projections.DefaultConfig())
+
+	today := time.Now().UTC()
+	store.Apply(projections.Delta{UserID: "u", Day: today.Format("2006-01-02"), TracksPlayed: 1})
+	store.Apply(projections.Delta{UserID: "u", Day: today.AddDate(0, 0, -1).Format("2006-01-02"), TracksPlayed: 2})
+	store.Apply(projections.Delta{UserID: "u", Day: today.AddDate(0, 0, -10).Format("2006-01-02"), TracksPlayed: 4})
+
+	stats, err := service.UserStats("u", 7)
+	if err != nil {
+		t.Fatalf("UserStats failed: %v", err)
+	}
+	if len(stats.Days) != 2 || stats.Total.TracksPlayed != 3 {
+		t.Errorf("Expected two days totalling 3 tracks, got %+v", stats)
+	}
+	if stats.Days[0].Day > stats.Days[1].Day {
+		t.Errorf("Expected days oldest first, got %s then %s", stats.Days[0].Day, stats.Days[1].Day)
+	}
+}
@@ -0,0 +1,125 @@
+package services_test
+
+import (
+	"backend/services/prompts"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrompts_BuiltIn(t *testing.T) {
+	prompt, err := prompts.Default().Render(prompts.LyricsAnalysis, prompts.LyricsAnalysisData{SongInfo: "Numb by Linkin Park", Query: "What is it about?"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(prompt, `You are analyzing "Numb by Linkin Park".`) || !strings.Contains(prompt, "Question: What is it about?") || strings.HasSuffix(prompt, "\n") {
+		t.Errorf("Unexpected prompt %q", prompt)
+	}
+
+	if _, err := prompts.Default().Render("missing", nil); !errors.Is(err, prompts.ErrUnknownTemplate) {
+		t.Errorf("Expected ErrUnknownTemplate, got %v", err)
+	}
+}
+
+func TestPrompts_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "mood_detection.tmpl"), []byte("Mood of {{.Message}}?\n"), 0o644)
+	file := filepath.Join(t.TempDir(), "lyrics.tmpl")
+	os.WriteFile(file, []byte("Lyrics mood: {{.Lyrics}}"), 0o644)
+
+	service, err := prompts.New(prompts.Config{Dir: dir, Files: map[string]string{prompts.LyricsMood: file}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if prompt, _ := service.Render(prompts.MoodDetection, prompts.MoodDetectionData{Message: "tired"}); prompt != "Mood of tired?" {
+		t.Errorf("Expected the directory override, got %q", prompt)
+	}
+	if prompt, _ := service.Render(prompts.LyricsMood, prompts.LyricsMoodData{Lyrics: "la la"}); prompt != "Lyrics mood: la la" {
+		t.Errorf("Expected the file override, got %q", prompt)
+	}
+	if prompt, _ := service.Render(prompts.LyricsAnalysis, prompts.LyricsAnalysisData{}); !strings.HasPrefix(prompt, "You are analyzing") {
+		t.Errorf("Expected the built-in template without an override, got %q", prompt)
+	}
+}
+
+func TestPrompts_InvalidOverrides(t *testing.T) {
+	for name, source := range map[string]string{
+		"mood_detection": "{{.Message",   // Doesn't parse
+		"lyrics_mood":    "{{.Songs}}",   // Unknown field
+		"mood_detecton":  "{{.Message}}", // Unknown template
+	} {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, name+".tmpl"), []byte(source), 0o644)
+		if _, err := prompts.New(prompts.Config{Dir: dir}); err == nil {
+			t.Errorf("Expected %s with %q to be rejected", name, source)
+		}
+	}
+}
+
+func TestPrompts_ReloadIfChanged(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mood_detection.tmpl")
+	os.WriteFile(path, []byte("v1 {{.Message}}"), 0o644)
+	service, err := prompts.New(prompts.Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if reloaded, err := service.ReloadIfChanged(); reloaded || err != nil {
+		t.Errorf("Expected no reload without changes, got %v, %v", reloaded, err)
+	}
+
+	os.WriteFile(path, []byte("v2 {{.Message}}"), 0o644)
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Minute))
+	if reloaded, err := service.ReloadIfChanged(); !reloaded || err != nil {
+		t.Errorf("Expected a reload after a change, got %v, %v", reloaded, err)
+	}
+	if prompt, _ := service.Render(prompts.MoodDetection, prompts.MoodDetectionData{Message: "hi"}); prompt != "v2 hi" {
+		t.Errorf("Expected the changed template, got %q", prompt)
+	}
+
+	// A broken change keeps the previous templates
+	os.WriteFile(path, []byte("v3 {{.Nope}}"), 0o644)
+	os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute))
+	if _, err := service.ReloadIfChanged(); err == nil {
+		t.Error("Expected the broken template to be reported")
+	}
+	if prompt, _ := service.Render(prompts.MoodDetection, prompts.MoodDetectionData{Message: "hi"}); prompt != "v2 hi" {
+		t.Errorf("Expected the previous template to stay in use, got %q", prompt)
+	}
+
+	// Removing the override restores the built-in template
+	os.Remove(path)
+	if reloaded, err := service.ReloadIfChanged(); !reloaded || err != nil {
+		t.Errorf("Expected a reload after the removal, got %v, %v", reloaded, err)
+	}
+	if prompt, _ := service.Render(prompts.MoodDetection, prompts.MoodDetectionData{Message: "hi"}); !strings.HasPrefix(prompt, "Analyze the following message") {
+		t.Errorf("Expected the built-in template, got %q", prompt)
+	}
+}
+
+func TestPrompts_Variants(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "lyrics_analysis.concise.tmpl"), []byte("One line on {{.SongInfo}}: {{.Query}}"), 0o644)
+	service, err := prompts.New(prompts.Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !service.Has("lyrics_analysis.concise") || !service.Has(prompts.LyricsAnalysis) || service.Has("lyrics_analysis.verbose") {
+		t.Error("Expected the variant and the built-in template to be loaded")
+	}
+	prompt, err := service.Render("lyrics_analysis.concise", prompts.LyricsAnalysisData{SongInfo: "Numb", Query: "why?"})
+	if err != nil || prompt != "One line on Numb: why?" {
+		t.Errorf("Expected the variant prompt, got %q, %v", prompt, err)
+	}
+
+	// Variants are checked against their template's data
+	os.WriteFile(filepath.Join(dir, "mood_detection.strict.tmpl"), []byte("{{.Lyrics}}"), 0o644)
+	if _, err := prompts.New(prompts.Config{Dir: dir}); err == nil {
+		t.Error("Expected a variant using another template's data to be rejected")
+	}
+}
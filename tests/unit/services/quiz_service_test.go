@@ -0,0 +1,194 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/events"
+	"backend/services/quiz"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// historyFunc adapts a function to quiz.History
+type historyFunc func() []models.PlayHistoryItem
+
+func (f historyFunc) GetPlayHistory() []models.PlayHistoryItem {
+	return f()
+}
+
+// lyricsFunc adapts a function to quiz.Lyrics
+type lyricsFunc func(trackName, artistName string) (string, error)
+
+func (f lyricsFunc) GetLyrics(trackName, artistName string) (string, error) {
+	return f(trackName, artistName)
+}
+
+var quizLyrics = map[string]string{
+	"Numb":               "[Verse 1]\nI'm tired of being what you want me to be\nFeeling so faithless, lost under the surface\n\n[Chorus]\nI've become so numb, I can't feel you there",
+	"In the End":         "[Intro]\nIt starts with one thing, I don't know why\nIt doesn't even matter how hard you try\nIn the end, it doesn't even matter",
+	"Breaking the Habit": "Memories consume\nLike opening the wound",
+}
+
+func quizHistory(names ...string) historyFunc {
+	return func() []models.PlayHistoryItem {
+		var items []models.PlayHistoryItem
+		for _, name := range names {
+			items = append(items, models.PlayHistoryItem{TrackID: name, TrackName: name, Artist: "Linkin Park"})
+		}
+		return items
+	}
+}
+
+func quizLyricsSource() lyricsFunc {
+	return func(trackName, artistName string) (string, error) {
+		if lyrics, ok := quizLyrics[trackName]; ok {
+			return lyrics, nil
+		}
+		return "", errors.New("no lyrics found")
+	}
+}
+
+// trackForSnippet finds which song a round's snippet was taken from
+func trackForSnippet(snippet string) string {
+	for name, lyrics := range quizLyrics {
+		if strings.Contains(lyrics, snippet) {
+			return name
+		}
+	}
+	return ""
+}
+
+func quizTestConfig() quiz.Config {
+	config := quiz.DefaultConfig()
+	config.Rounds = 2
+	config.AnswerTime = 300 * time.Millisecond
+	config.RevealTime = 10 * time.Millisecond
+	return config
+}
+
+func TestQuizService_PlaysGameAndRecordsScores(t *testing.T) {
+	bus := events.New(events.DefaultConfig())
+	defer bus.Close()
+
+	rounds := make(chan models.QuizRound, 10)
+	results := make(chan models.QuizRoundResult, 10)
+	ended := make(chan models.QuizGame, 1)
+	bus.Subscribe(events.QuizRoundStarted, "test", func(event events.Event) error {
+		rounds <- event.Payload.(models.QuizRound)
+		return nil
+	})
+	bus.Subscribe(events.QuizRoundEnded, "test", func(event events.Event) error {
+		results <- event.Payload.(models.QuizRoundResult)
+		return nil
+	})
+	bus.Subscribe(events.QuizEnded, "test", func(event events.Event) error {
+		ended <- event.Payload.(models.QuizGame)
+		return nil
+	})
+
+	store := quiz.NewMemoryStore()
+	service := quiz.New(quizHistory("Numb", "In the End", "Faint"), quizLyricsSource(), store, bus, quizTestConfig())
+
+	game, err := service.Start(models.QuizGlobalRoom)
+	if err != nil {
+		t.Fatalf("Failed to start quiz: %v", err)
+	}
+	if game.Rounds != 2 {
+		t.Errorf("Expected 2 rounds, got %d", game.Rounds)
+	}
+	if _, err := service.Start(models.QuizGlobalRoom); !errors.Is(err, quiz.ErrGameRunning) {
+		t.Errorf("Expected ErrGameRunning for a second game, got %v", err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		round := <-rounds
+		if round.Number != i || round.Of != 2 || len(round.Choices) != 3 {
+			t.Fatalf("Unexpected round %+v", round)
+		}
+		answer := trackForSnippet(round.Snippet)
+		if answer == "" || strings.Contains(strings.ToLower(round.Snippet), strings.ToLower(answer)) {
+			t.Fatalf("Snippet %q should come from a song without naming it", round.Snippet)
+		}
+		wrong := ""
+		for _, choice := range round.Choices {
+			if choice != answer {
+				wrong = choice
+			}
+		}
+
+		if _, err := service.Answer(models.QuizGlobalRoom, "alice", "Not a choice"); !errors.Is(err, quiz.ErrInvalidAnswer) {
+			t.Errorf("Expected ErrInvalidAnswer, got %v", err)
+		}
+		result, err := service.Answer(models.QuizGlobalRoom, "alice", strings.ToUpper(answer))
+		if err != nil || !result.Correct || result.Points <= 100 || result.Points > 1000 {
+			t.Errorf("Expected a correct answer worth more than the minimum, got %+v, %v", result, err)
+		}
+		if _, err := service.Answer(models.QuizGlobalRoom, "alice", answer); !errors.Is(err, quiz.ErrAlreadyAnswered) {
+			t.Errorf("Expected ErrAlreadyAnswered, got %v", err)
+		}
+		result, err = service.Answer(models.QuizGlobalRoom, "bob", wrong)
+		if err != nil || result.Correct || result.Points != 0 {
+			t.Errorf("Expected a wrong answer worth nothing, got %+v, %v", result, err)
+		}
+
+		revealed := <-results
+		if revealed.TrackName != answer || revealed.Points["alice"] == 0 {
+			t.Errorf("Expected %s revealed with alice's points, got %+v", answer, revealed)
+		}
+	}
+
+	final := <-ended
+	if !final.Finished || len(final.Standings) != 2 || final.Standings[0].UserID != "alice" || final.Standings[0].CorrectAnswers != 2 {
+		t.Errorf("Expected alice to win with 2 correct answers, got %+v", final.Standings)
+	}
+	if _, err := service.Answer(models.QuizGlobalRoom, "alice", "Numb"); !errors.Is(err, quiz.ErrNoGame) {
+		t.Errorf("Expected ErrNoGame after the game, got %v", err)
+	}
+
+	leaderboard, err := service.Leaderboard(models.QuizGlobalRoom, 10)
+	if err != nil {
+		t.Fatalf("Failed to load leaderboard: %v", err)
+	}
+	if len(leaderboard) != 2 || leaderboard[0].UserID != "alice" || leaderboard[0].Points != final.Standings[0].Points || leaderboard[1].GamesPlayed != 1 {
+		t.Errorf("Expected the game's standings on the leaderboard, got %+v", leaderboard)
+	}
+}
+
+func TestQuizService_NeedsSongsWithLyrics(t *testing.T) {
+	bus := events.New(events.DefaultConfig())
+	defer bus.Close()
+
+	// One song can't make a multiple choice question
+	service := quiz.New(quizHistory("Numb", "Numb"), quizLyricsSource(), quiz.NewMemoryStore(), bus, quizTestConfig())
+	if _, err := service.Start(models.QuizGlobalRoom); !errors.Is(err, quiz.ErrNotEnoughTracks) {
+		t.Errorf("Expected ErrNotEnoughTracks for a single song, got %v", err)
+	}
+
+	// Songs without lyrics can only be wrong choices
+	service = quiz.New(quizHistory("Faint", "Papercut"), quizLyricsSource(), quiz.NewMemoryStore(), bus, quizTestConfig())
+	if _, err := service.Start(models.QuizGlobalRoom); !errors.Is(err, quiz.ErrNotEnoughTracks) {
+		t.Errorf("Expected ErrNotEnoughTracks without lyrics, got %v", err)
+	}
+	if _, ok := service.Game(models.QuizGlobalRoom); ok {
+		t.Error("Expected no game after a failed start")
+	}
+}
+
+func TestQuizService_LeaderboardsArePerRoom(t *testing.T) {
+	store := quiz.NewMemoryStore()
+	store.AddScores("global", []models.QuizScore{{UserID: "alice", Points: 500, CorrectAnswers: 1, GamesPlayed: 1}})
+	store.AddScores("global", []models.QuizScore{
+		{UserID: "alice", Points: 300, CorrectAnswers: 1, GamesPlayed: 1},
+		{UserID: "bob", Points: 900, CorrectAnswers: 2, GamesPlayed: 1},
+	})
+	store.AddScores("other", []models.QuizScore{{UserID: "carol", Points: 5000, GamesPlayed: 1}})
+
+	scores, _ := store.Leaderboard("global", 10)
+	if len(scores) != 2 || scores[0].UserID != "bob" || scores[1].Points != 800 || scores[1].GamesPlayed != 2 {
+		t.Errorf("Expected bob ahead of alice's combined 800 points, got %+v", scores)
+	}
+	if top, _ := store.Leaderboard("global", 1); len(top) != 1 {
+		t.Errorf("Expected the limit to apply, got %+v", top)
+	}
+}
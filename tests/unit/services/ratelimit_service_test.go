@@ -0,0 +1,176 @@
+package services_test
+
+import (
+	"backend/services/ratelimit"
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiter_SlidingWindow(t *testing.T) {
+	limiter := ratelimit.NewMemory()
+
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow("user:alice", 3, time.Minute)
+		if err != nil || !result.Allowed {
+			t.Fatalf("Expected request %d to be allowed, got %+v, %v", i+1, result, err)
+		}
+		if result.Remaining != 2-i {
+			t.Errorf("Expected %d remaining, got %d", 2-i, result.Remaining)
+		}
+	}
+
+	result, _ := limiter.Allow("user:alice", 3, time.Minute)
+	if result.Allowed {
+		t.Error("Expected the fourth request to be rejected")
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > time.Minute {
+		t.Errorf("Expected a retry after within the window, got %v", result.RetryAfter)
+	}
+
+	if result, _ := limiter.Allow("user:bob", 3, time.Minute); !result.Allowed {
+		t.Error("Expected other keys to have their own window")
+	}
+}
+
+func TestMemoryLimiter_WindowSlides(t *testing.T) {
+	limiter := ratelimit.NewMemory()
+
+	limiter.Allow("ip:10.0.0.1", 1, 50*time.Millisecond)
+	if result, _ := limiter.Allow("ip:10.0.0.1", 1, 50*time.Millisecond); result.Allowed {
+		t.Fatal("Expected the second request in the window to be rejected")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if result, _ := limiter.Allow("ip:10.0.0.1", 1, 50*time.Millisecond); !result.Allowed {
+		t.Error("Expected a request to be allowed once the window has passed")
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ratelimit.ParseRules("post /api/chat=30/1m, /api/*=600/1m")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %d", len(rules))
+	}
+	if rules[0].Method != "POST" || rules[0].Path != "/api/chat" || rules[0].Limit != 30 || rules[0].Window != time.Minute {
+		t.Errorf("Unexpected first rule: %+v", rules[0])
+	}
+
+	if !rules[0].Matches("POST", "/api/chat") || rules[0].Matches("GET", "/api/chat") || rules[0].Matches("POST", "/api/chat/stream") {
+		t.Error("Expected the method rule to match only POST /api/chat")
+	}
+	if !rules[1].Matches("GET", "/api/trending") || rules[1].Matches("GET", "/ws") {
+		t.Error("Expected the prefix rule to match paths under /api/")
+	}
+
+	for _, invalid := range []string{"/api/chat", "/api/chat=0/1m", "/api/chat=5/soon", "api/chat=5/1m", "GET POST /api=5/1m"} {
+		if _, err := ratelimit.ParseRules(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+// fakeRedis answers every command read from a connection with the next reply
+func fakeRedis(t *testing.T, replies ...string) (string, <-chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	commands := make(chan []string, len(replies))
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for _, reply := range replies {
+			command, err := readRESPCommand(reader)
+			if err != nil {
+				return
+			}
+			commands <- command
+			conn.Write([]byte(reply))
+		}
+	}()
+	return "redis://:secret@" + listener.Addr().String() + "/2", commands
+}
+
+// readRESPCommand reads a command sent as an array of bulk strings
+func readRESPCommand(reader *bufio.Reader) ([]string, error) {
+	readLength := func() (int, error) {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		return strconv.Atoi(strings.TrimSpace(line[1:]))
+	}
+
+	count, err := readLength()
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	for i := 0; i < count; i++ {
+		size, err := readLength()
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		args = append(args, string(arg[:size]))
+	}
+	return args, nil
+}
+
+func TestRedisLimiter_RunsSlidingWindowScript(t *testing.T) {
+	url, commands := fakeRedis(t, "+OK\r\n", "+OK\r\n", "*3\r\n:1\r\n:4\r\n:0\r\n", "*3\r\n:0\r\n:0\r\n:1500\r\n")
+
+	limiter, err := ratelimit.NewRedis(url, "test:")
+	if err != nil {
+		t.Fatalf("Failed to create limiter: %v", err)
+	}
+	if auth := <-commands; auth[0] != "AUTH" || auth[1] != "secret" {
+		t.Errorf("Expected AUTH with the URL password, got %v", auth)
+	}
+	if selectDB := <-commands; selectDB[0] != "SELECT" || selectDB[1] != "2" {
+		t.Errorf("Expected SELECT of the URL database, got %v", selectDB)
+	}
+
+	result, err := limiter.Allow("user:alice", 5, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Allowed || result.Remaining != 4 || result.Limit != 5 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	eval := <-commands
+	if eval[0] != "EVAL" || eval[3] != "test:user:alice" || eval[5] != "60000" || eval[6] != "5" {
+		t.Errorf("Unexpected EVAL arguments: %v", eval[2:])
+	}
+
+	result, err = limiter.Allow("user:alice", 5, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Allowed || result.RetryAfter != 1500*time.Millisecond {
+		t.Errorf("Expected a rejection with a 1.5s retry, got %+v", result)
+	}
+}
+
+func TestRedisLimiter_RejectsInvalidURL(t *testing.T) {
+	if _, err := ratelimit.NewRedis("http://localhost:6379", ""); err == nil {
+		t.Error("Expected non-redis URLs to be rejected")
+	}
+}
@@ -0,0 +1,258 @@
+package services_test
+
+import (
+	"backend/services/realtime"
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newEchoSocketServer serves WebSocket connections that broadcast every
+// message to all clients. Read errors are sent to errs.
+func newEchoSocketServer(t *testing.T, config realtime.Config, errs chan<- error) *httptest.Server {
+	hub := realtime.NewHub()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := realtime.Upgrade(w, r, config)
+		if err != nil {
+			return
+		}
+		defer hub.Subscribe("echo", conn)()
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				errs <- err
+				return
+			}
+			hub.Broadcast("echo", data)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// socketClient is a minimal WebSocket client
+type socketClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialSocket(t *testing.T, server *httptest.Server) *socketClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Origin: http://localhost:3000\r\nSec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("Failed to read handshake: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected 101, got %d", resp.StatusCode)
+	}
+	// The accept value for this key from RFC 6455 section 1.3
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Unexpected Sec-WebSocket-Accept %q", accept)
+	}
+	return &socketClient{conn: conn, reader: reader}
+}
+
+// writeFrame sends a final frame, masked unless masked is false
+func (c *socketClient) writeFrame(opcode byte, payload []byte, masked bool) {
+	frame := []byte{0x80 | opcode}
+	maskBit := byte(0)
+	if masked {
+		maskBit = 0x80
+	}
+	if len(payload) <= 125 {
+		frame = append(frame, maskBit|byte(len(payload)))
+	} else {
+		frame = append(frame, maskBit|126, byte(len(payload)>>8), byte(len(payload)))
+	}
+	if masked {
+		mask := []byte{1, 2, 3, 4}
+		frame = append(frame, mask...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+	c.conn.Write(frame)
+}
+
+// readFrame reads an unmasked server frame
+func (c *socketClient) readFrame(t *testing.T) (byte, []byte) {
+	t.Helper()
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		t.Fatalf("Failed to read frame: %v", err)
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var extended [2]byte
+		io.ReadFull(c.reader, extended[:])
+		length = int(binary.BigEndian.Uint16(extended[:]))
+	}
+	payload := make([]byte, length)
+	io.ReadFull(c.reader, payload)
+	return header[0] & 0x0F, payload
+}
+
+// expectClose reads frames until a close frame and checks its code
+func (c *socketClient) expectClose(t *testing.T, code int) {
+	t.Helper()
+	for {
+		opcode, payload := c.readFrame(t)
+		if opcode != 0x8 {
+			continue
+		}
+		if len(payload) < 2 || int(binary.BigEndian.Uint16(payload)) != code {
+			t.Fatalf("Expected close code %d, got %v", code, payload)
+		}
+		return
+	}
+}
+
+func TestRealtime_BroadcastsMessages(t *testing.T) {
+	errs := make(chan error, 2)
+	server := newEchoSocketServer(t, realtime.DefaultConfig(), errs)
+	sender := dialSocket(t, server)
+	receiver := dialSocket(t, server)
+
+	// Wait for both connections to subscribe
+	sender.writeFrame(0x9, []byte("ping"), true)
+	if opcode, payload := sender.readFrame(t); opcode != 0xA || string(payload) != "ping" {
+		t.Fatalf("Expected a pong echoing the ping, got %d %q", opcode, payload)
+	}
+	receiver.writeFrame(0x9, nil, true)
+	receiver.readFrame(t)
+
+	sender.writeFrame(0x1, []byte(`{"text":"hello"}`), true)
+	if opcode, payload := receiver.readFrame(t); opcode != 0x1 || string(payload) != `{"text":"hello"}` {
+		t.Errorf("Expected the message to be broadcast, got %d %q", opcode, payload)
+	}
+}
+
+func TestRealtime_RejectsForeignOrigins(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/ws/chat", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	rr := httptest.NewRecorder()
+
+	if _, err := realtime.Upgrade(rr, req, realtime.DefaultConfig()); err == nil {
+		t.Fatal("Expected the upgrade to fail")
+	}
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected 403, got %d", rr.Code)
+	}
+}
+
+func TestRealtime_RejectsPlainRequests(t *testing.T) {
+	rr := httptest.NewRecorder()
+	if _, err := realtime.Upgrade(rr, httptest.NewRequest("GET", "/api/ws/chat", nil), realtime.DefaultConfig()); err == nil {
+		t.Fatal("Expected the upgrade to fail")
+	}
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rr.Code)
+	}
+}
+
+func TestRealtime_ClosesOnOversizedMessages(t *testing.T) {
+	errs := make(chan error, 1)
+	config := realtime.DefaultConfig()
+	config.MaxMessageBytes = 16
+	client := dialSocket(t, newEchoSocketServer(t, config, errs))
+
+	client.writeFrame(0x1, []byte(strings.Repeat("x", 200)), true)
+	client.expectClose(t, realtime.CloseMessageTooBig)
+	if err := <-errs; !errors.Is(err, realtime.ErrMessageTooBig) {
+		t.Errorf("Expected ErrMessageTooBig, got %v", err)
+	}
+}
+
+func TestRealtime_ClosesOnTooManyMessages(t *testing.T) {
+	errs := make(chan error, 1)
+	config := realtime.DefaultConfig()
+	config.MessagesPerMinute = 10 // Bursts of one message
+	client := dialSocket(t, newEchoSocketServer(t, config, errs))
+
+	client.writeFrame(0x1, []byte("first"), true)
+	if _, payload := client.readFrame(t); string(payload) != "first" {
+		t.Fatalf("Expected the first message to be echoed, got %q", payload)
+	}
+	client.writeFrame(0x1, []byte("second"), true)
+	client.expectClose(t, realtime.ClosePolicyViolation)
+	if err := <-errs; !errors.Is(err, realtime.ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestRealtime_ClosesOnUnmaskedFrames(t *testing.T) {
+	errs := make(chan error, 1)
+	client := dialSocket(t, newEchoSocketServer(t, realtime.DefaultConfig(), errs))
+
+	client.writeFrame(0x1, []byte("hello"), false)
+	client.expectClose(t, realtime.CloseProtocolError)
+	if err := <-errs; !errors.Is(err, realtime.ErrProtocol) {
+		t.Errorf("Expected ErrProtocol, got %v", err)
+	}
+}
+
+func TestRealtime_TypingTrackerReportsChangesAndExpires(t *testing.T) {
+	changes := make(chan string, 10)
+	tracker := realtime.NewTypingTracker(50*time.Millisecond, func(room, user string, typing bool) {
+		changes <- fmt.Sprintf("%s/%s/%v", room, user, typing)
+	})
+	expect := func(want string) {
+		t.Helper()
+		select {
+		case got := <-changes:
+			if got != want {
+				t.Errorf("Expected change %s, got %s", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected change %s, got none", want)
+		}
+	}
+
+	tracker.Start("global", "alice")
+	tracker.Start("global", "alice") // A refresh isn't a change
+	tracker.Start("global", "bob")
+	expect("global/alice/true")
+	expect("global/bob/true")
+	if typing := tracker.Typing("global"); len(typing) != 2 || typing[0] != "alice" || typing[1] != "bob" {
+		t.Errorf("Expected alice and bob typing, got %v", typing)
+	}
+
+	tracker.Stop("global", "bob")
+	tracker.Stop("global", "bob") // Already stopped
+	expect("global/bob/false")
+
+	// alice stops refreshing
+	expect("global/alice/false")
+	if typing := tracker.Typing("global"); len(typing) != 0 {
+		t.Errorf("Expected nobody typing, got %v", typing)
+	}
+	select {
+	case got := <-changes:
+		t.Errorf("Expected no more changes, got %s", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
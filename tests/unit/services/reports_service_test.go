@@ -0,0 +1,154 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/mood"
+	"backend/services/reports"
+	"backend/tests/mocks"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePlays serves a fixed play history
+type fakePlays []models.PlayHistoryItem
+
+func (f fakePlays) QueryPlayHistory(filter models.PlayHistoryFilter, offset, limit int) ([]models.PlayHistoryItem, int, error) {
+	page, total := filter.Page(f, offset, limit)
+	return page, total, nil
+}
+
+func reportMoods(entries map[string][]mood.UserMoodEntry) *mocks.MockMoodService {
+	return &mocks.MockMoodService{
+		ListMoodUsersFunc: func() ([]string, error) {
+			users := make([]string, 0, len(entries))
+			for userID := range entries {
+				users = append(users, userID)
+			}
+			return users, nil
+		},
+		GetUserMoodHistoryFunc: func(userID string) ([]mood.UserMoodEntry, error) {
+			return entries[userID], nil
+		},
+	}
+}
+
+func at(value string) time.Time {
+	parsed, _ := time.Parse(time.RFC3339, value)
+	return parsed
+}
+
+func TestReportsService_Generate(t *testing.T) {
+	moods := reportMoods(map[string][]mood.UserMoodEntry{
+		"alice": {
+			{Timestamp: "2026-09-30T10:00:00Z", DetectedMood: "angry"}, // The week before
+			{Timestamp: "2026-10-06T10:00:00Z", DetectedMood: "sad"},
+			{Timestamp: "2026-10-07T20:00:00.5Z", DetectedMood: "sad"},
+			{Timestamp: "2026-10-08T12:00:00Z", DetectedMood: "happy"},
+		},
+	})
+	plays := fakePlays{
+		{TrackID: "numb", TrackName: "Numb", Artist: "Linkin Park", PlayedAt: at("2026-10-06T10:30:00Z"), PlayCount: 2},
+		{TrackID: "yellow", TrackName: "Yellow", Artist: "Coldplay", PlayedAt: at("2026-10-06T13:00:00Z")}, // Too long after the mood
+		{TrackID: "itend", TrackName: "In the End", Artist: "Linkin Park", PlayedAt: at("2026-10-07T20:10:00Z")},
+		{TrackID: "olt", TrackName: "One More Time", Artist: "Daft Punk", PlayedAt: at("2026-10-08T12:15:00Z")},
+		{TrackID: "old", TrackName: "Old", Artist: "Someone", PlayedAt: at("2026-09-30T10:10:00Z")},
+	}
+	writer := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			if !strings.Contains(prompt, `"sad":2`) {
+				t.Errorf("Expected the mood counts in the prompt, got %q", prompt)
+			}
+			return "  You had a reflective week.  ", nil
+		},
+	}
+	service := reports.New(moods, plays, writer)
+
+	report, err := service.Generate("alice", at("2026-10-05T00:00:00Z"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.MoodEntries != 3 || strings.Join(report.DominantMoods, ",") != "sad,happy" {
+		t.Errorf("Unexpected moods: %d entries, dominant %v", report.MoodEntries, report.DominantMoods)
+	}
+	if len(report.Correlations) != 2 || report.Correlations[0].Mood != "sad" || report.Correlations[0].Plays != 3 ||
+		strings.Join(report.Correlations[0].TopArtists, ",") != "Linkin Park" || report.Correlations[1].TopArtists[0] != "Daft Punk" {
+		t.Errorf("Unexpected correlations: %+v", report.Correlations)
+	}
+	if len(report.NotableTracks) != 4 || report.NotableTracks[0].Name != "Numb" || report.NotableTracks[0].Plays != 2 {
+		t.Errorf("Unexpected notable tracks: %+v", report.NotableTracks)
+	}
+	if report.Summary != "You had a reflective week." {
+		t.Errorf("Expected the AI summary, got %q", report.Summary)
+	}
+	if latest, ok := service.Latest("alice"); !ok || !latest.WeekStart.Equal(report.WeekStart) {
+		t.Errorf("Expected the report to be kept, got %+v", latest)
+	}
+}
+
+func TestReportsService_GenerateFallsBackToTemplate(t *testing.T) {
+	moods := reportMoods(map[string][]mood.UserMoodEntry{
+		"alice": {{Timestamp: "2026-10-06T10:00:00Z", DetectedMood: "calm"}},
+	})
+	writer := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			return "", errors.New("model unavailable")
+		},
+	}
+	service := reports.New(moods, nil, writer)
+
+	report, err := service.Generate("alice", at("2026-10-05T00:00:00Z"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Summary != "This week you mostly felt calm, across 1 mood check-ins." {
+		t.Errorf("Expected the template summary, got %q", report.Summary)
+	}
+
+	// A week without moods isn't reported
+	if _, err := service.Generate("bob", at("2026-10-05T00:00:00Z")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := service.Latest("bob"); ok {
+		t.Error("Expected no report for a week without moods")
+	}
+}
+
+func TestReportsService_RunReportsLastWeekOnce(t *testing.T) {
+	lastWeek := reports.WeekStart(time.Now()).Add(-7 * 24 * time.Hour)
+	calls := 0
+	writer := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			calls++
+			return "Recap", nil
+		},
+	}
+	moods := reportMoods(map[string][]mood.UserMoodEntry{
+		"alice": {{Timestamp: lastWeek.Add(time.Hour).Format(time.RFC3339), DetectedMood: "happy"}},
+		"bob":   {},
+	})
+	service := reports.New(moods, nil, writer)
+
+	if generated := service.Run(); generated != 1 {
+		t.Errorf("Expected one report, got %d", generated)
+	}
+	service.Run()
+	if calls != 1 {
+		t.Errorf("Expected an existing report not to be regenerated, got %d summaries", calls)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	tests := map[string]string{
+		"2026-10-05T00:00:00Z":      "2026-10-05T00:00:00Z",
+		"2026-10-11T23:59:59Z":      "2026-10-05T00:00:00Z",
+		"2026-10-12T01:00:00+02:00": "2026-10-05T00:00:00Z", // Still Sunday in UTC
+	}
+	for input, want := range tests {
+		if got := reports.WeekStart(at(input)); !got.Equal(at(want)) {
+			t.Errorf("WeekStart(%s) = %s, want %s", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/restricted"
+	"backend/tests/mocks"
+	"strings"
+	"testing"
+)
+
+func TestRestricted_PerUserAndDeployment(t *testing.T) {
+	service := restricted.New(restricted.Config{Users: []string{"teen"}})
+	if !service.IsRestricted("teen") || service.IsRestricted("adult") {
+		t.Fatal("Expected only the configured user to be restricted")
+	}
+
+	service.SetRestricted("adult", true)
+	service.SetRestricted("teen", false)
+	if service.IsRestricted("teen") || !service.IsRestricted("adult") {
+		t.Error("Expected per-user settings to be updated")
+	}
+
+	deployment := restricted.New(restricted.Config{Deployment: true})
+	deployment.SetRestricted("anyone", false)
+	if status := deployment.Status("anyone"); !status.Restricted || !status.Deployment {
+		t.Errorf("Expected deployment-wide restriction to win, got %+v", status)
+	}
+}
+
+func TestRestricted_ContentRules(t *testing.T) {
+	if masked := restricted.MaskProfanity("What the Fuck is this shit"); masked != "What the F*** is this s***" {
+		t.Errorf("Unexpected masking: %q", masked)
+	}
+	if masked := restricted.MaskProfanity("Shitake mushrooms"); masked != "Shitake mushrooms" {
+		t.Errorf("Expected only whole words to be masked, got %q", masked)
+	}
+
+	if !restricted.ContainsMention("hey @sam, listen to this") || restricted.ContainsMention("email me at a@b.com") {
+		t.Error("Expected @mentions, but not email addresses, to be detected")
+	}
+
+	service := restricted.New(restricted.Config{})
+	tracks := service.FilterTracks([]models.UnifiedTrack{{ID: "1"}, {ID: "2", Explicit: true}})
+	if len(tracks) != 1 || tracks[0].ID != "1" {
+		t.Errorf("Expected explicit tracks to be removed, got %+v", tracks)
+	}
+}
+
+func TestRestricted_WrapAIUsesStricterPrompt(t *testing.T) {
+	var gotPrompt, gotLyrics string
+	ai := &mocks.MockOllamaService{
+		GenerateResponseFunc: func(prompt string) (string, error) {
+			gotPrompt = prompt
+			return "That damn song is great", nil
+		},
+		AnalyzeLyricsFunc: func(query, lyrics, songInfo string) (string, error) {
+			gotLyrics = lyrics
+			return "ok", nil
+		},
+	}
+	wrapped := restricted.New(restricted.Config{}).WrapAI(ai)
+
+	answer, _ := wrapped.GenerateResponse("Tell me about Numb")
+	if !strings.HasPrefix(gotPrompt, restricted.SystemPrompt) || !strings.HasSuffix(gotPrompt, "Tell me about Numb") {
+		t.Errorf("Expected the restricted system prompt to be prepended, got %q", gotPrompt)
+	}
+	if answer != "That d*** song is great" {
+		t.Errorf("Expected profanity masked in the answer, got %q", answer)
+	}
+
+	wrapped.AnalyzeLyrics("meaning?", "shit happens", "Song by Artist")
+	if gotLyrics != "s*** happens" {
+		t.Errorf("Expected lyrics masked before analysis, got %q", gotLyrics)
+	}
+}
@@ -0,0 +1,72 @@
+package services_test
+
+import (
+	"backend/services/retention"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetention_ArchivesExpiredMoodHistory(t *testing.T) {
+	dataDir := t.TempDir()
+	archiveDir := t.TempDir()
+	os.MkdirAll(filepath.Join(dataDir, "mood_history"), 0755)
+
+	old := time.Now().AddDate(-3, 0, 0).Format(time.RFC3339)
+	recent := time.Now().AddDate(0, -1, 0).Format(time.RFC3339)
+	historyFile := filepath.Join(dataDir, "mood_history", "user_a_mood_history.txt")
+	os.WriteFile(historyFile, []byte(old+"|sad|Numb\n"+recent+"|happy|Faint\n"), 0644)
+
+	service := retention.New(nil, retention.NewDirSink(archiveDir), retention.Config{
+		DataDir:              dataDir,
+		MoodHistoryRetention: 2 * 365 * 24 * time.Hour,
+	})
+	report := service.Run()
+
+	if report.MoodEntriesArchived != 1 || len(report.Archives) != 1 || len(report.Errors) != 0 {
+		t.Fatalf("Expected one archived entry, got %+v", report)
+	}
+
+	remaining, _ := os.ReadFile(historyFile)
+	if string(remaining) != recent+"|happy|Faint\n" {
+		t.Errorf("Expected only the recent entry to remain, got %q", remaining)
+	}
+
+	compressed, err := os.ReadFile(filepath.Join(archiveDir, report.Archives[0]))
+	if err != nil {
+		t.Fatalf("Expected archive to be written: %v", err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("Expected a gzip archive: %v", err)
+	}
+	archived, _ := io.ReadAll(reader)
+	if !strings.HasPrefix(string(archived), old+"|sad|Numb") {
+		t.Errorf("Expected the expired entry in the archive, got %q", archived)
+	}
+
+	if last, ok := service.LastReport(); !ok || last.MoodEntriesArchived != 1 {
+		t.Errorf("Expected LastReport to return the pass, got %+v", last)
+	}
+}
+
+func TestRetention_ZeroRetentionKeepsEverything(t *testing.T) {
+	dataDir := t.TempDir()
+	os.MkdirAll(filepath.Join(dataDir, "mood_history"), 0755)
+	historyFile := filepath.Join(dataDir, "mood_history", "user_a_mood_history.txt")
+	content := time.Now().AddDate(-10, 0, 0).Format(time.RFC3339) + "|sad|Numb\n"
+	os.WriteFile(historyFile, []byte(content), 0644)
+
+	service := retention.New(nil, retention.NewDirSink(t.TempDir()), retention.Config{DataDir: dataDir})
+	if report := service.Run(); report.MoodEntriesArchived != 0 {
+		t.Errorf("Expected nothing archived, got %+v", report)
+	}
+	if remaining, _ := os.ReadFile(historyFile); string(remaining) != content {
+		t.Errorf("Expected history untouched, got %q", remaining)
+	}
+}
@@ -0,0 +1,86 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/safety"
+	"testing"
+)
+
+func TestSafetyService_Screen(t *testing.T) {
+	service := safety.New(safety.Config{})
+
+	crises := []string{
+		"I want to die",
+		"Sometimes I think about killing myself",
+		"I don’t want to live anymore",
+		"I keep wanting to hurt myself",
+		"Ya no quiero vivir",
+		"J'ai envie de mourir",
+	}
+	for _, message := range crises {
+		if !service.Screen(message) {
+			t.Errorf("Expected %q to be recognized as a crisis", message)
+		}
+	}
+
+	others := []string{
+		"I feel so sad and alone",
+		"I want to diet before summer, give me workout songs",
+		"Songs for when I'm dying of boredom",
+	}
+	for _, message := range others {
+		if service.Screen(message) {
+			t.Errorf("Expected %q not to be recognized as a crisis", message)
+		}
+	}
+}
+
+func TestSafetyService_Resources(t *testing.T) {
+	service := safety.New(safety.Config{
+		Helplines: []models.Helpline{
+			{Region: "GB", Name: "Samaritans", Phone: "116 123"},
+			{Region: "US", Name: "988 Lifeline", Phone: "988"},
+			{Region: "*", Name: "Find A Helpline", URL: "https://findahelpline.com"},
+		},
+		DefaultRegion: "us",
+	})
+
+	tests := []struct {
+		region string
+		want   string
+	}{
+		{"gb", "Samaritans"},
+		{"FR", "988 Lifeline"}, // No helplines for the region; the default one is used
+		{"", "988 Lifeline"},
+	}
+	for _, tt := range tests {
+		resources := service.Resources(tt.region)
+		if len(resources) != 2 || resources[0].Name != tt.want || resources[1].Region != "*" {
+			t.Errorf("Region %q: expected %s and the international helpline, got %+v", tt.region, tt.want, resources)
+		}
+	}
+}
+
+func TestParseHelplines(t *testing.T) {
+	helplines, err := safety.ParseHelplines("gb=Samaritans|116 123|https://www.samaritans.org; *=Find A Helpline||https://findahelpline.com;")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(helplines) != 2 || helplines[0].Region != "GB" || helplines[0].Phone != "116 123" || helplines[1].Phone != "" {
+		t.Errorf("Unexpected helplines: %+v", helplines)
+	}
+
+	invalid := []string{
+		"Samaritans|116 123|https://www.samaritans.org",
+		"GBR=Samaritans|116 123|",
+		"GB=Samaritans|116 123",
+		"GB=|116 123|",
+		"GB=Samaritans||",
+		"GB=Samaritans||www.samaritans.org",
+	}
+	for _, value := range invalid {
+		if _, err := safety.ParseHelplines(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
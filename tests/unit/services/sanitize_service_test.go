@@ -0,0 +1,57 @@
+package services_test
+
+import (
+	"backend/services/sanitize"
+	"backend/tests/mocks"
+	"errors"
+	"testing"
+)
+
+const scrapedLyrics = "42 ContributorsTranslationsEspañolNumb Lyrics[Verse 1]\r\nI'm tired of being what you want me to be\r\n\r\n\r\n\r\n[Chorus]\r\nI've become so numb,   I can't feel you there\r\nYou might also like\r\nSee Linkin Park LiveGet tickets as low as $45\r\n[Outro]  \r\nI've become so numb\r\n128Embed"
+
+func TestSanitize_DefaultConfigRemovesNoise(t *testing.T) {
+	got := sanitize.New(sanitize.DefaultConfig()).Sanitize(scrapedLyrics)
+
+	want := "[Verse 1]\nI'm tired of being what you want me to be\n\n[Chorus]\nI've become so numb, I can't feel you there\n\n[Outro]\nI've become so numb"
+	if got != want {
+		t.Errorf("Unexpected sanitized lyrics:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSanitize_StripSectionMarkers(t *testing.T) {
+	config := sanitize.DefaultConfig()
+	config.StripSectionMarkers = true
+	got := sanitize.New(config).Sanitize(scrapedLyrics)
+
+	want := "I'm tired of being what you want me to be\n\nI've become so numb, I can't feel you there\n\nI've become so numb"
+	if got != want {
+		t.Errorf("Unexpected sanitized lyrics:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSanitize_StepsCanBeTurnedOff(t *testing.T) {
+	lyrics := "Line one  \n\n\n\nLine two Embed"
+	if got := sanitize.New(sanitize.Config{}).Sanitize(lyrics); got != "Line one  \n\n\n\nLine two Embed" {
+		t.Errorf("Expected lyrics left alone without steps, got %q", got)
+	}
+}
+
+func TestSanitize_WrapsGenius(t *testing.T) {
+	genius := &mocks.MockGeniusService{
+		GetLyricsFunc: func(trackName, artistName string) (string, error) {
+			if trackName == "missing" {
+				return "", errors.New("not found")
+			}
+			return "3 ContributorsIn the End Lyrics[Intro]\nIt starts with one thing\nEmbed", nil
+		},
+	}
+	service := sanitize.NewGenius(genius, sanitize.New(sanitize.DefaultConfig()))
+
+	lyrics, err := service.GetLyrics("In the End", "Linkin Park")
+	if err != nil || lyrics != "[Intro]\nIt starts with one thing" {
+		t.Errorf("Expected sanitized lyrics, got %q, %v", lyrics, err)
+	}
+	if _, err := service.GetLyrics("missing", "Linkin Park"); err == nil {
+		t.Error("Expected the Genius error to be passed on")
+	}
+}
@@ -0,0 +1,147 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/scheduler"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForJob polls the scheduler until the named job satisfies done
+func waitForJob(t *testing.T, s scheduler.Service, name string, done func(models.ScheduledJob) bool) models.ScheduledJob {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		for _, job := range s.Jobs() {
+			if job.Name == name && done(job) {
+				return job
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for job %s: %+v", name, s.Jobs())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestScheduler_RunsJobsEveryInterval(t *testing.T) {
+	s := scheduler.New(scheduler.Config{Jitter: 0.5})
+	var runs atomic.Int32
+	err := s.Register(scheduler.Job{Name: "count", Interval: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	job := waitForJob(t, s, "count", func(job models.ScheduledJob) bool { return job.Runs >= 3 })
+	if !job.Enabled || job.Interval != "10ms" || job.LastStartedAt == nil || job.NextRunAt == nil || job.Failures != 0 {
+		t.Errorf("Unexpected job status: %+v", job)
+	}
+	if runs.Load() < 3 {
+		t.Errorf("Expected at least 3 runs, got %d", runs.Load())
+	}
+}
+
+func TestScheduler_SkipsOverlappingRuns(t *testing.T) {
+	s := scheduler.New(scheduler.Config{})
+	release := make(chan struct{})
+	var running, maxRunning atomic.Int32
+	s.Register(scheduler.Job{Name: "slow", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+		if n := running.Add(1); n > maxRunning.Load() {
+			maxRunning.Store(n)
+		}
+		<-release
+		running.Add(-1)
+		return nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	waitForJob(t, s, "slow", func(job models.ScheduledJob) bool { return job.Running && job.Skipped >= 2 })
+	close(release)
+	waitForJob(t, s, "slow", func(job models.ScheduledJob) bool { return job.Runs >= 1 })
+	if maxRunning.Load() != 1 {
+		t.Errorf("Expected runs never to overlap, saw %d at once", maxRunning.Load())
+	}
+}
+
+func TestScheduler_RecordsFailuresAndPanics(t *testing.T) {
+	s := scheduler.New(scheduler.Config{})
+	s.Register(scheduler.Job{Name: "failing", Interval: time.Hour, Run: func(ctx context.Context) error {
+		return errors.New("upstream down")
+	}})
+	s.Register(scheduler.Job{Name: "panicking", Interval: time.Hour, Run: func(ctx context.Context) error {
+		panic("boom")
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	failing := waitForJob(t, s, "failing", func(job models.ScheduledJob) bool { return job.Runs == 1 })
+	if failing.Failures != 1 || failing.LastError != "upstream down" {
+		t.Errorf("Expected the failure to be recorded, got %+v", failing)
+	}
+	panicking := waitForJob(t, s, "panicking", func(job models.ScheduledJob) bool { return job.Runs == 1 })
+	if panicking.Failures != 1 || panicking.LastError != "panic: boom" || panicking.Running {
+		t.Errorf("Expected the panic to be recorded, got %+v", panicking)
+	}
+}
+
+func TestScheduler_DisabledJobsNeverRun(t *testing.T) {
+	s := scheduler.New(scheduler.Config{Disabled: []string{"off", "unknown"}})
+	var runs atomic.Int32
+	count := func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}
+	s.Register(scheduler.Job{Name: "off", Interval: time.Millisecond, Run: count})
+	s.Register(scheduler.Job{Name: "on", Interval: time.Hour, Run: count})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	waitForJob(t, s, "on", func(job models.ScheduledJob) bool { return job.Runs == 1 })
+	time.Sleep(20 * time.Millisecond)
+	jobs := s.Jobs()
+	if len(jobs) != 2 || jobs[0].Name != "off" || jobs[0].Enabled || jobs[0].Runs != 0 || !jobs[1].Enabled {
+		t.Errorf("Expected the disabled job to be listed but not run, got %+v", jobs)
+	}
+	if runs.Load() != 1 {
+		t.Errorf("Expected only the enabled job to run, got %d runs", runs.Load())
+	}
+}
+
+func TestScheduler_Register(t *testing.T) {
+	s := scheduler.New(scheduler.DefaultConfig())
+	noop := func(ctx context.Context) error { return nil }
+
+	if err := s.Register(scheduler.Job{Name: "job", Interval: time.Minute, Run: noop}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := s.Register(scheduler.Job{Name: "job", Interval: time.Minute, Run: noop}); !errors.Is(err, scheduler.ErrDuplicateJob) {
+		t.Errorf("Expected ErrDuplicateJob, got %v", err)
+	}
+	if err := s.Register(scheduler.Job{Name: "never", Run: noop}); !errors.Is(err, scheduler.ErrInvalidJob) {
+		t.Errorf("Expected ErrInvalidJob without an interval, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Start(ctx)
+	if err := s.Register(scheduler.Job{Name: "late", Interval: time.Minute, Run: noop}); !errors.Is(err, scheduler.ErrInvalidJob) {
+		t.Errorf("Expected registering after Start to fail, got %v", err)
+	}
+}
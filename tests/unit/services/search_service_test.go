@@ -0,0 +1,50 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/search"
+	"testing"
+)
+
+func TestSearchService_SuggestPrefix(t *testing.T) {
+	service := search.New()
+	service.Add(models.UnifiedTrack{ID: "1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"})
+	service.Add(models.UnifiedTrack{ID: "2", Name: "In the End", Artist: "Linkin Park", Source: "spotify"})
+	service.Add(models.UnifiedTrack{ID: "3", Name: "Hurt", Artist: "Johnny Cash", Source: "spotify"})
+
+	results := service.Suggest("lin", 10)
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 suggestions for artist prefix, got %d", len(results))
+	}
+
+	results = service.Suggest("Linkin nu", 10)
+	if len(results) != 1 || results[0].ID != "1" {
+		t.Errorf("Expected only Numb for multi-term query, got %+v", results)
+	}
+
+	if results := service.Suggest("zzz", 10); len(results) != 0 {
+		t.Errorf("Expected no suggestions, got %+v", results)
+	}
+}
+
+func TestSearchService_SuggestOrdersByPopularity(t *testing.T) {
+	service := search.New()
+	numb := models.UnifiedTrack{ID: "1", Name: "Numb", Artist: "Linkin Park", Source: "spotify"}
+	end := models.UnifiedTrack{ID: "2", Name: "In the End", Artist: "Linkin Park", Source: "spotify"}
+	service.Add(numb)
+	service.Add(end)
+
+	// Populate the cache, then make "In the End" more popular
+	service.Suggest("linkin", 10)
+	service.Add(end)
+	service.Add(end)
+
+	results := service.Suggest("linkin", 10)
+	if results[0].ID != "2" {
+		t.Errorf("Expected most played track first after cache invalidation, got %s", results[0].ID)
+	}
+
+	if results := service.Suggest("linkin", 1); len(results) != 1 {
+		t.Errorf("Expected limit to be applied, got %d results", len(results))
+	}
+}
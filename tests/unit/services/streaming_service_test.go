@@ -0,0 +1,203 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/events"
+	"backend/services/streaming"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreaming_KafkaMirrorsBusEvents(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/vnd.kafka.json.v2+json" {
+			t.Errorf("Unexpected content type %s", r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- r.URL.Path + " " + string(body)
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":1}]}`))
+	}))
+	defer server.Close()
+
+	publisher, err := streaming.New(streaming.Config{Backend: streaming.BackendKafka, URL: server.URL})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	bus := events.New(events.DefaultConfig())
+	streaming.Mirror(bus, publisher, "linkinsync.")
+	bus.Publish(events.TrackChanged, models.UnifiedTrack{ID: "t1", Name: "Numb", Artist: "Linkin Park"})
+	bus.Close()
+
+	select {
+	case request := <-received:
+		path, body, _ := strings.Cut(request, " ")
+		if path != "/topics/linkinsync.track_changed" {
+			t.Errorf("Expected topic linkinsync.track_changed, got %s", path)
+		}
+		var records struct {
+			Records []struct {
+				Value struct {
+					Type    string              `json:"type"`
+					Payload models.UnifiedTrack `json:"payload"`
+				} `json:"value"`
+			} `json:"records"`
+		}
+		json.Unmarshal([]byte(body), &records)
+		if len(records.Records) != 1 || records.Records[0].Value.Payload.ID != "t1" {
+			t.Errorf("Unexpected Kafka records %s", body)
+		}
+	default:
+		t.Fatal("Expected the event to be published to Kafka")
+	}
+}
+
+func TestStreaming_NATSPublish(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	lines := make(chan string, 4)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for i := 0; i < 3; i++ {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lines <- strings.TrimSpace(line)
+		}
+	}()
+
+	publisher, err := streaming.New(streaming.Config{Backend: streaming.BackendNATS, URL: "nats://" + listener.Addr().String()})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer publisher.Close()
+
+	if err := publisher.Publish("linkinsync.mood_detected", []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"CONNECT", "PUB linkinsync.mood_detected 7", `{"a":1}`}
+	for _, prefix := range expected {
+		select {
+		case line := <-lines:
+			if !strings.HasPrefix(line, prefix) {
+				t.Errorf("Expected line starting with %q, got %q", prefix, line)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %q", prefix)
+		}
+	}
+}
+
+func TestStreaming_UnknownBackend(t *testing.T) {
+	if _, err := streaming.New(streaming.Config{Backend: "rabbitmq"}); err == nil {
+		t.Error("Expected an error for an unknown backend")
+	}
+}
+
+// flakyPublisher fails the first failures publishes and records the rest
+type flakyPublisher struct {
+	failures  int
+	published []string
+}
+
+func (p *flakyPublisher) Publish(topic string, data []byte) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("connection refused")
+	}
+	p.published = append(p.published, topic+" "+string(data))
+	return nil
+}
+
+func (p *flakyPublisher) Close() error {
+	return nil
+}
+
+func TestDeliveryLog_RetriesAndRecords(t *testing.T) {
+	publisher := &flakyPublisher{failures: 1}
+	deliveryLog := streaming.NewDeliveryLog(publisher, streaming.DeliveryLogConfig{
+		Backend:     streaming.BackendKafka,
+		Size:        10,
+		MaxAttempts: 2,
+		RetryDelay:  time.Millisecond,
+	})
+
+	if err := deliveryLog.Publish("linkinsync.track_changed", []byte(`{"id":"t1"}`)); err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+
+	deliveries := deliveryLog.Deliveries("", 10)
+	if len(deliveries) != 1 {
+		t.Fatalf("Expected 1 delivery, got %d", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if delivery.Status != models.DeliveryDelivered || delivery.Attempts != 2 || delivery.Error != "" {
+		t.Errorf("Expected a delivery after 2 attempts, got %+v", delivery)
+	}
+	if delivery.Backend != "kafka" || delivery.Topic != "linkinsync.track_changed" || delivery.PayloadPreview != `{"id":"t1"}` {
+		t.Errorf("Unexpected delivery details %+v", delivery)
+	}
+}
+
+func TestDeliveryLog_RedeliversFailures(t *testing.T) {
+	publisher := &flakyPublisher{failures: 2}
+	deliveryLog := streaming.NewDeliveryLog(publisher, streaming.DeliveryLogConfig{Size: 10, MaxAttempts: 2})
+
+	if err := deliveryLog.Publish("linkinsync.message_posted", []byte(`{}`)); err == nil {
+		t.Fatal("Expected the publish to fail after all attempts")
+	}
+	failed := deliveryLog.Deliveries(models.DeliveryFailed, 10)
+	if len(failed) != 1 || failed[0].Error != "connection refused" || deliveryLog.Failed() != 1 {
+		t.Fatalf("Expected one failed delivery, got %+v", failed)
+	}
+
+	delivery, err := deliveryLog.Redeliver(failed[0].ID)
+	if err != nil {
+		t.Fatalf("Expected the redelivery to succeed, got %v", err)
+	}
+	if delivery.Status != models.DeliveryDelivered || delivery.Attempts != 3 {
+		t.Errorf("Expected a delivered status after 3 attempts, got %+v", delivery)
+	}
+	if len(publisher.published) != 1 || deliveryLog.Failed() != 0 {
+		t.Errorf("Expected the payload to be published once, got %v", publisher.published)
+	}
+
+	if _, err := deliveryLog.Redeliver(999); !errors.Is(err, streaming.ErrDeliveryNotFound) {
+		t.Errorf("Expected ErrDeliveryNotFound, got %v", err)
+	}
+}
+
+func TestDeliveryLog_EvictsOldest(t *testing.T) {
+	deliveryLog := streaming.NewDeliveryLog(&flakyPublisher{}, streaming.DeliveryLogConfig{Size: 2, MaxAttempts: 1})
+	for _, topic := range []string{"a", "b", "c"} {
+		deliveryLog.Publish(topic, []byte(topic))
+	}
+
+	deliveries := deliveryLog.Deliveries("", 10)
+	if len(deliveries) != 2 || deliveries[0].Topic != "c" || deliveries[1].Topic != "b" {
+		t.Errorf("Expected the 2 newest deliveries, newest first, got %+v", deliveries)
+	}
+	if _, found := deliveryLog.Delivery(deliveries[1].ID - 1); found {
+		t.Error("Expected the oldest delivery to be evicted")
+	}
+}
@@ -0,0 +1,88 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/spotify"
+	"backend/services/style"
+	"backend/tests/mocks"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func styleSpotify() *mocks.MockSpotifyService {
+	return &mocks.MockSpotifyService{
+		SearchArtistFunc: func(name string) (*models.SpotifyArtist, error) {
+			if name == "Nobody" {
+				return nil, fmt.Errorf("%w: no match for %q", spotify.ErrArtistNotFound, name)
+			}
+			return &models.SpotifyArtist{ID: "lp", Name: "Linkin Park"}, nil
+		},
+		GetArtistTopTracksFunc: func(artistID string) ([]models.UnifiedTrack, error) {
+			return []models.UnifiedTrack{
+				{ID: "t1", Name: "Heavy", Album: "One More Light", ReleaseDate: "2017-05-19"},
+				{ID: "t2", Name: "Instrumental", Album: "Meteora", ReleaseDate: "2003-03-25"},
+				{ID: "t3", Name: "Numb", Album: "Meteora", ReleaseDate: "2003-03-25"},
+			}, nil
+		},
+	}
+}
+
+func TestStyle_SamplesTopSongsOldestFirst(t *testing.T) {
+	var prompt string
+	var calls int32
+	genius := &mocks.MockGeniusService{
+		GetLyricsFunc: func(trackName, artistName string) (string, error) {
+			if trackName == "Instrumental" {
+				return "", errors.New("no lyrics")
+			}
+			return "Lyrics of " + trackName, nil
+		},
+	}
+	ai := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(p string) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			prompt = p
+			return `{"themes": ["alienation", ""], "evolution": " From rage to grief. ", "signature_phrases": ["I've become so numb"], "summary": "Raw and direct."}`, nil
+		},
+	}
+	service := style.New(styleSpotify(), genius, ai, nil, style.DefaultConfig())
+
+	artistStyle, err := service.Get("linkin park")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if artistStyle.Artist != "Linkin Park" || len(artistStyle.Themes) != 1 || artistStyle.Evolution != "From rage to grief." || len(artistStyle.SignaturePhrases) != 1 {
+		t.Errorf("Expected the parsed style, got %+v", artistStyle)
+	}
+	if len(artistStyle.Songs) != 2 || artistStyle.Songs[0].Name != "Numb" || artistStyle.Songs[1].Name != "Heavy" {
+		t.Errorf("Expected the songs with lyrics, oldest first, got %+v", artistStyle.Songs)
+	}
+	if !strings.Contains(prompt, `"Numb" from Meteora (2003-03-25)`) || strings.Index(prompt, "Lyrics of Numb") > strings.Index(prompt, "Lyrics of Heavy") {
+		t.Errorf("Expected the excerpts oldest first in the prompt, got %q", prompt)
+	}
+
+	service.Get("Linkin Park")
+	if calls != 1 {
+		t.Errorf("Expected the cached style to be reused, got %d AI calls", calls)
+	}
+}
+
+func TestStyle_Errors(t *testing.T) {
+	noLyrics := &mocks.MockGeniusService{
+		GetLyricsFunc: func(trackName, artistName string) (string, error) {
+			return "", errors.New("no lyrics")
+		},
+	}
+	service := style.New(styleSpotify(), noLyrics, &mocks.MockOllamaService{}, nil, style.Config{Songs: 2, CacheTTL: time.Hour, MaxEntries: 10})
+
+	if _, err := service.Get("Nobody"); !errors.Is(err, spotify.ErrArtistNotFound) {
+		t.Errorf("Expected ErrArtistNotFound, got %v", err)
+	}
+	if _, err := service.Get("Linkin Park"); !errors.Is(err, style.ErrNoLyrics) {
+		t.Errorf("Expected ErrNoLyrics, got %v", err)
+	}
+}
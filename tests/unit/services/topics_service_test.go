@@ -0,0 +1,191 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/topics"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// messageSourceFunc adapts a function to topics.MessageSource
+type messageSourceFunc func(since time.Time, limit int) ([]models.Message, error)
+
+func (f messageSourceFunc) RecentMessages(since time.Time, limit int) ([]models.Message, error) {
+	return f(since, limit)
+}
+
+// labelerFunc adapts a function to topics.Labeler
+type labelerFunc func(prompt string) (string, error)
+
+func (f labelerFunc) GenerateResponse(prompt string) (string, error) {
+	return f(prompt)
+}
+
+func communityMessages(texts ...string) messageSourceFunc {
+	return func(since time.Time, limit int) ([]models.Message, error) {
+		var messages []models.Message
+		for i, text := range texts {
+			messages = append(messages, models.Message{
+				ID:        int64(i + 1),
+				UserEmail: string(rune('a'+i%3)) + "@example.com",
+				Username:  string(rune('a' + i%3)),
+				Text:      text,
+				CreatedAt: time.Now().Add(time.Duration(i-len(texts)) * time.Minute),
+			})
+		}
+		return messages, nil
+	}
+}
+
+var linkinParkChat = []string{
+	"The new Linkin Park single is amazing",
+	"Has anyone heard the new Linkin Park single yet?",
+	"Linkin Park single on repeat all day",
+	"What pizza toppings do you like",
+	"Taylor Swift tour tickets sold out again",
+	"Taylor Swift tour setlist is huge",
+	"Got my Taylor Swift tour tickets!",
+}
+
+func TestTopicsService_ClustersMessages(t *testing.T) {
+	config := topics.DefaultConfig()
+	config.Similarity = topics.LocalSimilarity
+	service := topics.New(communityMessages(linkinParkChat...), topics.NewHashingEmbedder(512), nil, nil, config)
+
+	report := service.Run()
+	if report.Error != "" {
+		t.Fatalf("Expected no error, got %s", report.Error)
+	}
+	if report.MessagesAnalyzed != len(linkinParkChat) || report.Embedder != "local" {
+		t.Errorf("Unexpected report header %+v", report)
+	}
+	if len(report.Topics) != 2 {
+		t.Fatalf("Expected 2 topics, got %+v", report.Topics)
+	}
+	for _, topic := range report.Topics {
+		if topic.MessageCount != 3 || topic.Participants != 3 || len(topic.SampleMessages) != 3 {
+			t.Errorf("Expected 3 messages from 3 users, got %+v", topic)
+		}
+		if !strings.HasPrefix(topic.Label, "People are talking about ") {
+			t.Errorf("Expected a keyword label, got %q", topic.Label)
+		}
+	}
+	if labels := report.Topics[0].Label + report.Topics[1].Label; !strings.Contains(labels, "linkin") || !strings.Contains(labels, "swift") {
+		t.Errorf("Expected Linkin Park and Taylor Swift topics, got %q", labels)
+	}
+
+	if last, ok := service.LastReport(); !ok || len(last.Topics) != 2 {
+		t.Error("Expected the report to be kept")
+	}
+}
+
+func TestTopicsService_LabelsWithAIAndPostsDigestOnce(t *testing.T) {
+	config := topics.DefaultConfig()
+	config.Similarity = topics.LocalSimilarity
+	config.MinClusterSize = 3
+	labeler := labelerFunc(func(prompt string) (string, error) {
+		if strings.Contains(prompt, "Linkin Park") {
+			return "\"People are talking about the new Linkin Park single\"\nExtra text", nil
+		}
+		return "", errors.New("model unavailable")
+	})
+	var digests []string
+	poster := topics.PosterFunc(func(text string) error {
+		digests = append(digests, text)
+		return nil
+	})
+	service := topics.New(communityMessages(linkinParkChat...), topics.NewHashingEmbedder(512), labeler, poster, config)
+
+	report := service.Run()
+	var labels []string
+	for _, topic := range report.Topics {
+		labels = append(labels, topic.Label)
+	}
+	if !strings.Contains(strings.Join(labels, "|"), "People are talking about the new Linkin Park single") {
+		t.Errorf("Expected the AI label, got %v", labels)
+	}
+	if len(digests) != 1 || !strings.Contains(digests[0], "Linkin Park single") || report.Digest != digests[0] {
+		t.Fatalf("Expected one digest, got %v", digests)
+	}
+
+	service.Run()
+	if len(digests) != 1 {
+		t.Errorf("Expected unchanged topics not to be posted again, got %d digests", len(digests))
+	}
+}
+
+func TestTopicsService_SkipsDigestMessagesAndSmallClusters(t *testing.T) {
+	source := messageSourceFunc(func(since time.Time, limit int) ([]models.Message, error) {
+		return []models.Message{
+			{Username: "LinkinSync", Text: "What the community is talking about: Linkin Park single"},
+			{Username: "a", Text: "Linkin Park single"},
+			{Username: "b", Text: "Linkin Park single"},
+		}, nil
+	})
+	service := topics.New(source, topics.NewHashingEmbedder(64), nil, nil, topics.DefaultConfig())
+
+	report := service.Run()
+	if report.MessagesAnalyzed != 2 || len(report.Topics) != 0 {
+		t.Errorf("Expected 2 messages and no topics, got %+v", report)
+	}
+}
+
+func TestTopicsService_ReportsSourceErrors(t *testing.T) {
+	source := messageSourceFunc(func(since time.Time, limit int) ([]models.Message, error) {
+		return nil, errors.New("database down")
+	})
+	report := topics.New(source, topics.NewHashingEmbedder(64), nil, nil, topics.DefaultConfig()).Run()
+	if !strings.Contains(report.Error, "database down") {
+		t.Errorf("Expected the source error in the report, got %q", report.Error)
+	}
+}
+
+func TestOpenAIEmbedder_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer sk-test" || req.Model != "text-embedding-3-small" || len(req.Input) != 2 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		// Out of order, as the API allows
+		w.Write([]byte(`{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer server.Close()
+
+	embedder := topics.NewOpenAIEmbedder(server.URL+"/v1", "sk-test", "text-embedding-3-small")
+	vectors, err := embedder.Embed([]string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Expected embeddings in input order, got %v", vectors)
+	}
+}
+
+func TestOllamaEmbedder_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"embeddings": [[0.5, 0.5]]}`))
+	}))
+	defer server.Close()
+
+	vectors, err := topics.NewOllamaEmbedder(server.URL, "nomic-embed-text").Embed([]string{"only"})
+	if err != nil || len(vectors) != 1 {
+		t.Fatalf("Expected one embedding, got %v, %v", vectors, err)
+	}
+	if _, err := topics.NewOllamaEmbedder(server.URL, "nomic-embed-text").Embed([]string{"a", "b"}); err == nil {
+		t.Error("Expected an error when the embedding count doesn't match")
+	}
+}
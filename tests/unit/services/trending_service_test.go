@@ -0,0 +1,55 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/trending"
+	"testing"
+	"time"
+)
+
+func TestTrending_RanksByPlaysInWindow(t *testing.T) {
+	service := trending.New(trending.DefaultConfig())
+	numb := models.UnifiedTrack{ID: "1", Name: "Numb", Source: "spotify"}
+	inTheEnd := models.UnifiedTrack{ID: "2", Name: "In the End", Source: "spotify"}
+
+	now := time.Now()
+	service.Record(numb, now)
+	service.Record(inTheEnd, now)
+	service.Record(inTheEnd, now)
+	service.Record(numb, now.Add(-2*time.Hour)) // Already outside the window
+
+	top := service.Top(10)
+	if len(top) != 2 {
+		t.Fatalf("Expected 2 trending tracks, got %d", len(top))
+	}
+	if top[0].Track.Name != "In the End" || top[0].Plays != 2 {
+		t.Errorf("Expected In the End with 2 plays first, got %+v", top[0])
+	}
+	if top[1].Plays != 1 {
+		t.Errorf("Expected the old Numb play to be ignored, got %d plays", top[1].Plays)
+	}
+
+	if limited := service.Top(1); len(limited) != 1 {
+		t.Errorf("Expected limit to be applied, got %d", len(limited))
+	}
+}
+
+func TestTrending_ExpiresPlaysAsWindowSlides(t *testing.T) {
+	service := trending.New(trending.Config{Window: 100 * time.Millisecond, Buckets: 4})
+	track := models.UnifiedTrack{ID: "1", Name: "Numb", Source: "spotify"}
+
+	service.Record(track, time.Now())
+	if top := service.Top(10); len(top) != 1 {
+		t.Fatalf("Expected the play to be counted, got %d tracks", len(top))
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if top := service.Top(10); len(top) != 0 {
+		t.Errorf("Expected the play to expire, got %+v", top)
+	}
+
+	service.Record(track, time.Now())
+	if top := service.Top(10); len(top) != 1 || top[0].Plays != 1 {
+		t.Errorf("Expected a fresh count after expiry, got %+v", top)
+	}
+}
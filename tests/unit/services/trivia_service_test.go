@@ -0,0 +1,132 @@
+package services_test
+
+import (
+	"backend/services/trivia"
+	"backend/tests/mocks"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const triviaQuestions = `{"questions": [
+	{"question": "Which album is Numb on?", "choices": ["Meteora", "Hybrid Theory", "Minutes to Midnight", "One More Light"], "answer": 0, "explanation": "Numb closes Meteora."},
+	{"question": "Broken question", "choices": ["Only", "Three", "Choices"], "answer": 1},
+	{"question": "Who produced Meteora?", "choices": ["Rick Rubin", "Don Gilmore", "Butch Vig", "Max Martin"], "answer": 1, "explanation": "Don Gilmore produced it with the band."}
+]}`
+
+func triviaAI(prompt *string) *mocks.MockOllamaService {
+	return &mocks.MockOllamaService{
+		GenerateJSONFunc: func(p string) (string, error) {
+			if prompt != nil {
+				*prompt = p
+			}
+			return triviaQuestions, nil
+		},
+	}
+}
+
+func TestTrivia_StartsWithUsableQuestions(t *testing.T) {
+	var prompt string
+	service := trivia.New(nil, trivia.Config{Questions: 3, SessionTTL: time.Hour})
+
+	quiz, err := service.Start("user1", triviaAI(&prompt), "the artist Linkin Park", "Answer in Spanish.")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if quiz.Total != 2 || quiz.Question == nil || quiz.Question.Number != 1 || quiz.Question.Question != "Which album is Numb on?" {
+		t.Errorf("Expected the two usable questions, starting with the first, got %+v", quiz)
+	}
+	for _, want := range []string{"3 multiple-choice", "the artist Linkin Park", "Answer in Spanish."} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q, got %q", want, prompt)
+		}
+	}
+	if !service.Active("user1") || service.Active("user2") {
+		t.Error("Expected only user1 to have a quiz")
+	}
+}
+
+func TestTrivia_ScoresAnswers(t *testing.T) {
+	service := trivia.New(nil, trivia.DefaultConfig())
+	service.Start("user1", triviaAI(nil), "Linkin Park", "")
+
+	if _, err := service.Answer("user1", "what year was it released?"); !errors.Is(err, trivia.ErrNotAnAnswer) {
+		t.Errorf("Expected ErrNotAnAnswer for a question, got %v", err)
+	}
+
+	quiz, err := service.Answer("user1", "My answer is A.")
+	if err != nil || !quiz.Result.Correct || quiz.Score != 1 || quiz.Question.Number != 2 {
+		t.Errorf("Expected a correct first answer, got %+v, %v", quiz, err)
+	}
+
+	quiz, err = service.Answer("user1", "rick rubin")
+	if err != nil || quiz.Result.Correct || quiz.Result.Choice != "Don Gilmore" || quiz.Result.Explanation == "" {
+		t.Errorf("Expected a wrong answer naming the correct choice, got %+v, %v", quiz.Result, err)
+	}
+	if !quiz.Finished || quiz.Score != 1 || quiz.Answered != 2 || quiz.Question != nil {
+		t.Errorf("Expected the quiz finished with 1 of 2, got %+v", quiz)
+	}
+	if service.Active("user1") {
+		t.Error("Expected the finished quiz to be gone")
+	}
+	if _, err := service.Answer("user1", "b"); !errors.Is(err, trivia.ErrNoQuiz) {
+		t.Errorf("Expected ErrNoQuiz after the quiz finished, got %v", err)
+	}
+}
+
+func TestTrivia_AcceptsChoiceNumbers(t *testing.T) {
+	service := trivia.New(nil, trivia.DefaultConfig())
+	service.Start("user1", triviaAI(nil), "Linkin Park", "")
+
+	quiz, err := service.Answer("user1", "1")
+	if err != nil || !quiz.Result.Correct {
+		t.Errorf("Expected 1 to pick the first choice, got %+v, %v", quiz.Result, err)
+	}
+	if _, err := service.Answer("user1", "5"); !errors.Is(err, trivia.ErrNotAnAnswer) {
+		t.Errorf("Expected ErrNotAnAnswer for a choice out of range, got %v", err)
+	}
+}
+
+func TestTrivia_Stop(t *testing.T) {
+	service := trivia.New(nil, trivia.DefaultConfig())
+	service.Start("user1", triviaAI(nil), "Linkin Park", "")
+	service.Answer("user1", "a")
+
+	quiz, err := service.Stop("user1")
+	if err != nil || !quiz.Finished || quiz.Score != 1 || quiz.Answered != 1 || quiz.Question != nil {
+		t.Errorf("Expected the stopped quiz's final state, got %+v, %v", quiz, err)
+	}
+	if service.Active("user1") {
+		t.Error("Expected the quiz to be gone after Stop")
+	}
+	if _, err := service.Stop("user1"); !errors.Is(err, trivia.ErrNoQuiz) {
+		t.Errorf("Expected ErrNoQuiz, got %v", err)
+	}
+}
+
+func TestTrivia_ExpiresSessions(t *testing.T) {
+	service := trivia.New(nil, trivia.Config{Questions: 5, SessionTTL: 10 * time.Millisecond})
+	service.Start("user1", triviaAI(nil), "Linkin Park", "")
+
+	time.Sleep(20 * time.Millisecond)
+	if service.Active("user1") {
+		t.Error("Expected the quiz to expire")
+	}
+}
+
+func TestTrivia_RejectsUnusableQuestions(t *testing.T) {
+	service := trivia.New(nil, trivia.DefaultConfig())
+	ai := &mocks.MockOllamaService{
+		GenerateJSONFunc: func(prompt string) (string, error) {
+			return `{"questions": [{"question": "Same?", "choices": ["a", "A", "b", "c"], "answer": 0}, {"question": "Out of range?", "choices": ["a", "b", "c", "d"], "answer": 4}]}`, nil
+		},
+	}
+
+	if _, err := service.Start("user1", ai, "Linkin Park", ""); !errors.Is(err, trivia.ErrNoQuestions) {
+		t.Errorf("Expected ErrNoQuestions, got %v", err)
+	}
+	if service.Active("user1") {
+		t.Error("Expected no quiz without questions")
+	}
+}
@@ -0,0 +1,100 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/users"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestUsers_RegistersOnFirstUse(t *testing.T) {
+	store := users.NewMemoryStore()
+	service := users.New(store)
+
+	if err := service.Register("Alice@Example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	user, err := store.Get("Alice@Example.com")
+	if err != nil {
+		t.Fatalf("Expected alice to be registered, got %v", err)
+	}
+	if user.Email != "alice@example.com" {
+		t.Errorf("Expected the ID as the email, got %q", user.Email)
+	}
+
+	user, err = service.Get("bob")
+	if err != nil || user.ID != "bob" || user.Email != "" {
+		t.Errorf("Expected bob to be registered without an email, got %+v, %v", user, err)
+	}
+}
+
+func TestUsers_UpdatesProfiles(t *testing.T) {
+	service := users.New(users.NewMemoryStore())
+
+	username, bio := "alice", "Hybrid Theory on repeat"
+	genres := []string{"rock", "nu metal"}
+	user, err := service.Update("alice-id", models.UserUpdate{Username: &username, Bio: &bio, FavoriteGenres: &genres})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.Username != "alice" || user.Bio != bio || len(user.FavoriteGenres) != 2 || user.Name() != "alice" {
+		t.Errorf("Expected the update to be applied, got %+v", user)
+	}
+
+	// Fields left out are kept
+	displayName := "Alice"
+	user, err = service.Update("alice-id", models.UserUpdate{DisplayName: &displayName})
+	if err != nil || user.Bio != bio || user.Name() != "Alice" {
+		t.Errorf("Expected only the display name to change, got %+v, %v", user, err)
+	}
+
+	taken := "ALICE"
+	if _, err := service.Update("bob-id", models.UserUpdate{Username: &taken}); !errors.Is(err, users.ErrUsernameTaken) {
+		t.Errorf("Expected ErrUsernameTaken, got %v", err)
+	}
+}
+
+func TestUsers_DeleteForgetsRegistration(t *testing.T) {
+	store := users.NewMemoryStore()
+	service := users.New(store)
+
+	if err := service.Register("alice"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user, err := service.Delete("alice"); err != nil || user.ID != "alice" {
+		t.Fatalf("Expected the deleted profile, got %+v, %v", user, err)
+	}
+	if _, err := service.Delete("alice"); !errors.Is(err, users.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// The next request registers a new profile
+	if err := service.Register("alice"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := store.Get("alice"); err != nil {
+		t.Errorf("Expected alice to be registered again, got %v", err)
+	}
+}
+
+func TestUsers_ForgetsLeastRecentlySeenRegistrations(t *testing.T) {
+	store := users.NewMemoryStore()
+	service := users.New(store)
+
+	if err := service.Register("alice"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for i := 0; i < 10001; i++ {
+		service.Register(fmt.Sprintf("user-%d", i))
+	}
+	store.Delete("alice")
+
+	// alice was forgotten, so the next request upserts the profile again
+	if err := service.Register("alice"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := store.Get("alice"); err != nil {
+		t.Errorf("Expected alice to be registered again, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"backend/repositories"
+	"backend/server/models"
+	"backend/services/spotify"
+	"backend/services/validation"
+	"backend/tests/mocks"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestValidationService_Run(t *testing.T) {
+	catalog := repositories.NewMoodCatalog(map[string][]models.MoodBasedRecommendation{
+		"happy": {
+			{Track: models.UnifiedTrack{ID: "valid", Name: "Happy", Artist: "Pharrell Williams", Source: "spotify"}, MoodScore: 0.9},
+			{Track: models.UnifiedTrack{ID: "dead", Name: "Good Life", Artist: "OneRepublic", Source: "spotify"}, MoodScore: 0.8},
+			{Track: models.UnifiedTrack{ID: "gone", Name: "Lost Song", Artist: "Nobody", Source: "spotify"}, MoodScore: 0.7},
+			{Track: models.UnifiedTrack{ID: "flaky", Name: "Mr. Blue Sky", Artist: "ELO", Source: "spotify"}, MoodScore: 0.6},
+		},
+	})
+
+	mockSpotify := &mocks.MockSpotifyService{
+		GetTrackByIDFunc: func(trackID string) (*models.SpotifyTrack, error) {
+			switch trackID {
+			case "valid":
+				return &models.SpotifyTrack{ID: trackID}, nil
+			case "flaky":
+				return nil, errors.New("spotify API failed with status 503")
+			default:
+				return nil, fmt.Errorf("%w: %s", spotify.ErrTrackNotFound, trackID)
+			}
+		},
+		SearchTrackFunc: func(name, artist string) (*models.UnifiedTrack, error) {
+			if name == "Good Life" {
+				return &models.UnifiedTrack{ID: "corrected", Name: name, Artist: artist, Source: "spotify"}, nil
+			}
+			return nil, spotify.ErrTrackNotFound
+		},
+	}
+
+	service := validation.New(mockSpotify, catalog)
+
+	if _, ok := service.LastReport(); ok {
+		t.Error("Expected no report before the first run")
+	}
+
+	report := service.Run()
+
+	if report.Checked != 4 || report.Valid != 1 || report.Replaced != 1 || report.Unresolved != 1 || report.Errors != 1 {
+		t.Errorf("Unexpected report counts: %+v", report)
+	}
+
+	suggestions, _ := catalog.GetSuggestions("happy")
+	if suggestions[1].Track.ID != "corrected" {
+		t.Errorf("Expected dead ID to be replaced, got %s", suggestions[1].Track.ID)
+	}
+	if suggestions[1].MoodScore != 0.8 {
+		t.Errorf("Expected mood score to be kept, got %f", suggestions[1].MoodScore)
+	}
+	if suggestions[3].Track.ID != "flaky" {
+		t.Error("Expected IDs with transient errors to be left alone")
+	}
+
+	if last, ok := service.LastReport(); !ok || last.Replaced != 1 {
+		t.Errorf("Expected last report to be stored, got %+v", last)
+	}
+}
@@ -0,0 +1,161 @@
+package services_test
+
+import (
+	"backend/server/models"
+	"backend/services/events"
+	"backend/services/webhooks"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func webhookTestConfig() webhooks.Config {
+	config := webhooks.DefaultConfig()
+	config.MaxAttempts = 3
+	config.RetryDelay = time.Millisecond
+	return config
+}
+
+// webhookReceiver records the POSTs it accepts, failing the first failures
+type webhookReceiver struct {
+	mutex    sync.Mutex
+	failures int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (rec *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	if rec.failures > 0 {
+		rec.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	rec.bodies = append(rec.bodies, body)
+	rec.headers = append(rec.headers, r.Header.Clone())
+}
+
+func (rec *webhookReceiver) received() ([][]byte, []http.Header) {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	return rec.bodies, rec.headers
+}
+
+func TestWebhooksService_DeliversSignedEvents(t *testing.T) {
+	receiver := &webhookReceiver{failures: 1}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	bus := events.New(events.DefaultConfig())
+	service := webhooks.New(webhooks.NewMemoryStore(), bus, webhookTestConfig())
+
+	webhook, err := service.Register(models.CreateWebhookRequest{URL: server.URL, Events: []string{events.TrackChanged}, Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("Failed to register webhook: %v", err)
+	}
+
+	bus.Publish(events.MoodDetected, events.MoodDetectedPayload{UserID: "alice"}) // Not subscribed
+	bus.Publish(events.TrackChanged, models.UnifiedTrack{ID: "track1", Name: "Numb"})
+	bus.Close()
+	service.Close()
+
+	bodies, headers := receiver.received()
+	if len(bodies) != 1 {
+		t.Fatalf("Expected one delivery after a retry, got %d", len(bodies))
+	}
+	if headers[0].Get(webhooks.EventHeader) != events.TrackChanged {
+		t.Errorf("Expected the event header, got %q", headers[0].Get(webhooks.EventHeader))
+	}
+	if signature := headers[0].Get(webhooks.SignatureHeader); signature != webhooks.Sign("s3cret", bodies[0]) {
+		t.Errorf("Expected the body signed with the secret, got %q", signature)
+	}
+
+	var body struct {
+		WebhookID int64               `json:"webhook_id"`
+		Type      string              `json:"type"`
+		Payload   models.UnifiedTrack `json:"payload"`
+	}
+	json.Unmarshal(bodies[0], &body)
+	if body.WebhookID != webhook.ID || body.Type != events.TrackChanged || body.Payload.Name != "Numb" {
+		t.Errorf("Unexpected body %s", bodies[0])
+	}
+
+	deliveries, err := service.Deliveries(webhook.ID, "", 10)
+	if err != nil || len(deliveries.Deliveries) != 1 {
+		t.Fatalf("Expected one logged delivery, got %+v, %v", deliveries, err)
+	}
+	delivery := deliveries.Deliveries[0]
+	if delivery.Status != models.DeliveryDelivered || delivery.Attempts != 2 || delivery.Backend != "webhook" || delivery.Topic != events.TrackChanged {
+		t.Errorf("Expected a delivery that succeeded on the second attempt, got %+v", delivery)
+	}
+
+	redelivered, err := service.Redeliver(webhook.ID, delivery.ID)
+	if err != nil || redelivered.Attempts != 3 {
+		t.Errorf("Expected a third attempt, got %+v, %v", redelivered, err)
+	}
+	if bodies, _ := receiver.received(); len(bodies) != 2 {
+		t.Errorf("Expected the redelivery to be received, got %d POSTs", len(bodies))
+	}
+}
+
+func TestWebhooksService_LogsFailedDeliveries(t *testing.T) {
+	receiver := &webhookReceiver{failures: 10}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	bus := events.New(events.DefaultConfig())
+	service := webhooks.New(webhooks.NewMemoryStore(), bus, webhookTestConfig())
+	webhook, _ := service.Register(models.CreateWebhookRequest{URL: server.URL})
+
+	bus.Publish(events.MoodDetected, events.MoodDetectedPayload{UserID: "alice"})
+	bus.Close()
+	service.Close()
+
+	deliveries, _ := service.Deliveries(webhook.ID, models.DeliveryFailed, 10)
+	if deliveries.Failed != 1 || len(deliveries.Deliveries) != 1 || deliveries.Deliveries[0].Attempts != 3 {
+		t.Errorf("Expected one failed delivery after 3 attempts, got %+v", deliveries)
+	}
+}
+
+func TestWebhooksService_Register(t *testing.T) {
+	bus := events.New(events.DefaultConfig())
+	defer bus.Close()
+	service := webhooks.New(webhooks.NewMemoryStore(), bus, webhookTestConfig())
+
+	if _, err := service.Register(models.CreateWebhookRequest{URL: "ftp://example.com/hook"}); !errors.Is(err, webhooks.ErrInvalidURL) {
+		t.Errorf("Expected ErrInvalidURL, got %v", err)
+	}
+	if _, err := service.Register(models.CreateWebhookRequest{URL: "https://example.com/hook", Events: []string{"message_posted"}}); !errors.Is(err, webhooks.ErrInvalidEvent) {
+		t.Errorf("Expected ErrInvalidEvent, got %v", err)
+	}
+
+	webhook, err := service.Register(models.CreateWebhookRequest{URL: "https://example.com/hook"})
+	if err != nil {
+		t.Fatalf("Failed to register webhook: %v", err)
+	}
+	if len(webhook.Secret) != 64 || len(webhook.Events) != len(webhooks.Events) {
+		t.Errorf("Expected a generated secret and all events, got %+v", webhook)
+	}
+
+	listed, _ := service.List()
+	if len(listed) != 1 || listed[0].Secret != "" {
+		t.Errorf("Expected the webhook listed without its secret, got %+v", listed)
+	}
+
+	if err := service.Delete(webhook.ID); err != nil {
+		t.Errorf("Failed to delete webhook: %v", err)
+	}
+	if err := service.Delete(webhook.ID); !errors.Is(err, webhooks.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a deleted webhook, got %v", err)
+	}
+	if _, err := service.Deliveries(webhook.ID, "", 10); !errors.Is(err, webhooks.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a deleted webhook's deliveries, got %v", err)
+	}
+}